@@ -33,11 +33,11 @@ func main() {
 		}
 	}()
 
-	waitForShutdown(app.Server, cleanup)
+	waitForShutdown(app, cleanup)
 }
 
 func waitForShutdown(
-	server *http.Server,
+	app *app.App,
 	cleanup func(context.Context) error,
 ) {
 	quit := make(chan os.Signal, 1)
@@ -50,10 +50,15 @@ func waitForShutdown(
 	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
 	defer cancel()
 
-	if err := server.Shutdown(ctx); err != nil {
+	if err := app.Server.Shutdown(ctx); err != nil {
 		logger.Error("http server shutdown failed", "error", err)
 	}
 
+	// store accepted logs before the storage is closed
+	if err := app.Drain(ctx); err != nil {
+		logger.Error("ingest queue drain failed", "error", err)
+	}
+
 	if err := cleanup(ctx); err != nil {
 		logger.Error("cleanup failed", "error", err)
 	}
@@ -0,0 +1,276 @@
+// Command import-access-logs imports nginx and Apache access log files into a
+// project. Each file is streamed, gzip compressed, to the import endpoint of a
+// running API logs server, which parses it and queues the logs for storage.
+// When the server stops a file part way (rate limits, overload), the import
+// waits and resumes from the first line that was not processed.
+//
+//	import-access-logs -url http://localhost:8080 -api-key apilog_... access.log access.log.1.gz
+package main
+
+import (
+	"bufio"
+	"compress/gzip"
+	"context"
+	"encoding/json"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"os"
+	"os/signal"
+	"strconv"
+	"strings"
+	"syscall"
+	"time"
+)
+
+// importResponse is the part of the server's response the command reports
+type importResponse struct {
+	Data struct {
+		Lines          int `json:"lines"`
+		SuccessCount   int `json:"success_count"`
+		DuplicateCount int `json:"duplicate_count"`
+		FailedCount    int `json:"failed_count"`
+		Errors         []struct {
+			Line  int    `json:"line"`
+			Error string `json:"error"`
+		} `json:"errors"`
+		ResumeFromLine int `json:"resume_from_line"`
+	} `json:"data"`
+	Error   string `json:"error"`
+	Details string `json:"details"`
+}
+
+type importer struct {
+	client      *http.Client
+	endpoint    string
+	apiKey      string
+	environment string
+	query       url.Values
+	maxErrors   int
+}
+
+func main() {
+	serverURL := flag.String("url", envOr("API_LOGS_URL", "http://localhost:8080"), "API logs server URL (API_LOGS_URL)")
+	apiKey := flag.String("api-key", os.Getenv("API_LOGS_API_KEY"), "project API key with the ingest scope (API_LOGS_API_KEY)")
+	environment := flag.String("environment", "dev", "environment of the API key")
+	format := flag.String("format", "combined", "predefined log format: common or combined")
+	logFormat := flag.String("log-format", "", "nginx log_format string, overrides -format")
+	maxErrors := flag.Int("max-errors", 10, "failed lines to print per file")
+	flag.Usage = func() {
+		fmt.Fprintf(flag.CommandLine.Output(), "Usage: %s [flags] file... (- reads stdin; .gz files are decompressed)\n", os.Args[0])
+		flag.PrintDefaults()
+	}
+	flag.Parse()
+
+	if *apiKey == "" || flag.NArg() == 0 {
+		flag.Usage()
+		os.Exit(2)
+	}
+
+	query := url.Values{}
+	if *logFormat != "" {
+		query.Set("log_format", *logFormat)
+	} else {
+		query.Set("format", *format)
+	}
+
+	imp := &importer{
+		client:      &http.Client{},
+		endpoint:    strings.TrimRight(*serverURL, "/") + "/api/v1/logs/import/access-log",
+		apiKey:      *apiKey,
+		environment: *environment,
+		query:       query,
+		maxErrors:   *maxErrors,
+	}
+
+	ctx, stop := signal.NotifyContext(context.Background(), syscall.SIGINT, syscall.SIGTERM)
+	defer stop()
+
+	failed := false
+	for _, name := range flag.Args() {
+		if err := imp.importFile(ctx, name); err != nil {
+			fmt.Fprintf(os.Stderr, "%s: %v\n", name, err)
+			failed = true
+			if ctx.Err() != nil {
+				break
+			}
+		}
+	}
+	if failed {
+		os.Exit(1)
+	}
+}
+
+// importFile uploads a file, resuming it until every line was processed
+func (imp *importer) importFile(ctx context.Context, name string) error {
+	var total importResponse
+	firstLine := 1
+	for {
+		status, response, retryAfter, err := imp.upload(ctx, name, firstLine)
+		if err != nil {
+			return err
+		}
+
+		data := response.Data
+		total.Data.Lines = firstLine - 1 + data.Lines
+		total.Data.SuccessCount += data.SuccessCount
+		total.Data.DuplicateCount += data.DuplicateCount
+		total.Data.FailedCount += data.FailedCount
+		total.Data.Errors = append(total.Data.Errors, data.Errors...)
+
+		// Stdin cannot be read again, so only files are resumed
+		retryable := status == http.StatusTooManyRequests || status == http.StatusServiceUnavailable
+		if retryable && data.ResumeFromLine > 0 && name != "-" {
+			firstLine = data.ResumeFromLine
+			fmt.Fprintf(os.Stderr, "%s: %s, resuming from line %d in %s\n", name, response.Error, firstLine, retryAfter)
+			select {
+			case <-time.After(retryAfter):
+				continue
+			case <-ctx.Done():
+				return ctx.Err()
+			}
+		}
+
+		imp.report(name, &total)
+		switch {
+		case status == http.StatusAccepted || status == http.StatusPartialContent:
+			return nil
+		case response.Error == "" && data.FailedCount > 0:
+			return errors.New("no line could be imported, check the log format")
+		case response.Error == "Stream contains no logs":
+			return nil
+		}
+
+		message := response.Error
+		if response.Details != "" {
+			message += ": " + response.Details
+		}
+		if data.ResumeFromLine > 0 {
+			message += fmt.Sprintf(" (resume with line %d)", data.ResumeFromLine)
+		}
+		return fmt.Errorf("server responded %d: %s", status, message)
+	}
+}
+
+// upload sends a file from firstLine on, gzip compressed
+func (imp *importer) upload(ctx context.Context, name string, firstLine int) (int, *importResponse, time.Duration, error) {
+	file, err := openLog(name)
+	if err != nil {
+		return 0, nil, 0, err
+	}
+	defer file.Close()
+
+	lines := bufio.NewReaderSize(file, 64*1024)
+	if err := skipLines(lines, firstLine-1); err != nil {
+		return 0, nil, 0, err
+	}
+
+	body, writer := io.Pipe()
+	go func() {
+		gz := gzip.NewWriter(writer)
+		_, err := io.Copy(gz, lines)
+		if err == nil {
+			err = gz.Close()
+		}
+		writer.CloseWithError(err)
+	}()
+	defer body.Close()
+
+	query := url.Values{}
+	for key, values := range imp.query {
+		query[key] = values
+	}
+	query.Set("first_line", strconv.Itoa(firstLine))
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, imp.endpoint+"?"+query.Encode(), body)
+	if err != nil {
+		return 0, nil, 0, err
+	}
+	req.Header.Set("Content-Type", "text/plain")
+	req.Header.Set("Content-Encoding", "gzip")
+	req.Header.Set("X-API-Key", imp.apiKey)
+	req.Header.Set("X-Environment", imp.environment)
+
+	resp, err := imp.client.Do(req)
+	if err != nil {
+		return 0, nil, 0, err
+	}
+	defer resp.Body.Close()
+
+	var response importResponse
+	if err := json.NewDecoder(resp.Body).Decode(&response); err != nil {
+		return 0, nil, 0, fmt.Errorf("server responded %d: %w", resp.StatusCode, err)
+	}
+
+	retryAfter := time.Second
+	if seconds, err := strconv.Atoi(resp.Header.Get("Retry-After")); err == nil && seconds > 0 {
+		retryAfter = time.Duration(seconds) * time.Second
+	}
+	return resp.StatusCode, &response, retryAfter, nil
+}
+
+// report prints the outcome of a file
+func (imp *importer) report(name string, response *importResponse) {
+	data := response.Data
+	fmt.Printf("%s: %d lines, %d accepted (%d duplicates), %d failed\n",
+		name, data.Lines, data.SuccessCount, data.DuplicateCount, data.FailedCount)
+	for i, lineErr := range data.Errors {
+		if i == imp.maxErrors {
+			fmt.Printf("  ... and %d more\n", data.FailedCount-i)
+			break
+		}
+		fmt.Printf("  line %d: %s\n", lineErr.Line, lineErr.Error)
+	}
+}
+
+// openLog opens a log file, decompressing .gz files; "-" is stdin
+func openLog(name string) (io.ReadCloser, error) {
+	if name == "-" {
+		return io.NopCloser(os.Stdin), nil
+	}
+
+	file, err := os.Open(name)
+	if err != nil {
+		return nil, err
+	}
+	if !strings.HasSuffix(name, ".gz") {
+		return file, nil
+	}
+
+	gz, err := gzip.NewReader(file)
+	if err != nil {
+		file.Close()
+		return nil, err
+	}
+	return struct {
+		io.Reader
+		io.Closer
+	}{gz, file}, nil
+}
+
+// skipLines discards n lines
+func skipLines(r *bufio.Reader, n int) error {
+	for n > 0 {
+		_, err := r.ReadSlice('\n')
+		switch {
+		case err == nil:
+			n--
+		case errors.Is(err, bufio.ErrBufferFull):
+		case err == io.EOF:
+			return nil
+		default:
+			return err
+		}
+	}
+	return nil
+}
+
+func envOr(key, fallback string) string {
+	if value := os.Getenv(key); value != "" {
+		return value
+	}
+	return fallback
+}
@@ -0,0 +1,196 @@
+// Command restore-archive restores the logs a running API logs server archived
+// before purging them. The archive is read by the server, which decrypts the
+// archived headers and bodies and stores the logs, with their original
+// timestamps, in the target project. Days are restored one request at a time;
+// restoring a day again stores nothing twice.
+//
+//	restore-archive -url http://localhost:8080 -token ... -project <id> -from 2026-01-01 -to 2026-01-07
+//
+// Restored logs are subject to the target project's retention, so restore
+// into a project whose retention covers the range.
+package main
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"os"
+	"os/signal"
+	"strings"
+	"syscall"
+	"time"
+)
+
+// restoreResponse is the part of the server's restore response the command
+// reports
+type restoreResponse struct {
+	Data struct {
+		Objects int `json:"objects"`
+		Logs    int `json:"logs"`
+		Headers int `json:"headers"`
+		Bodies  int `json:"bodies"`
+		Failed  int `json:"failed"`
+	} `json:"data"`
+}
+
+// listResponse is the server's list of archived objects
+type listResponse struct {
+	Data []struct {
+		Key   string    `json:"key"`
+		Class string    `json:"class"`
+		Day   time.Time `json:"day"`
+	} `json:"data"`
+}
+
+type restorer struct {
+	client   *http.Client
+	endpoint string
+	token    string
+}
+
+func main() {
+	serverURL := flag.String("url", envOr("API_LOGS_URL", "http://localhost:8080"), "API logs server URL (API_LOGS_URL)")
+	token := flag.String("token", os.Getenv("API_LOGS_TOKEN"), "session token from POST /api/v1/auth/login (API_LOGS_TOKEN)")
+	project := flag.String("project", "", "ID of the project whose archive is restored")
+	into := flag.String("into", "", "ID of the project the logs are restored into (default -project)")
+	fromDate := flag.String("from", "", "first UTC day restored, 2006-01-02")
+	toDate := flag.String("to", "", "last UTC day restored (default -from)")
+	list := flag.Bool("list", false, "list the archived objects of the range instead of restoring them")
+	flag.Parse()
+
+	if *token == "" || *project == "" || *fromDate == "" {
+		flag.Usage()
+		os.Exit(2)
+	}
+	if *toDate == "" {
+		*toDate = *fromDate
+	}
+	from, err := time.Parse(time.DateOnly, *fromDate)
+	if err != nil {
+		fatalf("-from must be a date (2006-01-02)")
+	}
+	to, err := time.Parse(time.DateOnly, *toDate)
+	if err != nil || to.Before(from) {
+		fatalf("-to must be a date (2006-01-02) on or after -from")
+	}
+
+	r := &restorer{
+		client:   &http.Client{},
+		endpoint: strings.TrimRight(*serverURL, "/") + "/api/v1/projects/" + url.PathEscape(*project) + "/archive",
+		token:    *token,
+	}
+
+	ctx, stop := signal.NotifyContext(context.Background(), syscall.SIGINT, syscall.SIGTERM)
+	defer stop()
+
+	if *list {
+		if err := r.list(ctx, from, to); err != nil {
+			fatalf("%v", err)
+		}
+		return
+	}
+
+	var logs, failed int
+	for day := from; !day.After(to); day = day.AddDate(0, 0, 1) {
+		response, err := r.restore(ctx, day, *into)
+		if err != nil {
+			fatalf("%s: %v", day.Format(time.DateOnly), err)
+		}
+
+		data := response.Data
+		fmt.Printf("%s: %d objects, %d logs restored (%d with headers, %d with bodies), %d failed\n",
+			day.Format(time.DateOnly), data.Objects, data.Logs, data.Headers, data.Bodies, data.Failed)
+		logs += data.Logs
+		failed += data.Failed
+	}
+	if from != to {
+		fmt.Printf("total: %d logs restored, %d failed\n", logs, failed)
+	}
+	if failed > 0 {
+		os.Exit(1)
+	}
+}
+
+// restore restores one day of the archive
+func (r *restorer) restore(ctx context.Context, day time.Time, into string) (*restoreResponse, error) {
+	body, err := json.Marshal(map[string]string{
+		"from":              day.Format(time.DateOnly),
+		"to":                day.Format(time.DateOnly),
+		"target_project_id": into,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	var response restoreResponse
+	if err := r.do(ctx, http.MethodPost, r.endpoint+"/restore", bytes.NewReader(body), &response); err != nil {
+		return nil, err
+	}
+	return &response, nil
+}
+
+// list prints the archived objects of the range
+func (r *restorer) list(ctx context.Context, from, to time.Time) error {
+	query := url.Values{}
+	query.Set("from", from.Format(time.DateOnly))
+	query.Set("to", to.Format(time.DateOnly))
+
+	var response listResponse
+	if err := r.do(ctx, http.MethodGet, r.endpoint+"?"+query.Encode(), nil, &response); err != nil {
+		return err
+	}
+	for _, object := range response.Data {
+		fmt.Printf("%s  %-8s  %s\n", object.Day.Format(time.DateOnly), object.Class, object.Key)
+	}
+	return nil
+}
+
+// do sends a request and decodes the response into v, returning the server's
+// error for responses other than 200
+func (r *restorer) do(ctx context.Context, method, endpoint string, body io.Reader, v any) error {
+	req, err := http.NewRequestWithContext(ctx, method, endpoint, body)
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Authorization", "Bearer "+r.token)
+	req.Header.Set("Content-Type", "application/json")
+
+	resp, err := r.client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		var failure struct {
+			Error   string `json:"error"`
+			Details string `json:"details"`
+		}
+		if err := json.NewDecoder(resp.Body).Decode(&failure); err != nil {
+			return fmt.Errorf("server responded %d", resp.StatusCode)
+		}
+		message := failure.Error
+		if failure.Details != "" {
+			message += ": " + failure.Details
+		}
+		return fmt.Errorf("server responded %d: %s", resp.StatusCode, message)
+	}
+	return json.NewDecoder(resp.Body).Decode(v)
+}
+
+func fatalf(format string, args ...any) {
+	fmt.Fprintf(os.Stderr, format+"\n", args...)
+	os.Exit(1)
+}
+
+func envOr(key, fallback string) string {
+	if value := os.Getenv(key); value != "" {
+		return value
+	}
+	return fallback
+}
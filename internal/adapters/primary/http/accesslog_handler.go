@@ -57,6 +57,10 @@ func (h *AccessLogHandler) CreateAccessLog(c *gin.Context) {
 	}
 
 	if err := h.logService.CreateLog(c.Request.Context(), log); err != nil {
+		if err == domain.ErrForbidden {
+			c.JSON(403, gin.H{"error": "Forbidden"})
+			return
+		}
 		c.JSON(500, gin.H{"error": "Failed to create access log", "details": err.Error()})
 		return
 	}
@@ -92,6 +96,10 @@ func (h *AccessLogHandler) CreateManyAccessLogs(c *gin.Context) {
 	}
 
 	if err := h.logService.CreateManyLogs(c.Request.Context(), logs); err != nil {
+		if err == domain.ErrForbidden {
+			c.JSON(403, gin.H{"error": "Forbidden"})
+			return
+		}
 		c.JSON(500, gin.H{"error": "Failed to create access logs", "details": err.Error()})
 		return
 	}
@@ -102,6 +110,14 @@ func (h *AccessLogHandler) CreateManyAccessLogs(c *gin.Context) {
 func (h *AccessLogHandler) GetAccessLogDetails(c *gin.Context) {
 	id := c.Param("id")
 	log, err := h.logService.GetLogDetails(c.Request.Context(), id)
+	if err == domain.ErrAccessLogNotFound {
+		c.JSON(404, gin.H{"error": "Access log not found"})
+		return
+	}
+	if err == domain.ErrForbidden {
+		c.JSON(403, gin.H{"error": "Forbidden"})
+		return
+	}
 	if err != nil {
 		c.JSON(500, gin.H{"error": "Failed to retrieve access log", "details": err.Error()})
 		return
@@ -124,8 +140,20 @@ func (h *AccessLogHandler) GetAccessLogs(c *gin.Context) {
 		return
 	}
 
+	// metadata[key]=value pairs filter on metadata contents
+	if metadata := c.QueryMap("metadata"); len(metadata) > 0 {
+		filter.Metadata = make(map[string]any, len(metadata))
+		for key, value := range metadata {
+			filter.Metadata[key] = value
+		}
+	}
+
 	logs, err := h.logService.GetLogs(ctx, filter)
 	if err != nil {
+		if err == domain.ErrForbidden {
+			c.JSON(403, gin.H{"error": "Forbidden"})
+			return
+		}
 		c.JSON(500, gin.H{"error": "Failed to retrieve access logs", "details": err.Error()})
 		return
 	}
@@ -133,6 +161,10 @@ func (h *AccessLogHandler) GetAccessLogs(c *gin.Context) {
 	count, err := h.logService.CountLogs(ctx, filter)
 
 	if err != nil {
+		if err == domain.ErrForbidden {
+			c.JSON(403, gin.H{"error": "Forbidden"})
+			return
+		}
 		c.JSON(500, gin.H{"error": "Failed to count access logs", "details": err.Error()})
 		return
 	}
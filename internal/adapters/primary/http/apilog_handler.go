@@ -1,13 +1,15 @@
 package http
 
 import (
+	"fmt"
+	"math"
 	"net/http"
+	"net/url"
 	"strconv"
 	"strings"
 	"time"
 
 	"github.com/gin-gonic/gin"
-	"github.com/google/uuid"
 	"github.com/spidey52/api-logs/internal/domain"
 	"github.com/spidey52/api-logs/internal/ports/input"
 	"github.com/spidey52/api-logs/pkg/logger"
@@ -17,20 +19,29 @@ import (
 type APILogHandler struct {
 	logService     input.APILogService
 	projectService input.ProjectService
-	userService    input.UserService
+	authService    input.AuthService
+	quotaService   input.QuotaService
+	ingestService  input.IngestService
 }
 
 // NewAPILogHandler creates a new instance of APILogHandler
-func NewAPILogHandler(logService input.APILogService, projectService input.ProjectService, userService input.UserService) *APILogHandler {
+func NewAPILogHandler(logService input.APILogService, projectService input.ProjectService, authService input.AuthService, quotaService input.QuotaService, ingestService input.IngestService) *APILogHandler {
 	return &APILogHandler{
 		logService:     logService,
 		projectService: projectService,
-		userService:    userService,
+		authService:    authService,
+		quotaService:   quotaService,
+		ingestService:  ingestService,
 	}
 }
 
 // CreateLogRequest represents the request body for creating a log
 type CreateLogRequest struct {
+	// ID or IdempotencyKey make retries safe: a log with the ID (or derived
+	// from the key) of an accepted log is reported as a duplicate and stored once
+	ID             string `json:"id" binding:"omitempty,uuid"`
+	IdempotencyKey string `json:"idempotency_key" binding:"omitempty,max=255"`
+
 	Method          string            `json:"method" binding:"required"`
 	Path            string            `json:"path" binding:"required"`
 	Params          map[string]string `json:"params"`
@@ -61,19 +72,39 @@ type CreateBatchLogsRequest struct {
 
 // BatchLogResponse represents the response for batch log creation
 type BatchLogResponse struct {
-	SuccessCount int      `json:"success_count"`
-	FailedCount  int      `json:"failed_count"`
-	Total        int      `json:"total"`
-	Errors       []string `json:"errors,omitempty"`
+	SuccessCount   int              `json:"success_count"` // accepted and duplicate logs
+	DuplicateCount int              `json:"duplicate_count"`
+	FailedCount    int              `json:"failed_count"`
+	Total          int              `json:"total"`
+	Errors         []string         `json:"errors,omitempty"`
+	Results        []BatchLogResult `json:"results"`
+}
+
+// BatchLogResult reports the outcome of one log of a batch, in request order
+type BatchLogResult struct {
+	Index  int    `json:"index"`
+	Status string `json:"status"` // accepted, duplicate or failed
+	ID     string `json:"id,omitempty"`
+	Error  string `json:"error,omitempty"`
+	// RejectedFields lists the fields that failed the log, or that were
+	// dropped or truncated from an accepted log
+	RejectedFields []domain.FieldError `json:"rejected_fields,omitempty"`
 }
 
-// AuthMiddleware validates API key from headers
-func (h *APILogHandler) AuthMiddleware() gin.HandlerFunc {
+// AuthMiddleware validates the API key from headers and requires it to hold the
+// given scope. Dashboard accounts may instead send a session bearer token
+// together with X-Project-ID; their project role is checked by the services.
+func (h *APILogHandler) AuthMiddleware(scope domain.APIKeyScope) gin.HandlerFunc {
 	return func(c *gin.Context) {
 		apiKey := c.GetHeader("X-API-Key")
 		environment := c.GetHeader("X-Environment")
 
 		if apiKey == "" {
+			if token := bearerToken(c); token != "" {
+				h.authenticateAccount(c, token)
+				return
+			}
+
 			c.JSON(http.StatusUnauthorized, gin.H{"error": "API key is required"})
 			c.Abort()
 			return
@@ -90,29 +121,116 @@ func (h *APILogHandler) AuthMiddleware() gin.HandlerFunc {
 			return
 		}
 
-		project, err := h.projectService.ValidateAPIKey(c.Request.Context(), apiKey, env)
+		project, key, err := h.projectService.ValidateAPIKey(c.Request.Context(), apiKey, env, scope)
 		if err != nil {
-			c.JSON(http.StatusUnauthorized, gin.H{"error": "Unauthorized"})
+			if err == domain.ErrInsufficientScope {
+				c.JSON(http.StatusForbidden, gin.H{"error": "API key lacks the " + string(scope) + " scope"})
+			} else {
+				c.JSON(http.StatusUnauthorized, gin.H{"error": "Unauthorized"})
+			}
 			c.Abort()
 			return
 		}
 
+		// Tell the caller which key authenticated the request, and when it stops
+		// working if it is a rotated key in its grace period
+		c.Header("X-API-Key-ID", key.ID)
+		c.Header("X-API-Key-Prefix", key.Prefix)
+		if key.ExpiresAt != nil {
+			c.Header("X-API-Key-Expires-At", key.ExpiresAt.UTC().Format(time.RFC3339))
+		}
+
 		// Store project info in context
 		c.Set("project_id", project.ID)
+		c.Set("api_key_id", key.ID)
 		c.Set("environment", string(project.Environment))
+		c.Request = c.Request.WithContext(domain.ContextWithPrincipal(c.Request.Context(), domain.APIKeyPrincipal(project, key)))
 
 		c.Next()
 	}
 }
 
-// CreateLog handles POST /api/v1/logs
-func (h *APILogHandler) CreateLog(c *gin.Context) {
-	var req CreateLogRequest
-	if err := c.ShouldBindJSON(&req); err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+// authenticateAccount resolves a session token and the project named by
+// X-Project-ID; the services then check the account's role on that project
+func (h *APILogHandler) authenticateAccount(c *gin.Context, token string) {
+	account, err := h.authService.Authenticate(c.Request.Context(), token)
+	if err != nil {
+		if err == domain.ErrUnauthorized {
+			c.JSON(http.StatusUnauthorized, gin.H{"error": "Unauthorized"})
+		} else {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to authenticate", "details": err.Error()})
+		}
+		c.Abort()
+		return
+	}
+
+	projectID := c.GetHeader("X-Project-ID")
+	if projectID == "" {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "X-Project-ID is required with a bearer token"})
+		c.Abort()
 		return
 	}
 
+	ctx := domain.ContextWithPrincipal(c.Request.Context(), domain.AccountPrincipal(account))
+	project, err := h.projectService.GetProject(ctx, projectID)
+	if err != nil {
+		switch err {
+		case domain.ErrProjectNotFound:
+			c.JSON(http.StatusNotFound, gin.H{"error": "Project not found"})
+		case domain.ErrForbidden:
+			c.JSON(http.StatusForbidden, gin.H{"error": "Forbidden"})
+		default:
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to retrieve project"})
+		}
+		c.Abort()
+		return
+	}
+
+	c.Set("account", account)
+	c.Set("account_id", account.ID)
+	c.Set("project_id", project.ID)
+	c.Set("environment", string(project.Environment))
+	c.Request = c.Request.WithContext(ctx)
+
+	c.Next()
+}
+
+// checkQuota counts an ingest request against the project's and the API key's
+// request rates, responding 429 with Retry-After when one is exceeded. It
+// reports whether the request may proceed. The logs are counted by the ingest
+// service once validated and deduplicated.
+func (h *APILogHandler) checkQuota(c *gin.Context) bool {
+	projectID := c.GetString("project_id")
+	keyID := c.GetString("api_key_id")
+
+	err := h.quotaService.CheckRequest(c.Request.Context(), projectID, keyID)
+	if err == nil {
+		return true
+	}
+
+	c.JSON(quotaErrorResponse(c, err))
+	return false
+}
+
+// quotaErrorResponse returns the status and body reporting a failed quota
+// check, setting Retry-After when the quota was exceeded
+func quotaErrorResponse(c *gin.Context, err error) (int, gin.H) {
+	exceeded, ok := err.(*domain.QuotaExceededError)
+	if !ok {
+		return http.StatusInternalServerError, gin.H{"error": "Failed to check quota", "details": err.Error()}
+	}
+
+	retryAfter := int(math.Ceil(exceeded.RetryAfter.Seconds()))
+	if retryAfter < 1 {
+		retryAfter = 1
+	}
+	c.Header("Retry-After", strconv.Itoa(retryAfter))
+	return http.StatusTooManyRequests, gin.H{"error": "Quota exceeded", "limit": exceeded.Limit, "retry_after": retryAfter}
+}
+
+// newLogEntry converts a log request into a log entry for the project the
+// request was authenticated for
+func newLogEntry(c *gin.Context, req *CreateLogRequest) *domain.LogEntry {
 	// Get project info from middleware
 	projectID, _ := c.Get("project_id")
 	environment, _ := c.Get("environment")
@@ -132,6 +250,7 @@ func (h *APILogHandler) CreateLog(c *gin.Context) {
 		UserAgent:     req.UserAgent,
 		ErrorMessage:  req.ErrorMessage,
 		UserID:        req.UserID,
+		ID:            req.ID,
 	}
 
 	// If IP not provided, get from request
@@ -144,45 +263,87 @@ func (h *APILogHandler) CreateLog(c *gin.Context) {
 		log.UserAgent = c.Request.UserAgent()
 	}
 
+	entry := &domain.LogEntry{Log: log, IdempotencyKey: req.IdempotencyKey}
+
 	// Create headers object if provided
-	var headers *domain.APILogHeaders
 	if len(req.RequestHeaders) > 0 || len(req.ResponseHeaders) > 0 {
-		headers = &domain.APILogHeaders{
+		entry.Headers = &domain.APILogHeaders{
 			RequestHeaders:  req.RequestHeaders,
 			ResponseHeaders: req.ResponseHeaders,
 		}
 	}
 
 	// Create body object if provided
-	var body *domain.APILogBody
-	// if len(req.RequestBody) > 0 || len(req.ResponseBody) > 0 {
-	// 	body = &domain.APILogBody{
-	// 		RequestBody:  req.RequestBody,
-	// 		ResponseBody: req.ResponseBody,
-	// 	}
-	// }
-
 	if req.RequestBody != nil || req.ResponseBody != nil {
-		body = &domain.APILogBody{
+		entry.Body = &domain.APILogBody{
 			RequestBody:  req.RequestBody,
 			ResponseBody: req.ResponseBody,
 		}
 	}
 
-	// Create log with headers and body
-	if err := h.logService.CreateLog(c.Request.Context(), log, headers, body); err != nil {
-		if err == domain.ErrInvalidInput {
-			c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid input"})
-			return
-		}
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to create log"})
+	return entry
+}
+
+// queryParams keeps the first value of each parameter of a query string, like
+// the SDK middleware. Malformed parameters are skipped.
+func queryParams(query string) map[string]string {
+	values, _ := url.ParseQuery(query)
+	if len(values) == 0 {
+		return nil
+	}
+	params := make(map[string]string, len(values))
+	for key, value := range values {
+		params[key] = value[0]
+	}
+	return params
+}
+
+// CreateLog handles POST /api/v1/logs
+func (h *APILogHandler) CreateLog(c *gin.Context) {
+	var req CreateLogRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		respondBindError(c, err)
+		return
+	}
+
+	if req.IdempotencyKey == "" {
+		req.IdempotencyKey = c.GetHeader("Idempotency-Key")
+	}
+
+	if !h.checkQuota(c) {
 		return
 	}
 
-	c.JSON(http.StatusCreated, gin.H{"data": gin.H{
+	entry := newLogEntry(c, &req)
+	log := entry.Log
+
+	// Queue log with headers and body for storage
+	errs, err := h.ingestService.Enqueue(c.Request.Context(), []*domain.LogEntry{entry})
+	if err != nil {
+		h.respondEnqueueError(c, err)
+		return
+	}
+	switch errs[0] {
+	case nil:
+	case domain.ErrDuplicateLog:
+		c.JSON(http.StatusOK, gin.H{"data": gin.H{
+			"id":        log.ID,
+			"duplicate": true,
+		}})
+		return
+	default:
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid input", "details": errs[0].Error(), "rejected_fields": rejectedFields(errs[0])})
+		return
+	}
+
+	data := gin.H{
 		"id":        log.ID,
 		"timestamp": log.Timestamp,
-	}})
+	}
+	if len(entry.RejectedFields) > 0 {
+		data["rejected_fields"] = entry.RejectedFields
+	}
+	c.JSON(http.StatusAccepted, gin.H{"data": data})
 }
 
 // GetLog handles GET /api/v1/logs/:id
@@ -195,6 +356,10 @@ func (h *APILogHandler) GetLog(c *gin.Context) {
 			c.JSON(http.StatusNotFound, gin.H{"error": "Log not found"})
 			return
 		}
+		if err == domain.ErrForbidden {
+			c.JSON(http.StatusForbidden, gin.H{"error": "Forbidden"})
+			return
+		}
 		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to retrieve log", "details": err.Error()})
 		return
 	}
@@ -212,6 +377,10 @@ func (h *APILogHandler) GetLogWithDetails(c *gin.Context) {
 			c.JSON(http.StatusNotFound, gin.H{"error": "Log not found"})
 			return
 		}
+		if err == domain.ErrForbidden {
+			c.JSON(http.StatusForbidden, gin.H{"error": "Forbidden"})
+			return
+		}
 		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to retrieve log details"})
 		return
 	}
@@ -235,6 +404,10 @@ func (h *APILogHandler) GetLogHeaders(c *gin.Context) {
 			c.JSON(http.StatusNotFound, gin.H{"error": "Headers not found"})
 			return
 		}
+		if err == domain.ErrForbidden {
+			c.JSON(http.StatusForbidden, gin.H{"error": "Forbidden"})
+			return
+		}
 		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to retrieve headers"})
 		return
 	}
@@ -252,6 +425,10 @@ func (h *APILogHandler) GetLogBody(c *gin.Context) {
 			c.JSON(http.StatusNotFound, gin.H{"error": "Body not found"})
 			return
 		}
+		if err == domain.ErrForbidden {
+			c.JSON(http.StatusForbidden, gin.H{"error": "Forbidden"})
+			return
+		}
 		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to retrieve body"})
 		return
 	}
@@ -261,13 +438,41 @@ func (h *APILogHandler) GetLogBody(c *gin.Context) {
 
 // ListLogs handles GET /api/v1/logs
 func (h *APILogHandler) ListLogs(c *gin.Context) {
+	filter := logFilter(c)
+
+	logger.Info("Listing logs for project", filter.ProjectID, "environment", filter.Environment)
+
+	logs, err := h.logService.ListLogs(c.Request.Context(), filter)
+	if err != nil {
+		if err == domain.ErrForbidden {
+			c.JSON(http.StatusForbidden, gin.H{"error": "Forbidden"})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to retrieve logs", "details": err.Error()})
+		return
+	}
+
+	// Get total count for pagination
+	total, err := h.logService.CountLogs(c.Request.Context(), filter)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to get total count", "details": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"data":  logs,
+		"total": total,
+	})
+}
+
+// logFilter reads the filter of ListLogs from the query string, for the
+// project the request was authenticated for
+func logFilter(c *gin.Context) domain.LogFilter {
 	// Get project info from middleware
 	projectID, _ := c.Get("project_id")
 	userID, _ := c.Get("userId")
 	environment := c.Query("environment")
 
-	logger.Info("Listing logs for project", projectID, "environment", environment)
-
 	filter := domain.LogFilter{
 		ProjectID:   projectID.(string),
 		Environment: domain.Environment(environment),
@@ -350,23 +555,7 @@ func (h *APILogHandler) ListLogs(c *gin.Context) {
 	// Apply defaults for pagination
 	filter.ApplyDefaults()
 
-	logs, err := h.logService.ListLogs(c.Request.Context(), filter)
-	if err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to retrieve logs", "details": err.Error()})
-		return
-	}
-
-	// Get total count for pagination
-	total, err := h.logService.CountLogs(c.Request.Context(), filter)
-	if err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to get total count", "details": err.Error()})
-		return
-	}
-
-	c.JSON(http.StatusOK, gin.H{
-		"data":  logs,
-		"total": total,
-	})
+	return filter
 }
 
 // GetStats handles GET /api/v1/logs/stats
@@ -381,6 +570,10 @@ func (h *APILogHandler) GetStats(c *gin.Context) {
 		domain.Environment(environment.(string)),
 	)
 	if err != nil {
+		if err == domain.ErrForbidden {
+			c.JSON(http.StatusForbidden, gin.H{"error": "Forbidden"})
+			return
+		}
 		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to retrieve stats"})
 		return
 	}
@@ -400,6 +593,10 @@ func (h *APILogHandler) GetUniquePaths(c *gin.Context) {
 		domain.Environment(environment.(string)),
 	)
 	if err != nil {
+		if err == domain.ErrForbidden {
+			c.JSON(http.StatusForbidden, gin.H{"error": "Forbidden"})
+			return
+		}
 		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to retrieve paths"})
 		return
 	}
@@ -411,122 +608,70 @@ func (h *APILogHandler) GetUniquePaths(c *gin.Context) {
 func (h *APILogHandler) CreateBatchLogs(c *gin.Context) {
 	var req CreateBatchLogsRequest
 	if err := c.ShouldBindJSON(&req); err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		respondBindError(c, err)
 		return
 	}
 
-	// Get project info from middleware
-	projectID, _ := c.Get("project_id")
-	environment, _ := c.Get("environment")
+	if !h.checkQuota(c) {
+		return
+	}
 
-	successCount := 0
-	failedCount := 0
-	errors := []string{}
+	entries := make([]*domain.LogEntry, len(req.Logs))
+	for i := range req.Logs {
+		logReq := &req.Logs[i]
+		entries[i] = newLogEntry(c, logReq)
 
-	for _, logReq := range req.Logs {
-		// Handle user creation if requested
-		var userID *string
+		// Resolve the user by identifier if requested
 		if req.CreateUsers && logReq.UserIdentifier != "" {
-			// Try to get existing user
-			user, err := h.userService.GetUserByIdentifier(c.Request.Context(), logReq.UserIdentifier, projectID.(string))
-
-			switch err {
-			case domain.ErrUserNotFound:
-				// Create new user
-				newUser := &domain.User{
-					ID:         uuid.NewString(),
-					Identifier: logReq.UserIdentifier,
-					Name:       logReq.UserName,
-					ProjectID:  projectID.(string),
-				}
-
-				if err := h.userService.CreateUser(c.Request.Context(), newUser); err == nil {
-					userID = &newUser.ID
-				}
-				// If creation fails, continue without user ID
-			case nil:
-				// User found
-				userID = &user.ID
-			}
-			// If error is something else, continue without user ID
-		} else if logReq.UserID != nil {
-			userID = logReq.UserID
-		}
-
-		// Create core log
-		log := &domain.APILog{
-			ProjectID:     projectID.(string),
-			Environment:   domain.Environment(environment.(string)),
-			Method:        domain.HTTPMethod(logReq.Method),
-			Path:          logReq.Path,
-			Params:        logReq.Params,
-			QueryParams:   logReq.QueryParams,
-			StatusCode:    logReq.StatusCode,
-			ResponseTime:  logReq.ResponseTime,
-			ContentLength: logReq.ContentLength,
-			IPAddress:     logReq.IPAddress,
-			UserAgent:     logReq.UserAgent,
-			ErrorMessage:  logReq.ErrorMessage,
-			UserID:        userID,
-		}
-
-		// If IP not provided, get from request
-		if log.IPAddress == "" {
-			log.IPAddress = c.ClientIP()
-		}
-
-		// If UserAgent not provided, get from request
-		if log.UserAgent == "" {
-			log.UserAgent = c.Request.UserAgent()
-		}
-
-		// Create headers object if provided
-		var headers *domain.APILogHeaders
-		if len(logReq.RequestHeaders) > 0 || len(logReq.ResponseHeaders) > 0 {
-			headers = &domain.APILogHeaders{
-				RequestHeaders:  logReq.RequestHeaders,
-				ResponseHeaders: logReq.ResponseHeaders,
-			}
+			entries[i].Log.UserID = nil
+			entries[i].UserIdentifier = logReq.UserIdentifier
+			entries[i].UserName = logReq.UserName
 		}
+	}
 
-		// Create body object if provided
-		var body *domain.APILogBody
-		// if len(logReq.RequestBody) > 0 || len(logReq.ResponseBody) > 0 {
-		// 	body = &domain.APILogBody{
-		// 		RequestBody:  logReq.RequestBody,
-		// 		ResponseBody: logReq.ResponseBody,
-		// 	}
-		// }
-		if logReq.RequestBody != nil || logReq.ResponseBody != nil {
-			body = &domain.APILogBody{
-				RequestBody:  logReq.RequestBody,
-				ResponseBody: logReq.ResponseBody,
-			}
-		}
+	h.queueBatch(c, "logs", entries)
+}
 
-		// Create log with headers and body
-		if err := h.logService.CreateLog(c.Request.Context(), log, headers, body); err != nil {
-			failedCount++
-			errors = append(errors, err.Error())
-		} else {
-			successCount++
-		}
+// queueBatch queues a batch of entries and reports the outcome of each, in
+// order; field names the list the entries came from in the error messages
+func (h *APILogHandler) queueBatch(c *gin.Context, field string, entries []*domain.LogEntry) {
+	errs, err := h.ingestService.Enqueue(c.Request.Context(), entries)
+	if err != nil {
+		h.respondEnqueueError(c, err)
+		return
 	}
 
 	response := BatchLogResponse{
-		SuccessCount: successCount,
-		FailedCount:  failedCount,
-		Total:        len(req.Logs),
+		Total:   len(entries),
+		Results: make([]BatchLogResult, len(entries)),
 	}
-
-	if len(errors) > 0 {
-		response.Errors = errors
+	for i, entryErr := range errs {
+		result := BatchLogResult{Index: i}
+		switch entryErr {
+		case nil:
+			result.Status = "accepted"
+			result.ID = entries[i].Log.ID
+			result.RejectedFields = entries[i].RejectedFields
+			response.SuccessCount++
+		case domain.ErrDuplicateLog:
+			result.Status = "duplicate"
+			result.ID = entries[i].Log.ID
+			response.SuccessCount++
+			response.DuplicateCount++
+		default:
+			result.Status = "failed"
+			result.Error = entryErr.Error()
+			result.RejectedFields = rejectedFields(entryErr)
+			response.FailedCount++
+			response.Errors = append(response.Errors, fmt.Sprintf("%s[%d]: %s", field, i, entryErr))
+		}
+		response.Results[i] = result
 	}
 
-	statusCode := http.StatusCreated
-	if failedCount > 0 {
-		if successCount == 0 {
-			statusCode = http.StatusInternalServerError
+	statusCode := http.StatusAccepted
+	if response.FailedCount > 0 {
+		if response.SuccessCount == 0 {
+			statusCode = http.StatusBadRequest
 		} else {
 			statusCode = http.StatusPartialContent
 		}
@@ -534,3 +679,37 @@ func (h *APILogHandler) CreateBatchLogs(c *gin.Context) {
 
 	c.JSON(statusCode, gin.H{"data": response})
 }
+
+// rejectedFields returns the fields that failed a log, if the error names them
+func rejectedFields(err error) []domain.FieldError {
+	if validationErr, ok := err.(*domain.ValidationError); ok {
+		return validationErr.Fields
+	}
+	return nil
+}
+
+// respondEnqueueError reports a batch the ingestion pipeline did not accept.
+// A full or stopping queue is temporary, so clients are asked to retry.
+func (h *APILogHandler) respondEnqueueError(c *gin.Context, err error) {
+	c.JSON(enqueueErrorResponse(c, err))
+}
+
+// enqueueErrorResponse returns the status and body reporting a batch the
+// ingestion pipeline did not accept
+func enqueueErrorResponse(c *gin.Context, err error) (int, gin.H) {
+	if _, ok := err.(*domain.QuotaExceededError); ok {
+		return quotaErrorResponse(c, err)
+	}
+
+	switch err {
+	case domain.ErrForbidden:
+		return http.StatusForbidden, gin.H{"error": "Forbidden"}
+	case domain.ErrIngestQueueFull, domain.ErrIngestSpoolFull, domain.ErrIngestStopped:
+		c.Header("Retry-After", "1")
+		return http.StatusServiceUnavailable, gin.H{"error": "Ingestion is overloaded, retry later", "details": err.Error()}
+	case domain.ErrIngestTooLarge:
+		return http.StatusRequestEntityTooLarge, gin.H{"error": "Batch too large, send it in smaller batches", "details": err.Error()}
+	default:
+		return http.StatusInternalServerError, gin.H{"error": "Failed to accept logs", "details": err.Error()}
+	}
+}
@@ -0,0 +1,126 @@
+package http
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/spidey52/api-logs/internal/domain"
+	"github.com/spidey52/api-logs/internal/ports/input"
+)
+
+// restoreTimeout bounds a restore request, which outlives the server's write
+// timeout
+const restoreTimeout = 10 * time.Minute
+
+// ArchiveHandler handles HTTP requests for the archive of expired project data
+type ArchiveHandler struct {
+	archiveService input.ArchiveService
+}
+
+// NewArchiveHandler creates a new instance of ArchiveHandler
+func NewArchiveHandler(archiveService input.ArchiveService) *ArchiveHandler {
+	return &ArchiveHandler{
+		archiveService: archiveService,
+	}
+}
+
+// RestoreArchiveRequest represents the request body for restoring archived
+// logs. Days are UTC dates (2006-01-02); To defaults to From, and the target
+// project to the archived one.
+type RestoreArchiveRequest struct {
+	From            string `json:"from" binding:"required"`
+	To              string `json:"to"`
+	TargetProjectID string `json:"target_project_id"`
+}
+
+// List handles GET /api/v1/projects/:id/archive?from=2006-01-02&to=2006-01-02
+func (h *ArchiveHandler) List(c *gin.Context) {
+	from, to, err := parseDayRange(c.Query("from"), c.Query("to"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid input", "details": err.Error()})
+		return
+	}
+
+	objects, err := h.archiveService.List(c.Request.Context(), c.Param("id"), from, to)
+	if err != nil {
+		h.respondError(c, err, "Failed to list archive")
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"data": objects})
+}
+
+// Restore handles POST /api/v1/projects/:id/archive/restore
+func (h *ArchiveHandler) Restore(c *gin.Context) {
+	var req RestoreArchiveRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	if req.To == "" {
+		req.To = req.From
+	}
+	from, to, err := parseDayRange(req.From, req.To)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid input", "details": err.Error()})
+		return
+	}
+
+	restore := domain.ArchiveRestore{
+		SourceProjectID: c.Param("id"),
+		ProjectID:       req.TargetProjectID,
+		From:            from,
+		To:              to,
+	}
+	if restore.ProjectID == "" {
+		restore.ProjectID = restore.SourceProjectID
+	}
+
+	_ = http.NewResponseController(c.Writer).SetWriteDeadline(time.Now().Add(restoreTimeout))
+	ctx, cancel := context.WithTimeout(c.Request.Context(), restoreTimeout)
+	defer cancel()
+
+	result, err := h.archiveService.Restore(ctx, restore)
+	if err != nil {
+		h.respondError(c, err, "Failed to restore archive")
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"data": result})
+}
+
+func (h *ArchiveHandler) respondError(c *gin.Context, err error, message string) {
+	switch {
+	case err == domain.ErrProjectNotFound:
+		c.JSON(http.StatusNotFound, gin.H{"error": "Project not found"})
+	case err == domain.ErrForbidden:
+		c.JSON(http.StatusForbidden, gin.H{"error": "Forbidden"})
+	case err == domain.ErrArchiveDisabled:
+		c.JSON(http.StatusNotImplemented, gin.H{"error": "Archiving is not enabled"})
+	case errors.Is(err, domain.ErrInvalidArchiveRange):
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid input", "details": err.Error()})
+	default:
+		c.JSON(http.StatusInternalServerError, gin.H{"error": message, "details": err.Error()})
+	}
+}
+
+// parseDayRange parses a range of UTC dates. A missing from starts at the
+// earliest day, and a missing to ends today.
+func parseDayRange(fromDate, toDate string) (time.Time, time.Time, error) {
+	from, to := time.Unix(0, 0).UTC(), time.Now().UTC()
+	var err error
+	if fromDate != "" {
+		if from, err = time.Parse(time.DateOnly, fromDate); err != nil {
+			return from, to, errors.New("from must be a date (2006-01-02)")
+		}
+	}
+	if toDate != "" {
+		if to, err = time.Parse(time.DateOnly, toDate); err != nil {
+			return from, to, errors.New("to must be a date (2006-01-02)")
+		}
+	}
+	return from, to, nil
+}
@@ -0,0 +1,197 @@
+package http
+
+import (
+	"net/http"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+	"github.com/spidey52/api-logs/internal/domain"
+	"github.com/spidey52/api-logs/internal/ports/input"
+)
+
+// AuthHandler handles HTTP requests for dashboard authentication and accounts
+type AuthHandler struct {
+	authService input.AuthService
+}
+
+// NewAuthHandler creates a new instance of AuthHandler
+func NewAuthHandler(authService input.AuthService) *AuthHandler {
+	return &AuthHandler{
+		authService: authService,
+	}
+}
+
+// LoginRequest represents the request body for logging in
+type LoginRequest struct {
+	Username string `json:"username" binding:"required"`
+	Password string `json:"password" binding:"required"`
+}
+
+// CreateAccountRequest represents the request body for creating an account
+type CreateAccountRequest struct {
+	Username string `json:"username" binding:"required"`
+	Password string `json:"password" binding:"required"`
+	IsAdmin  bool   `json:"is_admin"`
+}
+
+// ChangePasswordRequest represents the request body for changing the current account's password
+type ChangePasswordRequest struct {
+	CurrentPassword string `json:"current_password" binding:"required"`
+	NewPassword     string `json:"new_password" binding:"required"`
+}
+
+// bearerToken extracts the token from an "Authorization: Bearer <token>" header
+func bearerToken(c *gin.Context) string {
+	header := c.GetHeader("Authorization")
+	scheme, token, found := strings.Cut(header, " ")
+	if !found || !strings.EqualFold(scheme, "Bearer") {
+		return ""
+	}
+	return strings.TrimSpace(token)
+}
+
+// AuthMiddleware requires a valid session bearer token
+func (h *AuthHandler) AuthMiddleware() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		token := bearerToken(c)
+		if token == "" {
+			c.JSON(http.StatusUnauthorized, gin.H{"error": "Bearer token is required"})
+			c.Abort()
+			return
+		}
+
+		account, err := h.authService.Authenticate(c.Request.Context(), token)
+		if err != nil {
+			if err == domain.ErrUnauthorized {
+				c.JSON(http.StatusUnauthorized, gin.H{"error": "Unauthorized"})
+			} else {
+				c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to authenticate", "details": err.Error()})
+			}
+			c.Abort()
+			return
+		}
+
+		// Store account info in context; services read the principal from the request context
+		c.Set("account", account)
+		c.Set("account_id", account.ID)
+		c.Request = c.Request.WithContext(domain.ContextWithPrincipal(c.Request.Context(), domain.AccountPrincipal(account)))
+
+		c.Next()
+	}
+}
+
+// Login handles POST /api/v1/auth/login
+func (h *AuthHandler) Login(c *gin.Context) {
+	var req LoginRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	session, token, account, err := h.authService.Login(c.Request.Context(), req.Username, req.Password)
+	if err != nil {
+		if err == domain.ErrInvalidCredentials {
+			c.JSON(http.StatusUnauthorized, gin.H{"error": "Invalid username or password"})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to log in", "details": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"data": gin.H{
+			"token":      token,
+			"expires_at": session.ExpiresAt,
+			"account":    account,
+		},
+	})
+}
+
+// Logout handles POST /api/v1/auth/logout
+func (h *AuthHandler) Logout(c *gin.Context) {
+	if err := h.authService.Logout(c.Request.Context(), bearerToken(c)); err != nil {
+		if err == domain.ErrUnauthorized {
+			c.JSON(http.StatusUnauthorized, gin.H{"error": "Unauthorized"})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to log out", "details": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusNoContent, nil)
+}
+
+// Me handles GET /api/v1/auth/me
+func (h *AuthHandler) Me(c *gin.Context) {
+	account, _ := c.Get("account")
+	c.JSON(http.StatusOK, gin.H{"data": account})
+}
+
+// ChangePassword handles PUT /api/v1/auth/password
+func (h *AuthHandler) ChangePassword(c *gin.Context) {
+	var req ChangePasswordRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	err := h.authService.ChangePassword(c.Request.Context(), c.GetString("account_id"), req.CurrentPassword, req.NewPassword)
+	if err != nil {
+		if err == domain.ErrInvalidCredentials {
+			c.JSON(http.StatusUnauthorized, gin.H{"error": "Current password is incorrect"})
+			return
+		}
+		if err == domain.ErrWeakPassword {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid input", "details": err.Error()})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to change password", "details": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusNoContent, nil)
+}
+
+// CreateAccount handles POST /api/v1/accounts
+func (h *AuthHandler) CreateAccount(c *gin.Context) {
+	var req CreateAccountRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	account, err := h.authService.CreateAccount(c.Request.Context(), req.Username, req.Password, req.IsAdmin)
+	if err != nil {
+		if err == domain.ErrForbidden {
+			c.JSON(http.StatusForbidden, gin.H{"error": "Only admins can create accounts"})
+			return
+		}
+		if err == domain.ErrDuplicateUsername {
+			c.JSON(http.StatusConflict, gin.H{"error": "Username already exists"})
+			return
+		}
+		if err == domain.ErrWeakPassword || err == domain.ErrInvalidUsername {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid input", "details": err.Error()})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to create account", "details": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusCreated, gin.H{"data": account})
+}
+
+// ListAccounts handles GET /api/v1/accounts
+func (h *AuthHandler) ListAccounts(c *gin.Context) {
+	accounts, err := h.authService.ListAccounts(c.Request.Context())
+	if err != nil {
+		if err == domain.ErrForbidden {
+			c.JSON(http.StatusForbidden, gin.H{"error": "Only admins can list accounts"})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to retrieve accounts"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"data": accounts})
+}
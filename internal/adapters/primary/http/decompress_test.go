@@ -0,0 +1,92 @@
+package http
+
+import (
+	"bytes"
+	"compress/gzip"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/klauspost/compress/zstd"
+)
+
+func gzipped(t *testing.T, data []byte) []byte {
+	t.Helper()
+	var buf bytes.Buffer
+	w := gzip.NewWriter(&buf)
+	if _, err := w.Write(data); err != nil {
+		t.Fatal(err)
+	}
+	if err := w.Close(); err != nil {
+		t.Fatal(err)
+	}
+	return buf.Bytes()
+}
+
+func zstded(t *testing.T, data []byte) []byte {
+	t.Helper()
+	w, err := zstd.NewWriter(nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer w.Close()
+	return w.EncodeAll(data, nil)
+}
+
+func TestDecompressBody(t *testing.T) {
+	const limit = 1024
+	payload := []byte(`{"method":"GET","path":"/items","status_code":200}`)
+	large := bytes.Repeat([]byte("a"), limit+1)
+
+	tests := []struct {
+		name       string
+		encoding   string
+		body       []byte
+		wantStatus int
+		wantBody   string
+	}{
+		{name: "identity", body: payload, wantStatus: http.StatusOK, wantBody: string(payload)},
+		{name: "gzip", encoding: "gzip", body: gzipped(t, payload), wantStatus: http.StatusOK, wantBody: string(payload)},
+		{name: "zstd", encoding: "zstd", body: zstded(t, payload), wantStatus: http.StatusOK, wantBody: string(payload)},
+		{name: "encoding in upper case", encoding: " GZIP ", body: gzipped(t, payload), wantStatus: http.StatusOK, wantBody: string(payload)},
+		{name: "invalid gzip", encoding: "gzip", body: payload, wantStatus: http.StatusBadRequest},
+		{name: "corrupt zstd", encoding: "zstd", body: payload, wantStatus: http.StatusBadRequest},
+		{name: "unsupported encoding", encoding: "br", body: payload, wantStatus: http.StatusUnsupportedMediaType},
+		{name: "identity over the limit", body: large, wantStatus: http.StatusRequestEntityTooLarge},
+		{name: "gzip expanding over the limit", encoding: "gzip", body: gzipped(t, large), wantStatus: http.StatusRequestEntityTooLarge},
+		{name: "zstd expanding over the limit", encoding: "zstd", body: zstded(t, large), wantStatus: http.StatusRequestEntityTooLarge},
+	}
+
+	router := gin.New()
+	router.POST("/", DecompressBody(limit), func(c *gin.Context) {
+		if encoding := c.GetHeader("Content-Encoding"); encoding != "" {
+			t.Errorf("Content-Encoding %q left on the decoded request", encoding)
+		}
+		body, err := io.ReadAll(c.Request.Body)
+		if err != nil {
+			respondBindError(c, err)
+			return
+		}
+		c.Data(http.StatusOK, "application/octet-stream", body)
+	})
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := httptest.NewRequest(http.MethodPost, "/", bytes.NewReader(tt.body))
+			if tt.encoding != "" {
+				req.Header.Set("Content-Encoding", tt.encoding)
+			}
+			w := httptest.NewRecorder()
+			router.ServeHTTP(w, req)
+
+			if w.Code != tt.wantStatus {
+				t.Fatalf("want status %d, got %d: %s", tt.wantStatus, w.Code, w.Body)
+			}
+			if tt.wantBody != "" && w.Body.String() != tt.wantBody {
+				t.Fatalf("want body %q, got %q", tt.wantBody, w.Body)
+			}
+		})
+	}
+}
@@ -0,0 +1,363 @@
+package http
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"math"
+	"mime"
+	"net/http"
+	"net/url"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/spidey52/api-logs/internal/domain"
+	"github.com/spidey52/api-logs/pkg/har"
+)
+
+// harCreatorName names this service as the creator of exported HAR documents
+const harCreatorName = "api-logs"
+
+// ImportHAR handles POST /api/v1/logs/import/har. The body is a HAR document,
+// as saved from a browser's developer tools; each entry becomes a log with
+// its headers and bodies. The response reports each entry like a batch.
+func (h *APILogHandler) ImportHAR(c *gin.Context) {
+	var document har.HAR
+	if err := json.NewDecoder(c.Request.Body).Decode(&document); err != nil {
+		respondBindError(c, err)
+		return
+	}
+	if len(document.Log.Entries) == 0 {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "HAR file contains no entries"})
+		return
+	}
+
+	if !h.checkQuota(c) {
+		return
+	}
+
+	entries := make([]*domain.LogEntry, len(document.Log.Entries))
+	for i := range document.Log.Entries {
+		entries[i] = harLogEntry(c, &document.Log.Entries[i], i)
+	}
+
+	h.queueBatch(c, "entries", entries)
+}
+
+// ExportHAR handles GET /api/v1/logs/export/har. It takes the filters of
+// ListLogs and returns the logs it would list as a HAR 1.2 document, with
+// their headers and bodies.
+func (h *APILogHandler) ExportHAR(c *gin.Context) {
+	entries, err := h.logService.ListLogDetails(c.Request.Context(), logFilter(c))
+	if err != nil {
+		if err == domain.ErrForbidden {
+			c.JSON(http.StatusForbidden, gin.H{"error": "Forbidden"})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to retrieve logs", "details": err.Error()})
+		return
+	}
+
+	document := har.HAR{Log: har.Log{
+		Version: har.Version,
+		Creator: har.Creator{Name: harCreatorName, Version: har.Version},
+		Entries: make([]har.Entry, len(entries)),
+	}}
+	for i, entry := range entries {
+		document.Log.Entries[i] = harEntry(entry)
+	}
+
+	filename := "logs-" + time.Now().UTC().Format("20060102-150405") + ".har"
+	c.Header("Content-Disposition", `attachment; filename="`+filename+`"`)
+	c.JSON(http.StatusOK, document)
+}
+
+// harLogEntry converts a HAR entry into a log entry for the project the
+// request was authenticated for
+func harLogEntry(c *gin.Context, entry *har.Entry, index int) *domain.LogEntry {
+	request, response := &entry.Request, &entry.Response
+
+	log := &domain.APILog{
+		ProjectID:    c.GetString("project_id"),
+		Environment:  domain.Environment(c.GetString("environment")),
+		Method:       domain.HTTPMethod(strings.ToUpper(request.Method)),
+		StatusCode:   response.Status,
+		ResponseTime: int64(math.Round(math.Max(entry.Time, 0))),
+		ErrorMessage: response.Error,
+	}
+
+	requestHeaders := harHeaders(request.Headers)
+	if requestURL, err := url.Parse(request.URL); err == nil {
+		log.Path = requestURL.Path
+		log.QueryParams = queryParams(requestURL.RawQuery)
+
+		// HTTP/2 requests carry the host in a pseudo-header; keeping it as Host
+		// lets the export rebuild the URL
+		if requestURL.Host != "" && headerValue(requestHeaders, "Host") == "" {
+			if requestHeaders == nil {
+				requestHeaders = map[string]any{}
+			}
+			requestHeaders["Host"] = requestURL.Host
+		}
+	}
+	if len(request.QueryString) > 0 {
+		log.QueryParams = make(map[string]string, len(request.QueryString))
+		for _, param := range request.QueryString {
+			if _, ok := log.QueryParams[param.Name]; !ok {
+				log.QueryParams[param.Name] = param.Value
+			}
+		}
+	}
+
+	if response.Content.Size > 0 {
+		log.ContentLength = response.Content.Size
+	} else if response.BodySize > 0 {
+		log.ContentLength = response.BodySize
+	}
+
+	if startedAt, err := time.Parse(time.RFC3339Nano, entry.StartedDateTime); err == nil {
+		log.Timestamp = startedAt
+	}
+
+	log.UserAgent = headerValue(requestHeaders, "User-Agent")
+
+	// Uploading a file again stores each entry once
+	sum := sha256.Sum256([]byte(entry.StartedDateTime + "\x00" + request.Method + "\x00" + request.URL))
+	logEntry := &domain.LogEntry{Log: log, IdempotencyKey: fmt.Sprintf("har:%d:%x", index, sum[:16])}
+
+	responseHeaders := harHeaders(response.Headers)
+	if len(requestHeaders) > 0 || len(responseHeaders) > 0 {
+		logEntry.Headers = &domain.APILogHeaders{
+			RequestHeaders:  requestHeaders,
+			ResponseHeaders: responseHeaders,
+		}
+	}
+
+	var requestBody, responseBody any
+	if request.PostData != nil && request.PostData.Text != "" {
+		requestBody = harBody(request.PostData.Text, request.PostData.MimeType)
+	}
+	if content := response.Content; content.Text != "" {
+		// Binary bodies (images, fonts) are not kept
+		text := content.Text
+		if content.Encoding == "base64" {
+			text = ""
+			if decoded, err := base64.StdEncoding.DecodeString(content.Text); err == nil && isText(content.MimeType) {
+				text = string(decoded)
+			}
+		}
+		if text != "" {
+			responseBody = harBody(text, content.MimeType)
+		}
+	}
+	if requestBody != nil || responseBody != nil {
+		logEntry.Body = &domain.APILogBody{
+			RequestBody:  requestBody,
+			ResponseBody: responseBody,
+		}
+	}
+
+	return logEntry
+}
+
+// harHeaders converts HAR headers into the map the SDK stores: a string per
+// header, or a list for repeated headers. HTTP/2 pseudo-headers are dropped.
+func harHeaders(headers []har.NameValue) map[string]any {
+	if len(headers) == 0 {
+		return nil
+	}
+
+	converted := make(map[string]any, len(headers))
+	for _, header := range headers {
+		if strings.HasPrefix(header.Name, ":") {
+			continue
+		}
+		switch existing := converted[header.Name].(type) {
+		case nil:
+			converted[header.Name] = header.Value
+		case string:
+			converted[header.Name] = []any{existing, header.Value}
+		case []any:
+			converted[header.Name] = append(existing, header.Value)
+		}
+	}
+	return converted
+}
+
+// harBody decodes a JSON body, keeping other bodies as text
+func harBody(text, mimeType string) any {
+	if isJSON(mimeType) {
+		var decoded any
+		if err := json.Unmarshal([]byte(text), &decoded); err == nil {
+			return decoded
+		}
+	}
+	return text
+}
+
+// harEntry converts a stored log into a HAR entry. Logs do not record the
+// scheme and host, so the URL is rebuilt from the Host and X-Forwarded-Proto
+// request headers, falling back to http://localhost.
+func harEntry(entry *domain.LogEntry) har.Entry {
+	log := entry.Log
+
+	var requestHeaders, responseHeaders map[string]any
+	if entry.Headers != nil {
+		requestHeaders, responseHeaders = entry.Headers.RequestHeaders, entry.Headers.ResponseHeaders
+	}
+	var requestBody, responseBody any
+	if entry.Body != nil {
+		requestBody, responseBody = entry.Body.RequestBody, entry.Body.ResponseBody
+	}
+
+	scheme := headerValue(requestHeaders, "X-Forwarded-Proto")
+	if scheme == "" {
+		scheme = "http"
+	}
+	host := headerValue(requestHeaders, "Host")
+	if host == "" {
+		host = "localhost"
+	}
+
+	query := url.Values{}
+	queryString := []har.NameValue{}
+	for _, name := range sortedKeys(log.QueryParams) {
+		query.Set(name, log.QueryParams[name])
+		queryString = append(queryString, har.NameValue{Name: name, Value: log.QueryParams[name]})
+	}
+	requestURL := url.URL{Scheme: scheme, Host: host, Path: log.Path, RawQuery: query.Encode()}
+
+	request := har.Request{
+		Method:      string(log.Method),
+		URL:         requestURL.String(),
+		HTTPVersion: "HTTP/1.1",
+		Cookies:     []har.Cookie{},
+		Headers:     harNameValues(requestHeaders),
+		QueryString: queryString,
+		HeadersSize: -1,
+		BodySize:    -1,
+	}
+	if requestBody != nil {
+		text, mimeType := harText(requestBody, headerValue(requestHeaders, "Content-Type"))
+		request.PostData = &har.PostData{MimeType: mimeType, Text: text}
+		request.BodySize = int64(len(text))
+	}
+
+	response := har.Response{
+		Status:      log.StatusCode,
+		StatusText:  http.StatusText(log.StatusCode),
+		HTTPVersion: "HTTP/1.1",
+		Cookies:     []har.Cookie{},
+		Headers:     harNameValues(responseHeaders),
+		Content:     har.Content{Size: log.ContentLength, MimeType: headerValue(responseHeaders, "Content-Type")},
+		HeadersSize: -1,
+		BodySize:    -1,
+		Error:       log.ErrorMessage,
+	}
+	if responseBody != nil {
+		response.Content.Text, response.Content.MimeType = harText(responseBody, response.Content.MimeType)
+		if response.Content.Size == 0 {
+			response.Content.Size = int64(len(response.Content.Text))
+		}
+	}
+	if location := headerValue(responseHeaders, "Location"); location != "" {
+		response.RedirectURL = location
+	}
+
+	// Only the total time is known, so it is all spent waiting
+	return har.Entry{
+		StartedDateTime: log.Timestamp.UTC().Format(time.RFC3339Nano),
+		Time:            float64(log.ResponseTime),
+		Request:         request,
+		Response:        response,
+		Timings:         har.Timings{Wait: float64(log.ResponseTime)},
+		ID:              log.ID,
+	}
+}
+
+// harNameValues converts stored headers into HAR headers, one per value,
+// sorted by name
+func harNameValues(headers map[string]any) []har.NameValue {
+	converted := []har.NameValue{}
+	for _, name := range sortedKeys(headers) {
+		switch value := headers[name].(type) {
+		case string:
+			converted = append(converted, har.NameValue{Name: name, Value: value})
+		case []any:
+			for _, v := range value {
+				converted = append(converted, har.NameValue{Name: name, Value: fmt.Sprint(v)})
+			}
+		case nil:
+		default:
+			converted = append(converted, har.NameValue{Name: name, Value: fmt.Sprint(value)})
+		}
+	}
+	return converted
+}
+
+// harText encodes a stored body as text. Decoded JSON is encoded again, and
+// reported as JSON when the headers did not say otherwise.
+func harText(body any, mimeType string) (string, string) {
+	if text, ok := body.(string); ok {
+		if mimeType == "" {
+			mimeType = "text/plain"
+		}
+		return text, mimeType
+	}
+
+	var buf bytes.Buffer
+	encoder := json.NewEncoder(&buf)
+	encoder.SetEscapeHTML(false)
+	_ = encoder.Encode(body)
+	if mimeType == "" {
+		mimeType = "application/json"
+	}
+	return strings.TrimSuffix(buf.String(), "\n"), mimeType
+}
+
+// headerValue returns the first value of a stored header, matching its name
+// case-insensitively
+func headerValue(headers map[string]any, name string) string {
+	for key, value := range headers {
+		if !strings.EqualFold(key, name) {
+			continue
+		}
+		switch v := value.(type) {
+		case string:
+			return v
+		case []any:
+			if len(v) > 0 {
+				return fmt.Sprint(v[0])
+			}
+		}
+	}
+	return ""
+}
+
+// isJSON reports whether a MIME type is JSON, including suffixed types such
+// as application/problem+json
+func isJSON(mimeType string) bool {
+	mediaType, _, _ := mime.ParseMediaType(mimeType)
+	return mediaType == "application/json" || strings.HasSuffix(mediaType, "+json")
+}
+
+// isText reports whether a MIME type holds text
+func isText(mimeType string) bool {
+	mediaType, _, _ := mime.ParseMediaType(mimeType)
+	return strings.HasPrefix(mediaType, "text/") || isJSON(mimeType) ||
+		mediaType == "application/xml" || mediaType == "application/javascript" ||
+		mediaType == "application/x-www-form-urlencoded"
+}
+
+func sortedKeys[V any](m map[string]V) []string {
+	keys := make([]string, 0, len(m))
+	for key := range m {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	return keys
+}
@@ -0,0 +1,128 @@
+package http
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/spidey52/api-logs/internal/domain"
+	"github.com/spidey52/api-logs/pkg/har"
+)
+
+// testHAR is a HAR document as a browser saves it: an HTTP/2 JSON request, and
+// an image whose binary body is not kept
+const testHAR = `{"log":{"version":"1.2","creator":{"name":"WebInspector","version":"537.36"},"entries":[
+	{"startedDateTime":"2026-01-07T10:00:00.123Z","time":41.6,
+	 "request":{"method":"post","url":"https://api.example.com/items?page=2&sort=name","httpVersion":"h2",
+		"headers":[{"name":":authority","value":"api.example.com"},{"name":"Content-Type","value":"application/json"},
+			{"name":"Accept","value":"application/json"},{"name":"Accept","value":"text/plain"},{"name":"User-Agent","value":"Mozilla/5.0"}],
+		"queryString":[{"name":"page","value":"2"},{"name":"sort","value":"name"}],
+		"cookies":[],"headersSize":-1,"bodySize":13,
+		"postData":{"mimeType":"application/json","text":"{\"name\":\"a\"}"}},
+	 "response":{"status":201,"statusText":"Created","httpVersion":"h2",
+		"headers":[{"name":"Content-Type","value":"application/json"},{"name":"Location","value":"/items/1"}],
+		"cookies":[],"content":{"size":20,"mimeType":"application/json","text":"{\"id\":1,\"name\":\"a\"}"},
+		"redirectURL":"","headersSize":-1,"bodySize":20},
+	 "cache":{},"timings":{"send":1,"wait":40,"receive":0.6}},
+	{"startedDateTime":"2026-01-07T10:00:01Z","time":5,
+	 "request":{"method":"GET","url":"https://api.example.com/logo.png","httpVersion":"h2","headers":[],
+		"queryString":[],"cookies":[],"headersSize":-1,"bodySize":0},
+	 "response":{"status":200,"statusText":"OK","httpVersion":"h2","headers":[],"cookies":[],
+		"content":{"size":4,"mimeType":"image/png","text":"iVBORw==","encoding":"base64"},
+		"redirectURL":"","headersSize":-1,"bodySize":4},
+	 "cache":{},"timings":{"send":0,"wait":5,"receive":0}}
+]}}`
+
+func TestImportHAR(t *testing.T) {
+	ingest := &fakeIngest{}
+	handler := NewAPILogHandler(nil, nil, nil, &fakeQuota{allowed: -1}, ingest)
+	router := newTestIngestRouter("/logs/import/har", handler.ImportHAR)
+
+	if w := post(router, "/logs/import/har", "application/json", testHAR); w.Code != http.StatusAccepted {
+		t.Fatalf("want status 202, got %d: %s", w.Code, w.Body)
+	}
+	if len(ingest.entries) != 2 {
+		t.Fatalf("want 2 entries queued, got %d", len(ingest.entries))
+	}
+
+	entry := ingest.entries[0]
+	wantLog := &domain.APILog{
+		ProjectID:     "p1",
+		Environment:   domain.EnvironmentDev,
+		Method:        domain.MethodPOST,
+		Path:          "/items",
+		QueryParams:   map[string]string{"page": "2", "sort": "name"},
+		StatusCode:    201,
+		ResponseTime:  42,
+		ContentLength: 20,
+		UserAgent:     "Mozilla/5.0",
+		Timestamp:     time.Date(2026, 1, 7, 10, 0, 0, 123000000, time.UTC),
+	}
+	if !reflect.DeepEqual(entry.Log, wantLog) {
+		t.Fatalf("log mismatch:\ngot  %+v\nwant %+v", entry.Log, wantLog)
+	}
+	wantHeaders := map[string]any{
+		"Host":         "api.example.com",
+		"Content-Type": "application/json",
+		"Accept":       []any{"application/json", "text/plain"},
+		"User-Agent":   "Mozilla/5.0",
+	}
+	if !reflect.DeepEqual(entry.Headers.RequestHeaders, wantHeaders) {
+		t.Fatalf("request headers mismatch: %+v", entry.Headers.RequestHeaders)
+	}
+	wantBody := &domain.APILogBody{
+		RequestBody:  map[string]any{"name": "a"},
+		ResponseBody: map[string]any{"id": float64(1), "name": "a"},
+	}
+	if !reflect.DeepEqual(entry.Body, wantBody) {
+		t.Fatalf("body mismatch: %+v", entry.Body)
+	}
+
+	if image := ingest.entries[1]; image.Body != nil || image.Headers == nil || image.Headers.RequestHeaders["Host"] != "api.example.com" {
+		t.Fatalf("want the binary body dropped and the host kept, got %+v", image)
+	}
+	if entry.IdempotencyKey == "" || entry.IdempotencyKey == ingest.entries[1].IdempotencyKey {
+		t.Fatalf("want distinct idempotency keys, got %q and %q", entry.IdempotencyKey, ingest.entries[1].IdempotencyKey)
+	}
+}
+
+func TestHARRoundTrip(t *testing.T) {
+	ingest := &fakeIngest{}
+	handler := NewAPILogHandler(nil, nil, nil, &fakeQuota{allowed: -1}, ingest)
+	router := newTestIngestRouter("/logs/import/har", handler.ImportHAR)
+	if w := post(router, "/logs/import/har", "application/json", testHAR); w.Code != http.StatusAccepted {
+		t.Fatalf("want status 202, got %d: %s", w.Code, w.Body)
+	}
+
+	stored := ingest.entries[0]
+	stored.Log.ID = "log-1"
+	exported := harEntry(stored)
+
+	// Exporting the stored log and importing it again keeps what was logged
+	c, _ := gin.CreateTestContext(httptest.NewRecorder())
+	c.Set("project_id", "p1")
+	c.Set("environment", string(domain.EnvironmentDev))
+	again := harLogEntry(c, &exported, 0)
+	again.Log.ID = stored.Log.ID
+	if !reflect.DeepEqual(again.Log, stored.Log) || !reflect.DeepEqual(again.Headers, stored.Headers) || !reflect.DeepEqual(again.Body, stored.Body) {
+		t.Fatalf("round trip mismatch:\ngot  %+v %+v %+v\nwant %+v %+v %+v",
+			again.Log, again.Headers, again.Body, stored.Log, stored.Headers, stored.Body)
+	}
+
+	if exported.Request.URL != "http://api.example.com/items?page=2&sort=name" {
+		t.Fatalf("unexpected URL %q", exported.Request.URL)
+	}
+	if exported.Request.PostData == nil || exported.Request.PostData.Text != `{"name":"a"}` {
+		t.Fatalf("unexpected request body %+v", exported.Request.PostData)
+	}
+	wantAccept := []har.NameValue{{Name: "Accept", Value: "application/json"}, {Name: "Accept", Value: "text/plain"}}
+	if !reflect.DeepEqual(exported.Request.Headers[:2], wantAccept) {
+		t.Fatalf("want repeated headers exported once per value, got %+v", exported.Request.Headers)
+	}
+	if exported.Response.RedirectURL != "/items/1" || exported.Response.StatusText != "Created" || exported.ID != "log-1" {
+		t.Fatalf("unexpected response %+v", exported.Response)
+	}
+}
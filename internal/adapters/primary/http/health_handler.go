@@ -0,0 +1,29 @@
+package http
+
+import (
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/spidey52/api-logs/internal/ports/input"
+)
+
+// HealthHandler reports the health of the service
+type HealthHandler struct {
+	ingestService input.IngestService
+}
+
+// NewHealthHandler creates a new instance of HealthHandler
+func NewHealthHandler(ingestService input.IngestService) *HealthHandler {
+	return &HealthHandler{
+		ingestService: ingestService,
+	}
+}
+
+// Health handles GET /health, including the depth and age of the logs
+// accepted but not yet stored
+func (h *HealthHandler) Health(c *gin.Context) {
+	c.JSON(http.StatusOK, gin.H{
+		"status": "ok",
+		"ingest": h.ingestService.Stats(),
+	})
+}
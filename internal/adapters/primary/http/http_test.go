@@ -0,0 +1,99 @@
+package http
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+	"github.com/spidey52/api-logs/internal/domain"
+	"github.com/spidey52/api-logs/internal/ports/input"
+)
+
+func init() {
+	gin.SetMode(gin.TestMode)
+}
+
+// fakeIngest accepts the entries it is given, failing those with method BAD
+// and reporting those with idempotency key "dup" as duplicates
+type fakeIngest struct {
+	entries []*domain.LogEntry
+}
+
+var _ input.IngestService = (*fakeIngest)(nil)
+
+func (f *fakeIngest) Enqueue(ctx context.Context, entries []*domain.LogEntry) ([]error, error) {
+	errs := make([]error, len(entries))
+	for i, entry := range entries {
+		switch {
+		case entry.Log.Method == "BAD":
+			errs[i] = domain.ErrInvalidInput
+		case entry.IdempotencyKey == "dup":
+			errs[i] = domain.ErrDuplicateLog
+		default:
+			f.entries = append(f.entries, entry)
+		}
+	}
+	return errs, nil
+}
+
+func (f *fakeIngest) Replay(ctx context.Context) (int, error) { return 0, nil }
+func (f *fakeIngest) Stats() domain.IngestStats               { return domain.IngestStats{} }
+func (f *fakeIngest) Drain(ctx context.Context) error         { return nil }
+
+// fakeQuota allows the first allowed requests and refuses the rest; a negative
+// allowed allows every request
+type fakeQuota struct {
+	allowed int
+}
+
+var _ input.QuotaService = (*fakeQuota)(nil)
+
+func (f *fakeQuota) CheckRequest(ctx context.Context, projectID, keyID string) error {
+	if f.allowed == 0 {
+		return &domain.QuotaExceededError{Limit: "requests_per_second"}
+	}
+	f.allowed--
+	return nil
+}
+
+func (f *fakeQuota) CountLogs(ctx context.Context, projectID, keyID string, logs int) error {
+	return nil
+}
+
+func (f *fakeQuota) ReleaseLogs(ctx context.Context, projectID, keyID string, logs int) error {
+	return nil
+}
+
+func (f *fakeQuota) GetQuota(ctx context.Context, projectID string) (*domain.ProjectQuota, error) {
+	return nil, nil
+}
+
+func (f *fakeQuota) GetUsage(ctx context.Context, projectID string) (*domain.QuotaUsage, error) {
+	return nil, nil
+}
+
+func (f *fakeQuota) SetQuota(ctx context.Context, quota *domain.ProjectQuota) error { return nil }
+func (f *fakeQuota) ResetQuota(ctx context.Context, projectID string) error         { return nil }
+
+// newTestIngestRouter routes path to handler for requests authenticated with
+// key k1 of project p1
+func newTestIngestRouter(path string, handler gin.HandlerFunc) *gin.Engine {
+	router := gin.New()
+	router.POST(path, func(c *gin.Context) {
+		c.Set("project_id", "p1")
+		c.Set("api_key_id", "k1")
+		c.Set("environment", string(domain.EnvironmentDev))
+	}, handler)
+	return router
+}
+
+// post sends body to the router with the content type
+func post(router *gin.Engine, path, contentType, body string) *httptest.ResponseRecorder {
+	req := httptest.NewRequest(http.MethodPost, path, strings.NewReader(body))
+	req.Header.Set("Content-Type", contentType)
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+	return w
+}
@@ -0,0 +1,80 @@
+package http
+
+import (
+	"crypto/sha256"
+	"fmt"
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+	"github.com/spidey52/api-logs/internal/domain"
+	"github.com/spidey52/api-logs/pkg/accesslog"
+)
+
+// ImportAccessLogs handles POST /api/v1/logs/import/access-log. The body is an
+// nginx or Apache access log in the format named by ?format= (common, or
+// combined by default) or given by ?log_format= in nginx log_format syntax.
+// Lines are parsed and queued like a stream; ?first_line= numbers them when a
+// file is resumed part way through.
+func (h *APILogHandler) ImportAccessLogs(maxLineSize int) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		logFormat := c.Query("log_format")
+		if logFormat == "" {
+			name := c.DefaultQuery("format", "combined")
+			var ok bool
+			if logFormat, ok = accesslog.Formats[name]; !ok {
+				c.JSON(http.StatusBadRequest, gin.H{"error": "Unknown log format", "details": "format must be common or combined"})
+				return
+			}
+		}
+
+		format, err := accesslog.Compile(logFormat)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid log format", "details": err.Error()})
+			return
+		}
+
+		firstLine, err := strconv.Atoi(c.DefaultQuery("first_line", "1"))
+		if err != nil || firstLine < 1 {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "first_line must be a positive integer"})
+			return
+		}
+
+		h.ingestLines(c, maxLineSize, firstLine, func(line []byte, number int) (*domain.LogEntry, error) {
+			entry, err := format.Parse(string(line))
+			if err != nil {
+				return nil, err
+			}
+			return accessLogEntry(c, entry, line, number), nil
+		})
+	}
+}
+
+// accessLogEntry converts a parsed access log line into a log entry for the
+// project the request was authenticated for
+func accessLogEntry(c *gin.Context, entry *accesslog.Entry, line []byte, number int) *domain.LogEntry {
+	log := &domain.APILog{
+		ProjectID:     c.GetString("project_id"),
+		Environment:   domain.Environment(c.GetString("environment")),
+		Method:        domain.HTTPMethod(entry.Method),
+		Path:          entry.Path,
+		QueryParams:   queryParams(entry.Query),
+		StatusCode:    entry.Status,
+		ResponseTime:  entry.RequestTime.Milliseconds(),
+		ContentLength: entry.BodyBytes,
+		IPAddress:     entry.RemoteAddr,
+		UserAgent:     entry.UserAgent,
+		Timestamp:     entry.Time,
+	}
+
+	// Importing a file again, or resuming it, stores each line once
+	sum := sha256.Sum256(line)
+	logEntry := &domain.LogEntry{Log: log, IdempotencyKey: fmt.Sprintf("access-log:%d:%x", number, sum[:16])}
+
+	if entry.Referer != "" {
+		logEntry.Headers = &domain.APILogHeaders{
+			RequestHeaders: map[string]any{"Referer": entry.Referer},
+		}
+	}
+	return logEntry
+}
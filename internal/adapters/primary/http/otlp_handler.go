@@ -0,0 +1,244 @@
+package http
+
+import (
+	"encoding/base64"
+	"encoding/hex"
+	"io"
+	"mime"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/spidey52/api-logs/internal/domain"
+	coltracepb "go.opentelemetry.io/proto/otlp/collector/trace/v1"
+	commonpb "go.opentelemetry.io/proto/otlp/common/v1"
+	tracepb "go.opentelemetry.io/proto/otlp/trace/v1"
+	"google.golang.org/protobuf/encoding/protojson"
+	"google.golang.org/protobuf/proto"
+)
+
+const (
+	otlpProtobufContentType = "application/x-protobuf"
+	otlpJSONContentType     = "application/json"
+
+	// otlpOtherMethod stands for a method the instrumentation does not know
+	otlpOtherMethod = "_OTHER"
+)
+
+// Semantic convention attributes of HTTP server spans, current names first
+var (
+	otlpMethodAttributes         = []string{"http.request.method", "http.method"}
+	otlpOriginalMethodAttributes = []string{"http.request.method_original"}
+	otlpRouteAttributes          = []string{"http.route"}
+	otlpPathAttributes           = []string{"url.path", "http.target"}
+	otlpQueryAttributes          = []string{"url.query"}
+	otlpStatusAttributes         = []string{"http.response.status_code", "http.status_code"}
+	otlpSizeAttributes           = []string{"http.response.body.size", "http.response_content_length"}
+	otlpClientAttributes         = []string{"client.address", "http.client_ip", "network.peer.address", "net.sock.peer.addr", "net.peer.ip"}
+	otlpUserAgentAttributes      = []string{"user_agent.original", "http.user_agent"}
+	otlpErrorTypeAttributes      = []string{"error.type"}
+)
+
+// ExportTraces handles POST /api/v1/otlp/v1/traces, the OTLP/HTTP traces
+// endpoint, so services instrumented with OpenTelemetry can send their traces
+// as they are. Server spans with HTTP attributes become logs; other spans are
+// ignored. The body is an ExportTraceServiceRequest, as protobuf or JSON.
+func (h *APILogHandler) ExportTraces(c *gin.Context) {
+	contentType, _, _ := mime.ParseMediaType(c.GetHeader("Content-Type"))
+	if contentType != otlpProtobufContentType && contentType != otlpJSONContentType {
+		c.JSON(http.StatusUnsupportedMediaType, gin.H{"error": "Content-Type must be " + otlpProtobufContentType + " or " + otlpJSONContentType})
+		return
+	}
+
+	body, err := io.ReadAll(c.Request.Body)
+	if err != nil {
+		respondBindError(c, err)
+		return
+	}
+
+	var traces coltracepb.ExportTraceServiceRequest
+	if contentType == otlpProtobufContentType {
+		err = proto.Unmarshal(body, &traces)
+	} else {
+		err = protojson.UnmarshalOptions{DiscardUnknown: true}.Unmarshal(body, &traces)
+	}
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid OTLP request", "details": err.Error()})
+		return
+	}
+
+	var entries []*domain.LogEntry
+	for _, resourceSpans := range traces.ResourceSpans {
+		for _, scopeSpans := range resourceSpans.ScopeSpans {
+			for _, span := range scopeSpans.Spans {
+				if entry := spanLogEntry(c, span); entry != nil {
+					entries = append(entries, entry)
+				}
+			}
+		}
+	}
+
+	if len(entries) == 0 {
+		respondExportTraces(c, contentType, 0, "")
+		return
+	}
+
+	if !h.checkQuota(c) {
+		return
+	}
+
+	errs, err := h.ingestService.Enqueue(c.Request.Context(), entries)
+	if err != nil {
+		h.respondEnqueueError(c, err)
+		return
+	}
+
+	var rejected int64
+	var message string
+	for _, entryErr := range errs {
+		if entryErr == nil || entryErr == domain.ErrDuplicateLog {
+			continue
+		}
+		if rejected == 0 {
+			message = entryErr.Error()
+		}
+		rejected++
+	}
+	respondExportTraces(c, contentType, rejected, message)
+}
+
+// spanLogEntry converts a server span into a log entry for the project the
+// request was authenticated for. It returns nil for spans that are not HTTP
+// server spans.
+func spanLogEntry(c *gin.Context, span *tracepb.Span) *domain.LogEntry {
+	if span.Kind != tracepb.Span_SPAN_KIND_SERVER {
+		return nil
+	}
+	attributes := span.Attributes
+
+	method := otlpString(attributes, otlpMethodAttributes)
+	if method == "" {
+		return nil
+	}
+	if method == otlpOtherMethod {
+		if original := otlpString(attributes, otlpOriginalMethodAttributes); original != "" {
+			method = original
+		}
+	}
+
+	target := otlpString(attributes, otlpPathAttributes)
+	path, query, _ := strings.Cut(target, "?")
+	if q := otlpString(attributes, otlpQueryAttributes); q != "" {
+		query = q
+	}
+	if route := otlpString(attributes, otlpRouteAttributes); route != "" {
+		path = route
+	}
+
+	log := &domain.APILog{
+		ProjectID:     c.GetString("project_id"),
+		Environment:   domain.Environment(c.GetString("environment")),
+		Method:        domain.HTTPMethod(strings.ToUpper(method)),
+		Path:          path,
+		QueryParams:   queryParams(query),
+		StatusCode:    int(otlpInt(attributes, otlpStatusAttributes)),
+		ContentLength: otlpInt(attributes, otlpSizeAttributes),
+		IPAddress:     otlpString(attributes, otlpClientAttributes),
+		UserAgent:     otlpString(attributes, otlpUserAgentAttributes),
+	}
+
+	if span.StartTimeUnixNano > 0 {
+		log.Timestamp = time.Unix(0, int64(span.StartTimeUnixNano)).UTC()
+		if span.EndTimeUnixNano > span.StartTimeUnixNano {
+			log.ResponseTime = time.Duration(span.EndTimeUnixNano - span.StartTimeUnixNano).Milliseconds()
+		}
+	}
+
+	if span.Status != nil && span.Status.Code == tracepb.Status_STATUS_CODE_ERROR {
+		log.ErrorMessage = span.Status.Message
+		if log.ErrorMessage == "" {
+			log.ErrorMessage = otlpString(attributes, otlpErrorTypeAttributes)
+		}
+	}
+
+	// Exporters retry whole requests, so each span is stored once
+	return &domain.LogEntry{
+		Log:            log,
+		IdempotencyKey: "otlp:" + otlpID(span.TraceId, 16) + ":" + otlpID(span.SpanId, 8),
+	}
+}
+
+// otlpString returns the first of the attributes that is set, as a string
+func otlpString(attributes []*commonpb.KeyValue, keys []string) string {
+	for _, key := range keys {
+		for _, attribute := range attributes {
+			if attribute.Key != key || attribute.Value == nil {
+				continue
+			}
+			switch value := attribute.Value.Value.(type) {
+			case *commonpb.AnyValue_StringValue:
+				return value.StringValue
+			case *commonpb.AnyValue_IntValue:
+				return strconv.FormatInt(value.IntValue, 10)
+			}
+		}
+	}
+	return ""
+}
+
+// otlpInt returns the first of the attributes that is set, as an integer
+func otlpInt(attributes []*commonpb.KeyValue, keys []string) int64 {
+	for _, key := range keys {
+		for _, attribute := range attributes {
+			if attribute.Key != key || attribute.Value == nil {
+				continue
+			}
+			switch value := attribute.Value.Value.(type) {
+			case *commonpb.AnyValue_IntValue:
+				return value.IntValue
+			case *commonpb.AnyValue_StringValue:
+				if n, err := strconv.ParseInt(value.StringValue, 10, 64); err == nil {
+					return n
+				}
+			}
+		}
+	}
+	return 0
+}
+
+// otlpID formats a trace or span ID as hex. OTLP/JSON sends IDs as hex rather
+// than the base64 protojson expects, so an ID of the wrong size is the hex text
+// decoded as base64, and encoding it back recovers the text.
+func otlpID(id []byte, size int) string {
+	if len(id) == size {
+		return hex.EncodeToString(id)
+	}
+	return base64.StdEncoding.EncodeToString(id)
+}
+
+// respondExportTraces writes an ExportTraceServiceResponse in the encoding of
+// the request, reporting the spans that were rejected
+func respondExportTraces(c *gin.Context, contentType string, rejected int64, message string) {
+	response := &coltracepb.ExportTraceServiceResponse{}
+	if rejected > 0 {
+		response.PartialSuccess = &coltracepb.ExportTracePartialSuccess{
+			RejectedSpans: rejected,
+			ErrorMessage:  message,
+		}
+	}
+
+	var body []byte
+	var err error
+	if contentType == otlpJSONContentType {
+		body, err = protojson.Marshal(response)
+	} else {
+		body, err = proto.Marshal(response)
+	}
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to encode OTLP response", "details": err.Error()})
+		return
+	}
+	c.Data(http.StatusOK, contentType, body)
+}
@@ -0,0 +1,223 @@
+package http
+
+import (
+	"encoding/hex"
+	"net/http"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/spidey52/api-logs/internal/domain"
+	coltracepb "go.opentelemetry.io/proto/otlp/collector/trace/v1"
+	commonpb "go.opentelemetry.io/proto/otlp/common/v1"
+	tracepb "go.opentelemetry.io/proto/otlp/trace/v1"
+	"google.golang.org/protobuf/encoding/protojson"
+	"google.golang.org/protobuf/proto"
+)
+
+const (
+	testTraceID = "5b8efff798038103d269b633813fc60c"
+	testSpanID  = "eee19b7ec3c1b174"
+)
+
+func stringAttribute(key, value string) *commonpb.KeyValue {
+	return &commonpb.KeyValue{Key: key, Value: &commonpb.AnyValue{Value: &commonpb.AnyValue_StringValue{StringValue: value}}}
+}
+
+func intAttribute(key string, value int64) *commonpb.KeyValue {
+	return &commonpb.KeyValue{Key: key, Value: &commonpb.AnyValue{Value: &commonpb.AnyValue_IntValue{IntValue: value}}}
+}
+
+func mustHex(t *testing.T, s string) []byte {
+	t.Helper()
+	b, err := hex.DecodeString(s)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return b
+}
+
+// testTraces holds a server span in current semantic conventions, one in the
+// older ones, and spans that are not HTTP server spans
+func testTraces(t *testing.T) *coltracepb.ExportTraceServiceRequest {
+	start := time.Date(2026, 1, 7, 10, 0, 0, 0, time.UTC)
+	spans := []*tracepb.Span{
+		{
+			TraceId:           mustHex(t, testTraceID),
+			SpanId:            mustHex(t, testSpanID),
+			Kind:              tracepb.Span_SPAN_KIND_SERVER,
+			StartTimeUnixNano: uint64(start.UnixNano()),
+			EndTimeUnixNano:   uint64(start.Add(150 * time.Millisecond).UnixNano()),
+			Attributes: []*commonpb.KeyValue{
+				stringAttribute("http.request.method", "_OTHER"),
+				stringAttribute("http.request.method_original", "purge"),
+				stringAttribute("url.path", "/items/42"),
+				stringAttribute("url.query", "verbose=1"),
+				stringAttribute("http.route", "/items/:id"),
+				intAttribute("http.response.status_code", 500),
+				intAttribute("http.response.body.size", 12),
+				stringAttribute("client.address", "10.0.0.1"),
+				stringAttribute("user_agent.original", "curl/8"),
+			},
+			Status: &tracepb.Status{Code: tracepb.Status_STATUS_CODE_ERROR, Message: "boom"},
+		},
+		{
+			TraceId: mustHex(t, testTraceID),
+			SpanId:  mustHex(t, "eee19b7ec3c1b175"),
+			Kind:    tracepb.Span_SPAN_KIND_SERVER,
+			Attributes: []*commonpb.KeyValue{
+				stringAttribute("http.method", "get"),
+				stringAttribute("http.target", "/search?q=shoes"),
+				stringAttribute("http.status_code", "200"),
+			},
+		},
+		{
+			Kind:       tracepb.Span_SPAN_KIND_CLIENT,
+			Attributes: []*commonpb.KeyValue{stringAttribute("http.request.method", "GET")},
+		},
+		{
+			Kind:       tracepb.Span_SPAN_KIND_SERVER,
+			Attributes: []*commonpb.KeyValue{stringAttribute("rpc.method", "Get")},
+		},
+	}
+	return &coltracepb.ExportTraceServiceRequest{ResourceSpans: []*tracepb.ResourceSpans{{
+		ScopeSpans: []*tracepb.ScopeSpans{{Spans: spans}},
+	}}}
+}
+
+func TestExportTracesMapsServerSpans(t *testing.T) {
+	request := testTraces(t)
+	protobuf, err := proto.Marshal(request)
+	if err != nil {
+		t.Fatal(err)
+	}
+	// OTLP/JSON sends trace and span IDs as hex, which protojson does not
+	jsonBody := `{"resourceSpans":[{"scopeSpans":[{"spans":[
+		{"traceId":"` + testTraceID + `","spanId":"` + testSpanID + `","kind":2,
+		 "startTimeUnixNano":"1767780000000000000","endTimeUnixNano":"1767780000150000000",
+		 "attributes":[
+			{"key":"http.request.method","value":{"stringValue":"_OTHER"}},
+			{"key":"http.request.method_original","value":{"stringValue":"purge"}},
+			{"key":"url.path","value":{"stringValue":"/items/42"}},
+			{"key":"url.query","value":{"stringValue":"verbose=1"}},
+			{"key":"http.route","value":{"stringValue":"/items/:id"}},
+			{"key":"http.response.status_code","value":{"intValue":"500"}},
+			{"key":"http.response.body.size","value":{"intValue":"12"}},
+			{"key":"client.address","value":{"stringValue":"10.0.0.1"}},
+			{"key":"user_agent.original","value":{"stringValue":"curl/8"}}],
+		 "status":{"code":2,"message":"boom"}},
+		{"traceId":"` + testTraceID + `","spanId":"eee19b7ec3c1b175","kind":2,
+		 "attributes":[
+			{"key":"http.method","value":{"stringValue":"get"}},
+			{"key":"http.target","value":{"stringValue":"/search?q=shoes"}},
+			{"key":"http.status_code","value":{"stringValue":"200"}}]},
+		{"kind":3,"attributes":[{"key":"http.request.method","value":{"stringValue":"GET"}}]},
+		{"kind":2,"attributes":[{"key":"rpc.method","value":{"stringValue":"Get"}}]}
+	]}]}]}`
+
+	for _, tt := range []struct {
+		contentType string
+		body        string
+	}{
+		{otlpProtobufContentType, string(protobuf)},
+		{otlpJSONContentType, jsonBody},
+	} {
+		t.Run(tt.contentType, func(t *testing.T) {
+			ingest := &fakeIngest{}
+			handler := NewAPILogHandler(nil, nil, nil, &fakeQuota{allowed: -1}, ingest)
+			router := newTestIngestRouter("/otlp/v1/traces", handler.ExportTraces)
+
+			w := post(router, "/otlp/v1/traces", tt.contentType, tt.body)
+			if w.Code != http.StatusOK {
+				t.Fatalf("want status 200, got %d: %s", w.Code, w.Body)
+			}
+			if len(ingest.entries) != 2 {
+				t.Fatalf("want the 2 HTTP server spans queued, got %d", len(ingest.entries))
+			}
+
+			current := ingest.entries[0]
+			want := &domain.APILog{
+				ProjectID:     "p1",
+				Environment:   domain.EnvironmentDev,
+				Method:        "PURGE",
+				Path:          "/items/:id",
+				QueryParams:   map[string]string{"verbose": "1"},
+				StatusCode:    500,
+				ResponseTime:  150,
+				ContentLength: 12,
+				IPAddress:     "10.0.0.1",
+				UserAgent:     "curl/8",
+				ErrorMessage:  "boom",
+				Timestamp:     time.Date(2026, 1, 7, 10, 0, 0, 0, time.UTC),
+			}
+			if !reflect.DeepEqual(current.Log, want) {
+				t.Fatalf("current conventions span mismatch:\ngot  %+v\nwant %+v", current.Log, want)
+			}
+			if current.IdempotencyKey != "otlp:"+testTraceID+":"+testSpanID {
+				t.Fatalf("idempotency key mismatch: %q", current.IdempotencyKey)
+			}
+
+			older := ingest.entries[1].Log
+			if older.Method != domain.MethodGET || older.Path != "/search" || older.QueryParams["q"] != "shoes" || older.StatusCode != 200 {
+				t.Fatalf("older conventions span mismatch: %+v", older)
+			}
+		})
+	}
+}
+
+func TestExportTracesResponse(t *testing.T) {
+	request := testTraces(t)
+	// The ingest service refuses the second span
+	request.ResourceSpans[0].ScopeSpans[0].Spans[1].Attributes[0] = stringAttribute("http.method", "bad")
+	protobuf, err := proto.Marshal(request)
+	if err != nil {
+		t.Fatal(err)
+	}
+	jsonBody, err := protojson.Marshal(request)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for _, tt := range []struct {
+		contentType string
+		body        []byte
+		unmarshal   func([]byte, proto.Message) error
+	}{
+		{otlpProtobufContentType, protobuf, proto.Unmarshal},
+		{otlpJSONContentType, jsonBody, protojson.Unmarshal},
+	} {
+		t.Run(tt.contentType, func(t *testing.T) {
+			handler := NewAPILogHandler(nil, nil, nil, &fakeQuota{allowed: -1}, &fakeIngest{})
+			router := newTestIngestRouter("/otlp/v1/traces", handler.ExportTraces)
+
+			w := post(router, "/otlp/v1/traces", tt.contentType, string(tt.body))
+			if w.Code != http.StatusOK {
+				t.Fatalf("want status 200, got %d: %s", w.Code, w.Body)
+			}
+			if contentType := w.Header().Get("Content-Type"); contentType != tt.contentType {
+				t.Fatalf("want response content type %s, got %s", tt.contentType, contentType)
+			}
+
+			var response coltracepb.ExportTraceServiceResponse
+			if err := tt.unmarshal(w.Body.Bytes(), &response); err != nil {
+				t.Fatalf("decode response: %v", err)
+			}
+			partial := response.GetPartialSuccess()
+			if partial.GetRejectedSpans() != 1 || partial.GetErrorMessage() != domain.ErrInvalidInput.Error() {
+				t.Fatalf("want one rejected span, got %v", partial)
+			}
+		})
+	}
+}
+
+func TestExportTracesRejectsOtherContentTypes(t *testing.T) {
+	handler := NewAPILogHandler(nil, nil, nil, &fakeQuota{allowed: -1}, &fakeIngest{})
+	router := newTestIngestRouter("/otlp/v1/traces", handler.ExportTraces)
+
+	if w := post(router, "/otlp/v1/traces", "text/plain", "spans"); w.Code != http.StatusUnsupportedMediaType {
+		t.Fatalf("want status 415, got %d", w.Code)
+	}
+	if w := post(router, "/otlp/v1/traces", otlpProtobufContentType, "\xff\xff"); w.Code != http.StatusBadRequest {
+		t.Fatalf("want status 400 for an invalid body, got %d", w.Code)
+	}
+}
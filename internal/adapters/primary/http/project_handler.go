@@ -1,7 +1,9 @@
 package http
 
 import (
+	"io"
 	"net/http"
+	"time"
 
 	"github.com/gin-gonic/gin"
 	"github.com/spidey52/api-logs/internal/domain"
@@ -76,6 +78,10 @@ func (h *ProjectHandler) GetProject(c *gin.Context) {
 			c.JSON(http.StatusNotFound, gin.H{"error": "Project not found"})
 			return
 		}
+		if err == domain.ErrForbidden {
+			c.JSON(http.StatusForbidden, gin.H{"error": "Forbidden"})
+			return
+		}
 		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to retrieve project"})
 		return
 	}
@@ -127,6 +133,10 @@ func (h *ProjectHandler) UpdateProject(c *gin.Context) {
 			c.JSON(http.StatusNotFound, gin.H{"error": "Project not found"})
 			return
 		}
+		if err == domain.ErrForbidden {
+			c.JSON(http.StatusForbidden, gin.H{"error": "Forbidden"})
+			return
+		}
 		if err == domain.ErrInvalidInput {
 			c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid input"})
 			return
@@ -147,6 +157,10 @@ func (h *ProjectHandler) DeleteProject(c *gin.Context) {
 			c.JSON(http.StatusNotFound, gin.H{"error": "Project not found"})
 			return
 		}
+		if err == domain.ErrForbidden {
+			c.JSON(http.StatusForbidden, gin.H{"error": "Forbidden"})
+			return
+		}
 		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to delete project"})
 		return
 	}
@@ -154,19 +168,235 @@ func (h *ProjectHandler) DeleteProject(c *gin.Context) {
 	c.JSON(http.StatusNoContent, nil)
 }
 
+// RegenerateAPIKeyRequest represents the optional request body for rotating a project's key
+type RegenerateAPIKeyRequest struct {
+	// GracePeriod is how long the previous key keeps working, e.g. "1h"; "0s"
+	// invalidates it immediately. Defaults to API_KEY_ROTATION_GRACE.
+	GracePeriod *string `json:"grace_period"`
+}
+
 // RegenerateAPIKey handles POST /api/v1/projects/:id/regenerate-key
 func (h *ProjectHandler) RegenerateAPIKey(c *gin.Context) {
 	id := c.Param("id")
 
-	newKey, err := h.projectService.RegenerateAPIKey(c.Request.Context(), id)
+	var req RegenerateAPIKeyRequest
+	if err := c.ShouldBindJSON(&req); err != nil && err != io.EOF {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	var gracePeriod *time.Duration
+	if req.GracePeriod != nil {
+		grace, err := time.ParseDuration(*req.GracePeriod)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid grace_period", "details": err.Error()})
+			return
+		}
+		gracePeriod = &grace
+	}
+
+	rotation, err := h.projectService.RegenerateAPIKey(c.Request.Context(), id, gracePeriod)
 	if err != nil {
 		if err == domain.ErrProjectNotFound {
 			c.JSON(http.StatusNotFound, gin.H{"error": "Project not found"})
 			return
 		}
+		if err == domain.ErrForbidden {
+			c.JSON(http.StatusForbidden, gin.H{"error": "Forbidden"})
+			return
+		}
+		if err == domain.ErrInvalidInput {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid input", "details": "grace_period must be between 0s and " + domain.MaxRotationGracePeriod.String()})
+			return
+		}
 		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to regenerate API key"})
 		return
 	}
 
-	c.JSON(http.StatusOK, gin.H{"data": gin.H{"api_key": newKey}})
+	c.JSON(http.StatusOK, gin.H{"data": rotation})
+}
+
+// CreateAPIKeyRequest represents the request body for issuing a named API key
+type CreateAPIKeyRequest struct {
+	Name      string               `json:"name" binding:"required"`
+	Scopes    []domain.APIKeyScope `json:"scopes" binding:"required,min=1"`
+	ExpiresAt *time.Time           `json:"expires_at"`
+}
+
+// ListAPIKeys handles GET /api/v1/projects/:id/keys
+func (h *ProjectHandler) ListAPIKeys(c *gin.Context) {
+	keys, err := h.projectService.ListAPIKeys(c.Request.Context(), c.Param("id"))
+	if err != nil {
+		switch err {
+		case domain.ErrProjectNotFound:
+			c.JSON(http.StatusNotFound, gin.H{"error": "Project not found"})
+		case domain.ErrForbidden:
+			c.JSON(http.StatusForbidden, gin.H{"error": "Forbidden"})
+		default:
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to retrieve API keys"})
+		}
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"data": keys})
+}
+
+// CreateAPIKey handles POST /api/v1/projects/:id/keys
+func (h *ProjectHandler) CreateAPIKey(c *gin.Context) {
+	var req CreateAPIKeyRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	key, err := h.projectService.CreateAPIKey(c.Request.Context(), c.Param("id"), req.Name, req.Scopes, req.ExpiresAt)
+	if err != nil {
+		switch err {
+		case domain.ErrProjectNotFound:
+			c.JSON(http.StatusNotFound, gin.H{"error": "Project not found"})
+		case domain.ErrForbidden:
+			c.JSON(http.StatusForbidden, gin.H{"error": "Forbidden"})
+		case domain.ErrDuplicateAPIKey:
+			c.JSON(http.StatusConflict, gin.H{"error": "API key already exists"})
+		case domain.ErrInvalidScope, domain.ErrInvalidInput:
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid input", "details": err.Error()})
+		default:
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to create API key", "details": err.Error()})
+		}
+		return
+	}
+
+	c.JSON(http.StatusCreated, gin.H{"data": key})
+}
+
+// RevokeAPIKey handles DELETE /api/v1/projects/:id/keys/:key_id
+func (h *ProjectHandler) RevokeAPIKey(c *gin.Context) {
+	err := h.projectService.RevokeAPIKey(c.Request.Context(), c.Param("id"), c.Param("key_id"))
+	if err != nil {
+		switch err {
+		case domain.ErrProjectNotFound:
+			c.JSON(http.StatusNotFound, gin.H{"error": "Project not found"})
+		case domain.ErrAPIKeyNotFound:
+			c.JSON(http.StatusNotFound, gin.H{"error": "API key not found"})
+		case domain.ErrForbidden:
+			c.JSON(http.StatusForbidden, gin.H{"error": "Forbidden"})
+		default:
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to revoke API key", "details": err.Error()})
+		}
+		return
+	}
+
+	c.JSON(http.StatusNoContent, nil)
+}
+
+// AddMemberRequest represents the request body for adding a project member
+type AddMemberRequest struct {
+	Username string             `json:"username" binding:"required"`
+	Role     domain.ProjectRole `json:"role" binding:"required"`
+}
+
+// UpdateMemberRequest represents the request body for changing a member's role
+type UpdateMemberRequest struct {
+	Role domain.ProjectRole `json:"role" binding:"required"`
+}
+
+// ListMembers handles GET /api/v1/projects/:id/members
+func (h *ProjectHandler) ListMembers(c *gin.Context) {
+	members, err := h.projectService.ListMembers(c.Request.Context(), c.Param("id"))
+	if err != nil {
+		if err == domain.ErrProjectNotFound {
+			c.JSON(http.StatusNotFound, gin.H{"error": "Project not found"})
+			return
+		}
+		if err == domain.ErrForbidden {
+			c.JSON(http.StatusForbidden, gin.H{"error": "Forbidden"})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to retrieve members"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"data": members})
+}
+
+// AddMember handles POST /api/v1/projects/:id/members
+func (h *ProjectHandler) AddMember(c *gin.Context) {
+	var req AddMemberRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	member, err := h.projectService.AddMember(c.Request.Context(), c.Param("id"), req.Username, req.Role)
+	if err != nil {
+		switch err {
+		case domain.ErrProjectNotFound:
+			c.JSON(http.StatusNotFound, gin.H{"error": "Project not found"})
+		case domain.ErrAccountNotFound:
+			c.JSON(http.StatusNotFound, gin.H{"error": "Account not found"})
+		case domain.ErrForbidden:
+			c.JSON(http.StatusForbidden, gin.H{"error": "Forbidden"})
+		case domain.ErrDuplicateMember:
+			c.JSON(http.StatusConflict, gin.H{"error": "Account is already a member of the project"})
+		case domain.ErrInvalidRole:
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid input", "details": err.Error()})
+		default:
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to add member", "details": err.Error()})
+		}
+		return
+	}
+
+	c.JSON(http.StatusCreated, gin.H{"data": member})
+}
+
+// UpdateMember handles PUT /api/v1/projects/:id/members/:account_id
+func (h *ProjectHandler) UpdateMember(c *gin.Context) {
+	var req UpdateMemberRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	member, err := h.projectService.UpdateMemberRole(c.Request.Context(), c.Param("id"), c.Param("account_id"), req.Role)
+	if err != nil {
+		switch err {
+		case domain.ErrProjectNotFound:
+			c.JSON(http.StatusNotFound, gin.H{"error": "Project not found"})
+		case domain.ErrMemberNotFound:
+			c.JSON(http.StatusNotFound, gin.H{"error": "Member not found"})
+		case domain.ErrForbidden:
+			c.JSON(http.StatusForbidden, gin.H{"error": "Forbidden"})
+		case domain.ErrLastProjectOwner:
+			c.JSON(http.StatusConflict, gin.H{"error": "Project must keep at least one owner"})
+		case domain.ErrInvalidRole:
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid input", "details": err.Error()})
+		default:
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to update member", "details": err.Error()})
+		}
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"data": member})
+}
+
+// RemoveMember handles DELETE /api/v1/projects/:id/members/:account_id
+func (h *ProjectHandler) RemoveMember(c *gin.Context) {
+	err := h.projectService.RemoveMember(c.Request.Context(), c.Param("id"), c.Param("account_id"))
+	if err != nil {
+		switch err {
+		case domain.ErrProjectNotFound:
+			c.JSON(http.StatusNotFound, gin.H{"error": "Project not found"})
+		case domain.ErrMemberNotFound:
+			c.JSON(http.StatusNotFound, gin.H{"error": "Member not found"})
+		case domain.ErrForbidden:
+			c.JSON(http.StatusForbidden, gin.H{"error": "Forbidden"})
+		case domain.ErrLastProjectOwner:
+			c.JSON(http.StatusConflict, gin.H{"error": "Project must keep at least one owner"})
+		default:
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to remove member", "details": err.Error()})
+		}
+		return
+	}
+
+	c.JSON(http.StatusNoContent, nil)
 }
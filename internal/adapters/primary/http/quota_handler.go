@@ -0,0 +1,98 @@
+package http
+
+import (
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/spidey52/api-logs/internal/domain"
+	"github.com/spidey52/api-logs/internal/ports/input"
+)
+
+// QuotaHandler handles HTTP requests for project quotas
+type QuotaHandler struct {
+	quotaService input.QuotaService
+}
+
+// NewQuotaHandler creates a new instance of QuotaHandler
+func NewQuotaHandler(quotaService input.QuotaService) *QuotaHandler {
+	return &QuotaHandler{
+		quotaService: quotaService,
+	}
+}
+
+// SetQuotaRequest represents the request body for setting a project's quota.
+// Zero means unlimited.
+type SetQuotaRequest struct {
+	RequestsPerSecond    int64 `json:"requests_per_second"`
+	LogsPerDay           int64 `json:"logs_per_day"`
+	KeyRequestsPerSecond int64 `json:"key_requests_per_second"`
+	KeyLogsPerDay        int64 `json:"key_logs_per_day"`
+}
+
+// GetQuota handles GET /api/v1/projects/:id/quota
+func (h *QuotaHandler) GetQuota(c *gin.Context) {
+	quota, err := h.quotaService.GetQuota(c.Request.Context(), c.Param("id"))
+	if err != nil {
+		h.respondError(c, err, "Failed to retrieve quota")
+		return
+	}
+
+	usage, err := h.quotaService.GetUsage(c.Request.Context(), c.Param("id"))
+	if err != nil {
+		h.respondError(c, err, "Failed to retrieve usage")
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"data": gin.H{
+		"quota": quota,
+		"usage": usage,
+	}})
+}
+
+// SetQuota handles PUT /api/v1/projects/:id/quota
+func (h *QuotaHandler) SetQuota(c *gin.Context) {
+	var req SetQuotaRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	quota := &domain.ProjectQuota{
+		ProjectID:            c.Param("id"),
+		RequestsPerSecond:    req.RequestsPerSecond,
+		LogsPerDay:           req.LogsPerDay,
+		KeyRequestsPerSecond: req.KeyRequestsPerSecond,
+		KeyLogsPerDay:        req.KeyLogsPerDay,
+	}
+	if err := h.quotaService.SetQuota(c.Request.Context(), quota); err != nil {
+		h.respondError(c, err, "Failed to set quota")
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"data": quota})
+}
+
+// ResetQuota handles DELETE /api/v1/projects/:id/quota
+func (h *QuotaHandler) ResetQuota(c *gin.Context) {
+	if err := h.quotaService.ResetQuota(c.Request.Context(), c.Param("id")); err != nil {
+		h.respondError(c, err, "Failed to reset quota")
+		return
+	}
+
+	c.JSON(http.StatusNoContent, nil)
+}
+
+func (h *QuotaHandler) respondError(c *gin.Context, err error, message string) {
+	switch err {
+	case domain.ErrProjectNotFound:
+		c.JSON(http.StatusNotFound, gin.H{"error": "Project not found"})
+	case domain.ErrQuotaNotFound:
+		c.JSON(http.StatusNotFound, gin.H{"error": "Project has no quota of its own"})
+	case domain.ErrForbidden:
+		c.JSON(http.StatusForbidden, gin.H{"error": "Forbidden"})
+	case domain.ErrInvalidQuota:
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid input", "details": err.Error()})
+	default:
+		c.JSON(http.StatusInternalServerError, gin.H{"error": message, "details": err.Error()})
+	}
+}
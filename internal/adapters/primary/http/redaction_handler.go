@@ -0,0 +1,92 @@
+package http
+
+import (
+	"errors"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/spidey52/api-logs/internal/domain"
+	"github.com/spidey52/api-logs/internal/ports/input"
+)
+
+// RedactionHandler handles HTTP requests for project redaction policies
+type RedactionHandler struct {
+	redactionService input.RedactionService
+}
+
+// NewRedactionHandler creates a new instance of RedactionHandler
+func NewRedactionHandler(redactionService input.RedactionService) *RedactionHandler {
+	return &RedactionHandler{
+		redactionService: redactionService,
+	}
+}
+
+// SetRedactionPolicyRequest represents the request body for setting a
+// project's redaction policy. It replaces the default policy as a whole.
+type SetRedactionPolicyRequest struct {
+	Headers     []string `json:"headers"`
+	QueryParams []string `json:"query_params"`
+	JSONPaths   []string `json:"json_paths"`
+	Patterns    []string `json:"patterns"`
+	Regexes     []string `json:"regexes"`
+}
+
+// GetPolicy handles GET /api/v1/projects/:id/redaction
+func (h *RedactionHandler) GetPolicy(c *gin.Context) {
+	policy, err := h.redactionService.GetPolicy(c.Request.Context(), c.Param("id"))
+	if err != nil {
+		h.respondError(c, err, "Failed to retrieve redaction policy")
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"data": policy})
+}
+
+// SetPolicy handles PUT /api/v1/projects/:id/redaction
+func (h *RedactionHandler) SetPolicy(c *gin.Context) {
+	var req SetRedactionPolicyRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	policy := &domain.RedactionPolicy{
+		ProjectID:   c.Param("id"),
+		Headers:     req.Headers,
+		QueryParams: req.QueryParams,
+		JSONPaths:   req.JSONPaths,
+		Patterns:    req.Patterns,
+		Regexes:     req.Regexes,
+	}
+	if err := h.redactionService.SetPolicy(c.Request.Context(), policy); err != nil {
+		h.respondError(c, err, "Failed to set redaction policy")
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"data": policy})
+}
+
+// ResetPolicy handles DELETE /api/v1/projects/:id/redaction
+func (h *RedactionHandler) ResetPolicy(c *gin.Context) {
+	if err := h.redactionService.ResetPolicy(c.Request.Context(), c.Param("id")); err != nil {
+		h.respondError(c, err, "Failed to reset redaction policy")
+		return
+	}
+
+	c.JSON(http.StatusNoContent, nil)
+}
+
+func (h *RedactionHandler) respondError(c *gin.Context, err error, message string) {
+	switch {
+	case err == domain.ErrProjectNotFound:
+		c.JSON(http.StatusNotFound, gin.H{"error": "Project not found"})
+	case err == domain.ErrRedactionPolicyNotFound:
+		c.JSON(http.StatusNotFound, gin.H{"error": "Project has no redaction policy of its own"})
+	case err == domain.ErrForbidden:
+		c.JSON(http.StatusForbidden, gin.H{"error": "Forbidden"})
+	case errors.Is(err, domain.ErrInvalidRedactionPolicy):
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid input", "details": err.Error()})
+	default:
+		c.JSON(http.StatusInternalServerError, gin.H{"error": message, "details": err.Error()})
+	}
+}
@@ -0,0 +1,88 @@
+package http
+
+import (
+	"errors"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/spidey52/api-logs/internal/domain"
+	"github.com/spidey52/api-logs/internal/ports/input"
+)
+
+// RetentionHandler handles HTTP requests for project retention policies
+type RetentionHandler struct {
+	retentionService input.RetentionService
+}
+
+// NewRetentionHandler creates a new instance of RetentionHandler
+func NewRetentionHandler(retentionService input.RetentionService) *RetentionHandler {
+	return &RetentionHandler{
+		retentionService: retentionService,
+	}
+}
+
+// SetRetentionPolicyRequest represents the request body for setting a
+// project's retention policy. Days left out or zero take the default.
+type SetRetentionPolicyRequest struct {
+	LogsDays       int `json:"logs_days"`
+	HeadersDays    int `json:"headers_days"`
+	BodiesDays     int `json:"bodies_days"`
+	AccessLogsDays int `json:"access_logs_days"`
+}
+
+// GetPolicy handles GET /api/v1/projects/:id/retention
+func (h *RetentionHandler) GetPolicy(c *gin.Context) {
+	policy, err := h.retentionService.GetPolicy(c.Request.Context(), c.Param("id"))
+	if err != nil {
+		h.respondError(c, err, "Failed to retrieve retention policy")
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"data": policy})
+}
+
+// SetPolicy handles PUT /api/v1/projects/:id/retention
+func (h *RetentionHandler) SetPolicy(c *gin.Context) {
+	var req SetRetentionPolicyRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	policy, err := h.retentionService.SetPolicy(c.Request.Context(), c.Param("id"), domain.RetentionPolicy{
+		LogsDays:       req.LogsDays,
+		HeadersDays:    req.HeadersDays,
+		BodiesDays:     req.BodiesDays,
+		AccessLogsDays: req.AccessLogsDays,
+	})
+	if err != nil {
+		h.respondError(c, err, "Failed to set retention policy")
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"data": policy})
+}
+
+// Preview handles GET /api/v1/projects/:id/retention/preview
+func (h *RetentionHandler) Preview(c *gin.Context) {
+	preview, err := h.retentionService.Preview(c.Request.Context(), c.Param("id"))
+	if err != nil {
+		h.respondError(c, err, "Failed to preview retention")
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"data": preview})
+}
+
+func (h *RetentionHandler) respondError(c *gin.Context, err error, message string) {
+	switch {
+	case err == domain.ErrProjectNotFound:
+		c.JSON(http.StatusNotFound, gin.H{"error": "Project not found"})
+	case err == domain.ErrForbidden:
+		c.JSON(http.StatusForbidden, gin.H{"error": "Forbidden"})
+	case errors.Is(err, domain.ErrInvalidRetentionPolicy):
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid input", "details": err.Error()})
+	default:
+		c.JSON(http.StatusInternalServerError, gin.H{"error": message, "details": err.Error()})
+	}
+}
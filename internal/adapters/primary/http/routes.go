@@ -2,6 +2,7 @@ package http
 
 import (
 	"github.com/gin-gonic/gin"
+	"github.com/spidey52/api-logs/internal/domain"
 )
 
 type SetupRoutesParams struct {
@@ -10,6 +11,16 @@ type SetupRoutesParams struct {
 	APILogHandler    *APILogHandler
 	UserHandler      *UserHandler
 	AccessLogHandler *AccessLogHandler
+	AuthHandler      *AuthHandler
+	QuotaHandler     *QuotaHandler
+	RedactionHandler *RedactionHandler
+	RetentionHandler *RetentionHandler
+	ArchiveHandler   *ArchiveHandler
+	HealthHandler    *HealthHandler
+
+	// MaxIngestBodySize limits the decompressed body of ingest requests, and
+	// each line of a stream
+	MaxIngestBodySize int64
 }
 
 // SetupRoutes configures all HTTP routes
@@ -20,6 +31,12 @@ func SetupRoutes(params SetupRoutesParams) {
 	apiLogHandler := params.APILogHandler
 	userHandler := params.UserHandler
 	accessLogHandler := params.AccessLogHandler
+	authHandler := params.AuthHandler
+	quotaHandler := params.QuotaHandler
+	redactionHandler := params.RedactionHandler
+	retentionHandler := params.RetentionHandler
+	archiveHandler := params.ArchiveHandler
+	healthHandler := params.HealthHandler
 
 	// API Documentation (Scalar UI)
 	docsHandler := NewDocsHandler()
@@ -32,15 +49,31 @@ func SetupRoutes(params SetupRoutesParams) {
 	})
 
 	// Health check
-	router.GET("/health", func(c *gin.Context) {
-		c.JSON(200, gin.H{"status": "ok"})
-	})
+	router.GET("/health", healthHandler.Health)
 
 	// API v1
 	v1 := router.Group("/api/v1")
 	{
-		// Project routes (admin/management - no auth required for now)
+		// Auth routes (login is public, the rest requires a session token)
+		auth := v1.Group("/auth")
+		{
+			auth.POST("/login", authHandler.Login)
+			auth.POST("/logout", authHandler.AuthMiddleware(), authHandler.Logout)
+			auth.GET("/me", authHandler.AuthMiddleware(), authHandler.Me)
+			auth.PUT("/password", authHandler.AuthMiddleware(), authHandler.ChangePassword)
+		}
+
+		// Account routes (admin/management - requires a session token)
+		accounts := v1.Group("/accounts")
+		accounts.Use(authHandler.AuthMiddleware())
+		{
+			accounts.POST("", authHandler.CreateAccount)
+			accounts.GET("", authHandler.ListAccounts)
+		}
+
+		// Project routes (admin/management - requires a session token)
 		projects := v1.Group("/projects")
+		projects.Use(authHandler.AuthMiddleware())
 		{
 			projects.POST("", projectHandler.CreateProject)
 			projects.GET("", projectHandler.ListProjects)
@@ -48,10 +81,29 @@ func SetupRoutes(params SetupRoutesParams) {
 			projects.PUT("/:id", projectHandler.UpdateProject)
 			projects.DELETE("/:id", projectHandler.DeleteProject)
 			projects.POST("/:id/regenerate-key", projectHandler.RegenerateAPIKey)
+			projects.GET("/:id/keys", projectHandler.ListAPIKeys)
+			projects.POST("/:id/keys", projectHandler.CreateAPIKey)
+			projects.DELETE("/:id/keys/:key_id", projectHandler.RevokeAPIKey)
+			projects.GET("/:id/members", projectHandler.ListMembers)
+			projects.POST("/:id/members", projectHandler.AddMember)
+			projects.PUT("/:id/members/:account_id", projectHandler.UpdateMember)
+			projects.DELETE("/:id/members/:account_id", projectHandler.RemoveMember)
+			projects.GET("/:id/quota", quotaHandler.GetQuota)
+			projects.PUT("/:id/quota", quotaHandler.SetQuota)
+			projects.DELETE("/:id/quota", quotaHandler.ResetQuota)
+			projects.GET("/:id/redaction", redactionHandler.GetPolicy)
+			projects.PUT("/:id/redaction", redactionHandler.SetPolicy)
+			projects.DELETE("/:id/redaction", redactionHandler.ResetPolicy)
+			projects.GET("/:id/retention", retentionHandler.GetPolicy)
+			projects.PUT("/:id/retention", retentionHandler.SetPolicy)
+			projects.GET("/:id/retention/preview", retentionHandler.Preview)
+			projects.GET("/:id/archive", archiveHandler.List)
+			projects.POST("/:id/archive/restore", archiveHandler.Restore)
 		}
 
-		// User routes (admin/management - no auth required for now)
+		// User routes (requires a session token; access follows the project role)
 		users := v1.Group("/users")
+		users.Use(authHandler.AuthMiddleware())
 		{
 			users.POST("", userHandler.CreateUser)
 			users.GET("", userHandler.ListUsers)
@@ -61,23 +113,38 @@ func SetupRoutes(params SetupRoutesParams) {
 			users.DELETE("/:id", userHandler.DeleteUser)
 		}
 
-		// Log routes (requires API key authentication)
+		// Log routes (requires an API key with the route's scope, or a session
+		// token plus X-Project-ID)
 		logs := v1.Group("/logs")
-		logs.Use(apiLogHandler.AuthMiddleware())
 		{
-			logs.POST("", apiLogHandler.CreateLog)
-			logs.POST("/batch", apiLogHandler.CreateBatchLogs)
-			logs.GET("", apiLogHandler.ListLogs)
-			logs.GET("/stats", apiLogHandler.GetStats)
-			logs.GET("/paths", apiLogHandler.GetUniquePaths)
-			logs.GET("/:id", apiLogHandler.GetLog)
-			logs.GET("/:id/details", apiLogHandler.GetLogWithDetails)
-			logs.GET("/:id/headers", apiLogHandler.GetLogHeaders)
-			logs.GET("/:id/body", apiLogHandler.GetLogBody)
+			ingest := apiLogHandler.AuthMiddleware(domain.ScopeIngest)
+			read := apiLogHandler.AuthMiddleware(domain.ScopeRead)
+			stats := apiLogHandler.AuthMiddleware(domain.ScopeStats)
+			decompress := DecompressBody(params.MaxIngestBodySize)
+
+			logs.POST("", ingest, decompress, apiLogHandler.CreateLog)
+			logs.POST("/batch", ingest, decompress, apiLogHandler.CreateBatchLogs)
+			// Streams and imports are not limited as a whole, only each of their lines
+			logs.POST("/stream", ingest, DecompressBody(0), apiLogHandler.CreateStreamLogs(int(params.MaxIngestBodySize)))
+			logs.POST("/import/access-log", ingest, DecompressBody(0), apiLogHandler.ImportAccessLogs(int(params.MaxIngestBodySize)))
+			logs.POST("/import/har", ingest, decompress, apiLogHandler.ImportHAR)
+			logs.GET("", read, apiLogHandler.ListLogs)
+			logs.GET("/stats", stats, apiLogHandler.GetStats)
+			logs.GET("/paths", read, apiLogHandler.GetUniquePaths)
+			logs.GET("/export/har", read, apiLogHandler.ExportHAR)
+			logs.GET("/:id", read, apiLogHandler.GetLog)
+			logs.GET("/:id/details", read, apiLogHandler.GetLogWithDetails)
+			logs.GET("/:id/headers", read, apiLogHandler.GetLogHeaders)
+			logs.GET("/:id/body", read, apiLogHandler.GetLogBody)
 		}
 
-		// access log routes
+		// OTLP/HTTP receiver, so exporters are configured with the endpoint
+		// /api/v1/otlp and an X-API-Key header
+		v1.POST("/otlp/v1/traces", apiLogHandler.AuthMiddleware(domain.ScopeIngest), DecompressBody(params.MaxIngestBodySize), apiLogHandler.ExportTraces)
+
+		// Access log routes (admin/management - requires a session token)
 		accessLogs := v1.Group("/access-logs")
+		accessLogs.Use(authHandler.AuthMiddleware())
 		{
 			accessLogs.POST("", accessLogHandler.CreateAccessLog)
 			accessLogs.GET("", accessLogHandler.GetAccessLogs)
@@ -0,0 +1,206 @@
+package http
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/gin-gonic/gin/binding"
+	"github.com/spidey52/api-logs/internal/domain"
+)
+
+// streamChunkSize is how many lines of a stream are queued together. Each
+// chunk counts as one request against the rate limits.
+const streamChunkSize = 500
+
+// streamMaxErrors caps the failed lines a stream response lists; the rest are
+// only counted
+const streamMaxErrors = 1000
+
+// streamIdleTimeout is how long a stream may wait for its next line. It takes
+// the place of the server's timeouts, which bound the whole request.
+const streamIdleTimeout = 30 * time.Second
+
+// StreamLogResponse reports the outcome of a streamed ingest
+type StreamLogResponse struct {
+	Lines          int              `json:"lines"` // lines read, blank ones included
+	SuccessCount   int              `json:"success_count"`
+	DuplicateCount int              `json:"duplicate_count"`
+	FailedCount    int              `json:"failed_count"`
+	Errors         []StreamLogError `json:"errors,omitempty"` // the first streamMaxErrors
+
+	// ResumeFromLine is set when the stream was cut short: every line before
+	// it was processed, and none from it on
+	ResumeFromLine int `json:"resume_from_line,omitempty"`
+}
+
+// StreamLogError reports a line that was not accepted
+type StreamLogError struct {
+	Line  int    `json:"line"` // 1-based
+	Error string `json:"error"`
+}
+
+// streamChunk collects the entries of a stream until they are queued
+type streamChunk struct {
+	entries []*domain.LogEntry
+	lines   []int
+}
+
+// CreateStreamLogs handles POST /api/v1/logs/stream. The body holds one
+// CreateLogRequest per line (NDJSON), so log shippers can send files as they
+// are. Lines are decoded as they arrive and queued every streamChunkSize
+// lines; a line longer than maxLineSize ends the stream.
+func (h *APILogHandler) CreateStreamLogs(maxLineSize int) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		createUsers := c.Query("create_users") == "true"
+
+		h.ingestLines(c, maxLineSize, 1, func(line []byte, _ int) (*domain.LogEntry, error) {
+			var req CreateLogRequest
+			if err := json.Unmarshal(line, &req); err != nil {
+				return nil, err
+			}
+			if err := binding.Validator.ValidateStruct(&req); err != nil {
+				return nil, err
+			}
+
+			entry := newLogEntry(c, &req)
+			if createUsers && req.UserIdentifier != "" {
+				entry.Log.UserID = nil
+				entry.UserIdentifier = req.UserIdentifier
+				entry.UserName = req.UserName
+			}
+			return entry, nil
+		})
+	}
+}
+
+// ingestLines reads the request body line by line, converting each non-blank
+// line with parse and queueing the entries every streamChunkSize lines. Lines
+// are numbered from firstLine. It writes the StreamLogResponse.
+func (h *APILogHandler) ingestLines(c *gin.Context, maxLineSize, firstLine int, parse func(line []byte, number int) (*domain.LogEntry, error)) {
+	// The scanner allows lines as long as its initial buffer, so that must not
+	// exceed maxLineSize
+	scanner := bufio.NewScanner(c.Request.Body)
+	scanner.Buffer(make([]byte, 0, min(64*1024, maxLineSize)), maxLineSize)
+
+	controller := http.NewResponseController(c.Writer)
+	extendDeadlines := func() {
+		deadline := time.Now().Add(streamIdleTimeout)
+		_ = controller.SetReadDeadline(deadline)
+		_ = controller.SetWriteDeadline(deadline)
+	}
+	extendDeadlines()
+
+	var response StreamLogResponse
+	number := firstLine - 1
+	chunk := &streamChunk{}
+	for scanner.Scan() {
+		extendDeadlines()
+		response.Lines++
+		number++
+		line := bytes.TrimSpace(scanner.Bytes())
+		if len(line) == 0 {
+			continue
+		}
+
+		entry, err := parse(line, number)
+		if err != nil {
+			response.fail(number, err)
+			continue
+		}
+		chunk.entries = append(chunk.entries, entry)
+		chunk.lines = append(chunk.lines, number)
+
+		if len(chunk.entries) >= streamChunkSize {
+			if !h.queueStreamChunk(c, chunk, &response) {
+				return
+			}
+			chunk = &streamChunk{}
+		}
+	}
+
+	if err := scanner.Err(); err != nil {
+		// The line being read is the one after the last complete line
+		response.ResumeFromLine = number + 1
+		if len(chunk.lines) > 0 {
+			response.ResumeFromLine = chunk.lines[0]
+		}
+		status, body := http.StatusBadRequest, gin.H{"error": "Failed to read stream", "details": err.Error()}
+		var tooLarge *http.MaxBytesError
+		if errors.Is(err, bufio.ErrTooLong) || errors.As(err, &tooLarge) {
+			status, body = http.StatusRequestEntityTooLarge, gin.H{"error": "Line too long", "details": err.Error()}
+		}
+		body["data"] = response
+		c.JSON(status, body)
+		return
+	}
+
+	if !h.queueStreamChunk(c, chunk, &response) {
+		return
+	}
+
+	if response.SuccessCount == 0 && response.FailedCount == 0 {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Stream contains no logs"})
+		return
+	}
+
+	statusCode := http.StatusAccepted
+	if response.FailedCount > 0 {
+		if response.SuccessCount == 0 {
+			statusCode = http.StatusBadRequest
+		} else {
+			statusCode = http.StatusPartialContent
+		}
+	}
+	c.JSON(statusCode, gin.H{"data": response})
+}
+
+// queueStreamChunk checks the request rate for a chunk and queues it. When
+// the chunk is refused as a whole it responds, with the chunk's first line to
+// resume from, and reports false.
+func (h *APILogHandler) queueStreamChunk(c *gin.Context, chunk *streamChunk, response *StreamLogResponse) bool {
+	if len(chunk.entries) == 0 {
+		return true
+	}
+
+	stop := func(status int, body gin.H) bool {
+		response.ResumeFromLine = chunk.lines[0]
+		body["data"] = response
+		c.JSON(status, body)
+		return false
+	}
+
+	err := h.quotaService.CheckRequest(c.Request.Context(), c.GetString("project_id"), c.GetString("api_key_id"))
+	if err != nil {
+		return stop(quotaErrorResponse(c, err))
+	}
+
+	errs, err := h.ingestService.Enqueue(c.Request.Context(), chunk.entries)
+	if err != nil {
+		return stop(enqueueErrorResponse(c, err))
+	}
+
+	for i, entryErr := range errs {
+		switch entryErr {
+		case nil:
+			response.SuccessCount++
+		case domain.ErrDuplicateLog:
+			response.SuccessCount++
+			response.DuplicateCount++
+		default:
+			response.fail(chunk.lines[i], entryErr)
+		}
+	}
+	return true
+}
+
+func (r *StreamLogResponse) fail(line int, err error) {
+	r.FailedCount++
+	if len(r.Errors) < streamMaxErrors {
+		r.Errors = append(r.Errors, StreamLogError{Line: line, Error: err.Error()})
+	}
+}
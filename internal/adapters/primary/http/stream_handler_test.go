@@ -0,0 +1,146 @@
+package http
+
+import (
+	"encoding/json"
+	"net/http"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestCreateStreamLogs(t *testing.T) {
+	const maxLineSize = 256
+	valid := `{"method":"GET","path":"/items","status_code":200}`
+	duplicate := `{"method":"GET","path":"/items","status_code":200,"idempotency_key":"dup"}`
+
+	tests := []struct {
+		name       string
+		quota      int
+		lines      []string
+		wantStatus int
+		wantLines  int
+		wantOK     int
+		wantFailed []int // lines reported as failed
+		wantResume int
+		wantStored int
+	}{
+		{
+			name:       "all accepted",
+			quota:      -1,
+			lines:      []string{valid, valid, duplicate},
+			wantStatus: http.StatusAccepted,
+			wantLines:  3,
+			wantOK:     3,
+			wantStored: 2,
+		},
+		{
+			name:  "partial success",
+			quota: -1,
+			lines: []string{
+				valid,
+				`{"method":"GET",`,                    // not JSON
+				``,                                    // blank lines are skipped
+				`{"path":"/items","status_code":200}`, // missing method
+				`{"method":"BAD","path":"/items","status_code":200}`, // refused by ingestion
+				valid,
+			},
+			wantStatus: http.StatusPartialContent,
+			wantLines:  6,
+			wantOK:     2,
+			wantFailed: []int{2, 4, 5},
+			wantStored: 2,
+		},
+		{
+			name:       "all failed",
+			quota:      -1,
+			lines:      []string{`not json`, `{"method":"GET"}`},
+			wantStatus: http.StatusBadRequest,
+			wantLines:  2,
+			wantFailed: []int{1, 2},
+		},
+		{
+			name:       "empty",
+			quota:      -1,
+			lines:      []string{``, ``},
+			wantStatus: http.StatusBadRequest,
+		},
+		{
+			name:       "line too long",
+			quota:      -1,
+			lines:      []string{valid, `{"method":"GET","path":"/` + strings.Repeat("a", maxLineSize) + `"}`, valid},
+			wantStatus: http.StatusRequestEntityTooLarge,
+			wantLines:  1,
+			wantResume: 1,
+		},
+		{
+			name:       "quota exceeded",
+			quota:      0,
+			lines:      []string{valid, valid},
+			wantStatus: http.StatusTooManyRequests,
+			wantLines:  2,
+			wantResume: 1,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ingest := &fakeIngest{}
+			handler := NewAPILogHandler(nil, nil, nil, &fakeQuota{allowed: tt.quota}, ingest)
+			router := newTestIngestRouter("/logs/stream", handler.CreateStreamLogs(maxLineSize))
+
+			w := post(router, "/logs/stream", "application/x-ndjson", strings.Join(tt.lines, "\n"))
+			if w.Code != tt.wantStatus {
+				t.Fatalf("want status %d, got %d: %s", tt.wantStatus, w.Code, w.Body)
+			}
+
+			var response struct {
+				Data StreamLogResponse `json:"data"`
+			}
+			if err := json.Unmarshal(w.Body.Bytes(), &response); err != nil {
+				t.Fatal(err)
+			}
+			got := response.Data
+			var failed []int
+			for _, lineErr := range got.Errors {
+				failed = append(failed, lineErr.Line)
+			}
+
+			if got.Lines != tt.wantLines || got.SuccessCount != tt.wantOK || got.FailedCount != len(tt.wantFailed) ||
+				!reflect.DeepEqual(failed, tt.wantFailed) || got.ResumeFromLine != tt.wantResume {
+				t.Fatalf("unexpected response: %+v", got)
+			}
+			if len(ingest.entries) != tt.wantStored {
+				t.Fatalf("want %d logs queued, got %d", tt.wantStored, len(ingest.entries))
+			}
+		})
+	}
+}
+
+func TestCreateStreamLogsChunks(t *testing.T) {
+	ingest := &fakeIngest{}
+	// The second chunk is refused, so the stream resumes from its first line
+	handler := NewAPILogHandler(nil, nil, nil, &fakeQuota{allowed: 1}, ingest)
+	router := newTestIngestRouter("/logs/stream", handler.CreateStreamLogs(1024))
+
+	lines := make([]string, streamChunkSize+10)
+	for i := range lines {
+		lines[i] = `{"method":"GET","path":"/items","status_code":200}`
+	}
+	w := post(router, "/logs/stream", "application/x-ndjson", strings.Join(lines, "\n"))
+	if w.Code != http.StatusTooManyRequests {
+		t.Fatalf("want status 429, got %d: %s", w.Code, w.Body)
+	}
+
+	var response struct {
+		Data StreamLogResponse `json:"data"`
+	}
+	if err := json.Unmarshal(w.Body.Bytes(), &response); err != nil {
+		t.Fatal(err)
+	}
+	if response.Data.SuccessCount != streamChunkSize || response.Data.ResumeFromLine != streamChunkSize+1 {
+		t.Fatalf("unexpected response: %+v", response.Data)
+	}
+	if len(ingest.entries) != streamChunkSize {
+		t.Fatalf("want the first chunk queued, got %d logs", len(ingest.entries))
+	}
+}
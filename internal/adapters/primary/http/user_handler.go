@@ -28,6 +28,7 @@ func NewUserHandler(userService input.UserService) *UserHandler {
 // @Param user body CreateUserRequest true "User details"
 // @Success 201 {object} UserResponse
 // @Failure 400 {object} ErrorResponse
+// @Failure 403 {object} ErrorResponse
 // @Failure 500 {object} ErrorResponse
 // @Router /users [post]
 func (h *UserHandler) CreateUser(c *gin.Context) {
@@ -49,6 +50,10 @@ func (h *UserHandler) CreateUser(c *gin.Context) {
 			c.JSON(http.StatusConflict, ErrorResponse{Error: "User identifier already exists"})
 			return
 		}
+		if err == domain.ErrForbidden {
+			c.JSON(http.StatusForbidden, ErrorResponse{Error: "Forbidden"})
+			return
+		}
 		c.JSON(http.StatusInternalServerError, ErrorResponse{Error: err.Error()})
 		return
 	}
@@ -65,6 +70,7 @@ func (h *UserHandler) CreateUser(c *gin.Context) {
 // @Param id path string true "User ID"
 // @Success 200 {object} UserResponse
 // @Failure 404 {object} ErrorResponse
+// @Failure 403 {object} ErrorResponse
 // @Failure 500 {object} ErrorResponse
 // @Router /users/{id} [get]
 func (h *UserHandler) GetUser(c *gin.Context) {
@@ -76,6 +82,10 @@ func (h *UserHandler) GetUser(c *gin.Context) {
 			c.JSON(http.StatusNotFound, ErrorResponse{Error: "User not found"})
 			return
 		}
+		if err == domain.ErrForbidden {
+			c.JSON(http.StatusForbidden, ErrorResponse{Error: "Forbidden"})
+			return
+		}
 		c.JSON(http.StatusInternalServerError, ErrorResponse{Error: err.Error()})
 		return
 	}
@@ -90,8 +100,10 @@ func (h *UserHandler) GetUser(c *gin.Context) {
 // @Tags users
 // @Produce json
 // @Param identifier query string true "User Identifier"
+// @Param project_id query string true "Project ID"
 // @Success 200 {object} UserResponse
 // @Failure 404 {object} ErrorResponse
+// @Failure 403 {object} ErrorResponse
 // @Failure 500 {object} ErrorResponse
 // @Router /users/by-identifier [get]
 func (h *UserHandler) GetUserByIdentifier(c *gin.Context) {
@@ -102,6 +114,10 @@ func (h *UserHandler) GetUserByIdentifier(c *gin.Context) {
 	}
 
 	projectID := c.Query("project_id")
+	if projectID == "" {
+		c.JSON(http.StatusBadRequest, ErrorResponse{Error: "Project ID is required"})
+		return
+	}
 
 	user, err := h.userService.GetUserByIdentifier(c.Request.Context(), identifier, projectID)
 	if err != nil {
@@ -109,6 +125,10 @@ func (h *UserHandler) GetUserByIdentifier(c *gin.Context) {
 			c.JSON(http.StatusNotFound, ErrorResponse{Error: "User not found"})
 			return
 		}
+		if err == domain.ErrForbidden {
+			c.JSON(http.StatusForbidden, ErrorResponse{Error: "Forbidden"})
+			return
+		}
 		c.JSON(http.StatusInternalServerError, ErrorResponse{Error: err.Error()})
 		return
 	}
@@ -128,6 +148,7 @@ func (h *UserHandler) GetUserByIdentifier(c *gin.Context) {
 // @Success 200 {object} UserResponse
 // @Failure 400 {object} ErrorResponse
 // @Failure 404 {object} ErrorResponse
+// @Failure 403 {object} ErrorResponse
 // @Failure 500 {object} ErrorResponse
 // @Router /users/{id} [put]
 func (h *UserHandler) UpdateUser(c *gin.Context) {
@@ -155,6 +176,10 @@ func (h *UserHandler) UpdateUser(c *gin.Context) {
 			c.JSON(http.StatusConflict, ErrorResponse{Error: "User identifier already exists"})
 			return
 		}
+		if err == domain.ErrForbidden {
+			c.JSON(http.StatusForbidden, ErrorResponse{Error: "Forbidden"})
+			return
+		}
 		c.JSON(http.StatusInternalServerError, ErrorResponse{Error: err.Error()})
 		return
 	}
@@ -171,6 +196,7 @@ func (h *UserHandler) UpdateUser(c *gin.Context) {
 // @Param id path string true "User ID"
 // @Success 204
 // @Failure 404 {object} ErrorResponse
+// @Failure 403 {object} ErrorResponse
 // @Failure 500 {object} ErrorResponse
 // @Router /users/{id} [delete]
 func (h *UserHandler) DeleteUser(c *gin.Context) {
@@ -181,6 +207,10 @@ func (h *UserHandler) DeleteUser(c *gin.Context) {
 			c.JSON(http.StatusNotFound, ErrorResponse{Error: "User not found"})
 			return
 		}
+		if err == domain.ErrForbidden {
+			c.JSON(http.StatusForbidden, ErrorResponse{Error: "Forbidden"})
+			return
+		}
 		c.JSON(http.StatusInternalServerError, ErrorResponse{Error: err.Error()})
 		return
 	}
@@ -192,17 +222,30 @@ func (h *UserHandler) DeleteUser(c *gin.Context) {
 // @Summary List users
 // @Tags users
 // @Produce json
+// @Param project_id query string true "Project ID"
 // @Param page query int false "Page number" default(1)
 // @Param page_size query int false "Page size" default(10)
 // @Success 200 {object} UsersListResponse
+// @Failure 400 {object} ErrorResponse
+// @Failure 403 {object} ErrorResponse
 // @Failure 500 {object} ErrorResponse
 // @Router /users [get]
 func (h *UserHandler) ListUsers(c *gin.Context) {
+	projectID := c.Query("project_id")
+	if projectID == "" {
+		c.JSON(http.StatusBadRequest, ErrorResponse{Error: "Project ID is required"})
+		return
+	}
+
 	page, _ := strconv.Atoi(c.DefaultQuery("page", "1"))
 	pageSize, _ := strconv.Atoi(c.DefaultQuery("limit", "10"))
 
-	users, total, err := h.userService.ListUsers(c.Request.Context(), page, pageSize)
+	users, total, err := h.userService.ListUsers(c.Request.Context(), projectID, page, pageSize)
 	if err != nil {
+		if err == domain.ErrForbidden {
+			c.JSON(http.StatusForbidden, ErrorResponse{Error: "Forbidden"})
+			return
+		}
 		c.JSON(http.StatusInternalServerError, ErrorResponse{Error: err.Error()})
 		return
 	}
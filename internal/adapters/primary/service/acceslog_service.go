@@ -10,19 +10,24 @@ import (
 
 // accessLogService implements the AccessLogService interface
 type accessLogService struct {
-	logRepo output.AccessLogRepository
+	logRepo    output.AccessLogRepository
+	authorizer *Authorizer
 }
 
 var _ input.AccessLogService = (*accessLogService)(nil)
 
-func NewAccessLogService(logRepo output.AccessLogRepository) input.AccessLogService {
+func NewAccessLogService(logRepo output.AccessLogRepository, authorizer *Authorizer) input.AccessLogService {
 	return &accessLogService{
-		logRepo: logRepo,
+		logRepo:    logRepo,
+		authorizer: authorizer,
 	}
 }
 
 // CountLogs implements input.AccessLogService.
 func (a *accessLogService) CountLogs(ctx context.Context, filter domain.AccessLogFilter) (int64, error) {
+	if err := a.authorizer.Authorize(ctx, filter.ProjectID, domain.RoleViewer, actionRead, resourceAccessLog, ""); err != nil {
+		return 0, err
+	}
 	return a.logRepo.CountByFilter(ctx, filter)
 }
 
@@ -35,6 +40,10 @@ func (a *accessLogService) CreateLog(ctx context.Context, log *domain.AccessLog)
 		return err
 	}
 
+	if err := a.authorizer.Authorize(ctx, log.ProjectID, domain.RoleEditor, actionWrite, resourceAccessLog, ""); err != nil {
+		return err
+	}
+
 	return a.logRepo.Create(ctx, log)
 }
 
@@ -49,15 +58,37 @@ func (a *accessLogService) CreateManyLogs(ctx context.Context, logs []*domain.Ac
 		}
 	}
 
+	authorized := map[string]bool{}
+	for _, log := range logs {
+		if authorized[log.ProjectID] {
+			continue
+		}
+		if err := a.authorizer.Authorize(ctx, log.ProjectID, domain.RoleEditor, actionWrite, resourceAccessLog, ""); err != nil {
+			return err
+		}
+		authorized[log.ProjectID] = true
+	}
+
 	return a.logRepo.CreateMany(ctx, logs)
 }
 
 // GetLogDetails implements input.AccessLogService.
 func (a *accessLogService) GetLogDetails(ctx context.Context, id string) (*domain.AccessLog, error) {
-	return a.logRepo.FindByID(ctx, id)
+	log, err := a.logRepo.FindByID(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := a.authorizer.Authorize(ctx, log.ProjectID, domain.RoleViewer, actionRead, resourceAccessLog, id); err != nil {
+		return nil, err
+	}
+	return log, nil
 }
 
 // GetLogs implements input.AccessLogService.
 func (a *accessLogService) GetLogs(ctx context.Context, filter domain.AccessLogFilter) ([]*domain.AccessLog, error) {
+	if err := a.authorizer.Authorize(ctx, filter.ProjectID, domain.RoleViewer, actionRead, resourceAccessLog, ""); err != nil {
+		return nil, err
+	}
 	return a.logRepo.FindByFilter(ctx, filter)
 }
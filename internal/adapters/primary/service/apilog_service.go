@@ -3,12 +3,14 @@ package service
 import (
 	"context"
 	"fmt"
+	"sort"
 	"time"
 
 	"github.com/google/uuid"
 	"github.com/spidey52/api-logs/internal/domain"
 	"github.com/spidey52/api-logs/internal/ports/input"
 	"github.com/spidey52/api-logs/internal/ports/output"
+	"github.com/spidey52/api-logs/pkg/logger"
 )
 
 // apiLogService implements the APILogService interface
@@ -17,6 +19,15 @@ type apiLogService struct {
 	headersRepo output.APILogHeadersRepository
 	bodyRepo    output.APILogBodyRepository
 	userRepo    output.UserRepository
+	authorizer  *Authorizer
+
+	// decrypter opens headers and bodies stored encrypted; nil when the
+	// storage does not encrypt them
+	decrypter output.LogDecrypter
+
+	// rollups is told of stored logs, and serves the summaries the stats
+	// are read from
+	rollups input.RollupService
 }
 
 // NewAPILogService creates a new instance of APILogService
@@ -25,12 +36,18 @@ func NewAPILogService(
 	headersRepo output.APILogHeadersRepository,
 	bodyRepo output.APILogBodyRepository,
 	userRepo output.UserRepository,
+	authorizer *Authorizer,
+	decrypter output.LogDecrypter,
+	rollups input.RollupService,
 ) input.APILogService {
 	return &apiLogService{
 		logRepo:     logRepo,
 		headersRepo: headersRepo,
 		bodyRepo:    bodyRepo,
 		userRepo:    userRepo,
+		authorizer:  authorizer,
+		decrypter:   decrypter,
+		rollups:     rollups,
 	}
 }
 
@@ -41,6 +58,10 @@ func (s *apiLogService) CreateLog(ctx context.Context, log *domain.APILog, heade
 		return domain.ErrInvalidInput
 	}
 
+	if err := s.authorizer.Authorize(ctx, log.ProjectID, domain.RoleEditor, actionWrite, resourceAPILog, ""); err != nil {
+		return err
+	}
+
 	// Generate ID if not provided
 	if log.ID == "" {
 		log.ID = uuid.New().String()
@@ -55,11 +76,13 @@ func (s *apiLogService) CreateLog(ctx context.Context, log *domain.APILog, heade
 	if err := s.logRepo.Create(ctx, log); err != nil {
 		return err
 	}
+	s.rollups.Track([]*domain.APILog{log})
 
 	// Create headers if provided
 	if headers != nil && (len(headers.RequestHeaders) > 0 || len(headers.ResponseHeaders) > 0) {
 		headers.ID = uuid.New().String()
 		headers.LogID = log.ID
+		headers.ProjectID = log.ProjectID
 		headers.CreatedAt = time.Now()
 
 		if err := headers.Validate(); err != nil {
@@ -73,6 +96,7 @@ func (s *apiLogService) CreateLog(ctx context.Context, log *domain.APILog, heade
 	if body != nil && (body.RequestBody != nil || body.ResponseBody != nil) {
 		body.ID = uuid.New().String()
 		body.LogID = log.ID
+		body.ProjectID = log.ProjectID
 		body.CreatedAt = time.Now()
 
 		if err := body.Validate(); err != nil {
@@ -85,9 +109,141 @@ func (s *apiLogService) CreateLog(ctx context.Context, log *domain.APILog, heade
 	return nil
 }
 
+// CreateLogs creates a batch of log entries. Logs, headers and bodies are each
+// written with one bulk insert, and every distinct user identifier is resolved
+// once per batch. Like CreateLog, failing to store headers or bodies does not
+// fail the logs. Logs that already exist are skipped, so a batch whose write
+// failed can be retried as a whole.
+func (s *apiLogService) CreateLogs(ctx context.Context, entries []*domain.LogEntry) ([]error, error) {
+	results := make([]error, len(entries))
+
+	valid := make([]*domain.LogEntry, 0, len(entries))
+	authorized := make(map[string]bool)
+	for i, entry := range entries {
+		if entry.Log == nil || entry.Log.Validate() != nil {
+			results[i] = domain.ErrInvalidInput
+			continue
+		}
+
+		projectID := entry.Log.ProjectID
+		if !authorized[projectID] {
+			if err := s.authorizer.Authorize(ctx, projectID, domain.RoleEditor, actionWrite, resourceAPILog, ""); err != nil {
+				return nil, err
+			}
+			authorized[projectID] = true
+		}
+		valid = append(valid, entry)
+	}
+
+	s.resolveUsers(ctx, valid)
+
+	now := time.Now()
+	logs := make([]*domain.APILog, 0, len(valid))
+	var headers []*domain.APILogHeaders
+	var bodies []*domain.APILogBody
+	for _, entry := range valid {
+		log := entry.Log
+		if log.ID == "" {
+			log.ID = uuid.New().String()
+		}
+		if log.Timestamp.IsZero() {
+			log.Timestamp = now
+		}
+		logs = append(logs, log)
+
+		if h := entry.Headers; h != nil && (len(h.RequestHeaders) > 0 || len(h.ResponseHeaders) > 0) {
+			h.ID = uuid.New().String()
+			h.LogID = log.ID
+			h.ProjectID = log.ProjectID
+			h.CreatedAt = now
+			headers = append(headers, h)
+		}
+		if b := entry.Body; b != nil && (b.RequestBody != nil || b.ResponseBody != nil) {
+			b.ID = uuid.New().String()
+			b.LogID = log.ID
+			b.ProjectID = log.ProjectID
+			b.CreatedAt = now
+			bodies = append(bodies, b)
+		}
+	}
+
+	if err := s.logRepo.CreateMany(ctx, logs); err != nil {
+		return nil, err
+	}
+	s.rollups.Track(logs)
+
+	if err := s.headersRepo.CreateMany(ctx, headers); err != nil {
+		logger.Warn("Failed to store batch headers", "count", len(headers), "error", err)
+	}
+	if err := s.bodyRepo.CreateMany(ctx, bodies); err != nil {
+		logger.Warn("Failed to store batch bodies", "count", len(bodies), "error", err)
+	}
+
+	return results, nil
+}
+
+// resolveUsers sets the user of every entry with a user identifier, looking up
+// (and if needed creating) each distinct identifier once. Entries whose user
+// cannot be resolved are stored without one.
+func (s *apiLogService) resolveUsers(ctx context.Context, entries []*domain.LogEntry) {
+	type userKey struct{ projectID, identifier string }
+	resolved := make(map[userKey]*string)
+
+	for _, entry := range entries {
+		if entry.UserIdentifier == "" {
+			continue
+		}
+
+		key := userKey{entry.Log.ProjectID, entry.UserIdentifier}
+		userID, ok := resolved[key]
+		if !ok {
+			userID = s.findOrCreateUser(ctx, key.projectID, key.identifier, entry.UserName)
+			resolved[key] = userID
+		}
+		entry.Log.UserID = userID
+	}
+}
+
+func (s *apiLogService) findOrCreateUser(ctx context.Context, projectID, identifier, name string) *string {
+	user, err := s.userRepo.FindByIdentifier(ctx, identifier, projectID)
+	if err == nil {
+		return &user.ID
+	}
+	if err != domain.ErrUserNotFound {
+		return nil
+	}
+
+	user = &domain.User{
+		ID:         uuid.New().String(),
+		Identifier: identifier,
+		Name:       name,
+		ProjectID:  projectID,
+		CreatedAt:  time.Now(),
+	}
+	if err := user.Validate(); err != nil {
+		return nil
+	}
+	if err := s.userRepo.Create(ctx, user); err != nil {
+		// Another batch may have created the user in the meantime
+		if existing, err := s.userRepo.FindByIdentifier(ctx, identifier, projectID); err == nil {
+			return &existing.ID
+		}
+		return nil
+	}
+	return &user.ID
+}
+
 // GetLog retrieves a log by ID (core log only)
 func (s *apiLogService) GetLog(ctx context.Context, id string) (*domain.APILog, error) {
-	return s.logRepo.FindByID(ctx, id)
+	log, err := s.logRepo.FindByID(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := s.authorizer.Authorize(ctx, log.ProjectID, domain.RoleViewer, actionRead, resourceAPILog, id); err != nil {
+		return nil, err
+	}
+	return log, nil
 }
 
 // GetLogWithDetails retrieves a log with headers and body
@@ -101,6 +257,10 @@ func (s *apiLogService) GetLogWithDetails(ctx context.Context, id string) (*doma
 		return nil, nil, nil, domain.ErrLogNotFound
 	}
 
+	if err := s.authorizer.Authorize(ctx, log.ProjectID, domain.RoleViewer, actionRead, resourceAPILog, id); err != nil {
+		return nil, nil, nil, err
+	}
+
 	var headers *domain.APILogHeaders
 	var body *domain.APILogBody
 
@@ -118,21 +278,113 @@ func (s *apiLogService) GetLogWithDetails(ctx context.Context, id string) (*doma
 		body = nil
 	}
 
+	if err := s.decryptHeaders(ctx, log.ProjectID, headers); err != nil {
+		return nil, nil, nil, err
+	}
+	if err := s.decryptBody(ctx, log.ProjectID, body); err != nil {
+		return nil, nil, nil, err
+	}
+
 	return log, headers, body, nil
 }
 
-// GetLogHeaders retrieves headers for a specific log
+// GetLogHeaders retrieves headers for a specific log, leaving encrypted
+// headers out
 func (s *apiLogService) GetLogHeaders(ctx context.Context, logID string) (*domain.APILogHeaders, error) {
-	return s.headersRepo.FindByLogID(ctx, logID)
+	if _, err := s.authorizeLogRead(ctx, logID); err != nil {
+		if err == domain.ErrLogNotFound {
+			return nil, domain.ErrHeadersNotFound
+		}
+		return nil, err
+	}
+
+	headers, err := s.headersRepo.FindByLogID(ctx, logID)
+	if err != nil {
+		return nil, err
+	}
+	omitEncryptedHeaders(headers)
+	return headers, nil
 }
 
 // GetLogBody retrieves body for a specific log
 func (s *apiLogService) GetLogBody(ctx context.Context, logID string) (*domain.APILogBody, error) {
-	return s.bodyRepo.FindByLogID(ctx, logID)
+	projectID, err := s.authorizeLogRead(ctx, logID)
+	if err != nil {
+		if err == domain.ErrLogNotFound {
+			return nil, domain.ErrBodyNotFound
+		}
+		return nil, err
+	}
+
+	body, err := s.bodyRepo.FindByLogID(ctx, logID)
+	if err != nil {
+		return nil, err
+	}
+	if err := s.decryptBody(ctx, projectID, body); err != nil {
+		return nil, err
+	}
+	return body, nil
+}
+
+// authorizeLogRead checks that the caller may read the log's project,
+// returning the project
+func (s *apiLogService) authorizeLogRead(ctx context.Context, logID string) (string, error) {
+	log, err := s.logRepo.FindByID(ctx, logID)
+	if err != nil {
+		return "", err
+	}
+	return log.ProjectID, s.authorizer.Authorize(ctx, log.ProjectID, domain.RoleViewer, actionRead, resourceAPILog, logID)
+}
+
+// decryptHeaders opens headers stored encrypted. Only the details and body
+// reads decrypt; other reads leave encrypted values out.
+func (s *apiLogService) decryptHeaders(ctx context.Context, projectID string, headers *domain.APILogHeaders) error {
+	if headers == nil || s.decrypter == nil {
+		return nil
+	}
+	return s.decrypter.DecryptHeaders(ctx, projectID, headers)
+}
+
+// decryptBody opens a body stored encrypted, like decryptHeaders
+func (s *apiLogService) decryptBody(ctx context.Context, projectID string, body *domain.APILogBody) error {
+	if body == nil || s.decrypter == nil {
+		return nil
+	}
+	return s.decrypter.DecryptBody(ctx, projectID, body)
+}
+
+// omitEncryptedHeaders clears the headers stored encrypted
+func omitEncryptedHeaders(headers *domain.APILogHeaders) {
+	if headers == nil {
+		return
+	}
+	if domain.IsEncrypted(headers.RequestHeaders) {
+		headers.RequestHeaders = nil
+	}
+	if domain.IsEncrypted(headers.ResponseHeaders) {
+		headers.ResponseHeaders = nil
+	}
+}
+
+// omitEncryptedBody clears the bodies stored encrypted
+func omitEncryptedBody(body *domain.APILogBody) {
+	if body == nil {
+		return
+	}
+	if domain.IsEncrypted(body.RequestBody) {
+		body.RequestBody = nil
+	}
+	if domain.IsEncrypted(body.ResponseBody) {
+		body.ResponseBody = nil
+	}
 }
 
 // ListLogs retrieves logs based on filter criteria
 func (s *apiLogService) ListLogs(ctx context.Context, filter domain.LogFilter) ([]*domain.APILog, error) {
+	if err := s.authorizer.Authorize(ctx, filter.ProjectID, domain.RoleViewer, actionRead, resourceAPILog, ""); err != nil {
+		return nil, err
+	}
+
 	filter.ApplyDefaults()
 	logs, err := s.logRepo.FindByFilter(ctx, filter)
 
@@ -168,8 +420,54 @@ func (s *apiLogService) ListLogs(ctx context.Context, filter domain.LogFilter) (
 	return logs, nil
 }
 
+// ListLogDetails retrieves logs with their headers and bodies, looking the
+// details up for the whole page at once. Headers and bodies stored encrypted
+// are not decrypted in bulk and are left out.
+func (s *apiLogService) ListLogDetails(ctx context.Context, filter domain.LogFilter) ([]*domain.LogEntry, error) {
+	logs, err := s.ListLogs(ctx, filter)
+	if err != nil {
+		return nil, err
+	}
+
+	logIDs := make([]string, len(logs))
+	entries := make([]*domain.LogEntry, len(logs))
+	byLogID := make(map[string]*domain.LogEntry, len(logs))
+	for i, log := range logs {
+		logIDs[i] = log.ID
+		entries[i] = &domain.LogEntry{Log: log}
+		byLogID[log.ID] = entries[i]
+	}
+
+	headers, err := s.headersRepo.FindByLogIDs(ctx, logIDs)
+	if err != nil {
+		return nil, err
+	}
+	for _, h := range headers {
+		omitEncryptedHeaders(h)
+		if entry, ok := byLogID[h.LogID]; ok {
+			entry.Headers = h
+		}
+	}
+
+	bodies, err := s.bodyRepo.FindByLogIDs(ctx, logIDs)
+	if err != nil {
+		return nil, err
+	}
+	for _, b := range bodies {
+		omitEncryptedBody(b)
+		if entry, ok := byLogID[b.LogID]; ok {
+			entry.Body = b
+		}
+	}
+
+	return entries, nil
+}
+
 // CountLogs counts logs matching the filter criteria
 func (s *apiLogService) CountLogs(ctx context.Context, filter domain.LogFilter) (int64, error) {
+	if err := s.authorizer.Authorize(ctx, filter.ProjectID, domain.RoleViewer, actionRead, resourceAPILog, ""); err != nil {
+		return 0, err
+	}
 	return s.logRepo.CountByFilter(ctx, filter)
 }
 
@@ -184,6 +482,10 @@ func (s *apiLogService) DeleteLog(ctx context.Context, id string) error {
 		return domain.ErrLogNotFound
 	}
 
+	if err := s.authorizer.Authorize(ctx, log.ProjectID, domain.RoleEditor, actionDelete, resourceAPILog, id); err != nil {
+		return err
+	}
+
 	// Delete headers if they exist
 	_ = s.headersRepo.Delete(ctx, id) // Ignore error
 
@@ -194,60 +496,143 @@ func (s *apiLogService) DeleteLog(ctx context.Context, id string) error {
 	return s.logRepo.Delete(ctx, id)
 }
 
-// GetLogStats retrieves statistics for a project
+// statsTopEndpoints is how many endpoints the stats rank
+const statsTopEndpoints = 10
+
+// GetLogStats retrieves today's statistics for a project from its log
+// summaries, which trail the stored logs by up to the rollup interval
 func (s *apiLogService) GetLogStats(ctx context.Context, projectID string, environment domain.Environment) (map[string]interface{}, error) {
 	if err := environment.Validate(); err != nil {
 		return nil, domain.ErrInvalidEnvironment
 	}
 
-	// Get total count
-	count, err := s.logRepo.CountByProject(ctx, projectID, environment)
-	if err != nil {
+	if err := s.authorizer.Authorize(ctx, projectID, domain.RoleViewer, actionRead, resourceAPILog, ""); err != nil {
 		return nil, err
 	}
 
-	// Get status code distribution
-	distribution, err := s.logRepo.GetStatusCodeDistribution(ctx, projectID, environment)
+	now := time.Now()
+	summaries, err := s.rollups.Summaries(ctx, projectID, environment, startOfDay(now), now)
 	if err != nil {
 		return nil, err
 	}
 
-	// Get average response time
-	avgResponseTime, err := s.logRepo.GetAverageResponseTime(ctx, projectID, environment)
-	if err != nil {
-		return nil, err
+	stats := summarize(summaries)
+	stats["environment"] = environment.String()
+	stats["project_id"] = projectID
+	return stats, nil
+}
+
+// startOfDay returns local midnight for t, the start of the stats window
+func startOfDay(t time.Time) time.Time {
+	year, month, day := t.Date()
+	return time.Date(year, month, day, 0, 0, 0, 0, t.Location())
+}
+
+// summarize aggregates summaries into the stats: totals, distributions, the
+// request count of each hour (the first 24) and the busiest endpoints
+func summarize(summaries []*domain.LogSummary) map[string]interface{} {
+	type endpoint struct {
+		path      string
+		count     int64
+		latency   int64
+		byMethod  map[domain.HTTPMethod]int64
+		topMethod domain.HTTPMethod
 	}
 
-	// Get time series data
-	timeSeries, err := s.logRepo.GetTimeSeriesStats(ctx, projectID, environment)
-	if err != nil {
-		return nil, err
+	total := &domain.LogSummary{StatusCodes: make(map[int]int64)}
+	methods := make(map[string]int64)
+	hours := make(map[time.Time]int64)
+	endpoints := make(map[string]*endpoint)
+	for _, summary := range summaries {
+		total.Merge(summary)
+		methods[string(summary.Method)] += summary.Count
+		hours[domain.GranularityHour.Bucket(summary.Bucket)] += summary.Count
+
+		e, ok := endpoints[summary.Route]
+		if !ok {
+			e = &endpoint{path: summary.Route, byMethod: make(map[domain.HTTPMethod]int64)}
+			endpoints[summary.Route] = e
+		}
+		e.count += summary.Count
+		e.latency += summary.LatencySum
+		e.byMethod[summary.Method] += summary.Count
+		if count := e.byMethod[summary.Method]; count > e.byMethod[e.topMethod] ||
+			(count == e.byMethod[e.topMethod] && summary.Method < e.topMethod) {
+			e.topMethod = summary.Method
+		}
 	}
 
-	// Get top endpoints
-	topEndpoints, err := s.logRepo.GetTopEndpoints(ctx, projectID, environment, 10)
-	if err != nil {
-		return nil, err
+	var average float64
+	if total.Count > 0 {
+		average = float64(total.LatencySum) / float64(total.Count)
 	}
 
-	// Get method distribution
-	methodDistribution, err := s.logRepo.GetMethodDistribution(ctx, projectID, environment)
-	if err != nil {
-		return nil, err
+	buckets := make([]time.Time, 0, len(hours))
+	for hour := range hours {
+		buckets = append(buckets, hour)
+	}
+	sort.Slice(buckets, func(i, j int) bool { return buckets[i].Before(buckets[j]) })
+	if len(buckets) > 24 {
+		buckets = buckets[:24]
+	}
+	timeSeries := make([]map[string]interface{}, 0, len(buckets))
+	for _, hour := range buckets {
+		timeSeries = append(timeSeries, map[string]interface{}{
+			"timestamp": hour,
+			"count":     hours[hour],
+		})
+	}
+
+	ranked := make([]*endpoint, 0, len(endpoints))
+	for _, e := range endpoints {
+		ranked = append(ranked, e)
+	}
+	sort.Slice(ranked, func(i, j int) bool {
+		if ranked[i].count != ranked[j].count {
+			return ranked[i].count > ranked[j].count
+		}
+		return ranked[i].path < ranked[j].path
+	})
+	if len(ranked) > statsTopEndpoints {
+		ranked = ranked[:statsTopEndpoints]
+	}
+	topEndpoints := make([]map[string]interface{}, 0, len(ranked))
+	for _, e := range ranked {
+		topEndpoints = append(topEndpoints, map[string]interface{}{
+			"_id":               e.path,
+			"count":             e.count,
+			"method":            string(e.topMethod),
+			"avg_response_time": float64(e.latency) / float64(e.count),
+		})
 	}
 
-	stats := map[string]interface{}{
-		"total_logs":               count,
-		"status_code_distribution": distribution,
-		"average_response_time_ms": avgResponseTime,
-		"time_series":              timeSeries,
-		"top_endpoints":            topEndpoints,
-		"method_distribution":      methodDistribution,
-		"environment":              environment.String(),
-		"project_id":               projectID,
+	histogram := total.Histogram
+	if histogram == nil {
+		histogram = make([]int64, len(domain.LatencyBounds)+1)
 	}
 
-	return stats, nil
+	return map[string]interface{}{
+		"total_logs":               total.Count,
+		"error_count":              total.ErrorCount,
+		"status_code_distribution": total.StatusCodes,
+		"status_class_distribution": map[string]int64{
+			"1xx": total.Status1xx,
+			"2xx": total.Status2xx,
+			"3xx": total.Status3xx,
+			"4xx": total.Status4xx,
+			"5xx": total.Status5xx,
+		},
+		"average_response_time_ms": average,
+		"min_response_time_ms":     total.LatencyMin,
+		"max_response_time_ms":     total.LatencyMax,
+		"latency_histogram": map[string]interface{}{
+			"bounds_ms": domain.LatencyBounds,
+			"counts":    histogram,
+		},
+		"time_series":         timeSeries,
+		"top_endpoints":       topEndpoints,
+		"method_distribution": methods,
+	}
 }
 
 // GetUniquePaths retrieves unique paths for autocomplete
@@ -256,5 +641,9 @@ func (s *apiLogService) GetUniquePaths(ctx context.Context, projectID string, en
 		return nil, domain.ErrInvalidEnvironment
 	}
 
+	if err := s.authorizer.Authorize(ctx, projectID, domain.RoleViewer, actionRead, resourceAPILog, ""); err != nil {
+		return nil, err
+	}
+
 	return s.logRepo.GetUniquePaths(ctx, projectID, environment)
 }
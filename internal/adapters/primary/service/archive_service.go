@@ -0,0 +1,298 @@
+package service
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"github.com/spidey52/api-logs/internal/domain"
+	"github.com/spidey52/api-logs/internal/ports/input"
+	"github.com/spidey52/api-logs/internal/ports/output"
+	"github.com/spidey52/api-logs/pkg/archive"
+)
+
+// restoreBatchSize is how many restored logs are stored together
+const restoreBatchSize = 500
+
+// archiveService implements the ArchiveService interface
+type archiveService struct {
+	archive     *archive.Archive
+	projectRepo output.ProjectRepository
+	logService  input.APILogService
+	authorizer  *Authorizer
+
+	// decrypter opens the archived headers and bodies that were stored
+	// encrypted; nil when the storage does not encrypt them
+	decrypter output.LogDecrypter
+}
+
+// NewArchiveService creates a new instance of ArchiveService. A nil archive
+// means archiving is disabled, and every call returns domain.ErrArchiveDisabled.
+func NewArchiveService(
+	archiveStore *archive.Archive,
+	projectRepo output.ProjectRepository,
+	logService input.APILogService,
+	authorizer *Authorizer,
+	decrypter output.LogDecrypter,
+) input.ArchiveService {
+	return &archiveService{
+		archive:     archiveStore,
+		projectRepo: projectRepo,
+		logService:  logService,
+		authorizer:  authorizer,
+		decrypter:   decrypter,
+	}
+}
+
+// List returns the archived objects of a project
+func (s *archiveService) List(ctx context.Context, projectID string, from, to time.Time) ([]domain.ArchivedObject, error) {
+	if s.archive == nil {
+		return nil, domain.ErrArchiveDisabled
+	}
+	if err := s.authorizer.Authorize(ctx, projectID, domain.RoleViewer, actionRead, resourceAPILog, ""); err != nil {
+		return nil, err
+	}
+	if to.Before(from) {
+		return nil, fmt.Errorf("%w: from must be a day on or before to", domain.ErrInvalidArchiveRange)
+	}
+
+	found, err := s.archive.Objects(ctx, projectID, "", from, to)
+	if err != nil {
+		return nil, err
+	}
+
+	objects := make([]domain.ArchivedObject, len(found))
+	for i, object := range found {
+		objects[i] = domain.ArchivedObject{Key: object.Key, Class: domain.DataClass(object.Kind), Day: object.Day}
+	}
+	return objects, nil
+}
+
+// Restore stores the archived logs of the range one UTC day at a time, so
+// only a day's headers and bodies are held at once. Each day's logs are
+// stored in batches with the details archived the same day; details archived
+// on a later day than their log are added to the log once it is stored.
+// Restored logs keep their IDs when restored into their own project, and get
+// IDs derived from them otherwise, so restoring a range again stores nothing
+// twice.
+func (s *archiveService) Restore(ctx context.Context, restore domain.ArchiveRestore) (*domain.RestoreResult, error) {
+	if s.archive == nil {
+		return nil, domain.ErrArchiveDisabled
+	}
+	if err := restore.Validate(); err != nil {
+		return nil, err
+	}
+	if err := s.authorizer.Authorize(ctx, restore.SourceProjectID, domain.RoleViewer, actionRead, resourceAPILog, ""); err != nil {
+		return nil, err
+	}
+	if _, err := s.projectRepo.FindByID(ctx, restore.ProjectID); err != nil {
+		return nil, err
+	}
+
+	objects, err := s.archive.Objects(ctx, restore.SourceProjectID, "", restore.From, restore.To)
+	if err != nil {
+		return nil, err
+	}
+
+	result := &domain.RestoreResult{Objects: len(objects)}
+	// Objects are listed oldest day first
+	for start := 0; start < len(objects); {
+		end := start + 1
+		for end < len(objects) && objects[end].Day.Equal(objects[start].Day) {
+			end++
+		}
+		if err := s.restoreDay(ctx, restore, objects[start:end], result); err != nil {
+			return result, err
+		}
+		start = end
+	}
+	return result, nil
+}
+
+// restoreDay restores the archived objects of one day
+func (s *archiveService) restoreDay(ctx context.Context, restore domain.ArchiveRestore, objects []archive.Object, result *domain.RestoreResult) error {
+	headers := make(map[string]*domain.APILogHeaders)
+	bodies := make(map[string]*domain.APILogBody)
+	for _, object := range objects {
+		var err error
+		switch domain.DataClass(object.Kind) {
+		case domain.DataHeaders:
+			err = readArchived(ctx, s.archive, object.Key, func(h *domain.APILogHeaders) error {
+				headers[h.LogID] = h
+				return nil
+			})
+		case domain.DataBodies:
+			err = readArchived(ctx, s.archive, object.Key, func(b *domain.APILogBody) error {
+				bodies[b.LogID] = b
+				return nil
+			})
+		}
+		if err != nil {
+			return err
+		}
+	}
+
+	batch := &restoreBatch{service: s, result: result}
+	for _, object := range objects {
+		if domain.DataClass(object.Kind) != domain.DataLogs {
+			continue
+		}
+		err := readArchived(ctx, s.archive, object.Key, func(log *domain.APILog) error {
+			h, b := headers[log.ID], bodies[log.ID]
+			delete(headers, log.ID)
+			delete(bodies, log.ID)
+
+			entry, err := s.restoredEntry(ctx, restore, log, h, b)
+			if err != nil {
+				return err
+			}
+			return batch.add(ctx, entry, true)
+		})
+		if err != nil {
+			return err
+		}
+	}
+	if err := batch.flush(ctx); err != nil {
+		return err
+	}
+
+	return s.restoreDetails(ctx, restore, headers, bodies, result)
+}
+
+// restoreDetails stores headers and bodies whose log was not archived the
+// same day, adding them to their log if it is stored. Details of logs outside
+// the restored range are left out.
+func (s *archiveService) restoreDetails(ctx context.Context, restore domain.ArchiveRestore, headers map[string]*domain.APILogHeaders, bodies map[string]*domain.APILogBody, result *domain.RestoreResult) error {
+	logIDs := make([]string, 0, len(headers)+len(bodies))
+	for logID := range headers {
+		logIDs = append(logIDs, logID)
+	}
+	for logID := range bodies {
+		if _, ok := headers[logID]; !ok {
+			logIDs = append(logIDs, logID)
+		}
+	}
+
+	batch := &restoreBatch{service: s, result: result}
+	for _, logID := range logIDs {
+		log, err := s.logService.GetLog(ctx, restoredLogID(restore, logID))
+		if err == domain.ErrLogNotFound {
+			continue
+		}
+		if err != nil {
+			return err
+		}
+
+		if err := s.openDetails(ctx, restore, logID, headers[logID], bodies[logID]); err != nil {
+			return err
+		}
+		log.User = nil
+		if err := batch.add(ctx, &domain.LogEntry{Log: log, Headers: headers[logID], Body: bodies[logID]}, false); err != nil {
+			return err
+		}
+	}
+	return batch.flush(ctx)
+}
+
+// restoreBatch collects restored entries and stores them restoreBatchSize at
+// a time, counting what was stored
+type restoreBatch struct {
+	service *archiveService
+	result  *domain.RestoreResult
+	entries []*domain.LogEntry
+	// newLogs reports, per entry, whether its log is restored with it rather
+	// than already stored
+	newLogs []bool
+}
+
+func (b *restoreBatch) add(ctx context.Context, entry *domain.LogEntry, newLog bool) error {
+	b.entries = append(b.entries, entry)
+	b.newLogs = append(b.newLogs, newLog)
+	if len(b.entries) >= restoreBatchSize {
+		return b.flush(ctx)
+	}
+	return nil
+}
+
+func (b *restoreBatch) flush(ctx context.Context) error {
+	if len(b.entries) == 0 {
+		return nil
+	}
+	errs, err := b.service.logService.CreateLogs(ctx, b.entries)
+	if err != nil {
+		return err
+	}
+	for i, err := range errs {
+		if err != nil {
+			b.result.Failed++
+			continue
+		}
+		if b.newLogs[i] {
+			b.result.Logs++
+		}
+		if b.entries[i].Headers != nil {
+			b.result.Headers++
+		}
+		if b.entries[i].Body != nil {
+			b.result.Bodies++
+		}
+	}
+	b.entries, b.newLogs = b.entries[:0], b.newLogs[:0]
+	return nil
+}
+
+// restoredLogID returns the ID an archived log is restored under
+func restoredLogID(restore domain.ArchiveRestore, logID string) string {
+	if restore.ProjectID != restore.SourceProjectID {
+		return idempotentLogID(restore.ProjectID, logID)
+	}
+	return logID
+}
+
+// restoredEntry turns an archived log, with its archived details, into an
+// entry of the target project
+func (s *archiveService) restoredEntry(ctx context.Context, restore domain.ArchiveRestore, log *domain.APILog, headers *domain.APILogHeaders, body *domain.APILogBody) (*domain.LogEntry, error) {
+	if err := s.openDetails(ctx, restore, log.ID, headers, body); err != nil {
+		return nil, err
+	}
+
+	if restore.ProjectID != restore.SourceProjectID {
+		log.ID = restoredLogID(restore, log.ID)
+		log.ProjectID = restore.ProjectID
+		log.UserID = nil // users belong to the source project
+	}
+	log.User = nil
+	return &domain.LogEntry{Log: log, Headers: headers, Body: body}, nil
+}
+
+// openDetails opens the details of an archived log that were archived
+// encrypted, with the source project's key; they are stored again under the
+// target's
+func (s *archiveService) openDetails(ctx context.Context, restore domain.ArchiveRestore, logID string, headers *domain.APILogHeaders, body *domain.APILogBody) error {
+	if s.decrypter == nil {
+		return nil
+	}
+	if headers != nil {
+		if err := s.decrypter.DecryptHeaders(ctx, restore.SourceProjectID, headers); err != nil {
+			return fmt.Errorf("decrypt headers of log %s: %w", logID, err)
+		}
+	}
+	if body != nil {
+		if err := s.decrypter.DecryptBody(ctx, restore.SourceProjectID, body); err != nil {
+			return fmt.Errorf("decrypt body of log %s: %w", logID, err)
+		}
+	}
+	return nil
+}
+
+// readArchived decodes the records of an archived object as T
+func readArchived[T any](ctx context.Context, a *archive.Archive, key string, fn func(*T) error) error {
+	return a.Read(ctx, key, func(record json.RawMessage) error {
+		var item T
+		if err := json.Unmarshal(record, &item); err != nil {
+			return fmt.Errorf("decode %s: %w", key, err)
+		}
+		return fn(&item)
+	})
+}
@@ -0,0 +1,73 @@
+package service
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/spidey52/api-logs/internal/adapters/secondary/repository/inmemory"
+	"github.com/spidey52/api-logs/internal/domain"
+	"github.com/spidey52/api-logs/pkg/archive"
+)
+
+func TestRestoreDetailsArchivedOnAnotherDay(t *testing.T) {
+	ctx := domain.SystemContext(context.Background())
+	sink, err := archive.NewFileSink(t.TempDir())
+	if err != nil {
+		t.Fatal(err)
+	}
+	archived := archive.New(sink)
+
+	projectRepo := inmemory.NewProjectRepository()
+	if err := projectRepo.Create(ctx, &domain.Project{ID: "p1", Name: "archived", Environment: domain.EnvironmentDev}); err != nil {
+		t.Fatal(err)
+	}
+	authorizer := NewAuthorizer(inmemory.NewProjectMemberRepository(), inmemory.NewAccessLogRepository())
+	logRepo := inmemory.NewAPILogRepository()
+	headersRepo := inmemory.NewHeadersRepository()
+	bodyRepo := inmemory.NewBodyRepository()
+	rollups := NewRollupService(logRepo, inmemory.NewLogSummaryRepository(), projectRepo, RollupOptions{})
+	logs := NewAPILogService(logRepo, headersRepo, bodyRepo, inmemory.NewUserRepository(), authorizer, nil, rollups)
+	restorer := NewArchiveService(archived, projectRepo, logs, authorizer, nil)
+
+	day1 := time.Date(2026, 1, 7, 0, 0, 0, 0, time.UTC)
+	day2 := day1.Add(24 * time.Hour)
+	log := func(id string, ts time.Time) any {
+		return &domain.APILog{ID: id, ProjectID: "p1", Environment: domain.EnvironmentDev, Method: domain.MethodGET, Path: "/" + id, StatusCode: 200, Timestamp: ts}
+	}
+	headers := func(logID string) any {
+		return &domain.APILogHeaders{LogID: logID, ProjectID: "p1", RequestHeaders: map[string]any{"X-Log": logID}}
+	}
+	write := func(kind string, day time.Time, records ...any) {
+		if _, err := archived.Write(ctx, "p1", kind, day, records); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	// "same" has its headers archived with it; "late" was stored just before
+	// midnight and its headers after; "outside" was archived before the range
+	write(string(domain.DataLogs), day1, log("same", day1.Add(time.Hour)), log("late", day2.Add(-time.Second)))
+	write(string(domain.DataHeaders), day1, headers("same"))
+	write(string(domain.DataHeaders), day2, headers("late"), headers("outside"))
+
+	result, err := restorer.Restore(ctx, domain.ArchiveRestore{SourceProjectID: "p1", ProjectID: "p1", From: day1, To: day2})
+	if err != nil {
+		t.Fatalf("restore: %v", err)
+	}
+	if result.Objects != 3 || result.Logs != 2 || result.Headers != 2 || result.Failed != 0 {
+		t.Fatalf("unexpected result: %+v", result)
+	}
+
+	for _, logID := range []string{"same", "late"} {
+		h, err := headersRepo.FindByLogID(ctx, logID)
+		if err != nil {
+			t.Fatalf("headers of %s: %v", logID, err)
+		}
+		if h.RequestHeaders["X-Log"] != logID {
+			t.Fatalf("headers of %s mismatch: %+v", logID, h.RequestHeaders)
+		}
+	}
+	if _, err := headersRepo.FindByLogID(ctx, "outside"); err == nil {
+		t.Fatal("headers of a log outside the range were restored")
+	}
+}
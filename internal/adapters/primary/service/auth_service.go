@@ -0,0 +1,256 @@
+package service
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/spidey52/api-logs/internal/domain"
+	"github.com/spidey52/api-logs/internal/ports/input"
+	"github.com/spidey52/api-logs/internal/ports/output"
+	"github.com/spidey52/api-logs/pkg/logger"
+	"golang.org/x/crypto/bcrypt"
+)
+
+// dummyPasswordHash is compared against when the username is unknown so a
+// failed login takes the same time whether or not the account exists
+var dummyPasswordHash, _ = bcrypt.GenerateFromPassword([]byte("api-logs-dummy-password"), bcrypt.DefaultCost)
+
+// authService implements the AuthService interface
+type authService struct {
+	accountRepo output.AccountRepository
+	sessionRepo output.SessionRepository
+	sessionTTL  time.Duration
+}
+
+// NewAuthService creates a new instance of AuthService
+func NewAuthService(accountRepo output.AccountRepository, sessionRepo output.SessionRepository, sessionTTL time.Duration) input.AuthService {
+	return &authService{
+		accountRepo: accountRepo,
+		sessionRepo: sessionRepo,
+		sessionTTL:  sessionTTL,
+	}
+}
+
+// hashToken returns the stored form of a bearer token
+func hashToken(token string) string {
+	sum := sha256.Sum256([]byte(token))
+	return hex.EncodeToString(sum[:])
+}
+
+// generateSessionToken generates a random bearer token
+func generateSessionToken() (string, error) {
+	bytes := make([]byte, 32)
+	if _, err := rand.Read(bytes); err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(bytes), nil
+}
+
+// Login verifies credentials and returns a new session with its bearer token
+func (s *authService) Login(ctx context.Context, username, password string) (*domain.Session, string, *domain.Account, error) {
+	account, err := s.accountRepo.FindByUsername(ctx, domain.NormalizeUsername(username))
+	if err != nil {
+		if err != domain.ErrAccountNotFound {
+			return nil, "", nil, err
+		}
+		_ = bcrypt.CompareHashAndPassword(dummyPasswordHash, []byte(password))
+		return nil, "", nil, domain.ErrInvalidCredentials
+	}
+
+	if err := bcrypt.CompareHashAndPassword([]byte(account.PasswordHash), []byte(password)); err != nil {
+		return nil, "", nil, domain.ErrInvalidCredentials
+	}
+	if !account.IsActive {
+		return nil, "", nil, domain.ErrInvalidCredentials
+	}
+
+	token, err := generateSessionToken()
+	if err != nil {
+		return nil, "", nil, err
+	}
+
+	now := time.Now()
+	session := &domain.Session{
+		ID:        uuid.New().String(),
+		AccountID: account.ID,
+		TokenHash: hashToken(token),
+		CreatedAt: now,
+		ExpiresAt: now.Add(s.sessionTTL),
+	}
+	if err := s.sessionRepo.Create(ctx, session); err != nil {
+		return nil, "", nil, err
+	}
+
+	account.LastLoginAt = &now
+	if err := s.accountRepo.Update(ctx, account); err != nil {
+		logger.Warn("failed to record last login", "account_id", account.ID, "error", err)
+	}
+
+	// Opportunistically clean up sessions nobody will present again
+	if _, err := s.sessionRepo.DeleteExpired(ctx, now); err != nil {
+		logger.Warn("failed to delete expired sessions", "error", err)
+	}
+
+	return session, token, account, nil
+}
+
+// Logout revokes the session identified by the bearer token
+func (s *authService) Logout(ctx context.Context, token string) error {
+	session, err := s.sessionRepo.FindByTokenHash(ctx, hashToken(token))
+	if err != nil {
+		if err == domain.ErrSessionNotFound {
+			return domain.ErrUnauthorized
+		}
+		return err
+	}
+	return s.sessionRepo.Delete(ctx, session.ID)
+}
+
+// Authenticate resolves a bearer token to its active account
+func (s *authService) Authenticate(ctx context.Context, token string) (*domain.Account, error) {
+	if token == "" {
+		return nil, domain.ErrUnauthorized
+	}
+
+	session, err := s.sessionRepo.FindByTokenHash(ctx, hashToken(token))
+	if err != nil {
+		if err == domain.ErrSessionNotFound {
+			return nil, domain.ErrUnauthorized
+		}
+		return nil, err
+	}
+
+	if session.IsExpired(time.Now()) {
+		_ = s.sessionRepo.Delete(ctx, session.ID)
+		return nil, domain.ErrUnauthorized
+	}
+
+	account, err := s.accountRepo.FindByID(ctx, session.AccountID)
+	if err != nil {
+		if err == domain.ErrAccountNotFound {
+			return nil, domain.ErrUnauthorized
+		}
+		return nil, err
+	}
+	if !account.IsActive {
+		return nil, domain.ErrUnauthorized
+	}
+
+	return account, nil
+}
+
+// requireAdmin returns ErrForbidden unless the caller is an admin account or the system itself
+func requireAdmin(ctx context.Context) error {
+	principal, ok := domain.PrincipalFromContext(ctx)
+	if !ok || !(principal.IsAdmin() || principal.IsSystem()) {
+		return domain.ErrForbidden
+	}
+	return nil
+}
+
+// CreateAccount creates a new account with a hashed password
+func (s *authService) CreateAccount(ctx context.Context, username, password string, isAdmin bool) (*domain.Account, error) {
+	if err := requireAdmin(ctx); err != nil {
+		return nil, err
+	}
+	return s.createAccount(ctx, username, password, isAdmin)
+}
+
+func (s *authService) createAccount(ctx context.Context, username, password string, isAdmin bool) (*domain.Account, error) {
+	if err := domain.ValidatePassword(password); err != nil {
+		return nil, err
+	}
+
+	hash, err := bcrypt.GenerateFromPassword([]byte(password), bcrypt.DefaultCost)
+	if err != nil {
+		return nil, err
+	}
+
+	now := time.Now()
+	account := &domain.Account{
+		ID:           uuid.New().String(),
+		Username:     domain.NormalizeUsername(username),
+		PasswordHash: string(hash),
+		IsActive:     true,
+		IsAdmin:      isAdmin,
+		CreatedAt:    now,
+		UpdatedAt:    now,
+	}
+	if err := account.Validate(); err != nil {
+		return nil, err
+	}
+
+	if err := s.accountRepo.Create(ctx, account); err != nil {
+		return nil, err
+	}
+	return account, nil
+}
+
+// ListAccounts retrieves all accounts
+func (s *authService) ListAccounts(ctx context.Context) ([]*domain.Account, error) {
+	if err := requireAdmin(ctx); err != nil {
+		return nil, err
+	}
+	return s.accountRepo.List(ctx)
+}
+
+// ChangePassword replaces an account's password and revokes its sessions
+func (s *authService) ChangePassword(ctx context.Context, accountID, currentPassword, newPassword string) error {
+	account, err := s.accountRepo.FindByID(ctx, accountID)
+	if err != nil {
+		return err
+	}
+
+	if err := bcrypt.CompareHashAndPassword([]byte(account.PasswordHash), []byte(currentPassword)); err != nil {
+		return domain.ErrInvalidCredentials
+	}
+	if err := domain.ValidatePassword(newPassword); err != nil {
+		return err
+	}
+
+	hash, err := bcrypt.GenerateFromPassword([]byte(newPassword), bcrypt.DefaultCost)
+	if err != nil {
+		return err
+	}
+
+	account.PasswordHash = string(hash)
+	account.UpdatedAt = time.Now()
+	if err := s.accountRepo.Update(ctx, account); err != nil {
+		return err
+	}
+
+	return s.sessionRepo.DeleteByAccountID(ctx, account.ID)
+}
+
+// EnsureAdminAccount creates the admin account if no account with that username
+// exists, and grants admin to an existing account of that name
+func (s *authService) EnsureAdminAccount(ctx context.Context, username, password string) error {
+	existing, err := s.accountRepo.FindByUsername(ctx, domain.NormalizeUsername(username))
+	if err == nil {
+		if existing.IsAdmin {
+			return nil
+		}
+		existing.IsAdmin = true
+		existing.UpdatedAt = time.Now()
+		if err := s.accountRepo.Update(ctx, existing); err != nil {
+			return err
+		}
+		logger.Info("granted admin to bootstrap account", "username", existing.Username)
+		return nil
+	}
+	if err != domain.ErrAccountNotFound {
+		return err
+	}
+
+	account, err := s.createAccount(ctx, username, password, true)
+	if err != nil {
+		return err
+	}
+
+	logger.Info("created bootstrap admin account", "username", account.Username)
+	return nil
+}
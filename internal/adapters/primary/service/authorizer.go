@@ -0,0 +1,156 @@
+package service
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/spidey52/api-logs/internal/domain"
+	"github.com/spidey52/api-logs/internal/ports/output"
+	"github.com/spidey52/api-logs/pkg/logger"
+)
+
+// Access log actions recorded for authorization decisions
+const (
+	actionRead   = "read"
+	actionWrite  = "write"
+	actionDelete = "delete"
+	actionManage = "manage"
+)
+
+// Resource types recorded for authorization decisions
+const (
+	resourceProject   = "project"
+	resourceAPIKey    = "api_key"
+	resourceMember    = "project_member"
+	resourceAPILog    = "api_log"
+	resourceAccessLog = "access_log"
+	resourceUser      = "user"
+
+	resourceRedactionPolicy = "redaction_policy"
+	resourceRetentionPolicy = "retention_policy"
+)
+
+// Authorizer checks the principal in the context against its project role and
+// records denied attempts as access log events. It is shared by the services
+// so every entry point enforces the same rules.
+type Authorizer struct {
+	memberRepo    output.ProjectMemberRepository
+	accessLogRepo output.AccessLogRepository
+}
+
+// NewAuthorizer creates a new Authorizer
+func NewAuthorizer(memberRepo output.ProjectMemberRepository, accessLogRepo output.AccessLogRepository) *Authorizer {
+	return &Authorizer{
+		memberRepo:    memberRepo,
+		accessLogRepo: accessLogRepo,
+	}
+}
+
+// Authorize returns nil when the caller holds at least the required role on the
+// project, and domain.ErrForbidden otherwise. Calls without a principal are
+// denied; admin accounts and the system principal are allowed everywhere. API
+// keys act as editors of their own project.
+func (a *Authorizer) Authorize(ctx context.Context, projectID string, required domain.ProjectRole, action, resourceType, resourceID string) error {
+	principal, ok := domain.PrincipalFromContext(ctx)
+	if !ok {
+		return domain.ErrForbidden
+	}
+	if principal.IsAdmin() || principal.IsSystem() {
+		return nil
+	}
+
+	role, err := a.roleOf(ctx, principal, projectID)
+	if err != nil {
+		return err
+	}
+	if role.Allows(required) {
+		return nil
+	}
+
+	a.recordDenied(ctx, principal, projectID, role, required, action, resourceType, resourceID)
+	return domain.ErrForbidden
+}
+
+// ProjectIDs returns the projects the caller may read, or nil when the caller is
+// not restricted to a set of projects
+func (a *Authorizer) ProjectIDs(ctx context.Context) ([]string, error) {
+	principal, ok := domain.PrincipalFromContext(ctx)
+	if !ok {
+		return nil, domain.ErrForbidden
+	}
+	if principal.IsAdmin() || principal.IsSystem() {
+		return nil, nil
+	}
+
+	if principal.ActorType == domain.ActorAPIKey {
+		return []string{principal.ProjectID}, nil
+	}
+
+	members, err := a.memberRepo.ListByAccount(ctx, principal.ActorID)
+	if err != nil {
+		return nil, err
+	}
+
+	ids := make([]string, 0, len(members))
+	for _, member := range members {
+		ids = append(ids, member.ProjectID)
+	}
+	return ids, nil
+}
+
+// roleOf returns the principal's role on the project, or an empty role if it has none
+func (a *Authorizer) roleOf(ctx context.Context, principal *domain.Principal, projectID string) (domain.ProjectRole, error) {
+	if projectID == "" {
+		return "", nil
+	}
+
+	if principal.ActorType == domain.ActorAPIKey {
+		if principal.ProjectID == projectID {
+			return domain.RoleEditor, nil
+		}
+		return "", nil
+	}
+
+	member, err := a.memberRepo.Find(ctx, projectID, principal.ActorID)
+	if err != nil {
+		if err == domain.ErrMemberNotFound {
+			return "", nil
+		}
+		return "", err
+	}
+	return member.Role, nil
+}
+
+// recordDenied stores a denied access log event; failures are logged and do not
+// change the outcome of the request
+func (a *Authorizer) recordDenied(ctx context.Context, principal *domain.Principal, projectID string, role, required domain.ProjectRole, action, resourceType, resourceID string) {
+	// Access logs belong to a project, so requests without one cannot be recorded
+	if projectID == "" {
+		return
+	}
+
+	metadata := map[string]any{
+		"required_role": required.String(),
+		"role":          role.String(),
+	}
+	if principal.Account != nil {
+		metadata["username"] = principal.Account.Username
+	}
+
+	event := &domain.AccessLog{
+		ProjectID:    projectID,
+		ActorID:      principal.ActorID,
+		ActorType:    principal.ActorType,
+		Action:       action,
+		ResourceType: resourceType,
+		ResourceID:   resourceID,
+		Outcome:      domain.OutcomeDenied,
+		Message:      fmt.Sprintf("%s %s requires the %s role", action, resourceType, required),
+		Metadata:     metadata,
+	}
+	event.SetDefaults()
+
+	if err := a.accessLogRepo.Create(ctx, event); err != nil {
+		logger.Warn("failed to record denied access", "project_id", projectID, "actor_id", principal.ActorID, "error", err)
+	}
+}
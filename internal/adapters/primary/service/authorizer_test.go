@@ -0,0 +1,234 @@
+package service
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/spidey52/api-logs/internal/adapters/secondary/repository/inmemory"
+	"github.com/spidey52/api-logs/internal/domain"
+	"github.com/spidey52/api-logs/internal/ports/input"
+	"github.com/spidey52/api-logs/internal/ports/output"
+)
+
+// testProjects holds the project, log and access log services over in-memory
+// repositories
+type testProjects struct {
+	input.ProjectService
+	logs       input.APILogService
+	accessLogs input.AccessLogService
+
+	projectRepo   output.ProjectRepository
+	memberRepo    output.ProjectMemberRepository
+	apiKeyRepo    output.APIKeyRepository
+	accessLogRepo output.AccessLogRepository
+	hasher        *KeyHasher
+}
+
+func newTestProjects(t *testing.T, rotationGrace time.Duration) *testProjects {
+	t.Helper()
+	p := &testProjects{
+		projectRepo:   inmemory.NewProjectRepository(),
+		memberRepo:    inmemory.NewProjectMemberRepository(),
+		apiKeyRepo:    inmemory.NewAPIKeyRepository(),
+		accessLogRepo: inmemory.NewAccessLogRepository(),
+		hasher:        NewKeyHasher("test-secret"),
+	}
+	authorizer := NewAuthorizer(p.memberRepo, p.accessLogRepo)
+
+	logRepo := inmemory.NewAPILogRepository()
+	headersRepo := inmemory.NewHeadersRepository()
+	bodyRepo := inmemory.NewBodyRepository()
+	userRepo := inmemory.NewUserRepository()
+	summaryRepo := inmemory.NewLogSummaryRepository()
+	rollups := NewRollupService(logRepo, summaryRepo, p.projectRepo, RollupOptions{})
+
+	data := ProjectData{
+		Logs:              logRepo,
+		Headers:           headersRepo,
+		Bodies:            bodyRepo,
+		AccessLogs:        p.accessLogRepo,
+		Summaries:         summaryRepo,
+		Users:             userRepo,
+		Quotas:            inmemory.NewQuotaRepository(),
+		RedactionPolicies: inmemory.NewRedactionPolicyRepository(),
+		DataKeys:          inmemory.NewDataKeyRepository(),
+	}
+	p.ProjectService = NewProjectService(p.projectRepo, p.memberRepo, inmemory.NewAccountRepository(), p.apiKeyRepo, authorizer, p.hasher, data, rotationGrace)
+	p.logs = NewAPILogService(logRepo, headersRepo, bodyRepo, userRepo, authorizer, nil, rollups)
+	p.accessLogs = NewAccessLogService(p.accessLogRepo, authorizer)
+	return p
+}
+
+// createProject creates a project as an admin, returning it with its plaintext key
+func (p *testProjects) createProject(t *testing.T) *domain.Project {
+	t.Helper()
+	project := &domain.Project{Name: "shop", Environment: domain.EnvironmentDev}
+	if err := p.CreateProject(adminContext(), project); err != nil {
+		t.Fatalf("create project: %v", err)
+	}
+	return project
+}
+
+// addMember gives the account a role on the project, returning a context
+// calling as that account
+func (p *testProjects) addMember(t *testing.T, projectID, accountID string, role domain.ProjectRole) context.Context {
+	t.Helper()
+	member := &domain.ProjectMember{ID: uuid.New().String(), ProjectID: projectID, AccountID: accountID, Role: role}
+	if err := p.memberRepo.Create(context.Background(), member); err != nil {
+		t.Fatal(err)
+	}
+	return accountContext(accountID)
+}
+
+// deniedCount counts the denied access log events recorded for an actor
+func (p *testProjects) deniedCount(t *testing.T, projectID, actorID string) int {
+	t.Helper()
+	denied := domain.OutcomeDenied
+	events, err := p.accessLogRepo.FindByFilter(context.Background(), domain.AccessLogFilter{
+		SharedFilter: domain.SharedFilter{Limit: 100},
+		ProjectID:    projectID,
+		ActorID:      actorID,
+		Outcome:      &denied,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	return len(events)
+}
+
+func adminContext() context.Context {
+	return accountContext("admin", func(a *domain.Account) { a.IsAdmin = true })
+}
+
+func accountContext(accountID string, opts ...func(*domain.Account)) context.Context {
+	account := &domain.Account{ID: accountID, Username: accountID, IsActive: true}
+	for _, opt := range opts {
+		opt(account)
+	}
+	return domain.ContextWithPrincipal(context.Background(), domain.AccountPrincipal(account))
+}
+
+// protectedCalls are service calls and the least role each requires
+var protectedCalls = []struct {
+	name     string
+	required domain.ProjectRole
+	call     func(ctx context.Context, p *testProjects, projectID string) error
+}{
+	{"get project", domain.RoleViewer, func(ctx context.Context, p *testProjects, projectID string) error {
+		_, err := p.GetProject(ctx, projectID)
+		return err
+	}},
+	{"list logs", domain.RoleViewer, func(ctx context.Context, p *testProjects, projectID string) error {
+		_, err := p.logs.ListLogs(ctx, domain.LogFilter{ProjectID: projectID})
+		return err
+	}},
+	{"list access logs", domain.RoleViewer, func(ctx context.Context, p *testProjects, projectID string) error {
+		_, err := p.accessLogs.GetLogs(ctx, domain.AccessLogFilter{ProjectID: projectID})
+		return err
+	}},
+	{"update project", domain.RoleEditor, func(ctx context.Context, p *testProjects, projectID string) error {
+		return p.UpdateProject(ctx, &domain.Project{ID: projectID, Name: "renamed", Environment: domain.EnvironmentDev})
+	}},
+	{"create access log", domain.RoleEditor, func(ctx context.Context, p *testProjects, projectID string) error {
+		return p.accessLogs.CreateLog(ctx, &domain.AccessLog{ProjectID: projectID, ActorID: "svc", ActorType: domain.ActorService, Action: "login", Outcome: domain.OutcomeSuccess})
+	}},
+	{"regenerate API key", domain.RoleOwner, func(ctx context.Context, p *testProjects, projectID string) error {
+		_, err := p.RegenerateAPIKey(ctx, projectID, nil)
+		return err
+	}},
+	{"delete project", domain.RoleOwner, func(ctx context.Context, p *testProjects, projectID string) error {
+		return p.DeleteProject(ctx, projectID)
+	}},
+}
+
+func TestProjectRoles(t *testing.T) {
+	for _, call := range protectedCalls {
+		for _, role := range []domain.ProjectRole{domain.RoleViewer, domain.RoleEditor, domain.RoleOwner} {
+			t.Run(call.name+" as "+role.String(), func(t *testing.T) {
+				p := newTestProjects(t, 0)
+				project := p.createProject(t)
+				ctx := p.addMember(t, project.ID, "a1", role)
+
+				err := call.call(ctx, p, project.ID)
+				if !role.Allows(call.required) {
+					if err != domain.ErrForbidden {
+						t.Fatalf("want ErrForbidden, got %v", err)
+					}
+					if denied := p.deniedCount(t, project.ID, "a1"); denied != 1 {
+						t.Fatalf("want the denied call recorded once, got %d events", denied)
+					}
+					return
+				}
+				if err != nil {
+					t.Fatalf("want the call allowed, got %v", err)
+				}
+				if denied := p.deniedCount(t, project.ID, "a1"); denied != 0 {
+					t.Fatalf("want no denied events, got %d", denied)
+				}
+			})
+		}
+	}
+}
+
+func TestAuthorizeDeniesNonMembers(t *testing.T) {
+	p := newTestProjects(t, 0)
+	project := p.createProject(t)
+	other := p.createProject(t)
+	// A member of another project has no role on this one
+	ctx := p.addMember(t, other.ID, "a1", domain.RoleOwner)
+
+	for _, call := range protectedCalls {
+		if err := call.call(ctx, p, project.ID); err != domain.ErrForbidden {
+			t.Fatalf("%s: want ErrForbidden, got %v", call.name, err)
+		}
+	}
+	if denied := p.deniedCount(t, project.ID, "a1"); denied != len(protectedCalls) {
+		t.Fatalf("want %d denied events, got %d", len(protectedCalls), denied)
+	}
+}
+
+func TestAuthorizeWithoutPrincipal(t *testing.T) {
+	p := newTestProjects(t, 0)
+	project := p.createProject(t)
+
+	for _, call := range protectedCalls {
+		if err := call.call(context.Background(), p, project.ID); err != domain.ErrForbidden {
+			t.Fatalf("%s: want ErrForbidden, got %v", call.name, err)
+		}
+	}
+	if _, err := p.ListProjects(context.Background(), domain.ProjectFilter{}); err != domain.ErrForbidden {
+		t.Fatalf("list projects: want ErrForbidden, got %v", err)
+	}
+	if _, err := p.GetProject(adminContext(), project.ID); err != nil {
+		t.Fatalf("want the project left in place, got %v", err)
+	}
+}
+
+func TestAPIKeyReachesOnlyItsProject(t *testing.T) {
+	p := newTestProjects(t, 0)
+	project := p.createProject(t)
+	other := p.createProject(t)
+	key := &domain.APIKey{ID: "k1", ProjectID: project.ID}
+	ctx := domain.ContextWithPrincipal(context.Background(), domain.APIKeyPrincipal(project, key))
+
+	// Keys act as editors of their own project
+	for _, call := range protectedCalls {
+		err := call.call(ctx, p, project.ID)
+		if domain.RoleEditor.Allows(call.required) {
+			if err != nil {
+				t.Fatalf("%s on its project: want allowed, got %v", call.name, err)
+			}
+		} else if err != domain.ErrForbidden {
+			t.Fatalf("%s on its project: want ErrForbidden, got %v", call.name, err)
+		}
+
+		if err := call.call(ctx, p, other.ID); err != domain.ErrForbidden {
+			t.Fatalf("%s on another project: want ErrForbidden, got %v", call.name, err)
+		}
+	}
+	if denied := p.deniedCount(t, other.ID, "k1"); denied != len(protectedCalls) {
+		t.Fatalf("want %d denied events on the other project, got %d", len(protectedCalls), denied)
+	}
+}
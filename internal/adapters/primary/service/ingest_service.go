@@ -0,0 +1,645 @@
+package service
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/spidey52/api-logs/internal/domain"
+	"github.com/spidey52/api-logs/internal/ports/input"
+	"github.com/spidey52/api-logs/pkg/cache"
+	"github.com/spidey52/api-logs/pkg/logger"
+	"github.com/spidey52/api-logs/pkg/wal"
+)
+
+// IngestOptions configures the ingestion pipeline
+type IngestOptions struct {
+	// QueueSize bounds how many logs may wait for storage
+	QueueSize int
+	// Workers is the number of concurrent writers
+	Workers int
+	// BatchSize and FlushInterval bound how many logs a writer collects, and
+	// for how long, before writing them
+	BatchSize     int
+	FlushInterval time.Duration
+	// DedupWindow is how long a client-supplied log ID is remembered, so a
+	// retried log is reported as a duplicate
+	DedupWindow time.Duration
+	// Normalize configures the validation and clean-up of accepted logs
+	Normalize NormalizeOptions
+}
+
+// idempotencyNamespace derives log IDs from idempotency keys
+var idempotencyNamespace = uuid.MustParse("6f1c0d2e-8a4b-4c57-9a3e-2b7d5e9f1a60")
+
+// idempotentLogID returns the log ID for an idempotency key. It depends only
+// on the project and the key, so every retry of a log gets the same ID.
+func idempotentLogID(projectID, key string) string {
+	return uuid.NewSHA1(idempotencyNamespace, []byte(projectID+"\x00"+key)).String()
+}
+
+// clientIDNamespace derives log IDs from client-supplied IDs
+var clientIDNamespace = uuid.MustParse("2347741f-9521-4fcc-8604-b6788cdadaae")
+
+// clientLogID returns the log ID for a client-supplied ID. Log IDs are global,
+// so the ID is derived from the project too: a project sending an ID another
+// project already used gets a log of its own instead of having it skipped.
+func clientLogID(projectID, id string) string {
+	return uuid.NewSHA1(clientIDNamespace, []byte(projectID+"\x00"+id)).String()
+}
+
+// ingestBatch is the accepted entries of one Enqueue call
+type ingestBatch struct {
+	entries []*domain.LogEntry
+	// pos is the batch's spool record, when spooled
+	pos     wal.Position
+	spooled bool
+	// claimed holds each entry's dedup key, empty for entries not claimed
+	claimed []string
+}
+
+// ingestService implements the IngestService interface. Requests hand their
+// logs to a bounded queue and return; a pool of workers drains the queue in
+// batches through the log service, so a slow database delays storage instead
+// of every client's request.
+//
+// With a spool, every batch is also appended to it before Enqueue returns, and
+// acknowledged once stored. Batches whose write fails, or that a crash left
+// unwritten, stay in the spool until Replay stores them.
+type ingestService struct {
+	logService input.APILogService
+	redaction  input.RedactionService
+	quotas     input.QuotaService
+	authorizer *Authorizer
+	normalizer *normalizer
+	spool      *wal.Log
+	seen       cache.Cache
+	opts       IngestOptions
+
+	queue chan *ingestBatch
+	// pending counts accepted logs not yet stored, whether still queued or in a
+	// worker's batch. Enqueue reserves room for a whole batch before sending, so
+	// sends never block and batches are never split.
+	pending atomic.Int64
+
+	// inflight holds the spool records of queued batches and batches being
+	// written, which Replay leaves to the workers
+	spoolMu  sync.Mutex
+	inflight map[wal.Position]bool
+	replayMu sync.Mutex
+
+	// mu guards closed; Enqueue holds it for reading while it sends, so Drain
+	// cannot close the queue under it
+	mu      sync.RWMutex
+	closed  bool
+	workers sync.WaitGroup
+}
+
+// NewIngestService creates the ingestion pipeline and starts its workers.
+// Accepted logs are masked by the redaction service before they are spooled
+// or queued, and counted against the daily quotas once validated and
+// deduplicated. A nil spool keeps accepted logs in memory only. seen remembers
+// the client supplied log IDs of the dedup window.
+func NewIngestService(logService input.APILogService, redaction input.RedactionService, quotas input.QuotaService, authorizer *Authorizer, spool *wal.Log, seen cache.Cache, opts IngestOptions) input.IngestService {
+	if opts.QueueSize <= 0 {
+		opts.QueueSize = 10000
+	}
+	if opts.Workers <= 0 {
+		opts.Workers = 1
+	}
+	if opts.BatchSize <= 0 {
+		opts.BatchSize = 500
+	}
+	if opts.FlushInterval <= 0 {
+		opts.FlushInterval = time.Second
+	}
+	if opts.DedupWindow <= 0 {
+		opts.DedupWindow = 24 * time.Hour
+	}
+
+	s := &ingestService{
+		logService: logService,
+		redaction:  redaction,
+		quotas:     quotas,
+		authorizer: authorizer,
+		normalizer: newNormalizer(opts.Normalize),
+		spool:      spool,
+		seen:       seen,
+		opts:       opts,
+		queue:      make(chan *ingestBatch, opts.QueueSize),
+		inflight:   make(map[wal.Position]bool),
+	}
+
+	if spool != nil {
+		if pending := len(spool.Pending()); pending > 0 {
+			logger.Info("Recovered spooled ingest batches for replay", "batches", pending)
+		}
+	}
+
+	s.workers.Add(opts.Workers)
+	for i := 0; i < opts.Workers; i++ {
+		go s.work()
+	}
+	return s
+}
+
+// Enqueue normalizes, validates, redacts and queues entries for storage.
+// Entries with fields that cannot be stored get a *domain.ValidationError.
+func (s *ingestService) Enqueue(ctx context.Context, entries []*domain.LogEntry) ([]error, error) {
+	results := make([]error, len(entries))
+
+	now := time.Now()
+	valid := make([]int, 0, len(entries))
+	authorized := make(map[string]bool)
+	for i, entry := range entries {
+		if entry.Log == nil {
+			results[i] = domain.ErrInvalidInput
+			continue
+		}
+		if err := s.normalizer.normalize(entry); err != nil {
+			results[i] = err
+			continue
+		}
+		if entry.Log.Validate() != nil {
+			results[i] = domain.ErrInvalidInput
+			continue
+		}
+
+		// Workers store logs without the caller's principal, so the caller is
+		// authorized here
+		projectID := entry.Log.ProjectID
+		if !authorized[projectID] {
+			if err := s.authorizer.Authorize(ctx, projectID, domain.RoleEditor, actionWrite, resourceAPILog, ""); err != nil {
+				return nil, err
+			}
+			authorized[projectID] = true
+		}
+
+		if entry.Log.Timestamp.IsZero() {
+			entry.Log.Timestamp = now
+		}
+		valid = append(valid, i)
+	}
+
+	// Secrets are masked before anything is spooled, so they never reach disk
+	redacted := make([]*domain.LogEntry, len(valid))
+	for j, i := range valid {
+		redacted[j] = entries[i]
+	}
+	if err := s.redaction.Redact(ctx, redacted); err != nil {
+		return nil, err
+	}
+	for _, entry := range redacted {
+		s.normalizer.limitBodies(entry)
+	}
+
+	// Logs with a client-supplied ID or idempotency key are claimed, so a
+	// retry within the dedup window is reported as a duplicate. Storage skips
+	// log IDs that already exist whether or not the claim was remembered.
+	accepted := make([]*domain.LogEntry, 0, len(valid))
+	claimed := make([]string, 0, len(valid))
+	for _, i := range valid {
+		log := entries[i].Log
+		switch {
+		case log.ID != "":
+			log.ID = clientLogID(log.ProjectID, log.ID)
+		case entries[i].IdempotencyKey != "":
+			log.ID = idempotentLogID(log.ProjectID, entries[i].IdempotencyKey)
+		default:
+			log.ID = uuid.New().String()
+			accepted = append(accepted, entries[i])
+			claimed = append(claimed, "")
+			continue
+		}
+
+		key, ok := s.claim(ctx, log.ProjectID, log.ID)
+		if !ok {
+			results[i] = domain.ErrDuplicateLog
+			continue
+		}
+		accepted = append(accepted, entries[i])
+		claimed = append(claimed, key)
+	}
+
+	if len(accepted) == 0 {
+		return results, nil
+	}
+
+	counts, err := s.countLogs(ctx, accepted)
+	if err != nil {
+		s.unclaim(ctx, claimed)
+		return nil, err
+	}
+
+	if err := s.queueBatch(&ingestBatch{entries: accepted, claimed: claimed}); err != nil {
+		// The logs were not accepted, so a retry must not count as a duplicate
+		// or against the quota
+		s.unclaim(ctx, claimed)
+		s.releaseLogs(ctx, counts)
+		return nil, err
+	}
+	return results, nil
+}
+
+// projectLogs is how many logs of a batch belong to a project
+type projectLogs struct {
+	projectID string
+	logs      int
+}
+
+// countLogs counts accepted entries against their project's daily quota, and
+// the calling key's, and returns what it counted. When a project is over its
+// quota, the projects already counted are released.
+func (s *ingestService) countLogs(ctx context.Context, entries []*domain.LogEntry) ([]projectLogs, error) {
+	var counts []projectLogs
+	index := make(map[string]int)
+	for _, entry := range entries {
+		projectID := entry.Log.ProjectID
+		i, ok := index[projectID]
+		if !ok {
+			i = len(counts)
+			index[projectID] = i
+			counts = append(counts, projectLogs{projectID: projectID})
+		}
+		counts[i].logs++
+	}
+
+	keyID := callingKeyID(ctx)
+	for i, count := range counts {
+		if err := s.quotas.CountLogs(ctx, count.projectID, keyID, count.logs); err != nil {
+			s.releaseLogs(ctx, counts[:i])
+			return nil, err
+		}
+	}
+	return counts, nil
+}
+
+// releaseLogs takes counted logs back out of the quotas. A failure only
+// leaves the day's counts high, so it is logged.
+func (s *ingestService) releaseLogs(ctx context.Context, counts []projectLogs) {
+	keyID := callingKeyID(ctx)
+	for _, count := range counts {
+		if err := s.quotas.ReleaseLogs(ctx, count.projectID, keyID, count.logs); err != nil {
+			logger.Warn("Failed to release counted logs", "project_id", count.projectID, "error", err)
+		}
+	}
+}
+
+// callingKeyID returns the ID of the API key making the call, if any
+func callingKeyID(ctx context.Context) string {
+	if principal, ok := domain.PrincipalFromContext(ctx); ok && principal.ActorType == domain.ActorAPIKey {
+		return principal.ActorID
+	}
+	return ""
+}
+
+// queueBatch reserves room for a batch, spools it and queues it
+func (s *ingestService) queueBatch(batch *ingestBatch) error {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	if s.closed {
+		return domain.ErrIngestStopped
+	}
+	if !s.reserve(int64(len(batch.entries))) {
+		return domain.ErrIngestQueueFull
+	}
+
+	if s.spool != nil {
+		if err := s.spoolBatch(batch); err != nil {
+			s.pending.Add(-int64(len(batch.entries)))
+			return err
+		}
+	}
+	s.queue <- batch
+	return nil
+}
+
+func dedupKey(projectID, logID string) string {
+	return "ingest:seen:" + projectID + ":" + logID
+}
+
+// claim records a log ID as ingested for the dedup window, reporting false if
+// it already was. It returns the key to release if the log is not accepted
+// after all. Without a working cache every log is reported as new.
+func (s *ingestService) claim(ctx context.Context, projectID, logID string) (string, bool) {
+	if s.seen == nil {
+		return "", true
+	}
+
+	// The key is set with its expiry at once, so a failure can never leave
+	// a log ID claimed for good
+	key := dedupKey(projectID, logID)
+	claimed, err := s.seen.SetNX(ctx, key, true, s.opts.DedupWindow)
+	if err != nil {
+		logger.Warn("Failed to check for a duplicate log", "log_id", logID, "error", err)
+		return "", true
+	}
+	if !claimed {
+		return "", false
+	}
+	return key, true
+}
+
+// unclaim releases claimed log IDs, skipping empty keys
+func (s *ingestService) unclaim(ctx context.Context, keys []string) {
+	for _, key := range keys {
+		if key == "" {
+			continue
+		}
+		if err := s.seen.Delete(ctx, key); err != nil {
+			logger.Warn("Failed to release a claimed log ID", "key", key, "error", err)
+		}
+	}
+}
+
+// spoolBatch appends a batch to the spool and marks it in flight. The record
+// is appended and marked under spoolMu, which Replay holds while it checks
+// records, so Replay never sees it pending but not in flight.
+func (s *ingestService) spoolBatch(batch *ingestBatch) error {
+	data, err := json.Marshal(batch.entries)
+	if err != nil {
+		return fmt.Errorf("encode ingest batch: %w", err)
+	}
+
+	s.spoolMu.Lock()
+	defer s.spoolMu.Unlock()
+
+	pos, err := s.spool.Append(data)
+	if err != nil {
+		if errors.Is(err, wal.ErrFull) {
+			return domain.ErrIngestSpoolFull
+		}
+		if errors.Is(err, wal.ErrTooLarge) {
+			return domain.ErrIngestTooLarge
+		}
+		return err
+	}
+	s.inflight[pos] = true
+
+	batch.pos, batch.spooled = pos, true
+	return nil
+}
+
+// reserve claims room for n logs, failing when the queue would overflow
+func (s *ingestService) reserve(n int64) bool {
+	for {
+		pending := s.pending.Load()
+		if pending+n > int64(s.opts.QueueSize) {
+			return false
+		}
+		if s.pending.CompareAndSwap(pending, pending+n) {
+			return true
+		}
+	}
+}
+
+// Drain stops accepting entries and waits for the workers to store the queued
+// ones, or for ctx to end
+func (s *ingestService) Drain(ctx context.Context) error {
+	s.mu.Lock()
+	if !s.closed {
+		s.closed = true
+		close(s.queue)
+	}
+	s.mu.Unlock()
+
+	done := make(chan struct{})
+	go func() {
+		s.workers.Wait()
+		close(done)
+	}()
+
+	select {
+	case <-done:
+		return nil
+	case <-ctx.Done():
+		logger.Error("Ingest queue not drained", "pending", s.pending.Load())
+		return ctx.Err()
+	}
+}
+
+// work collects queued batches until they hold BatchSize entries, writing them
+// when full or FlushInterval after the first one arrived, until the queue is
+// closed and empty
+func (s *ingestService) work() {
+	defer s.workers.Done()
+
+	var batches []*ingestBatch
+	count := 0
+	timer := time.NewTimer(s.opts.FlushInterval)
+	timer.Stop()
+
+	flush := func() {
+		if len(batches) == 0 {
+			return
+		}
+		s.write(batches)
+		batches, count = nil, 0
+	}
+
+	for {
+		select {
+		case batch, ok := <-s.queue:
+			if !ok {
+				timer.Stop()
+				flush()
+				return
+			}
+
+			if len(batches) == 0 {
+				timer.Reset(s.opts.FlushInterval)
+			}
+			batches = append(batches, batch)
+			count += len(batch.entries)
+			if count >= s.opts.BatchSize {
+				timer.Stop()
+				flush()
+			}
+		case <-timer.C:
+			flush()
+		}
+	}
+}
+
+// write stores the entries of the batches together. Enqueue already authorized
+// the callers, so they are written as the system.
+func (s *ingestService) write(batches []*ingestBatch) {
+	var entries []*domain.LogEntry
+	for _, batch := range batches {
+		entries = append(entries, batch.entries...)
+	}
+	defer s.pending.Add(-int64(len(entries)))
+
+	errs, err := s.logService.CreateLogs(domain.SystemContext(context.Background()), entries)
+	if err != nil {
+		if s.spool != nil {
+			logger.Error("Failed to store ingested logs; keeping them spooled for replay", "count", len(entries), "error", err)
+		} else {
+			logger.Error("Failed to store ingested logs", "count", len(entries), "error", err)
+			// The logs are lost, so a retry must be stored rather than reported
+			// as a duplicate
+			for _, batch := range batches {
+				s.unclaim(context.Background(), batch.claimed)
+			}
+		}
+		s.release(batches, false)
+		return
+	}
+	// Entries rejected on their own would fail again, so they are not replayed
+	s.release(batches, true)
+
+	// Rejected entries were not stored, so a retry must not count as a
+	// duplicate
+	var rejected []string
+	offset := 0
+	for _, batch := range batches {
+		for i, key := range batch.claimed {
+			if errs[offset+i] != nil {
+				rejected = append(rejected, key)
+			}
+		}
+		offset += len(batch.entries)
+	}
+	s.unclaim(context.Background(), rejected)
+
+	failed := 0
+	var firstErr error
+	for _, err := range errs {
+		if err != nil {
+			if firstErr == nil {
+				firstErr = err
+			}
+			failed++
+		}
+	}
+	if failed > 0 {
+		logger.Error("Failed to store ingested logs", "count", failed, "batch", len(entries), "error", firstErr)
+	}
+}
+
+// release hands the spool records of written batches back, acknowledging them
+// when stored and otherwise leaving them to Replay
+func (s *ingestService) release(batches []*ingestBatch, stored bool) {
+	if s.spool == nil {
+		return
+	}
+
+	for _, batch := range batches {
+		if stored {
+			if err := s.spool.Ack(batch.pos); err != nil {
+				logger.Error("Failed to acknowledge spooled ingest batch", "segment", batch.pos.Segment, "error", err)
+			}
+		}
+
+		s.spoolMu.Lock()
+		delete(s.inflight, batch.pos)
+		s.spoolMu.Unlock()
+	}
+}
+
+// Replay stores the spooled batches that are not queued or being written,
+// oldest first, collecting up to BatchSize logs per write. Enqueue authorized
+// the callers before spooling, so they are written as the system.
+func (s *ingestService) Replay(ctx context.Context) (int, error) {
+	if s.spool == nil {
+		return 0, nil
+	}
+	ctx = domain.SystemContext(ctx)
+
+	s.replayMu.Lock()
+	defer s.replayMu.Unlock()
+
+	stored := 0
+	var positions []wal.Position
+	var entries []*domain.LogEntry
+	flush := func() error {
+		if len(positions) == 0 {
+			return nil
+		}
+		if _, err := s.logService.CreateLogs(ctx, entries); err != nil {
+			return err
+		}
+		for _, pos := range positions {
+			if err := s.spool.Ack(pos); err != nil {
+				return err
+			}
+		}
+		stored += len(entries)
+		positions, entries = nil, nil
+		return nil
+	}
+
+	for _, pos := range s.spool.Pending() {
+		s.spoolMu.Lock()
+		inflight := s.inflight[pos]
+		s.spoolMu.Unlock()
+		if inflight {
+			continue
+		}
+
+		batch, err := s.readSpooled(pos)
+		if err != nil {
+			if !errors.Is(err, wal.ErrCorrupt) {
+				return stored, err
+			}
+			// A record that cannot be decoded never will be
+			logger.Error("Dropping unreadable spooled ingest batch", "segment", pos.Segment, "offset", pos.Offset, "error", err)
+			if err := s.spool.Ack(pos); err != nil {
+				return stored, err
+			}
+			continue
+		}
+
+		positions = append(positions, pos)
+		entries = append(entries, batch...)
+		if len(entries) >= s.opts.BatchSize {
+			if err := flush(); err != nil {
+				return stored, err
+			}
+		}
+	}
+
+	if err := flush(); err != nil {
+		return stored, err
+	}
+	return stored, nil
+}
+
+// readSpooled decodes a spooled batch, reporting undecodable records as
+// wal.ErrCorrupt
+func (s *ingestService) readSpooled(pos wal.Position) ([]*domain.LogEntry, error) {
+	data, err := s.spool.Read(pos)
+	if err != nil {
+		return nil, err
+	}
+
+	var entries []*domain.LogEntry
+	if err := json.Unmarshal(data, &entries); err != nil {
+		return nil, fmt.Errorf("%w: %v", wal.ErrCorrupt, err)
+	}
+	return entries, nil
+}
+
+// Stats reports the queued logs and the state of the spool
+func (s *ingestService) Stats() domain.IngestStats {
+	stats := domain.IngestStats{QueuedLogs: s.pending.Load()}
+	if s.spool == nil {
+		return stats
+	}
+
+	spool := s.spool.Stats()
+	stats.Spool = &domain.SpoolStats{
+		Batches: spool.Records,
+		Bytes:   spool.Bytes,
+	}
+	if !spool.Oldest.IsZero() {
+		stats.Spool.OldestAgeSeconds = time.Since(spool.Oldest).Seconds()
+	}
+	return stats
+}
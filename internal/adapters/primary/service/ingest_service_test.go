@@ -0,0 +1,314 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/spidey52/api-logs/internal/adapters/secondary/repository/inmemory"
+	"github.com/spidey52/api-logs/internal/domain"
+	"github.com/spidey52/api-logs/internal/ports/input"
+	"github.com/spidey52/api-logs/internal/ports/output"
+	"github.com/spidey52/api-logs/pkg/cache"
+	"github.com/spidey52/api-logs/pkg/wal"
+)
+
+// testIngest is an ingest service storing into in-memory repositories
+type testIngest struct {
+	input.IngestService
+	logRepo     output.APILogRepository
+	projectRepo output.ProjectRepository
+	quotas      input.QuotaService
+}
+
+// newTestIngest returns an ingest service storing into in-memory repositories,
+// counting logs against quota in counters
+func newTestIngest(t *testing.T, quota domain.ProjectQuota, counters cache.Cache, opts IngestOptions) *testIngest {
+	return newTestIngestSpool(t, quota, counters, nil, opts)
+}
+
+// newTestIngestSpool is newTestIngest with a spool
+func newTestIngestSpool(t *testing.T, quota domain.ProjectQuota, counters cache.Cache, spool *wal.Log, opts IngestOptions) *testIngest {
+	t.Helper()
+	authorizer := NewAuthorizer(inmemory.NewProjectMemberRepository(), inmemory.NewAccessLogRepository())
+	logRepo := inmemory.NewAPILogRepository()
+	projectRepo := inmemory.NewProjectRepository()
+	rollups := NewRollupService(logRepo, inmemory.NewLogSummaryRepository(), projectRepo, RollupOptions{})
+	logs := NewAPILogService(logRepo, inmemory.NewHeadersRepository(), inmemory.NewBodyRepository(), inmemory.NewUserRepository(), authorizer, nil, rollups)
+	redaction, err := NewRedactionService(inmemory.NewRedactionPolicyRepository(), projectRepo, authorizer, domain.RedactionPolicy{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	quotas := NewQuotaService(inmemory.NewQuotaRepository(), projectRepo, inmemory.NewAPIKeyRepository(), counters, quota)
+
+	ingest := NewIngestService(logs, redaction, quotas, authorizer, spool, counters, opts)
+	t.Cleanup(func() { ingest.Drain(context.Background()) })
+	return &testIngest{IngestService: ingest, logRepo: logRepo, projectRepo: projectRepo, quotas: quotas}
+}
+
+// keyContext returns a context calling as an API key of the project
+func keyContext(projectID, keyID string) context.Context {
+	project := &domain.Project{ID: projectID}
+	return domain.ContextWithPrincipal(context.Background(), domain.APIKeyPrincipal(project, &domain.APIKey{ID: keyID}))
+}
+
+func newTestEntry(projectID, id string) *domain.LogEntry {
+	return &domain.LogEntry{Log: &domain.APILog{
+		ID:          id,
+		ProjectID:   projectID,
+		Environment: domain.EnvironmentDev,
+		Method:      domain.MethodGET,
+		Path:        "/items",
+		StatusCode:  200,
+	}}
+}
+
+func TestEnqueueCountsAcceptedLogs(t *testing.T) {
+	counters := cache.NewMemoryCache()
+	ingest := newTestIngest(t, domain.ProjectQuota{LogsPerDay: 3, KeyLogsPerDay: 3}, counters, IngestOptions{})
+	ctx := keyContext("p1", "k1")
+
+	invalid := newTestEntry("p1", "")
+	invalid.Log.Method = ""
+	entries := []*domain.LogEntry{
+		newTestEntry("p1", "9b2c7a6e-3f1d-4c8b-a5e2-7d4f6b1c0e93"),
+		newTestEntry("p1", "9b2c7a6e-3f1d-4c8b-a5e2-7d4f6b1c0e93"),
+		invalid,
+		newTestEntry("p1", ""),
+		newTestEntry("p1", ""),
+	}
+	errs, err := ingest.Enqueue(ctx, entries)
+	if err != nil {
+		t.Fatalf("enqueue: %v", err)
+	}
+	for i, want := range []error{nil, domain.ErrDuplicateLog, domain.ErrInvalidInput, nil, nil} {
+		if !errors.Is(errs[i], want) {
+			t.Fatalf("entry %d: want %v, got %v", i, want, errs[i])
+		}
+	}
+
+	now := time.Now().UTC()
+	for _, key := range []string{dayKey(quotaScopeProject, "p1", now), dayKey(quotaScopeKey, "k1", now)} {
+		count, err := counters.IncrBy(context.Background(), key, 0)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if count != 3 {
+			t.Fatalf("%s: want the 3 accepted logs counted, got %d", key, count)
+		}
+	}
+
+	// The quota is used up: the next log is refused, and its ID is not
+	// remembered, so it is accepted once the quota allows
+	retried := newTestEntry("p1", "0c5d8e2f-6a4b-4f7e-9d13-2b8a7c5e4f61")
+	_, err = ingest.Enqueue(ctx, []*domain.LogEntry{retried})
+	var exceeded *domain.QuotaExceededError
+	if !errors.As(err, &exceeded) || exceeded.Limit != "logs_per_day" {
+		t.Fatalf("want logs_per_day exceeded, got %v", err)
+	}
+	if _, err := counters.IncrBy(context.Background(), dayKey(quotaScopeProject, "p1", now), -1); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := counters.IncrBy(context.Background(), dayKey(quotaScopeKey, "k1", now), -1); err != nil {
+		t.Fatal(err)
+	}
+	errs, err = ingest.Enqueue(ctx, []*domain.LogEntry{newTestEntry("p1", "0c5d8e2f-6a4b-4f7e-9d13-2b8a7c5e4f61")})
+	if err != nil || errs[0] != nil {
+		t.Fatalf("want the retried log accepted, got %v, %v", err, errs)
+	}
+}
+
+func TestEnqueueRejectsBatchOverQueueSize(t *testing.T) {
+	ingest := newTestIngest(t, domain.ProjectQuota{}, cache.NewMemoryCache(), IngestOptions{QueueSize: 2, FlushInterval: time.Hour})
+	ctx := keyContext("p1", "k1")
+
+	batch := []*domain.LogEntry{newTestEntry("p1", ""), newTestEntry("p1", ""), newTestEntry("p1", "")}
+	if _, err := ingest.Enqueue(ctx, batch); err != domain.ErrIngestQueueFull {
+		t.Fatalf("want ErrIngestQueueFull, got %v", err)
+	}
+	if _, err := ingest.Enqueue(ctx, batch[:2]); err != nil {
+		t.Fatalf("enqueue: %v", err)
+	}
+	if queued := ingest.Stats().QueuedLogs; queued != 2 {
+		t.Fatalf("want 2 queued logs, got %d", queued)
+	}
+}
+
+func TestDrainStoresQueuedLogs(t *testing.T) {
+	ingest := newTestIngest(t, domain.ProjectQuota{}, cache.NewMemoryCache(), IngestOptions{Workers: 2, BatchSize: 100, FlushInterval: time.Hour})
+	ctx := keyContext("p1", "k1")
+
+	for i := 0; i < 3; i++ {
+		if _, err := ingest.Enqueue(ctx, []*domain.LogEntry{newTestEntry("p1", ""), newTestEntry("p1", "")}); err != nil {
+			t.Fatalf("enqueue: %v", err)
+		}
+	}
+	if err := ingest.Drain(context.Background()); err != nil {
+		t.Fatalf("drain: %v", err)
+	}
+
+	stored, err := ingest.logRepo.CountByProject(context.Background(), "p1", "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if stored != 6 || ingest.Stats().QueuedLogs != 0 {
+		t.Fatalf("want the 6 queued logs stored, got %d stored and %d queued", stored, ingest.Stats().QueuedLogs)
+	}
+	if _, err := ingest.Enqueue(ctx, []*domain.LogEntry{newTestEntry("p1", "")}); err != domain.ErrIngestStopped {
+		t.Fatalf("want ErrIngestStopped after draining, got %v", err)
+	}
+}
+
+// logsToday returns the logs counted against a project today
+func (ti *testIngest) logsToday(t *testing.T, projectID string) int64 {
+	t.Helper()
+	ctx := adminContext()
+	if _, err := ti.projectRepo.FindByID(ctx, projectID); err == domain.ErrProjectNotFound {
+		if err := ti.projectRepo.Create(ctx, &domain.Project{ID: projectID, Name: projectID, APIKeyHash: "hash-" + projectID, Environment: domain.EnvironmentDev, IsActive: true}); err != nil {
+			t.Fatal(err)
+		}
+	}
+	usage, err := ti.quotas.GetUsage(ctx, projectID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return usage.LogsToday
+}
+
+func TestEnqueueReleasesQuotaWhenQueueIsFull(t *testing.T) {
+	ingest := newTestIngest(t, domain.ProjectQuota{LogsPerDay: 10}, cache.NewMemoryCache(), IngestOptions{QueueSize: 2, FlushInterval: time.Hour})
+	ctx := keyContext("p1", "k1")
+
+	if _, err := ingest.Enqueue(ctx, []*domain.LogEntry{newTestEntry("p1", ""), newTestEntry("p1", "")}); err != nil {
+		t.Fatalf("enqueue: %v", err)
+	}
+	if logs := ingest.logsToday(t, "p1"); logs != 2 {
+		t.Fatalf("want 2 logs counted, got %d", logs)
+	}
+
+	if _, err := ingest.Enqueue(ctx, []*domain.LogEntry{newTestEntry("p1", "")}); err != domain.ErrIngestQueueFull {
+		t.Fatalf("want ErrIngestQueueFull, got %v", err)
+	}
+	if logs := ingest.logsToday(t, "p1"); logs != 2 {
+		t.Fatalf("want the refused log not counted, got %d logs", logs)
+	}
+
+	if err := ingest.Drain(context.Background()); err != nil {
+		t.Fatalf("drain: %v", err)
+	}
+	if _, err := ingest.Enqueue(ctx, []*domain.LogEntry{newTestEntry("p1", "")}); err != domain.ErrIngestStopped {
+		t.Fatalf("want ErrIngestStopped, got %v", err)
+	}
+	if logs := ingest.logsToday(t, "p1"); logs != 2 {
+		t.Fatalf("want the refused log not counted, got %d logs", logs)
+	}
+}
+
+func TestEnqueueReleasesCountedProjectsOverQuota(t *testing.T) {
+	counters := cache.NewMemoryCache()
+	ingest := newTestIngest(t, domain.ProjectQuota{LogsPerDay: 2}, counters, IngestOptions{})
+	ctx := adminContext()
+
+	if _, err := ingest.Enqueue(ctx, []*domain.LogEntry{newTestEntry("p2", ""), newTestEntry("p2", "")}); err != nil {
+		t.Fatalf("enqueue: %v", err)
+	}
+
+	_, err := ingest.Enqueue(ctx, []*domain.LogEntry{newTestEntry("p1", ""), newTestEntry("p2", "")})
+	var exceeded *domain.QuotaExceededError
+	if !errors.As(err, &exceeded) {
+		t.Fatalf("want the quota exceeded, got %v", err)
+	}
+	if logs := ingest.logsToday(t, "p1"); logs != 0 {
+		t.Fatalf("want p1's log released, got %d logs", logs)
+	}
+	if logs := ingest.logsToday(t, "p2"); logs != 2 {
+		t.Fatalf("want p2's count unchanged, got %d logs", logs)
+	}
+}
+
+func TestEnqueueRejectsBatchOverSpoolRecordSize(t *testing.T) {
+	spool, err := wal.Open(t.TempDir(), wal.Options{MaxRecordSize: 512})
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { spool.Close() })
+	ingest := newTestIngestSpool(t, domain.ProjectQuota{LogsPerDay: 10}, cache.NewMemoryCache(), spool, IngestOptions{FlushInterval: time.Hour})
+	ctx := keyContext("p1", "k1")
+
+	batch := make([]*domain.LogEntry, 10)
+	for i := range batch {
+		batch[i] = newTestEntry("p1", "")
+	}
+	if _, err := ingest.Enqueue(ctx, batch); err != domain.ErrIngestTooLarge {
+		t.Fatalf("want ErrIngestTooLarge, got %v", err)
+	}
+	if logs := ingest.logsToday(t, "p1"); logs != 0 {
+		t.Fatalf("want the refused batch not counted, got %d logs", logs)
+	}
+	if _, err := ingest.Enqueue(ctx, batch[:1]); err != nil {
+		t.Fatalf("want a batch under the record size accepted, got %v", err)
+	}
+}
+
+// rejectingLogs stores logs through the embedded service, rejecting those
+// with path /reject on their own
+type rejectingLogs struct {
+	input.APILogService
+}
+
+func (r rejectingLogs) CreateLogs(ctx context.Context, entries []*domain.LogEntry) ([]error, error) {
+	results := make([]error, len(entries))
+	var stored []*domain.LogEntry
+	for i, entry := range entries {
+		if entry.Log.Path == "/reject" {
+			results[i] = domain.ErrInvalidInput
+			continue
+		}
+		stored = append(stored, entry)
+	}
+	if _, err := r.APILogService.CreateLogs(ctx, stored); err != nil {
+		return nil, err
+	}
+	return results, nil
+}
+
+func TestWriteReleasesClaimsOfRejectedLogs(t *testing.T) {
+	authorizer := NewAuthorizer(inmemory.NewProjectMemberRepository(), inmemory.NewAccessLogRepository())
+	logRepo := inmemory.NewAPILogRepository()
+	projectRepo := inmemory.NewProjectRepository()
+	rollups := NewRollupService(logRepo, inmemory.NewLogSummaryRepository(), projectRepo, RollupOptions{})
+	logs := NewAPILogService(logRepo, inmemory.NewHeadersRepository(), inmemory.NewBodyRepository(), inmemory.NewUserRepository(), authorizer, nil, rollups)
+	redaction, err := NewRedactionService(inmemory.NewRedactionPolicyRepository(), projectRepo, authorizer, domain.RedactionPolicy{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	seen := cache.NewMemoryCache()
+	quotas := NewQuotaService(inmemory.NewQuotaRepository(), projectRepo, inmemory.NewAPIKeyRepository(), seen, domain.ProjectQuota{})
+	ingest := NewIngestService(rejectingLogs{logs}, redaction, quotas, authorizer, nil, seen, IngestOptions{FlushInterval: time.Hour})
+
+	rejected := newTestEntry("p1", "3e7a1c9d-5b2f-4d8e-a6c4-1f9b3d7e2a58")
+	rejected.Log.Path = "/reject"
+	stored := newTestEntry("p1", "8d4f2b6a-1c9e-4a3d-b7f5-6e2c8a4d1b97")
+	errs, err := ingest.Enqueue(keyContext("p1", "k1"), []*domain.LogEntry{rejected, stored})
+	if err != nil || errs[0] != nil || errs[1] != nil {
+		t.Fatalf("want both logs accepted, got %v, %v", err, errs)
+	}
+	if err := ingest.Drain(context.Background()); err != nil {
+		t.Fatalf("drain: %v", err)
+	}
+
+	for _, tc := range []struct {
+		log     *domain.APILog
+		claimed bool
+	}{{rejected.Log, false}, {stored.Log, true}} {
+		var value bool
+		found, err := seen.Get(context.Background(), dedupKey("p1", tc.log.ID), &value)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if found != tc.claimed {
+			t.Fatalf("%s: want claimed %v, got %v", tc.log.Path, tc.claimed, found)
+		}
+	}
+}
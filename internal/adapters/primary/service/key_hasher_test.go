@@ -0,0 +1,83 @@
+package service
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"testing"
+
+	"github.com/spidey52/api-logs/internal/domain"
+	"go.mongodb.org/mongo-driver/bson"
+)
+
+func TestKeyHasherIsKeyed(t *testing.T) {
+	const key = "dev_0123456789abcdef0123456789abcdef"
+	hasher := NewKeyHasher("secret-a")
+
+	hash := hasher.Hash(key)
+	if len(hash) != 64 {
+		t.Fatalf("want a hex-encoded SHA-256 HMAC, got %q", hash)
+	}
+	if hasher.Hash(key) != hash {
+		t.Fatal("want the same key hashed alike")
+	}
+	if NewKeyHasher("secret-b").Hash(key) == hash {
+		t.Fatal("want a different secret to give a different hash")
+	}
+	digest := sha256.Sum256([]byte(key))
+	if hash == hex.EncodeToString(digest[:]) {
+		t.Fatal("want a keyed hash, not a plain digest")
+	}
+}
+
+func TestProjectKeysStoredHashed(t *testing.T) {
+	p := newTestProjects(t, 0)
+	project := p.createProject(t)
+
+	stored, err := p.projectRepo.FindByID(context.Background(), project.ID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if stored.APIKey != "" {
+		t.Fatal("want the plaintext project key left unstored")
+	}
+	if stored.APIKeyHash != p.hasher.Hash(project.APIKey) || stored.APIKeyPrefix != domain.KeyPrefix(project.APIKey) {
+		t.Fatalf("want the key's hash and prefix stored, got %q and %q", stored.APIKeyHash, stored.APIKeyPrefix)
+	}
+
+	key, err := p.CreateAPIKey(adminContext(), project.ID, "ingest", []domain.APIKeyScope{domain.ScopeIngest}, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	storedKey, err := p.apiKeyRepo.FindByID(context.Background(), key.ID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if key.Key == "" || storedKey.Key != "" {
+		t.Fatal("want the plaintext key returned once and left unstored")
+	}
+	if storedKey.KeyHash != p.hasher.Hash(key.Key) || storedKey.Prefix != domain.KeyPrefix(key.Key) {
+		t.Fatalf("want the key's hash and prefix stored, got %q and %q", storedKey.KeyHash, storedKey.Prefix)
+	}
+}
+
+func TestPlaintextKeysNotEncoded(t *testing.T) {
+	project := &domain.Project{ID: "p1", APIKey: "dev_plaintext", APIKeyHash: "hash"}
+	key := &domain.APIKey{ID: "k1", Key: "dev_plaintext", KeyHash: "hash"}
+
+	for name, document := range map[string]any{"project": project, "key": key} {
+		encoded, err := bson.Marshal(document)
+		if err != nil {
+			t.Fatal(err)
+		}
+		var fields bson.M
+		if err := bson.Unmarshal(encoded, &fields); err != nil {
+			t.Fatal(err)
+		}
+		for field, value := range fields {
+			if value == "dev_plaintext" {
+				t.Fatalf("%s: plaintext key encoded as %q", name, field)
+			}
+		}
+	}
+}
@@ -0,0 +1,372 @@
+package service
+
+import (
+	"encoding/json"
+	"fmt"
+	"maps"
+	"net"
+	"net/url"
+	"path"
+	"regexp"
+	"slices"
+	"strconv"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+
+	"github.com/spidey52/api-logs/internal/domain"
+)
+
+// NormalizeOptions configures how ingested logs are cleaned up before they are
+// queued. A zero size limit means unlimited.
+type NormalizeOptions struct {
+	// Methods are the accepted HTTP methods; empty accepts domain.StandardMethods
+	Methods []string
+
+	// Size limits in bytes; longer values are truncated
+	MaxPathLength         int
+	MaxUserAgentLength    int
+	MaxErrorMessageLength int
+	MaxParamLength        int // each path and query parameter value
+	MaxHeaderLength       int // each header value
+	MaxBodySize           int // each body, JSON encoded
+
+	// LowercaseHeaders stores header names in lower case, merging headers
+	// that differ only in case
+	LowercaseHeaders bool
+	// TemplatePaths replaces the ID segments of paths (numbers, UUIDs, object
+	// IDs) with :id parameters, moving their values to the path parameters
+	TemplatePaths bool
+}
+
+// idSegmentPattern matches path segments that identify a resource: numbers,
+// UUIDs and long hexadecimal IDs such as MongoDB object IDs
+var idSegmentPattern = regexp.MustCompile(`^(?:\d+|[0-9a-fA-F]{8}-[0-9a-fA-F]{4}-[0-9a-fA-F]{4}-[0-9a-fA-F]{4}-[0-9a-fA-F]{12}|[0-9a-fA-F]*\d[0-9a-fA-F]*)$`)
+
+// minHexIDLength keeps short hexadecimal words ("cafe", "b2b") out of templating
+const minHexIDLength = 16
+
+// normalizer validates and cleans up ingested logs. Fields a log cannot be
+// stored without reject it; other malformed fields are dropped, and oversized
+// ones truncated, and both are reported on the entry.
+type normalizer struct {
+	opts    NormalizeOptions
+	methods map[domain.HTTPMethod]bool
+}
+
+func newNormalizer(opts NormalizeOptions) *normalizer {
+	n := &normalizer{opts: opts, methods: make(map[domain.HTTPMethod]bool)}
+	if len(opts.Methods) == 0 {
+		for _, method := range domain.StandardMethods {
+			n.methods[method] = true
+		}
+	}
+	for _, method := range opts.Methods {
+		n.methods[domain.HTTPMethod(strings.ToUpper(strings.TrimSpace(method)))] = true
+	}
+	return n
+}
+
+// normalize cleans up an entry in place, returning a *domain.ValidationError
+// when it must be rejected. Bodies are left to limitBodies.
+func (n *normalizer) normalize(entry *domain.LogEntry) error {
+	log := entry.Log
+	var rejected, dropped []domain.FieldError
+	reject := func(field, reason string) {
+		rejected = append(rejected, domain.FieldError{Field: field, Reason: reason})
+	}
+	drop := func(field, reason string) {
+		dropped = append(dropped, domain.FieldError{Field: field, Reason: reason})
+	}
+	truncate := func(field string, value *string, limit int) {
+		var cut bool
+		if *value, cut = truncateString(*value, limit); cut {
+			drop(field, fmt.Sprintf("truncated to %d bytes", limit))
+		}
+	}
+
+	if log.ProjectID == "" {
+		reject("project_id", "is required")
+	}
+	if err := log.Environment.Validate(); err != nil {
+		reject("environment", err.Error())
+	}
+
+	log.Method = domain.HTTPMethod(strings.ToUpper(strings.TrimSpace(string(log.Method))))
+	switch {
+	case log.Method == "":
+		reject("method", "is required")
+	case !n.methods[log.Method]:
+		method, _ := truncateString(string(log.Method), 32)
+		reject("method", fmt.Sprintf("unknown method %q", method))
+	}
+
+	if log.StatusCode < 100 || log.StatusCode > 599 {
+		reject("status_code", "must be between 100 and 599")
+	}
+
+	if reason := n.normalizePath(log); reason != "" {
+		reject("path", reason)
+	}
+	truncate("path", &log.Path, n.opts.MaxPathLength)
+
+	if len(rejected) > 0 {
+		return &domain.ValidationError{Fields: rejected}
+	}
+
+	if log.ResponseTime < 0 {
+		log.ResponseTime = 0
+		drop("response_time_ms", "must not be negative, dropped")
+	}
+	if log.ContentLength < 0 {
+		log.ContentLength = 0
+		drop("content_length", "must not be negative, dropped")
+	}
+
+	if log.IPAddress != "" {
+		ip, ok := normalizeIP(log.IPAddress)
+		if !ok {
+			drop("ip_address", "not an IP address, dropped")
+		}
+		log.IPAddress = ip
+	}
+
+	log.UserAgent = cleanText(log.UserAgent)
+	truncate("user_agent", &log.UserAgent, n.opts.MaxUserAgentLength)
+	log.ErrorMessage = strings.ToValidUTF8(log.ErrorMessage, "\uFFFD")
+	truncate("error_message", &log.ErrorMessage, n.opts.MaxErrorMessageLength)
+
+	for _, name := range slices.Sorted(maps.Keys(log.Params)) {
+		value := log.Params[name]
+		truncate("params."+name, &value, n.opts.MaxParamLength)
+		log.Params[name] = value
+	}
+	for _, name := range slices.Sorted(maps.Keys(log.QueryParams)) {
+		value := log.QueryParams[name]
+		truncate("query_params."+name, &value, n.opts.MaxParamLength)
+		log.QueryParams[name] = value
+	}
+
+	if entry.Headers != nil {
+		entry.Headers.RequestHeaders = n.normalizeHeaders("request_headers", entry.Headers.RequestHeaders, drop)
+		entry.Headers.ResponseHeaders = n.normalizeHeaders("response_headers", entry.Headers.ResponseHeaders, drop)
+	}
+
+	entry.RejectedFields = append(entry.RejectedFields, dropped...)
+	return nil
+}
+
+// normalizePath canonicalizes a log's path, returning why it is invalid. Full
+// URLs are reduced to their path, a query string moves to the query
+// parameters when the log has none, and dot segments and repeated or
+// trailing slashes are removed.
+func (n *normalizer) normalizePath(log *domain.APILog) string {
+	p := strings.TrimSpace(log.Path)
+	if p == "" {
+		return "is required"
+	}
+	if strings.IndexFunc(p, unicode.IsControl) >= 0 {
+		return "contains control characters"
+	}
+
+	if strings.HasPrefix(p, "http://") || strings.HasPrefix(p, "https://") {
+		u, err := url.Parse(p)
+		if err != nil {
+			return "is not a valid URL"
+		}
+		p = u.EscapedPath()
+		if u.RawQuery != "" {
+			p += "?" + u.RawQuery
+		}
+	}
+
+	p, query, hasQuery := strings.Cut(p, "?")
+	p, _, _ = strings.Cut(p, "#")
+	if hasQuery && len(log.QueryParams) == 0 {
+		if values, _ := url.ParseQuery(query); len(values) > 0 {
+			log.QueryParams = make(map[string]string, len(values))
+			for key, value := range values {
+				log.QueryParams[key] = value[0]
+			}
+		}
+	}
+
+	p = path.Clean("/" + p)
+	if n.opts.TemplatePaths {
+		p = templatePath(p, log)
+	}
+	log.Path = p
+	return ""
+}
+
+// templatePath replaces ID segments with :id, :id2 and so on, adding their
+// values to the path parameters under names not already taken
+func templatePath(p string, log *domain.APILog) string {
+	segments := strings.Split(p, "/")
+	templated := false
+	for i, segment := range segments {
+		if !isIDSegment(segment) {
+			continue
+		}
+
+		name := "id"
+		for suffix := 2; ; suffix++ {
+			if _, taken := log.Params[name]; !taken {
+				break
+			}
+			name = "id" + strconv.Itoa(suffix)
+		}
+		if log.Params == nil {
+			log.Params = make(map[string]string)
+		}
+		log.Params[name] = segment
+		segments[i] = ":" + name
+		templated = true
+	}
+
+	if !templated {
+		return p
+	}
+	return strings.Join(segments, "/")
+}
+
+func isIDSegment(segment string) bool {
+	if segment == "" || !idSegmentPattern.MatchString(segment) {
+		return false
+	}
+	// Hexadecimal IDs must be long; numbers and UUIDs always count
+	if strings.Trim(segment, "0123456789") == "" || strings.Count(segment, "-") == 4 {
+		return true
+	}
+	return len(segment) >= minHexIDLength
+}
+
+// normalizeHeaders lower-cases header names, when configured, and truncates
+// header values
+func (n *normalizer) normalizeHeaders(field string, headers map[string]any, drop func(field, reason string)) map[string]any {
+	if len(headers) == 0 {
+		return headers
+	}
+
+	normalized := headers
+	if n.opts.LowercaseHeaders {
+		normalized = make(map[string]any, len(headers))
+		// Sorted, so merged headers keep a stable order
+		for _, name := range slices.Sorted(maps.Keys(headers)) {
+			lower := strings.ToLower(name)
+			normalized[lower] = mergeHeader(normalized[lower], headers[name])
+		}
+	}
+
+	limit := n.opts.MaxHeaderLength
+	for _, name := range slices.Sorted(maps.Keys(normalized)) {
+		switch value := normalized[name].(type) {
+		case string:
+			if truncated, cut := truncateString(value, limit); cut {
+				normalized[name] = truncated
+				drop(field+"."+name, fmt.Sprintf("truncated to %d bytes", limit))
+			}
+		case []any:
+			for i, v := range value {
+				s, ok := v.(string)
+				if !ok {
+					continue
+				}
+				if truncated, cut := truncateString(s, limit); cut {
+					value[i] = truncated
+					drop(fmt.Sprintf("%s.%s[%d]", field, name, i), fmt.Sprintf("truncated to %d bytes", limit))
+				}
+			}
+		}
+	}
+	return normalized
+}
+
+// mergeHeader combines the values of headers whose names differ only in case
+func mergeHeader(existing, value any) any {
+	if existing == nil {
+		return value
+	}
+
+	var merged []any
+	for _, v := range []any{existing, value} {
+		if list, ok := v.([]any); ok {
+			merged = append(merged, list...)
+		} else {
+			merged = append(merged, v)
+		}
+	}
+	return merged
+}
+
+// limitBodies truncates bodies larger than MaxBodySize, keeping the start of
+// their JSON encoding as text. It runs after redaction, so a truncated body
+// cannot hide a field a policy masks.
+func (n *normalizer) limitBodies(entry *domain.LogEntry) {
+	limit := n.opts.MaxBodySize
+	if entry.Body == nil || limit <= 0 {
+		return
+	}
+
+	limitBody := func(field string, body any) any {
+		if body == nil {
+			return nil
+		}
+		text, ok := body.(string)
+		if !ok {
+			encoded, err := json.Marshal(body)
+			if err != nil || len(encoded) <= limit {
+				return body
+			}
+			text = string(encoded)
+		}
+		truncated, cut := truncateString(text, limit)
+		if !cut {
+			return body
+		}
+		entry.RejectedFields = append(entry.RejectedFields, domain.FieldError{Field: field, Reason: fmt.Sprintf("truncated to %d bytes", limit)})
+		return truncated
+	}
+
+	entry.Body.RequestBody = limitBody("request_body", entry.Body.RequestBody)
+	entry.Body.ResponseBody = limitBody("response_body", entry.Body.ResponseBody)
+}
+
+// normalizeIP reduces a client address to its IP: the first address of a
+// forwarded list, without a port
+func normalizeIP(address string) (string, bool) {
+	address, _, _ = strings.Cut(address, ",")
+	address = strings.TrimSpace(address)
+	if ip := net.ParseIP(address); ip != nil {
+		return ip.String(), true
+	}
+	if host, _, err := net.SplitHostPort(address); err == nil {
+		if ip := net.ParseIP(host); ip != nil {
+			return ip.String(), true
+		}
+	}
+	return "", false
+}
+
+// cleanText trims a value and replaces invalid UTF-8 and control characters
+func cleanText(s string) string {
+	s = strings.ToValidUTF8(strings.TrimSpace(s), "\uFFFD")
+	return strings.Map(func(r rune) rune {
+		if unicode.IsControl(r) {
+			return -1
+		}
+		return r
+	}, s)
+}
+
+// truncateString cuts s to at most limit bytes without splitting a character,
+// reporting whether it was cut. A limit of 0 means unlimited.
+func truncateString(s string, limit int) (string, bool) {
+	if limit <= 0 || len(s) <= limit {
+		return s, false
+	}
+	for limit > 0 && !utf8.RuneStart(s[limit]) {
+		limit--
+	}
+	return s[:limit], true
+}
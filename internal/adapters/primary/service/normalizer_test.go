@@ -0,0 +1,223 @@
+package service
+
+import (
+	"errors"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/spidey52/api-logs/internal/domain"
+)
+
+func TestNormalizeRejects(t *testing.T) {
+	tests := []struct {
+		name       string
+		opts       NormalizeOptions
+		change     func(log *domain.APILog)
+		wantFields []string
+	}{
+		{"missing project", NormalizeOptions{}, func(log *domain.APILog) { log.ProjectID = "" }, []string{"project_id"}},
+		{"invalid environment", NormalizeOptions{}, func(log *domain.APILog) { log.Environment = "qa" }, []string{"environment"}},
+		{"missing method", NormalizeOptions{}, func(log *domain.APILog) { log.Method = " " }, []string{"method"}},
+		{"unknown method", NormalizeOptions{}, func(log *domain.APILog) { log.Method = "PURGE" }, []string{"method"}},
+		{"method not configured", NormalizeOptions{Methods: []string{"purge"}}, func(log *domain.APILog) {}, []string{"method"}},
+		{"status out of range", NormalizeOptions{}, func(log *domain.APILog) { log.StatusCode = 600 }, []string{"status_code"}},
+		{"missing path", NormalizeOptions{}, func(log *domain.APILog) { log.Path = "" }, []string{"path"}},
+		{"control characters in path", NormalizeOptions{}, func(log *domain.APILog) { log.Path = "/items\x00/1" }, []string{"path"}},
+		{"several fields", NormalizeOptions{}, func(log *domain.APILog) {
+			log.Method, log.StatusCode = "", 0
+		}, []string{"method", "status_code"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			entry := newTestEntry("p1", "")
+			tt.change(entry.Log)
+
+			err := newNormalizer(tt.opts).normalize(entry)
+			var validation *domain.ValidationError
+			if !errors.As(err, &validation) {
+				t.Fatalf("want a validation error, got %v", err)
+			}
+			var fields []string
+			for _, field := range validation.Fields {
+				fields = append(fields, field.Field)
+			}
+			if !reflect.DeepEqual(fields, tt.wantFields) {
+				t.Fatalf("want rejected fields %v, got %v", tt.wantFields, fields)
+			}
+		})
+	}
+
+	custom := newTestEntry("p1", "")
+	custom.Log.Method = "purge"
+	if err := newNormalizer(NormalizeOptions{Methods: []string{" Purge "}}).normalize(custom); err != nil || custom.Log.Method != "PURGE" {
+		t.Fatalf("want a configured method accepted, got %v, %q", err, custom.Log.Method)
+	}
+}
+
+func TestNormalizeCleansUp(t *testing.T) {
+	n := newNormalizer(NormalizeOptions{
+		MaxUserAgentLength:    8,
+		MaxErrorMessageLength: 4,
+		MaxParamLength:        3,
+		MaxHeaderLength:       5,
+	})
+	entry := newTestEntry("p1", "")
+	log := entry.Log
+	log.Method = " get "
+	log.Path = "https://api.example.com/items//42/../43/?page=2&sort=name#top"
+	log.ResponseTime = -1
+	log.ContentLength = -1
+	log.IPAddress = "[2001:db8::1]:443, 10.0.0.2"
+	log.UserAgent = " curl/8\x00.1 (Linux) "
+	log.ErrorMessage = "naïve"
+	entry.Headers = &domain.APILogHeaders{RequestHeaders: map[string]any{
+		"Accept":          []any{"application/json", "text/plain"},
+		"X-Request-Id":    "1234",
+		"X-Forwarded-For": 17,
+	}}
+
+	if err := n.normalize(entry); err != nil {
+		t.Fatalf("normalize: %v", err)
+	}
+
+	want := &domain.APILog{
+		ProjectID:    "p1",
+		Environment:  domain.EnvironmentDev,
+		Method:       domain.MethodGET,
+		Path:         "/items/43",
+		QueryParams:  map[string]string{"page": "2", "sort": "nam"},
+		StatusCode:   200,
+		IPAddress:    "2001:db8::1",
+		UserAgent:    "curl/8.1",
+		ErrorMessage: "naï",
+	}
+	if !reflect.DeepEqual(log, want) {
+		t.Fatalf("log mismatch:\ngot  %+v\nwant %+v", log, want)
+	}
+	wantHeaders := map[string]any{
+		"Accept":          []any{"appli", "text/"},
+		"X-Request-Id":    "1234",
+		"X-Forwarded-For": 17,
+	}
+	if !reflect.DeepEqual(entry.Headers.RequestHeaders, wantHeaders) {
+		t.Fatalf("headers mismatch: %+v", entry.Headers.RequestHeaders)
+	}
+
+	var dropped []string
+	for _, field := range entry.RejectedFields {
+		dropped = append(dropped, field.Field)
+	}
+	wantDropped := []string{
+		"response_time_ms", "content_length", "user_agent", "error_message", "query_params.sort",
+		"request_headers.Accept[0]", "request_headers.Accept[1]",
+	}
+	if !reflect.DeepEqual(dropped, wantDropped) {
+		t.Fatalf("want dropped fields %v, got %v", wantDropped, dropped)
+	}
+}
+
+func TestNormalizeDropsInvalidIP(t *testing.T) {
+	entry := newTestEntry("p1", "")
+	entry.Log.IPAddress = "localhost"
+	if err := newNormalizer(NormalizeOptions{}).normalize(entry); err != nil {
+		t.Fatal(err)
+	}
+	if entry.Log.IPAddress != "" || len(entry.RejectedFields) != 1 || entry.RejectedFields[0].Field != "ip_address" {
+		t.Fatalf("want the IP address dropped, got %q, %v", entry.Log.IPAddress, entry.RejectedFields)
+	}
+}
+
+func TestNormalizeQueryInPath(t *testing.T) {
+	n := newNormalizer(NormalizeOptions{})
+
+	entry := newTestEntry("p1", "")
+	entry.Log.Path = "/search?q=shoes"
+	if err := n.normalize(entry); err != nil {
+		t.Fatal(err)
+	}
+	if entry.Log.Path != "/search" || entry.Log.QueryParams["q"] != "shoes" {
+		t.Fatalf("want the query moved to the parameters, got %q, %v", entry.Log.Path, entry.Log.QueryParams)
+	}
+
+	// Query parameters sent with the log take precedence
+	entry = newTestEntry("p1", "")
+	entry.Log.Path = "/search?q=shoes"
+	entry.Log.QueryParams = map[string]string{"q": "boots"}
+	if err := n.normalize(entry); err != nil {
+		t.Fatal(err)
+	}
+	if entry.Log.Path != "/search" || !reflect.DeepEqual(entry.Log.QueryParams, map[string]string{"q": "boots"}) {
+		t.Fatalf("want the logged query kept, got %q, %v", entry.Log.Path, entry.Log.QueryParams)
+	}
+}
+
+func TestNormalizeLowercaseHeaders(t *testing.T) {
+	entry := newTestEntry("p1", "")
+	entry.Headers = &domain.APILogHeaders{ResponseHeaders: map[string]any{
+		"Set-Cookie": "a=1",
+		"set-cookie": []any{"b=2", "c=3"},
+		"ETag":       `"v1"`,
+	}}
+	if err := newNormalizer(NormalizeOptions{LowercaseHeaders: true}).normalize(entry); err != nil {
+		t.Fatal(err)
+	}
+	want := map[string]any{
+		"set-cookie": []any{"a=1", "b=2", "c=3"},
+		"etag":       `"v1"`,
+	}
+	if !reflect.DeepEqual(entry.Headers.ResponseHeaders, want) {
+		t.Fatalf("headers mismatch: %+v", entry.Headers.ResponseHeaders)
+	}
+}
+
+func TestTemplatePaths(t *testing.T) {
+	tests := []struct {
+		path       string
+		params     map[string]string
+		wantPath   string
+		wantParams map[string]string
+	}{
+		{"/users/42/orders", nil, "/users/:id/orders", map[string]string{"id": "42"}},
+		{
+			"/users/9b2c7a6e-3f1d-4c8b-a5e2-7d4f6b1c0e93/orders/507f1f77bcf86cd799439011", nil,
+			"/users/:id/orders/:id2", map[string]string{"id": "9b2c7a6e-3f1d-4c8b-a5e2-7d4f6b1c0e93", "id2": "507f1f77bcf86cd799439011"},
+		},
+		{"/cafe/b2b/v2", nil, "/cafe/b2b/v2", nil},
+		{"/teams/acme/items/7", map[string]string{"id": "acme"}, "/teams/acme/items/:id2", map[string]string{"id": "acme", "id2": "7"}},
+	}
+
+	n := newNormalizer(NormalizeOptions{TemplatePaths: true})
+	for _, tt := range tests {
+		t.Run(tt.path, func(t *testing.T) {
+			entry := newTestEntry("p1", "")
+			entry.Log.Path, entry.Log.Params = tt.path, tt.params
+			if err := n.normalize(entry); err != nil {
+				t.Fatal(err)
+			}
+			if entry.Log.Path != tt.wantPath || !reflect.DeepEqual(entry.Log.Params, tt.wantParams) {
+				t.Fatalf("want %q %v, got %q %v", tt.wantPath, tt.wantParams, entry.Log.Path, entry.Log.Params)
+			}
+		})
+	}
+}
+
+func TestLimitBodies(t *testing.T) {
+	entry := newTestEntry("p1", "")
+	entry.Body = &domain.APILogBody{
+		RequestBody:  map[string]any{"name": strings.Repeat("a", 20)},
+		ResponseBody: map[string]any{"ok": true},
+	}
+	newNormalizer(NormalizeOptions{MaxBodySize: 16}).limitBodies(entry)
+
+	if entry.Body.RequestBody != `{"name":"aaaaaaa` {
+		t.Fatalf("want the request body cut to its first 16 bytes, got %v", entry.Body.RequestBody)
+	}
+	if !reflect.DeepEqual(entry.Body.ResponseBody, map[string]any{"ok": true}) {
+		t.Fatalf("want the small response body kept, got %v", entry.Body.ResponseBody)
+	}
+	if len(entry.RejectedFields) != 1 || entry.RejectedFields[0].Field != "request_body" {
+		t.Fatalf("want the request body reported, got %v", entry.RejectedFields)
+	}
+}
@@ -4,6 +4,8 @@ import (
 	"context"
 	"crypto/rand"
 	"encoding/hex"
+	"fmt"
+	"strings"
 	"time"
 
 	"github.com/google/uuid"
@@ -16,16 +18,71 @@ import (
 // projectService implements the ProjectService interface
 type projectService struct {
 	projectRepo output.ProjectRepository
+	memberRepo  output.ProjectMemberRepository
+	accountRepo output.AccountRepository
+	apiKeyRepo  output.APIKeyRepository
+	authorizer  *Authorizer
+	hasher      *KeyHasher
+	data        ProjectData
+
+	// rotationGrace is how long a project's previous key keeps working after
+	// RegenerateAPIKey when the caller does not choose a grace period
+	rotationGrace time.Duration
+}
+
+// lastUsedResolution bounds how often a key's last-used time is written, so a
+// busy ingest key does not cost a database write per request
+const lastUsedResolution = time.Minute
+
+// ProjectData holds the repositories of the data a project owns besides its
+// members and API keys, removed when the project is deleted
+type ProjectData struct {
+	Logs              output.APILogRepository
+	Headers           output.APILogHeadersRepository
+	Bodies            output.APILogBodyRepository
+	AccessLogs        output.AccessLogRepository
+	Summaries         output.LogSummaryRepository
+	Users             output.UserRepository
+	Quotas            output.QuotaRepository
+	RedactionPolicies output.RedactionPolicyRepository
+	DataKeys          output.DataKeyRepository
+
+	// BatchSize bounds the records removed by one delete, so deleting a
+	// large project does not hold locks for long
+	BatchSize int
 }
 
 // NewProjectService creates a new instance of ProjectService
-func NewProjectService(projectRepo output.ProjectRepository) input.ProjectService {
+func NewProjectService(
+	projectRepo output.ProjectRepository,
+	memberRepo output.ProjectMemberRepository,
+	accountRepo output.AccountRepository,
+	apiKeyRepo output.APIKeyRepository,
+	authorizer *Authorizer,
+	hasher *KeyHasher,
+	data ProjectData,
+	rotationGrace time.Duration,
+) input.ProjectService {
+	if data.BatchSize <= 0 {
+		data.BatchSize = 1000
+	}
+
 	return &projectService{
 		projectRepo: projectRepo,
+		memberRepo:  memberRepo,
+		accountRepo: accountRepo,
+		apiKeyRepo:  apiKeyRepo,
+		authorizer:  authorizer,
+		hasher:      hasher,
+		data:        data,
+
+		rotationGrace: rotationGrace,
 	}
 }
 
-// CreateProject creates a new project with generated API key
+// CreateProject creates a new project with generated API key. The plaintext
+// key is left in project.APIKey for the caller to show once; only its hash is
+// stored.
 func (s *projectService) CreateProject(ctx context.Context, project *domain.Project) error {
 	// Generate ID if not provided
 	if project.ID == "" {
@@ -40,6 +97,8 @@ func (s *projectService) CreateProject(ctx context.Context, project *domain.Proj
 		}
 		project.APIKey = apiKey
 	}
+	project.APIKeyHash = s.hasher.Hash(project.APIKey)
+	project.APIKeyPrefix = domain.KeyPrefix(project.APIKey)
 
 	// Validate project
 	if err := project.Validate(); err != nil {
@@ -48,7 +107,7 @@ func (s *projectService) CreateProject(ctx context.Context, project *domain.Proj
 	}
 
 	// Check if API key already exists
-	exists, err := s.projectRepo.ExistsByAPIKey(ctx, project.APIKey)
+	exists, err := s.projectRepo.ExistsByAPIKey(ctx, project.APIKeyHash)
 	if err != nil {
 		return err
 	}
@@ -66,31 +125,68 @@ func (s *projectService) CreateProject(ctx context.Context, project *domain.Proj
 		project.IsActive = true
 	}
 
-	return s.projectRepo.Create(ctx, project)
+	if err := s.projectRepo.Create(ctx, project); err != nil {
+		return err
+	}
+
+	// The account creating a project becomes its first owner
+	if principal, ok := domain.PrincipalFromContext(ctx); ok && principal.Account != nil {
+		owner := &domain.ProjectMember{
+			ID:        uuid.New().String(),
+			ProjectID: project.ID,
+			AccountID: principal.Account.ID,
+			Role:      domain.RoleOwner,
+			CreatedAt: now,
+			UpdatedAt: now,
+		}
+		if err := s.memberRepo.Create(ctx, owner); err != nil {
+			return err
+		}
+	}
+
+	return nil
 }
 
 // GetProject retrieves a project by ID
 func (s *projectService) GetProject(ctx context.Context, id string) (*domain.Project, error) {
-	return s.projectRepo.FindByID(ctx, id)
+	project, err := s.projectRepo.FindByID(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := s.authorizer.Authorize(ctx, id, domain.RoleViewer, actionRead, resourceProject, id); err != nil {
+		return nil, err
+	}
+	return project, nil
 }
 
 // GetProjectByAPIKey retrieves a project by API key
 func (s *projectService) GetProjectByAPIKey(ctx context.Context, apiKey string) (*domain.Project, error) {
-	return s.projectRepo.FindByAPIKey(ctx, apiKey)
+	return s.projectRepo.FindByAPIKey(ctx, s.hasher.Hash(apiKey))
 }
 
 // ListProjects retrieves projects based on filter criteria
 func (s *projectService) ListProjects(ctx context.Context, filter domain.ProjectFilter) ([]*domain.Project, error) {
 	filter.ApplyDefaults()
+
+	// Restrict non-admin callers to the projects they are a member of
+	ids, err := s.authorizer.ProjectIDs(ctx)
+	if err != nil {
+		return nil, err
+	}
+	if ids != nil {
+		if len(ids) == 0 {
+			return []*domain.Project{}, nil
+		}
+		filter.IDs = ids
+	}
+
 	return s.projectRepo.FindAll(ctx, filter)
 }
 
-// UpdateProject updates an existing project
+// UpdateProject updates an existing project. The API key is not changed
+// here; see RegenerateAPIKey.
 func (s *projectService) UpdateProject(ctx context.Context, project *domain.Project) error {
-	if err := project.Validate(); err != nil {
-		return domain.ErrInvalidInput
-	}
-
 	// Check if project exists
 	existing, err := s.projectRepo.FindByID(ctx, project.ID)
 	if err != nil {
@@ -100,13 +196,27 @@ func (s *projectService) UpdateProject(ctx context.Context, project *domain.Proj
 		return domain.ErrProjectNotFound
 	}
 
+	if err := s.authorizer.Authorize(ctx, project.ID, domain.RoleEditor, actionWrite, resourceProject, project.ID); err != nil {
+		return err
+	}
+
+	project.APIKeyHash = existing.APIKeyHash
+	project.APIKeyPrefix = existing.APIKeyPrefix
+	project.CreatedAt = existing.CreatedAt
+	project.Retention = existing.Retention
+	if err := project.Validate(); err != nil {
+		return domain.ErrInvalidInput
+	}
+
 	// Update timestamp
 	project.UpdatedAt = time.Now()
 
 	return s.projectRepo.Update(ctx, project)
 }
 
-// DeleteProject deletes a project by ID
+// DeleteProject deletes a project and everything it owns. The project is
+// deactivated first, so its keys stop ingesting, and removed last, so a
+// deletion that fails part way can be retried.
 func (s *projectService) DeleteProject(ctx context.Context, id string) error {
 	// Check if project exists
 	existing, err := s.projectRepo.FindByID(ctx, id)
@@ -117,61 +227,438 @@ func (s *projectService) DeleteProject(ctx context.Context, id string) error {
 		return domain.ErrProjectNotFound
 	}
 
+	if err := s.authorizer.Authorize(ctx, id, domain.RoleOwner, actionDelete, resourceProject, id); err != nil {
+		return err
+	}
+
+	if existing.IsActive {
+		existing.IsActive = false
+		existing.UpdatedAt = time.Now()
+		if err := s.projectRepo.Update(ctx, existing); err != nil {
+			return err
+		}
+	}
+
+	if err := s.apiKeyRepo.DeleteByProject(ctx, id); err != nil {
+		return err
+	}
+
+	if err := s.deleteData(ctx, id); err != nil {
+		return err
+	}
+
+	if err := s.memberRepo.DeleteByProject(ctx, id); err != nil {
+		return err
+	}
+
 	return s.projectRepo.Delete(ctx, id)
 }
 
-// ValidateAPIKey validates an API key and returns the associated project
-func (s *projectService) ValidateAPIKey(ctx context.Context, apiKey string, environment domain.Environment) (*domain.Project, error) {
+// deleteData removes the data a project owns. Bodies and headers are removed
+// before the logs they belong to, and the data key last, which leaves any
+// archived copies unreadable.
+func (s *projectService) deleteData(ctx context.Context, projectID string) error {
+	// Everything stored so far is older than this
+	end := time.Date(9999, 1, 1, 0, 0, 0, 0, time.UTC)
+
+	stores := map[domain.DataClass]expiringStore{
+		domain.DataBodies:     s.data.Bodies,
+		domain.DataHeaders:    s.data.Headers,
+		domain.DataLogs:       s.data.Logs,
+		domain.DataAccessLogs: s.data.AccessLogs,
+	}
+	for _, class := range domain.DataClasses {
+		for {
+			deleted, err := stores[class].DeleteOlderThan(ctx, projectID, end, s.data.BatchSize)
+			if err != nil {
+				return fmt.Errorf("delete %s: %w", class, err)
+			}
+			if deleted < int64(s.data.BatchSize) {
+				break
+			}
+		}
+	}
+
+	for _, granularity := range []domain.SummaryGranularity{domain.GranularityMinute, domain.GranularityHour} {
+		if err := s.data.Summaries.Replace(ctx, projectID, granularity, time.Time{}, end, nil); err != nil {
+			return fmt.Errorf("delete log summaries: %w", err)
+		}
+	}
+
+	if err := s.data.Users.DeleteByProject(ctx, projectID); err != nil {
+		return err
+	}
+	if err := s.data.Quotas.Delete(ctx, projectID); err != nil && err != domain.ErrQuotaNotFound {
+		return err
+	}
+	if err := s.data.RedactionPolicies.Delete(ctx, projectID); err != nil && err != domain.ErrRedactionPolicyNotFound {
+		return err
+	}
+	if err := s.data.DataKeys.Delete(ctx, projectID); err != nil && err != domain.ErrDataKeyNotFound {
+		return err
+	}
+	return nil
+}
+
+// ValidateAPIKey validates an API key for the given scope and returns the
+// associated project and key. The project's own key is accepted as a key with
+// every scope.
+func (s *projectService) ValidateAPIKey(ctx context.Context, apiKey string, environment domain.Environment, scope domain.APIKeyScope) (*domain.Project, *domain.APIKey, error) {
 	if apiKey == "" {
-		return nil, domain.ErrInvalidAPIKey
+		return nil, nil, domain.ErrInvalidAPIKey
 	}
 
 	if err := environment.Validate(); err != nil {
-		return nil, domain.ErrInvalidEnvironment
+		return nil, nil, domain.ErrInvalidEnvironment
 	}
 
-	project, err := s.projectRepo.FindByAPIKey(ctx, apiKey)
-	if err != nil {
-		return nil, domain.ErrInvalidAPIKey
+	now := time.Now()
+	keyHash := s.hasher.Hash(apiKey)
+	key, err := s.apiKeyRepo.FindByKey(ctx, keyHash)
+	named := err == nil
+	var project *domain.Project
+	switch err {
+	case nil:
+		if !key.IsUsable(now) {
+			return nil, nil, domain.ErrInvalidAPIKey
+		}
+		project, err = s.projectRepo.FindByID(ctx, key.ProjectID)
+		if err != nil {
+			return nil, nil, domain.ErrInvalidAPIKey
+		}
+	case domain.ErrAPIKeyNotFound:
+		project, err = s.projectRepo.FindByAPIKey(ctx, keyHash)
+		if err != nil {
+			return nil, nil, domain.ErrInvalidAPIKey
+		}
+		key = &domain.APIKey{
+			ID:        project.ID,
+			ProjectID: project.ID,
+			Name:      "default",
+			KeyHash:   project.APIKeyHash,
+			Prefix:    project.APIKeyPrefix,
+			Scopes:    domain.AllAPIKeyScopes,
+			CreatedAt: project.CreatedAt,
+		}
+	default:
+		return nil, nil, err
 	}
 
 	if !project.IsActive {
-		return nil, domain.ErrUnauthorized
+		return nil, nil, domain.ErrUnauthorized
 	}
 
 	if project.Environment != environment {
-		return nil, domain.ErrUnauthorized
+		return nil, nil, domain.ErrUnauthorized
 	}
 
-	return project, nil
+	if !key.HasScope(scope) {
+		return nil, nil, domain.ErrInsufficientScope
+	}
+
+	if named && (key.LastUsedAt == nil || now.Sub(*key.LastUsedAt) >= lastUsedResolution) {
+		if err := s.apiKeyRepo.TouchLastUsed(ctx, key.ID, now); err != nil {
+			logger.Warn("Failed to record API key use", "key_id", key.ID, "error", err)
+		}
+		key.LastUsedAt = &now
+	}
+
+	return project, key, nil
 }
 
-// RegenerateAPIKey generates a new API key for a project
-func (s *projectService) RegenerateAPIKey(ctx context.Context, projectID string) (string, error) {
+// RegenerateAPIKey generates a new API key for a project. The previous key
+// stays valid for the grace period (the service default when nil) as a named
+// key, so deployed exporters keep working until they pick up the new key; a
+// zero grace period invalidates it immediately.
+func (s *projectService) RegenerateAPIKey(ctx context.Context, projectID string, gracePeriod *time.Duration) (*domain.APIKeyRotation, error) {
+	grace := s.rotationGrace
+	if gracePeriod != nil {
+		grace = *gracePeriod
+	}
+	if grace < 0 || grace > domain.MaxRotationGracePeriod {
+		return nil, domain.ErrInvalidInput
+	}
+
 	// Get existing project
 	project, err := s.projectRepo.FindByID(ctx, projectID)
 	if err != nil {
-		return "", err
+		return nil, err
 	}
 	if project == nil {
-		return "", domain.ErrProjectNotFound
+		return nil, domain.ErrProjectNotFound
+	}
+
+	if err := s.authorizer.Authorize(ctx, projectID, domain.RoleOwner, actionWrite, resourceAPIKey, projectID); err != nil {
+		return nil, err
 	}
 
 	// Generate new API key
 	newAPIKey, err := s.generateAPIKey(project)
 	if err != nil {
-		return "", err
+		return nil, err
 	}
 
-	// Update project
-	project.APIKey = newAPIKey
-	project.UpdatedAt = time.Now()
+	now := time.Now()
+	rotation := &domain.APIKeyRotation{
+		APIKey:       newAPIKey,
+		APIKeyPrefix: domain.KeyPrefix(newAPIKey),
+	}
+
+	// Keep the previous key working until the grace period ends; the
+	// expired-key sweep revokes it afterwards
+	if grace > 0 {
+		expiresAt := now.Add(grace)
+		previous := &domain.APIKey{
+			ID:        uuid.New().String(),
+			ProjectID: projectID,
+			Name:      domain.RotatedKeyName,
+			KeyHash:   project.APIKeyHash,
+			Prefix:    project.APIKeyPrefix,
+			Scopes:    domain.AllAPIKeyScopes,
+			ExpiresAt: &expiresAt,
+			CreatedAt: now,
+		}
+		if err := s.apiKeyRepo.Create(ctx, previous); err != nil {
+			return nil, err
+		}
+		rotation.PreviousKeyID = previous.ID
+		rotation.PreviousKeyPrefix = previous.Prefix
+		rotation.PreviousKeyExpiresAt = &expiresAt
+	}
+
+	// Update project; only the hash of the new key is stored
+	project.APIKeyHash = s.hasher.Hash(newAPIKey)
+	project.APIKeyPrefix = rotation.APIKeyPrefix
+	project.UpdatedAt = now
 
 	if err := s.projectRepo.Update(ctx, project); err != nil {
-		return "", err
+		return nil, err
 	}
 
-	return newAPIKey, nil
+	return rotation, nil
+}
+
+// RevokeExpiredAPIKeys revokes keys whose expiry has passed, including
+// previous project keys at the end of their rotation grace period
+func (s *projectService) RevokeExpiredAPIKeys(ctx context.Context) (int, error) {
+	return s.apiKeyRepo.RevokeExpired(ctx, time.Now())
+}
+
+// CreateAPIKey issues a new named key for a project
+func (s *projectService) CreateAPIKey(ctx context.Context, projectID, name string, scopes []domain.APIKeyScope, expiresAt *time.Time) (*domain.APIKey, error) {
+	project, err := s.projectRepo.FindByID(ctx, projectID)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := s.authorizer.Authorize(ctx, projectID, domain.RoleOwner, actionManage, resourceAPIKey, name); err != nil {
+		return nil, err
+	}
+
+	secret, err := s.generateAPIKey(project)
+	if err != nil {
+		return nil, err
+	}
+
+	key := &domain.APIKey{
+		ID:        uuid.New().String(),
+		ProjectID: projectID,
+		Name:      strings.TrimSpace(name),
+		Key:       secret,
+		KeyHash:   s.hasher.Hash(secret),
+		Prefix:    domain.KeyPrefix(secret),
+		Scopes:    scopes,
+		ExpiresAt: expiresAt,
+		CreatedAt: time.Now(),
+	}
+	if err := key.Validate(); err != nil {
+		if err == domain.ErrInvalidScope {
+			return nil, err
+		}
+		return nil, domain.ErrInvalidInput
+	}
+	if key.IsExpired(key.CreatedAt) {
+		return nil, domain.ErrInvalidInput
+	}
+
+	if err := s.apiKeyRepo.Create(ctx, key); err != nil {
+		return nil, err
+	}
+
+	return key, nil
+}
+
+// ListAPIKeys retrieves the named keys of a project, newest first
+func (s *projectService) ListAPIKeys(ctx context.Context, projectID string) ([]*domain.APIKey, error) {
+	if _, err := s.projectRepo.FindByID(ctx, projectID); err != nil {
+		return nil, err
+	}
+
+	if err := s.authorizer.Authorize(ctx, projectID, domain.RoleOwner, actionRead, resourceAPIKey, projectID); err != nil {
+		return nil, err
+	}
+
+	return s.apiKeyRepo.ListByProject(ctx, projectID)
+}
+
+// RevokeAPIKey revokes one of a project's named keys
+func (s *projectService) RevokeAPIKey(ctx context.Context, projectID, keyID string) error {
+	if _, err := s.projectRepo.FindByID(ctx, projectID); err != nil {
+		return err
+	}
+
+	if err := s.authorizer.Authorize(ctx, projectID, domain.RoleOwner, actionManage, resourceAPIKey, keyID); err != nil {
+		return err
+	}
+
+	key, err := s.apiKeyRepo.FindByID(ctx, keyID)
+	if err != nil {
+		return err
+	}
+	if key.ProjectID != projectID {
+		return domain.ErrAPIKeyNotFound
+	}
+
+	return s.apiKeyRepo.Revoke(ctx, keyID, time.Now())
+}
+
+// ListMembers retrieves the members of a project
+func (s *projectService) ListMembers(ctx context.Context, projectID string) ([]*domain.ProjectMember, error) {
+	if _, err := s.projectRepo.FindByID(ctx, projectID); err != nil {
+		return nil, err
+	}
+
+	if err := s.authorizer.Authorize(ctx, projectID, domain.RoleViewer, actionRead, resourceMember, projectID); err != nil {
+		return nil, err
+	}
+
+	members, err := s.memberRepo.ListByProject(ctx, projectID)
+	if err != nil {
+		return nil, err
+	}
+
+	for _, member := range members {
+		account, err := s.accountRepo.FindByID(ctx, member.AccountID)
+		if err != nil {
+			if err == domain.ErrAccountNotFound {
+				continue
+			}
+			return nil, err
+		}
+		member.Username = account.Username
+	}
+
+	return members, nil
+}
+
+// AddMember grants an account a role on a project
+func (s *projectService) AddMember(ctx context.Context, projectID, username string, role domain.ProjectRole) (*domain.ProjectMember, error) {
+	if err := role.Validate(); err != nil {
+		return nil, err
+	}
+
+	if _, err := s.projectRepo.FindByID(ctx, projectID); err != nil {
+		return nil, err
+	}
+
+	if err := s.authorizer.Authorize(ctx, projectID, domain.RoleOwner, actionManage, resourceMember, username); err != nil {
+		return nil, err
+	}
+
+	account, err := s.accountRepo.FindByUsername(ctx, domain.NormalizeUsername(username))
+	if err != nil {
+		return nil, err
+	}
+
+	now := time.Now()
+	member := &domain.ProjectMember{
+		ID:        uuid.New().String(),
+		ProjectID: projectID,
+		AccountID: account.ID,
+		Username:  account.Username,
+		Role:      role,
+		CreatedAt: now,
+		UpdatedAt: now,
+	}
+	if err := s.memberRepo.Create(ctx, member); err != nil {
+		return nil, err
+	}
+
+	return member, nil
+}
+
+// UpdateMemberRole changes the role of an account on a project
+func (s *projectService) UpdateMemberRole(ctx context.Context, projectID, accountID string, role domain.ProjectRole) (*domain.ProjectMember, error) {
+	if err := role.Validate(); err != nil {
+		return nil, err
+	}
+
+	if _, err := s.projectRepo.FindByID(ctx, projectID); err != nil {
+		return nil, err
+	}
+
+	if err := s.authorizer.Authorize(ctx, projectID, domain.RoleOwner, actionManage, resourceMember, accountID); err != nil {
+		return nil, err
+	}
+
+	member, err := s.memberRepo.Find(ctx, projectID, accountID)
+	if err != nil {
+		return nil, err
+	}
+
+	if member.Role == domain.RoleOwner && role != domain.RoleOwner {
+		if err := s.ensureAnotherOwner(ctx, projectID, accountID); err != nil {
+			return nil, err
+		}
+	}
+
+	member.Role = role
+	member.UpdatedAt = time.Now()
+	if err := s.memberRepo.Update(ctx, member); err != nil {
+		return nil, err
+	}
+
+	return member, nil
+}
+
+// RemoveMember revokes an account's role on a project
+func (s *projectService) RemoveMember(ctx context.Context, projectID, accountID string) error {
+	if _, err := s.projectRepo.FindByID(ctx, projectID); err != nil {
+		return err
+	}
+
+	if err := s.authorizer.Authorize(ctx, projectID, domain.RoleOwner, actionManage, resourceMember, accountID); err != nil {
+		return err
+	}
+
+	member, err := s.memberRepo.Find(ctx, projectID, accountID)
+	if err != nil {
+		return err
+	}
+
+	if member.Role == domain.RoleOwner {
+		if err := s.ensureAnotherOwner(ctx, projectID, accountID); err != nil {
+			return err
+		}
+	}
+
+	return s.memberRepo.Delete(ctx, projectID, accountID)
+}
+
+// ensureAnotherOwner returns ErrLastProjectOwner unless the project has an owner
+// other than the given account
+func (s *projectService) ensureAnotherOwner(ctx context.Context, projectID, accountID string) error {
+	members, err := s.memberRepo.ListByProject(ctx, projectID)
+	if err != nil {
+		return err
+	}
+
+	for _, member := range members {
+		if member.Role == domain.RoleOwner && member.AccountID != accountID {
+			return nil
+		}
+	}
+	return domain.ErrLastProjectOwner
 }
 
 // generateAPIKey generates a random API key with prefix
@@ -0,0 +1,92 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/spidey52/api-logs/internal/domain"
+)
+
+func TestRegenerateAPIKeyKeepsPreviousKeyDuringGrace(t *testing.T) {
+	const grace = 50 * time.Millisecond
+	p := newTestProjects(t, grace)
+	project := p.createProject(t)
+
+	rotation, err := p.RegenerateAPIKey(adminContext(), project.ID, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if rotation.PreviousKeyID == "" || rotation.PreviousKeyExpiresAt == nil {
+		t.Fatalf("want the previous key kept for the default grace period, got %+v", rotation)
+	}
+
+	ctx := context.Background()
+	_, key, err := p.ValidateAPIKey(ctx, project.APIKey, project.Environment, domain.ScopeIngest)
+	if err != nil {
+		t.Fatalf("want the previous key valid inside the grace period, got %v", err)
+	}
+	if key.ID != rotation.PreviousKeyID || key.Name != domain.RotatedKeyName {
+		t.Fatalf("want the previous key resolved to its rotated key, got %+v", key)
+	}
+	if _, _, err := p.ValidateAPIKey(ctx, rotation.APIKey, project.Environment, domain.ScopeIngest); err != nil {
+		t.Fatalf("want the new key valid, got %v", err)
+	}
+
+	time.Sleep(time.Until(*rotation.PreviousKeyExpiresAt) + 10*time.Millisecond)
+
+	if _, _, err := p.ValidateAPIKey(ctx, project.APIKey, project.Environment, domain.ScopeIngest); !errors.Is(err, domain.ErrInvalidAPIKey) {
+		t.Fatalf("want the previous key rejected after the grace period, got %v", err)
+	}
+	if _, _, err := p.ValidateAPIKey(ctx, rotation.APIKey, project.Environment, domain.ScopeIngest); err != nil {
+		t.Fatalf("want the new key still valid, got %v", err)
+	}
+}
+
+func TestRegenerateAPIKeyWithoutGrace(t *testing.T) {
+	p := newTestProjects(t, time.Hour)
+	project := p.createProject(t)
+
+	noGrace := time.Duration(0)
+	rotation, err := p.RegenerateAPIKey(adminContext(), project.ID, &noGrace)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if rotation.PreviousKeyID != "" {
+		t.Fatalf("want no previous key kept, got %s", rotation.PreviousKeyID)
+	}
+	if _, _, err := p.ValidateAPIKey(context.Background(), project.APIKey, project.Environment, domain.ScopeIngest); !errors.Is(err, domain.ErrInvalidAPIKey) {
+		t.Fatalf("want the previous key rejected at once, got %v", err)
+	}
+}
+
+func TestRegenerateAPIKeyGracePeriodLimits(t *testing.T) {
+	p := newTestProjects(t, time.Hour)
+	project := p.createProject(t)
+
+	tests := []struct {
+		name  string
+		grace time.Duration
+		err   error
+	}{
+		{"negative", -time.Second, domain.ErrInvalidInput},
+		{"above maximum", domain.MaxRotationGracePeriod + time.Second, domain.ErrInvalidInput},
+		{"maximum", domain.MaxRotationGracePeriod, nil},
+	}
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			grace := tc.grace
+			rotation, err := p.RegenerateAPIKey(adminContext(), project.ID, &grace)
+			if !errors.Is(err, tc.err) {
+				t.Fatalf("want %v, got %v", tc.err, err)
+			}
+			if err != nil {
+				return
+			}
+			if got := time.Until(*rotation.PreviousKeyExpiresAt); got > domain.MaxRotationGracePeriod {
+				t.Fatalf("want the previous key to expire within %s, got %s", domain.MaxRotationGracePeriod, got)
+			}
+		})
+	}
+}
@@ -0,0 +1,343 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/spidey52/api-logs/internal/domain"
+	"github.com/spidey52/api-logs/internal/ports/input"
+	"github.com/spidey52/api-logs/internal/ports/output"
+	"github.com/spidey52/api-logs/pkg/cache"
+	"github.com/spidey52/api-logs/pkg/logger"
+)
+
+// Counter windows. Counters live in the shared cache so every instance
+// enforces the same limits; they expire a little after their window closes.
+const (
+	secondCounterTTL = 2 * time.Second
+	dayCounterTTL    = 48 * time.Hour
+
+	// quotaCacheTTL bounds how long an instance keeps using a quota after an
+	// admin changed it on another instance
+	quotaCacheTTL = 10 * time.Second
+)
+
+// Counter scopes
+const (
+	quotaScopeProject = "project"
+	quotaScopeKey     = "key"
+)
+
+type cachedQuota struct {
+	quota     domain.ProjectQuota
+	expiresAt time.Time
+}
+
+// quotaService implements the QuotaService interface
+type quotaService struct {
+	quotaRepo   output.QuotaRepository
+	projectRepo output.ProjectRepository
+	apiKeyRepo  output.APIKeyRepository
+	counters    cache.Cache
+
+	// defaults apply to projects without a quota of their own
+	defaults domain.ProjectQuota
+
+	mu     sync.Mutex
+	quotas map[string]cachedQuota
+}
+
+// NewQuotaService creates a new instance of QuotaService
+func NewQuotaService(
+	quotaRepo output.QuotaRepository,
+	projectRepo output.ProjectRepository,
+	apiKeyRepo output.APIKeyRepository,
+	counters cache.Cache,
+	defaults domain.ProjectQuota,
+) input.QuotaService {
+	return &quotaService{
+		quotaRepo:   quotaRepo,
+		projectRepo: projectRepo,
+		apiKeyRepo:  apiKeyRepo,
+		counters:    counters,
+		defaults:    defaults,
+		quotas:      make(map[string]cachedQuota),
+	}
+}
+
+// CheckRequest counts an ingest request against the project's and the key's
+// requests per second, in a fixed one-second window. The cache being
+// unavailable does not block ingestion: the request is let through and the
+// failure logged.
+func (s *quotaService) CheckRequest(ctx context.Context, projectID, keyID string) error {
+	quota, err := s.effectiveQuota(ctx, projectID)
+	if err != nil {
+		return err
+	}
+
+	now := time.Now().UTC()
+	retryAfter := now.Truncate(time.Second).Add(time.Second).Sub(now)
+	err = s.countRequest(ctx, secondKey(quotaScopeProject, projectID, now), quota.RequestsPerSecond, "requests_per_second", retryAfter)
+	if err == nil && keyID != "" {
+		err = s.countRequest(ctx, secondKey(quotaScopeKey, keyID, now), quota.KeyRequestsPerSecond, "key_requests_per_second", retryAfter)
+	}
+	return allowOnCacheError(projectID, err)
+}
+
+// CountLogs counts accepted logs against the project's and the key's logs per
+// day, which reset at midnight UTC. Like CheckRequest, it lets the logs
+// through when the cache is unavailable.
+func (s *quotaService) CountLogs(ctx context.Context, projectID, keyID string, logs int) error {
+	if logs <= 0 {
+		return nil
+	}
+	quota, err := s.effectiveQuota(ctx, projectID)
+	if err != nil {
+		return err
+	}
+
+	now := time.Now().UTC()
+	return allowOnCacheError(projectID, s.countDay(ctx, quota, projectID, keyID, int64(logs), now))
+}
+
+func (s *quotaService) countDay(ctx context.Context, quota *domain.ProjectQuota, projectID, keyID string, logs int64, now time.Time) error {
+	retryAfter := now.Truncate(24 * time.Hour).Add(24 * time.Hour).Sub(now)
+	projectDay := dayKey(quotaScopeProject, projectID, now)
+	if err := s.countLogs(ctx, projectDay, logs, quota.LogsPerDay, "logs_per_day", retryAfter); err != nil {
+		return err
+	}
+	if keyID != "" {
+		if err := s.countLogs(ctx, dayKey(quotaScopeKey, keyID, now), logs, quota.KeyLogsPerDay, "key_logs_per_day", retryAfter); err != nil {
+			// The project already counted these logs
+			if _, rollbackErr := s.counters.IncrByWithTTL(ctx, projectDay, -logs, dayCounterTTL); rollbackErr != nil {
+				logger.Warn("Failed to roll back project log counter", "project_id", projectID, "error", rollbackErr)
+			}
+			return err
+		}
+	}
+	return nil
+}
+
+// ReleaseLogs takes logs counted by CountLogs back out of the project's and
+// the key's logs per day
+func (s *quotaService) ReleaseLogs(ctx context.Context, projectID, keyID string, logs int) error {
+	if logs <= 0 {
+		return nil
+	}
+
+	now := time.Now().UTC()
+	if _, err := s.counters.IncrByWithTTL(ctx, dayKey(quotaScopeProject, projectID, now), -int64(logs), dayCounterTTL); err != nil {
+		return err
+	}
+	if keyID != "" {
+		if _, err := s.counters.IncrByWithTTL(ctx, dayKey(quotaScopeKey, keyID, now), -int64(logs), dayCounterTTL); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// allowOnCacheError returns a quota being exceeded, and logs and drops any
+// other error
+func allowOnCacheError(projectID string, err error) error {
+	if err == nil {
+		return nil
+	}
+	if _, ok := err.(*domain.QuotaExceededError); ok {
+		return err
+	}
+	logger.Warn("Quota check failed, allowing request", "project_id", projectID, "error", err)
+	return nil
+}
+
+// countRequest counts a request in a per-second window. Unlimited requests are
+// counted for usage reporting; denied requests count too, so a client that
+// keeps retrying stays limited.
+func (s *quotaService) countRequest(ctx context.Context, key string, limit int64, name string, retryAfter time.Duration) error {
+	count, err := s.counters.IncrByWithTTL(ctx, key, 1, secondCounterTTL)
+	if err != nil {
+		return err
+	}
+
+	if limit > 0 && count > limit {
+		return &domain.QuotaExceededError{Limit: name, RetryAfter: retryAfter}
+	}
+	return nil
+}
+
+// countLogs counts logs in a per-day window. Denied logs are taken back out,
+// since they are not stored.
+func (s *quotaService) countLogs(ctx context.Context, key string, logs, limit int64, name string, retryAfter time.Duration) error {
+	count, err := s.counters.IncrByWithTTL(ctx, key, logs, dayCounterTTL)
+	if err != nil {
+		return err
+	}
+
+	if limit > 0 && count > limit {
+		if _, err := s.counters.IncrByWithTTL(ctx, key, -logs, dayCounterTTL); err != nil {
+			return err
+		}
+		return &domain.QuotaExceededError{Limit: name, RetryAfter: retryAfter}
+	}
+	return nil
+}
+
+// GetQuota retrieves a project's effective quota
+func (s *quotaService) GetQuota(ctx context.Context, projectID string) (*domain.ProjectQuota, error) {
+	if err := requireAdmin(ctx); err != nil {
+		return nil, err
+	}
+	if _, err := s.projectRepo.FindByID(ctx, projectID); err != nil {
+		return nil, err
+	}
+
+	return s.loadQuota(ctx, projectID)
+}
+
+// GetUsage retrieves a project's and its keys' consumption in the current
+// second and UTC day
+func (s *quotaService) GetUsage(ctx context.Context, projectID string) (*domain.QuotaUsage, error) {
+	if err := requireAdmin(ctx); err != nil {
+		return nil, err
+	}
+	project, err := s.projectRepo.FindByID(ctx, projectID)
+	if err != nil {
+		return nil, err
+	}
+	keys, err := s.apiKeyRepo.ListByProject(ctx, projectID)
+	if err != nil {
+		return nil, err
+	}
+
+	now := time.Now().UTC()
+	usage := &domain.QuotaUsage{ProjectID: projectID, Day: now.Format(time.DateOnly)}
+	if usage.RequestsThisSecond, usage.LogsToday, err = s.readUsage(ctx, quotaScopeProject, projectID, now); err != nil {
+		return nil, err
+	}
+
+	// The project key is counted under the project ID, like ValidateAPIKey reports it
+	usage.Keys = make([]domain.KeyUsage, 0, len(keys)+1)
+	usage.Keys = append(usage.Keys, domain.KeyUsage{KeyID: project.ID, Name: "default", Prefix: project.APIKeyPrefix})
+	for _, key := range keys {
+		if key.IsUsable(now) {
+			usage.Keys = append(usage.Keys, domain.KeyUsage{KeyID: key.ID, Name: key.Name, Prefix: key.Prefix})
+		}
+	}
+	for i := range usage.Keys {
+		if usage.Keys[i].RequestsThisSecond, usage.Keys[i].LogsToday, err = s.readUsage(ctx, quotaScopeKey, usage.Keys[i].KeyID, now); err != nil {
+			return nil, err
+		}
+	}
+
+	return usage, nil
+}
+
+// readUsage reads a counter pair; a missing counter reads as zero
+func (s *quotaService) readUsage(ctx context.Context, scope, id string, now time.Time) (int64, int64, error) {
+	read := func(key string) (int64, error) {
+		var count int64
+		if _, err := s.counters.Get(ctx, key, &count); err != nil {
+			return 0, fmt.Errorf("read usage counter: %w", err)
+		}
+		return count, nil
+	}
+
+	requests, err := read(secondKey(scope, id, now))
+	if err != nil {
+		return 0, 0, err
+	}
+	logs, err := read(dayKey(scope, id, now))
+	if err != nil {
+		return 0, 0, err
+	}
+	return requests, logs, nil
+}
+
+// SetQuota replaces a project's quota
+func (s *quotaService) SetQuota(ctx context.Context, quota *domain.ProjectQuota) error {
+	if err := requireAdmin(ctx); err != nil {
+		return err
+	}
+	if err := quota.Validate(); err != nil {
+		return err
+	}
+	if _, err := s.projectRepo.FindByID(ctx, quota.ProjectID); err != nil {
+		return err
+	}
+
+	quota.UpdatedAt = time.Now()
+	if err := s.quotaRepo.Upsert(ctx, quota); err != nil {
+		return err
+	}
+
+	s.forget(quota.ProjectID)
+	return nil
+}
+
+// ResetQuota reverts a project to the default quota
+func (s *quotaService) ResetQuota(ctx context.Context, projectID string) error {
+	if err := requireAdmin(ctx); err != nil {
+		return err
+	}
+	if _, err := s.projectRepo.FindByID(ctx, projectID); err != nil {
+		return err
+	}
+
+	if err := s.quotaRepo.Delete(ctx, projectID); err != nil {
+		return err
+	}
+
+	s.forget(projectID)
+	return nil
+}
+
+// effectiveQuota returns the quota CheckRequest and CountLogs enforce, cached briefly so
+// ingestion does not cost a database read per request
+func (s *quotaService) effectiveQuota(ctx context.Context, projectID string) (*domain.ProjectQuota, error) {
+	s.mu.Lock()
+	cached, ok := s.quotas[projectID]
+	s.mu.Unlock()
+	if ok && time.Now().Before(cached.expiresAt) {
+		return &cached.quota, nil
+	}
+
+	quota, err := s.loadQuota(ctx, projectID)
+	if err != nil {
+		return nil, err
+	}
+
+	s.mu.Lock()
+	s.quotas[projectID] = cachedQuota{quota: *quota, expiresAt: time.Now().Add(quotaCacheTTL)}
+	s.mu.Unlock()
+	return quota, nil
+}
+
+// loadQuota reads a project's quota, falling back to the defaults
+func (s *quotaService) loadQuota(ctx context.Context, projectID string) (*domain.ProjectQuota, error) {
+	quota, err := s.quotaRepo.Find(ctx, projectID)
+	if err == domain.ErrQuotaNotFound {
+		defaults := s.defaults
+		defaults.ProjectID = projectID
+		return &defaults, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	return quota, nil
+}
+
+func (s *quotaService) forget(projectID string) {
+	s.mu.Lock()
+	delete(s.quotas, projectID)
+	s.mu.Unlock()
+}
+
+func secondKey(scope, id string, now time.Time) string {
+	return fmt.Sprintf("quota:%s:%s:requests:%d", scope, id, now.Unix())
+}
+
+func dayKey(scope, id string, now time.Time) string {
+	return fmt.Sprintf("quota:%s:%s:logs:%s", scope, id, now.Format(time.DateOnly))
+}
@@ -0,0 +1,187 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/spidey52/api-logs/internal/domain"
+	"github.com/spidey52/api-logs/internal/ports/input"
+	"github.com/spidey52/api-logs/internal/ports/output"
+	"github.com/spidey52/api-logs/pkg/redact"
+)
+
+// redactionCacheTTL bounds how long an instance keeps using a policy after an
+// owner changed it on another instance
+const redactionCacheTTL = 10 * time.Second
+
+type cachedRedactor struct {
+	redactor  *redact.Redactor
+	expiresAt time.Time
+}
+
+// redactionService implements the RedactionService interface
+type redactionService struct {
+	policyRepo  output.RedactionPolicyRepository
+	projectRepo output.ProjectRepository
+	authorizer  *Authorizer
+
+	// defaults apply to projects without a policy of their own
+	defaults domain.RedactionPolicy
+
+	mu        sync.Mutex
+	redactors map[string]cachedRedactor
+}
+
+// NewRedactionService creates a new instance of RedactionService. It fails if
+// the default policy does not compile.
+func NewRedactionService(
+	policyRepo output.RedactionPolicyRepository,
+	projectRepo output.ProjectRepository,
+	authorizer *Authorizer,
+	defaults domain.RedactionPolicy,
+) (input.RedactionService, error) {
+	if _, err := compilePolicy(&defaults); err != nil {
+		return nil, fmt.Errorf("default redaction policy: %w", err)
+	}
+
+	return &redactionService{
+		policyRepo:  policyRepo,
+		projectRepo: projectRepo,
+		authorizer:  authorizer,
+		defaults:    defaults,
+		redactors:   make(map[string]cachedRedactor),
+	}, nil
+}
+
+// Redact masks the entries' headers, path and query parameters and bodies
+// with the policy of their project
+func (s *redactionService) Redact(ctx context.Context, entries []*domain.LogEntry) error {
+	for _, entry := range entries {
+		redactor, err := s.effectiveRedactor(ctx, entry.Log.ProjectID)
+		if err != nil {
+			return err
+		}
+
+		redactor.Params(entry.Log.Params)
+		redactor.Params(entry.Log.QueryParams)
+		if entry.Headers != nil {
+			redactor.Headers(entry.Headers.RequestHeaders)
+			redactor.Headers(entry.Headers.ResponseHeaders)
+		}
+		if entry.Body != nil {
+			entry.Body.RequestBody = redactor.Body(entry.Body.RequestBody)
+			entry.Body.ResponseBody = redactor.Body(entry.Body.ResponseBody)
+		}
+	}
+	return nil
+}
+
+// GetPolicy retrieves a project's effective policy
+func (s *redactionService) GetPolicy(ctx context.Context, projectID string) (*domain.RedactionPolicy, error) {
+	if err := s.authorizer.Authorize(ctx, projectID, domain.RoleViewer, actionRead, resourceRedactionPolicy, projectID); err != nil {
+		return nil, err
+	}
+	if _, err := s.projectRepo.FindByID(ctx, projectID); err != nil {
+		return nil, err
+	}
+
+	return s.loadPolicy(ctx, projectID)
+}
+
+// SetPolicy replaces a project's policy
+func (s *redactionService) SetPolicy(ctx context.Context, policy *domain.RedactionPolicy) error {
+	if err := s.authorizer.Authorize(ctx, policy.ProjectID, domain.RoleOwner, actionManage, resourceRedactionPolicy, policy.ProjectID); err != nil {
+		return err
+	}
+	if err := policy.Validate(); err != nil {
+		return err
+	}
+	if _, err := compilePolicy(policy); err != nil {
+		return fmt.Errorf("%w: %v", domain.ErrInvalidRedactionPolicy, err)
+	}
+	if _, err := s.projectRepo.FindByID(ctx, policy.ProjectID); err != nil {
+		return err
+	}
+
+	policy.UpdatedAt = time.Now()
+	if err := s.policyRepo.Upsert(ctx, policy); err != nil {
+		return err
+	}
+
+	s.forget(policy.ProjectID)
+	return nil
+}
+
+// ResetPolicy reverts a project to the default policy
+func (s *redactionService) ResetPolicy(ctx context.Context, projectID string) error {
+	if err := s.authorizer.Authorize(ctx, projectID, domain.RoleOwner, actionManage, resourceRedactionPolicy, projectID); err != nil {
+		return err
+	}
+	if _, err := s.projectRepo.FindByID(ctx, projectID); err != nil {
+		return err
+	}
+
+	if err := s.policyRepo.Delete(ctx, projectID); err != nil {
+		return err
+	}
+
+	s.forget(projectID)
+	return nil
+}
+
+// effectiveRedactor returns the compiled policy Redact applies, cached briefly
+// so ingestion does not cost a database read per request
+func (s *redactionService) effectiveRedactor(ctx context.Context, projectID string) (*redact.Redactor, error) {
+	s.mu.Lock()
+	cached, ok := s.redactors[projectID]
+	s.mu.Unlock()
+	if ok && time.Now().Before(cached.expiresAt) {
+		return cached.redactor, nil
+	}
+
+	policy, err := s.loadPolicy(ctx, projectID)
+	if err != nil {
+		return nil, err
+	}
+	redactor, err := compilePolicy(policy)
+	if err != nil {
+		return nil, fmt.Errorf("redaction policy of project %s: %w", projectID, err)
+	}
+
+	s.mu.Lock()
+	s.redactors[projectID] = cachedRedactor{redactor: redactor, expiresAt: time.Now().Add(redactionCacheTTL)}
+	s.mu.Unlock()
+	return redactor, nil
+}
+
+// loadPolicy reads a project's policy, falling back to the default
+func (s *redactionService) loadPolicy(ctx context.Context, projectID string) (*domain.RedactionPolicy, error) {
+	policy, err := s.policyRepo.Find(ctx, projectID)
+	if err == domain.ErrRedactionPolicyNotFound {
+		defaults := s.defaults
+		defaults.ProjectID = projectID
+		return &defaults, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	return policy, nil
+}
+
+func (s *redactionService) forget(projectID string) {
+	s.mu.Lock()
+	delete(s.redactors, projectID)
+	s.mu.Unlock()
+}
+
+func compilePolicy(policy *domain.RedactionPolicy) (*redact.Redactor, error) {
+	return redact.Compile(redact.Rules{
+		Headers:   policy.Headers,
+		Params:    policy.QueryParams,
+		JSONPaths: policy.JSONPaths,
+		Patterns:  policy.Patterns,
+		Regexes:   policy.Regexes,
+	})
+}
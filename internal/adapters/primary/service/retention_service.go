@@ -0,0 +1,334 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/spidey52/api-logs/internal/domain"
+	"github.com/spidey52/api-logs/internal/ports/input"
+	"github.com/spidey52/api-logs/internal/ports/output"
+	"github.com/spidey52/api-logs/pkg/archive"
+	"github.com/spidey52/api-logs/pkg/logger"
+)
+
+// RetentionOptions configures how project data is purged
+type RetentionOptions struct {
+	// Defaults apply to the days a project's policy leaves unset
+	Defaults domain.RetentionPolicy
+	// Interval is how often Purge runs, used to date the next purge; zero
+	// means purges are not scheduled
+	Interval time.Duration
+	// BatchSize bounds the records removed by one delete, so a large purge
+	// does not hold locks for long
+	BatchSize int
+	// Archive, when set, receives a project's expired logs, headers and
+	// bodies before they are removed
+	Archive *archive.Archive
+}
+
+// expiringStore is the part of a repository retention purges
+type expiringStore interface {
+	DeleteOlderThan(ctx context.Context, projectID string, before time.Time, limit int) (int64, error)
+	VolumeOlderThan(ctx context.Context, projectID string, before time.Time) (domain.DataVolume, error)
+}
+
+// archivable finds a class of expired records for archiving and removes the
+// ones archived
+type archivable struct {
+	find   func(ctx context.Context, projectID string, before time.Time, limit int) ([]archivedRecord, error)
+	delete func(ctx context.Context, ids []string) error
+}
+
+// archivedRecord is a record to archive, with the ID delete takes and the
+// time it is archived under
+type archivedRecord struct {
+	id       string
+	storedAt time.Time
+	record   any
+}
+
+// newArchivable adapts a repository's FindOlderThan and batch delete, stamp
+// returning the ID and time of a record
+func newArchivable[T any](
+	find func(ctx context.Context, projectID string, before time.Time, limit int) ([]T, error),
+	delete func(ctx context.Context, ids []string) error,
+	stamp func(T) (string, time.Time),
+) archivable {
+	return archivable{
+		find: func(ctx context.Context, projectID string, before time.Time, limit int) ([]archivedRecord, error) {
+			found, err := find(ctx, projectID, before, limit)
+			if err != nil {
+				return nil, err
+			}
+			records := make([]archivedRecord, len(found))
+			for i, record := range found {
+				id, storedAt := stamp(record)
+				records[i] = archivedRecord{id: id, storedAt: storedAt, record: record}
+			}
+			return records, nil
+		},
+		delete: delete,
+	}
+}
+
+// retentionService implements the RetentionService interface
+type retentionService struct {
+	projectRepo output.ProjectRepository
+	stores      map[domain.DataClass]expiringStore
+	archivables map[domain.DataClass]archivable
+	authorizer  *Authorizer
+	opts        RetentionOptions
+
+	mu      sync.Mutex
+	lastRun time.Time
+}
+
+// NewRetentionService creates a new instance of RetentionService. It fails if
+// the default policy is invalid.
+func NewRetentionService(
+	projectRepo output.ProjectRepository,
+	logRepo output.APILogRepository,
+	headersRepo output.APILogHeadersRepository,
+	bodyRepo output.APILogBodyRepository,
+	accessLogRepo output.AccessLogRepository,
+	authorizer *Authorizer,
+	opts RetentionOptions,
+) (input.RetentionService, error) {
+	if err := opts.Defaults.Validate(); err != nil {
+		return nil, fmt.Errorf("default retention policy: %w", err)
+	}
+	if opts.BatchSize <= 0 {
+		opts.BatchSize = 1000
+	}
+
+	return &retentionService{
+		projectRepo: projectRepo,
+		stores: map[domain.DataClass]expiringStore{
+			domain.DataLogs:       logRepo,
+			domain.DataHeaders:    headersRepo,
+			domain.DataBodies:     bodyRepo,
+			domain.DataAccessLogs: accessLogRepo,
+		},
+		archivables: map[domain.DataClass]archivable{
+			domain.DataLogs: newArchivable(logRepo.FindOlderThan, logRepo.DeleteBatch, func(log *domain.APILog) (string, time.Time) {
+				return log.ID, log.Timestamp
+			}),
+			domain.DataHeaders: newArchivable(headersRepo.FindOlderThan, headersRepo.DeleteBatch, func(h *domain.APILogHeaders) (string, time.Time) {
+				return h.LogID, h.CreatedAt
+			}),
+			domain.DataBodies: newArchivable(bodyRepo.FindOlderThan, bodyRepo.DeleteBatch, func(b *domain.APILogBody) (string, time.Time) {
+				return b.LogID, b.CreatedAt
+			}),
+		},
+		authorizer: authorizer,
+		opts:       opts,
+		lastRun:    time.Now(),
+	}, nil
+}
+
+// GetPolicy retrieves a project's effective policy
+func (s *retentionService) GetPolicy(ctx context.Context, projectID string) (*domain.RetentionPolicy, error) {
+	if err := s.authorizer.Authorize(ctx, projectID, domain.RoleViewer, actionRead, resourceRetentionPolicy, projectID); err != nil {
+		return nil, err
+	}
+	project, err := s.projectRepo.FindByID(ctx, projectID)
+	if err != nil {
+		return nil, err
+	}
+
+	policy := project.Retention.WithDefaults(s.opts.Defaults)
+	return &policy, nil
+}
+
+// SetPolicy replaces a project's retention days
+func (s *retentionService) SetPolicy(ctx context.Context, projectID string, policy domain.RetentionPolicy) (*domain.RetentionPolicy, error) {
+	if err := s.authorizer.Authorize(ctx, projectID, domain.RoleOwner, actionManage, resourceRetentionPolicy, projectID); err != nil {
+		return nil, err
+	}
+	effective := policy.WithDefaults(s.opts.Defaults)
+	if err := effective.Validate(); err != nil {
+		return nil, err
+	}
+	project, err := s.projectRepo.FindByID(ctx, projectID)
+	if err != nil {
+		return nil, err
+	}
+
+	project.Retention = policy
+	project.UpdatedAt = time.Now()
+	if err := s.projectRepo.Update(ctx, project); err != nil {
+		return nil, err
+	}
+	return &effective, nil
+}
+
+// Preview estimates what the next purge will remove from a project: the data
+// that will be past its retention when the purge runs. When purges are not
+// scheduled, it estimates a purge run now.
+func (s *retentionService) Preview(ctx context.Context, projectID string) (*domain.RetentionPreview, error) {
+	policy, err := s.GetPolicy(ctx, projectID)
+	if err != nil {
+		return nil, err
+	}
+
+	preview := &domain.RetentionPreview{
+		ProjectID: projectID,
+		Policy:    *policy,
+		NextRunAt: s.nextRun(),
+		Classes:   make([]domain.PurgeEstimate, 0, len(domain.DataClasses)),
+	}
+	for _, class := range domain.DataClasses {
+		estimate := domain.PurgeEstimate{Class: class, Days: policy.Days(class)}
+		if estimate.Days > 0 {
+			_, estimate.Archived = s.archivable(projectID, class)
+			before := cutoff(preview.NextRunAt, estimate.Days)
+			estimate.Before = &before
+			if estimate.DataVolume, err = s.stores[class].VolumeOlderThan(ctx, projectID, before); err != nil {
+				return nil, err
+			}
+		}
+
+		preview.Classes = append(preview.Classes, estimate)
+		preview.Total.Documents += estimate.Documents
+		preview.Total.Bytes += estimate.Bytes
+	}
+	return preview, nil
+}
+
+// Purge removes each project's expired data, then the headers and bodies
+// stored without their project under the default policy. Bodies and headers
+// are purged before the logs they belong to.
+func (s *retentionService) Purge(ctx context.Context) (int64, error) {
+	now := time.Now()
+	s.mu.Lock()
+	s.lastRun = now
+	s.mu.Unlock()
+
+	projects, err := s.projectRepo.FindAll(ctx, domain.ProjectFilter{})
+	if err != nil {
+		return 0, err
+	}
+
+	var total int64
+	for _, project := range projects {
+		purged, err := s.purgeProject(ctx, project.ID, project.Retention.WithDefaults(s.opts.Defaults), now)
+		total += purged
+		if err != nil {
+			return total, fmt.Errorf("purge project %s: %w", project.ID, err)
+		}
+	}
+
+	purged, err := s.purgeProject(ctx, "", s.opts.Defaults, now)
+	total += purged
+	return total, err
+}
+
+// purgeProject removes a project's data past the policy, one batch at a time,
+// archiving the batches first when an archive is configured
+func (s *retentionService) purgeProject(ctx context.Context, projectID string, policy domain.RetentionPolicy, now time.Time) (int64, error) {
+	var total int64
+	for _, class := range domain.DataClasses {
+		days := policy.Days(class)
+		if days == 0 {
+			continue
+		}
+
+		before := cutoff(now, days)
+		store, archived := s.archivable(projectID, class)
+		var removed int64
+		for {
+			var deleted int64
+			var err error
+			if archived {
+				deleted, err = s.archiveBatch(ctx, store, projectID, class, before)
+			} else {
+				deleted, err = s.stores[class].DeleteOlderThan(ctx, projectID, before, s.opts.BatchSize)
+			}
+			removed += deleted
+			if err != nil {
+				return total + removed, fmt.Errorf("%s: %w", class, err)
+			}
+			if deleted < int64(s.opts.BatchSize) || ctx.Err() != nil {
+				break
+			}
+		}
+
+		if removed > 0 {
+			logger.Debug("Purged expired data", "project_id", projectID, "class", class, "count", removed, "before", before, "archived", archived)
+		}
+		total += removed
+	}
+	return total, ctx.Err()
+}
+
+// archivable returns how a class of a project's data is archived, if it is.
+// Headers and bodies stored without their project are not archived: their
+// logs are gone, so they could not be restored.
+func (s *retentionService) archivable(projectID string, class domain.DataClass) (archivable, bool) {
+	if s.opts.Archive == nil || projectID == "" {
+		return archivable{}, false
+	}
+	store, ok := s.archivables[class]
+	return store, ok
+}
+
+// archiveBatch archives a batch of a project's expired records, one object
+// per UTC day, and removes each day's records once its object is stored. A
+// failed write leaves the records in place for the next purge; a failed
+// delete archives them again, which restores tolerate.
+func (s *retentionService) archiveBatch(ctx context.Context, store archivable, projectID string, class domain.DataClass, before time.Time) (int64, error) {
+	records, err := store.find(ctx, projectID, before, s.opts.BatchSize)
+	if err != nil {
+		return 0, err
+	}
+
+	var removed int64
+	for len(records) > 0 {
+		// Records are found oldest first, so a day's records are adjacent
+		day := records[0].storedAt.UTC().Truncate(24 * time.Hour)
+		n := 1
+		for n < len(records) && records[n].storedAt.UTC().Truncate(24*time.Hour).Equal(day) {
+			n++
+		}
+
+		batch := make([]any, n)
+		ids := make([]string, n)
+		for i, record := range records[:n] {
+			batch[i], ids[i] = record.record, record.id
+		}
+		if _, err := s.opts.Archive.Write(ctx, projectID, string(class), day, batch); err != nil {
+			return removed, err
+		}
+		if err := store.delete(ctx, ids); err != nil {
+			return removed, err
+		}
+
+		removed += int64(n)
+		records = records[n:]
+	}
+	return removed, nil
+}
+
+// nextRun returns when the next scheduled purge runs, or now when purges are
+// not scheduled
+func (s *retentionService) nextRun() time.Time {
+	now := time.Now()
+	if s.opts.Interval <= 0 {
+		return now
+	}
+
+	s.mu.Lock()
+	next := s.lastRun.Add(s.opts.Interval)
+	s.mu.Unlock()
+	if next.Before(now) {
+		return now
+	}
+	return next
+}
+
+// cutoff returns the time before which data kept for days is expired at t
+func cutoff(t time.Time, days int) time.Time {
+	return t.Add(-time.Duration(days) * 24 * time.Hour)
+}
@@ -0,0 +1,320 @@
+package service
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/spidey52/api-logs/internal/adapters/secondary/repository/inmemory"
+	"github.com/spidey52/api-logs/internal/domain"
+	"github.com/spidey52/api-logs/internal/ports/input"
+	"github.com/spidey52/api-logs/internal/ports/output"
+	"github.com/spidey52/api-logs/pkg/archive"
+)
+
+const retentionDay = 24 * time.Hour
+
+// testRetention is a retention service purging in-memory repositories
+type testRetention struct {
+	input.RetentionService
+	projectRepo   output.ProjectRepository
+	logRepo       output.APILogRepository
+	headersRepo   output.APILogHeadersRepository
+	bodyRepo      output.APILogBodyRepository
+	accessLogRepo output.AccessLogRepository
+}
+
+// newTestRetention returns a retention service purging in-memory repositories
+func newTestRetention(t *testing.T, opts RetentionOptions) *testRetention {
+	return newTestRetentionLogs(t, inmemory.NewAPILogRepository(), opts)
+}
+
+// newTestRetentionLogs is newTestRetention purging logs from logRepo
+func newTestRetentionLogs(t *testing.T, logRepo output.APILogRepository, opts RetentionOptions) *testRetention {
+	t.Helper()
+	r := &testRetention{
+		projectRepo:   inmemory.NewProjectRepository(),
+		logRepo:       logRepo,
+		headersRepo:   inmemory.NewHeadersRepository(),
+		bodyRepo:      inmemory.NewBodyRepository(),
+		accessLogRepo: inmemory.NewAccessLogRepository(),
+	}
+	authorizer := NewAuthorizer(inmemory.NewProjectMemberRepository(), r.accessLogRepo)
+	var err error
+	r.RetentionService, err = NewRetentionService(r.projectRepo, r.logRepo, r.headersRepo, r.bodyRepo, r.accessLogRepo, authorizer, opts)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return r
+}
+
+// addProject stores a project with its own retention days
+func (r *testRetention) addProject(t *testing.T, projectID string, policy domain.RetentionPolicy) {
+	t.Helper()
+	project := &domain.Project{ID: projectID, Name: projectID, APIKeyHash: "hash-" + projectID, Environment: domain.EnvironmentDev, IsActive: true, Retention: policy}
+	if err := r.projectRepo.Create(context.Background(), project); err != nil {
+		t.Fatal(err)
+	}
+}
+
+// addLog stores a log of a project, with its headers and body when details is
+// set, and returns it
+func (r *testRetention) addLog(t *testing.T, projectID string, storedAt time.Time, details bool) *domain.APILog {
+	t.Helper()
+	ctx := context.Background()
+	log := &domain.APILog{
+		ID:          projectID + "-" + storedAt.Format(time.RFC3339Nano),
+		ProjectID:   projectID,
+		Environment: domain.EnvironmentDev,
+		Method:      domain.MethodGET,
+		Path:        "/items",
+		StatusCode:  200,
+		Timestamp:   storedAt,
+	}
+	if err := r.logRepo.Create(ctx, log); err != nil {
+		t.Fatal(err)
+	}
+	if !details {
+		return log
+	}
+
+	if err := r.headersRepo.Create(ctx, &domain.APILogHeaders{ID: log.ID, LogID: log.ID, ProjectID: projectID, RequestHeaders: map[string]any{"Accept": "*/*"}, CreatedAt: storedAt}); err != nil {
+		t.Fatal(err)
+	}
+	if err := r.bodyRepo.Create(ctx, &domain.APILogBody{ID: log.ID, LogID: log.ID, ProjectID: projectID, ResponseBody: "ok", CreatedAt: storedAt}); err != nil {
+		t.Fatal(err)
+	}
+	if err := r.accessLogRepo.Create(ctx, &domain.AccessLog{ID: log.ID, ProjectID: projectID, Timestamp: storedAt, Action: actionRead}); err != nil {
+		t.Fatal(err)
+	}
+	return log
+}
+
+// remaining returns how many of a project's records a store still holds
+func remaining(t *testing.T, store expiringStore, projectID string) int64 {
+	t.Helper()
+	volume, err := store.VolumeOlderThan(context.Background(), projectID, time.Now().Add(time.Hour))
+	if err != nil {
+		t.Fatal(err)
+	}
+	return volume.Documents
+}
+
+func TestPurgeAppliesPolicies(t *testing.T) {
+	r := newTestRetention(t, RetentionOptions{
+		Defaults: domain.RetentionPolicy{LogsDays: 30, HeadersDays: 7, BodiesDays: 3, AccessLogsDays: 15},
+	})
+	r.addProject(t, "defaults", domain.RetentionPolicy{})
+	r.addProject(t, "custom", domain.RetentionPolicy{LogsDays: 10, AccessLogsDays: 60})
+
+	now := time.Now()
+	for _, projectID := range []string{"defaults", "custom"} {
+		for _, age := range []int{2, 5, 12, 25, 45} {
+			r.addLog(t, projectID, now.Add(-time.Duration(age)*retentionDay), true)
+		}
+	}
+
+	purged, err := r.Purge(context.Background())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if purged != 20 {
+		t.Fatalf("want 20 records purged, got %d", purged)
+	}
+
+	tests := []struct {
+		projectID string
+		class     string
+		store     expiringStore
+		want      int64
+	}{
+		{"defaults", "logs", r.logRepo, 4},
+		{"defaults", "headers", r.headersRepo, 2},
+		{"defaults", "bodies", r.bodyRepo, 1},
+		{"defaults", "access logs", r.accessLogRepo, 3},
+		{"custom", "logs", r.logRepo, 2},
+		{"custom", "headers", r.headersRepo, 2},
+		{"custom", "bodies", r.bodyRepo, 1},
+		{"custom", "access logs", r.accessLogRepo, 5},
+	}
+	for _, tc := range tests {
+		if got := remaining(t, tc.store, tc.projectID); got != tc.want {
+			t.Errorf("%s %s: want %d kept, got %d", tc.projectID, tc.class, tc.want, got)
+		}
+	}
+}
+
+// batchRecordingLogs records the deletes of a project's expired logs
+type batchRecordingLogs struct {
+	output.APILogRepository
+	projectID string
+	limits    []int
+	deleted   []int64
+}
+
+func (r *batchRecordingLogs) DeleteOlderThan(ctx context.Context, projectID string, before time.Time, limit int) (int64, error) {
+	deleted, err := r.APILogRepository.DeleteOlderThan(ctx, projectID, before, limit)
+	if projectID == r.projectID {
+		r.limits = append(r.limits, limit)
+		r.deleted = append(r.deleted, deleted)
+	}
+	return deleted, err
+}
+
+func TestPurgeDeletesInBatches(t *testing.T) {
+	logs := &batchRecordingLogs{APILogRepository: inmemory.NewAPILogRepository(), projectID: "p1"}
+	r := newTestRetentionLogs(t, logs, RetentionOptions{Defaults: domain.RetentionPolicy{LogsDays: 30, HeadersDays: 30, BodiesDays: 30}, BatchSize: 2})
+	r.addProject(t, "p1", domain.RetentionPolicy{})
+
+	now := time.Now()
+	for i := 0; i < 5; i++ {
+		r.addLog(t, "p1", now.Add(-40*retentionDay-time.Duration(i)*time.Minute), false)
+	}
+	r.addLog(t, "p1", now.Add(-time.Hour), false)
+
+	if _, err := r.Purge(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+	if len(logs.deleted) != 3 || logs.deleted[0] != 2 || logs.deleted[1] != 2 || logs.deleted[2] != 1 {
+		t.Fatalf("want batches of 2, 2 and 1 logs, got %v", logs.deleted)
+	}
+	for _, limit := range logs.limits {
+		if limit != 2 {
+			t.Fatalf("want every delete limited to the batch size, got %v", logs.limits)
+		}
+	}
+	if got := remaining(t, r.logRepo, "p1"); got != 1 {
+		t.Fatalf("want the recent log kept, got %d logs", got)
+	}
+}
+
+// watchedSink calls put before storing each object, failing the write when
+// it returns an error
+type watchedSink struct {
+	archive.Sink
+	put func() error
+}
+
+func (s *watchedSink) Put(ctx context.Context, key string, data []byte) error {
+	if err := s.put(); err != nil {
+		return err
+	}
+	return s.Sink.Put(ctx, key, data)
+}
+
+func TestPurgeArchivesBeforeDeleting(t *testing.T) {
+	files, err := archive.NewFileSink(t.TempDir())
+	if err != nil {
+		t.Fatal(err)
+	}
+	sink := &watchedSink{Sink: files}
+	archived := archive.New(sink)
+	r := newTestRetention(t, RetentionOptions{Defaults: domain.RetentionPolicy{LogsDays: 30, HeadersDays: 30, BodiesDays: 30}, Archive: archived})
+	r.addProject(t, "p1", domain.RetentionPolicy{})
+
+	// Two expired days: two logs on the first, one on the second
+	first := time.Now().UTC().Truncate(retentionDay).Add(-40 * retentionDay)
+	r.addLog(t, "p1", first.Add(time.Hour), false)
+	r.addLog(t, "p1", first.Add(2*time.Hour), false)
+	r.addLog(t, "p1", first.Add(retentionDay+time.Hour), false)
+	r.addLog(t, "p1", time.Now().Add(-time.Hour), false)
+
+	// A failed write leaves the logs in place
+	sink.put = func() error { return errors.New("sink unavailable") }
+	if _, err := r.Purge(context.Background()); err == nil {
+		t.Fatal("want the failed archive write reported")
+	}
+	if got := remaining(t, r.logRepo, "p1"); got != 4 {
+		t.Fatalf("want no log deleted without its archive, got %d logs", got)
+	}
+
+	var stored []int64
+	sink.put = func() error {
+		stored = append(stored, remaining(t, r.logRepo, "p1"))
+		return nil
+	}
+	purged, err := r.Purge(context.Background())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if purged != 3 {
+		t.Fatalf("want 3 logs purged, got %d", purged)
+	}
+	if len(stored) != 2 || stored[0] != 4 || stored[1] != 2 {
+		t.Fatalf("want each day archived while its logs were stored, got %v logs at each write", stored)
+	}
+
+	objects, err := archived.Objects(context.Background(), "p1", string(domain.DataLogs), first, first.Add(retentionDay))
+	if err != nil {
+		t.Fatal(err)
+	}
+	records := 0
+	for _, object := range objects {
+		if err := archived.Read(context.Background(), object.Key, func(json.RawMessage) error {
+			records++
+			return nil
+		}); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if len(objects) != 2 || records != 3 {
+		t.Fatalf("want 3 logs archived in 2 objects, got %d in %d", records, len(objects))
+	}
+}
+
+func TestPreviewEstimatesNextPurge(t *testing.T) {
+	r := newTestRetention(t, RetentionOptions{
+		Defaults: domain.RetentionPolicy{LogsDays: 30, HeadersDays: 7, BodiesDays: 7},
+		Interval: 2 * retentionDay,
+	})
+	r.addProject(t, "p1", domain.RetentionPolicy{})
+
+	// The 29 day old log expires before the next purge, in two days
+	now := time.Now()
+	expiring := []*domain.APILog{
+		r.addLog(t, "p1", now.Add(-29*retentionDay), false),
+		r.addLog(t, "p1", now.Add(-31*retentionDay), false),
+	}
+	r.addLog(t, "p1", now.Add(-10*retentionDay), false)
+	var bytes int64
+	for _, log := range expiring {
+		data, err := json.Marshal(log)
+		if err != nil {
+			t.Fatal(err)
+		}
+		bytes += int64(len(data))
+	}
+
+	preview, err := r.Preview(adminContext(), "p1")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if next := time.Until(preview.NextRunAt); next < 2*retentionDay-time.Minute || next > 2*retentionDay {
+		t.Fatalf("want the next purge in 2 days, got %s", preview.NextRunAt)
+	}
+
+	var total domain.DataVolume
+	for _, estimate := range preview.Classes {
+		total.Documents += estimate.Documents
+		total.Bytes += estimate.Bytes
+		switch estimate.Class {
+		case domain.DataLogs:
+			if estimate.Days != 30 || estimate.Before == nil || estimate.Documents != 2 || estimate.Bytes != bytes {
+				t.Fatalf("want 2 logs of %d bytes, got %+v", bytes, estimate)
+			}
+		case domain.DataAccessLogs:
+			if estimate.Days != 0 || estimate.Before != nil || estimate.Documents != 0 {
+				t.Fatalf("want access logs kept forever, got %+v", estimate)
+			}
+		}
+	}
+	if preview.Total != total {
+		t.Fatalf("want the total %+v, got %+v", total, preview.Total)
+	}
+
+	if _, err := r.Preview(context.Background(), "p1"); !errors.Is(err, domain.ErrForbidden) {
+		t.Fatalf("want a preview without a principal denied, got %v", err)
+	}
+}
@@ -0,0 +1,314 @@
+package service
+
+import (
+	"context"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/spidey52/api-logs/internal/domain"
+	"github.com/spidey52/api-logs/internal/ports/input"
+	"github.com/spidey52/api-logs/internal/ports/output"
+)
+
+// RollupOptions configures how long log summaries are kept
+type RollupOptions struct {
+	// MinuteRetention is how long minute summaries are kept; zero keeps them
+	// forever. Minutes older than this are refreshed a whole hour at a time,
+	// as their hour's other minutes may have been purged.
+	MinuteRetention time.Duration
+	// HourRetention is how long hour summaries are kept; zero keeps them
+	// forever
+	HourRetention time.Duration
+}
+
+// rollupService implements the RollupService interface. Summaries are never
+// incremented: the minutes logs are stored in are marked, and a refresh
+// recomputes them from the stored logs, so logs stored twice, retried or
+// restored, are counted once.
+type rollupService struct {
+	logRepo     output.APILogRepository
+	summaryRepo output.LogSummaryRepository
+	projectRepo output.ProjectRepository
+	opts        RollupOptions
+
+	mu sync.Mutex
+	// pending holds the marked minutes of each project
+	pending map[string]map[time.Time]bool
+}
+
+// NewRollupService creates a new instance of RollupService
+func NewRollupService(
+	logRepo output.APILogRepository,
+	summaryRepo output.LogSummaryRepository,
+	projectRepo output.ProjectRepository,
+	opts RollupOptions,
+) input.RollupService {
+	return &rollupService{
+		logRepo:     logRepo,
+		summaryRepo: summaryRepo,
+		projectRepo: projectRepo,
+		opts:        opts,
+		pending:     make(map[string]map[time.Time]bool),
+	}
+}
+
+// Track marks the minutes of stored logs for the next refresh
+func (s *rollupService) Track(logs []*domain.APILog) {
+	var horizon time.Time
+	if s.opts.MinuteRetention > 0 {
+		horizon = time.Now().Add(-s.opts.MinuteRetention)
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	for _, log := range logs {
+		minute := domain.GranularityMinute.Bucket(log.Timestamp)
+		if minute.Before(horizon) {
+			s.markRange(log.ProjectID, domain.GranularityHour.Bucket(minute), time.Hour)
+			continue
+		}
+		s.mark(log.ProjectID, minute)
+	}
+}
+
+// Backfill marks every minute since a time, of every project
+func (s *rollupService) Backfill(ctx context.Context, since time.Time) error {
+	projects, err := s.projectRepo.FindAll(ctx, domain.ProjectFilter{})
+	if err != nil {
+		return err
+	}
+
+	from := domain.GranularityMinute.Bucket(since)
+	to := time.Now()
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	for _, project := range projects {
+		s.markRange(project.ID, from, to.Sub(from))
+	}
+	return nil
+}
+
+// mark marks a minute of a project; the caller holds mu
+func (s *rollupService) mark(projectID string, minute time.Time) {
+	minutes, ok := s.pending[projectID]
+	if !ok {
+		minutes = make(map[time.Time]bool)
+		s.pending[projectID] = minutes
+	}
+	minutes[minute] = true
+}
+
+// markRange marks the minutes of a project in [from, from+d); the caller
+// holds mu
+func (s *rollupService) markRange(projectID string, from time.Time, d time.Duration) {
+	for minute := from; minute.Before(from.Add(d)); minute = minute.Add(time.Minute) {
+		s.mark(projectID, minute)
+	}
+}
+
+// Refresh recomputes the summaries of the marked minutes. The minutes of a
+// project that fails to refresh stay marked for the next one.
+func (s *rollupService) Refresh(ctx context.Context) (int, error) {
+	s.mu.Lock()
+	pending := s.pending
+	s.pending = make(map[string]map[time.Time]bool)
+	s.mu.Unlock()
+
+	refreshed := 0
+	for projectID, minutes := range pending {
+		if err := s.refreshProject(ctx, projectID, minutes); err != nil {
+			s.mu.Lock()
+			for left, leftMinutes := range pending {
+				for minute := range leftMinutes {
+					s.mark(left, minute)
+				}
+			}
+			s.mu.Unlock()
+			return refreshed, err
+		}
+		refreshed += len(minutes)
+		delete(pending, projectID)
+	}
+	return refreshed, nil
+}
+
+// refreshProject recomputes a project's minutes from its logs, reading each
+// run of consecutive minutes within an hour at once, then the hours holding
+// them from their minutes
+func (s *rollupService) refreshProject(ctx context.Context, projectID string, marked map[time.Time]bool) error {
+	minutes := make([]time.Time, 0, len(marked))
+	for minute := range marked {
+		minutes = append(minutes, minute)
+	}
+	sort.Slice(minutes, func(i, j int) bool { return minutes[i].Before(minutes[j]) })
+
+	var hours []time.Time
+	for start := 0; start < len(minutes); {
+		hour := domain.GranularityHour.Bucket(minutes[start])
+		end := start + 1
+		for end < len(minutes) && minutes[end].Equal(minutes[end-1].Add(time.Minute)) &&
+			domain.GranularityHour.Bucket(minutes[end]).Equal(hour) {
+			end++
+		}
+
+		from, to := minutes[start], minutes[end-1].Add(time.Minute)
+		if err := s.refreshMinutes(ctx, projectID, from, to); err != nil {
+			return err
+		}
+		if len(hours) == 0 || !hours[len(hours)-1].Equal(hour) {
+			hours = append(hours, hour)
+		}
+		start = end
+	}
+
+	for _, hour := range hours {
+		if err := s.refreshHour(ctx, projectID, hour); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// refreshMinutes recomputes a project's minute summaries in [from, to) from
+// its logs
+func (s *rollupService) refreshMinutes(ctx context.Context, projectID string, from, to time.Time) error {
+	last := to.Add(-time.Nanosecond)
+	logs, err := s.logRepo.FindByFilter(ctx, domain.LogFilter{ProjectID: projectID, FromDate: &from, ToDate: &last})
+	if err != nil {
+		return err
+	}
+
+	groups := newSummaryGroups()
+	for _, log := range logs {
+		groups.get(domain.NewLogSummary(log, domain.GranularityMinute)).Add(log)
+	}
+	return s.summaryRepo.Replace(ctx, projectID, domain.GranularityMinute, from, to, groups.summaries)
+}
+
+// refreshHour recomputes a project's hour summaries from its minute summaries
+func (s *rollupService) refreshHour(ctx context.Context, projectID string, hour time.Time) error {
+	minutes, err := s.summaryRepo.Find(ctx, domain.SummaryFilter{
+		ProjectID:   projectID,
+		Granularity: domain.GranularityMinute,
+		From:        hour,
+		To:          hour.Add(time.Hour),
+	})
+	if err != nil {
+		return err
+	}
+
+	groups := newSummaryGroups()
+	for _, minute := range minutes {
+		summary := *minute
+		summary.Granularity = domain.GranularityHour
+		summary.Bucket = hour
+		groups.get(&summary).Merge(minute)
+	}
+	return s.summaryRepo.Replace(ctx, projectID, domain.GranularityHour, hour, hour.Add(time.Hour), groups.summaries)
+}
+
+// summaryGroups collects summaries by bucket and dimensions, in the order
+// they were first seen
+type summaryGroups struct {
+	byKey     map[summaryKey]*domain.LogSummary
+	summaries []*domain.LogSummary
+}
+
+type summaryKey struct {
+	bucket      time.Time
+	environment domain.Environment
+	method      domain.HTTPMethod
+	route       string
+}
+
+func newSummaryGroups() *summaryGroups {
+	return &summaryGroups{byKey: make(map[summaryKey]*domain.LogSummary)}
+}
+
+// get returns the collected summary with the bucket and dimensions of s,
+// adding an empty one if there is none
+func (g *summaryGroups) get(s *domain.LogSummary) *domain.LogSummary {
+	key := summaryKey{bucket: s.Bucket, environment: s.Environment, method: s.Method, route: s.Route}
+	if summary, ok := g.byKey[key]; ok {
+		return summary
+	}
+
+	summary := &domain.LogSummary{
+		ProjectID:   s.ProjectID,
+		Environment: s.Environment,
+		Granularity: s.Granularity,
+		Bucket:      s.Bucket,
+		Method:      s.Method,
+		Route:       s.Route,
+		StatusCodes: make(map[int]int64),
+		Histogram:   make([]int64, len(domain.LatencyBounds)+1),
+	}
+	g.byKey[key] = summary
+	g.summaries = append(g.summaries, summary)
+	return summary
+}
+
+// Purge removes the minute and hour summaries past their retention
+func (s *rollupService) Purge(ctx context.Context) (int64, error) {
+	var purged int64
+	for granularity, retention := range map[domain.SummaryGranularity]time.Duration{
+		domain.GranularityMinute: s.opts.MinuteRetention,
+		domain.GranularityHour:   s.opts.HourRetention,
+	} {
+		if retention <= 0 {
+			continue
+		}
+		deleted, err := s.summaryRepo.DeleteOlderThan(ctx, granularity, time.Now().Add(-retention))
+		purged += deleted
+		if err != nil {
+			return purged, err
+		}
+	}
+	return purged, nil
+}
+
+// Summaries retrieves the summaries covering a project's logs in [from, to)
+func (s *rollupService) Summaries(ctx context.Context, projectID string, environment domain.Environment, from, to time.Time) ([]*domain.LogSummary, error) {
+	find := func(granularity domain.SummaryGranularity, from, to time.Time) ([]*domain.LogSummary, error) {
+		if !from.Before(to) {
+			return nil, nil
+		}
+		return s.summaryRepo.Find(ctx, domain.SummaryFilter{
+			ProjectID:   projectID,
+			Environment: environment,
+			Granularity: granularity,
+			From:        from,
+			To:          to,
+		})
+	}
+
+	firstHour := from.Truncate(time.Hour)
+	if firstHour.Before(from) {
+		firstHour = firstHour.Add(time.Hour)
+	}
+	lastHour := to.Truncate(time.Hour)
+	if !firstHour.Before(lastHour) {
+		return find(domain.GranularityMinute, from, to)
+	}
+
+	var summaries []*domain.LogSummary
+	for _, part := range []struct {
+		granularity domain.SummaryGranularity
+		from, to    time.Time
+	}{
+		{domain.GranularityMinute, from, firstHour},
+		{domain.GranularityHour, firstHour, lastHour},
+		{domain.GranularityMinute, lastHour, to},
+	} {
+		found, err := find(part.granularity, part.from, part.to)
+		if err != nil {
+			return nil, err
+		}
+		summaries = append(summaries, found...)
+	}
+	return summaries, nil
+}
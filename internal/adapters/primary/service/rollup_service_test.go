@@ -0,0 +1,216 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/spidey52/api-logs/internal/adapters/secondary/repository/inmemory"
+	"github.com/spidey52/api-logs/internal/domain"
+	"github.com/spidey52/api-logs/internal/ports/input"
+	"github.com/spidey52/api-logs/internal/ports/output"
+)
+
+// failingSummaries fails to replace summaries while fail is set
+type failingSummaries struct {
+	output.LogSummaryRepository
+	fail bool
+}
+
+func (r *failingSummaries) Replace(ctx context.Context, projectID string, granularity domain.SummaryGranularity, from, to time.Time, summaries []*domain.LogSummary) error {
+	if r.fail {
+		return errors.New("summaries unavailable")
+	}
+	return r.LogSummaryRepository.Replace(ctx, projectID, granularity, from, to, summaries)
+}
+
+// testRollups is a rollup service summarizing an in-memory log repository
+type testRollups struct {
+	input.RollupService
+	logRepo   output.APILogRepository
+	summaries *failingSummaries
+}
+
+func newTestRollups(t *testing.T, opts RollupOptions) *testRollups {
+	t.Helper()
+	r := &testRollups{
+		logRepo:   inmemory.NewAPILogRepository(),
+		summaries: &failingSummaries{LogSummaryRepository: inmemory.NewLogSummaryRepository()},
+	}
+	r.RollupService = NewRollupService(r.logRepo, r.summaries, inmemory.NewProjectRepository(), opts)
+	return r
+}
+
+// store stores logs and tracks them, like the log service does
+func (r *testRollups) store(t *testing.T, logs ...*domain.APILog) {
+	t.Helper()
+	if err := r.logRepo.CreateMany(context.Background(), logs); err != nil {
+		t.Fatal(err)
+	}
+	r.Track(logs)
+}
+
+// refresh refreshes the marked minutes, expecting want of them
+func (r *testRollups) refresh(t *testing.T, want int) {
+	t.Helper()
+	refreshed, err := r.Refresh(context.Background())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if refreshed != want {
+		t.Fatalf("want %d minutes refreshed, got %d", want, refreshed)
+	}
+}
+
+// find returns the summary of p1's method in a bucket
+func (r *testRollups) find(t *testing.T, granularity domain.SummaryGranularity, bucket time.Time, method domain.HTTPMethod) *domain.LogSummary {
+	t.Helper()
+	summaries, err := r.summaries.Find(context.Background(), domain.SummaryFilter{
+		ProjectID:   "p1",
+		Granularity: granularity,
+		From:        bucket,
+		To:          bucket.Add(granularity.Duration()),
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, summary := range summaries {
+		if summary.Method == method {
+			return summary
+		}
+	}
+	t.Fatalf("no %s summary of %s at %s", granularity, method, bucket)
+	return nil
+}
+
+func newRollupLog(id string, method domain.HTTPMethod, status int, responseTime int64, ts time.Time) *domain.APILog {
+	return &domain.APILog{
+		ID:           id,
+		ProjectID:    "p1",
+		Environment:  domain.EnvironmentDev,
+		Method:       method,
+		Path:         "/items",
+		StatusCode:   status,
+		ResponseTime: responseTime,
+		Timestamp:    ts,
+	}
+}
+
+// histogram returns a latency histogram counting one response per bucket index
+func histogram(buckets ...int) []int64 {
+	counts := make([]int64, len(domain.LatencyBounds)+1)
+	for _, bucket := range buckets {
+		counts[bucket]++
+	}
+	return counts
+}
+
+func TestRefreshSummarizesMinutesAndHours(t *testing.T) {
+	r := newTestRollups(t, RollupOptions{})
+	hour := time.Now().UTC().Truncate(time.Hour).Add(-2 * time.Hour)
+	first, second := hour.Add(time.Minute), hour.Add(2*time.Minute)
+
+	r.store(t,
+		newRollupLog("l1", domain.MethodGET, 200, 3, first.Add(time.Second)),
+		newRollupLog("l2", domain.MethodGET, 404, 30, first.Add(2*time.Second)),
+		newRollupLog("l3", domain.MethodGET, 503, 20000, first.Add(3*time.Second)),
+		newRollupLog("l4", domain.MethodPOST, 201, 7, first.Add(4*time.Second)),
+		newRollupLog("l5", domain.MethodGET, 200, 100, second.Add(time.Second)),
+	)
+	r.refresh(t, 2)
+
+	got := r.find(t, domain.GranularityMinute, first, domain.MethodGET)
+	want := &domain.LogSummary{
+		ProjectID: "p1", Environment: domain.EnvironmentDev, Granularity: domain.GranularityMinute,
+		Bucket: first, Method: domain.MethodGET, Route: "/items",
+		Count: 3, ErrorCount: 2, Status2xx: 1, Status4xx: 1, Status5xx: 1,
+		StatusCodes: map[int]int64{200: 1, 404: 1, 503: 1},
+		LatencySum:  20033, LatencyMin: 3, LatencyMax: 20000,
+		Histogram: histogram(0, 3, len(domain.LatencyBounds)),
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Fatalf("minute summary:\n got %+v\nwant %+v", got, want)
+	}
+
+	// The hour adds up its minutes
+	got = r.find(t, domain.GranularityHour, hour, domain.MethodGET)
+	want.Granularity, want.Bucket = domain.GranularityHour, hour
+	want.Count, want.Status2xx, want.LatencySum = 4, 2, 20133
+	want.StatusCodes = map[int]int64{200: 2, 404: 1, 503: 1}
+	want.Histogram = histogram(0, 3, 4, len(domain.LatencyBounds))
+	if !reflect.DeepEqual(got, want) {
+		t.Fatalf("hour summary:\n got %+v\nwant %+v", got, want)
+	}
+	if post := r.find(t, domain.GranularityHour, hour, domain.MethodPOST); post.Count != 1 || post.Status2xx != 1 || post.ErrorCount != 0 {
+		t.Fatalf("want the POST summarized on its own, got %+v", post)
+	}
+
+	summaries, err := r.Summaries(context.Background(), "p1", "", hour, hour.Add(time.Hour))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(summaries) != 2 || summaries[0].Granularity != domain.GranularityHour {
+		t.Fatalf("want the hour's 2 summaries, got %d", len(summaries))
+	}
+
+	r.refresh(t, 0)
+}
+
+func TestRefreshCountsLogsStoredTwiceOnce(t *testing.T) {
+	r := newTestRollups(t, RollupOptions{})
+	minute := time.Now().UTC().Truncate(time.Minute).Add(-10 * time.Minute)
+	logs := []*domain.APILog{
+		newRollupLog("l1", domain.MethodGET, 200, 10, minute.Add(time.Second)),
+		newRollupLog("l2", domain.MethodGET, 500, 20, minute.Add(2*time.Second)),
+	}
+
+	r.store(t, logs...)
+	r.store(t, logs...)
+	r.refresh(t, 1)
+
+	// Stored again after the refresh, as a restore would
+	r.store(t, logs...)
+	r.refresh(t, 1)
+
+	for _, granularity := range []domain.SummaryGranularity{domain.GranularityMinute, domain.GranularityHour} {
+		summary := r.find(t, granularity, granularity.Bucket(minute), domain.MethodGET)
+		if summary.Count != 2 || summary.ErrorCount != 1 || summary.LatencySum != 30 {
+			t.Fatalf("%s: want the 2 logs counted once, got %+v", granularity, summary)
+		}
+	}
+}
+
+func TestRefreshRemarksMinutesAfterFailure(t *testing.T) {
+	r := newTestRollups(t, RollupOptions{})
+	minute := time.Now().UTC().Truncate(time.Minute).Add(-10 * time.Minute)
+	r.store(t, newRollupLog("l1", domain.MethodGET, 200, 10, minute))
+
+	r.summaries.fail = true
+	if _, err := r.Refresh(context.Background()); err == nil {
+		t.Fatal("want the failed refresh reported")
+	}
+
+	r.summaries.fail = false
+	r.refresh(t, 1)
+	if summary := r.find(t, domain.GranularityMinute, minute, domain.MethodGET); summary.Count != 1 {
+		t.Fatalf("want the log summarized on the next refresh, got %+v", summary)
+	}
+}
+
+func TestTrackRefreshesWholeHourPastMinuteRetention(t *testing.T) {
+	r := newTestRollups(t, RollupOptions{MinuteRetention: time.Hour})
+	hour := time.Now().UTC().Truncate(time.Hour).Add(-3 * time.Hour)
+	recent := time.Now().UTC().Truncate(time.Minute).Add(-time.Minute)
+
+	r.store(t,
+		newRollupLog("old", domain.MethodGET, 200, 10, hour.Add(10*time.Minute)),
+		newRollupLog("recent", domain.MethodGET, 200, 10, recent),
+	)
+	r.refresh(t, 61)
+
+	if summary := r.find(t, domain.GranularityHour, hour, domain.MethodGET); summary.Count != 1 {
+		t.Fatalf("want the old log's hour summarized, got %+v", summary)
+	}
+}
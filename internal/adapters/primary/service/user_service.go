@@ -11,13 +11,16 @@ import (
 )
 
 type userService struct {
-	userRepo output.UserRepository
+	userRepo   output.UserRepository
+	authorizer *Authorizer
 }
 
-// NewUserService creates a new user service
-func NewUserService(userRepo output.UserRepository) input.UserService {
+// NewUserService creates a new user service. Users are read by viewers and
+// managed by editors of their project.
+func NewUserService(userRepo output.UserRepository, authorizer *Authorizer) input.UserService {
 	return &userService{
-		userRepo: userRepo,
+		userRepo:   userRepo,
+		authorizer: authorizer,
 	}
 }
 
@@ -27,6 +30,10 @@ func (s *userService) CreateUser(ctx context.Context, user *domain.User) error {
 		return err
 	}
 
+	if err := s.authorizer.Authorize(ctx, user.ProjectID, domain.RoleEditor, actionWrite, resourceUser, ""); err != nil {
+		return err
+	}
+
 	// Check if identifier already exists
 	existing, _ := s.userRepo.FindByIdentifier(ctx, user.Identifier, user.ProjectID)
 	if existing != nil {
@@ -41,10 +48,21 @@ func (s *userService) CreateUser(ctx context.Context, user *domain.User) error {
 }
 
 func (s *userService) GetUser(ctx context.Context, id string) (*domain.User, error) {
-	return s.userRepo.FindByID(ctx, id)
+	user, err := s.userRepo.FindByID(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := s.authorizer.Authorize(ctx, user.ProjectID, domain.RoleViewer, actionRead, resourceUser, id); err != nil {
+		return nil, err
+	}
+	return user, nil
 }
 
 func (s *userService) GetUserByIdentifier(ctx context.Context, identifier string, projectID string) (*domain.User, error) {
+	if err := s.authorizer.Authorize(ctx, projectID, domain.RoleViewer, actionRead, resourceUser, identifier); err != nil {
+		return nil, err
+	}
 	return s.userRepo.FindByIdentifier(ctx, identifier, projectID)
 }
 
@@ -60,6 +78,13 @@ func (s *userService) UpdateUser(ctx context.Context, user *domain.User) error {
 		return err
 	}
 
+	if err := s.authorizer.Authorize(ctx, existing.ProjectID, domain.RoleEditor, actionWrite, resourceUser, user.ID); err != nil {
+		return err
+	}
+
+	// Users stay in the project they were created in
+	user.ProjectID = existing.ProjectID
+
 	// Check if identifier is being changed and if it's already taken
 	if existing.Identifier != user.Identifier {
 		existingByIdentifier, _ := s.userRepo.FindByIdentifier(ctx, user.Identifier, user.ProjectID)
@@ -73,15 +98,23 @@ func (s *userService) UpdateUser(ctx context.Context, user *domain.User) error {
 
 func (s *userService) DeleteUser(ctx context.Context, id string) error {
 	// Check if user exists
-	_, err := s.userRepo.FindByID(ctx, id)
+	existing, err := s.userRepo.FindByID(ctx, id)
 	if err != nil {
 		return err
 	}
 
+	if err := s.authorizer.Authorize(ctx, existing.ProjectID, domain.RoleEditor, actionDelete, resourceUser, id); err != nil {
+		return err
+	}
+
 	return s.userRepo.Delete(ctx, id)
 }
 
-func (s *userService) ListUsers(ctx context.Context, page, pageSize int) ([]*domain.User, int, error) {
+func (s *userService) ListUsers(ctx context.Context, projectID string, page, pageSize int) ([]*domain.User, int, error) {
+	if err := s.authorizer.Authorize(ctx, projectID, domain.RoleViewer, actionRead, resourceUser, ""); err != nil {
+		return nil, 0, err
+	}
+
 	if page < 1 {
 		page = 1
 	}
@@ -89,5 +122,5 @@ func (s *userService) ListUsers(ctx context.Context, page, pageSize int) ([]*dom
 		pageSize = 10
 	}
 
-	return s.userRepo.List(ctx, page, pageSize)
+	return s.userRepo.List(ctx, projectID, page, pageSize)
 }
@@ -0,0 +1,173 @@
+package contract
+
+import (
+	"testing"
+	"time"
+
+	"github.com/spidey52/api-logs/internal/domain"
+	"github.com/spidey52/api-logs/internal/ports/output"
+)
+
+func newAccessLog(projectID, actorID, action string, outcome domain.AccessOutcome, ts time.Time) *domain.AccessLog {
+	return &domain.AccessLog{
+		ID:           newID(),
+		ProjectID:    projectID,
+		Timestamp:    ts,
+		ActorID:      actorID,
+		ActorType:    domain.ActorUser,
+		Action:       action,
+		ResourceType: "api_log",
+		ResourceID:   newID(),
+		Outcome:      outcome,
+		Message:      actorID + " performed " + action,
+		Metadata:     map[string]any{},
+	}
+}
+
+func accessLogIDs(logs []*domain.AccessLog) []string {
+	ids := make([]string, len(logs))
+	for i, log := range logs {
+		ids[i] = log.ID
+	}
+	return ids
+}
+
+// RunAccessLogRepository runs the AccessLogRepository contract
+func RunAccessLogRepository(t *testing.T, newRepo func(t *testing.T) output.AccessLogRepository) {
+	t.Run("CreateAndFindByID", func(t *testing.T) {
+		repo := newRepo(t)
+		log := newAccessLog("p1", "alice", "read", domain.OutcomeSuccess, now())
+		log.Metadata = map[string]any{"ip": "10.0.0.1"}
+		mustNoError(t, repo.Create(ctx(), log))
+
+		got, err := repo.FindByID(ctx(), log.ID)
+		mustNoError(t, err)
+		if got.ProjectID != "p1" || got.ActorID != "alice" || got.ActorType != domain.ActorUser || got.Action != "read" ||
+			got.ResourceType != log.ResourceType || got.ResourceID != log.ResourceID || got.Outcome != domain.OutcomeSuccess ||
+			got.Message != log.Message || got.Metadata["ip"] != "10.0.0.1" || !got.Timestamp.Equal(log.Timestamp) {
+			t.Fatalf("access log mismatch: got %+v", got)
+		}
+	})
+
+	t.Run("FindByIDNotFound", func(t *testing.T) {
+		repo := newRepo(t)
+		_, err := repo.FindByID(ctx(), newID())
+		mustBeError(t, err, domain.ErrAccessLogNotFound)
+	})
+
+	t.Run("FilterCountAndDelete", func(t *testing.T) {
+		repo := newRepo(t)
+		base := now().Add(-time.Hour)
+
+		logs := []*domain.AccessLog{
+			newAccessLog("p1", "alice", "read", domain.OutcomeSuccess, base.Add(1*time.Minute)),
+			newAccessLog("p1", "bob", "delete", domain.OutcomeDenied, base.Add(2*time.Minute)),
+			newAccessLog("p1", "alice", "write", domain.OutcomeSuccess, base.Add(3*time.Minute)),
+			newAccessLog("p2", "alice", "read", domain.OutcomeError, base.Add(4*time.Minute)),
+		}
+		logs[1].Metadata = map[string]any{"reason": "role"}
+		logs[2].ActorType = domain.ActorAPIKey
+		mustNoError(t, repo.CreateMany(ctx(), logs[:3]))
+		mustNoError(t, repo.Create(ctx(), logs[3]))
+
+		denied := domain.OutcomeDenied
+		apiKey := domain.ActorAPIKey
+		from := base.Add(2 * time.Minute)
+
+		cases := []struct {
+			name   string
+			filter domain.AccessLogFilter
+			want   []*domain.AccessLog
+		}{
+			{"project newest first", domain.AccessLogFilter{ProjectID: "p1"}, []*domain.AccessLog{logs[2], logs[1], logs[0]}},
+			{"actor", domain.AccessLogFilter{ActorID: "alice"}, []*domain.AccessLog{logs[3], logs[2], logs[0]}},
+			{"actor type", domain.AccessLogFilter{ActorType: &apiKey}, []*domain.AccessLog{logs[2]}},
+			{"action", domain.AccessLogFilter{ProjectID: "p1", Action: "read"}, []*domain.AccessLog{logs[0]}},
+			{"outcome", domain.AccessLogFilter{Outcome: &denied}, []*domain.AccessLog{logs[1]}},
+			{"metadata", domain.AccessLogFilter{Metadata: map[string]any{"reason": "role"}}, []*domain.AccessLog{logs[1]}},
+			{"search message", domain.AccessLogFilter{Search: "BOB performed"}, []*domain.AccessLog{logs[1]}},
+			{"from date", domain.AccessLogFilter{ProjectID: "p1", FromDate: &from}, []*domain.AccessLog{logs[2], logs[1]}},
+			{"pagination", domain.AccessLogFilter{SharedFilter: domain.SharedFilter{Limit: 2, Offset: 1}}, []*domain.AccessLog{logs[2], logs[1]}},
+		}
+
+		for _, tc := range cases {
+			t.Run(tc.name, func(t *testing.T) {
+				got, err := repo.FindByFilter(ctx(), tc.filter)
+				mustNoError(t, err)
+
+				gotIDs, wantIDs := accessLogIDs(got), accessLogIDs(tc.want)
+				if len(gotIDs) != len(wantIDs) {
+					t.Fatalf("want %d access logs, got %d", len(wantIDs), len(gotIDs))
+				}
+				for i := range wantIDs {
+					if gotIDs[i] != wantIDs[i] {
+						t.Fatalf("access log %d: want %s, got %s", i, wantIDs[i], gotIDs[i])
+					}
+				}
+			})
+		}
+
+		count, err := repo.CountByFilter(ctx(), domain.AccessLogFilter{ActorID: "alice"})
+		mustNoError(t, err)
+		if count != 3 {
+			t.Fatalf("CountByFilter: want 3, got %d", count)
+		}
+
+		deleted, err := repo.DeleteByFilter(ctx(), domain.AccessLogFilter{ProjectID: "p1", ActorID: "alice"})
+		mustNoError(t, err)
+		if deleted != 2 {
+			t.Fatalf("DeleteByFilter: want 2, got %d", deleted)
+		}
+
+		mustNoError(t, repo.DeleteByID(ctx(), logs[1].ID))
+		_, err = repo.FindByID(ctx(), logs[1].ID)
+		mustBeError(t, err, domain.ErrAccessLogNotFound)
+
+		remaining, err := repo.CountByFilter(ctx(), domain.AccessLogFilter{})
+		mustNoError(t, err)
+		if remaining != 1 {
+			t.Fatalf("want 1 access log left, got %d", remaining)
+		}
+	})
+
+	t.Run("DeleteAndMeasureOlderThan", func(t *testing.T) {
+		repo := newRepo(t)
+		at := func(projectID string, ts time.Time) *domain.AccessLog {
+			return newAccessLog(projectID, "alice", "read", domain.OutcomeSuccess, ts)
+		}
+		old, recent := now().Add(-48*time.Hour), now()
+		expired := []*domain.AccessLog{at("p1", old), at("p1", old), at("p1", old)}
+		kept := []*domain.AccessLog{at("p1", recent), at("p2", old)}
+		for _, item := range append(expired, kept...) {
+			mustNoError(t, repo.Create(ctx(), item))
+		}
+		before := now().Add(-24 * time.Hour)
+
+		volume, err := repo.VolumeOlderThan(ctx(), "p1", before)
+		mustNoError(t, err)
+		if volume.Documents != 3 || volume.Bytes <= 0 {
+			t.Fatalf("expected 3 access logs with their size, got %+v", volume)
+		}
+
+		deleted, err := repo.DeleteOlderThan(ctx(), "p1", before, 2)
+		mustNoError(t, err)
+		if deleted != 2 {
+			t.Fatalf("expected a batch of 2 deleted, got %d", deleted)
+		}
+		deleted, err = repo.DeleteOlderThan(ctx(), "p1", before, 2)
+		mustNoError(t, err)
+		if deleted != 1 {
+			t.Fatalf("expected the last 1 deleted, got %d", deleted)
+		}
+		volume, err = repo.VolumeOlderThan(ctx(), "p1", before)
+		mustNoError(t, err)
+		if volume != (domain.DataVolume{}) {
+			t.Fatalf("expected nothing left to purge, got %+v", volume)
+		}
+
+		for _, item := range kept {
+			_, err := repo.FindByID(ctx(), item.ID)
+			mustNoError(t, err)
+		}
+	})
+}
@@ -0,0 +1,172 @@
+package contract
+
+import (
+	"testing"
+	"time"
+
+	"github.com/spidey52/api-logs/internal/domain"
+	"github.com/spidey52/api-logs/internal/ports/output"
+)
+
+func newAccount(username string, createdAt time.Time) *domain.Account {
+	return &domain.Account{
+		ID:           newID(),
+		Username:     username,
+		PasswordHash: "$2a$10$contracttesthash",
+		IsActive:     true,
+		CreatedAt:    createdAt,
+		UpdatedAt:    createdAt,
+	}
+}
+
+func newSession(accountID, tokenHash string, createdAt, expiresAt time.Time) *domain.Session {
+	return &domain.Session{
+		ID:        newID(),
+		AccountID: accountID,
+		TokenHash: tokenHash,
+		CreatedAt: createdAt,
+		ExpiresAt: expiresAt,
+	}
+}
+
+// RunAccountRepository runs the AccountRepository contract
+func RunAccountRepository(t *testing.T, newRepo func(t *testing.T) output.AccountRepository) {
+	t.Run("CreateAndFind", func(t *testing.T) {
+		repo := newRepo(t)
+		account := newAccount("alice", now())
+		mustNoError(t, repo.Create(ctx(), account))
+
+		byID, err := repo.FindByID(ctx(), account.ID)
+		mustNoError(t, err)
+		if byID.Username != "alice" || byID.PasswordHash != account.PasswordHash || !byID.IsActive || byID.IsAdmin ||
+			!byID.CreatedAt.Equal(account.CreatedAt) || byID.LastLoginAt != nil {
+			t.Fatalf("account mismatch: got %+v", byID)
+		}
+
+		byUsername, err := repo.FindByUsername(ctx(), "alice")
+		mustNoError(t, err)
+		if byUsername.ID != account.ID {
+			t.Fatalf("FindByUsername returned %s, want %s", byUsername.ID, account.ID)
+		}
+	})
+
+	t.Run("NotFound", func(t *testing.T) {
+		repo := newRepo(t)
+
+		_, err := repo.FindByID(ctx(), newID())
+		mustBeError(t, err, domain.ErrAccountNotFound)
+
+		_, err = repo.FindByUsername(ctx(), "nobody")
+		mustBeError(t, err, domain.ErrAccountNotFound)
+
+		mustBeError(t, repo.Update(ctx(), newAccount("nobody", now())), domain.ErrAccountNotFound)
+	})
+
+	t.Run("UsernameUnique", func(t *testing.T) {
+		repo := newRepo(t)
+		mustNoError(t, repo.Create(ctx(), newAccount("alice", now())))
+		mustBeError(t, repo.Create(ctx(), newAccount("alice", now())), domain.ErrDuplicateUsername)
+	})
+
+	t.Run("Update", func(t *testing.T) {
+		repo := newRepo(t)
+		account := newAccount("alice", now())
+		mustNoError(t, repo.Create(ctx(), account))
+
+		lastLogin := now().Add(time.Minute)
+		account.PasswordHash = "$2a$10$rotated"
+		account.IsActive = false
+		account.IsAdmin = true
+		account.UpdatedAt = lastLogin
+		account.LastLoginAt = &lastLogin
+		mustNoError(t, repo.Update(ctx(), account))
+
+		got, err := repo.FindByID(ctx(), account.ID)
+		mustNoError(t, err)
+		if got.PasswordHash != "$2a$10$rotated" || got.IsActive || !got.IsAdmin || got.LastLoginAt == nil ||
+			!got.LastLoginAt.Equal(lastLogin) || !got.UpdatedAt.Equal(lastLogin) {
+			t.Fatalf("update not applied: got %+v", got)
+		}
+	})
+
+	t.Run("ListOrderedByUsername", func(t *testing.T) {
+		repo := newRepo(t)
+		for _, username := range []string{"carol", "alice", "bob"} {
+			mustNoError(t, repo.Create(ctx(), newAccount(username, now())))
+		}
+
+		accounts, err := repo.List(ctx())
+		mustNoError(t, err)
+		if len(accounts) != 3 {
+			t.Fatalf("expected 3 accounts, got %d", len(accounts))
+		}
+		for i, want := range []string{"alice", "bob", "carol"} {
+			if accounts[i].Username != want {
+				t.Fatalf("account %d: got %s, want %s", i, accounts[i].Username, want)
+			}
+		}
+	})
+}
+
+// RunSessionRepository runs the SessionRepository contract
+func RunSessionRepository(t *testing.T, newRepo func(t *testing.T) output.SessionRepository) {
+	t.Run("CreateAndFind", func(t *testing.T) {
+		repo := newRepo(t)
+		base := now()
+		session := newSession("a1", "hash-1", base, base.Add(time.Hour))
+		mustNoError(t, repo.Create(ctx(), session))
+
+		got, err := repo.FindByTokenHash(ctx(), "hash-1")
+		mustNoError(t, err)
+		if got.ID != session.ID || got.AccountID != "a1" || !got.ExpiresAt.Equal(session.ExpiresAt) {
+			t.Fatalf("session mismatch: got %+v", got)
+		}
+
+		_, err = repo.FindByTokenHash(ctx(), "missing")
+		mustBeError(t, err, domain.ErrSessionNotFound)
+	})
+
+	t.Run("Delete", func(t *testing.T) {
+		repo := newRepo(t)
+		base := now()
+		session := newSession("a1", "hash-1", base, base.Add(time.Hour))
+		mustNoError(t, repo.Create(ctx(), session))
+
+		mustNoError(t, repo.Delete(ctx(), session.ID))
+		_, err := repo.FindByTokenHash(ctx(), "hash-1")
+		mustBeError(t, err, domain.ErrSessionNotFound)
+	})
+
+	t.Run("DeleteByAccountID", func(t *testing.T) {
+		repo := newRepo(t)
+		base := now()
+		mustNoError(t, repo.Create(ctx(), newSession("a1", "hash-1", base, base.Add(time.Hour))))
+		mustNoError(t, repo.Create(ctx(), newSession("a1", "hash-2", base, base.Add(time.Hour))))
+		mustNoError(t, repo.Create(ctx(), newSession("a2", "hash-3", base, base.Add(time.Hour))))
+
+		mustNoError(t, repo.DeleteByAccountID(ctx(), "a1"))
+
+		for _, hash := range []string{"hash-1", "hash-2"} {
+			_, err := repo.FindByTokenHash(ctx(), hash)
+			mustBeError(t, err, domain.ErrSessionNotFound)
+		}
+		_, err := repo.FindByTokenHash(ctx(), "hash-3")
+		mustNoError(t, err)
+	})
+
+	t.Run("DeleteExpired", func(t *testing.T) {
+		repo := newRepo(t)
+		base := now()
+		mustNoError(t, repo.Create(ctx(), newSession("a1", "expired", base.Add(-2*time.Hour), base.Add(-time.Hour))))
+		mustNoError(t, repo.Create(ctx(), newSession("a1", "live", base, base.Add(time.Hour))))
+
+		deleted, err := repo.DeleteExpired(ctx(), base)
+		mustNoError(t, err)
+		if deleted != 1 {
+			t.Fatalf("expected 1 expired session deleted, got %d", deleted)
+		}
+
+		_, err = repo.FindByTokenHash(ctx(), "live")
+		mustNoError(t, err)
+	})
+}
@@ -0,0 +1,223 @@
+package contract
+
+import (
+	"testing"
+	"time"
+
+	"github.com/spidey52/api-logs/internal/domain"
+	"github.com/spidey52/api-logs/internal/ports/output"
+)
+
+func newAPIKey(projectID string, createdAt time.Time, scopes ...domain.APIKeyScope) *domain.APIKey {
+	id := newID()
+	return &domain.APIKey{
+		ID:        id,
+		ProjectID: projectID,
+		Name:      "key-" + id,
+		KeyHash:   "secret_" + id,
+		Prefix:    domain.KeyPrefix("secret_" + id),
+		Scopes:    scopes,
+		CreatedAt: createdAt,
+	}
+}
+
+// RunAPIKeyRepository runs the APIKeyRepository contract
+func RunAPIKeyRepository(t *testing.T, newRepo func(t *testing.T) output.APIKeyRepository) {
+	t.Run("CreateAndFind", func(t *testing.T) {
+		repo := newRepo(t)
+		key := newAPIKey("p1", now(), domain.ScopeIngest, domain.ScopeStats)
+		expires := now().Add(time.Hour)
+		key.ExpiresAt = &expires
+		mustNoError(t, repo.Create(ctx(), key))
+
+		byID, err := repo.FindByID(ctx(), key.ID)
+		mustNoError(t, err)
+		if byID.KeyHash != key.KeyHash || byID.Prefix != key.Prefix || byID.Name != key.Name || byID.ProjectID != "p1" {
+			t.Fatalf("key mismatch: got %+v", byID)
+		}
+		if len(byID.Scopes) != 2 || !byID.HasScope(domain.ScopeIngest) || !byID.HasScope(domain.ScopeStats) {
+			t.Fatalf("scopes not round-tripped: %v", byID.Scopes)
+		}
+		if byID.ExpiresAt == nil || !byID.ExpiresAt.Equal(expires) {
+			t.Fatalf("expires_at not round-tripped: %v", byID.ExpiresAt)
+		}
+		if byID.LastUsedAt != nil || byID.RevokedAt != nil {
+			t.Fatalf("new key should be unused and active: %+v", byID)
+		}
+
+		byKey, err := repo.FindByKey(ctx(), key.KeyHash)
+		mustNoError(t, err)
+		if byKey.ID != key.ID {
+			t.Fatalf("FindByKey returned %s, want %s", byKey.ID, key.ID)
+		}
+
+		_, err = repo.FindByID(ctx(), "missing")
+		mustBeError(t, err, domain.ErrAPIKeyNotFound)
+		_, err = repo.FindByKey(ctx(), "missing")
+		mustBeError(t, err, domain.ErrAPIKeyNotFound)
+	})
+
+	t.Run("UniqueKey", func(t *testing.T) {
+		repo := newRepo(t)
+		key := newAPIKey("p1", now(), domain.ScopeRead)
+		mustNoError(t, repo.Create(ctx(), key))
+
+		dup := newAPIKey("p2", now(), domain.ScopeRead)
+		dup.KeyHash = key.KeyHash
+		mustBeError(t, repo.Create(ctx(), dup), domain.ErrDuplicateAPIKey)
+	})
+
+	t.Run("ListNewestFirst", func(t *testing.T) {
+		repo := newRepo(t)
+		base := now()
+		older := newAPIKey("p1", base, domain.ScopeRead)
+		newer := newAPIKey("p1", base.Add(time.Minute), domain.ScopeIngest)
+		other := newAPIKey("p2", base.Add(2*time.Minute), domain.ScopeIngest)
+		for _, key := range []*domain.APIKey{older, newer, other} {
+			mustNoError(t, repo.Create(ctx(), key))
+		}
+
+		keys, err := repo.ListByProject(ctx(), "p1")
+		mustNoError(t, err)
+		if len(keys) != 2 || keys[0].ID != newer.ID || keys[1].ID != older.ID {
+			t.Fatalf("unexpected order: %+v", keys)
+		}
+
+		keys, err = repo.ListByProject(ctx(), "missing")
+		mustNoError(t, err)
+		if len(keys) != 0 {
+			t.Fatalf("expected no keys, got %d", len(keys))
+		}
+	})
+
+	t.Run("RevokeKeepsFirstTimestamp", func(t *testing.T) {
+		repo := newRepo(t)
+		key := newAPIKey("p1", now(), domain.ScopeRead)
+		mustNoError(t, repo.Create(ctx(), key))
+
+		revokedAt := now().Add(time.Minute)
+		mustNoError(t, repo.Revoke(ctx(), key.ID, revokedAt))
+		mustNoError(t, repo.Revoke(ctx(), key.ID, revokedAt.Add(time.Hour)))
+
+		got, err := repo.FindByID(ctx(), key.ID)
+		mustNoError(t, err)
+		if got.RevokedAt == nil || !got.RevokedAt.Equal(revokedAt) {
+			t.Fatalf("revoked_at = %v, want %v", got.RevokedAt, revokedAt)
+		}
+
+		mustBeError(t, repo.Revoke(ctx(), "missing", revokedAt), domain.ErrAPIKeyNotFound)
+	})
+
+	t.Run("RevokeExpired", func(t *testing.T) {
+		repo := newRepo(t)
+		base := now()
+		expired := newAPIKey("p1", base, domain.ScopeRead)
+		expiredAt := base.Add(-time.Minute)
+		expired.ExpiresAt = &expiredAt
+		pending := newAPIKey("p1", base, domain.ScopeRead)
+		pendingAt := base.Add(time.Hour)
+		pending.ExpiresAt = &pendingAt
+		forever := newAPIKey("p1", base, domain.ScopeRead)
+		for _, key := range []*domain.APIKey{expired, pending, forever} {
+			mustNoError(t, repo.Create(ctx(), key))
+		}
+
+		revoked, err := repo.RevokeExpired(ctx(), base)
+		mustNoError(t, err)
+		if revoked != 1 {
+			t.Fatalf("revoked %d keys, want 1", revoked)
+		}
+
+		got, err := repo.FindByID(ctx(), expired.ID)
+		mustNoError(t, err)
+		if got.RevokedAt == nil || !got.RevokedAt.Equal(expiredAt) {
+			t.Fatalf("revoked_at = %v, want %v", got.RevokedAt, expiredAt)
+		}
+		for _, key := range []*domain.APIKey{pending, forever} {
+			got, err := repo.FindByID(ctx(), key.ID)
+			mustNoError(t, err)
+			if got.RevokedAt != nil {
+				t.Fatalf("key %s revoked early", key.ID)
+			}
+		}
+
+		revoked, err = repo.RevokeExpired(ctx(), base)
+		mustNoError(t, err)
+		if revoked != 0 {
+			t.Fatalf("second run revoked %d keys, want 0", revoked)
+		}
+	})
+
+	t.Run("TouchLastUsed", func(t *testing.T) {
+		repo := newRepo(t)
+		key := newAPIKey("p1", now(), domain.ScopeRead)
+		mustNoError(t, repo.Create(ctx(), key))
+
+		usedAt := now().Add(time.Minute)
+		mustNoError(t, repo.TouchLastUsed(ctx(), key.ID, usedAt))
+
+		got, err := repo.FindByID(ctx(), key.ID)
+		mustNoError(t, err)
+		if got.LastUsedAt == nil || !got.LastUsedAt.Equal(usedAt) {
+			t.Fatalf("last_used_at = %v, want %v", got.LastUsedAt, usedAt)
+		}
+	})
+
+	t.Run("PlaintextKeyNotStored", func(t *testing.T) {
+		repo := newRepo(t)
+		key := newAPIKey("p1", now(), domain.ScopeIngest)
+		key.Key = "dev_plain"
+		mustNoError(t, repo.Create(ctx(), key))
+
+		got, err := repo.FindByID(ctx(), key.ID)
+		mustNoError(t, err)
+		if got.Key != "" {
+			t.Fatalf("plaintext key stored: %q", got.Key)
+		}
+		_, err = repo.FindByKey(ctx(), "dev_plain")
+		mustBeError(t, err, domain.ErrAPIKeyNotFound)
+	})
+
+	t.Run("HashPlaintextKeys", func(t *testing.T) {
+		repo := newRepo(t)
+		legacy := newAPIKey("p1", now(), domain.ScopeIngest)
+		legacy.KeyHash = "dev_0123456789"
+		legacy.Prefix = ""
+		hashed := newAPIKey("p1", now(), domain.ScopeIngest)
+		mustNoError(t, repo.Create(ctx(), legacy))
+		mustNoError(t, repo.Create(ctx(), hashed))
+
+		hash := func(key string) string { return "hashed:" + key }
+		converted, err := repo.HashPlaintextKeys(ctx(), hash)
+		mustNoError(t, err)
+		if converted != 1 {
+			t.Fatalf("converted %d keys, want 1", converted)
+		}
+
+		got, err := repo.FindByKey(ctx(), "hashed:dev_0123456789")
+		mustNoError(t, err)
+		if got.ID != legacy.ID || got.Prefix != "dev_0123" {
+			t.Fatalf("legacy key not converted: got %+v", got)
+		}
+
+		converted, err = repo.HashPlaintextKeys(ctx(), hash)
+		mustNoError(t, err)
+		if converted != 0 {
+			t.Fatalf("second run converted %d keys, want 0", converted)
+		}
+	})
+
+	t.Run("DeleteByProject", func(t *testing.T) {
+		repo := newRepo(t)
+		gone := newAPIKey("p1", now(), domain.ScopeRead)
+		kept := newAPIKey("p2", now(), domain.ScopeRead)
+		mustNoError(t, repo.Create(ctx(), gone))
+		mustNoError(t, repo.Create(ctx(), kept))
+
+		mustNoError(t, repo.DeleteByProject(ctx(), "p1"))
+		_, err := repo.FindByID(ctx(), gone.ID)
+		mustBeError(t, err, domain.ErrAPIKeyNotFound)
+		_, err = repo.FindByID(ctx(), kept.ID)
+		mustNoError(t, err)
+	})
+}
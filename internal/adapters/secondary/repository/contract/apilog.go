@@ -0,0 +1,440 @@
+package contract
+
+import (
+	"sort"
+	"testing"
+	"time"
+
+	"github.com/spidey52/api-logs/internal/domain"
+	"github.com/spidey52/api-logs/internal/ports/output"
+)
+
+type logOption func(*domain.APILog)
+
+func newLog(projectID string, env domain.Environment, ts time.Time, opts ...logOption) *domain.APILog {
+	log := &domain.APILog{
+		ID:          newID(),
+		ProjectID:   projectID,
+		Environment: env,
+		Method:      domain.MethodGET,
+		Path:        "/api/items",
+		Params:      map[string]string{"id": "1"},
+		QueryParams: map[string]string{"page": "2"},
+		StatusCode:  200,
+		IPAddress:   "10.0.0.1",
+		UserAgent:   "contract-test",
+		Timestamp:   ts,
+	}
+	for _, opt := range opts {
+		opt(log)
+	}
+	return log
+}
+
+func withPath(method domain.HTTPMethod, path string) logOption {
+	return func(l *domain.APILog) {
+		l.Method = method
+		l.Path = path
+	}
+}
+
+func withStatus(code int) logOption {
+	return func(l *domain.APILog) { l.StatusCode = code }
+}
+
+func withResponseTime(ms int64) logOption {
+	return func(l *domain.APILog) { l.ResponseTime = ms }
+}
+
+func withUser(userID string) logOption {
+	return func(l *domain.APILog) { l.UserID = &userID }
+}
+
+func withUserAgent(ua string) logOption {
+	return func(l *domain.APILog) { l.UserAgent = ua }
+}
+
+func intPtr(v int) *int { return &v }
+
+func logIDs(logs []*domain.APILog) []string {
+	ids := make([]string, len(logs))
+	for i, log := range logs {
+		ids[i] = log.ID
+	}
+	return ids
+}
+
+// RunAPILogRepository runs the APILogRepository contract. newRepo must return
+// a repository backed by empty storage on every call.
+func RunAPILogRepository(t *testing.T, newRepo func(t *testing.T) output.APILogRepository) {
+	t.Run("CreateAndFindByID", func(t *testing.T) {
+		repo := newRepo(t)
+		log := newLog("p1", domain.EnvironmentDev, now(), withStatus(201), withResponseTime(42), withUser("u1"))
+		log.ContentLength = 512
+		log.ErrorMessage = "partial"
+
+		mustNoError(t, repo.Create(ctx(), log))
+
+		got, err := repo.FindByID(ctx(), log.ID)
+		mustNoError(t, err)
+
+		if got.ProjectID != log.ProjectID || got.Environment != log.Environment || got.Method != log.Method || got.Path != log.Path {
+			t.Fatalf("identity fields mismatch: got %+v", got)
+		}
+		if got.StatusCode != 201 || got.ResponseTime != 42 || got.ContentLength != 512 || got.ErrorMessage != "partial" {
+			t.Fatalf("numeric fields mismatch: got %+v", got)
+		}
+		if got.IPAddress != log.IPAddress || got.UserAgent != log.UserAgent {
+			t.Fatalf("client fields mismatch: got %+v", got)
+		}
+		if got.UserID == nil || *got.UserID != "u1" {
+			t.Fatalf("expected user_id u1, got %v", got.UserID)
+		}
+		if got.Params["id"] != "1" || got.QueryParams["page"] != "2" {
+			t.Fatalf("params mismatch: %v %v", got.Params, got.QueryParams)
+		}
+		if !got.Timestamp.Equal(log.Timestamp) {
+			t.Fatalf("timestamp mismatch: want %v, got %v", log.Timestamp, got.Timestamp)
+		}
+	})
+
+	t.Run("CreateMany", func(t *testing.T) {
+		repo := newRepo(t)
+		base := now()
+		logs := []*domain.APILog{
+			newLog("p1", domain.EnvironmentDev, base, withStatus(201), withUser("u1")),
+			newLog("p1", domain.EnvironmentDev, base.Add(time.Second), withPath(domain.MethodPOST, "/api/orders")),
+			newLog("p1", domain.EnvironmentDev, base.Add(2*time.Second), withStatus(500)),
+		}
+		mustNoError(t, repo.CreateMany(ctx(), logs))
+		mustNoError(t, repo.CreateMany(ctx(), nil))
+
+		for _, log := range logs {
+			got, err := repo.FindByID(ctx(), log.ID)
+			mustNoError(t, err)
+			if got.Path != log.Path || got.StatusCode != log.StatusCode || !got.Timestamp.Equal(log.Timestamp) {
+				t.Fatalf("log mismatch: want %+v, got %+v", log, got)
+			}
+			if got.Params["id"] != "1" || got.QueryParams["page"] != "2" {
+				t.Fatalf("params mismatch: %v %v", got.Params, got.QueryParams)
+			}
+		}
+
+		got, err := repo.FindByID(ctx(), logs[0].ID)
+		mustNoError(t, err)
+		if got.UserID == nil || *got.UserID != "u1" {
+			t.Fatalf("expected user_id u1, got %v", got.UserID)
+		}
+
+		count, err := repo.CountByProject(ctx(), "p1", domain.EnvironmentDev)
+		mustNoError(t, err)
+		if count != 3 {
+			t.Fatalf("expected 3 logs, got %d", count)
+		}
+	})
+
+	t.Run("CreateManySkipsExisting", func(t *testing.T) {
+		repo := newRepo(t)
+		existing := newLog("p1", domain.EnvironmentDev, now(), withStatus(201))
+		mustNoError(t, repo.Create(ctx(), existing))
+
+		retried := *existing
+		retried.StatusCode = 500
+		added := newLog("p1", domain.EnvironmentDev, now())
+		mustNoError(t, repo.CreateMany(ctx(), []*domain.APILog{&retried, added}))
+
+		got, err := repo.FindByID(ctx(), existing.ID)
+		mustNoError(t, err)
+		if got.StatusCode != 201 {
+			t.Fatalf("expected the existing log to be kept, got status %d", got.StatusCode)
+		}
+		_, err = repo.FindByID(ctx(), added.ID)
+		mustNoError(t, err)
+	})
+
+	t.Run("FindByIDNotFound", func(t *testing.T) {
+		repo := newRepo(t)
+		_, err := repo.FindByID(ctx(), newID())
+		mustBeError(t, err, domain.ErrLogNotFound)
+	})
+
+	t.Run("Delete", func(t *testing.T) {
+		repo := newRepo(t)
+		log := newLog("p1", domain.EnvironmentDev, now())
+		mustNoError(t, repo.Create(ctx(), log))
+
+		mustNoError(t, repo.Delete(ctx(), log.ID))
+		_, err := repo.FindByID(ctx(), log.ID)
+		mustBeError(t, err, domain.ErrLogNotFound)
+
+		mustBeError(t, repo.Delete(ctx(), log.ID), domain.ErrLogNotFound)
+	})
+
+	t.Run("FindByFilter", func(t *testing.T) {
+		repo := newRepo(t)
+		base := now().Add(-time.Hour)
+
+		logs := []*domain.APILog{
+			newLog("p1", domain.EnvironmentDev, base.Add(1*time.Minute), withPath(domain.MethodGET, "/api/users"), withStatus(200), withUser("u1")),
+			newLog("p1", domain.EnvironmentDev, base.Add(2*time.Minute), withPath(domain.MethodPOST, "/api/users"), withStatus(201)),
+			newLog("p1", domain.EnvironmentDev, base.Add(3*time.Minute), withPath(domain.MethodGET, "/api/orders"), withStatus(404), withUserAgent("Mozilla/5.0")),
+			newLog("p1", domain.EnvironmentDev, base.Add(4*time.Minute), withPath(domain.MethodDELETE, "/API/Users/7"), withStatus(500), withUser("u1")),
+			newLog("p1", domain.EnvironmentProduction, base.Add(5*time.Minute), withPath(domain.MethodGET, "/api/users"), withStatus(200)),
+			newLog("p2", domain.EnvironmentDev, base.Add(6*time.Minute), withPath(domain.MethodGET, "/api/users"), withStatus(200)),
+		}
+		for _, log := range logs {
+			mustNoError(t, repo.Create(ctx(), log))
+		}
+
+		from := base.Add(2 * time.Minute)
+		to := base.Add(3 * time.Minute)
+
+		cases := []struct {
+			name   string
+			filter domain.LogFilter
+			want   []*domain.APILog
+		}{
+			{"project", domain.LogFilter{ProjectID: "p1"}, []*domain.APILog{logs[4], logs[3], logs[2], logs[1], logs[0]}},
+			{"environment", domain.LogFilter{ProjectID: "p1", Environment: domain.EnvironmentDev}, []*domain.APILog{logs[3], logs[2], logs[1], logs[0]}},
+			{"method", domain.LogFilter{ProjectID: "p1", Environment: domain.EnvironmentDev, Method: domain.MethodGET}, []*domain.APILog{logs[2], logs[0]}},
+			{"exact status", domain.LogFilter{ProjectID: "p1", StatusCode: intPtr(404)}, []*domain.APILog{logs[2]}},
+			{"status range", domain.LogFilter{ProjectID: "p1", StatusCodeMin: intPtr(400), StatusCodeMax: intPtr(599)}, []*domain.APILog{logs[3], logs[2]}},
+			{"exact status wins over range", domain.LogFilter{ProjectID: "p1", StatusCode: intPtr(201), StatusCodeMin: intPtr(400)}, []*domain.APILog{logs[1]}},
+			{"path is case-insensitive substring", domain.LogFilter{ProjectID: "p1", Environment: domain.EnvironmentDev, Path: "users"}, []*domain.APILog{logs[3], logs[1], logs[0]}},
+			{"search matches user agent", domain.LogFilter{ProjectID: "p1", Search: "mozilla"}, []*domain.APILog{logs[2]}},
+			{"search matches path", domain.LogFilter{ProjectID: "p1", Environment: domain.EnvironmentDev, Search: "ORDERS"}, []*domain.APILog{logs[2]}},
+			{"date range is inclusive", domain.LogFilter{ProjectID: "p1", FromDate: &from, ToDate: &to}, []*domain.APILog{logs[2], logs[1]}},
+			{"user", domain.LogFilter{ProjectID: "p1", UserID: "u1"}, []*domain.APILog{logs[3], logs[0]}},
+			{"no match", domain.LogFilter{ProjectID: "p3"}, []*domain.APILog{}},
+		}
+
+		for _, tc := range cases {
+			t.Run(tc.name, func(t *testing.T) {
+				got, err := repo.FindByFilter(ctx(), tc.filter)
+				mustNoError(t, err)
+				assertLogIDs(t, got, tc.want)
+
+				count, err := repo.CountByFilter(ctx(), tc.filter)
+				mustNoError(t, err)
+				if count != int64(len(tc.want)) {
+					t.Fatalf("CountByFilter: want %d, got %d", len(tc.want), count)
+				}
+			})
+		}
+	})
+
+	t.Run("FindByFilterPagination", func(t *testing.T) {
+		repo := newRepo(t)
+		base := now().Add(-time.Hour)
+
+		var logs []*domain.APILog
+		for i := 0; i < 5; i++ {
+			log := newLog("p1", domain.EnvironmentDev, base.Add(time.Duration(i)*time.Minute))
+			logs = append(logs, log)
+			mustNoError(t, repo.Create(ctx(), log))
+		}
+
+		page, err := repo.FindByFilter(ctx(), domain.LogFilter{ProjectID: "p1", SharedFilter: domain.SharedFilter{Limit: 2, Offset: 1}})
+		mustNoError(t, err)
+		assertLogIDs(t, page, []*domain.APILog{logs[3], logs[2]})
+
+		last, err := repo.FindByFilter(ctx(), domain.LogFilter{ProjectID: "p1", SharedFilter: domain.SharedFilter{Limit: 2, Offset: 4}})
+		mustNoError(t, err)
+		assertLogIDs(t, last, []*domain.APILog{logs[0]})
+
+		count, err := repo.CountByFilter(ctx(), domain.LogFilter{ProjectID: "p1", SharedFilter: domain.SharedFilter{Limit: 2}})
+		mustNoError(t, err)
+		if count != 5 {
+			t.Fatalf("CountByFilter must ignore pagination: want 5, got %d", count)
+		}
+	})
+
+	t.Run("Stats", func(t *testing.T) {
+		repo := newRepo(t)
+		ts := now().Add(-time.Second)
+		old := now().AddDate(0, 0, -3)
+
+		logs := []*domain.APILog{
+			newLog("p1", domain.EnvironmentDev, ts, withPath(domain.MethodGET, "/a"), withStatus(200), withResponseTime(10)),
+			newLog("p1", domain.EnvironmentDev, ts, withPath(domain.MethodGET, "/a"), withStatus(200), withResponseTime(20)),
+			newLog("p1", domain.EnvironmentDev, ts, withPath(domain.MethodGET, "/a"), withStatus(500), withResponseTime(30)),
+			newLog("p1", domain.EnvironmentDev, ts, withPath(domain.MethodPOST, "/b"), withStatus(201), withResponseTime(40)),
+			// Outside the stats window: other environment, other project, before today
+			newLog("p1", domain.EnvironmentProduction, ts, withPath(domain.MethodGET, "/prod"), withResponseTime(1000)),
+			newLog("p2", domain.EnvironmentDev, ts, withPath(domain.MethodGET, "/other"), withResponseTime(1000)),
+			newLog("p1", domain.EnvironmentDev, old, withPath(domain.MethodGET, "/old"), withResponseTime(1000)),
+		}
+		for _, log := range logs {
+			mustNoError(t, repo.Create(ctx(), log))
+		}
+
+		count, err := repo.CountByProject(ctx(), "p1", domain.EnvironmentDev)
+		mustNoError(t, err)
+		if count != 4 {
+			t.Fatalf("CountByProject: want 4, got %d", count)
+		}
+
+		statusCodes, err := repo.GetStatusCodeDistribution(ctx(), "p1", domain.EnvironmentDev)
+		mustNoError(t, err)
+		if len(statusCodes) != 3 || statusCodes[200] != 2 || statusCodes[500] != 1 || statusCodes[201] != 1 {
+			t.Fatalf("unexpected status code distribution: %v", statusCodes)
+		}
+
+		methods, err := repo.GetMethodDistribution(ctx(), "p1", domain.EnvironmentDev)
+		mustNoError(t, err)
+		if len(methods) != 2 || methods["GET"] != 3 || methods["POST"] != 1 {
+			t.Fatalf("unexpected method distribution: %v", methods)
+		}
+
+		avg, err := repo.GetAverageResponseTime(ctx(), "p1", domain.EnvironmentDev)
+		mustNoError(t, err)
+		if avg != 25 {
+			t.Fatalf("GetAverageResponseTime: want 25, got %v", avg)
+		}
+
+		series, err := repo.GetTimeSeriesStats(ctx(), "p1", domain.EnvironmentDev)
+		mustNoError(t, err)
+		var total int64
+		for _, point := range series {
+			if _, ok := point["timestamp"]; !ok {
+				t.Fatalf("time series point missing timestamp: %v", point)
+			}
+			total += toInt64(t, point["count"])
+		}
+		if total != 4 {
+			t.Fatalf("time series counts: want 4, got %d", total)
+		}
+
+		top, err := repo.GetTopEndpoints(ctx(), "p1", domain.EnvironmentDev, 1)
+		mustNoError(t, err)
+		if len(top) != 1 {
+			t.Fatalf("GetTopEndpoints: want 1 entry, got %d", len(top))
+		}
+		if top[0]["_id"] != "/a" || toInt64(t, top[0]["count"]) != 3 || top[0]["method"] != "GET" {
+			t.Fatalf("unexpected top endpoint: %v", top[0])
+		}
+		if avg, ok := top[0]["avg_response_time"].(float64); !ok || avg != 20 {
+			t.Fatalf("unexpected top endpoint avg_response_time: %v", top[0]["avg_response_time"])
+		}
+
+		paths, err := repo.GetUniquePaths(ctx(), "p1", domain.EnvironmentDev)
+		mustNoError(t, err)
+		sort.Strings(paths)
+		if len(paths) != 2 || paths[0] != "/a" || paths[1] != "/b" {
+			t.Fatalf("unexpected unique paths: %v", paths)
+		}
+	})
+
+	t.Run("StatsEmpty", func(t *testing.T) {
+		repo := newRepo(t)
+
+		count, err := repo.CountByProject(ctx(), "p1", domain.EnvironmentDev)
+		mustNoError(t, err)
+		if count != 0 {
+			t.Fatalf("CountByProject: want 0, got %d", count)
+		}
+
+		avg, err := repo.GetAverageResponseTime(ctx(), "p1", domain.EnvironmentDev)
+		mustNoError(t, err)
+		if avg != 0 {
+			t.Fatalf("GetAverageResponseTime: want 0, got %v", avg)
+		}
+
+		paths, err := repo.GetUniquePaths(ctx(), "p1", domain.EnvironmentDev)
+		mustNoError(t, err)
+		if len(paths) != 0 {
+			t.Fatalf("GetUniquePaths: want none, got %v", paths)
+		}
+	})
+
+	t.Run("DeleteAndMeasureOlderThan", func(t *testing.T) {
+		repo := newRepo(t)
+		at := func(projectID string, ts time.Time) *domain.APILog {
+			return newLog(projectID, domain.EnvironmentDev, ts)
+		}
+		old, recent := now().Add(-48*time.Hour), now()
+		expired := []*domain.APILog{at("p1", old), at("p1", old), at("p1", old)}
+		kept := []*domain.APILog{at("p1", recent), at("p2", old)}
+		for _, item := range append(expired, kept...) {
+			mustNoError(t, repo.Create(ctx(), item))
+		}
+		before := now().Add(-24 * time.Hour)
+
+		volume, err := repo.VolumeOlderThan(ctx(), "p1", before)
+		mustNoError(t, err)
+		if volume.Documents != 3 || volume.Bytes <= 0 {
+			t.Fatalf("expected 3 logs with their size, got %+v", volume)
+		}
+
+		deleted, err := repo.DeleteOlderThan(ctx(), "p1", before, 2)
+		mustNoError(t, err)
+		if deleted != 2 {
+			t.Fatalf("expected a batch of 2 deleted, got %d", deleted)
+		}
+		deleted, err = repo.DeleteOlderThan(ctx(), "p1", before, 2)
+		mustNoError(t, err)
+		if deleted != 1 {
+			t.Fatalf("expected the last 1 deleted, got %d", deleted)
+		}
+		volume, err = repo.VolumeOlderThan(ctx(), "p1", before)
+		mustNoError(t, err)
+		if volume != (domain.DataVolume{}) {
+			t.Fatalf("expected nothing left to purge, got %+v", volume)
+		}
+
+		for _, item := range kept {
+			_, err := repo.FindByID(ctx(), item.ID)
+			mustNoError(t, err)
+		}
+	})
+
+	t.Run("FindOlderThanAndDeleteBatch", func(t *testing.T) {
+		repo := newRepo(t)
+		at := func(projectID string, ts time.Time) *domain.APILog {
+			return newLog(projectID, domain.EnvironmentDev, ts)
+		}
+		old := now().Add(-48 * time.Hour)
+		oldest, older, expired := at("p1", old.Add(-2*time.Hour)), at("p1", old.Add(-time.Hour)), at("p1", old)
+		kept := []*domain.APILog{at("p1", now()), at("p2", old)}
+		for _, item := range append([]*domain.APILog{expired, oldest, older}, kept...) {
+			mustNoError(t, repo.Create(ctx(), item))
+		}
+		before := now().Add(-24 * time.Hour)
+
+		found, err := repo.FindOlderThan(ctx(), "p1", before, 2)
+		mustNoError(t, err)
+		if len(found) != 2 || found[0].ID != oldest.ID || found[1].ID != older.ID {
+			t.Fatalf("expected the 2 oldest logs, oldest first, got %v", logIDs(found))
+		}
+		if found[0].Path != oldest.Path || !found[0].Timestamp.Equal(oldest.Timestamp) {
+			t.Fatalf("expected the stored log, got %+v", found[0])
+		}
+
+		mustNoError(t, repo.DeleteBatch(ctx(), []string{found[0].ID, found[1].ID, newID()}))
+		mustNoError(t, repo.DeleteBatch(ctx(), nil))
+		found, err = repo.FindOlderThan(ctx(), "p1", before, 10)
+		mustNoError(t, err)
+		if len(found) != 1 || found[0].ID != expired.ID {
+			t.Fatalf("expected only %s left, got %v", expired.ID, logIDs(found))
+		}
+
+		for _, item := range kept {
+			_, err := repo.FindByID(ctx(), item.ID)
+			mustNoError(t, err)
+		}
+	})
+}
+
+func assertLogIDs(t *testing.T, got, want []*domain.APILog) {
+	t.Helper()
+
+	gotIDs, wantIDs := logIDs(got), logIDs(want)
+	if len(gotIDs) != len(wantIDs) {
+		t.Fatalf("want %d logs %v, got %d %v", len(wantIDs), wantIDs, len(gotIDs), gotIDs)
+	}
+	for i := range wantIDs {
+		if gotIDs[i] != wantIDs[i] {
+			t.Fatalf("log %d: want %s, got %s (order must be newest first)", i, wantIDs[i], gotIDs[i])
+		}
+	}
+}
@@ -0,0 +1,77 @@
+// Package contract holds the behaviour every storage adapter must share.
+//
+// Each adapter's tests call the Run* functions with a factory that returns a
+// repository backed by empty storage, so the same assertions cover MongoDB,
+// PostgreSQL, SQLite and the in-memory implementation.
+package contract
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/spidey52/api-logs/internal/domain"
+)
+
+// now returns the current time truncated to the millisecond precision every
+// backend can round-trip
+func now() time.Time {
+	return time.Now().UTC().Truncate(time.Millisecond)
+}
+
+func newID() string {
+	return uuid.New().String()
+}
+
+func ctx() context.Context {
+	return context.Background()
+}
+
+// toInt64 normalises numeric aggregation results, which differ in width
+// between drivers
+func toInt64(t *testing.T, v interface{}) int64 {
+	t.Helper()
+
+	switch n := v.(type) {
+	case int:
+		return int64(n)
+	case int32:
+		return int64(n)
+	case int64:
+		return n
+	case float64:
+		return int64(n)
+	default:
+		t.Fatalf("unexpected numeric type %T", v)
+		return 0
+	}
+}
+
+func mustNoError(t *testing.T, err error) {
+	t.Helper()
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+}
+
+func mustBeError(t *testing.T, got, want error) {
+	t.Helper()
+	if got != want {
+		t.Fatalf("expected error %v, got %v", want, got)
+	}
+}
+
+func newProject(apiKey string, env domain.Environment, createdAt time.Time) *domain.Project {
+	return &domain.Project{
+		ID:           newID(),
+		Name:         "project " + apiKey,
+		Description:  "contract test project",
+		APIKeyHash:   apiKey,
+		APIKeyPrefix: domain.KeyPrefix(apiKey),
+		Environment:  env,
+		IsActive:     true,
+		CreatedAt:    createdAt,
+		UpdatedAt:    createdAt,
+	}
+}
@@ -0,0 +1,90 @@
+package contract
+
+import (
+	"bytes"
+	"testing"
+	"time"
+
+	"github.com/spidey52/api-logs/internal/domain"
+	"github.com/spidey52/api-logs/internal/ports/output"
+)
+
+// RunDataKeyRepository runs the DataKeyRepository contract
+func RunDataKeyRepository(t *testing.T, newRepo func(t *testing.T) output.DataKeyRepository) {
+	t.Run("CreateFindRewrap", func(t *testing.T) {
+		repo := newRepo(t)
+		projectID := newID()
+
+		_, err := repo.Find(ctx(), projectID)
+		mustBeError(t, err, domain.ErrDataKeyNotFound)
+
+		key := &domain.DataKey{
+			ProjectID:   projectID,
+			MasterKeyID: "primary",
+			WrappedKey:  []byte{0x00, 0x01, 0xfe, 0xff},
+			CreatedAt:   now(),
+			UpdatedAt:   now(),
+		}
+		mustNoError(t, repo.Create(ctx(), key))
+		mustBeError(t, repo.Create(ctx(), key), domain.ErrDuplicateDataKey)
+
+		got, err := repo.Find(ctx(), projectID)
+		mustNoError(t, err)
+		assertDataKey(t, got, key)
+
+		rewrapped := *key
+		rewrapped.MasterKeyID = "secondary"
+		rewrapped.WrappedKey = []byte("wrapped by the secondary key")
+		rewrapped.UpdatedAt = key.UpdatedAt.Add(time.Hour)
+		mustNoError(t, repo.Rewrap(ctx(), &rewrapped))
+
+		got, err = repo.Find(ctx(), projectID)
+		mustNoError(t, err)
+		assertDataKey(t, got, &rewrapped)
+		if !got.CreatedAt.Equal(key.CreatedAt) {
+			t.Fatalf("rewrap changed created_at: got %v, want %v", got.CreatedAt, key.CreatedAt)
+		}
+
+		rewrapped.ProjectID = newID()
+		mustBeError(t, repo.Rewrap(ctx(), &rewrapped), domain.ErrDataKeyNotFound)
+
+		mustNoError(t, repo.Delete(ctx(), projectID))
+		_, err = repo.Find(ctx(), projectID)
+		mustBeError(t, err, domain.ErrDataKeyNotFound)
+		mustBeError(t, repo.Delete(ctx(), projectID), domain.ErrDataKeyNotFound)
+	})
+
+	t.Run("List", func(t *testing.T) {
+		repo := newRepo(t)
+
+		keys, err := repo.List(ctx())
+		mustNoError(t, err)
+		if len(keys) != 0 {
+			t.Fatalf("expected no keys, got %d", len(keys))
+		}
+
+		first := &domain.DataKey{ProjectID: "project-a", MasterKeyID: "primary", WrappedKey: []byte("a"), CreatedAt: now(), UpdatedAt: now()}
+		second := &domain.DataKey{ProjectID: "project-b", MasterKeyID: "old", WrappedKey: []byte("b"), CreatedAt: now(), UpdatedAt: now()}
+		mustNoError(t, repo.Create(ctx(), second))
+		mustNoError(t, repo.Create(ctx(), first))
+
+		keys, err = repo.List(ctx())
+		mustNoError(t, err)
+		if len(keys) != 2 {
+			t.Fatalf("expected 2 keys, got %d", len(keys))
+		}
+		assertDataKey(t, keys[0], first)
+		assertDataKey(t, keys[1], second)
+	})
+}
+
+func assertDataKey(t *testing.T, got, want *domain.DataKey) {
+	t.Helper()
+	if got.ProjectID != want.ProjectID || got.MasterKeyID != want.MasterKeyID || !bytes.Equal(got.WrappedKey, want.WrappedKey) {
+		t.Fatalf("data key mismatch: got %s/%s/%x, want %s/%s/%x",
+			got.ProjectID, got.MasterKeyID, got.WrappedKey, want.ProjectID, want.MasterKeyID, want.WrappedKey)
+	}
+	if !got.UpdatedAt.Equal(want.UpdatedAt) {
+		t.Fatalf("updated_at mismatch: got %v, want %v", got.UpdatedAt, want.UpdatedAt)
+	}
+}
@@ -0,0 +1,387 @@
+package contract
+
+import (
+	"testing"
+	"time"
+
+	"github.com/spidey52/api-logs/internal/domain"
+	"github.com/spidey52/api-logs/internal/ports/output"
+)
+
+// RunHeadersRepository runs the APILogHeadersRepository contract
+func RunHeadersRepository(t *testing.T, newRepo func(t *testing.T) output.APILogHeadersRepository) {
+	newHeaders := func(logID string) *domain.APILogHeaders {
+		return &domain.APILogHeaders{
+			ID:              newID(),
+			LogID:           logID,
+			RequestHeaders:  map[string]any{"content-type": "application/json"},
+			ResponseHeaders: map[string]any{"x-request-id": "abc"},
+			CreatedAt:       now(),
+		}
+	}
+
+	t.Run("CreateAndFindByLogID", func(t *testing.T) {
+		repo := newRepo(t)
+		headers := newHeaders(newID())
+		mustNoError(t, repo.Create(ctx(), headers))
+
+		got, err := repo.FindByLogID(ctx(), headers.LogID)
+		mustNoError(t, err)
+		if got.ID != headers.ID || got.LogID != headers.LogID {
+			t.Fatalf("identity mismatch: got %+v", got)
+		}
+		if got.RequestHeaders["content-type"] != "application/json" || got.ResponseHeaders["x-request-id"] != "abc" {
+			t.Fatalf("headers mismatch: got %+v", got)
+		}
+	})
+
+	t.Run("CreateMany", func(t *testing.T) {
+		repo := newRepo(t)
+		a, b := newHeaders(newID()), newHeaders(newID())
+		mustNoError(t, repo.CreateMany(ctx(), []*domain.APILogHeaders{a, b}))
+		mustNoError(t, repo.CreateMany(ctx(), nil))
+
+		for _, want := range []*domain.APILogHeaders{a, b} {
+			got, err := repo.FindByLogID(ctx(), want.LogID)
+			mustNoError(t, err)
+			if got.ID != want.ID || got.RequestHeaders["content-type"] != "application/json" {
+				t.Fatalf("headers mismatch: got %+v", got)
+			}
+		}
+	})
+
+	t.Run("CreateManySkipsExisting", func(t *testing.T) {
+		repo := newRepo(t)
+		existing := newHeaders(newID())
+		mustNoError(t, repo.Create(ctx(), existing))
+
+		added := newHeaders(newID())
+		mustNoError(t, repo.CreateMany(ctx(), []*domain.APILogHeaders{newHeaders(existing.LogID), added}))
+
+		got, err := repo.FindByLogID(ctx(), existing.LogID)
+		mustNoError(t, err)
+		if got.ID != existing.ID {
+			t.Fatalf("expected the existing headers to be kept, got %+v", got)
+		}
+		_, err = repo.FindByLogID(ctx(), added.LogID)
+		mustNoError(t, err)
+	})
+
+	t.Run("FindByLogIDNotFound", func(t *testing.T) {
+		repo := newRepo(t)
+		_, err := repo.FindByLogID(ctx(), newID())
+		mustBeError(t, err, domain.ErrHeadersNotFound)
+	})
+
+	t.Run("FindByLogIDs", func(t *testing.T) {
+		repo := newRepo(t)
+		a, b, c := newHeaders(newID()), newHeaders(newID()), newHeaders(newID())
+		mustNoError(t, repo.CreateMany(ctx(), []*domain.APILogHeaders{a, b, c}))
+
+		found, err := repo.FindByLogIDs(ctx(), []string{a.LogID, c.LogID, newID()})
+		mustNoError(t, err)
+		if len(found) != 2 {
+			t.Fatalf("expected 2 headers, got %d", len(found))
+		}
+		for _, got := range found {
+			if (got.LogID != a.LogID && got.LogID != c.LogID) || got.RequestHeaders["content-type"] != "application/json" {
+				t.Fatalf("unexpected headers %+v", got)
+			}
+		}
+
+		found, err = repo.FindByLogIDs(ctx(), nil)
+		mustNoError(t, err)
+		if len(found) != 0 {
+			t.Fatalf("expected no headers, got %d", len(found))
+		}
+	})
+
+	t.Run("DeleteAndDeleteBatch", func(t *testing.T) {
+		repo := newRepo(t)
+		a, b, c := newHeaders(newID()), newHeaders(newID()), newHeaders(newID())
+		for _, h := range []*domain.APILogHeaders{a, b, c} {
+			mustNoError(t, repo.Create(ctx(), h))
+		}
+
+		mustNoError(t, repo.Delete(ctx(), a.LogID))
+		mustNoError(t, repo.DeleteBatch(ctx(), []string{b.LogID, newID()}))
+		mustNoError(t, repo.DeleteBatch(ctx(), nil))
+
+		for _, deleted := range []string{a.LogID, b.LogID} {
+			_, err := repo.FindByLogID(ctx(), deleted)
+			mustBeError(t, err, domain.ErrHeadersNotFound)
+		}
+		_, err := repo.FindByLogID(ctx(), c.LogID)
+		mustNoError(t, err)
+	})
+
+	t.Run("DeleteAndMeasureOlderThan", func(t *testing.T) {
+		repo := newRepo(t)
+		at := func(projectID string, ts time.Time) *domain.APILogHeaders {
+			headers := newHeaders(newID())
+			headers.ProjectID, headers.CreatedAt = projectID, ts
+			return headers
+		}
+		old, recent := now().Add(-48*time.Hour), now()
+		expired := []*domain.APILogHeaders{at("p1", old), at("p1", old), at("p1", old)}
+		kept := []*domain.APILogHeaders{at("p1", recent), at("p2", old)}
+		unowned := at("", old)
+		for _, item := range append(append(expired, kept...), unowned) {
+			mustNoError(t, repo.Create(ctx(), item))
+		}
+		before := now().Add(-24 * time.Hour)
+
+		volume, err := repo.VolumeOlderThan(ctx(), "p1", before)
+		mustNoError(t, err)
+		if volume.Documents != 3 || volume.Bytes <= 0 {
+			t.Fatalf("expected 3 headers with their size, got %+v", volume)
+		}
+
+		deleted, err := repo.DeleteOlderThan(ctx(), "p1", before, 2)
+		mustNoError(t, err)
+		if deleted != 2 {
+			t.Fatalf("expected a batch of 2 deleted, got %d", deleted)
+		}
+		deleted, err = repo.DeleteOlderThan(ctx(), "p1", before, 2)
+		mustNoError(t, err)
+		if deleted != 1 {
+			t.Fatalf("expected the last 1 deleted, got %d", deleted)
+		}
+		volume, err = repo.VolumeOlderThan(ctx(), "p1", before)
+		mustNoError(t, err)
+		if volume != (domain.DataVolume{}) {
+			t.Fatalf("expected nothing left to purge, got %+v", volume)
+		}
+
+		for _, item := range kept {
+			_, err := repo.FindByLogID(ctx(), item.LogID)
+			mustNoError(t, err)
+		}
+
+		deleted, err = repo.DeleteOlderThan(ctx(), "", before, 10)
+		mustNoError(t, err)
+		if deleted != 1 {
+			t.Fatalf("expected the headers without a project deleted, got %d", deleted)
+		}
+	})
+
+	t.Run("FindOlderThan", func(t *testing.T) {
+		repo := newRepo(t)
+		at := func(projectID string, ts time.Time) *domain.APILogHeaders {
+			item := newHeaders(newID())
+			item.ProjectID, item.CreatedAt = projectID, ts
+			return item
+		}
+		old := now().Add(-48 * time.Hour)
+		oldest, expired := at("p1", old.Add(-time.Hour)), at("p1", old)
+		unowned := at("", old)
+		for _, item := range []*domain.APILogHeaders{expired, oldest, at("p1", now()), at("p2", old), unowned} {
+			mustNoError(t, repo.Create(ctx(), item))
+		}
+		before := now().Add(-24 * time.Hour)
+
+		found, err := repo.FindOlderThan(ctx(), "p1", before, 10)
+		mustNoError(t, err)
+		if len(found) != 2 || found[0].LogID != oldest.LogID || found[1].LogID != expired.LogID {
+			t.Fatalf("expected the 2 expired headers, oldest first, got %+v", found)
+		}
+		if found[0].ProjectID != "p1" || found[0].RequestHeaders == nil {
+			t.Fatalf("expected the stored headers with its project, got %+v", found[0])
+		}
+
+		found, err = repo.FindOlderThan(ctx(), "p1", before, 1)
+		mustNoError(t, err)
+		if len(found) != 1 || found[0].LogID != oldest.LogID {
+			t.Fatalf("expected a batch of the oldest headers, got %+v", found)
+		}
+
+		found, err = repo.FindOlderThan(ctx(), "", before, 10)
+		mustNoError(t, err)
+		if len(found) != 1 || found[0].LogID != unowned.LogID || found[0].ProjectID != "" {
+			t.Fatalf("expected the headers without a project, got %+v", found)
+		}
+	})
+}
+
+// RunBodyRepository runs the APILogBodyRepository contract
+func RunBodyRepository(t *testing.T, newRepo func(t *testing.T) output.APILogBodyRepository) {
+	newBody := func(logID string) *domain.APILogBody {
+		return &domain.APILogBody{
+			ID:           newID(),
+			LogID:        logID,
+			RequestBody:  map[string]any{"name": "widget"},
+			ResponseBody: map[string]any{"ok": true},
+			CreatedAt:    now(),
+		}
+	}
+
+	t.Run("CreateAndFindByLogID", func(t *testing.T) {
+		repo := newRepo(t)
+		body := newBody(newID())
+		mustNoError(t, repo.Create(ctx(), body))
+
+		got, err := repo.FindByLogID(ctx(), body.LogID)
+		mustNoError(t, err)
+		if got.ID != body.ID || got.LogID != body.LogID {
+			t.Fatalf("identity mismatch: got %+v", got)
+		}
+		if got.RequestBody == nil || got.ResponseBody == nil {
+			t.Fatalf("bodies not round-tripped: got %+v", got)
+		}
+	})
+
+	t.Run("CreateMany", func(t *testing.T) {
+		repo := newRepo(t)
+		a, b := newBody(newID()), newBody(newID())
+		mustNoError(t, repo.CreateMany(ctx(), []*domain.APILogBody{a, b}))
+		mustNoError(t, repo.CreateMany(ctx(), nil))
+
+		for _, want := range []*domain.APILogBody{a, b} {
+			got, err := repo.FindByLogID(ctx(), want.LogID)
+			mustNoError(t, err)
+			if got.ID != want.ID || got.RequestBody == nil || got.ResponseBody == nil {
+				t.Fatalf("body mismatch: got %+v", got)
+			}
+		}
+	})
+
+	t.Run("CreateManySkipsExisting", func(t *testing.T) {
+		repo := newRepo(t)
+		existing := newBody(newID())
+		mustNoError(t, repo.Create(ctx(), existing))
+
+		added := newBody(newID())
+		mustNoError(t, repo.CreateMany(ctx(), []*domain.APILogBody{newBody(existing.LogID), added}))
+
+		got, err := repo.FindByLogID(ctx(), existing.LogID)
+		mustNoError(t, err)
+		if got.ID != existing.ID {
+			t.Fatalf("expected the existing body to be kept, got %+v", got)
+		}
+		_, err = repo.FindByLogID(ctx(), added.LogID)
+		mustNoError(t, err)
+	})
+
+	t.Run("FindByLogIDNotFound", func(t *testing.T) {
+		repo := newRepo(t)
+		_, err := repo.FindByLogID(ctx(), newID())
+		mustBeError(t, err, domain.ErrBodyNotFound)
+	})
+
+	t.Run("FindByLogIDs", func(t *testing.T) {
+		repo := newRepo(t)
+		a, b, c := newBody(newID()), newBody(newID()), newBody(newID())
+		mustNoError(t, repo.CreateMany(ctx(), []*domain.APILogBody{a, b, c}))
+
+		found, err := repo.FindByLogIDs(ctx(), []string{b.LogID, newID()})
+		mustNoError(t, err)
+		if len(found) != 1 || found[0].ID != b.ID || found[0].RequestBody == nil {
+			t.Fatalf("expected the body of %s, got %+v", b.LogID, found)
+		}
+	})
+
+	t.Run("DeleteAndDeleteBatch", func(t *testing.T) {
+		repo := newRepo(t)
+		a, b, c := newBody(newID()), newBody(newID()), newBody(newID())
+		for _, body := range []*domain.APILogBody{a, b, c} {
+			mustNoError(t, repo.Create(ctx(), body))
+		}
+
+		mustNoError(t, repo.Delete(ctx(), a.LogID))
+		mustNoError(t, repo.DeleteBatch(ctx(), []string{b.LogID}))
+
+		for _, deleted := range []string{a.LogID, b.LogID} {
+			_, err := repo.FindByLogID(ctx(), deleted)
+			mustBeError(t, err, domain.ErrBodyNotFound)
+		}
+		_, err := repo.FindByLogID(ctx(), c.LogID)
+		mustNoError(t, err)
+	})
+
+	t.Run("DeleteAndMeasureOlderThan", func(t *testing.T) {
+		repo := newRepo(t)
+		at := func(projectID string, ts time.Time) *domain.APILogBody {
+			body := newBody(newID())
+			body.ProjectID, body.CreatedAt = projectID, ts
+			return body
+		}
+		old, recent := now().Add(-48*time.Hour), now()
+		expired := []*domain.APILogBody{at("p1", old), at("p1", old), at("p1", old)}
+		kept := []*domain.APILogBody{at("p1", recent), at("p2", old)}
+		unowned := at("", old)
+		for _, item := range append(append(expired, kept...), unowned) {
+			mustNoError(t, repo.Create(ctx(), item))
+		}
+		before := now().Add(-24 * time.Hour)
+
+		volume, err := repo.VolumeOlderThan(ctx(), "p1", before)
+		mustNoError(t, err)
+		if volume.Documents != 3 || volume.Bytes <= 0 {
+			t.Fatalf("expected 3 bodies with their size, got %+v", volume)
+		}
+
+		deleted, err := repo.DeleteOlderThan(ctx(), "p1", before, 2)
+		mustNoError(t, err)
+		if deleted != 2 {
+			t.Fatalf("expected a batch of 2 deleted, got %d", deleted)
+		}
+		deleted, err = repo.DeleteOlderThan(ctx(), "p1", before, 2)
+		mustNoError(t, err)
+		if deleted != 1 {
+			t.Fatalf("expected the last 1 deleted, got %d", deleted)
+		}
+		volume, err = repo.VolumeOlderThan(ctx(), "p1", before)
+		mustNoError(t, err)
+		if volume != (domain.DataVolume{}) {
+			t.Fatalf("expected nothing left to purge, got %+v", volume)
+		}
+
+		for _, item := range kept {
+			_, err := repo.FindByLogID(ctx(), item.LogID)
+			mustNoError(t, err)
+		}
+
+		deleted, err = repo.DeleteOlderThan(ctx(), "", before, 10)
+		mustNoError(t, err)
+		if deleted != 1 {
+			t.Fatalf("expected the body without a project deleted, got %d", deleted)
+		}
+	})
+
+	t.Run("FindOlderThan", func(t *testing.T) {
+		repo := newRepo(t)
+		at := func(projectID string, ts time.Time) *domain.APILogBody {
+			item := newBody(newID())
+			item.ProjectID, item.CreatedAt = projectID, ts
+			return item
+		}
+		old := now().Add(-48 * time.Hour)
+		oldest, expired := at("p1", old.Add(-time.Hour)), at("p1", old)
+		unowned := at("", old)
+		for _, item := range []*domain.APILogBody{expired, oldest, at("p1", now()), at("p2", old), unowned} {
+			mustNoError(t, repo.Create(ctx(), item))
+		}
+		before := now().Add(-24 * time.Hour)
+
+		found, err := repo.FindOlderThan(ctx(), "p1", before, 10)
+		mustNoError(t, err)
+		if len(found) != 2 || found[0].LogID != oldest.LogID || found[1].LogID != expired.LogID {
+			t.Fatalf("expected the 2 expired bodies, oldest first, got %+v", found)
+		}
+		if found[0].ProjectID != "p1" || found[0].RequestBody == nil {
+			t.Fatalf("expected the stored body with its project, got %+v", found[0])
+		}
+
+		found, err = repo.FindOlderThan(ctx(), "p1", before, 1)
+		mustNoError(t, err)
+		if len(found) != 1 || found[0].LogID != oldest.LogID {
+			t.Fatalf("expected a batch of the oldest body, got %+v", found)
+		}
+
+		found, err = repo.FindOlderThan(ctx(), "", before, 10)
+		mustNoError(t, err)
+		if len(found) != 1 || found[0].LogID != unowned.LogID || found[0].ProjectID != "" {
+			t.Fatalf("expected the body without a project, got %+v", found)
+		}
+	})
+}
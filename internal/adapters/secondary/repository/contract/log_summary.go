@@ -0,0 +1,154 @@
+package contract
+
+import (
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/spidey52/api-logs/internal/domain"
+	"github.com/spidey52/api-logs/internal/ports/output"
+)
+
+// newSummary rolls up logs of the given status codes and response times, all
+// in the bucket of ts
+func newSummary(projectID string, env domain.Environment, granularity domain.SummaryGranularity, ts time.Time, path string, statuses []int, times []int64) *domain.LogSummary {
+	var summary *domain.LogSummary
+	for i, status := range statuses {
+		log := newLog(projectID, env, ts, withPath(domain.MethodGET, path), withStatus(status), withResponseTime(times[i]))
+		if summary == nil {
+			summary = domain.NewLogSummary(log, granularity)
+		}
+		summary.Add(log)
+	}
+	return summary
+}
+
+// RunLogSummaryRepository runs the LogSummaryRepository contract
+func RunLogSummaryRepository(t *testing.T, newRepo func(t *testing.T) output.LogSummaryRepository) {
+	hour := now().Truncate(time.Hour)
+
+	t.Run("ReplaceAndFind", func(t *testing.T) {
+		repo := newRepo(t)
+		projectID := newID()
+
+		want := newSummary(projectID, domain.EnvironmentDev, domain.GranularityMinute, hour, "/api/items",
+			[]int{200, 201, 404, 503}, []int64{3, 40, 120, 20000})
+		other := newSummary(projectID, domain.EnvironmentProduction, domain.GranularityMinute, hour.Add(time.Minute), "/api/orders",
+			[]int{200}, []int64{7})
+		mustNoError(t, repo.Replace(ctx(), projectID, domain.GranularityMinute, hour, hour.Add(time.Hour), []*domain.LogSummary{other, want}))
+
+		found, err := repo.Find(ctx(), domain.SummaryFilter{
+			ProjectID: projectID, Granularity: domain.GranularityMinute, From: hour, To: hour.Add(time.Hour),
+		})
+		mustNoError(t, err)
+		if len(found) != 2 {
+			t.Fatalf("expected 2 summaries, got %d", len(found))
+		}
+
+		got := found[0]
+		if got.Route != "/api/items" || !got.Bucket.Equal(hour) || got.Environment != domain.EnvironmentDev ||
+			got.Method != domain.MethodGET || got.Granularity != domain.GranularityMinute {
+			t.Fatalf("summaries not ordered by bucket, or dimensions mismatch: %+v", got)
+		}
+		if got.Count != 4 || got.ErrorCount != 2 || got.Status2xx != 2 || got.Status4xx != 1 || got.Status5xx != 1 ||
+			got.LatencySum != 20163 || got.LatencyMin != 3 || got.LatencyMax != 20000 {
+			t.Fatalf("summary counts mismatch: got %+v, want %+v", got, want)
+		}
+		if !reflect.DeepEqual(got.StatusCodes, map[int]int64{200: 1, 201: 1, 404: 1, 503: 1}) {
+			t.Fatalf("status codes mismatch: %v", got.StatusCodes)
+		}
+		if !reflect.DeepEqual(got.Histogram, want.Histogram) {
+			t.Fatalf("histogram mismatch: got %v, want %v", got.Histogram, want.Histogram)
+		}
+
+		found, err = repo.Find(ctx(), domain.SummaryFilter{
+			ProjectID: projectID, Environment: domain.EnvironmentProduction, Granularity: domain.GranularityMinute,
+			From: hour, To: hour.Add(time.Hour),
+		})
+		mustNoError(t, err)
+		if len(found) != 1 || found[0].Route != "/api/orders" {
+			t.Fatalf("environment filter mismatch: %+v", found)
+		}
+	})
+
+	t.Run("ReplaceOnlyTouchesRange", func(t *testing.T) {
+		repo := newRepo(t)
+		projectID := newID()
+		otherProject := newID()
+
+		first := newSummary(projectID, domain.EnvironmentDev, domain.GranularityMinute, hour, "/a", []int{200}, []int64{1})
+		second := newSummary(projectID, domain.EnvironmentDev, domain.GranularityMinute, hour.Add(time.Minute), "/a", []int{200}, []int64{1})
+		hourly := newSummary(projectID, domain.EnvironmentDev, domain.GranularityHour, hour, "/a", []int{200, 200}, []int64{1, 1})
+		foreign := newSummary(otherProject, domain.EnvironmentDev, domain.GranularityMinute, hour, "/a", []int{200}, []int64{1})
+		mustNoError(t, repo.Replace(ctx(), projectID, domain.GranularityMinute, hour, hour.Add(time.Hour), []*domain.LogSummary{first, second}))
+		mustNoError(t, repo.Replace(ctx(), projectID, domain.GranularityHour, hour, hour.Add(time.Hour), []*domain.LogSummary{hourly}))
+		mustNoError(t, repo.Replace(ctx(), otherProject, domain.GranularityMinute, hour, hour.Add(time.Hour), []*domain.LogSummary{foreign}))
+
+		// Refreshing the second minute replaces its summary and drops the
+		// routes no longer seen in it
+		replaced := newSummary(projectID, domain.EnvironmentDev, domain.GranularityMinute, hour.Add(time.Minute), "/b", []int{500}, []int64{9})
+		mustNoError(t, repo.Replace(ctx(), projectID, domain.GranularityMinute, hour.Add(time.Minute), hour.Add(2*time.Minute), []*domain.LogSummary{replaced}))
+
+		found, err := repo.Find(ctx(), domain.SummaryFilter{
+			ProjectID: projectID, Granularity: domain.GranularityMinute, From: hour, To: hour.Add(time.Hour),
+		})
+		mustNoError(t, err)
+		if len(found) != 2 || found[0].Route != "/a" || found[1].Route != "/b" || found[1].ErrorCount != 1 {
+			t.Fatalf("replace mismatch: %+v", found)
+		}
+
+		for _, filter := range []domain.SummaryFilter{
+			{ProjectID: projectID, Granularity: domain.GranularityHour, From: hour, To: hour.Add(time.Hour)},
+			{ProjectID: otherProject, Granularity: domain.GranularityMinute, From: hour, To: hour.Add(time.Hour)},
+		} {
+			found, err := repo.Find(ctx(), filter)
+			mustNoError(t, err)
+			if len(found) != 1 {
+				t.Fatalf("replace touched summaries outside its range: %+v", found)
+			}
+		}
+
+		// Replacing a range with nothing empties it
+		mustNoError(t, repo.Replace(ctx(), projectID, domain.GranularityMinute, hour, hour.Add(time.Hour), nil))
+		found, err = repo.Find(ctx(), domain.SummaryFilter{
+			ProjectID: projectID, Granularity: domain.GranularityMinute, From: hour, To: hour.Add(time.Hour),
+		})
+		mustNoError(t, err)
+		if len(found) != 0 {
+			t.Fatalf("expected the range to be empty, got %d summaries", len(found))
+		}
+	})
+
+	t.Run("DeleteOlderThan", func(t *testing.T) {
+		repo := newRepo(t)
+		projectID := newID()
+
+		old := newSummary(projectID, domain.EnvironmentDev, domain.GranularityMinute, hour.Add(-time.Hour), "/a", []int{200}, []int64{1})
+		recent := newSummary(projectID, domain.EnvironmentDev, domain.GranularityMinute, hour, "/a", []int{200}, []int64{1})
+		oldHour := newSummary(projectID, domain.EnvironmentDev, domain.GranularityHour, hour.Add(-time.Hour), "/a", []int{200}, []int64{1})
+		mustNoError(t, repo.Replace(ctx(), projectID, domain.GranularityMinute, hour.Add(-time.Hour), hour.Add(time.Hour), []*domain.LogSummary{old, recent}))
+		mustNoError(t, repo.Replace(ctx(), projectID, domain.GranularityHour, hour.Add(-time.Hour), hour, []*domain.LogSummary{oldHour}))
+
+		deleted, err := repo.DeleteOlderThan(ctx(), domain.GranularityMinute, hour)
+		mustNoError(t, err)
+		if deleted < 1 {
+			t.Fatalf("expected the old minute summary to be deleted, deleted %d", deleted)
+		}
+
+		found, err := repo.Find(ctx(), domain.SummaryFilter{
+			ProjectID: projectID, Granularity: domain.GranularityMinute, From: hour.Add(-time.Hour), To: hour.Add(time.Hour),
+		})
+		mustNoError(t, err)
+		if len(found) != 1 || !found[0].Bucket.Equal(hour) {
+			t.Fatalf("expected only the recent minute summary, got %+v", found)
+		}
+
+		found, err = repo.Find(ctx(), domain.SummaryFilter{
+			ProjectID: projectID, Granularity: domain.GranularityHour, From: hour.Add(-time.Hour), To: hour,
+		})
+		mustNoError(t, err)
+		if len(found) != 1 {
+			t.Fatalf("deleting minute summaries removed hour summaries: %+v", found)
+		}
+	})
+}
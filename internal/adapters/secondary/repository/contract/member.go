@@ -0,0 +1,114 @@
+package contract
+
+import (
+	"testing"
+	"time"
+
+	"github.com/spidey52/api-logs/internal/domain"
+	"github.com/spidey52/api-logs/internal/ports/output"
+)
+
+func newMember(projectID, accountID string, role domain.ProjectRole, createdAt time.Time) *domain.ProjectMember {
+	return &domain.ProjectMember{
+		ID:        newID(),
+		ProjectID: projectID,
+		AccountID: accountID,
+		Role:      role,
+		CreatedAt: createdAt,
+		UpdatedAt: createdAt,
+	}
+}
+
+// RunProjectMemberRepository runs the ProjectMemberRepository contract
+func RunProjectMemberRepository(t *testing.T, newRepo func(t *testing.T) output.ProjectMemberRepository) {
+	t.Run("CreateAndFind", func(t *testing.T) {
+		repo := newRepo(t)
+		member := newMember("p1", "a1", domain.RoleEditor, now())
+		mustNoError(t, repo.Create(ctx(), member))
+
+		got, err := repo.Find(ctx(), "p1", "a1")
+		mustNoError(t, err)
+		if got.ID != member.ID || got.Role != domain.RoleEditor || !got.CreatedAt.Equal(member.CreatedAt) {
+			t.Fatalf("member mismatch: got %+v", got)
+		}
+
+		_, err = repo.Find(ctx(), "p2", "a1")
+		mustBeError(t, err, domain.ErrMemberNotFound)
+	})
+
+	t.Run("UniquePerProjectAndAccount", func(t *testing.T) {
+		repo := newRepo(t)
+		mustNoError(t, repo.Create(ctx(), newMember("p1", "a1", domain.RoleViewer, now())))
+		mustNoError(t, repo.Create(ctx(), newMember("p2", "a1", domain.RoleViewer, now())))
+		mustBeError(t, repo.Create(ctx(), newMember("p1", "a1", domain.RoleOwner, now())), domain.ErrDuplicateMember)
+	})
+
+	t.Run("UpdateAndDelete", func(t *testing.T) {
+		repo := newRepo(t)
+		member := newMember("p1", "a1", domain.RoleViewer, now())
+		mustNoError(t, repo.Create(ctx(), member))
+
+		member.Role = domain.RoleOwner
+		member.UpdatedAt = now().Add(time.Minute)
+		mustNoError(t, repo.Update(ctx(), member))
+
+		got, err := repo.Find(ctx(), "p1", "a1")
+		mustNoError(t, err)
+		if got.Role != domain.RoleOwner || !got.UpdatedAt.Equal(member.UpdatedAt) {
+			t.Fatalf("update not applied: got %+v", got)
+		}
+
+		mustNoError(t, repo.Delete(ctx(), "p1", "a1"))
+		_, err = repo.Find(ctx(), "p1", "a1")
+		mustBeError(t, err, domain.ErrMemberNotFound)
+
+		mustBeError(t, repo.Update(ctx(), member), domain.ErrMemberNotFound)
+		mustBeError(t, repo.Delete(ctx(), "p1", "a1"), domain.ErrMemberNotFound)
+	})
+
+	t.Run("ListOldestFirst", func(t *testing.T) {
+		repo := newRepo(t)
+		base := now()
+		second := newMember("p1", "a2", domain.RoleViewer, base.Add(time.Minute))
+		first := newMember("p1", "a1", domain.RoleOwner, base)
+		other := newMember("p2", "a1", domain.RoleEditor, base.Add(2*time.Minute))
+		for _, member := range []*domain.ProjectMember{second, first, other} {
+			mustNoError(t, repo.Create(ctx(), member))
+		}
+
+		byProject, err := repo.ListByProject(ctx(), "p1")
+		mustNoError(t, err)
+		if len(byProject) != 2 || byProject[0].ID != first.ID || byProject[1].ID != second.ID {
+			t.Fatalf("ListByProject returned %+v", byProject)
+		}
+
+		byAccount, err := repo.ListByAccount(ctx(), "a1")
+		mustNoError(t, err)
+		if len(byAccount) != 2 || byAccount[0].ID != first.ID || byAccount[1].ID != other.ID {
+			t.Fatalf("ListByAccount returned %+v", byAccount)
+		}
+
+		empty, err := repo.ListByAccount(ctx(), "nobody")
+		mustNoError(t, err)
+		if len(empty) != 0 {
+			t.Fatalf("expected no memberships, got %d", len(empty))
+		}
+	})
+
+	t.Run("DeleteByProject", func(t *testing.T) {
+		repo := newRepo(t)
+		mustNoError(t, repo.Create(ctx(), newMember("p1", "a1", domain.RoleOwner, now())))
+		mustNoError(t, repo.Create(ctx(), newMember("p1", "a2", domain.RoleViewer, now())))
+		mustNoError(t, repo.Create(ctx(), newMember("p2", "a1", domain.RoleOwner, now())))
+
+		mustNoError(t, repo.DeleteByProject(ctx(), "p1"))
+
+		members, err := repo.ListByProject(ctx(), "p1")
+		mustNoError(t, err)
+		if len(members) != 0 {
+			t.Fatalf("expected p1 members deleted, got %d", len(members))
+		}
+		_, err = repo.Find(ctx(), "p2", "a1")
+		mustNoError(t, err)
+	})
+}
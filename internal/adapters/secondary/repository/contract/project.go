@@ -0,0 +1,181 @@
+package contract
+
+import (
+	"testing"
+	"time"
+
+	"github.com/spidey52/api-logs/internal/domain"
+	"github.com/spidey52/api-logs/internal/ports/output"
+)
+
+// RunProjectRepository runs the ProjectRepository contract
+func RunProjectRepository(t *testing.T, newRepo func(t *testing.T) output.ProjectRepository) {
+	t.Run("CreateAndFind", func(t *testing.T) {
+		repo := newRepo(t)
+		project := newProject("dev_key1", domain.EnvironmentDev, now())
+		mustNoError(t, repo.Create(ctx(), project))
+
+		byID, err := repo.FindByID(ctx(), project.ID)
+		mustNoError(t, err)
+		if byID.Name != project.Name || byID.Description != project.Description || byID.APIKeyHash != project.APIKeyHash ||
+			byID.APIKeyPrefix != project.APIKeyPrefix ||
+			byID.Environment != project.Environment || !byID.IsActive || !byID.CreatedAt.Equal(project.CreatedAt) {
+			t.Fatalf("project mismatch: got %+v", byID)
+		}
+
+		byKey, err := repo.FindByAPIKey(ctx(), "dev_key1")
+		mustNoError(t, err)
+		if byKey.ID != project.ID {
+			t.Fatalf("FindByAPIKey returned %s, want %s", byKey.ID, project.ID)
+		}
+
+		exists, err := repo.ExistsByAPIKey(ctx(), "dev_key1")
+		mustNoError(t, err)
+		if !exists {
+			t.Fatal("ExistsByAPIKey: want true")
+		}
+
+		exists, err = repo.ExistsByAPIKey(ctx(), "dev_missing")
+		mustNoError(t, err)
+		if exists {
+			t.Fatal("ExistsByAPIKey: want false")
+		}
+	})
+
+	t.Run("NotFound", func(t *testing.T) {
+		repo := newRepo(t)
+
+		_, err := repo.FindByID(ctx(), newID())
+		mustBeError(t, err, domain.ErrProjectNotFound)
+
+		_, err = repo.FindByAPIKey(ctx(), "dev_missing")
+		mustBeError(t, err, domain.ErrProjectNotFound)
+
+		mustBeError(t, repo.Update(ctx(), newProject("dev_missing", domain.EnvironmentDev, now())), domain.ErrProjectNotFound)
+		mustBeError(t, repo.Delete(ctx(), newID()), domain.ErrProjectNotFound)
+	})
+
+	t.Run("DuplicateAPIKey", func(t *testing.T) {
+		repo := newRepo(t)
+		mustNoError(t, repo.Create(ctx(), newProject("dev_dup", domain.EnvironmentDev, now())))
+		mustBeError(t, repo.Create(ctx(), newProject("dev_dup", domain.EnvironmentDev, now())), domain.ErrDuplicateAPIKey)
+	})
+
+	t.Run("UpdateAndDelete", func(t *testing.T) {
+		repo := newRepo(t)
+		project := newProject("dev_key1", domain.EnvironmentDev, now())
+		mustNoError(t, repo.Create(ctx(), project))
+
+		project.Name = "renamed"
+		project.APIKeyHash = "dev_key2"
+		project.APIKeyPrefix = domain.KeyPrefix("dev_key2")
+		project.IsActive = false
+		project.Retention = domain.RetentionPolicy{LogsDays: 90, HeadersDays: 30, BodiesDays: 7, AccessLogsDays: 365}
+		project.UpdatedAt = now().Add(time.Minute)
+		mustNoError(t, repo.Update(ctx(), project))
+
+		got, err := repo.FindByID(ctx(), project.ID)
+		mustNoError(t, err)
+		if got.Name != "renamed" || got.APIKeyHash != "dev_key2" || got.IsActive || !got.UpdatedAt.Equal(project.UpdatedAt) {
+			t.Fatalf("update not applied: got %+v", got)
+		}
+		if got.Retention != project.Retention {
+			t.Fatalf("retention not updated: got %+v", got.Retention)
+		}
+
+		_, err = repo.FindByAPIKey(ctx(), "dev_key1")
+		mustBeError(t, err, domain.ErrProjectNotFound)
+
+		mustNoError(t, repo.Delete(ctx(), project.ID))
+		_, err = repo.FindByID(ctx(), project.ID)
+		mustBeError(t, err, domain.ErrProjectNotFound)
+	})
+
+	t.Run("PlaintextAPIKeyNotStored", func(t *testing.T) {
+		repo := newRepo(t)
+		project := newProject("hashed:dev_plain", domain.EnvironmentDev, now())
+		project.APIKey = "dev_plain"
+		mustNoError(t, repo.Create(ctx(), project))
+
+		got, err := repo.FindByID(ctx(), project.ID)
+		mustNoError(t, err)
+		if got.APIKey != "" {
+			t.Fatalf("plaintext API key stored: %q", got.APIKey)
+		}
+		_, err = repo.FindByAPIKey(ctx(), "dev_plain")
+		mustBeError(t, err, domain.ErrProjectNotFound)
+	})
+
+	t.Run("HashPlaintextAPIKeys", func(t *testing.T) {
+		repo := newRepo(t)
+		legacy := newProject("prod_0123456789", domain.EnvironmentProduction, now())
+		legacy.APIKeyPrefix = ""
+		hashed := newProject("dev_hashed", domain.EnvironmentDev, now())
+		mustNoError(t, repo.Create(ctx(), legacy))
+		mustNoError(t, repo.Create(ctx(), hashed))
+
+		hash := func(key string) string { return "hashed:" + key }
+		converted, err := repo.HashPlaintextAPIKeys(ctx(), hash)
+		mustNoError(t, err)
+		if converted != 1 {
+			t.Fatalf("converted %d projects, want 1", converted)
+		}
+
+		got, err := repo.FindByAPIKey(ctx(), "hashed:prod_0123456789")
+		mustNoError(t, err)
+		if got.ID != legacy.ID || got.APIKeyPrefix != "prod_0123" {
+			t.Fatalf("legacy project not converted: got %+v", got)
+		}
+		_, err = repo.FindByAPIKey(ctx(), "prod_0123456789")
+		mustBeError(t, err, domain.ErrProjectNotFound)
+
+		converted, err = repo.HashPlaintextAPIKeys(ctx(), hash)
+		mustNoError(t, err)
+		if converted != 0 {
+			t.Fatalf("second run converted %d projects, want 0", converted)
+		}
+	})
+
+	t.Run("FindAll", func(t *testing.T) {
+		repo := newRepo(t)
+		base := now().Add(-time.Hour)
+
+		projects := []*domain.Project{
+			newProject("dev_a", domain.EnvironmentDev, base.Add(1*time.Minute)),
+			newProject("prod_b", domain.EnvironmentProduction, base.Add(2*time.Minute)),
+			newProject("dev_c", domain.EnvironmentDev, base.Add(3*time.Minute)),
+		}
+		projects[2].IsActive = false
+		for _, project := range projects {
+			mustNoError(t, repo.Create(ctx(), project))
+		}
+
+		inactive := false
+		cases := []struct {
+			name   string
+			filter domain.ProjectFilter
+			want   []*domain.Project
+		}{
+			{"all newest first", domain.ProjectFilter{}, []*domain.Project{projects[2], projects[1], projects[0]}},
+			{"environment", domain.ProjectFilter{Environment: domain.EnvironmentDev}, []*domain.Project{projects[2], projects[0]}},
+			{"active flag", domain.ProjectFilter{IsActive: &inactive}, []*domain.Project{projects[2]}},
+			{"pagination", domain.ProjectFilter{SharedFilter: domain.SharedFilter{Limit: 1, Offset: 1}}, []*domain.Project{projects[1]}},
+			{"ids", domain.ProjectFilter{IDs: []string{projects[0].ID, projects[2].ID}}, []*domain.Project{projects[2], projects[0]}},
+		}
+
+		for _, tc := range cases {
+			t.Run(tc.name, func(t *testing.T) {
+				got, err := repo.FindAll(ctx(), tc.filter)
+				mustNoError(t, err)
+				if len(got) != len(tc.want) {
+					t.Fatalf("want %d projects, got %d", len(tc.want), len(got))
+				}
+				for i := range tc.want {
+					if got[i].ID != tc.want[i].ID {
+						t.Fatalf("project %d: want %s, got %s", i, tc.want[i].APIKeyHash, got[i].APIKeyHash)
+					}
+				}
+			})
+		}
+	})
+}
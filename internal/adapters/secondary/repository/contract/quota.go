@@ -0,0 +1,49 @@
+package contract
+
+import (
+	"testing"
+
+	"github.com/spidey52/api-logs/internal/domain"
+	"github.com/spidey52/api-logs/internal/ports/output"
+)
+
+// RunQuotaRepository runs the QuotaRepository contract
+func RunQuotaRepository(t *testing.T, newRepo func(t *testing.T) output.QuotaRepository) {
+	t.Run("UpsertFindDelete", func(t *testing.T) {
+		repo := newRepo(t)
+		projectID := newID()
+
+		_, err := repo.Find(ctx(), projectID)
+		mustBeError(t, err, domain.ErrQuotaNotFound)
+
+		quota := &domain.ProjectQuota{
+			ProjectID:            projectID,
+			RequestsPerSecond:    10,
+			LogsPerDay:           1000,
+			KeyRequestsPerSecond: 5,
+			UpdatedAt:            now(),
+		}
+		mustNoError(t, repo.Upsert(ctx(), quota))
+
+		got, err := repo.Find(ctx(), projectID)
+		mustNoError(t, err)
+		if got.RequestsPerSecond != 10 || got.LogsPerDay != 1000 || got.KeyRequestsPerSecond != 5 || got.KeyLogsPerDay != 0 ||
+			!got.UpdatedAt.Equal(quota.UpdatedAt) {
+			t.Fatalf("quota mismatch: got %+v, want %+v", got, quota)
+		}
+
+		quota.LogsPerDay = 0
+		quota.KeyLogsPerDay = 50
+		mustNoError(t, repo.Upsert(ctx(), quota))
+		got, err = repo.Find(ctx(), projectID)
+		mustNoError(t, err)
+		if got.LogsPerDay != 0 || got.KeyLogsPerDay != 50 || got.RequestsPerSecond != 10 {
+			t.Fatalf("upsert did not replace quota: %+v", got)
+		}
+
+		mustNoError(t, repo.Delete(ctx(), projectID))
+		_, err = repo.Find(ctx(), projectID)
+		mustBeError(t, err, domain.ErrQuotaNotFound)
+		mustBeError(t, repo.Delete(ctx(), projectID), domain.ErrQuotaNotFound)
+	})
+}
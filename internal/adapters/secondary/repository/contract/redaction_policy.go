@@ -0,0 +1,62 @@
+package contract
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/spidey52/api-logs/internal/domain"
+	"github.com/spidey52/api-logs/internal/ports/output"
+)
+
+// RunRedactionPolicyRepository runs the RedactionPolicyRepository contract
+func RunRedactionPolicyRepository(t *testing.T, newRepo func(t *testing.T) output.RedactionPolicyRepository) {
+	t.Run("UpsertFindDelete", func(t *testing.T) {
+		repo := newRepo(t)
+		projectID := newID()
+
+		_, err := repo.Find(ctx(), projectID)
+		mustBeError(t, err, domain.ErrRedactionPolicyNotFound)
+
+		policy := &domain.RedactionPolicy{
+			ProjectID: projectID,
+			Headers:   []string{"authorization", "cookie"},
+			JSONPaths: []string{"$.password", "$..card_number"},
+			Patterns:  []string{"email"},
+			UpdatedAt: now(),
+		}
+		mustNoError(t, repo.Upsert(ctx(), policy))
+
+		got, err := repo.Find(ctx(), projectID)
+		mustNoError(t, err)
+		assertStrings(t, "headers", got.Headers, policy.Headers)
+		assertStrings(t, "json_paths", got.JSONPaths, policy.JSONPaths)
+		assertStrings(t, "patterns", got.Patterns, policy.Patterns)
+		assertStrings(t, "query_params", got.QueryParams, nil)
+		if !got.UpdatedAt.Equal(policy.UpdatedAt) {
+			t.Fatalf("updated_at mismatch: got %v, want %v", got.UpdatedAt, policy.UpdatedAt)
+		}
+
+		policy.Headers = nil
+		policy.QueryParams = []string{"token"}
+		policy.Regexes = []string{`\bAcct-\d+\b`}
+		mustNoError(t, repo.Upsert(ctx(), policy))
+		got, err = repo.Find(ctx(), projectID)
+		mustNoError(t, err)
+		assertStrings(t, "headers", got.Headers, nil)
+		assertStrings(t, "query_params", got.QueryParams, policy.QueryParams)
+		assertStrings(t, "regexes", got.Regexes, policy.Regexes)
+
+		mustNoError(t, repo.Delete(ctx(), projectID))
+		_, err = repo.Find(ctx(), projectID)
+		mustBeError(t, err, domain.ErrRedactionPolicyNotFound)
+		mustBeError(t, repo.Delete(ctx(), projectID), domain.ErrRedactionPolicyNotFound)
+	})
+}
+
+// assertStrings compares lists, treating nil and empty as equal
+func assertStrings(t *testing.T, name string, got, want []string) {
+	t.Helper()
+	if len(got) != len(want) || strings.Join(got, "\x00") != strings.Join(want, "\x00") {
+		t.Fatalf("%s mismatch: got %q, want %q", name, got, want)
+	}
+}
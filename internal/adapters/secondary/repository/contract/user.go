@@ -0,0 +1,142 @@
+package contract
+
+import (
+	"testing"
+	"time"
+
+	"github.com/spidey52/api-logs/internal/domain"
+	"github.com/spidey52/api-logs/internal/ports/output"
+)
+
+func newUser(projectID, identifier string, createdAt time.Time) *domain.User {
+	return &domain.User{
+		ID:         newID(),
+		Name:       "User " + identifier,
+		Identifier: identifier,
+		Metadata:   map[string]any{"team": "platform"},
+		CreatedAt:  createdAt,
+		ProjectID:  projectID,
+	}
+}
+
+// RunUserRepository runs the UserRepository contract
+func RunUserRepository(t *testing.T, newRepo func(t *testing.T) output.UserRepository) {
+	t.Run("CreateAndFind", func(t *testing.T) {
+		repo := newRepo(t)
+		user := newUser("p1", "emp-1", now())
+		mustNoError(t, repo.Create(ctx(), user))
+
+		byID, err := repo.FindByID(ctx(), user.ID)
+		mustNoError(t, err)
+		if byID.Name != user.Name || byID.Identifier != user.Identifier || byID.ProjectID != "p1" ||
+			byID.Metadata["team"] != "platform" || !byID.CreatedAt.Equal(user.CreatedAt) {
+			t.Fatalf("user mismatch: got %+v", byID)
+		}
+
+		byIdentifier, err := repo.FindByIdentifier(ctx(), "emp-1", "p1")
+		mustNoError(t, err)
+		if byIdentifier.ID != user.ID {
+			t.Fatalf("FindByIdentifier returned %s, want %s", byIdentifier.ID, user.ID)
+		}
+
+		_, err = repo.FindByIdentifier(ctx(), "emp-1", "p2")
+		mustBeError(t, err, domain.ErrUserNotFound)
+	})
+
+	t.Run("NotFound", func(t *testing.T) {
+		repo := newRepo(t)
+
+		_, err := repo.FindByID(ctx(), newID())
+		mustBeError(t, err, domain.ErrUserNotFound)
+
+		mustBeError(t, repo.Update(ctx(), newUser("p1", "missing", now())), domain.ErrUserNotFound)
+		mustBeError(t, repo.Delete(ctx(), newID()), domain.ErrUserNotFound)
+	})
+
+	t.Run("IdentifierUniquePerProject", func(t *testing.T) {
+		repo := newRepo(t)
+		mustNoError(t, repo.Create(ctx(), newUser("p1", "emp-1", now())))
+		mustNoError(t, repo.Create(ctx(), newUser("p2", "emp-1", now())))
+		mustBeError(t, repo.Create(ctx(), newUser("p1", "emp-1", now())), domain.ErrDuplicateUserIdentifier)
+	})
+
+	t.Run("UpdateAndDelete", func(t *testing.T) {
+		repo := newRepo(t)
+		user := newUser("p1", "emp-1", now())
+		mustNoError(t, repo.Create(ctx(), user))
+
+		user.Name = "Renamed"
+		user.Metadata = map[string]any{"team": "billing"}
+		mustNoError(t, repo.Update(ctx(), user))
+
+		got, err := repo.FindByID(ctx(), user.ID)
+		mustNoError(t, err)
+		if got.Name != "Renamed" || got.Metadata["team"] != "billing" || got.ProjectID != "p1" {
+			t.Fatalf("update not applied: got %+v", got)
+		}
+
+		mustNoError(t, repo.Delete(ctx(), user.ID))
+		_, err = repo.FindByID(ctx(), user.ID)
+		mustBeError(t, err, domain.ErrUserNotFound)
+	})
+
+	t.Run("DeleteByProject", func(t *testing.T) {
+		repo := newRepo(t)
+		projectID := newID()
+		first := newUser(projectID, "a", now())
+		second := newUser(projectID, "b", now())
+		other := newUser(newID(), "a", now())
+		for _, user := range []*domain.User{first, second, other} {
+			mustNoError(t, repo.Create(ctx(), user))
+		}
+
+		mustNoError(t, repo.DeleteByProject(ctx(), projectID))
+		for _, user := range []*domain.User{first, second} {
+			_, err := repo.FindByID(ctx(), user.ID)
+			mustBeError(t, err, domain.ErrUserNotFound)
+		}
+		_, err := repo.FindByID(ctx(), other.ID)
+		mustNoError(t, err)
+	})
+
+	t.Run("ListAndGetUserMap", func(t *testing.T) {
+		repo := newRepo(t)
+		base := now().Add(-time.Hour)
+		projectID := newID()
+
+		var users []*domain.User
+		for i, identifier := range []string{"a", "b", "c"} {
+			user := newUser(projectID, identifier, base.Add(time.Duration(i)*time.Minute))
+			users = append(users, user)
+			mustNoError(t, repo.Create(ctx(), user))
+		}
+		mustNoError(t, repo.Create(ctx(), newUser(newID(), "a", base)))
+
+		page, total, err := repo.List(ctx(), projectID, 2, 2)
+		mustNoError(t, err)
+		if total != 3 {
+			t.Fatalf("List total: want 3, got %d", total)
+		}
+		if len(page) != 1 || page[0].ID != users[0].ID {
+			t.Fatalf("List page 2 should hold the oldest user, got %v", page)
+		}
+
+		first, _, err := repo.List(ctx(), projectID, 1, 2)
+		mustNoError(t, err)
+		if len(first) != 2 || first[0].ID != users[2].ID || first[1].ID != users[1].ID {
+			t.Fatalf("List page 1 should be newest first, got %v", first)
+		}
+
+		userMap, err := repo.GetUserMap(ctx(), []string{users[0].ID, users[2].ID, newID()})
+		mustNoError(t, err)
+		if len(userMap) != 2 || userMap[users[0].ID] == nil || userMap[users[2].ID].Identifier != "c" {
+			t.Fatalf("unexpected user map: %v", userMap)
+		}
+
+		empty, err := repo.GetUserMap(ctx(), nil)
+		mustNoError(t, err)
+		if len(empty) != 0 {
+			t.Fatalf("GetUserMap(nil): want empty, got %v", empty)
+		}
+	})
+}
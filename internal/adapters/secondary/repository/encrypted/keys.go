@@ -0,0 +1,222 @@
+// Package encrypted stores captured headers and bodies encrypted at rest on
+// top of any storage backend. Each project has its own data key, stored
+// wrapped by a master key; header maps and bodies are sealed with the data key
+// of their log's project when they are written, and stay sealed when they are
+// read until a LogDecrypter is asked to open them.
+package encrypted
+
+import (
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/spidey52/api-logs/internal/domain"
+	"github.com/spidey52/api-logs/internal/ports/output"
+	"github.com/spidey52/api-logs/pkg/envelope"
+)
+
+// formatVersion prefixes sealed values, so their format can change later
+const formatVersion = "v1"
+
+// Keys manages the projects' data keys and implements output.LogDecrypter.
+// Unwrapped data keys are cached: a data key never changes, only the master
+// key wrapping it.
+type Keys struct {
+	repo    output.DataKeyRepository
+	keyring *envelope.Keyring
+
+	mu    sync.Mutex
+	cache map[string][]byte
+}
+
+// NewKeys creates the data key manager for keys wrapped by the keyring
+func NewKeys(repo output.DataKeyRepository, keyring *envelope.Keyring) *Keys {
+	return &Keys{
+		repo:    repo,
+		keyring: keyring,
+		cache:   make(map[string][]byte),
+	}
+}
+
+var _ output.LogDecrypter = (*Keys)(nil)
+
+// DecryptHeaders implements output.LogDecrypter.
+func (k *Keys) DecryptHeaders(ctx context.Context, projectID string, headers *domain.APILogHeaders) error {
+	if !domain.IsEncrypted(headers.RequestHeaders) && !domain.IsEncrypted(headers.ResponseHeaders) {
+		return nil
+	}
+	key, err := k.dataKey(ctx, projectID, false)
+	if err != nil {
+		return err
+	}
+
+	for field, value := range map[string]*map[string]any{
+		"request_headers":  &headers.RequestHeaders,
+		"response_headers": &headers.ResponseHeaders,
+	} {
+		if !domain.IsEncrypted(*value) {
+			continue
+		}
+		var plaintext map[string]any
+		if err := open(key, projectID, headers.LogID, field, *value, &plaintext); err != nil {
+			return err
+		}
+		*value = plaintext
+	}
+	return nil
+}
+
+// DecryptBody implements output.LogDecrypter.
+func (k *Keys) DecryptBody(ctx context.Context, projectID string, body *domain.APILogBody) error {
+	if !domain.IsEncrypted(body.RequestBody) && !domain.IsEncrypted(body.ResponseBody) {
+		return nil
+	}
+	key, err := k.dataKey(ctx, projectID, false)
+	if err != nil {
+		return err
+	}
+
+	for field, value := range map[string]*any{
+		"request_body":  &body.RequestBody,
+		"response_body": &body.ResponseBody,
+	} {
+		if !domain.IsEncrypted(*value) {
+			continue
+		}
+		var plaintext any
+		if err := open(key, projectID, body.LogID, field, (*value).(map[string]any), &plaintext); err != nil {
+			return err
+		}
+		*value = plaintext
+	}
+	return nil
+}
+
+// Rewrap wraps every data key that is not wrapped by the active master key
+// with it, returning how many keys were re-wrapped. The values the keys
+// encrypt are left untouched, so a master key can be retired as soon as
+// Rewrap has run with its successor active.
+func (k *Keys) Rewrap(ctx context.Context) (int, error) {
+	keys, err := k.repo.List(ctx)
+	if err != nil {
+		return 0, err
+	}
+
+	rewrapped := 0
+	for _, stored := range keys {
+		if stored.MasterKeyID == k.keyring.ActiveKeyID() {
+			continue
+		}
+
+		dataKey, err := k.keyring.Unwrap(stored.MasterKeyID, stored.WrappedKey)
+		if err != nil {
+			return rewrapped, fmt.Errorf("unwrap data key of project %s: %w", stored.ProjectID, err)
+		}
+		if stored.MasterKeyID, stored.WrappedKey, err = k.keyring.Wrap(dataKey); err != nil {
+			return rewrapped, err
+		}
+		stored.UpdatedAt = time.Now()
+		if err := k.repo.Rewrap(ctx, stored); err != nil {
+			return rewrapped, err
+		}
+		rewrapped++
+	}
+	return rewrapped, nil
+}
+
+// dataKey returns a project's unwrapped data key. When create is set, a
+// project without a key gets one; otherwise it is an error.
+func (k *Keys) dataKey(ctx context.Context, projectID string, create bool) ([]byte, error) {
+	k.mu.Lock()
+	key, ok := k.cache[projectID]
+	k.mu.Unlock()
+	if ok {
+		return key, nil
+	}
+
+	stored, err := k.repo.Find(ctx, projectID)
+	if err == domain.ErrDataKeyNotFound && create {
+		stored, err = k.createDataKey(ctx, projectID)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("data key of project %s: %w", projectID, err)
+	}
+
+	key, err = k.keyring.Unwrap(stored.MasterKeyID, stored.WrappedKey)
+	if err != nil {
+		return nil, fmt.Errorf("data key of project %s: %w", projectID, err)
+	}
+
+	k.mu.Lock()
+	k.cache[projectID] = key
+	k.mu.Unlock()
+	return key, nil
+}
+
+// createDataKey generates and stores a project's data key. If another
+// instance stored one first, that key is used instead.
+func (k *Keys) createDataKey(ctx context.Context, projectID string) (*domain.DataKey, error) {
+	dataKey, err := envelope.NewDataKey()
+	if err != nil {
+		return nil, err
+	}
+
+	now := time.Now()
+	stored := &domain.DataKey{ProjectID: projectID, CreatedAt: now, UpdatedAt: now}
+	if stored.MasterKeyID, stored.WrappedKey, err = k.keyring.Wrap(dataKey); err != nil {
+		return nil, err
+	}
+
+	err = k.repo.Create(ctx, stored)
+	if err == domain.ErrDuplicateDataKey {
+		return k.repo.Find(ctx, projectID)
+	}
+	if err != nil {
+		return nil, err
+	}
+	return stored, nil
+}
+
+// seal encrypts a header map or body into the object it is stored as. The
+// ciphertext is bound to the project, log and field, so it cannot be passed
+// off as another log's.
+func seal(key []byte, projectID, logID, field string, value any) (map[string]any, error) {
+	plaintext, err := json.Marshal(value)
+	if err != nil {
+		return nil, fmt.Errorf("encode %s: %w", field, err)
+	}
+
+	sealed, err := envelope.Seal(key, plaintext, additionalData(projectID, logID, field))
+	if err != nil {
+		return nil, err
+	}
+	return map[string]any{
+		domain.EncryptedField: formatVersion + ":" + base64.StdEncoding.EncodeToString(sealed),
+	}, nil
+}
+
+// open decrypts a value sealed by seal into dst
+func open(key []byte, projectID, logID, field string, value map[string]any, dst any) error {
+	version, encoded, _ := strings.Cut(value[domain.EncryptedField].(string), ":")
+	if version != formatVersion {
+		return fmt.Errorf("%s of log %s: unsupported encryption format %q", field, logID, version)
+	}
+	sealed, err := base64.StdEncoding.DecodeString(encoded)
+	if err != nil {
+		return fmt.Errorf("%s of log %s: %w", field, logID, err)
+	}
+
+	plaintext, err := envelope.Open(key, sealed, additionalData(projectID, logID, field))
+	if err != nil {
+		return fmt.Errorf("%s of log %s: %w", field, logID, err)
+	}
+	return json.Unmarshal(plaintext, dst)
+}
+
+func additionalData(projectID, logID, field string) []byte {
+	return []byte(projectID + "\x00" + logID + "\x00" + field)
+}
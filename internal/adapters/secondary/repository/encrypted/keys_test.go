@@ -0,0 +1,203 @@
+package encrypted
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"reflect"
+	"testing"
+
+	"github.com/spidey52/api-logs/internal/adapters/secondary/repository/inmemory"
+	"github.com/spidey52/api-logs/internal/domain"
+	"github.com/spidey52/api-logs/internal/ports/output"
+	"github.com/spidey52/api-logs/pkg/envelope"
+)
+
+// newKeyring returns a keyring of the named master keys, the first active
+func newKeyring(t *testing.T, ids ...string) *envelope.Keyring {
+	t.Helper()
+	keys := make([]envelope.MasterKey, len(ids))
+	for i, id := range ids {
+		keys[i] = envelope.MasterKey{ID: id, Key: bytes.Repeat([]byte(id[len(id)-1:]), envelope.KeySize)}
+	}
+	keyring, err := envelope.NewKeyring(keys...)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return keyring
+}
+
+func TestSealedValueBoundToProjectLogAndField(t *testing.T) {
+	ctx := context.Background()
+	keys := NewKeys(inmemory.NewDataKeyRepository(), newKeyring(t, "k1"))
+	key, err := keys.dataKey(ctx, "p1", true)
+	if err != nil {
+		t.Fatal(err)
+	}
+	sealed, err := seal(key, "p1", "log-1", "request_body", map[string]any{"card": "4111"})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var plaintext map[string]any
+	if err := open(key, "p1", "log-1", "request_body", sealed, &plaintext); err != nil {
+		t.Fatal(err)
+	}
+	if plaintext["card"] != "4111" {
+		t.Fatalf("want the value opened, got %v", plaintext)
+	}
+
+	tests := []struct {
+		name                    string
+		projectID, logID, field string
+	}{
+		{"other log", "p1", "log-2", "request_body"},
+		{"other field", "p1", "log-1", "response_body"},
+		{"other project", "p2", "log-1", "request_body"},
+	}
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			if err := open(key, tc.projectID, tc.logID, tc.field, sealed, &plaintext); !errors.Is(err, envelope.ErrDecrypt) {
+				t.Fatalf("want ErrDecrypt, got %v", err)
+			}
+		})
+	}
+
+	// Another project's data key cannot open it either
+	other, err := keys.dataKey(ctx, "p2", true)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := open(other, "p1", "log-1", "request_body", sealed, &plaintext); !errors.Is(err, envelope.ErrDecrypt) {
+		t.Fatalf("want ErrDecrypt under another project's key, got %v", err)
+	}
+}
+
+func TestHeadersStoredEncrypted(t *testing.T) {
+	ctx := context.Background()
+	keys := NewKeys(inmemory.NewDataKeyRepository(), newKeyring(t, "k1"))
+	stored := inmemory.NewHeadersRepository()
+	repo := NewHeadersRepository(stored, keys)
+
+	headers := map[string]any{"Authorization": "Bearer token"}
+	if err := repo.Create(ctx, &domain.APILogHeaders{ID: "h1", LogID: "log-1", ProjectID: "p1", RequestHeaders: headers}); err != nil {
+		t.Fatal(err)
+	}
+	if err := repo.Create(ctx, &domain.APILogHeaders{ID: "h2", LogID: "log-2", RequestHeaders: headers}); err != errNoProject {
+		t.Fatalf("want errNoProject, got %v", err)
+	}
+
+	got, err := stored.FindByLogID(ctx, "log-1")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !domain.IsEncrypted(got.RequestHeaders) {
+		t.Fatalf("want the headers stored encrypted, got %v", got.RequestHeaders)
+	}
+	if err := keys.DecryptHeaders(ctx, "p1", got); err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(got.RequestHeaders, headers) {
+		t.Fatalf("want the headers decrypted, got %v", got.RequestHeaders)
+	}
+}
+
+func TestRewrap(t *testing.T) {
+	ctx := context.Background()
+	repo := inmemory.NewDataKeyRepository()
+	before := NewKeys(repo, newKeyring(t, "k1"))
+	sealed := make(map[string]map[string]any)
+	for _, projectID := range []string{"p1", "p2"} {
+		key, err := before.dataKey(ctx, projectID, true)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if sealed[projectID], err = seal(key, projectID, "log-1", "request_body", "body of "+projectID); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	rotated := NewKeys(repo, newKeyring(t, "k2", "k1"))
+	if _, err := rotated.dataKey(ctx, "p3", true); err != nil {
+		t.Fatal(err)
+	}
+	current, err := repo.Find(ctx, "p3")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	rewrapped, err := rotated.Rewrap(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if rewrapped != 2 {
+		t.Fatalf("want the 2 keys under k1 re-wrapped, got %d", rewrapped)
+	}
+	stored, err := repo.List(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, key := range stored {
+		if key.MasterKeyID != "k2" {
+			t.Fatalf("project %s: want its key wrapped by k2, got %s", key.ProjectID, key.MasterKeyID)
+		}
+	}
+	if unchanged, err := repo.Find(ctx, "p3"); err != nil || !bytes.Equal(unchanged.WrappedKey, current.WrappedKey) {
+		t.Fatalf("want the key already under k2 left alone, got %v", err)
+	}
+	if rewrapped, err := rotated.Rewrap(ctx); err != nil || rewrapped != 0 {
+		t.Fatalf("want nothing left to re-wrap, got %d, %v", rewrapped, err)
+	}
+
+	// k1 can be retired: the values still open with k2 alone
+	retired := NewKeys(repo, newKeyring(t, "k2"))
+	for projectID, value := range sealed {
+		key, err := retired.dataKey(ctx, projectID, false)
+		if err != nil {
+			t.Fatal(err)
+		}
+		var plaintext string
+		if err := open(key, projectID, "log-1", "request_body", value, &plaintext); err != nil {
+			t.Fatal(err)
+		}
+		if plaintext != "body of "+projectID {
+			t.Fatalf("want the value opened after the rewrap, got %q", plaintext)
+		}
+	}
+}
+
+// racingDataKeys reports a project's data key missing once, as if another
+// instance stored it between Find and Create
+type racingDataKeys struct {
+	output.DataKeyRepository
+	raced bool
+}
+
+func (r *racingDataKeys) Find(ctx context.Context, projectID string) (*domain.DataKey, error) {
+	if !r.raced {
+		r.raced = true
+		return nil, domain.ErrDataKeyNotFound
+	}
+	return r.DataKeyRepository.Find(ctx, projectID)
+}
+
+func TestCreateDataKeyUsesKeyStoredFirst(t *testing.T) {
+	ctx := context.Background()
+	repo := inmemory.NewDataKeyRepository()
+	keyring := newKeyring(t, "k1")
+
+	first := NewKeys(repo, keyring)
+	key, err := first.dataKey(ctx, "p1", true)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	second := NewKeys(&racingDataKeys{DataKeyRepository: repo}, keyring)
+	got, err := second.dataKey(ctx, "p1", true)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !bytes.Equal(got, key) {
+		t.Fatal("want the data key stored first")
+	}
+}
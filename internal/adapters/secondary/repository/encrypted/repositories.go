@@ -0,0 +1,128 @@
+package encrypted
+
+import (
+	"context"
+	"errors"
+
+	"github.com/spidey52/api-logs/internal/domain"
+	"github.com/spidey52/api-logs/internal/ports/output"
+)
+
+// errNoProject is returned when headers or a body are stored without the
+// project whose key encrypts them
+var errNoProject = errors.New("encrypted: project_id is required to encrypt")
+
+// headersRepository encrypts the header maps an APILogHeadersRepository
+// stores. Reads return them still encrypted.
+type headersRepository struct {
+	output.APILogHeadersRepository
+	keys *Keys
+}
+
+// NewHeadersRepository wraps a headers repository so header maps are stored
+// encrypted
+func NewHeadersRepository(repo output.APILogHeadersRepository, keys *Keys) output.APILogHeadersRepository {
+	return &headersRepository{APILogHeadersRepository: repo, keys: keys}
+}
+
+// Create encrypts and stores headers for a log
+func (r *headersRepository) Create(ctx context.Context, headers *domain.APILogHeaders) error {
+	sealed, err := r.seal(ctx, headers)
+	if err != nil {
+		return err
+	}
+	return r.APILogHeadersRepository.Create(ctx, sealed)
+}
+
+// CreateMany encrypts and stores headers for multiple logs
+func (r *headersRepository) CreateMany(ctx context.Context, headers []*domain.APILogHeaders) error {
+	sealed := make([]*domain.APILogHeaders, len(headers))
+	for i, h := range headers {
+		var err error
+		if sealed[i], err = r.seal(ctx, h); err != nil {
+			return err
+		}
+	}
+	return r.APILogHeadersRepository.CreateMany(ctx, sealed)
+}
+
+// seal returns a copy of headers with their maps encrypted
+func (r *headersRepository) seal(ctx context.Context, headers *domain.APILogHeaders) (*domain.APILogHeaders, error) {
+	if headers.ProjectID == "" {
+		return nil, errNoProject
+	}
+	key, err := r.keys.dataKey(ctx, headers.ProjectID, true)
+	if err != nil {
+		return nil, err
+	}
+
+	sealed := *headers
+	if len(headers.RequestHeaders) > 0 {
+		if sealed.RequestHeaders, err = seal(key, headers.ProjectID, headers.LogID, "request_headers", headers.RequestHeaders); err != nil {
+			return nil, err
+		}
+	}
+	if len(headers.ResponseHeaders) > 0 {
+		if sealed.ResponseHeaders, err = seal(key, headers.ProjectID, headers.LogID, "response_headers", headers.ResponseHeaders); err != nil {
+			return nil, err
+		}
+	}
+	return &sealed, nil
+}
+
+// bodyRepository encrypts the bodies an APILogBodyRepository stores. Reads
+// return them still encrypted.
+type bodyRepository struct {
+	output.APILogBodyRepository
+	keys *Keys
+}
+
+// NewBodyRepository wraps a body repository so bodies are stored encrypted
+func NewBodyRepository(repo output.APILogBodyRepository, keys *Keys) output.APILogBodyRepository {
+	return &bodyRepository{APILogBodyRepository: repo, keys: keys}
+}
+
+// Create encrypts and stores the bodies of a log
+func (r *bodyRepository) Create(ctx context.Context, body *domain.APILogBody) error {
+	sealed, err := r.seal(ctx, body)
+	if err != nil {
+		return err
+	}
+	return r.APILogBodyRepository.Create(ctx, sealed)
+}
+
+// CreateMany encrypts and stores the bodies of multiple logs
+func (r *bodyRepository) CreateMany(ctx context.Context, bodies []*domain.APILogBody) error {
+	sealed := make([]*domain.APILogBody, len(bodies))
+	for i, b := range bodies {
+		var err error
+		if sealed[i], err = r.seal(ctx, b); err != nil {
+			return err
+		}
+	}
+	return r.APILogBodyRepository.CreateMany(ctx, sealed)
+}
+
+// seal returns a copy of body with its request and response bodies encrypted
+func (r *bodyRepository) seal(ctx context.Context, body *domain.APILogBody) (*domain.APILogBody, error) {
+	if body.ProjectID == "" {
+		return nil, errNoProject
+	}
+	key, err := r.keys.dataKey(ctx, body.ProjectID, true)
+	if err != nil {
+		return nil, err
+	}
+
+	sealed := *body
+	if body.RequestBody != nil {
+		if sealed.RequestBody, err = seal(key, body.ProjectID, body.LogID, "request_body", body.RequestBody); err != nil {
+			return nil, err
+		}
+	}
+	if body.ResponseBody != nil {
+		if sealed.ResponseBody, err = seal(key, body.ProjectID, body.LogID, "response_body", body.ResponseBody); err != nil {
+			return nil, err
+		}
+	}
+	return &sealed, nil
+}
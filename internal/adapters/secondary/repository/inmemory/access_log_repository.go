@@ -0,0 +1,182 @@
+package inmemory
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/spidey52/api-logs/internal/domain"
+	"github.com/spidey52/api-logs/internal/ports/output"
+)
+
+// accessLogRepository implements AccessLogRepository interface
+type accessLogRepository struct {
+	mu   sync.RWMutex
+	logs map[string]*domain.AccessLog
+}
+
+// NewAccessLogRepository creates a new in-memory access log repository
+func NewAccessLogRepository() output.AccessLogRepository {
+	return &accessLogRepository{
+		logs: make(map[string]*domain.AccessLog),
+	}
+}
+
+func copyAccessLog(log *domain.AccessLog) *domain.AccessLog {
+	c := *log
+	c.Metadata = copyAnyMap(log.Metadata)
+	return &c
+}
+
+func matchesAccessLogFilter(log *domain.AccessLog, filter domain.AccessLogFilter) bool {
+	if filter.ProjectID != "" && log.ProjectID != filter.ProjectID {
+		return false
+	}
+	if filter.ActorID != "" && log.ActorID != filter.ActorID {
+		return false
+	}
+	if filter.ActorType != nil && log.ActorType != *filter.ActorType {
+		return false
+	}
+	if filter.Action != "" && log.Action != filter.Action {
+		return false
+	}
+	if filter.ResourceType != "" && log.ResourceType != filter.ResourceType {
+		return false
+	}
+	if filter.ResourceID != "" && log.ResourceID != filter.ResourceID {
+		return false
+	}
+	if filter.Outcome != nil && log.Outcome != *filter.Outcome {
+		return false
+	}
+	if filter.Search != "" && !containsFold(log.Message, filter.Search) {
+		return false
+	}
+	for key, value := range filter.Metadata {
+		actual, ok := log.Metadata[key]
+		// Compare formatted values so 1 and 1.0 from JSON decoding still match
+		if !ok || fmt.Sprint(actual) != fmt.Sprint(value) {
+			return false
+		}
+	}
+	if filter.FromDate != nil && log.Timestamp.Before(*filter.FromDate) {
+		return false
+	}
+	if filter.ToDate != nil && log.Timestamp.After(*filter.ToDate) {
+		return false
+	}
+	return true
+}
+
+// Create stores a new access log
+func (r *accessLogRepository) Create(ctx context.Context, log *domain.AccessLog) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	r.logs[log.ID] = copyAccessLog(log)
+	return nil
+}
+
+// CreateMany stores multiple access logs
+func (r *accessLogRepository) CreateMany(ctx context.Context, logs []*domain.AccessLog) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	for _, log := range logs {
+		r.logs[log.ID] = copyAccessLog(log)
+	}
+	return nil
+}
+
+// FindByID retrieves an access log by ID
+func (r *accessLogRepository) FindByID(ctx context.Context, id string) (*domain.AccessLog, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	log, ok := r.logs[id]
+	if !ok {
+		return nil, domain.ErrAccessLogNotFound
+	}
+	return copyAccessLog(log), nil
+}
+
+// FindByFilter retrieves access logs based on filter criteria, newest first
+func (r *accessLogRepository) FindByFilter(ctx context.Context, filter domain.AccessLogFilter) ([]*domain.AccessLog, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	logs := []*domain.AccessLog{}
+	for _, log := range r.logs {
+		if matchesAccessLogFilter(log, filter) {
+			logs = append(logs, copyAccessLog(log))
+		}
+	}
+
+	sort.SliceStable(logs, func(i, j int) bool {
+		return logs[i].Timestamp.After(logs[j].Timestamp)
+	})
+
+	start, end := paginate(len(logs), filter.SharedFilter)
+	return logs[start:end], nil
+}
+
+// CountByFilter counts access logs matching the filter criteria
+func (r *accessLogRepository) CountByFilter(ctx context.Context, filter domain.AccessLogFilter) (int64, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	var count int64
+	for _, log := range r.logs {
+		if matchesAccessLogFilter(log, filter) {
+			count++
+		}
+	}
+	return count, nil
+}
+
+// DeleteByID removes an access log by ID
+func (r *accessLogRepository) DeleteByID(ctx context.Context, id string) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	delete(r.logs, id)
+	return nil
+}
+
+// DeleteByFilter removes access logs matching the filter and returns how many were deleted
+func (r *accessLogRepository) DeleteByFilter(ctx context.Context, filter domain.AccessLogFilter) (int64, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	var deleted int64
+	for id, log := range r.logs {
+		if matchesAccessLogFilter(log, filter) {
+			delete(r.logs, id)
+			deleted++
+		}
+	}
+	return deleted, nil
+}
+
+func accessLogStamp(log *domain.AccessLog) (string, time.Time) {
+	return log.ProjectID, log.Timestamp
+}
+
+// DeleteOlderThan removes a batch of a project's access logs stored before a time
+func (r *accessLogRepository) DeleteOlderThan(ctx context.Context, projectID string, before time.Time, limit int) (int64, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	return deleteOlderThan(r.logs, projectID, before, limit, accessLogStamp), nil
+}
+
+// VolumeOlderThan measures a project's access logs stored before a time
+func (r *accessLogRepository) VolumeOlderThan(ctx context.Context, projectID string, before time.Time) (domain.DataVolume, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	return volumeOlderThan(r.logs, projectID, before, accessLogStamp), nil
+}
@@ -0,0 +1,181 @@
+package inmemory
+
+import (
+	"context"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/spidey52/api-logs/internal/domain"
+	"github.com/spidey52/api-logs/internal/ports/output"
+)
+
+// accountRepository implements AccountRepository interface
+type accountRepository struct {
+	mu       sync.RWMutex
+	accounts map[string]*domain.Account
+}
+
+// NewAccountRepository creates a new in-memory account repository
+func NewAccountRepository() output.AccountRepository {
+	return &accountRepository{
+		accounts: make(map[string]*domain.Account),
+	}
+}
+
+func copyAccount(account *domain.Account) *domain.Account {
+	c := *account
+	if account.LastLoginAt != nil {
+		lastLogin := *account.LastLoginAt
+		c.LastLoginAt = &lastLogin
+	}
+	return &c
+}
+
+// Create stores a new account
+func (r *accountRepository) Create(ctx context.Context, account *domain.Account) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	for _, existing := range r.accounts {
+		if existing.Username == account.Username {
+			return domain.ErrDuplicateUsername
+		}
+	}
+
+	r.accounts[account.ID] = copyAccount(account)
+	return nil
+}
+
+// FindByID retrieves an account by ID
+func (r *accountRepository) FindByID(ctx context.Context, id string) (*domain.Account, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	account, ok := r.accounts[id]
+	if !ok {
+		return nil, domain.ErrAccountNotFound
+	}
+	return copyAccount(account), nil
+}
+
+// FindByUsername retrieves an account by its normalized username
+func (r *accountRepository) FindByUsername(ctx context.Context, username string) (*domain.Account, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	for _, account := range r.accounts {
+		if account.Username == username {
+			return copyAccount(account), nil
+		}
+	}
+	return nil, domain.ErrAccountNotFound
+}
+
+// Update updates an existing account
+func (r *accountRepository) Update(ctx context.Context, account *domain.Account) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if _, ok := r.accounts[account.ID]; !ok {
+		return domain.ErrAccountNotFound
+	}
+	for _, existing := range r.accounts {
+		if existing.ID != account.ID && existing.Username == account.Username {
+			return domain.ErrDuplicateUsername
+		}
+	}
+
+	r.accounts[account.ID] = copyAccount(account)
+	return nil
+}
+
+// List retrieves all accounts ordered by username
+func (r *accountRepository) List(ctx context.Context) ([]*domain.Account, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	accounts := make([]*domain.Account, 0, len(r.accounts))
+	for _, account := range r.accounts {
+		accounts = append(accounts, copyAccount(account))
+	}
+
+	sort.Slice(accounts, func(i, j int) bool {
+		return accounts[i].Username < accounts[j].Username
+	})
+	return accounts, nil
+}
+
+// sessionRepository implements SessionRepository interface
+type sessionRepository struct {
+	mu       sync.RWMutex
+	sessions map[string]*domain.Session
+}
+
+// NewSessionRepository creates a new in-memory session repository
+func NewSessionRepository() output.SessionRepository {
+	return &sessionRepository{
+		sessions: make(map[string]*domain.Session),
+	}
+}
+
+// Create stores a new session
+func (r *sessionRepository) Create(ctx context.Context, session *domain.Session) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	c := *session
+	r.sessions[session.ID] = &c
+	return nil
+}
+
+// FindByTokenHash retrieves a session by the hash of its bearer token
+func (r *sessionRepository) FindByTokenHash(ctx context.Context, tokenHash string) (*domain.Session, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	for _, session := range r.sessions {
+		if session.TokenHash == tokenHash {
+			c := *session
+			return &c, nil
+		}
+	}
+	return nil, domain.ErrSessionNotFound
+}
+
+// Delete removes a session by ID
+func (r *sessionRepository) Delete(ctx context.Context, id string) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	delete(r.sessions, id)
+	return nil
+}
+
+// DeleteByAccountID removes every session of an account
+func (r *sessionRepository) DeleteByAccountID(ctx context.Context, accountID string) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	for id, session := range r.sessions {
+		if session.AccountID == accountID {
+			delete(r.sessions, id)
+		}
+	}
+	return nil
+}
+
+// DeleteExpired removes sessions that expired before the given time
+func (r *sessionRepository) DeleteExpired(ctx context.Context, before time.Time) (int64, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	var deleted int64
+	for id, session := range r.sessions {
+		if session.ExpiresAt.Before(before) {
+			delete(r.sessions, id)
+			deleted++
+		}
+	}
+	return deleted, nil
+}
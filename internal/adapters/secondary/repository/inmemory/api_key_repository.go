@@ -0,0 +1,167 @@
+package inmemory
+
+import (
+	"context"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/spidey52/api-logs/internal/domain"
+	"github.com/spidey52/api-logs/internal/ports/output"
+)
+
+// apiKeyRepository implements APIKeyRepository interface
+type apiKeyRepository struct {
+	mu   sync.RWMutex
+	keys map[string]*domain.APIKey
+}
+
+// NewAPIKeyRepository creates a new in-memory API key repository
+func NewAPIKeyRepository() output.APIKeyRepository {
+	return &apiKeyRepository{
+		keys: make(map[string]*domain.APIKey),
+	}
+}
+
+func copyAPIKey(key *domain.APIKey) *domain.APIKey {
+	c := *key
+	c.Scopes = append([]domain.APIKeyScope(nil), key.Scopes...)
+	c.ExpiresAt = copyTime(key.ExpiresAt)
+	c.LastUsedAt = copyTime(key.LastUsedAt)
+	c.RevokedAt = copyTime(key.RevokedAt)
+	return &c
+}
+
+// Create stores a new API key
+func (r *apiKeyRepository) Create(ctx context.Context, key *domain.APIKey) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	for _, existing := range r.keys {
+		if existing.KeyHash == key.KeyHash {
+			return domain.ErrDuplicateAPIKey
+		}
+	}
+
+	stored := copyAPIKey(key)
+	stored.Key = ""
+	r.keys[key.ID] = stored
+	return nil
+}
+
+// FindByID retrieves an API key by ID
+func (r *apiKeyRepository) FindByID(ctx context.Context, id string) (*domain.APIKey, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	key, ok := r.keys[id]
+	if !ok {
+		return nil, domain.ErrAPIKeyNotFound
+	}
+	return copyAPIKey(key), nil
+}
+
+// FindByKey retrieves an API key by the hash of its secret
+func (r *apiKeyRepository) FindByKey(ctx context.Context, keyHash string) (*domain.APIKey, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	for _, existing := range r.keys {
+		if existing.KeyHash == keyHash {
+			return copyAPIKey(existing), nil
+		}
+	}
+	return nil, domain.ErrAPIKeyNotFound
+}
+
+// ListByProject retrieves a project's API keys, newest first
+func (r *apiKeyRepository) ListByProject(ctx context.Context, projectID string) ([]*domain.APIKey, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	keys := []*domain.APIKey{}
+	for _, key := range r.keys {
+		if key.ProjectID == projectID {
+			keys = append(keys, copyAPIKey(key))
+		}
+	}
+
+	sort.Slice(keys, func(i, j int) bool {
+		if keys[i].CreatedAt.Equal(keys[j].CreatedAt) {
+			return keys[i].ID < keys[j].ID
+		}
+		return keys[i].CreatedAt.After(keys[j].CreatedAt)
+	})
+	return keys, nil
+}
+
+// Revoke marks an API key as revoked at the given time
+func (r *apiKeyRepository) Revoke(ctx context.Context, id string, revokedAt time.Time) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	key, ok := r.keys[id]
+	if !ok {
+		return domain.ErrAPIKeyNotFound
+	}
+	if key.RevokedAt == nil {
+		key.RevokedAt = &revokedAt
+	}
+	return nil
+}
+
+// RevokeExpired revokes every key that expired at or before now
+func (r *apiKeyRepository) RevokeExpired(ctx context.Context, now time.Time) (int, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	revoked := 0
+	for _, key := range r.keys {
+		if key.RevokedAt == nil && key.IsExpired(now) {
+			key.RevokedAt = copyTime(key.ExpiresAt)
+			revoked++
+		}
+	}
+	return revoked, nil
+}
+
+// TouchLastUsed records when an API key was last used
+func (r *apiKeyRepository) TouchLastUsed(ctx context.Context, id string, usedAt time.Time) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if key, ok := r.keys[id]; ok {
+		key.LastUsedAt = &usedAt
+	}
+	return nil
+}
+
+// DeleteByProject removes every API key of a project
+func (r *apiKeyRepository) DeleteByProject(ctx context.Context, projectID string) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	for id, key := range r.keys {
+		if key.ProjectID == projectID {
+			delete(r.keys, id)
+		}
+	}
+	return nil
+}
+
+// HashPlaintextKeys hashes keys stored without a prefix
+func (r *apiKeyRepository) HashPlaintextKeys(ctx context.Context, hash func(key string) string) (int, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	converted := 0
+	for _, key := range r.keys {
+		if key.Prefix != "" {
+			continue
+		}
+		key.Prefix = domain.KeyPrefix(key.KeyHash)
+		key.KeyHash = hash(key.KeyHash)
+		converted++
+	}
+	return converted, nil
+}
@@ -0,0 +1,278 @@
+package inmemory
+
+import (
+	"context"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/spidey52/api-logs/internal/domain"
+	"github.com/spidey52/api-logs/internal/ports/output"
+)
+
+// apiLogRepository implements APILogRepository interface
+type apiLogRepository struct {
+	mu   sync.RWMutex
+	logs map[string]*domain.APILog
+}
+
+// NewAPILogRepository creates a new in-memory API log repository
+func NewAPILogRepository() output.APILogRepository {
+	return &apiLogRepository{
+		logs: make(map[string]*domain.APILog),
+	}
+}
+
+func copyAPILog(log *domain.APILog) *domain.APILog {
+	c := *log
+	c.Params = copyStringMap(log.Params)
+	c.QueryParams = copyStringMap(log.QueryParams)
+	c.User = nil
+	return &c
+}
+
+// filtered returns copies of matching logs sorted by timestamp descending
+func (r *apiLogRepository) filtered(filter domain.LogFilter) []*domain.APILog {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	logs := []*domain.APILog{}
+	for _, log := range r.logs {
+		if filter.Matches(log) {
+			logs = append(logs, copyAPILog(log))
+		}
+	}
+
+	sort.SliceStable(logs, func(i, j int) bool {
+		return logs[i].Timestamp.After(logs[j].Timestamp)
+	})
+	return logs
+}
+
+// statsLogs returns today's logs for a project/environment
+func (r *apiLogRepository) statsLogs(projectID string, environment domain.Environment) []*domain.APILog {
+	from := startOfDay(time.Now())
+	return r.filtered(domain.LogFilter{
+		ProjectID:   projectID,
+		Environment: environment,
+		FromDate:    &from,
+	})
+}
+
+// Create stores a new API log entry
+func (r *apiLogRepository) Create(ctx context.Context, log *domain.APILog) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	r.logs[log.ID] = copyAPILog(log)
+	return nil
+}
+
+// CreateMany stores multiple API log entries
+func (r *apiLogRepository) CreateMany(ctx context.Context, logs []*domain.APILog) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	for _, log := range logs {
+		if _, ok := r.logs[log.ID]; ok {
+			continue
+		}
+		r.logs[log.ID] = copyAPILog(log)
+	}
+	return nil
+}
+
+// FindByID retrieves a log by ID
+func (r *apiLogRepository) FindByID(ctx context.Context, id string) (*domain.APILog, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	log, ok := r.logs[id]
+	if !ok {
+		return nil, domain.ErrLogNotFound
+	}
+	return copyAPILog(log), nil
+}
+
+// FindByFilter retrieves logs based on filter criteria
+func (r *apiLogRepository) FindByFilter(ctx context.Context, filter domain.LogFilter) ([]*domain.APILog, error) {
+	logs := r.filtered(filter)
+	start, end := paginate(len(logs), filter.SharedFilter)
+	return logs[start:end], nil
+}
+
+// Delete removes a log by ID
+func (r *apiLogRepository) Delete(ctx context.Context, id string) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if _, ok := r.logs[id]; !ok {
+		return domain.ErrLogNotFound
+	}
+	delete(r.logs, id)
+	return nil
+}
+
+// DeleteBatch removes the logs with the given IDs
+func (r *apiLogRepository) DeleteBatch(ctx context.Context, ids []string) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	for _, id := range ids {
+		delete(r.logs, id)
+	}
+	return nil
+}
+
+// CountByProject counts today's logs for a specific project
+func (r *apiLogRepository) CountByProject(ctx context.Context, projectID string, environment domain.Environment) (int64, error) {
+	return int64(len(r.statsLogs(projectID, environment))), nil
+}
+
+// CountByFilter counts logs matching the filter criteria
+func (r *apiLogRepository) CountByFilter(ctx context.Context, filter domain.LogFilter) (int64, error) {
+	return int64(len(r.filtered(filter))), nil
+}
+
+// GetStatusCodeDistribution returns distribution of status codes
+func (r *apiLogRepository) GetStatusCodeDistribution(ctx context.Context, projectID string, environment domain.Environment) (map[int]int64, error) {
+	distribution := make(map[int]int64)
+	for _, log := range r.statsLogs(projectID, environment) {
+		distribution[log.StatusCode]++
+	}
+	return distribution, nil
+}
+
+// GetAverageResponseTime returns average response time
+func (r *apiLogRepository) GetAverageResponseTime(ctx context.Context, projectID string, environment domain.Environment) (float64, error) {
+	logs := r.statsLogs(projectID, environment)
+	if len(logs) == 0 {
+		return 0, nil
+	}
+
+	var total int64
+	for _, log := range logs {
+		total += log.ResponseTime
+	}
+	return float64(total) / float64(len(logs)), nil
+}
+
+// GetTimeSeriesStats returns request count grouped by hour
+func (r *apiLogRepository) GetTimeSeriesStats(ctx context.Context, projectID string, environment domain.Environment) ([]map[string]interface{}, error) {
+	counts := make(map[time.Time]int64)
+	for _, log := range r.statsLogs(projectID, environment) {
+		counts[log.Timestamp.UTC().Truncate(time.Hour)]++
+	}
+
+	buckets := make([]time.Time, 0, len(counts))
+	for bucket := range counts {
+		buckets = append(buckets, bucket)
+	}
+	sort.Slice(buckets, func(i, j int) bool { return buckets[i].Before(buckets[j]) })
+	if len(buckets) > 24 {
+		buckets = buckets[:24]
+	}
+
+	var results []map[string]interface{}
+	for _, bucket := range buckets {
+		results = append(results, map[string]interface{}{
+			"timestamp": bucket,
+			"count":     counts[bucket],
+		})
+	}
+	return results, nil
+}
+
+// GetTopEndpoints returns top N most requested endpoints
+func (r *apiLogRepository) GetTopEndpoints(ctx context.Context, projectID string, environment domain.Environment, limit int) ([]map[string]interface{}, error) {
+	type endpoint struct {
+		path      string
+		method    domain.HTTPMethod
+		count     int64
+		totalTime int64
+	}
+
+	byPath := make(map[string]*endpoint)
+	order := []string{}
+	for _, log := range r.statsLogs(projectID, environment) {
+		e, ok := byPath[log.Path]
+		if !ok {
+			e = &endpoint{path: log.Path, method: log.Method}
+			byPath[log.Path] = e
+			order = append(order, log.Path)
+		}
+		e.count++
+		e.totalTime += log.ResponseTime
+	}
+
+	sort.SliceStable(order, func(i, j int) bool {
+		return byPath[order[i]].count > byPath[order[j]].count
+	})
+	if limit > 0 && len(order) > limit {
+		order = order[:limit]
+	}
+
+	var results []map[string]interface{}
+	for _, path := range order {
+		e := byPath[path]
+		results = append(results, map[string]interface{}{
+			"_id":               e.path,
+			"count":             e.count,
+			"method":            string(e.method),
+			"avg_response_time": float64(e.totalTime) / float64(e.count),
+		})
+	}
+	return results, nil
+}
+
+// GetMethodDistribution returns distribution of HTTP methods
+func (r *apiLogRepository) GetMethodDistribution(ctx context.Context, projectID string, environment domain.Environment) (map[string]int64, error) {
+	distribution := make(map[string]int64)
+	for _, log := range r.statsLogs(projectID, environment) {
+		distribution[string(log.Method)]++
+	}
+	return distribution, nil
+}
+
+// GetUniquePaths returns list of unique paths for autocomplete
+func (r *apiLogRepository) GetUniquePaths(ctx context.Context, projectID string, environment domain.Environment) ([]string, error) {
+	seen := make(map[string]bool)
+	paths := []string{}
+	for _, log := range r.statsLogs(projectID, environment) {
+		if !seen[log.Path] {
+			seen[log.Path] = true
+			paths = append(paths, log.Path)
+		}
+	}
+	sort.Strings(paths)
+	return paths, nil
+}
+
+func logStamp(log *domain.APILog) (string, time.Time) {
+	return log.ProjectID, log.Timestamp
+}
+
+// FindOlderThan retrieves a batch of a project's logs stored before a time,
+// oldest first
+func (r *apiLogRepository) FindOlderThan(ctx context.Context, projectID string, before time.Time, limit int) ([]*domain.APILog, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	return findOlderThan(r.logs, projectID, before, limit, logStamp, copyAPILog), nil
+}
+
+// DeleteOlderThan removes a batch of a project's logs stored before a time
+func (r *apiLogRepository) DeleteOlderThan(ctx context.Context, projectID string, before time.Time, limit int) (int64, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	return deleteOlderThan(r.logs, projectID, before, limit, logStamp), nil
+}
+
+// VolumeOlderThan measures a project's logs stored before a time
+func (r *apiLogRepository) VolumeOlderThan(ctx context.Context, projectID string, before time.Time) (domain.DataVolume, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	return volumeOlderThan(r.logs, projectID, before, logStamp), nil
+}
@@ -0,0 +1,130 @@
+package inmemory
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"github.com/spidey52/api-logs/internal/domain"
+	"github.com/spidey52/api-logs/internal/ports/output"
+)
+
+// bodyRepository implements APILogBodyRepository interface
+type bodyRepository struct {
+	mu      sync.RWMutex
+	byLogID map[string]*domain.APILogBody
+}
+
+// NewBodyRepository creates a new in-memory body repository
+func NewBodyRepository() output.APILogBodyRepository {
+	return &bodyRepository{
+		byLogID: make(map[string]*domain.APILogBody),
+	}
+}
+
+func copyBody(b *domain.APILogBody) *domain.APILogBody {
+	c := *b
+	return &c
+}
+
+// Create stores request/response bodies for a log
+func (r *bodyRepository) Create(ctx context.Context, body *domain.APILogBody) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	c := *body
+	r.byLogID[body.LogID] = &c
+	return nil
+}
+
+// CreateMany stores bodies for multiple logs
+func (r *bodyRepository) CreateMany(ctx context.Context, bodies []*domain.APILogBody) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	for _, body := range bodies {
+		if _, ok := r.byLogID[body.LogID]; ok {
+			continue
+		}
+		c := *body
+		r.byLogID[body.LogID] = &c
+	}
+	return nil
+}
+
+// FindByLogID retrieves body by log ID
+func (r *bodyRepository) FindByLogID(ctx context.Context, logID string) (*domain.APILogBody, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	body, ok := r.byLogID[logID]
+	if !ok {
+		return nil, domain.ErrBodyNotFound
+	}
+	c := *body
+	return &c, nil
+}
+
+// FindByLogIDs retrieves bodies for multiple log IDs
+func (r *bodyRepository) FindByLogIDs(ctx context.Context, logIDs []string) ([]*domain.APILogBody, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	var found []*domain.APILogBody
+	for _, logID := range logIDs {
+		if body, ok := r.byLogID[logID]; ok {
+			c := *body
+			found = append(found, &c)
+		}
+	}
+	return found, nil
+}
+
+// Delete removes body by log ID
+func (r *bodyRepository) Delete(ctx context.Context, logID string) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	delete(r.byLogID, logID)
+	return nil
+}
+
+// DeleteBatch removes bodies for multiple log IDs
+func (r *bodyRepository) DeleteBatch(ctx context.Context, logIDs []string) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	for _, logID := range logIDs {
+		delete(r.byLogID, logID)
+	}
+	return nil
+}
+
+func bodyStamp(b *domain.APILogBody) (string, time.Time) {
+	return b.ProjectID, b.CreatedAt
+}
+
+// FindOlderThan retrieves a batch of a project's bodies stored before a time,
+// oldest first
+func (r *bodyRepository) FindOlderThan(ctx context.Context, projectID string, before time.Time, limit int) ([]*domain.APILogBody, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	return findOlderThan(r.byLogID, projectID, before, limit, bodyStamp, copyBody), nil
+}
+
+// DeleteOlderThan removes a batch of a project's bodies stored before a time
+func (r *bodyRepository) DeleteOlderThan(ctx context.Context, projectID string, before time.Time, limit int) (int64, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	return deleteOlderThan(r.byLogID, projectID, before, limit, bodyStamp), nil
+}
+
+// VolumeOlderThan measures a project's bodies stored before a time
+func (r *bodyRepository) VolumeOlderThan(ctx context.Context, projectID string, before time.Time) (domain.DataVolume, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	return volumeOlderThan(r.byLogID, projectID, before, bodyStamp), nil
+}
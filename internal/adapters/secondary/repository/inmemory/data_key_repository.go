@@ -0,0 +1,99 @@
+package inmemory
+
+import (
+	"context"
+	"slices"
+	"sort"
+	"sync"
+
+	"github.com/spidey52/api-logs/internal/domain"
+	"github.com/spidey52/api-logs/internal/ports/output"
+)
+
+// dataKeyRepository implements DataKeyRepository interface
+type dataKeyRepository struct {
+	mu   sync.RWMutex
+	keys map[string]domain.DataKey
+}
+
+// NewDataKeyRepository creates a new in-memory data key repository
+func NewDataKeyRepository() output.DataKeyRepository {
+	return &dataKeyRepository{
+		keys: make(map[string]domain.DataKey),
+	}
+}
+
+// Find retrieves a project's data key
+func (r *dataKeyRepository) Find(ctx context.Context, projectID string) (*domain.DataKey, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	key, ok := r.keys[projectID]
+	if !ok {
+		return nil, domain.ErrDataKeyNotFound
+	}
+	return copyDataKey(&key), nil
+}
+
+// Create stores a project's data key
+func (r *dataKeyRepository) Create(ctx context.Context, key *domain.DataKey) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if _, ok := r.keys[key.ProjectID]; ok {
+		return domain.ErrDuplicateDataKey
+	}
+	r.keys[key.ProjectID] = *copyDataKey(key)
+	return nil
+}
+
+// Rewrap replaces the wrapping of a project's data key
+func (r *dataKeyRepository) Rewrap(ctx context.Context, key *domain.DataKey) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	stored, ok := r.keys[key.ProjectID]
+	if !ok {
+		return domain.ErrDataKeyNotFound
+	}
+	stored.MasterKeyID = key.MasterKeyID
+	stored.WrappedKey = slices.Clone(key.WrappedKey)
+	stored.UpdatedAt = key.UpdatedAt
+	r.keys[key.ProjectID] = stored
+	return nil
+}
+
+// Delete removes a project's data key
+func (r *dataKeyRepository) Delete(ctx context.Context, projectID string) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if _, ok := r.keys[projectID]; !ok {
+		return domain.ErrDataKeyNotFound
+	}
+	delete(r.keys, projectID)
+	return nil
+}
+
+// List retrieves the data keys of all projects ordered by project
+func (r *dataKeyRepository) List(ctx context.Context) ([]*domain.DataKey, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	keys := make([]*domain.DataKey, 0, len(r.keys))
+	for _, key := range r.keys {
+		keys = append(keys, copyDataKey(&key))
+	}
+
+	sort.Slice(keys, func(i, j int) bool {
+		return keys[i].ProjectID < keys[j].ProjectID
+	})
+	return keys, nil
+}
+
+// copyDataKey copies a key's bytes, so callers cannot change stored keys
+func copyDataKey(key *domain.DataKey) *domain.DataKey {
+	c := *key
+	c.WrappedKey = slices.Clone(key.WrappedKey)
+	return &c
+}
@@ -0,0 +1,128 @@
+package inmemory
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"github.com/spidey52/api-logs/internal/domain"
+	"github.com/spidey52/api-logs/internal/ports/output"
+)
+
+// headersRepository implements APILogHeadersRepository interface
+type headersRepository struct {
+	mu      sync.RWMutex
+	byLogID map[string]*domain.APILogHeaders
+}
+
+// NewHeadersRepository creates a new in-memory headers repository
+func NewHeadersRepository() output.APILogHeadersRepository {
+	return &headersRepository{
+		byLogID: make(map[string]*domain.APILogHeaders),
+	}
+}
+
+func copyHeaders(h *domain.APILogHeaders) *domain.APILogHeaders {
+	c := *h
+	c.RequestHeaders = copyAnyMap(h.RequestHeaders)
+	c.ResponseHeaders = copyAnyMap(h.ResponseHeaders)
+	return &c
+}
+
+// Create stores headers for a log
+func (r *headersRepository) Create(ctx context.Context, headers *domain.APILogHeaders) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	r.byLogID[headers.LogID] = copyHeaders(headers)
+	return nil
+}
+
+// CreateMany stores headers for multiple logs
+func (r *headersRepository) CreateMany(ctx context.Context, headers []*domain.APILogHeaders) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	for _, h := range headers {
+		if _, ok := r.byLogID[h.LogID]; ok {
+			continue
+		}
+		r.byLogID[h.LogID] = copyHeaders(h)
+	}
+	return nil
+}
+
+// FindByLogID retrieves headers by log ID
+func (r *headersRepository) FindByLogID(ctx context.Context, logID string) (*domain.APILogHeaders, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	headers, ok := r.byLogID[logID]
+	if !ok {
+		return nil, domain.ErrHeadersNotFound
+	}
+	return copyHeaders(headers), nil
+}
+
+// FindByLogIDs retrieves headers for multiple log IDs
+func (r *headersRepository) FindByLogIDs(ctx context.Context, logIDs []string) ([]*domain.APILogHeaders, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	var found []*domain.APILogHeaders
+	for _, logID := range logIDs {
+		if headers, ok := r.byLogID[logID]; ok {
+			found = append(found, copyHeaders(headers))
+		}
+	}
+	return found, nil
+}
+
+// Delete removes headers by log ID
+func (r *headersRepository) Delete(ctx context.Context, logID string) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	delete(r.byLogID, logID)
+	return nil
+}
+
+// DeleteBatch removes headers for multiple log IDs
+func (r *headersRepository) DeleteBatch(ctx context.Context, logIDs []string) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	for _, logID := range logIDs {
+		delete(r.byLogID, logID)
+	}
+	return nil
+}
+
+func headersStamp(h *domain.APILogHeaders) (string, time.Time) {
+	return h.ProjectID, h.CreatedAt
+}
+
+// FindOlderThan retrieves a batch of a project's headers stored before a time,
+// oldest first
+func (r *headersRepository) FindOlderThan(ctx context.Context, projectID string, before time.Time, limit int) ([]*domain.APILogHeaders, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	return findOlderThan(r.byLogID, projectID, before, limit, headersStamp, copyHeaders), nil
+}
+
+// DeleteOlderThan removes a batch of a project's headers stored before a time
+func (r *headersRepository) DeleteOlderThan(ctx context.Context, projectID string, before time.Time, limit int) (int64, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	return deleteOlderThan(r.byLogID, projectID, before, limit, headersStamp), nil
+}
+
+// VolumeOlderThan measures a project's headers stored before a time
+func (r *headersRepository) VolumeOlderThan(ctx context.Context, projectID string, before time.Time) (domain.DataVolume, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	return volumeOlderThan(r.byLogID, projectID, before, headersStamp), nil
+}
@@ -0,0 +1,123 @@
+package inmemory
+
+import (
+	"encoding/json"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/spidey52/api-logs/internal/domain"
+)
+
+// startOfDay returns local midnight for t, matching the MongoDB stats window
+func startOfDay(t time.Time) time.Time {
+	year, month, day := t.Date()
+	return time.Date(year, month, day, 0, 0, 0, 0, t.Location())
+}
+
+// containsFold reports whether substr is within s, ignoring case
+func containsFold(s, substr string) bool {
+	return strings.Contains(strings.ToLower(s), strings.ToLower(substr))
+}
+
+// paginate applies offset/limit to n items and returns the slice bounds
+func paginate(n int, shared domain.SharedFilter) (int, int) {
+	start := shared.Offset
+	if start > n {
+		start = n
+	}
+	end := n
+	if shared.Limit > 0 && start+shared.Limit < end {
+		end = start + shared.Limit
+	}
+	return start, end
+}
+
+func copyStringMap(m map[string]string) map[string]string {
+	if m == nil {
+		return nil
+	}
+	out := make(map[string]string, len(m))
+	for k, v := range m {
+		out[k] = v
+	}
+	return out
+}
+
+func copyAnyMap(m map[string]any) map[string]any {
+	if m == nil {
+		return nil
+	}
+	out := make(map[string]any, len(m))
+	for k, v := range m {
+		out[k] = v
+	}
+	return out
+}
+
+func copyTime(t *time.Time) *time.Time {
+	if t == nil {
+		return nil
+	}
+	c := *t
+	return &c
+}
+
+// expired reports whether an item of a project stored at a time is older than
+// before. Items without a project belong to the empty project ID.
+func expired(itemProjectID string, storedAt time.Time, projectID string, before time.Time) bool {
+	return itemProjectID == projectID && storedAt.Before(before)
+}
+
+// findOlderThan returns copies of at most limit of the items stamp reports as
+// expired, oldest first
+func findOlderThan[T any](items map[string]T, projectID string, before time.Time, limit int, stamp func(T) (string, time.Time), clone func(T) T) []T {
+	var found []T
+	for _, item := range items {
+		if itemProjectID, storedAt := stamp(item); expired(itemProjectID, storedAt, projectID, before) {
+			found = append(found, item)
+		}
+	}
+
+	sort.Slice(found, func(i, j int) bool {
+		_, a := stamp(found[i])
+		_, b := stamp(found[j])
+		return a.Before(b)
+	})
+	if len(found) > limit {
+		found = found[:limit]
+	}
+	for i, item := range found {
+		found[i] = clone(item)
+	}
+	return found
+}
+
+// deleteOlderThan removes at most limit of the items stamp reports as expired
+func deleteOlderThan[T any](items map[string]T, projectID string, before time.Time, limit int, stamp func(T) (string, time.Time)) int64 {
+	var deleted int64
+	for key, item := range items {
+		if deleted >= int64(limit) {
+			break
+		}
+		if itemProjectID, storedAt := stamp(item); expired(itemProjectID, storedAt, projectID, before) {
+			delete(items, key)
+			deleted++
+		}
+	}
+	return deleted
+}
+
+// volumeOlderThan measures the items stamp reports as expired by the size of
+// their JSON encoding
+func volumeOlderThan[T any](items map[string]T, projectID string, before time.Time, stamp func(T) (string, time.Time)) domain.DataVolume {
+	var volume domain.DataVolume
+	for _, item := range items {
+		if itemProjectID, storedAt := stamp(item); expired(itemProjectID, storedAt, projectID, before) {
+			data, _ := json.Marshal(item)
+			volume.Documents++
+			volume.Bytes += int64(len(data))
+		}
+	}
+	return volume
+}
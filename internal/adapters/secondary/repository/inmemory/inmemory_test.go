@@ -0,0 +1,92 @@
+package inmemory
+
+import (
+	"testing"
+
+	"github.com/spidey52/api-logs/internal/adapters/secondary/repository/contract"
+	"github.com/spidey52/api-logs/internal/ports/output"
+)
+
+func TestAPILogRepository(t *testing.T) {
+	contract.RunAPILogRepository(t, func(t *testing.T) output.APILogRepository {
+		return NewAPILogRepository()
+	})
+}
+
+func TestHeadersRepository(t *testing.T) {
+	contract.RunHeadersRepository(t, func(t *testing.T) output.APILogHeadersRepository {
+		return NewHeadersRepository()
+	})
+}
+
+func TestBodyRepository(t *testing.T) {
+	contract.RunBodyRepository(t, func(t *testing.T) output.APILogBodyRepository {
+		return NewBodyRepository()
+	})
+}
+
+func TestProjectRepository(t *testing.T) {
+	contract.RunProjectRepository(t, func(t *testing.T) output.ProjectRepository {
+		return NewProjectRepository()
+	})
+}
+
+func TestUserRepository(t *testing.T) {
+	contract.RunUserRepository(t, func(t *testing.T) output.UserRepository {
+		return NewUserRepository()
+	})
+}
+
+func TestAccessLogRepository(t *testing.T) {
+	contract.RunAccessLogRepository(t, func(t *testing.T) output.AccessLogRepository {
+		return NewAccessLogRepository()
+	})
+}
+
+func TestAccountRepository(t *testing.T) {
+	contract.RunAccountRepository(t, func(t *testing.T) output.AccountRepository {
+		return NewAccountRepository()
+	})
+}
+
+func TestSessionRepository(t *testing.T) {
+	contract.RunSessionRepository(t, func(t *testing.T) output.SessionRepository {
+		return NewSessionRepository()
+	})
+}
+
+func TestProjectMemberRepository(t *testing.T) {
+	contract.RunProjectMemberRepository(t, func(t *testing.T) output.ProjectMemberRepository {
+		return NewProjectMemberRepository()
+	})
+}
+
+func TestAPIKeyRepository(t *testing.T) {
+	contract.RunAPIKeyRepository(t, func(t *testing.T) output.APIKeyRepository {
+		return NewAPIKeyRepository()
+	})
+}
+
+func TestQuotaRepository(t *testing.T) {
+	contract.RunQuotaRepository(t, func(t *testing.T) output.QuotaRepository {
+		return NewQuotaRepository()
+	})
+}
+
+func TestRedactionPolicyRepository(t *testing.T) {
+	contract.RunRedactionPolicyRepository(t, func(t *testing.T) output.RedactionPolicyRepository {
+		return NewRedactionPolicyRepository()
+	})
+}
+
+func TestDataKeyRepository(t *testing.T) {
+	contract.RunDataKeyRepository(t, func(t *testing.T) output.DataKeyRepository {
+		return NewDataKeyRepository()
+	})
+}
+
+func TestLogSummaryRepository(t *testing.T) {
+	contract.RunLogSummaryRepository(t, func(t *testing.T) output.LogSummaryRepository {
+		return NewLogSummaryRepository()
+	})
+}
@@ -0,0 +1,108 @@
+package inmemory
+
+import (
+	"context"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/spidey52/api-logs/internal/domain"
+	"github.com/spidey52/api-logs/internal/ports/output"
+)
+
+// logSummaryRepository implements LogSummaryRepository interface
+type logSummaryRepository struct {
+	mu        sync.RWMutex
+	summaries []*domain.LogSummary
+}
+
+// NewLogSummaryRepository creates a new in-memory log summary repository
+func NewLogSummaryRepository() output.LogSummaryRepository {
+	return &logSummaryRepository{}
+}
+
+// inRange reports whether s is one of a project's summaries of one
+// granularity with a bucket in [from, to)
+func inRange(s *domain.LogSummary, projectID string, granularity domain.SummaryGranularity, from, to time.Time) bool {
+	return s.ProjectID == projectID && s.Granularity == granularity &&
+		!s.Bucket.Before(from) && s.Bucket.Before(to)
+}
+
+// Replace replaces a project's summaries of one granularity in a range
+func (r *logSummaryRepository) Replace(ctx context.Context, projectID string, granularity domain.SummaryGranularity, from, to time.Time, summaries []*domain.LogSummary) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	kept := r.summaries[:0]
+	for _, s := range r.summaries {
+		if !inRange(s, projectID, granularity, from, to) {
+			kept = append(kept, s)
+		}
+	}
+	for _, s := range summaries {
+		kept = append(kept, copySummary(s))
+	}
+	r.summaries = kept
+	return nil
+}
+
+// Find retrieves the summaries matching the filter, oldest bucket first
+func (r *logSummaryRepository) Find(ctx context.Context, filter domain.SummaryFilter) ([]*domain.LogSummary, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	var results []*domain.LogSummary
+	for _, s := range r.summaries {
+		if !inRange(s, filter.ProjectID, filter.Granularity, filter.From, filter.To) {
+			continue
+		}
+		if filter.Environment != "" && s.Environment != filter.Environment {
+			continue
+		}
+		results = append(results, copySummary(s))
+	}
+
+	sort.Slice(results, func(i, j int) bool {
+		a, b := results[i], results[j]
+		if !a.Bucket.Equal(b.Bucket) {
+			return a.Bucket.Before(b.Bucket)
+		}
+		if a.Environment != b.Environment {
+			return a.Environment < b.Environment
+		}
+		if a.Method != b.Method {
+			return a.Method < b.Method
+		}
+		return a.Route < b.Route
+	})
+	return results, nil
+}
+
+// DeleteOlderThan removes the summaries of one granularity before the cutoff
+func (r *logSummaryRepository) DeleteOlderThan(ctx context.Context, granularity domain.SummaryGranularity, before time.Time) (int64, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	var deleted int64
+	kept := r.summaries[:0]
+	for _, s := range r.summaries {
+		if s.Granularity == granularity && s.Bucket.Before(before) {
+			deleted++
+			continue
+		}
+		kept = append(kept, s)
+	}
+	r.summaries = kept
+	return deleted, nil
+}
+
+func copySummary(s *domain.LogSummary) *domain.LogSummary {
+	c := *s
+	c.Bucket = s.Bucket.UTC()
+	c.StatusCodes = make(map[int]int64, len(s.StatusCodes))
+	for code, count := range s.StatusCodes {
+		c.StatusCodes[code] = count
+	}
+	c.Histogram = append([]int64(nil), s.Histogram...)
+	return &c
+}
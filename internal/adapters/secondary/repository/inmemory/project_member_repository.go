@@ -0,0 +1,127 @@
+package inmemory
+
+import (
+	"context"
+	"sort"
+	"sync"
+
+	"github.com/spidey52/api-logs/internal/domain"
+	"github.com/spidey52/api-logs/internal/ports/output"
+)
+
+// projectMemberRepository implements ProjectMemberRepository interface
+type projectMemberRepository struct {
+	mu      sync.RWMutex
+	members map[string]*domain.ProjectMember
+}
+
+// NewProjectMemberRepository creates a new in-memory project member repository
+func NewProjectMemberRepository() output.ProjectMemberRepository {
+	return &projectMemberRepository{
+		members: make(map[string]*domain.ProjectMember),
+	}
+}
+
+// memberKey identifies a membership by project and account
+func memberKey(projectID, accountID string) string {
+	return projectID + "\x00" + accountID
+}
+
+// Create stores a new membership
+func (r *projectMemberRepository) Create(ctx context.Context, member *domain.ProjectMember) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	key := memberKey(member.ProjectID, member.AccountID)
+	if _, ok := r.members[key]; ok {
+		return domain.ErrDuplicateMember
+	}
+
+	c := *member
+	r.members[key] = &c
+	return nil
+}
+
+// Find retrieves the membership of an account in a project
+func (r *projectMemberRepository) Find(ctx context.Context, projectID, accountID string) (*domain.ProjectMember, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	member, ok := r.members[memberKey(projectID, accountID)]
+	if !ok {
+		return nil, domain.ErrMemberNotFound
+	}
+	c := *member
+	return &c, nil
+}
+
+// Update updates the role of an existing membership
+func (r *projectMemberRepository) Update(ctx context.Context, member *domain.ProjectMember) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	existing, ok := r.members[memberKey(member.ProjectID, member.AccountID)]
+	if !ok {
+		return domain.ErrMemberNotFound
+	}
+	existing.Role = member.Role
+	existing.UpdatedAt = member.UpdatedAt
+	return nil
+}
+
+// Delete removes the membership of an account in a project
+func (r *projectMemberRepository) Delete(ctx context.Context, projectID, accountID string) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	key := memberKey(projectID, accountID)
+	if _, ok := r.members[key]; !ok {
+		return domain.ErrMemberNotFound
+	}
+	delete(r.members, key)
+	return nil
+}
+
+// DeleteByProject removes every membership of a project
+func (r *projectMemberRepository) DeleteByProject(ctx context.Context, projectID string) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	for key, member := range r.members {
+		if member.ProjectID == projectID {
+			delete(r.members, key)
+		}
+	}
+	return nil
+}
+
+// ListByProject retrieves the members of a project, oldest first
+func (r *projectMemberRepository) ListByProject(ctx context.Context, projectID string) ([]*domain.ProjectMember, error) {
+	return r.list(func(m *domain.ProjectMember) bool { return m.ProjectID == projectID }), nil
+}
+
+// ListByAccount retrieves the memberships of an account, oldest first
+func (r *projectMemberRepository) ListByAccount(ctx context.Context, accountID string) ([]*domain.ProjectMember, error) {
+	return r.list(func(m *domain.ProjectMember) bool { return m.AccountID == accountID }), nil
+}
+
+func (r *projectMemberRepository) list(match func(*domain.ProjectMember) bool) []*domain.ProjectMember {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	members := []*domain.ProjectMember{}
+	for _, member := range r.members {
+		if match(member) {
+			c := *member
+			members = append(members, &c)
+		}
+	}
+
+	sort.Slice(members, func(i, j int) bool {
+		if members[i].CreatedAt.Equal(members[j].CreatedAt) {
+			return members[i].ID < members[j].ID
+		}
+		return members[i].CreatedAt.Before(members[j].CreatedAt)
+	})
+	return members
+}
@@ -0,0 +1,159 @@
+package inmemory
+
+import (
+	"context"
+	"slices"
+	"sort"
+	"sync"
+
+	"github.com/spidey52/api-logs/internal/domain"
+	"github.com/spidey52/api-logs/internal/ports/output"
+)
+
+// projectRepository implements ProjectRepository interface
+type projectRepository struct {
+	mu       sync.RWMutex
+	projects map[string]*domain.Project
+}
+
+// NewProjectRepository creates a new in-memory project repository
+func NewProjectRepository() output.ProjectRepository {
+	return &projectRepository{
+		projects: make(map[string]*domain.Project),
+	}
+}
+
+// apiKeyTaken reports whether another project already uses apiKeyHash; callers hold the lock
+func (r *projectRepository) apiKeyTaken(apiKeyHash, exceptID string) bool {
+	for _, project := range r.projects {
+		if project.APIKeyHash == apiKeyHash && project.ID != exceptID {
+			return true
+		}
+	}
+	return false
+}
+
+// Create stores a new project
+func (r *projectRepository) Create(ctx context.Context, project *domain.Project) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if r.apiKeyTaken(project.APIKeyHash, "") {
+		return domain.ErrDuplicateAPIKey
+	}
+
+	c := *project
+	c.APIKey = ""
+	r.projects[project.ID] = &c
+	return nil
+}
+
+// FindByID retrieves a project by ID
+func (r *projectRepository) FindByID(ctx context.Context, id string) (*domain.Project, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	project, ok := r.projects[id]
+	if !ok {
+		return nil, domain.ErrProjectNotFound
+	}
+	c := *project
+	return &c, nil
+}
+
+// FindByAPIKey retrieves a project by the hash of its API key
+func (r *projectRepository) FindByAPIKey(ctx context.Context, apiKeyHash string) (*domain.Project, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	for _, project := range r.projects {
+		if project.APIKeyHash == apiKeyHash {
+			c := *project
+			return &c, nil
+		}
+	}
+	return nil, domain.ErrProjectNotFound
+}
+
+// FindAll retrieves projects based on filter criteria
+func (r *projectRepository) FindAll(ctx context.Context, filter domain.ProjectFilter) ([]*domain.Project, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	projects := []*domain.Project{}
+	for _, project := range r.projects {
+		if filter.Environment != "" && project.Environment != filter.Environment {
+			continue
+		}
+		if filter.IsActive != nil && project.IsActive != *filter.IsActive {
+			continue
+		}
+		if len(filter.IDs) > 0 && !slices.Contains(filter.IDs, project.ID) {
+			continue
+		}
+		c := *project
+		projects = append(projects, &c)
+	}
+
+	sort.SliceStable(projects, func(i, j int) bool {
+		return projects[i].CreatedAt.After(projects[j].CreatedAt)
+	})
+
+	start, end := paginate(len(projects), filter.SharedFilter)
+	return projects[start:end], nil
+}
+
+// Update updates an existing project
+func (r *projectRepository) Update(ctx context.Context, project *domain.Project) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if _, ok := r.projects[project.ID]; !ok {
+		return domain.ErrProjectNotFound
+	}
+	if r.apiKeyTaken(project.APIKeyHash, project.ID) {
+		return domain.ErrDuplicateAPIKey
+	}
+
+	c := *project
+	c.APIKey = ""
+	r.projects[project.ID] = &c
+	return nil
+}
+
+// Delete removes a project by ID
+func (r *projectRepository) Delete(ctx context.Context, id string) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if _, ok := r.projects[id]; !ok {
+		return domain.ErrProjectNotFound
+	}
+	delete(r.projects, id)
+	return nil
+}
+
+// ExistsByAPIKey checks if an API key hash already exists
+func (r *projectRepository) ExistsByAPIKey(ctx context.Context, apiKeyHash string) (bool, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	return r.apiKeyTaken(apiKeyHash, ""), nil
+}
+
+// HashPlaintextAPIKeys hashes keys of projects stored without a prefix
+func (r *projectRepository) HashPlaintextAPIKeys(ctx context.Context, hash func(key string) string) (int, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	converted := 0
+	for _, project := range r.projects {
+		if project.APIKeyPrefix != "" {
+			continue
+		}
+		project.APIKeyPrefix = domain.KeyPrefix(project.APIKeyHash)
+		project.APIKeyHash = hash(project.APIKeyHash)
+		converted++
+	}
+	return converted, nil
+}
@@ -0,0 +1,55 @@
+package inmemory
+
+import (
+	"context"
+	"sync"
+
+	"github.com/spidey52/api-logs/internal/domain"
+	"github.com/spidey52/api-logs/internal/ports/output"
+)
+
+// quotaRepository implements QuotaRepository interface
+type quotaRepository struct {
+	mu     sync.RWMutex
+	quotas map[string]domain.ProjectQuota
+}
+
+// NewQuotaRepository creates a new in-memory quota repository
+func NewQuotaRepository() output.QuotaRepository {
+	return &quotaRepository{
+		quotas: make(map[string]domain.ProjectQuota),
+	}
+}
+
+// Find retrieves a project's quota
+func (r *quotaRepository) Find(ctx context.Context, projectID string) (*domain.ProjectQuota, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	quota, ok := r.quotas[projectID]
+	if !ok {
+		return nil, domain.ErrQuotaNotFound
+	}
+	return &quota, nil
+}
+
+// Upsert creates or replaces a project's quota
+func (r *quotaRepository) Upsert(ctx context.Context, quota *domain.ProjectQuota) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	r.quotas[quota.ProjectID] = *quota
+	return nil
+}
+
+// Delete removes a project's quota
+func (r *quotaRepository) Delete(ctx context.Context, projectID string) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if _, ok := r.quotas[projectID]; !ok {
+		return domain.ErrQuotaNotFound
+	}
+	delete(r.quotas, projectID)
+	return nil
+}
@@ -0,0 +1,67 @@
+package inmemory
+
+import (
+	"context"
+	"slices"
+	"sync"
+
+	"github.com/spidey52/api-logs/internal/domain"
+	"github.com/spidey52/api-logs/internal/ports/output"
+)
+
+// redactionPolicyRepository implements RedactionPolicyRepository interface
+type redactionPolicyRepository struct {
+	mu       sync.RWMutex
+	policies map[string]domain.RedactionPolicy
+}
+
+// NewRedactionPolicyRepository creates a new in-memory redaction policy repository
+func NewRedactionPolicyRepository() output.RedactionPolicyRepository {
+	return &redactionPolicyRepository{
+		policies: make(map[string]domain.RedactionPolicy),
+	}
+}
+
+// Find retrieves a project's redaction policy
+func (r *redactionPolicyRepository) Find(ctx context.Context, projectID string) (*domain.RedactionPolicy, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	policy, ok := r.policies[projectID]
+	if !ok {
+		return nil, domain.ErrRedactionPolicyNotFound
+	}
+	return clonePolicy(&policy), nil
+}
+
+// Upsert creates or replaces a project's redaction policy
+func (r *redactionPolicyRepository) Upsert(ctx context.Context, policy *domain.RedactionPolicy) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	r.policies[policy.ProjectID] = *clonePolicy(policy)
+	return nil
+}
+
+// Delete removes a project's redaction policy
+func (r *redactionPolicyRepository) Delete(ctx context.Context, projectID string) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if _, ok := r.policies[projectID]; !ok {
+		return domain.ErrRedactionPolicyNotFound
+	}
+	delete(r.policies, projectID)
+	return nil
+}
+
+// clonePolicy copies a policy's lists, so callers cannot change stored policies
+func clonePolicy(policy *domain.RedactionPolicy) *domain.RedactionPolicy {
+	c := *policy
+	c.Headers = slices.Clone(policy.Headers)
+	c.QueryParams = slices.Clone(policy.QueryParams)
+	c.JSONPaths = slices.Clone(policy.JSONPaths)
+	c.Patterns = slices.Clone(policy.Patterns)
+	c.Regexes = slices.Clone(policy.Regexes)
+	return &c
+}
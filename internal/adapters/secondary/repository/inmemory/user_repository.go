@@ -0,0 +1,153 @@
+package inmemory
+
+import (
+	"context"
+	"sort"
+	"sync"
+
+	"github.com/spidey52/api-logs/internal/domain"
+	"github.com/spidey52/api-logs/internal/ports/output"
+)
+
+// userRepository implements UserRepository interface
+type userRepository struct {
+	mu    sync.RWMutex
+	users map[string]*domain.User
+}
+
+// NewUserRepository creates a new in-memory user repository
+func NewUserRepository() output.UserRepository {
+	return &userRepository{
+		users: make(map[string]*domain.User),
+	}
+}
+
+func copyUser(user *domain.User) *domain.User {
+	c := *user
+	c.Metadata = copyAnyMap(user.Metadata)
+	return &c
+}
+
+// identifierTaken reports whether the identifier is used by another user in the project; callers hold the lock
+func (r *userRepository) identifierTaken(user *domain.User) bool {
+	for _, existing := range r.users {
+		if existing.ID != user.ID && existing.ProjectID == user.ProjectID && existing.Identifier == user.Identifier {
+			return true
+		}
+	}
+	return false
+}
+
+// Create creates a new user
+func (r *userRepository) Create(ctx context.Context, user *domain.User) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if r.identifierTaken(user) {
+		return domain.ErrDuplicateUserIdentifier
+	}
+
+	r.users[user.ID] = copyUser(user)
+	return nil
+}
+
+// FindByID retrieves a user by ID
+func (r *userRepository) FindByID(ctx context.Context, id string) (*domain.User, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	user, ok := r.users[id]
+	if !ok {
+		return nil, domain.ErrUserNotFound
+	}
+	return copyUser(user), nil
+}
+
+// FindByIdentifier retrieves a user by identifier
+func (r *userRepository) FindByIdentifier(ctx context.Context, identifier string, projectID string) (*domain.User, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	for _, user := range r.users {
+		if user.Identifier == identifier && user.ProjectID == projectID {
+			return copyUser(user), nil
+		}
+	}
+	return nil, domain.ErrUserNotFound
+}
+
+// Update updates an existing user
+func (r *userRepository) Update(ctx context.Context, user *domain.User) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	existing, ok := r.users[user.ID]
+	if !ok {
+		return domain.ErrUserNotFound
+	}
+
+	updated := copyUser(user)
+	updated.CreatedAt = existing.CreatedAt
+	updated.ProjectID = existing.ProjectID
+	r.users[user.ID] = updated
+	return nil
+}
+
+// Delete deletes a user by ID
+func (r *userRepository) Delete(ctx context.Context, id string) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if _, ok := r.users[id]; !ok {
+		return domain.ErrUserNotFound
+	}
+	delete(r.users, id)
+	return nil
+}
+
+// DeleteByProject removes every user of a project
+func (r *userRepository) DeleteByProject(ctx context.Context, projectID string) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	for id, user := range r.users {
+		if user.ProjectID == projectID {
+			delete(r.users, id)
+		}
+	}
+	return nil
+}
+
+// List retrieves all users with pagination
+func (r *userRepository) List(ctx context.Context, projectID string, page, pageSize int) ([]*domain.User, int, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	users := make([]*domain.User, 0, len(r.users))
+	for _, user := range r.users {
+		if user.ProjectID == projectID {
+			users = append(users, copyUser(user))
+		}
+	}
+
+	sort.SliceStable(users, func(i, j int) bool {
+		return users[i].CreatedAt.After(users[j].CreatedAt)
+	})
+
+	start, end := paginate(len(users), domain.SharedFilter{Limit: pageSize, Offset: (page - 1) * pageSize})
+	return users[start:end], len(users), nil
+}
+
+// GetUserMap retrieves users by IDs and returns them keyed by ID
+func (r *userRepository) GetUserMap(ctx context.Context, ids []string) (map[string]*domain.User, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	userMap := make(map[string]*domain.User)
+	for _, id := range ids {
+		if user, ok := r.users[id]; ok {
+			userMap[id] = copyUser(user)
+		}
+	}
+	return userMap, nil
+}
@@ -2,11 +2,14 @@ package mongodb
 
 import (
 	"context"
+	"regexp"
+	"time"
 
 	"github.com/spidey52/api-logs/internal/domain"
 	"github.com/spidey52/api-logs/internal/ports/output"
 	"go.mongodb.org/mongo-driver/bson"
 	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
 )
 
 type mongoAccessLogRepository struct {
@@ -32,6 +35,18 @@ func buildAccessLogFilterBSON(filter domain.AccessLogFilter) bson.M {
 		bsonFilter["actor_id"] = filter.ActorID
 	}
 
+	if filter.ActorType != nil {
+		bsonFilter["actor_type"] = *filter.ActorType
+	}
+
+	if filter.Outcome != nil {
+		bsonFilter["outcome"] = *filter.Outcome
+	}
+
+	for key, value := range filter.Metadata {
+		bsonFilter["metadata."+key] = value
+	}
+
 	if filter.Action != "" {
 		bsonFilter["action"] = filter.Action
 	}
@@ -45,7 +60,7 @@ func buildAccessLogFilterBSON(filter domain.AccessLogFilter) bson.M {
 	}
 
 	if filter.Search != "" {
-		bsonFilter["$text"] = bson.M{"$search": filter.Search}
+		bsonFilter["message"] = bson.M{"$regex": regexp.QuoteMeta(filter.Search), "$options": "i"}
 	}
 
 	if filter.FromDate != nil || filter.ToDate != nil {
@@ -108,11 +123,16 @@ func (m *mongoAccessLogRepository) DeleteByID(ctx context.Context, id string) er
 
 // FindByFilter implements output.AccessLogRepository.
 func (m *mongoAccessLogRepository) FindByFilter(ctx context.Context, filter domain.AccessLogFilter) ([]*domain.AccessLog, error) {
-	var logs []*domain.AccessLog
+	logs := []*domain.AccessLog{}
 
 	bsonFilter := buildAccessLogFilterBSON(filter)
 
-	cursor, err := m.collection.Find(ctx, bsonFilter)
+	opts := options.Find().
+		SetLimit(int64(filter.Limit)).
+		SetSkip(int64(filter.Offset)).
+		SetSort(bson.D{{Key: "timestamp", Value: -1}})
+
+	cursor, err := m.collection.Find(ctx, bsonFilter, opts)
 	if err != nil {
 		return nil, err
 	}
@@ -134,8 +154,21 @@ func (m *mongoAccessLogRepository) FindByID(ctx context.Context, id string) (*do
 	err := m.collection.FindOne(ctx, bson.M{"_id": id}).Decode(&log)
 
 	if err != nil {
+		if err == mongo.ErrNoDocuments {
+			return nil, domain.ErrAccessLogNotFound
+		}
 		return nil, err
 	}
 
 	return &log, nil
 }
+
+// DeleteOlderThan implements output.AccessLogRepository.
+func (m *mongoAccessLogRepository) DeleteOlderThan(ctx context.Context, projectID string, before time.Time, limit int) (int64, error) {
+	return deleteOlderThan(ctx, m.collection, "timestamp", projectID, before, limit)
+}
+
+// VolumeOlderThan implements output.AccessLogRepository.
+func (m *mongoAccessLogRepository) VolumeOlderThan(ctx context.Context, projectID string, before time.Time) (domain.DataVolume, error) {
+	return volumeOlderThan(ctx, m.collection, "timestamp", projectID, before)
+}
@@ -0,0 +1,139 @@
+package mongodb
+
+import (
+	"context"
+	"time"
+
+	"github.com/spidey52/api-logs/internal/domain"
+	"github.com/spidey52/api-logs/internal/ports/output"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+// accountRepository implements AccountRepository interface
+type accountRepository struct {
+	collection *mongo.Collection
+}
+
+// NewAccountRepository creates a new MongoDB account repository
+func NewAccountRepository(client *Client) output.AccountRepository {
+	return &accountRepository{
+		collection: client.Collection(CollectionAccounts),
+	}
+}
+
+// Create stores a new account
+func (r *accountRepository) Create(ctx context.Context, account *domain.Account) error {
+	_, err := r.collection.InsertOne(ctx, account)
+	if mongo.IsDuplicateKeyError(err) {
+		return domain.ErrDuplicateUsername
+	}
+	return err
+}
+
+// FindByID retrieves an account by ID
+func (r *accountRepository) FindByID(ctx context.Context, id string) (*domain.Account, error) {
+	return r.findOne(ctx, bson.M{"_id": id})
+}
+
+// FindByUsername retrieves an account by its normalized username
+func (r *accountRepository) FindByUsername(ctx context.Context, username string) (*domain.Account, error) {
+	return r.findOne(ctx, bson.M{"username": username})
+}
+
+func (r *accountRepository) findOne(ctx context.Context, filter bson.M) (*domain.Account, error) {
+	var account domain.Account
+	err := r.collection.FindOne(ctx, filter).Decode(&account)
+	if err != nil {
+		if err == mongo.ErrNoDocuments {
+			return nil, domain.ErrAccountNotFound
+		}
+		return nil, err
+	}
+	return &account, nil
+}
+
+// Update updates an existing account
+func (r *accountRepository) Update(ctx context.Context, account *domain.Account) error {
+	result, err := r.collection.ReplaceOne(ctx, bson.M{"_id": account.ID}, account)
+	if err != nil {
+		if mongo.IsDuplicateKeyError(err) {
+			return domain.ErrDuplicateUsername
+		}
+		return err
+	}
+
+	if result.MatchedCount == 0 {
+		return domain.ErrAccountNotFound
+	}
+	return nil
+}
+
+// List retrieves all accounts ordered by username
+func (r *accountRepository) List(ctx context.Context) ([]*domain.Account, error) {
+	opts := options.Find().SetSort(bson.D{{Key: "username", Value: 1}})
+	cursor, err := r.collection.Find(ctx, bson.M{}, opts)
+	if err != nil {
+		return nil, err
+	}
+	defer cursor.Close(ctx)
+
+	accounts := []*domain.Account{}
+	if err := cursor.All(ctx, &accounts); err != nil {
+		return nil, err
+	}
+	return accounts, nil
+}
+
+// sessionRepository implements SessionRepository interface
+type sessionRepository struct {
+	collection *mongo.Collection
+}
+
+// NewSessionRepository creates a new MongoDB session repository
+func NewSessionRepository(client *Client) output.SessionRepository {
+	return &sessionRepository{
+		collection: client.Collection(CollectionSessions),
+	}
+}
+
+// Create stores a new session
+func (r *sessionRepository) Create(ctx context.Context, session *domain.Session) error {
+	_, err := r.collection.InsertOne(ctx, session)
+	return err
+}
+
+// FindByTokenHash retrieves a session by the hash of its bearer token
+func (r *sessionRepository) FindByTokenHash(ctx context.Context, tokenHash string) (*domain.Session, error) {
+	var session domain.Session
+	err := r.collection.FindOne(ctx, bson.M{"token_hash": tokenHash}).Decode(&session)
+	if err != nil {
+		if err == mongo.ErrNoDocuments {
+			return nil, domain.ErrSessionNotFound
+		}
+		return nil, err
+	}
+	return &session, nil
+}
+
+// Delete removes a session by ID
+func (r *sessionRepository) Delete(ctx context.Context, id string) error {
+	_, err := r.collection.DeleteOne(ctx, bson.M{"_id": id})
+	return err
+}
+
+// DeleteByAccountID removes every session of an account
+func (r *sessionRepository) DeleteByAccountID(ctx context.Context, accountID string) error {
+	_, err := r.collection.DeleteMany(ctx, bson.M{"account_id": accountID})
+	return err
+}
+
+// DeleteExpired removes sessions that expired before the given time
+func (r *sessionRepository) DeleteExpired(ctx context.Context, before time.Time) (int64, error) {
+	result, err := r.collection.DeleteMany(ctx, bson.M{"expires_at": bson.M{"$lt": before}})
+	if err != nil {
+		return 0, err
+	}
+	return result.DeletedCount, nil
+}
@@ -0,0 +1,117 @@
+package mongodb
+
+import (
+	"context"
+	"time"
+
+	"github.com/spidey52/api-logs/internal/domain"
+	"github.com/spidey52/api-logs/internal/ports/output"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+// apiKeyRepository implements APIKeyRepository interface
+type apiKeyRepository struct {
+	collection *mongo.Collection
+}
+
+// NewAPIKeyRepository creates a new MongoDB API key repository
+func NewAPIKeyRepository(client *Client) output.APIKeyRepository {
+	return &apiKeyRepository{
+		collection: client.Collection(CollectionAPIKeys),
+	}
+}
+
+// Create stores a new API key
+func (r *apiKeyRepository) Create(ctx context.Context, key *domain.APIKey) error {
+	_, err := r.collection.InsertOne(ctx, key)
+	if mongo.IsDuplicateKeyError(err) {
+		return domain.ErrDuplicateAPIKey
+	}
+	return err
+}
+
+// FindByID retrieves an API key by ID
+func (r *apiKeyRepository) FindByID(ctx context.Context, id string) (*domain.APIKey, error) {
+	return r.findOne(ctx, bson.M{"_id": id})
+}
+
+// FindByKey retrieves an API key by the hash of its secret
+func (r *apiKeyRepository) FindByKey(ctx context.Context, keyHash string) (*domain.APIKey, error) {
+	return r.findOne(ctx, bson.M{"key_hash": keyHash})
+}
+
+func (r *apiKeyRepository) findOne(ctx context.Context, filter bson.M) (*domain.APIKey, error) {
+	var key domain.APIKey
+	err := r.collection.FindOne(ctx, filter).Decode(&key)
+	if err != nil {
+		if err == mongo.ErrNoDocuments {
+			return nil, domain.ErrAPIKeyNotFound
+		}
+		return nil, err
+	}
+	return &key, nil
+}
+
+// ListByProject retrieves a project's API keys, newest first
+func (r *apiKeyRepository) ListByProject(ctx context.Context, projectID string) ([]*domain.APIKey, error) {
+	opts := options.Find().SetSort(bson.D{{Key: "created_at", Value: -1}, {Key: "_id", Value: 1}})
+	cursor, err := r.collection.Find(ctx, bson.M{"project_id": projectID}, opts)
+	if err != nil {
+		return nil, err
+	}
+	defer cursor.Close(ctx)
+
+	keys := []*domain.APIKey{}
+	if err := cursor.All(ctx, &keys); err != nil {
+		return nil, err
+	}
+	return keys, nil
+}
+
+// Revoke marks an API key as revoked at the given time. Revoking an already
+// revoked key keeps the original timestamp.
+func (r *apiKeyRepository) Revoke(ctx context.Context, id string, revokedAt time.Time) error {
+	filter := bson.M{"_id": id, "revoked_at": bson.M{"$exists": false}}
+	result, err := r.collection.UpdateOne(ctx, filter, bson.M{"$set": bson.M{"revoked_at": revokedAt}})
+	if err != nil {
+		return err
+	}
+
+	if result.MatchedCount == 0 {
+		if _, err := r.FindByID(ctx, id); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// RevokeExpired revokes every key that expired at or before now
+func (r *apiKeyRepository) RevokeExpired(ctx context.Context, now time.Time) (int, error) {
+	filter := bson.M{"revoked_at": bson.M{"$exists": false}, "expires_at": bson.M{"$lte": now}}
+	update := mongo.Pipeline{{{Key: "$set", Value: bson.M{"revoked_at": "$expires_at"}}}}
+
+	result, err := r.collection.UpdateMany(ctx, filter, update)
+	if err != nil {
+		return 0, err
+	}
+	return int(result.ModifiedCount), nil
+}
+
+// TouchLastUsed records when an API key was last used
+func (r *apiKeyRepository) TouchLastUsed(ctx context.Context, id string, usedAt time.Time) error {
+	_, err := r.collection.UpdateOne(ctx, bson.M{"_id": id}, bson.M{"$set": bson.M{"last_used_at": usedAt}})
+	return err
+}
+
+// DeleteByProject removes every API key of a project
+func (r *apiKeyRepository) DeleteByProject(ctx context.Context, projectID string) error {
+	_, err := r.collection.DeleteMany(ctx, bson.M{"project_id": projectID})
+	return err
+}
+
+// HashPlaintextKeys hashes API keys stored before keys were hashed
+func (r *apiKeyRepository) HashPlaintextKeys(ctx context.Context, hash func(key string) string) (int, error) {
+	return hashPlaintextKeys(ctx, r.collection, "key", "key_hash", "prefix", hash)
+}
@@ -2,12 +2,12 @@ package mongodb
 
 import (
 	"context"
+	"regexp"
 	"time"
 
 	"github.com/spidey52/api-logs/internal/domain"
 	"github.com/spidey52/api-logs/internal/ports/output"
 	"github.com/spidey52/api-logs/pkg/cache"
-	"github.com/spidey52/api-logs/pkg/logger"
 	"go.mongodb.org/mongo-driver/bson"
 	"go.mongodb.org/mongo-driver/mongo"
 	"go.mongodb.org/mongo-driver/mongo/options"
@@ -34,6 +34,21 @@ func (r *apiLogRepository) Create(ctx context.Context, log *domain.APILog) error
 	return err
 }
 
+// CreateMany stores multiple API log entries
+func (r *apiLogRepository) CreateMany(ctx context.Context, logs []*domain.APILog) error {
+	if len(logs) == 0 {
+		return nil
+	}
+
+	docs := make([]any, len(logs))
+	for i, log := range logs {
+		docs[i] = apiLogToDocument(log)
+	}
+
+	_, err := r.collection.InsertMany(ctx, docs, options.InsertMany().SetOrdered(false))
+	return ignoreDuplicates(err)
+}
+
 // FindByID retrieves a log by ID
 func (r *apiLogRepository) FindByID(ctx context.Context, id string) (*domain.APILog, error) {
 	filter := bson.M{"_id": id}
@@ -50,8 +65,8 @@ func (r *apiLogRepository) FindByID(ctx context.Context, id string) (*domain.API
 	return documentToAPILog(&doc), nil
 }
 
-// FindByFilter retrieves logs based on filter criteria
-func (r *apiLogRepository) FindByFilter(ctx context.Context, filter domain.LogFilter) ([]*domain.APILog, error) {
+// buildLogFilterBSON translates a LogFilter into a MongoDB query document
+func buildLogFilterBSON(filter domain.LogFilter) bson.M {
 	mongoFilter := bson.M{}
 
 	if filter.ProjectID != "" {
@@ -83,21 +98,19 @@ func (r *apiLogRepository) FindByFilter(ctx context.Context, filter domain.LogFi
 	}
 
 	if filter.Path != "" {
-		mongoFilter["path"] = bson.M{"$regex": filter.Path, "$options": "i"}
+		mongoFilter["path"] = bson.M{"$regex": regexp.QuoteMeta(filter.Path), "$options": "i"}
 	}
 
 	if filter.Search != "" {
-		// Search in path, user_name, user_agent, or ip_address
+		// Search in path, user_agent, or ip_address
+		search := bson.M{"$regex": regexp.QuoteMeta(filter.Search), "$options": "i"}
 		mongoFilter["$or"] = []bson.M{
-			{"path": bson.M{"$regex": filter.Search, "$options": "i"}},
-			{"user_name": bson.M{"$regex": filter.Search, "$options": "i"}},
-			{"user_agent": bson.M{"$regex": filter.Search, "$options": "i"}},
-			{"ip_address": bson.M{"$regex": filter.Search, "$options": "i"}},
+			{"path": search},
+			{"user_agent": search},
+			{"ip_address": search},
 		}
 	}
 
-	logger.Info("environment", mongoFilter["environment"])
-
 	if filter.FromDate != nil || filter.ToDate != nil {
 		timeFilter := bson.M{}
 		if filter.FromDate != nil {
@@ -113,6 +126,13 @@ func (r *apiLogRepository) FindByFilter(ctx context.Context, filter domain.LogFi
 		mongoFilter["user_id"] = filter.UserID
 	}
 
+	return mongoFilter
+}
+
+// FindByFilter retrieves logs based on filter criteria
+func (r *apiLogRepository) FindByFilter(ctx context.Context, filter domain.LogFilter) ([]*domain.APILog, error) {
+	mongoFilter := buildLogFilterBSON(filter)
+
 	opts := options.Find().
 		SetLimit(int64(filter.Limit)).
 		SetSkip(int64(filter.Offset)).
@@ -158,6 +178,16 @@ func (r *apiLogRepository) Delete(ctx context.Context, id string) error {
 	return nil
 }
 
+// DeleteBatch removes the logs with the given IDs
+func (r *apiLogRepository) DeleteBatch(ctx context.Context, ids []string) error {
+	if len(ids) == 0 {
+		return nil
+	}
+
+	_, err := r.collection.DeleteMany(ctx, bson.M{"_id": bson.M{"$in": ids}})
+	return err
+}
+
 // CountByProject counts logs for a specific project
 func (r *apiLogRepository) CountByProject(ctx context.Context, projectID string, environment domain.Environment) (int64, error) {
 	return r.collection.CountDocuments(ctx, bson.M{
@@ -171,62 +201,7 @@ func (r *apiLogRepository) CountByProject(ctx context.Context, projectID string,
 
 // CountByFilter counts logs matching the filter criteria
 func (r *apiLogRepository) CountByFilter(ctx context.Context, filter domain.LogFilter) (int64, error) {
-	mongoFilter := bson.M{}
-
-	if filter.ProjectID != "" {
-		mongoFilter["project_id"] = filter.ProjectID
-	}
-
-	if filter.Environment != "" {
-		mongoFilter["environment"] = filter.Environment
-	}
-
-	if filter.Method != "" {
-		mongoFilter["method"] = filter.Method
-	}
-
-	// Handle status code filtering (exact, min/max range)
-	if filter.StatusCode != nil {
-		// Exact status code
-		mongoFilter["status_code"] = *filter.StatusCode
-	} else if filter.StatusCodeMin != nil || filter.StatusCodeMax != nil {
-		// Status code range
-		statusFilter := bson.M{}
-		if filter.StatusCodeMin != nil {
-			statusFilter["$gte"] = *filter.StatusCodeMin
-		}
-		if filter.StatusCodeMax != nil {
-			statusFilter["$lte"] = *filter.StatusCodeMax
-		}
-		mongoFilter["status_code"] = statusFilter
-	}
-
-	if filter.Path != "" {
-		mongoFilter["path"] = bson.M{"$regex": filter.Path, "$options": "i"}
-	}
-
-	if filter.Search != "" {
-		// Search in path, user_name, user_agent, or ip_address
-		mongoFilter["$or"] = []bson.M{
-			{"path": bson.M{"$regex": filter.Search, "$options": "i"}},
-			{"user_name": bson.M{"$regex": filter.Search, "$options": "i"}},
-			{"user_agent": bson.M{"$regex": filter.Search, "$options": "i"}},
-			{"ip_address": bson.M{"$regex": filter.Search, "$options": "i"}},
-		}
-	}
-
-	if filter.FromDate != nil || filter.ToDate != nil {
-		timeFilter := bson.M{}
-		if filter.FromDate != nil {
-			timeFilter["$gte"] = *filter.FromDate
-		}
-		if filter.ToDate != nil {
-			timeFilter["$lte"] = *filter.ToDate
-		}
-		mongoFilter["timestamp"] = timeFilter
-	}
-
-	return r.collection.CountDocuments(ctx, mongoFilter)
+	return r.collection.CountDocuments(ctx, buildLogFilterBSON(filter))
 }
 
 // GetStatusCodeDistribution returns distribution of status codes
@@ -457,3 +432,34 @@ func (r *apiLogRepository) GetUniquePaths(ctx context.Context, projectID string,
 
 	return result, nil
 }
+
+// FindOlderThan retrieves a batch of a project's expired logs, oldest first
+func (r *apiLogRepository) FindOlderThan(ctx context.Context, projectID string, before time.Time, limit int) ([]*domain.APILog, error) {
+	opts := options.Find().SetSort(bson.D{{Key: "timestamp", Value: 1}}).SetLimit(int64(limit))
+	cursor, err := r.collection.Find(ctx, olderThanFilter("timestamp", projectID, before), opts)
+	if err != nil {
+		return nil, err
+	}
+	defer cursor.Close(ctx)
+
+	var docs []apiLogDocument
+	if err := cursor.All(ctx, &docs); err != nil {
+		return nil, err
+	}
+
+	logs := make([]*domain.APILog, len(docs))
+	for i := range docs {
+		logs[i] = documentToAPILog(&docs[i])
+	}
+	return logs, nil
+}
+
+// DeleteOlderThan removes a batch of a project's expired logs
+func (r *apiLogRepository) DeleteOlderThan(ctx context.Context, projectID string, before time.Time, limit int) (int64, error) {
+	return deleteOlderThan(ctx, r.collection, "timestamp", projectID, before, limit)
+}
+
+// VolumeOlderThan measures a project's logs stored before a time
+func (r *apiLogRepository) VolumeOlderThan(ctx context.Context, projectID string, before time.Time) (domain.DataVolume, error) {
+	return volumeOlderThan(ctx, r.collection, "timestamp", projectID, before)
+}
@@ -2,11 +2,13 @@ package mongodb
 
 import (
 	"context"
+	"time"
 
 	"github.com/spidey52/api-logs/internal/domain"
 	"github.com/spidey52/api-logs/internal/ports/output"
 	"go.mongodb.org/mongo-driver/bson"
 	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
 )
 
 // bodyRepository implements APILogBodyRepository interface
@@ -28,6 +30,21 @@ func (r *bodyRepository) Create(ctx context.Context, body *domain.APILogBody) er
 	return err
 }
 
+// CreateMany stores bodies for multiple logs
+func (r *bodyRepository) CreateMany(ctx context.Context, bodies []*domain.APILogBody) error {
+	if len(bodies) == 0 {
+		return nil
+	}
+
+	docs := make([]any, len(bodies))
+	for i, b := range bodies {
+		docs[i] = bodyToDocument(b)
+	}
+
+	_, err := r.collection.InsertMany(ctx, docs, options.InsertMany().SetOrdered(false))
+	return ignoreDuplicates(err)
+}
+
 // FindByLogID retrieves body by log ID
 func (r *bodyRepository) FindByLogID(ctx context.Context, logID string) (*domain.APILogBody, error) {
 	filter := bson.M{"log_id": logID}
@@ -44,6 +61,30 @@ func (r *bodyRepository) FindByLogID(ctx context.Context, logID string) (*domain
 	return documentToBody(&doc), nil
 }
 
+// FindByLogIDs retrieves bodies for multiple log IDs
+func (r *bodyRepository) FindByLogIDs(ctx context.Context, logIDs []string) ([]*domain.APILogBody, error) {
+	if len(logIDs) == 0 {
+		return nil, nil
+	}
+
+	cursor, err := r.collection.Find(ctx, bson.M{"log_id": bson.M{"$in": logIDs}})
+	if err != nil {
+		return nil, err
+	}
+	defer cursor.Close(ctx)
+
+	var docs []apiLogBodyDocument
+	if err := cursor.All(ctx, &docs); err != nil {
+		return nil, err
+	}
+
+	found := make([]*domain.APILogBody, len(docs))
+	for i := range docs {
+		found[i] = documentToBody(&docs[i])
+	}
+	return found, nil
+}
+
 // Delete removes body by log ID
 func (r *bodyRepository) Delete(ctx context.Context, logID string) error {
 	filter := bson.M{"log_id": logID}
@@ -53,7 +94,42 @@ func (r *bodyRepository) Delete(ctx context.Context, logID string) error {
 
 // DeleteBatch removes bodies for multiple log IDs
 func (r *bodyRepository) DeleteBatch(ctx context.Context, logIDs []string) error {
+	if len(logIDs) == 0 {
+		return nil
+	}
+
 	filter := bson.M{"log_id": bson.M{"$in": logIDs}}
 	_, err := r.collection.DeleteMany(ctx, filter)
 	return err
 }
+
+// FindOlderThan retrieves a batch of a project's expired bodies, oldest first
+func (r *bodyRepository) FindOlderThan(ctx context.Context, projectID string, before time.Time, limit int) ([]*domain.APILogBody, error) {
+	opts := options.Find().SetSort(bson.D{{Key: "created_at", Value: 1}}).SetLimit(int64(limit))
+	cursor, err := r.collection.Find(ctx, olderThanFilter("created_at", projectID, before), opts)
+	if err != nil {
+		return nil, err
+	}
+	defer cursor.Close(ctx)
+
+	var docs []apiLogBodyDocument
+	if err := cursor.All(ctx, &docs); err != nil {
+		return nil, err
+	}
+
+	found := make([]*domain.APILogBody, len(docs))
+	for i := range docs {
+		found[i] = documentToBody(&docs[i])
+	}
+	return found, nil
+}
+
+// DeleteOlderThan removes a batch of a project's expired bodies
+func (r *bodyRepository) DeleteOlderThan(ctx context.Context, projectID string, before time.Time, limit int) (int64, error) {
+	return deleteOlderThan(ctx, r.collection, "created_at", projectID, before, limit)
+}
+
+// VolumeOlderThan measures a project's bodies stored before a time
+func (r *bodyRepository) VolumeOlderThan(ctx context.Context, projectID string, before time.Time) (domain.DataVolume, error) {
+	return volumeOlderThan(ctx, r.collection, "created_at", projectID, before)
+}
@@ -2,28 +2,38 @@ package mongodb
 
 import (
 	"context"
+	"errors"
 	"time"
 
+	"github.com/spidey52/api-logs/internal/domain"
 	"go.mongodb.org/mongo-driver/bson"
 	"go.mongodb.org/mongo-driver/mongo"
 	"go.mongodb.org/mongo-driver/mongo/options"
 	"go.mongodb.org/mongo-driver/mongo/readpref"
 )
 
+// Server error codes returned when dropping an index that is not there
+const (
+	codeNamespaceNotFound = 26
+	codeIndexNotFound     = 27
+)
+
 const (
 	// Collection names
-	CollectionProjects        = "projects"
-	CollectionAPILogs         = "api_logs"
-	CollectionAPILogSummaries = "api_log_summaries"
-	CollectionAPILogHeaders   = "api_log_headers"
-	CollectionAPILogBodies    = "api_log_bodies"
-	CollectionUsers           = "users"
-	CollectionAccessLogs      = "access_logs"
-
-	// TTL durations
-	LogsTTLDays    = 30
-	HeadersTTLDays = 30
-	BodiesTTLDays  = 14
+	CollectionProjects          = "projects"
+	CollectionAPILogs           = "api_logs"
+	CollectionAPILogSummaries   = "api_log_summaries"
+	CollectionAPILogHeaders     = "api_log_headers"
+	CollectionAPILogBodies      = "api_log_bodies"
+	CollectionUsers             = "users"
+	CollectionAccessLogs        = "access_logs"
+	CollectionAccounts          = "accounts"
+	CollectionSessions          = "sessions"
+	CollectionProjectMembers    = "project_members"
+	CollectionAPIKeys           = "api_keys"
+	CollectionProjectQuotas     = "project_quotas"
+	CollectionRedactionPolicies = "redaction_policies"
+	CollectionDataKeys          = "data_keys"
 )
 
 // Client wraps MongoDB client and database
@@ -78,12 +88,17 @@ func (c *Client) Collection(name string) *mongo.Collection {
 
 // CreateIndexes creates all required indexes for collections
 func (c *Client) CreateIndexes(ctx context.Context) error {
-	// Projects indexes
+	// Projects indexes. Keys are stored hashed in api_key_hash; documents
+	// written before that keep the plaintext in api_key until
+	// HashPlaintextAPIKeys converts them, so the unique index skips them.
 	projectsCol := c.Collection(CollectionProjects)
+	if err := dropIndexIfExists(ctx, projectsCol, "api_key_1"); err != nil {
+		return err
+	}
 	_, err := projectsCol.Indexes().CreateMany(ctx, []mongo.IndexModel{
 		{
-			Keys:    bson.D{{Key: "api_key", Value: 1}},
-			Options: options.Index().SetUnique(true),
+			Keys:    bson.D{{Key: "api_key_hash", Value: 1}},
+			Options: options.Index().SetUnique(true).SetPartialFilterExpression(bson.M{"api_key_hash": bson.M{"$exists": true}}),
 		},
 		{
 			Keys: bson.D{{Key: "environment", Value: 1}},
@@ -96,8 +111,13 @@ func (c *Client) CreateIndexes(ctx context.Context) error {
 		return err
 	}
 
-	// API Logs indexes
+	// API Logs indexes. Expired logs, headers and bodies are purged by the
+	// retention worker, per project, instead of the TTL indexes earlier
+	// versions created.
 	logsCol := c.Collection(CollectionAPILogs)
+	if err := dropIndexIfExists(ctx, logsCol, "timestamp_1"); err != nil {
+		return err
+	}
 	_, err = logsCol.Indexes().CreateMany(ctx, []mongo.IndexModel{
 		{
 			Keys: bson.D{
@@ -117,10 +137,6 @@ func (c *Client) CreateIndexes(ctx context.Context) error {
 		{
 			Keys: bson.D{{Key: "user_id", Value: 1}},
 		},
-		{
-			Keys:    bson.D{{Key: "timestamp", Value: 1}},
-			Options: options.Index().SetExpireAfterSeconds(int32(LogsTTLDays * 24 * 60 * 60)),
-		},
 	})
 	if err != nil {
 		return err
@@ -128,14 +144,16 @@ func (c *Client) CreateIndexes(ctx context.Context) error {
 
 	// Headers indexes
 	headersCol := c.Collection(CollectionAPILogHeaders)
+	if err := dropIndexIfExists(ctx, headersCol, "created_at_1"); err != nil {
+		return err
+	}
 	_, err = headersCol.Indexes().CreateMany(ctx, []mongo.IndexModel{
 		{
 			Keys:    bson.D{{Key: "log_id", Value: 1}},
 			Options: options.Index().SetUnique(true),
 		},
 		{
-			Keys:    bson.D{{Key: "created_at", Value: 1}},
-			Options: options.Index().SetExpireAfterSeconds(int32(HeadersTTLDays * 24 * 60 * 60)),
+			Keys: bson.D{{Key: "project_id", Value: 1}, {Key: "created_at", Value: 1}},
 		},
 	})
 	if err != nil {
@@ -144,25 +162,31 @@ func (c *Client) CreateIndexes(ctx context.Context) error {
 
 	// Bodies indexes
 	bodiesCol := c.Collection(CollectionAPILogBodies)
+	if err := dropIndexIfExists(ctx, bodiesCol, "created_at_1"); err != nil {
+		return err
+	}
 	_, err = bodiesCol.Indexes().CreateMany(ctx, []mongo.IndexModel{
 		{
 			Keys:    bson.D{{Key: "log_id", Value: 1}},
 			Options: options.Index().SetUnique(true),
 		},
 		{
-			Keys:    bson.D{{Key: "created_at", Value: 1}},
-			Options: options.Index().SetExpireAfterSeconds(int32(BodiesTTLDays * 24 * 60 * 60)),
+			Keys: bson.D{{Key: "project_id", Value: 1}, {Key: "created_at", Value: 1}},
 		},
 	})
 	if err != nil {
 		return err
 	}
 
-	// Users indexes
+	// Users indexes; identifiers are unique per project, so drop the
+	// global identifier index created by earlier versions if present
 	usersCol := c.Collection(CollectionUsers)
+	if err := dropIndexIfExists(ctx, usersCol, "identifier_1"); err != nil {
+		return err
+	}
 	_, err = usersCol.Indexes().CreateMany(ctx, []mongo.IndexModel{
 		{
-			Keys:    bson.D{{Key: "identifier", Value: 1}},
+			Keys:    bson.D{{Key: "project_id", Value: 1}, {Key: "identifier", Value: 1}},
 			Options: options.Index().SetUnique(true),
 		},
 		{
@@ -179,11 +203,150 @@ func (c *Client) CreateIndexes(ctx context.Context) error {
 		{
 			Keys: bson.D{{Key: "project_id", Value: 1}, {Key: "actor_id", Value: 1}, {Key: "timestamp", Value: -1}},
 		},
+		{
+			Keys: bson.D{{Key: "project_id", Value: 1}, {Key: "timestamp", Value: 1}},
+		},
+	})
+
+	if err != nil {
+		return err
+	}
+
+	// Accounts indexes
+	accountsCol := c.Collection(CollectionAccounts)
+	_, err = accountsCol.Indexes().CreateMany(ctx, []mongo.IndexModel{
+		{
+			Keys:    bson.D{{Key: "username", Value: 1}},
+			Options: options.Index().SetUnique(true),
+		},
+	})
+	if err != nil {
+		return err
+	}
+
+	// Sessions indexes; expired sessions are removed by the TTL index
+	sessionsCol := c.Collection(CollectionSessions)
+	_, err = sessionsCol.Indexes().CreateMany(ctx, []mongo.IndexModel{
+		{
+			Keys:    bson.D{{Key: "token_hash", Value: 1}},
+			Options: options.Index().SetUnique(true),
+		},
+		{
+			Keys: bson.D{{Key: "account_id", Value: 1}},
+		},
+		{
+			Keys:    bson.D{{Key: "expires_at", Value: 1}},
+			Options: options.Index().SetExpireAfterSeconds(0),
+		},
+	})
+	if err != nil {
+		return err
+	}
+
+	// Project members indexes
+	membersCol := c.Collection(CollectionProjectMembers)
+	_, err = membersCol.Indexes().CreateMany(ctx, []mongo.IndexModel{
+		{
+			Keys:    bson.D{{Key: "project_id", Value: 1}, {Key: "account_id", Value: 1}},
+			Options: options.Index().SetUnique(true),
+		},
+		{
+			Keys: bson.D{{Key: "account_id", Value: 1}},
+		},
 	})
+	if err != nil {
+		return err
+	}
+
+	// API keys indexes
+	apiKeysCol := c.Collection(CollectionAPIKeys)
+	if err := dropIndexIfExists(ctx, apiKeysCol, "key_1"); err != nil {
+		return err
+	}
+	_, err = apiKeysCol.Indexes().CreateMany(ctx, []mongo.IndexModel{
+		{
+			Keys:    bson.D{{Key: "key_hash", Value: 1}},
+			Options: options.Index().SetUnique(true).SetPartialFilterExpression(bson.M{"key_hash": bson.M{"$exists": true}}),
+		},
+		{
+			Keys: bson.D{{Key: "project_id", Value: 1}, {Key: "created_at", Value: -1}},
+		},
+	})
+	if err != nil {
+		return err
+	}
 
+	// Log summaries indexes; a summary is unique by its bucket and dimensions
+	summariesCol := c.Collection(CollectionAPILogSummaries)
+	_, err = summariesCol.Indexes().CreateMany(ctx, []mongo.IndexModel{
+		{
+			Keys: bson.D{
+				{Key: "project_id", Value: 1},
+				{Key: "granularity", Value: 1},
+				{Key: "bucket", Value: 1},
+				{Key: "environment", Value: 1},
+				{Key: "method", Value: 1},
+				{Key: "route", Value: 1},
+			},
+			Options: options.Index().SetUnique(true),
+		},
+		{
+			Keys: bson.D{{Key: "granularity", Value: 1}, {Key: "bucket", Value: 1}},
+		},
+	})
 	if err != nil {
 		return err
 	}
 
 	return nil
 }
+
+// dropIndexIfExists drops an index that a previous version created
+func dropIndexIfExists(ctx context.Context, col *mongo.Collection, name string) error {
+	_, err := col.Indexes().DropOne(ctx, name)
+	var cmdErr mongo.CommandError
+	if errors.As(err, &cmdErr) && (cmdErr.Code == codeIndexNotFound || cmdErr.Code == codeNamespaceNotFound) {
+		return nil
+	}
+	return err
+}
+
+// hashPlaintextKeys converts documents without a key prefix to the hashed
+// form. Documents written before keys were hashed hold the plaintext in
+// plainField; later ones without a prefix hold it in hashField.
+func hashPlaintextKeys(ctx context.Context, col *mongo.Collection, plainField, hashField, prefixField string, hash func(string) string) (int, error) {
+	unconverted := bson.M{prefixField: bson.M{"$in": bson.A{nil, ""}}}
+	opts := options.Find().SetProjection(bson.M{plainField: 1, hashField: 1})
+	cursor, err := col.Find(ctx, unconverted, opts)
+	if err != nil {
+		return 0, err
+	}
+
+	var docs []bson.M
+	if err := cursor.All(ctx, &docs); err != nil {
+		return 0, err
+	}
+
+	converted := 0
+	for _, doc := range docs {
+		key, ok := doc[hashField].(string)
+		if !ok {
+			key, _ = doc[plainField].(string)
+		}
+		if key == "" {
+			continue
+		}
+
+		update := bson.M{
+			"$set":   bson.M{hashField: hash(key), prefixField: domain.KeyPrefix(key)},
+			"$unset": bson.M{plainField: ""},
+		}
+		filter := bson.M{"_id": doc["_id"], prefixField: bson.M{"$in": bson.A{nil, ""}}}
+		result, err := col.UpdateOne(ctx, filter, update)
+		if err != nil {
+			return converted, err
+		}
+		converted += int(result.ModifiedCount)
+	}
+	return converted, nil
+}
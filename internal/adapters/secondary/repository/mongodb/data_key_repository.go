@@ -0,0 +1,91 @@
+package mongodb
+
+import (
+	"context"
+
+	"github.com/spidey52/api-logs/internal/domain"
+	"github.com/spidey52/api-logs/internal/ports/output"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+// dataKeyRepository implements DataKeyRepository interface
+type dataKeyRepository struct {
+	collection *mongo.Collection
+}
+
+// NewDataKeyRepository creates a new MongoDB data key repository
+func NewDataKeyRepository(client *Client) output.DataKeyRepository {
+	return &dataKeyRepository{
+		collection: client.Collection(CollectionDataKeys),
+	}
+}
+
+// Find retrieves a project's data key
+func (r *dataKeyRepository) Find(ctx context.Context, projectID string) (*domain.DataKey, error) {
+	var key domain.DataKey
+	err := r.collection.FindOne(ctx, bson.M{"_id": projectID}).Decode(&key)
+	if err != nil {
+		if err == mongo.ErrNoDocuments {
+			return nil, domain.ErrDataKeyNotFound
+		}
+		return nil, err
+	}
+	return &key, nil
+}
+
+// Create stores a project's data key
+func (r *dataKeyRepository) Create(ctx context.Context, key *domain.DataKey) error {
+	_, err := r.collection.InsertOne(ctx, key)
+	if mongo.IsDuplicateKeyError(err) {
+		return domain.ErrDuplicateDataKey
+	}
+	return err
+}
+
+// Rewrap replaces the wrapping of a project's data key
+func (r *dataKeyRepository) Rewrap(ctx context.Context, key *domain.DataKey) error {
+	result, err := r.collection.UpdateOne(ctx, bson.M{"_id": key.ProjectID}, bson.M{"$set": bson.M{
+		"master_key_id": key.MasterKeyID,
+		"wrapped_key":   key.WrappedKey,
+		"updated_at":    key.UpdatedAt,
+	}})
+	if err != nil {
+		return err
+	}
+
+	if result.MatchedCount == 0 {
+		return domain.ErrDataKeyNotFound
+	}
+	return nil
+}
+
+// Delete removes a project's data key
+func (r *dataKeyRepository) Delete(ctx context.Context, projectID string) error {
+	result, err := r.collection.DeleteOne(ctx, bson.M{"_id": projectID})
+	if err != nil {
+		return err
+	}
+
+	if result.DeletedCount == 0 {
+		return domain.ErrDataKeyNotFound
+	}
+	return nil
+}
+
+// List retrieves the data keys of all projects
+func (r *dataKeyRepository) List(ctx context.Context) ([]*domain.DataKey, error) {
+	opts := options.Find().SetSort(bson.D{{Key: "_id", Value: 1}})
+	cursor, err := r.collection.Find(ctx, bson.M{}, opts)
+	if err != nil {
+		return nil, err
+	}
+	defer cursor.Close(ctx)
+
+	keys := []*domain.DataKey{}
+	if err := cursor.All(ctx, &keys); err != nil {
+		return nil, err
+	}
+	return keys, nil
+}
@@ -2,11 +2,13 @@ package mongodb
 
 import (
 	"context"
+	"time"
 
 	"github.com/spidey52/api-logs/internal/domain"
 	"github.com/spidey52/api-logs/internal/ports/output"
 	"go.mongodb.org/mongo-driver/bson"
 	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
 )
 
 // headersRepository implements APILogHeadersRepository interface
@@ -28,6 +30,21 @@ func (r *headersRepository) Create(ctx context.Context, headers *domain.APILogHe
 	return err
 }
 
+// CreateMany stores headers for multiple logs
+func (r *headersRepository) CreateMany(ctx context.Context, headers []*domain.APILogHeaders) error {
+	if len(headers) == 0 {
+		return nil
+	}
+
+	docs := make([]any, len(headers))
+	for i, h := range headers {
+		docs[i] = headersToDocument(h)
+	}
+
+	_, err := r.collection.InsertMany(ctx, docs, options.InsertMany().SetOrdered(false))
+	return ignoreDuplicates(err)
+}
+
 // FindByLogID retrieves headers by log ID
 func (r *headersRepository) FindByLogID(ctx context.Context, logID string) (*domain.APILogHeaders, error) {
 	filter := bson.M{"log_id": logID}
@@ -44,6 +61,30 @@ func (r *headersRepository) FindByLogID(ctx context.Context, logID string) (*dom
 	return documentToHeaders(&doc), nil
 }
 
+// FindByLogIDs retrieves headers for multiple log IDs
+func (r *headersRepository) FindByLogIDs(ctx context.Context, logIDs []string) ([]*domain.APILogHeaders, error) {
+	if len(logIDs) == 0 {
+		return nil, nil
+	}
+
+	cursor, err := r.collection.Find(ctx, bson.M{"log_id": bson.M{"$in": logIDs}})
+	if err != nil {
+		return nil, err
+	}
+	defer cursor.Close(ctx)
+
+	var docs []apiLogHeadersDocument
+	if err := cursor.All(ctx, &docs); err != nil {
+		return nil, err
+	}
+
+	found := make([]*domain.APILogHeaders, len(docs))
+	for i := range docs {
+		found[i] = documentToHeaders(&docs[i])
+	}
+	return found, nil
+}
+
 // Delete removes headers by log ID
 func (r *headersRepository) Delete(ctx context.Context, logID string) error {
 	filter := bson.M{"log_id": logID}
@@ -53,7 +94,42 @@ func (r *headersRepository) Delete(ctx context.Context, logID string) error {
 
 // DeleteBatch removes headers for multiple log IDs
 func (r *headersRepository) DeleteBatch(ctx context.Context, logIDs []string) error {
+	if len(logIDs) == 0 {
+		return nil
+	}
+
 	filter := bson.M{"log_id": bson.M{"$in": logIDs}}
 	_, err := r.collection.DeleteMany(ctx, filter)
 	return err
 }
+
+// FindOlderThan retrieves a batch of a project's expired headers, oldest first
+func (r *headersRepository) FindOlderThan(ctx context.Context, projectID string, before time.Time, limit int) ([]*domain.APILogHeaders, error) {
+	opts := options.Find().SetSort(bson.D{{Key: "created_at", Value: 1}}).SetLimit(int64(limit))
+	cursor, err := r.collection.Find(ctx, olderThanFilter("created_at", projectID, before), opts)
+	if err != nil {
+		return nil, err
+	}
+	defer cursor.Close(ctx)
+
+	var docs []apiLogHeadersDocument
+	if err := cursor.All(ctx, &docs); err != nil {
+		return nil, err
+	}
+
+	found := make([]*domain.APILogHeaders, len(docs))
+	for i := range docs {
+		found[i] = documentToHeaders(&docs[i])
+	}
+	return found, nil
+}
+
+// DeleteOlderThan removes a batch of a project's expired headers
+func (r *headersRepository) DeleteOlderThan(ctx context.Context, projectID string, before time.Time, limit int) (int64, error) {
+	return deleteOlderThan(ctx, r.collection, "created_at", projectID, before, limit)
+}
+
+// VolumeOlderThan measures a project's headers stored before a time
+func (r *headersRepository) VolumeOlderThan(ctx context.Context, projectID string, before time.Time) (domain.DataVolume, error) {
+	return volumeOlderThan(ctx, r.collection, "created_at", projectID, before)
+}
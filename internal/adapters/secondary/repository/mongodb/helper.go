@@ -1 +1,103 @@
 package mongodb
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/spidey52/api-logs/internal/domain"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+// duplicateKeyCode is the server error code for a unique index violation
+const duplicateKeyCode = 11000
+
+// ignoreDuplicates drops the duplicate key errors of an unordered InsertMany,
+// so documents that already exist are skipped while the rest are inserted
+func ignoreDuplicates(err error) error {
+	var bulkErr mongo.BulkWriteException
+	if !errors.As(err, &bulkErr) || bulkErr.WriteConcernError != nil {
+		return err
+	}
+	for _, writeErr := range bulkErr.WriteErrors {
+		if writeErr.Code != duplicateKeyCode {
+			return err
+		}
+	}
+	return nil
+}
+
+// olderThanFilter selects the documents of a project stored before a time.
+// Headers and bodies written before their project was recorded have none,
+// and are selected by an empty project ID.
+func olderThanFilter(timeField, projectID string, before time.Time) bson.M {
+	filter := bson.M{timeField: bson.M{"$lt": before}}
+	if projectID == "" {
+		filter["project_id"] = bson.M{"$in": bson.A{nil, ""}}
+	} else {
+		filter["project_id"] = projectID
+	}
+	return filter
+}
+
+// deleteOlderThan removes at most limit of the documents olderThanFilter
+// selects. DeleteMany has no limit, so their IDs are looked up first.
+func deleteOlderThan(ctx context.Context, col *mongo.Collection, timeField, projectID string, before time.Time, limit int) (int64, error) {
+	opts := options.Find().SetProjection(bson.M{"_id": 1}).SetLimit(int64(limit))
+	cursor, err := col.Find(ctx, olderThanFilter(timeField, projectID, before), opts)
+	if err != nil {
+		return 0, err
+	}
+
+	var docs []struct {
+		ID string `bson:"_id"`
+	}
+	if err := cursor.All(ctx, &docs); err != nil {
+		return 0, err
+	}
+	if len(docs) == 0 {
+		return 0, nil
+	}
+
+	ids := make(bson.A, len(docs))
+	for i, doc := range docs {
+		ids[i] = doc.ID
+	}
+	result, err := col.DeleteMany(ctx, bson.M{"_id": bson.M{"$in": ids}})
+	if err != nil {
+		return 0, err
+	}
+	return result.DeletedCount, nil
+}
+
+// volumeOlderThan measures the documents olderThanFilter selects by their
+// BSON size
+func volumeOlderThan(ctx context.Context, col *mongo.Collection, timeField, projectID string, before time.Time) (domain.DataVolume, error) {
+	cursor, err := col.Aggregate(ctx, mongo.Pipeline{
+		{{Key: "$match", Value: olderThanFilter(timeField, projectID, before)}},
+		{{Key: "$group", Value: bson.M{
+			"_id":       nil,
+			"documents": bson.M{"$sum": 1},
+			"bytes":     bson.M{"$sum": bson.M{"$bsonSize": "$$ROOT"}},
+		}}},
+	})
+	if err != nil {
+		return domain.DataVolume{}, err
+	}
+	defer cursor.Close(ctx)
+
+	var volume domain.DataVolume
+	if cursor.Next(ctx) {
+		var result struct {
+			Documents int64 `bson:"documents"`
+			Bytes     int64 `bson:"bytes"`
+		}
+		if err := cursor.Decode(&result); err != nil {
+			return volume, err
+		}
+		volume = domain.DataVolume{Documents: result.Documents, Bytes: result.Bytes}
+	}
+	return volume, cursor.Err()
+}
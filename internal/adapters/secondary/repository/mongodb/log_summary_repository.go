@@ -0,0 +1,103 @@
+package mongodb
+
+import (
+	"context"
+	"time"
+
+	"github.com/spidey52/api-logs/internal/domain"
+	"github.com/spidey52/api-logs/internal/ports/output"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+// logSummaryRepository implements LogSummaryRepository interface
+type logSummaryRepository struct {
+	collection *mongo.Collection
+}
+
+// NewLogSummaryRepository creates a new MongoDB log summary repository
+func NewLogSummaryRepository(client *Client) output.LogSummaryRepository {
+	return &logSummaryRepository{
+		collection: client.Collection(CollectionAPILogSummaries),
+	}
+}
+
+// Replace replaces a project's summaries of one granularity in a range. The
+// new summaries are upserted by their dimensions rather than inserted, so two
+// instances replacing the same range at once leave each summary once.
+func (r *logSummaryRepository) Replace(ctx context.Context, projectID string, granularity domain.SummaryGranularity, from, to time.Time, summaries []*domain.LogSummary) error {
+	_, err := r.collection.DeleteMany(ctx, bson.M{
+		"project_id":  projectID,
+		"granularity": string(granularity),
+		"bucket":      bson.M{"$gte": from, "$lt": to},
+	})
+	if err != nil || len(summaries) == 0 {
+		return err
+	}
+
+	models := make([]mongo.WriteModel, len(summaries))
+	for i, s := range summaries {
+		doc := summaryToDocument(s)
+		models[i] = mongo.NewReplaceOneModel().
+			SetFilter(bson.M{
+				"project_id":  doc.ProjectID,
+				"granularity": doc.Granularity,
+				"bucket":      doc.Bucket,
+				"environment": doc.Environment,
+				"method":      doc.Method,
+				"route":       doc.Route,
+			}).
+			SetReplacement(doc).
+			SetUpsert(true)
+	}
+	_, err = r.collection.BulkWrite(ctx, models, options.BulkWrite().SetOrdered(false))
+	return err
+}
+
+// Find retrieves the summaries matching the filter, oldest bucket first
+func (r *logSummaryRepository) Find(ctx context.Context, filter domain.SummaryFilter) ([]*domain.LogSummary, error) {
+	query := bson.M{
+		"project_id":  filter.ProjectID,
+		"granularity": string(filter.Granularity),
+		"bucket":      bson.M{"$gte": filter.From, "$lt": filter.To},
+	}
+	if filter.Environment != "" {
+		query["environment"] = string(filter.Environment)
+	}
+
+	opts := options.Find().SetSort(bson.D{
+		{Key: "bucket", Value: 1},
+		{Key: "environment", Value: 1},
+		{Key: "method", Value: 1},
+		{Key: "route", Value: 1},
+	})
+	cursor, err := r.collection.Find(ctx, query, opts)
+	if err != nil {
+		return nil, err
+	}
+	defer cursor.Close(ctx)
+
+	var docs []logSummaryDocument
+	if err := cursor.All(ctx, &docs); err != nil {
+		return nil, err
+	}
+
+	summaries := make([]*domain.LogSummary, len(docs))
+	for i := range docs {
+		summaries[i] = documentToSummary(&docs[i])
+	}
+	return summaries, nil
+}
+
+// DeleteOlderThan removes the summaries of one granularity before the cutoff
+func (r *logSummaryRepository) DeleteOlderThan(ctx context.Context, granularity domain.SummaryGranularity, before time.Time) (int64, error) {
+	result, err := r.collection.DeleteMany(ctx, bson.M{
+		"granularity": string(granularity),
+		"bucket":      bson.M{"$lt": before},
+	})
+	if err != nil {
+		return 0, err
+	}
+	return result.DeletedCount, nil
+}
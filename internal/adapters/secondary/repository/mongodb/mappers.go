@@ -1,23 +1,28 @@
 package mongodb
 
 import (
+	"strconv"
 	"time"
 
 	"github.com/spidey52/api-logs/internal/domain"
+	"go.mongodb.org/mongo-driver/bson/primitive"
 )
 
 // Document types for MongoDB collections
 
 // projectDocument represents the MongoDB document for projects
 type projectDocument struct {
-	ID          string    `bson:"_id"`
-	Name        string    `bson:"name"`
-	Description string    `bson:"description"`
-	APIKey      string    `bson:"api_key"`
-	Environment string    `bson:"environment"`
-	IsActive    bool      `bson:"is_active"`
-	CreatedAt   time.Time `bson:"created_at"`
-	UpdatedAt   time.Time `bson:"updated_at"`
+	ID           string    `bson:"_id"`
+	Name         string    `bson:"name"`
+	Description  string    `bson:"description"`
+	APIKeyHash   string    `bson:"api_key_hash"`
+	APIKeyPrefix string    `bson:"api_key_prefix"`
+	Environment  string    `bson:"environment"`
+	IsActive     bool      `bson:"is_active"`
+	CreatedAt    time.Time `bson:"created_at"`
+	UpdatedAt    time.Time `bson:"updated_at"`
+
+	Retention domain.RetentionPolicy `bson:"retention"`
 }
 
 // apiLogDocument represents the MongoDB document for API logs
@@ -43,6 +48,7 @@ type apiLogDocument struct {
 type apiLogHeadersDocument struct {
 	ID              string         `bson:"_id"`
 	LogID           string         `bson:"log_id"`
+	ProjectID       string         `bson:"project_id,omitempty"`
 	RequestHeaders  map[string]any `bson:"request_headers"`
 	ResponseHeaders map[string]any `bson:"response_headers"`
 	CreatedAt       time.Time      `bson:"created_at"`
@@ -50,8 +56,9 @@ type apiLogHeadersDocument struct {
 
 // apiLogBodyDocument represents the MongoDB document for bodies
 type apiLogBodyDocument struct {
-	ID    string `bson:"_id"`
-	LogID string `bson:"log_id"`
+	ID        string `bson:"_id"`
+	LogID     string `bson:"log_id"`
+	ProjectID string `bson:"project_id,omitempty"`
 	// RequestBody  map[string]any `bson:"request_body,omitempty"`
 	// ResponseBody map[string]any `bson:"response_body,omitempty"`
 	RequestBody  any `bson:"request_body,omitempty"`
@@ -76,14 +83,16 @@ type userDocument struct {
 
 func projectToDocument(p *domain.Project) *projectDocument {
 	return &projectDocument{
-		ID:          p.ID,
-		Name:        p.Name,
-		Description: p.Description,
-		APIKey:      p.APIKey,
-		Environment: string(p.Environment),
-		IsActive:    p.IsActive,
-		CreatedAt:   p.CreatedAt,
-		UpdatedAt:   p.UpdatedAt,
+		ID:           p.ID,
+		Name:         p.Name,
+		Description:  p.Description,
+		APIKeyHash:   p.APIKeyHash,
+		APIKeyPrefix: p.APIKeyPrefix,
+		Environment:  string(p.Environment),
+		IsActive:     p.IsActive,
+		CreatedAt:    p.CreatedAt,
+		UpdatedAt:    p.UpdatedAt,
+		Retention:    p.Retention,
 	}
 }
 
@@ -111,6 +120,7 @@ func headersToDocument(h *domain.APILogHeaders) *apiLogHeadersDocument {
 	return &apiLogHeadersDocument{
 		ID:              h.ID,
 		LogID:           h.LogID,
+		ProjectID:       h.ProjectID,
 		RequestHeaders:  h.RequestHeaders,
 		ResponseHeaders: h.ResponseHeaders,
 		CreatedAt:       h.CreatedAt,
@@ -121,6 +131,7 @@ func bodyToDocument(b *domain.APILogBody) *apiLogBodyDocument {
 	return &apiLogBodyDocument{
 		ID:           b.ID,
 		LogID:        b.LogID,
+		ProjectID:    b.ProjectID,
 		RequestBody:  b.RequestBody,
 		ResponseBody: b.ResponseBody,
 		CreatedAt:    b.CreatedAt,
@@ -142,14 +153,16 @@ func userToDocument(u *domain.User) *userDocument {
 
 func documentToProject(doc *projectDocument) *domain.Project {
 	return &domain.Project{
-		ID:          doc.ID,
-		Name:        doc.Name,
-		Description: doc.Description,
-		APIKey:      doc.APIKey,
-		Environment: domain.Environment(doc.Environment),
-		IsActive:    doc.IsActive,
-		CreatedAt:   doc.CreatedAt,
-		UpdatedAt:   doc.UpdatedAt,
+		ID:           doc.ID,
+		Name:         doc.Name,
+		Description:  doc.Description,
+		APIKeyHash:   doc.APIKeyHash,
+		APIKeyPrefix: doc.APIKeyPrefix,
+		Environment:  domain.Environment(doc.Environment),
+		IsActive:     doc.IsActive,
+		CreatedAt:    doc.CreatedAt,
+		UpdatedAt:    doc.UpdatedAt,
+		Retention:    doc.Retention,
 	}
 }
 
@@ -177,6 +190,7 @@ func documentToHeaders(doc *apiLogHeadersDocument) *domain.APILogHeaders {
 	return &domain.APILogHeaders{
 		ID:              doc.ID,
 		LogID:           doc.LogID,
+		ProjectID:       doc.ProjectID,
 		RequestHeaders:  doc.RequestHeaders,
 		ResponseHeaders: doc.ResponseHeaders,
 		CreatedAt:       doc.CreatedAt,
@@ -187,12 +201,23 @@ func documentToBody(doc *apiLogBodyDocument) *domain.APILogBody {
 	return &domain.APILogBody{
 		ID:           doc.ID,
 		LogID:        doc.LogID,
-		RequestBody:  doc.RequestBody,
-		ResponseBody: doc.ResponseBody,
+		ProjectID:    doc.ProjectID,
+		RequestBody:  encryptedBodyAsMap(doc.RequestBody),
+		ResponseBody: encryptedBodyAsMap(doc.ResponseBody),
 		CreatedAt:    doc.CreatedAt,
 	}
 }
 
+// encryptedBodyAsMap converts an encrypted body, which decodes as a
+// primitive.D, into the map the other backends return, so it is recognized as
+// encrypted
+func encryptedBodyAsMap(body any) any {
+	if d, ok := body.(primitive.D); ok && len(d) == 1 && d[0].Key == domain.EncryptedField {
+		return map[string]any(d.Map())
+	}
+	return body
+}
+
 func documentToUser(doc *userDocument) *domain.User {
 	return &domain.User{
 		ID:         doc.ID,
@@ -200,5 +225,85 @@ func documentToUser(doc *userDocument) *domain.User {
 		Identifier: doc.Identifier,
 		Metadata:   doc.Metadata,
 		CreatedAt:  doc.CreatedAt,
+		ProjectID:  doc.ProjectID,
+	}
+}
+
+// logSummaryDocument represents the MongoDB document for log summaries. BSON
+// keys must be strings, so status codes are keyed by their decimal form.
+type logSummaryDocument struct {
+	ProjectID   string           `bson:"project_id"`
+	Granularity string           `bson:"granularity"`
+	Bucket      time.Time        `bson:"bucket"`
+	Environment string           `bson:"environment"`
+	Method      string           `bson:"method"`
+	Route       string           `bson:"route"`
+	Count       int64            `bson:"count"`
+	ErrorCount  int64            `bson:"error_count"`
+	Status1xx   int64            `bson:"status_1xx"`
+	Status2xx   int64            `bson:"status_2xx"`
+	Status3xx   int64            `bson:"status_3xx"`
+	Status4xx   int64            `bson:"status_4xx"`
+	Status5xx   int64            `bson:"status_5xx"`
+	StatusCodes map[string]int64 `bson:"status_codes"`
+	LatencySum  int64            `bson:"latency_sum"`
+	LatencyMin  int64            `bson:"latency_min"`
+	LatencyMax  int64            `bson:"latency_max"`
+	Histogram   []int64          `bson:"latency_histogram"`
+}
+
+func summaryToDocument(s *domain.LogSummary) *logSummaryDocument {
+	statusCodes := make(map[string]int64, len(s.StatusCodes))
+	for code, count := range s.StatusCodes {
+		statusCodes[strconv.Itoa(code)] = count
+	}
+	return &logSummaryDocument{
+		ProjectID:   s.ProjectID,
+		Granularity: string(s.Granularity),
+		Bucket:      s.Bucket,
+		Environment: string(s.Environment),
+		Method:      string(s.Method),
+		Route:       s.Route,
+		Count:       s.Count,
+		ErrorCount:  s.ErrorCount,
+		Status1xx:   s.Status1xx,
+		Status2xx:   s.Status2xx,
+		Status3xx:   s.Status3xx,
+		Status4xx:   s.Status4xx,
+		Status5xx:   s.Status5xx,
+		StatusCodes: statusCodes,
+		LatencySum:  s.LatencySum,
+		LatencyMin:  s.LatencyMin,
+		LatencyMax:  s.LatencyMax,
+		Histogram:   s.Histogram,
+	}
+}
+
+func documentToSummary(doc *logSummaryDocument) *domain.LogSummary {
+	statusCodes := make(map[int]int64, len(doc.StatusCodes))
+	for code, count := range doc.StatusCodes {
+		if n, err := strconv.Atoi(code); err == nil {
+			statusCodes[n] = count
+		}
+	}
+	return &domain.LogSummary{
+		ProjectID:   doc.ProjectID,
+		Environment: domain.Environment(doc.Environment),
+		Granularity: domain.SummaryGranularity(doc.Granularity),
+		Bucket:      doc.Bucket.UTC(),
+		Method:      domain.HTTPMethod(doc.Method),
+		Route:       doc.Route,
+		Count:       doc.Count,
+		ErrorCount:  doc.ErrorCount,
+		Status1xx:   doc.Status1xx,
+		Status2xx:   doc.Status2xx,
+		Status3xx:   doc.Status3xx,
+		Status4xx:   doc.Status4xx,
+		Status5xx:   doc.Status5xx,
+		StatusCodes: statusCodes,
+		LatencySum:  doc.LatencySum,
+		LatencyMin:  doc.LatencyMin,
+		LatencyMax:  doc.LatencyMax,
+		Histogram:   doc.Histogram,
 	}
 }
@@ -0,0 +1,124 @@
+package mongodb
+
+import (
+	"context"
+	"os"
+	"strings"
+	"testing"
+
+	"github.com/google/uuid"
+	"github.com/spidey52/api-logs/internal/adapters/secondary/repository/contract"
+	"github.com/spidey52/api-logs/internal/ports/output"
+)
+
+// openTestClient connects to TEST_MONGODB_URI using a throwaway database that
+// is dropped when the test ends. Tests are skipped when the variable is unset.
+func openTestClient(t *testing.T) *Client {
+	t.Helper()
+
+	uri := os.Getenv("TEST_MONGODB_URI")
+	if uri == "" {
+		t.Skip("TEST_MONGODB_URI not set")
+	}
+
+	ctx := context.Background()
+	dbName := "api_logs_test_" + strings.ReplaceAll(uuid.New().String(), "-", "")[:12]
+
+	client, err := NewClient(ctx, uri, dbName)
+	if err != nil {
+		t.Fatalf("connect mongodb: %v", err)
+	}
+	t.Cleanup(func() {
+		client.Database().Drop(ctx)
+		client.Close(ctx)
+	})
+
+	if err := client.CreateIndexes(ctx); err != nil {
+		t.Fatalf("create indexes: %v", err)
+	}
+	return client
+}
+
+func TestAPILogRepository(t *testing.T) {
+	contract.RunAPILogRepository(t, func(t *testing.T) output.APILogRepository {
+		return NewAPILogRepository(openTestClient(t), nil)
+	})
+}
+
+func TestHeadersRepository(t *testing.T) {
+	contract.RunHeadersRepository(t, func(t *testing.T) output.APILogHeadersRepository {
+		return NewHeadersRepository(openTestClient(t))
+	})
+}
+
+func TestBodyRepository(t *testing.T) {
+	contract.RunBodyRepository(t, func(t *testing.T) output.APILogBodyRepository {
+		return NewBodyRepository(openTestClient(t))
+	})
+}
+
+func TestProjectRepository(t *testing.T) {
+	contract.RunProjectRepository(t, func(t *testing.T) output.ProjectRepository {
+		return NewProjectRepository(openTestClient(t))
+	})
+}
+
+func TestUserRepository(t *testing.T) {
+	contract.RunUserRepository(t, func(t *testing.T) output.UserRepository {
+		return NewUserRepository(openTestClient(t))
+	})
+}
+
+func TestAccessLogRepository(t *testing.T) {
+	contract.RunAccessLogRepository(t, func(t *testing.T) output.AccessLogRepository {
+		return NewMongoAccessLogRepository(openTestClient(t))
+	})
+}
+
+func TestAccountRepository(t *testing.T) {
+	contract.RunAccountRepository(t, func(t *testing.T) output.AccountRepository {
+		return NewAccountRepository(openTestClient(t))
+	})
+}
+
+func TestSessionRepository(t *testing.T) {
+	contract.RunSessionRepository(t, func(t *testing.T) output.SessionRepository {
+		return NewSessionRepository(openTestClient(t))
+	})
+}
+
+func TestProjectMemberRepository(t *testing.T) {
+	contract.RunProjectMemberRepository(t, func(t *testing.T) output.ProjectMemberRepository {
+		return NewProjectMemberRepository(openTestClient(t))
+	})
+}
+
+func TestAPIKeyRepository(t *testing.T) {
+	contract.RunAPIKeyRepository(t, func(t *testing.T) output.APIKeyRepository {
+		return NewAPIKeyRepository(openTestClient(t))
+	})
+}
+
+func TestQuotaRepository(t *testing.T) {
+	contract.RunQuotaRepository(t, func(t *testing.T) output.QuotaRepository {
+		return NewQuotaRepository(openTestClient(t))
+	})
+}
+
+func TestRedactionPolicyRepository(t *testing.T) {
+	contract.RunRedactionPolicyRepository(t, func(t *testing.T) output.RedactionPolicyRepository {
+		return NewRedactionPolicyRepository(openTestClient(t))
+	})
+}
+
+func TestDataKeyRepository(t *testing.T) {
+	contract.RunDataKeyRepository(t, func(t *testing.T) output.DataKeyRepository {
+		return NewDataKeyRepository(openTestClient(t))
+	})
+}
+
+func TestLogSummaryRepository(t *testing.T) {
+	contract.RunLogSummaryRepository(t, func(t *testing.T) output.LogSummaryRepository {
+		return NewLogSummaryRepository(openTestClient(t))
+	})
+}
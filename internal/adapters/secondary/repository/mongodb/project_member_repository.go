@@ -0,0 +1,112 @@
+package mongodb
+
+import (
+	"context"
+
+	"github.com/spidey52/api-logs/internal/domain"
+	"github.com/spidey52/api-logs/internal/ports/output"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+// projectMemberRepository implements ProjectMemberRepository interface
+type projectMemberRepository struct {
+	collection *mongo.Collection
+}
+
+// NewProjectMemberRepository creates a new MongoDB project member repository
+func NewProjectMemberRepository(client *Client) output.ProjectMemberRepository {
+	return &projectMemberRepository{
+		collection: client.Collection(CollectionProjectMembers),
+	}
+}
+
+// Create stores a new membership
+func (r *projectMemberRepository) Create(ctx context.Context, member *domain.ProjectMember) error {
+	_, err := r.collection.InsertOne(ctx, member)
+	if mongo.IsDuplicateKeyError(err) {
+		return domain.ErrDuplicateMember
+	}
+	return err
+}
+
+// Find retrieves the membership of an account in a project
+func (r *projectMemberRepository) Find(ctx context.Context, projectID, accountID string) (*domain.ProjectMember, error) {
+	filter := bson.M{"project_id": projectID, "account_id": accountID}
+
+	var member domain.ProjectMember
+	err := r.collection.FindOne(ctx, filter).Decode(&member)
+	if err != nil {
+		if err == mongo.ErrNoDocuments {
+			return nil, domain.ErrMemberNotFound
+		}
+		return nil, err
+	}
+	return &member, nil
+}
+
+// Update updates the role of an existing membership
+func (r *projectMemberRepository) Update(ctx context.Context, member *domain.ProjectMember) error {
+	filter := bson.M{"project_id": member.ProjectID, "account_id": member.AccountID}
+	update := bson.M{
+		"$set": bson.M{
+			"role":       member.Role,
+			"updated_at": member.UpdatedAt,
+		},
+	}
+
+	result, err := r.collection.UpdateOne(ctx, filter, update)
+	if err != nil {
+		return err
+	}
+
+	if result.MatchedCount == 0 {
+		return domain.ErrMemberNotFound
+	}
+	return nil
+}
+
+// Delete removes the membership of an account in a project
+func (r *projectMemberRepository) Delete(ctx context.Context, projectID, accountID string) error {
+	result, err := r.collection.DeleteOne(ctx, bson.M{"project_id": projectID, "account_id": accountID})
+	if err != nil {
+		return err
+	}
+
+	if result.DeletedCount == 0 {
+		return domain.ErrMemberNotFound
+	}
+	return nil
+}
+
+// DeleteByProject removes every membership of a project
+func (r *projectMemberRepository) DeleteByProject(ctx context.Context, projectID string) error {
+	_, err := r.collection.DeleteMany(ctx, bson.M{"project_id": projectID})
+	return err
+}
+
+// ListByProject retrieves the members of a project, oldest first
+func (r *projectMemberRepository) ListByProject(ctx context.Context, projectID string) ([]*domain.ProjectMember, error) {
+	return r.list(ctx, bson.M{"project_id": projectID})
+}
+
+// ListByAccount retrieves the memberships of an account, oldest first
+func (r *projectMemberRepository) ListByAccount(ctx context.Context, accountID string) ([]*domain.ProjectMember, error) {
+	return r.list(ctx, bson.M{"account_id": accountID})
+}
+
+func (r *projectMemberRepository) list(ctx context.Context, filter bson.M) ([]*domain.ProjectMember, error) {
+	opts := options.Find().SetSort(bson.D{{Key: "created_at", Value: 1}, {Key: "_id", Value: 1}})
+	cursor, err := r.collection.Find(ctx, filter, opts)
+	if err != nil {
+		return nil, err
+	}
+	defer cursor.Close(ctx)
+
+	members := []*domain.ProjectMember{}
+	if err := cursor.All(ctx, &members); err != nil {
+		return nil, err
+	}
+	return members, nil
+}
@@ -51,9 +51,9 @@ func (r *projectRepository) FindByID(ctx context.Context, id string) (*domain.Pr
 	return documentToProject(&doc), nil
 }
 
-// FindByAPIKey retrieves a project by API key
-func (r *projectRepository) FindByAPIKey(ctx context.Context, apiKey string) (*domain.Project, error) {
-	filter := bson.M{"api_key": apiKey}
+// FindByAPIKey retrieves a project by the hash of its API key
+func (r *projectRepository) FindByAPIKey(ctx context.Context, apiKeyHash string) (*domain.Project, error) {
+	filter := bson.M{"api_key_hash": apiKeyHash}
 	var doc projectDocument
 
 	err := r.collection.FindOne(ctx, filter).Decode(&doc)
@@ -79,6 +79,10 @@ func (r *projectRepository) FindAll(ctx context.Context, filter domain.ProjectFi
 		mongoFilter["is_active"] = *filter.IsActive
 	}
 
+	if len(filter.IDs) > 0 {
+		mongoFilter["_id"] = bson.M{"$in": filter.IDs}
+	}
+
 	opts := options.Find().
 		SetLimit(int64(filter.Limit)).
 		SetSkip(int64(filter.Offset)).
@@ -108,12 +112,14 @@ func (r *projectRepository) Update(ctx context.Context, project *domain.Project)
 	filter := bson.M{"_id": project.ID}
 	update := bson.M{
 		"$set": bson.M{
-			"name":        project.Name,
-			"description": project.Description,
-			"api_key":     project.APIKey,
-			"environment": project.Environment,
-			"is_active":   project.IsActive,
-			"updated_at":  project.UpdatedAt,
+			"name":           project.Name,
+			"description":    project.Description,
+			"api_key_hash":   project.APIKeyHash,
+			"api_key_prefix": project.APIKeyPrefix,
+			"environment":    project.Environment,
+			"is_active":      project.IsActive,
+			"updated_at":     project.UpdatedAt,
+			"retention":      project.Retention,
 		},
 	}
 
@@ -145,12 +151,17 @@ func (r *projectRepository) Delete(ctx context.Context, id string) error {
 	return nil
 }
 
-// ExistsByAPIKey checks if an API key already exists
-func (r *projectRepository) ExistsByAPIKey(ctx context.Context, apiKey string) (bool, error) {
-	filter := bson.M{"api_key": apiKey}
+// ExistsByAPIKey checks if an API key hash already exists
+func (r *projectRepository) ExistsByAPIKey(ctx context.Context, apiKeyHash string) (bool, error) {
+	filter := bson.M{"api_key_hash": apiKeyHash}
 	count, err := r.collection.CountDocuments(ctx, filter)
 	if err != nil {
 		return false, err
 	}
 	return count > 0, nil
 }
+
+// HashPlaintextAPIKeys hashes the keys of projects stored before keys were hashed
+func (r *projectRepository) HashPlaintextAPIKeys(ctx context.Context, hash func(key string) string) (int, error) {
+	return hashPlaintextKeys(ctx, r.collection, "api_key", "api_key_hash", "api_key_prefix", hash)
+}
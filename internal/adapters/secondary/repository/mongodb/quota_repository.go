@@ -0,0 +1,55 @@
+package mongodb
+
+import (
+	"context"
+
+	"github.com/spidey52/api-logs/internal/domain"
+	"github.com/spidey52/api-logs/internal/ports/output"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+// quotaRepository implements QuotaRepository interface
+type quotaRepository struct {
+	collection *mongo.Collection
+}
+
+// NewQuotaRepository creates a new MongoDB quota repository
+func NewQuotaRepository(client *Client) output.QuotaRepository {
+	return &quotaRepository{
+		collection: client.Collection(CollectionProjectQuotas),
+	}
+}
+
+// Find retrieves a project's quota
+func (r *quotaRepository) Find(ctx context.Context, projectID string) (*domain.ProjectQuota, error) {
+	var quota domain.ProjectQuota
+	err := r.collection.FindOne(ctx, bson.M{"_id": projectID}).Decode(&quota)
+	if err != nil {
+		if err == mongo.ErrNoDocuments {
+			return nil, domain.ErrQuotaNotFound
+		}
+		return nil, err
+	}
+	return &quota, nil
+}
+
+// Upsert creates or replaces a project's quota
+func (r *quotaRepository) Upsert(ctx context.Context, quota *domain.ProjectQuota) error {
+	_, err := r.collection.ReplaceOne(ctx, bson.M{"_id": quota.ProjectID}, quota, options.Replace().SetUpsert(true))
+	return err
+}
+
+// Delete removes a project's quota
+func (r *quotaRepository) Delete(ctx context.Context, projectID string) error {
+	result, err := r.collection.DeleteOne(ctx, bson.M{"_id": projectID})
+	if err != nil {
+		return err
+	}
+
+	if result.DeletedCount == 0 {
+		return domain.ErrQuotaNotFound
+	}
+	return nil
+}
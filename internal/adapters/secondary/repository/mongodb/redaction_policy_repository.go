@@ -0,0 +1,55 @@
+package mongodb
+
+import (
+	"context"
+
+	"github.com/spidey52/api-logs/internal/domain"
+	"github.com/spidey52/api-logs/internal/ports/output"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+// redactionPolicyRepository implements RedactionPolicyRepository interface
+type redactionPolicyRepository struct {
+	collection *mongo.Collection
+}
+
+// NewRedactionPolicyRepository creates a new MongoDB redaction policy repository
+func NewRedactionPolicyRepository(client *Client) output.RedactionPolicyRepository {
+	return &redactionPolicyRepository{
+		collection: client.Collection(CollectionRedactionPolicies),
+	}
+}
+
+// Find retrieves a project's redaction policy
+func (r *redactionPolicyRepository) Find(ctx context.Context, projectID string) (*domain.RedactionPolicy, error) {
+	var policy domain.RedactionPolicy
+	err := r.collection.FindOne(ctx, bson.M{"_id": projectID}).Decode(&policy)
+	if err != nil {
+		if err == mongo.ErrNoDocuments {
+			return nil, domain.ErrRedactionPolicyNotFound
+		}
+		return nil, err
+	}
+	return &policy, nil
+}
+
+// Upsert creates or replaces a project's redaction policy
+func (r *redactionPolicyRepository) Upsert(ctx context.Context, policy *domain.RedactionPolicy) error {
+	_, err := r.collection.ReplaceOne(ctx, bson.M{"_id": policy.ProjectID}, policy, options.Replace().SetUpsert(true))
+	return err
+}
+
+// Delete removes a project's redaction policy
+func (r *redactionPolicyRepository) Delete(ctx context.Context, projectID string) error {
+	result, err := r.collection.DeleteOne(ctx, bson.M{"_id": projectID})
+	if err != nil {
+		return err
+	}
+
+	if result.DeletedCount == 0 {
+		return domain.ErrRedactionPolicyNotFound
+	}
+	return nil
+}
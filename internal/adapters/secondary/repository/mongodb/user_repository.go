@@ -24,6 +24,9 @@ func NewUserRepository(client *Client) output.UserRepository {
 func (r *userRepository) Create(ctx context.Context, user *domain.User) error {
 	doc := userToDocument(user)
 	_, err := r.collection.InsertOne(ctx, doc)
+	if mongo.IsDuplicateKeyError(err) {
+		return domain.ErrDuplicateUserIdentifier
+	}
 	return err
 }
 
@@ -94,14 +97,20 @@ func (r *userRepository) Delete(ctx context.Context, id string) error {
 	return nil
 }
 
-func (r *userRepository) List(ctx context.Context, page, pageSize int) ([]*domain.User, int, error) {
+// DeleteByProject removes every user of a project
+func (r *userRepository) DeleteByProject(ctx context.Context, projectID string) error {
+	_, err := r.collection.DeleteMany(ctx, bson.M{"project_id": projectID})
+	return err
+}
+
+func (r *userRepository) List(ctx context.Context, projectID string, page, pageSize int) ([]*domain.User, int, error) {
 	skip := (page - 1) * pageSize
 	opts := options.Find().
 		SetSkip(int64(skip)).
 		SetLimit(int64(pageSize)).
 		SetSort(bson.M{"created_at": -1})
 
-	cursor, err := r.collection.Find(ctx, bson.M{}, opts)
+	cursor, err := r.collection.Find(ctx, bson.M{"project_id": projectID}, opts)
 	if err != nil {
 		return nil, 0, err
 	}
@@ -121,7 +130,7 @@ func (r *userRepository) List(ctx context.Context, page, pageSize int) ([]*domai
 	}
 
 	// Get total count
-	total, err := r.collection.CountDocuments(ctx, bson.M{})
+	total, err := r.collection.CountDocuments(ctx, bson.M{"project_id": projectID})
 	if err != nil {
 		return users, 0, err
 	}
@@ -130,6 +139,10 @@ func (r *userRepository) List(ctx context.Context, page, pageSize int) ([]*domai
 }
 
 func (r *userRepository) GetUserMap(ctx context.Context, ids []string) (map[string]*domain.User, error) {
+	if len(ids) == 0 {
+		return map[string]*domain.User{}, nil
+	}
+
 	filter := bson.M{"_id": bson.M{"$in": ids}}
 	cursor, err := r.collection.Find(ctx, filter)
 	if err != nil {
@@ -0,0 +1,227 @@
+package postgres
+
+import (
+	"context"
+	"encoding/json"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+	"github.com/spidey52/api-logs/internal/domain"
+	"github.com/spidey52/api-logs/internal/ports/output"
+)
+
+type AccessLogRepository struct {
+	pool *pgxpool.Pool
+}
+
+func NewAccessLogRepository(pool *pgxpool.Pool) *AccessLogRepository {
+	return &AccessLogRepository{pool: pool}
+}
+
+var _ output.AccessLogRepository = (*AccessLogRepository)(nil)
+
+var accessLogColumns = []string{
+	"id", "project_id", "timestamp", "actor_id", "actor_type", "action",
+	"resource_type", "resource_id", "outcome", "message", "metadata",
+}
+
+// buildAccessLogWhere translates the filter into a WHERE clause and its arguments
+func buildAccessLogWhere(filter domain.AccessLogFilter) (string, []interface{}) {
+	conditions := []string{}
+	args := []interface{}{}
+
+	add := func(condition string, value interface{}) {
+		args = append(args, value)
+		conditions = append(conditions, strings.ReplaceAll(condition, "?", "$"+strconv.Itoa(len(args))))
+	}
+
+	if filter.ProjectID != "" {
+		add("project_id = ?", filter.ProjectID)
+	}
+	if filter.ActorID != "" {
+		add("actor_id = ?", filter.ActorID)
+	}
+	if filter.ActorType != nil {
+		add("actor_type = ?", string(*filter.ActorType))
+	}
+	if filter.Action != "" {
+		add("action = ?", filter.Action)
+	}
+	if filter.ResourceType != "" {
+		add("resource_type = ?", filter.ResourceType)
+	}
+	if filter.ResourceID != "" {
+		add("resource_id = ?", filter.ResourceID)
+	}
+	if filter.Outcome != nil {
+		add("outcome = ?", string(*filter.Outcome))
+	}
+	if filter.Search != "" {
+		add("message ILIKE ?", "%"+escapeLike(filter.Search)+"%")
+	}
+	if len(filter.Metadata) > 0 {
+		// containment lets the GIN index on metadata serve the lookup
+		metadataJSON, _ := json.Marshal(filter.Metadata)
+		add("metadata @> ?::jsonb", string(metadataJSON))
+	}
+	if filter.FromDate != nil {
+		add("timestamp >= ?", *filter.FromDate)
+	}
+	if filter.ToDate != nil {
+		add("timestamp <= ?", *filter.ToDate)
+	}
+
+	if len(conditions) == 0 {
+		return "", args
+	}
+	return " WHERE " + strings.Join(conditions, " AND "), args
+}
+
+// Create implements output.AccessLogRepository.
+func (r *AccessLogRepository) Create(ctx context.Context, log *domain.AccessLog) error {
+	metadata := log.Metadata
+	if metadata == nil {
+		metadata = map[string]any{}
+	}
+	metadataJSON, err := json.Marshal(metadata)
+	if err != nil {
+		return err
+	}
+
+	_, err = r.pool.Exec(ctx, `
+		INSERT INTO access_logs (
+			id, project_id, timestamp, actor_id, actor_type, action,
+			resource_type, resource_id, outcome, message, metadata
+		) VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11)`,
+		log.ID, log.ProjectID, log.Timestamp, log.ActorID, string(log.ActorType), log.Action,
+		log.ResourceType, log.ResourceID, string(log.Outcome), log.Message, metadataJSON,
+	)
+	return err
+}
+
+// CreateMany implements output.AccessLogRepository using COPY for bulk loads.
+func (r *AccessLogRepository) CreateMany(ctx context.Context, logs []*domain.AccessLog) error {
+	if len(logs) == 0 {
+		return nil
+	}
+
+	_, err := r.pool.CopyFrom(ctx, pgx.Identifier{"access_logs"}, accessLogColumns,
+		pgx.CopyFromSlice(len(logs), func(i int) ([]interface{}, error) {
+			log := logs[i]
+			metadata := log.Metadata
+			if metadata == nil {
+				metadata = map[string]any{}
+			}
+			return []interface{}{
+				log.ID, log.ProjectID, log.Timestamp, log.ActorID, string(log.ActorType), log.Action,
+				log.ResourceType, log.ResourceID, string(log.Outcome), log.Message, metadata,
+			}, nil
+		}),
+	)
+	return err
+}
+
+// FindByID implements output.AccessLogRepository.
+func (r *AccessLogRepository) FindByID(ctx context.Context, id string) (*domain.AccessLog, error) {
+	row := r.pool.QueryRow(ctx, `
+		SELECT `+strings.Join(accessLogColumns, ", ")+`
+		FROM access_logs WHERE id = $1`, id)
+
+	log, err := scanAccessLog(row)
+	if err != nil {
+		if err == pgx.ErrNoRows {
+			return nil, domain.ErrAccessLogNotFound
+		}
+		return nil, err
+	}
+	return log, nil
+}
+
+// FindByFilter implements output.AccessLogRepository.
+func (r *AccessLogRepository) FindByFilter(ctx context.Context, filter domain.AccessLogFilter) ([]*domain.AccessLog, error) {
+	where, args := buildAccessLogWhere(filter)
+
+	query := `SELECT ` + strings.Join(accessLogColumns, ", ") + ` FROM access_logs` + where + ` ORDER BY timestamp DESC`
+	if filter.Limit > 0 {
+		args = append(args, filter.Limit)
+		query += ` LIMIT $` + strconv.Itoa(len(args))
+	}
+	if filter.Offset > 0 {
+		args = append(args, filter.Offset)
+		query += ` OFFSET $` + strconv.Itoa(len(args))
+	}
+
+	rows, err := r.pool.Query(ctx, query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	logs := []*domain.AccessLog{}
+	for rows.Next() {
+		log, err := scanAccessLog(rows)
+		if err != nil {
+			return nil, err
+		}
+		logs = append(logs, log)
+	}
+	return logs, rows.Err()
+}
+
+// CountByFilter implements output.AccessLogRepository.
+func (r *AccessLogRepository) CountByFilter(ctx context.Context, filter domain.AccessLogFilter) (int64, error) {
+	where, args := buildAccessLogWhere(filter)
+
+	var count int64
+	err := r.pool.QueryRow(ctx, `SELECT COUNT(*) FROM access_logs`+where, args...).Scan(&count)
+	return count, err
+}
+
+// DeleteByID implements output.AccessLogRepository.
+func (r *AccessLogRepository) DeleteByID(ctx context.Context, id string) error {
+	_, err := r.pool.Exec(ctx, `DELETE FROM access_logs WHERE id = $1`, id)
+	return err
+}
+
+// DeleteByFilter implements output.AccessLogRepository.
+func (r *AccessLogRepository) DeleteByFilter(ctx context.Context, filter domain.AccessLogFilter) (int64, error) {
+	where, args := buildAccessLogWhere(filter)
+
+	tag, err := r.pool.Exec(ctx, `DELETE FROM access_logs`+where, args...)
+	if err != nil {
+		return 0, err
+	}
+	return tag.RowsAffected(), nil
+}
+
+func scanAccessLog(row pgx.Row) (*domain.AccessLog, error) {
+	var log domain.AccessLog
+	var actorType, outcome string
+	var metadataJSON []byte
+
+	err := row.Scan(
+		&log.ID, &log.ProjectID, &log.Timestamp, &log.ActorID, &actorType, &log.Action,
+		&log.ResourceType, &log.ResourceID, &outcome, &log.Message, &metadataJSON,
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	log.ActorType = domain.ActorType(actorType)
+	log.Outcome = domain.AccessOutcome(outcome)
+	json.Unmarshal(metadataJSON, &log.Metadata)
+	return &log, nil
+}
+
+// DeleteOlderThan implements output.AccessLogRepository.
+func (r *AccessLogRepository) DeleteOlderThan(ctx context.Context, projectID string, before time.Time, limit int) (int64, error) {
+	return deleteOlderThan(ctx, r.pool, "access_logs", "timestamp", projectID, before, limit)
+}
+
+// VolumeOlderThan implements output.AccessLogRepository.
+func (r *AccessLogRepository) VolumeOlderThan(ctx context.Context, projectID string, before time.Time) (domain.DataVolume, error) {
+	return volumeOlderThan(ctx, r.pool, "access_logs", "timestamp", projectID, before)
+}
@@ -0,0 +1,167 @@
+package postgres
+
+import (
+	"context"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+	"github.com/spidey52/api-logs/internal/domain"
+	"github.com/spidey52/api-logs/internal/ports/output"
+)
+
+type AccountRepository struct {
+	pool *pgxpool.Pool
+}
+
+func NewAccountRepository(pool *pgxpool.Pool) *AccountRepository {
+	return &AccountRepository{pool: pool}
+}
+
+var _ output.AccountRepository = (*AccountRepository)(nil)
+
+const accountColumns = `id, username, password_hash, is_active, is_admin, created_at, updated_at, last_login_at`
+
+func scanAccount(row pgx.Row) (*domain.Account, error) {
+	var account domain.Account
+	err := row.Scan(
+		&account.ID, &account.Username, &account.PasswordHash, &account.IsActive, &account.IsAdmin,
+		&account.CreatedAt, &account.UpdatedAt, &account.LastLoginAt,
+	)
+	if err != nil {
+		return nil, err
+	}
+	return &account, nil
+}
+
+// Create implements output.AccountRepository.
+func (r *AccountRepository) Create(ctx context.Context, account *domain.Account) error {
+	_, err := r.pool.Exec(ctx, `
+		INSERT INTO accounts (`+accountColumns+`)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8)`,
+		account.ID, account.Username, account.PasswordHash, account.IsActive, account.IsAdmin,
+		account.CreatedAt, account.UpdatedAt, account.LastLoginAt,
+	)
+	if isUniqueViolation(err) {
+		return domain.ErrDuplicateUsername
+	}
+	return err
+}
+
+// FindByID implements output.AccountRepository.
+func (r *AccountRepository) FindByID(ctx context.Context, id string) (*domain.Account, error) {
+	return r.findOne(ctx, `SELECT `+accountColumns+` FROM accounts WHERE id = $1`, id)
+}
+
+// FindByUsername implements output.AccountRepository.
+func (r *AccountRepository) FindByUsername(ctx context.Context, username string) (*domain.Account, error) {
+	return r.findOne(ctx, `SELECT `+accountColumns+` FROM accounts WHERE username = $1`, username)
+}
+
+func (r *AccountRepository) findOne(ctx context.Context, query string, args ...interface{}) (*domain.Account, error) {
+	account, err := scanAccount(r.pool.QueryRow(ctx, query, args...))
+	if err != nil {
+		if err == pgx.ErrNoRows {
+			return nil, domain.ErrAccountNotFound
+		}
+		return nil, err
+	}
+	return account, nil
+}
+
+// Update implements output.AccountRepository.
+func (r *AccountRepository) Update(ctx context.Context, account *domain.Account) error {
+	tag, err := r.pool.Exec(ctx, `
+		UPDATE accounts SET username = $1, password_hash = $2, is_active = $3, is_admin = $4, updated_at = $5, last_login_at = $6
+		WHERE id = $7`,
+		account.Username, account.PasswordHash, account.IsActive, account.IsAdmin, account.UpdatedAt, account.LastLoginAt, account.ID,
+	)
+	if err != nil {
+		if isUniqueViolation(err) {
+			return domain.ErrDuplicateUsername
+		}
+		return err
+	}
+
+	if tag.RowsAffected() == 0 {
+		return domain.ErrAccountNotFound
+	}
+	return nil
+}
+
+// List implements output.AccountRepository.
+func (r *AccountRepository) List(ctx context.Context) ([]*domain.Account, error) {
+	rows, err := r.pool.Query(ctx, `SELECT `+accountColumns+` FROM accounts ORDER BY username`)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	accounts := []*domain.Account{}
+	for rows.Next() {
+		account, err := scanAccount(rows)
+		if err != nil {
+			return nil, err
+		}
+		accounts = append(accounts, account)
+	}
+	return accounts, rows.Err()
+}
+
+type SessionRepository struct {
+	pool *pgxpool.Pool
+}
+
+func NewSessionRepository(pool *pgxpool.Pool) *SessionRepository {
+	return &SessionRepository{pool: pool}
+}
+
+var _ output.SessionRepository = (*SessionRepository)(nil)
+
+// Create implements output.SessionRepository.
+func (r *SessionRepository) Create(ctx context.Context, session *domain.Session) error {
+	_, err := r.pool.Exec(ctx, `
+		INSERT INTO sessions (id, account_id, token_hash, created_at, expires_at)
+		VALUES ($1, $2, $3, $4, $5)`,
+		session.ID, session.AccountID, session.TokenHash, session.CreatedAt, session.ExpiresAt,
+	)
+	return err
+}
+
+// FindByTokenHash implements output.SessionRepository.
+func (r *SessionRepository) FindByTokenHash(ctx context.Context, tokenHash string) (*domain.Session, error) {
+	var session domain.Session
+	err := r.pool.QueryRow(ctx, `
+		SELECT id, account_id, token_hash, created_at, expires_at
+		FROM sessions WHERE token_hash = $1`, tokenHash).Scan(
+		&session.ID, &session.AccountID, &session.TokenHash, &session.CreatedAt, &session.ExpiresAt,
+	)
+	if err != nil {
+		if err == pgx.ErrNoRows {
+			return nil, domain.ErrSessionNotFound
+		}
+		return nil, err
+	}
+	return &session, nil
+}
+
+// Delete implements output.SessionRepository.
+func (r *SessionRepository) Delete(ctx context.Context, id string) error {
+	_, err := r.pool.Exec(ctx, `DELETE FROM sessions WHERE id = $1`, id)
+	return err
+}
+
+// DeleteByAccountID implements output.SessionRepository.
+func (r *SessionRepository) DeleteByAccountID(ctx context.Context, accountID string) error {
+	_, err := r.pool.Exec(ctx, `DELETE FROM sessions WHERE account_id = $1`, accountID)
+	return err
+}
+
+// DeleteExpired implements output.SessionRepository.
+func (r *SessionRepository) DeleteExpired(ctx context.Context, before time.Time) (int64, error) {
+	tag, err := r.pool.Exec(ctx, `DELETE FROM sessions WHERE expires_at < $1`, before)
+	if err != nil {
+		return 0, err
+	}
+	return tag.RowsAffected(), nil
+}
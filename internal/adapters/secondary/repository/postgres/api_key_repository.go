@@ -0,0 +1,143 @@
+package postgres
+
+import (
+	"context"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+	"github.com/spidey52/api-logs/internal/domain"
+	"github.com/spidey52/api-logs/internal/ports/output"
+)
+
+type APIKeyRepository struct {
+	pool *pgxpool.Pool
+}
+
+func NewAPIKeyRepository(pool *pgxpool.Pool) *APIKeyRepository {
+	return &APIKeyRepository{pool: pool}
+}
+
+var _ output.APIKeyRepository = (*APIKeyRepository)(nil)
+
+const apiKeyColumns = `id, project_id, name, key_hash, prefix, scopes, expires_at, last_used_at, revoked_at, created_at`
+
+func scanAPIKey(row pgx.Row) (*domain.APIKey, error) {
+	var key domain.APIKey
+	var scopes []string
+	err := row.Scan(
+		&key.ID, &key.ProjectID, &key.Name, &key.KeyHash, &key.Prefix, &scopes,
+		&key.ExpiresAt, &key.LastUsedAt, &key.RevokedAt, &key.CreatedAt,
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	key.Scopes = make([]domain.APIKeyScope, len(scopes))
+	for i, scope := range scopes {
+		key.Scopes[i] = domain.APIKeyScope(scope)
+	}
+	return &key, nil
+}
+
+// Create implements output.APIKeyRepository.
+func (r *APIKeyRepository) Create(ctx context.Context, key *domain.APIKey) error {
+	scopes := make([]string, len(key.Scopes))
+	for i, scope := range key.Scopes {
+		scopes[i] = string(scope)
+	}
+
+	_, err := r.pool.Exec(ctx, `
+		INSERT INTO api_keys (`+apiKeyColumns+`)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10)`,
+		key.ID, key.ProjectID, key.Name, key.KeyHash, key.Prefix, scopes,
+		key.ExpiresAt, key.LastUsedAt, key.RevokedAt, key.CreatedAt,
+	)
+	if isUniqueViolation(err) {
+		return domain.ErrDuplicateAPIKey
+	}
+	return err
+}
+
+// FindByID implements output.APIKeyRepository.
+func (r *APIKeyRepository) FindByID(ctx context.Context, id string) (*domain.APIKey, error) {
+	return r.findOne(ctx, `SELECT `+apiKeyColumns+` FROM api_keys WHERE id = $1`, id)
+}
+
+// FindByKey implements output.APIKeyRepository.
+func (r *APIKeyRepository) FindByKey(ctx context.Context, keyHash string) (*domain.APIKey, error) {
+	return r.findOne(ctx, `SELECT `+apiKeyColumns+` FROM api_keys WHERE key_hash = $1`, keyHash)
+}
+
+func (r *APIKeyRepository) findOne(ctx context.Context, query string, args ...interface{}) (*domain.APIKey, error) {
+	key, err := scanAPIKey(r.pool.QueryRow(ctx, query, args...))
+	if err != nil {
+		if err == pgx.ErrNoRows {
+			return nil, domain.ErrAPIKeyNotFound
+		}
+		return nil, err
+	}
+	return key, nil
+}
+
+// ListByProject implements output.APIKeyRepository.
+func (r *APIKeyRepository) ListByProject(ctx context.Context, projectID string) ([]*domain.APIKey, error) {
+	rows, err := r.pool.Query(ctx, `
+		SELECT `+apiKeyColumns+` FROM api_keys
+		WHERE project_id = $1 ORDER BY created_at DESC, id`, projectID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	keys := []*domain.APIKey{}
+	for rows.Next() {
+		key, err := scanAPIKey(rows)
+		if err != nil {
+			return nil, err
+		}
+		keys = append(keys, key)
+	}
+	return keys, rows.Err()
+}
+
+// Revoke implements output.APIKeyRepository.
+func (r *APIKeyRepository) Revoke(ctx context.Context, id string, revokedAt time.Time) error {
+	tag, err := r.pool.Exec(ctx, `UPDATE api_keys SET revoked_at = COALESCE(revoked_at, $1) WHERE id = $2`, revokedAt, id)
+	if err != nil {
+		return err
+	}
+
+	if tag.RowsAffected() == 0 {
+		return domain.ErrAPIKeyNotFound
+	}
+	return nil
+}
+
+// RevokeExpired implements output.APIKeyRepository.
+func (r *APIKeyRepository) RevokeExpired(ctx context.Context, now time.Time) (int, error) {
+	tag, err := r.pool.Exec(ctx, `
+		UPDATE api_keys SET revoked_at = expires_at
+		WHERE revoked_at IS NULL AND expires_at <= $1`, now)
+	if err != nil {
+		return 0, err
+	}
+	return int(tag.RowsAffected()), nil
+}
+
+// TouchLastUsed implements output.APIKeyRepository.
+func (r *APIKeyRepository) TouchLastUsed(ctx context.Context, id string, usedAt time.Time) error {
+	_, err := r.pool.Exec(ctx, `UPDATE api_keys SET last_used_at = $1 WHERE id = $2`, usedAt, id)
+	return err
+}
+
+// DeleteByProject implements output.APIKeyRepository.
+func (r *APIKeyRepository) DeleteByProject(ctx context.Context, projectID string) error {
+	_, err := r.pool.Exec(ctx, `DELETE FROM api_keys WHERE project_id = $1`, projectID)
+	return err
+}
+
+// HashPlaintextKeys implements output.APIKeyRepository.
+func (r *APIKeyRepository) HashPlaintextKeys(ctx context.Context, hash func(key string) string) (int, error) {
+	return hashPlaintextKeys(ctx, r.pool, "api_keys", "key_hash", "prefix", hash)
+}
@@ -3,6 +3,8 @@ package postgres
 import (
 	"context"
 	"encoding/json"
+	"strconv"
+	"time"
 
 	"github.com/jackc/pgx/v5"
 	"github.com/jackc/pgx/v5/pgxpool"
@@ -24,13 +26,29 @@ func (r *APILogBodyRepository) Create(ctx context.Context, body *domain.APILogBo
 	responseBodyJSON, _ := json.Marshal(body.ResponseBody)
 
 	_, err := r.pool.Exec(ctx, `
-		INSERT INTO apilog_bodies (id, log_id, request_body, response_body, created_at)
-		VALUES ($1, $2, $3, $4, $5)`,
-		body.ID, body.LogID, requestBodyJSON, responseBodyJSON, body.CreatedAt,
+		INSERT INTO apilog_bodies (id, log_id, project_id, request_body, response_body, created_at)
+		VALUES ($1, $2, $3, $4, $5, $6)`,
+		body.ID, body.LogID, body.ProjectID, requestBodyJSON, responseBodyJSON, body.CreatedAt,
 	)
 	return err
 }
 
+// CreateMany implements output.APILogBodyRepository using COPY for bulk loads.
+func (r *APILogBodyRepository) CreateMany(ctx context.Context, bodies []*domain.APILogBody) error {
+	if len(bodies) == 0 {
+		return nil
+	}
+
+	rows := make([][]interface{}, len(bodies))
+	for i, b := range bodies {
+		requestBodyJSON, _ := json.Marshal(b.RequestBody)
+		responseBodyJSON, _ := json.Marshal(b.ResponseBody)
+		rows[i] = []interface{}{b.ID, b.LogID, b.ProjectID, requestBodyJSON, responseBodyJSON, b.CreatedAt}
+	}
+	return copyIgnoringConflicts(ctx, r.pool, "apilog_bodies",
+		[]string{"id", "log_id", "project_id", "request_body", "response_body", "created_at"}, rows)
+}
+
 // FindByLogID implements output.APILogBodyRepository.
 func (r *APILogBodyRepository) FindByLogID(ctx context.Context, logID string) (*domain.APILogBody, error) {
 	var body domain.APILogBody
@@ -43,7 +61,7 @@ func (r *APILogBodyRepository) FindByLogID(ctx context.Context, logID string) (*
 	)
 	if err != nil {
 		if err == pgx.ErrNoRows {
-			return nil, nil // Not found, return nil
+			return nil, domain.ErrBodyNotFound
 		}
 		return nil, err
 	}
@@ -53,6 +71,34 @@ func (r *APILogBodyRepository) FindByLogID(ctx context.Context, logID string) (*
 	return &body, nil
 }
 
+// FindByLogIDs implements output.APILogBodyRepository.
+func (r *APILogBodyRepository) FindByLogIDs(ctx context.Context, logIDs []string) ([]*domain.APILogBody, error) {
+	if len(logIDs) == 0 {
+		return nil, nil
+	}
+
+	rows, err := r.pool.Query(ctx, `
+		SELECT id, log_id, request_body, response_body, created_at
+		FROM apilog_bodies WHERE log_id = ANY($1)`, logIDs)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var found []*domain.APILogBody
+	for rows.Next() {
+		var body domain.APILogBody
+		var requestBodyJSON, responseBodyJSON []byte
+		if err := rows.Scan(&body.ID, &body.LogID, &requestBodyJSON, &responseBodyJSON, &body.CreatedAt); err != nil {
+			return nil, err
+		}
+		json.Unmarshal(requestBodyJSON, &body.RequestBody)
+		json.Unmarshal(responseBodyJSON, &body.ResponseBody)
+		found = append(found, &body)
+	}
+	return found, rows.Err()
+}
+
 // Delete implements output.APILogBodyRepository.
 func (r *APILogBodyRepository) Delete(ctx context.Context, logID string) error {
 	_, err := r.pool.Exec(ctx, `DELETE FROM apilog_bodies WHERE log_id = $1`, logID)
@@ -70,4 +116,43 @@ func (r *APILogBodyRepository) DeleteBatch(ctx context.Context, logIDs []string)
 	return err
 }
 
+// FindOlderThan implements output.APILogBodyRepository.
+func (r *APILogBodyRepository) FindOlderThan(ctx context.Context, projectID string, before time.Time, limit int) ([]*domain.APILogBody, error) {
+	where, args := olderThanWhere("created_at", projectID, before)
+	args = append(args, limit)
+
+	rows, err := r.pool.Query(ctx, `
+		SELECT id, log_id, COALESCE(project_id, ''), request_body, response_body, created_at
+		FROM apilog_bodies`+where+` ORDER BY created_at LIMIT $`+strconv.Itoa(len(args)),
+		args...,
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var found []*domain.APILogBody
+	for rows.Next() {
+		var body domain.APILogBody
+		var requestBodyJSON, responseBodyJSON []byte
+		if err := rows.Scan(&body.ID, &body.LogID, &body.ProjectID, &requestBodyJSON, &responseBodyJSON, &body.CreatedAt); err != nil {
+			return nil, err
+		}
+		json.Unmarshal(requestBodyJSON, &body.RequestBody)
+		json.Unmarshal(responseBodyJSON, &body.ResponseBody)
+		found = append(found, &body)
+	}
+	return found, rows.Err()
+}
+
+// DeleteOlderThan implements output.APILogBodyRepository.
+func (r *APILogBodyRepository) DeleteOlderThan(ctx context.Context, projectID string, before time.Time, limit int) (int64, error) {
+	return deleteOlderThan(ctx, r.pool, "apilog_bodies", "created_at", projectID, before, limit)
+}
+
+// VolumeOlderThan implements output.APILogBodyRepository.
+func (r *APILogBodyRepository) VolumeOlderThan(ctx context.Context, projectID string, before time.Time) (domain.DataVolume, error) {
+	return volumeOlderThan(ctx, r.pool, "apilog_bodies", "created_at", projectID, before)
+}
+
 var _ output.APILogBodyRepository = (*APILogBodyRepository)(nil)
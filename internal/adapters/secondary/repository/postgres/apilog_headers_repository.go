@@ -3,6 +3,8 @@ package postgres
 import (
 	"context"
 	"encoding/json"
+	"strconv"
+	"time"
 
 	"github.com/jackc/pgx/v5"
 	"github.com/jackc/pgx/v5/pgxpool"
@@ -24,13 +26,29 @@ func (r *APILogHeadersRepository) Create(ctx context.Context, headers *domain.AP
 	responseHeadersJSON, _ := json.Marshal(headers.ResponseHeaders)
 
 	_, err := r.pool.Exec(ctx, `
-		INSERT INTO apilog_headers (id, log_id, request_headers, response_headers, created_at)
-		VALUES ($1, $2, $3, $4, $5)`,
-		headers.ID, headers.LogID, requestHeadersJSON, responseHeadersJSON, headers.CreatedAt,
+		INSERT INTO apilog_headers (id, log_id, project_id, request_headers, response_headers, created_at)
+		VALUES ($1, $2, $3, $4, $5, $6)`,
+		headers.ID, headers.LogID, headers.ProjectID, requestHeadersJSON, responseHeadersJSON, headers.CreatedAt,
 	)
 	return err
 }
 
+// CreateMany implements output.APILogHeadersRepository using COPY for bulk loads.
+func (r *APILogHeadersRepository) CreateMany(ctx context.Context, headers []*domain.APILogHeaders) error {
+	if len(headers) == 0 {
+		return nil
+	}
+
+	rows := make([][]interface{}, len(headers))
+	for i, h := range headers {
+		requestHeadersJSON, _ := json.Marshal(h.RequestHeaders)
+		responseHeadersJSON, _ := json.Marshal(h.ResponseHeaders)
+		rows[i] = []interface{}{h.ID, h.LogID, h.ProjectID, requestHeadersJSON, responseHeadersJSON, h.CreatedAt}
+	}
+	return copyIgnoringConflicts(ctx, r.pool, "apilog_headers",
+		[]string{"id", "log_id", "project_id", "request_headers", "response_headers", "created_at"}, rows)
+}
+
 // FindByLogID implements output.APILogHeadersRepository.
 func (r *APILogHeadersRepository) FindByLogID(ctx context.Context, logID string) (*domain.APILogHeaders, error) {
 	var headers domain.APILogHeaders
@@ -43,7 +61,7 @@ func (r *APILogHeadersRepository) FindByLogID(ctx context.Context, logID string)
 	)
 	if err != nil {
 		if err == pgx.ErrNoRows {
-			return nil, nil // Not found, return nil
+			return nil, domain.ErrHeadersNotFound
 		}
 		return nil, err
 	}
@@ -53,6 +71,34 @@ func (r *APILogHeadersRepository) FindByLogID(ctx context.Context, logID string)
 	return &headers, nil
 }
 
+// FindByLogIDs implements output.APILogHeadersRepository.
+func (r *APILogHeadersRepository) FindByLogIDs(ctx context.Context, logIDs []string) ([]*domain.APILogHeaders, error) {
+	if len(logIDs) == 0 {
+		return nil, nil
+	}
+
+	rows, err := r.pool.Query(ctx, `
+		SELECT id, log_id, request_headers, response_headers, created_at
+		FROM apilog_headers WHERE log_id = ANY($1)`, logIDs)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var found []*domain.APILogHeaders
+	for rows.Next() {
+		var headers domain.APILogHeaders
+		var requestHeadersJSON, responseHeadersJSON []byte
+		if err := rows.Scan(&headers.ID, &headers.LogID, &requestHeadersJSON, &responseHeadersJSON, &headers.CreatedAt); err != nil {
+			return nil, err
+		}
+		json.Unmarshal(requestHeadersJSON, &headers.RequestHeaders)
+		json.Unmarshal(responseHeadersJSON, &headers.ResponseHeaders)
+		found = append(found, &headers)
+	}
+	return found, rows.Err()
+}
+
 // Delete implements output.APILogHeadersRepository.
 func (r *APILogHeadersRepository) Delete(ctx context.Context, logID string) error {
 	_, err := r.pool.Exec(ctx, `DELETE FROM apilog_headers WHERE log_id = $1`, logID)
@@ -70,4 +116,43 @@ func (r *APILogHeadersRepository) DeleteBatch(ctx context.Context, logIDs []stri
 	return err
 }
 
+// FindOlderThan implements output.APILogHeadersRepository.
+func (r *APILogHeadersRepository) FindOlderThan(ctx context.Context, projectID string, before time.Time, limit int) ([]*domain.APILogHeaders, error) {
+	where, args := olderThanWhere("created_at", projectID, before)
+	args = append(args, limit)
+
+	rows, err := r.pool.Query(ctx, `
+		SELECT id, log_id, COALESCE(project_id, ''), request_headers, response_headers, created_at
+		FROM apilog_headers`+where+` ORDER BY created_at LIMIT $`+strconv.Itoa(len(args)),
+		args...,
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var found []*domain.APILogHeaders
+	for rows.Next() {
+		var headers domain.APILogHeaders
+		var requestHeadersJSON, responseHeadersJSON []byte
+		if err := rows.Scan(&headers.ID, &headers.LogID, &headers.ProjectID, &requestHeadersJSON, &responseHeadersJSON, &headers.CreatedAt); err != nil {
+			return nil, err
+		}
+		json.Unmarshal(requestHeadersJSON, &headers.RequestHeaders)
+		json.Unmarshal(responseHeadersJSON, &headers.ResponseHeaders)
+		found = append(found, &headers)
+	}
+	return found, rows.Err()
+}
+
+// DeleteOlderThan implements output.APILogHeadersRepository.
+func (r *APILogHeadersRepository) DeleteOlderThan(ctx context.Context, projectID string, before time.Time, limit int) (int64, error) {
+	return deleteOlderThan(ctx, r.pool, "apilog_headers", "created_at", projectID, before, limit)
+}
+
+// VolumeOlderThan implements output.APILogHeadersRepository.
+func (r *APILogHeadersRepository) VolumeOlderThan(ctx context.Context, projectID string, before time.Time) (domain.DataVolume, error) {
+	return volumeOlderThan(ctx, r.pool, "apilog_headers", "created_at", projectID, before)
+}
+
 var _ output.APILogHeadersRepository = (*APILogHeadersRepository)(nil)
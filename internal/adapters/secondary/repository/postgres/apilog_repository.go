@@ -17,11 +17,101 @@ type APILogRepository struct {
 	pool *pgxpool.Pool
 }
 
-// CountByProject implements output.APILogRepository.
-func (r *APILogRepository) CountByProject(ctx context.Context, projectID string, environment domain.Environment) (int64, error) {
-	var count int64
-	err := r.pool.QueryRow(ctx, `SELECT COUNT(*) FROM api_logs WHERE project_id = $1 AND environment = $2`, projectID, string(environment)).Scan(&count)
-	return count, err
+func NewAPILogRepository(pool *pgxpool.Pool) *APILogRepository {
+	return &APILogRepository{pool: pool}
+}
+
+var _ output.APILogRepository = (*APILogRepository)(nil)
+
+const logColumns = `id, project_id, environment, method, path, params, query_params, status_code,
+	response_time, content_length, ip_address, user_agent, error_message, user_id, timestamp`
+
+// logCopyColumns lists logColumns for COPY
+var logCopyColumns = []string{"id", "project_id", "environment", "method", "path", "params", "query_params", "status_code",
+	"response_time", "content_length", "ip_address", "user_agent", "error_message", "user_id", "timestamp"}
+
+// buildLogWhere translates the filter into a WHERE clause and its arguments
+func buildLogWhere(filter domain.LogFilter) (string, []interface{}) {
+	conditions := []string{}
+	args := []interface{}{}
+
+	add := func(condition string, value interface{}) {
+		args = append(args, value)
+		conditions = append(conditions, strings.ReplaceAll(condition, "?", "$"+strconv.Itoa(len(args))))
+	}
+
+	if filter.ProjectID != "" {
+		add("project_id = ?", filter.ProjectID)
+	}
+	if filter.Environment != "" {
+		add("environment = ?", string(filter.Environment))
+	}
+	if filter.Method != "" {
+		add("method = ?", string(filter.Method))
+	}
+
+	// Handle status code filtering (exact, min/max range)
+	if filter.StatusCode != nil {
+		add("status_code = ?", *filter.StatusCode)
+	} else {
+		if filter.StatusCodeMin != nil {
+			add("status_code >= ?", *filter.StatusCodeMin)
+		}
+		if filter.StatusCodeMax != nil {
+			add("status_code <= ?", *filter.StatusCodeMax)
+		}
+	}
+
+	if filter.Path != "" {
+		add("path ILIKE ?", "%"+escapeLike(filter.Path)+"%")
+	}
+	if filter.Search != "" {
+		add("(path ILIKE ? OR user_agent ILIKE ? OR ip_address ILIKE ?)", "%"+escapeLike(filter.Search)+"%")
+	}
+	if filter.FromDate != nil {
+		add("timestamp >= ?", *filter.FromDate)
+	}
+	if filter.ToDate != nil {
+		add("timestamp <= ?", *filter.ToDate)
+	}
+	if filter.UserID != "" {
+		add("user_id = ?", filter.UserID)
+	}
+
+	if len(conditions) == 0 {
+		return "", args
+	}
+	return " WHERE " + strings.Join(conditions, " AND "), args
+}
+
+// statsWhere scopes dashboard stats to today's logs for a project/environment
+func statsWhere(projectID string, environment domain.Environment) (string, []interface{}) {
+	from := startOfDay(time.Now())
+	return buildLogWhere(domain.LogFilter{
+		ProjectID:   projectID,
+		Environment: environment,
+		FromDate:    &from,
+	})
+}
+
+func scanAPILog(row pgx.Row) (*domain.APILog, error) {
+	var log domain.APILog
+	var paramsJSON, queryParamsJSON []byte
+	var envStr, methodStr string
+
+	err := row.Scan(
+		&log.ID, &log.ProjectID, &envStr, &methodStr, &log.Path, &paramsJSON, &queryParamsJSON, &log.StatusCode,
+		&log.ResponseTime, &log.ContentLength, &log.IPAddress, &log.UserAgent, &log.ErrorMessage, &log.UserID, &log.Timestamp,
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	log.Environment = domain.Environment(envStr)
+	log.Method = domain.HTTPMethod(methodStr)
+	json.Unmarshal(paramsJSON, &log.Params)
+	json.Unmarshal(queryParamsJSON, &log.QueryParams)
+	return &log, nil
 }
 
 // Create implements output.APILogRepository.
@@ -30,84 +120,117 @@ func (r *APILogRepository) Create(ctx context.Context, log *domain.APILog) error
 	queryParamsJSON, _ := json.Marshal(log.QueryParams)
 
 	_, err := r.pool.Exec(ctx, `
-		INSERT INTO api_logs (
-			id, project_id, environment, method, path, params, query_params, status_code,
-			response_time, content_length, ip_address, user_agent, error_message, user_id, timestamp
-		) VALUES (
-			$1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13, $14, $15
-		)
-	`,
+		INSERT INTO api_logs (`+logColumns+`)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13, $14, $15)`,
 		log.ID, log.ProjectID, string(log.Environment), string(log.Method), log.Path, paramsJSON, queryParamsJSON, log.StatusCode,
 		log.ResponseTime, log.ContentLength, log.IPAddress, log.UserAgent, log.ErrorMessage, log.UserID, log.Timestamp,
 	)
 	return err
 }
 
-// Delete implements output.APILogRepository.
-func (r *APILogRepository) Delete(ctx context.Context, id string) error {
-	_, err := r.pool.Exec(ctx, `DELETE FROM api_logs WHERE id = $1`, id)
-	return err
+// CreateMany implements output.APILogRepository using COPY for bulk loads.
+func (r *APILogRepository) CreateMany(ctx context.Context, logs []*domain.APILog) error {
+	if len(logs) == 0 {
+		return nil
+	}
+
+	rows := make([][]interface{}, len(logs))
+	for i, log := range logs {
+		paramsJSON, _ := json.Marshal(log.Params)
+		queryParamsJSON, _ := json.Marshal(log.QueryParams)
+		rows[i] = []interface{}{
+			log.ID, log.ProjectID, string(log.Environment), string(log.Method), log.Path, paramsJSON, queryParamsJSON, log.StatusCode,
+			log.ResponseTime, log.ContentLength, log.IPAddress, log.UserAgent, log.ErrorMessage, log.UserID, log.Timestamp,
+		}
+	}
+	return copyIgnoringConflicts(ctx, r.pool, "api_logs", logCopyColumns, rows)
 }
 
 // FindByID implements output.APILogRepository.
 func (r *APILogRepository) FindByID(ctx context.Context, id string) (*domain.APILog, error) {
-	query := `
-		SELECT id, project_id, environment, method, path, params, query_params, status_code,
-			   response_time, content_length, ip_address, user_agent, error_message, user_id, timestamp
-		FROM api_logs WHERE id = $1`
-	var log domain.APILog
-	var paramsJSON, queryParamsJSON []byte
-	var envStr, methodStr string
-	err := r.pool.QueryRow(ctx, query, id).Scan(
-		&log.ID, &log.ProjectID, &envStr, &methodStr, &log.Path, &paramsJSON, &queryParamsJSON, &log.StatusCode,
-		&log.ResponseTime, &log.ContentLength, &log.IPAddress, &log.UserAgent, &log.ErrorMessage, &log.UserID, &log.Timestamp,
-	)
+	log, err := scanAPILog(r.pool.QueryRow(ctx, `SELECT `+logColumns+` FROM api_logs WHERE id = $1`, id))
 	if err != nil {
 		if err == pgx.ErrNoRows {
 			return nil, domain.ErrLogNotFound
 		}
 		return nil, err
 	}
-	log.Environment = domain.Environment(envStr)
-	log.Method = domain.HTTPMethod(methodStr)
-	json.Unmarshal(paramsJSON, &log.Params)
-	json.Unmarshal(queryParamsJSON, &log.QueryParams)
-	return &log, nil
+	return log, nil
 }
 
-// GetAverageResponseTime implements output.APILogRepository.
-func (r *APILogRepository) GetAverageResponseTime(ctx context.Context, projectID string, environment domain.Environment) (float64, error) {
-	var avg float64
-	err := r.pool.QueryRow(ctx, `SELECT AVG(response_time) FROM api_logs WHERE project_id = $1 AND environment = $2`, projectID, string(environment)).Scan(&avg)
-	if err != nil {
-		return 0, err
+// FindByFilter implements output.APILogRepository.
+func (r *APILogRepository) FindByFilter(ctx context.Context, filter domain.LogFilter) ([]*domain.APILog, error) {
+	where, args := buildLogWhere(filter)
+
+	query := `SELECT ` + logColumns + ` FROM api_logs` + where + ` ORDER BY timestamp DESC`
+	if filter.Limit > 0 {
+		query += ` LIMIT $` + strconv.Itoa(len(args)+1) + ` OFFSET $` + strconv.Itoa(len(args)+2)
+		args = append(args, filter.Limit, filter.Offset)
 	}
-	return avg, nil
-}
 
-// GetMethodDistribution implements output.APILogRepository.
-func (r *APILogRepository) GetMethodDistribution(ctx context.Context, projectID string, environment domain.Environment) (map[string]int64, error) {
-	rows, err := r.pool.Query(ctx, `SELECT method, COUNT(*) FROM api_logs WHERE project_id = $1 AND environment = $2 GROUP BY method`, projectID, string(environment))
+	rows, err := r.pool.Query(ctx, query, args...)
 	if err != nil {
 		return nil, err
 	}
 	defer rows.Close()
 
-	dist := make(map[string]int64)
+	logs := []*domain.APILog{}
 	for rows.Next() {
-		var method string
-		var count int64
-		if err := rows.Scan(&method, &count); err != nil {
+		log, err := scanAPILog(rows)
+		if err != nil {
 			return nil, err
 		}
-		dist[method] = count
+		logs = append(logs, log)
 	}
-	return dist, nil
+	return logs, rows.Err()
+}
+
+// Delete implements output.APILogRepository.
+func (r *APILogRepository) Delete(ctx context.Context, id string) error {
+	tag, err := r.pool.Exec(ctx, `DELETE FROM api_logs WHERE id = $1`, id)
+	if err != nil {
+		return err
+	}
+
+	if tag.RowsAffected() == 0 {
+		return domain.ErrLogNotFound
+	}
+	return nil
+}
+
+// DeleteBatch implements output.APILogRepository.
+func (r *APILogRepository) DeleteBatch(ctx context.Context, ids []string) error {
+	if len(ids) == 0 {
+		return nil
+	}
+
+	_, err := r.pool.Exec(ctx, `DELETE FROM api_logs WHERE id = ANY($1)`, ids)
+	return err
+}
+
+// CountByProject implements output.APILogRepository.
+func (r *APILogRepository) CountByProject(ctx context.Context, projectID string, environment domain.Environment) (int64, error) {
+	where, args := statsWhere(projectID, environment)
+
+	var count int64
+	err := r.pool.QueryRow(ctx, `SELECT COUNT(*) FROM api_logs`+where, args...).Scan(&count)
+	return count, err
+}
+
+// CountByFilter implements output.APILogRepository.
+func (r *APILogRepository) CountByFilter(ctx context.Context, filter domain.LogFilter) (int64, error) {
+	where, args := buildLogWhere(filter)
+
+	var count int64
+	err := r.pool.QueryRow(ctx, `SELECT COUNT(*) FROM api_logs`+where, args...).Scan(&count)
+	return count, err
 }
 
 // GetStatusCodeDistribution implements output.APILogRepository.
 func (r *APILogRepository) GetStatusCodeDistribution(ctx context.Context, projectID string, environment domain.Environment) (map[int]int64, error) {
-	rows, err := r.pool.Query(ctx, `SELECT status_code, COUNT(*) FROM api_logs WHERE project_id = $1 AND environment = $2 GROUP BY status_code`, projectID, string(environment))
+	where, args := statsWhere(projectID, environment)
+
+	rows, err := r.pool.Query(ctx, `SELECT status_code, COUNT(*) FROM api_logs`+where+` GROUP BY status_code`, args...)
 	if err != nil {
 		return nil, err
 	}
@@ -122,16 +245,29 @@ func (r *APILogRepository) GetStatusCodeDistribution(ctx context.Context, projec
 		}
 		dist[status] = count
 	}
-	return dist, nil
+	return dist, rows.Err()
+}
+
+// GetAverageResponseTime implements output.APILogRepository.
+func (r *APILogRepository) GetAverageResponseTime(ctx context.Context, projectID string, environment domain.Environment) (float64, error) {
+	where, args := statsWhere(projectID, environment)
+
+	var avg float64
+	err := r.pool.QueryRow(ctx, `SELECT COALESCE(AVG(response_time), 0) FROM api_logs`+where, args...).Scan(&avg)
+	if err != nil {
+		return 0, err
+	}
+	return avg, nil
 }
 
 // GetTimeSeriesStats implements output.APILogRepository.
 func (r *APILogRepository) GetTimeSeriesStats(ctx context.Context, projectID string, environment domain.Environment) ([]map[string]interface{}, error) {
+	where, args := statsWhere(projectID, environment)
+
 	rows, err := r.pool.Query(ctx, `
-		SELECT time_bucket('1 hour', timestamp) AS bucket, COUNT(*) AS count
-		FROM api_logs
-		WHERE project_id = $1 AND environment = $2 AND timestamp >= NOW() - INTERVAL '24 hours'
-		GROUP BY bucket ORDER BY bucket DESC`, projectID, string(environment))
+		SELECT date_trunc('hour', timestamp) AS bucket, COUNT(*) AS count
+		FROM api_logs`+where+`
+		GROUP BY bucket ORDER BY bucket ASC LIMIT 24`, args...)
 	if err != nil {
 		return nil, err
 	}
@@ -149,16 +285,18 @@ func (r *APILogRepository) GetTimeSeriesStats(ctx context.Context, projectID str
 			"count":     count,
 		})
 	}
-	return stats, nil
+	return stats, rows.Err()
 }
 
 // GetTopEndpoints implements output.APILogRepository.
 func (r *APILogRepository) GetTopEndpoints(ctx context.Context, projectID string, environment domain.Environment, limit int) ([]map[string]interface{}, error) {
+	where, args := statsWhere(projectID, environment)
+	args = append(args, limit)
+
 	rows, err := r.pool.Query(ctx, `
-		SELECT path, COUNT(*) AS count
-		FROM api_logs
-		WHERE project_id = $1 AND environment = $2
-		GROUP BY path ORDER BY count DESC LIMIT $3`, projectID, string(environment), limit)
+		SELECT path, COUNT(*) AS count, MIN(method) AS method, COALESCE(AVG(response_time), 0) AS avg_response_time
+		FROM api_logs`+where+`
+		GROUP BY path ORDER BY count DESC LIMIT $`+strconv.Itoa(len(args)), args...)
 	if err != nil {
 		return nil, err
 	}
@@ -166,28 +304,56 @@ func (r *APILogRepository) GetTopEndpoints(ctx context.Context, projectID string
 
 	var endpoints []map[string]interface{}
 	for rows.Next() {
-		var path string
+		var path, method string
 		var count int64
-		if err := rows.Scan(&path, &count); err != nil {
+		var avgResponseTime float64
+		if err := rows.Scan(&path, &count, &method, &avgResponseTime); err != nil {
 			return nil, err
 		}
+		// Keys mirror the MongoDB aggregation output consumed by the dashboard
 		endpoints = append(endpoints, map[string]interface{}{
-			"path":  path,
-			"count": count,
+			"_id":               path,
+			"count":             count,
+			"method":            method,
+			"avg_response_time": avgResponseTime,
 		})
 	}
-	return endpoints, nil
+	return endpoints, rows.Err()
+}
+
+// GetMethodDistribution implements output.APILogRepository.
+func (r *APILogRepository) GetMethodDistribution(ctx context.Context, projectID string, environment domain.Environment) (map[string]int64, error) {
+	where, args := statsWhere(projectID, environment)
+
+	rows, err := r.pool.Query(ctx, `SELECT method, COUNT(*) FROM api_logs`+where+` GROUP BY method`, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	dist := make(map[string]int64)
+	for rows.Next() {
+		var method string
+		var count int64
+		if err := rows.Scan(&method, &count); err != nil {
+			return nil, err
+		}
+		dist[method] = count
+	}
+	return dist, rows.Err()
 }
 
 // GetUniquePaths implements output.APILogRepository.
 func (r *APILogRepository) GetUniquePaths(ctx context.Context, projectID string, environment domain.Environment) ([]string, error) {
-	rows, err := r.pool.Query(ctx, `SELECT DISTINCT path FROM api_logs WHERE project_id = $1 AND environment = $2 ORDER BY path`, projectID, string(environment))
+	where, args := statsWhere(projectID, environment)
+
+	rows, err := r.pool.Query(ctx, `SELECT DISTINCT path FROM api_logs`+where+` ORDER BY path`, args...)
 	if err != nil {
 		return nil, err
 	}
 	defer rows.Close()
 
-	var paths []string
+	paths := []string{}
 	for rows.Next() {
 		var path string
 		if err := rows.Scan(&path); err != nil {
@@ -195,93 +361,18 @@ func (r *APILogRepository) GetUniquePaths(ctx context.Context, projectID string,
 		}
 		paths = append(paths, path)
 	}
-	return paths, nil
-}
-
-var _ output.APILogRepository = (*APILogRepository)(nil)
-
-func NewAPILogRepository(pool *pgxpool.Pool) *APILogRepository {
-	return &APILogRepository{pool: pool}
+	return paths, rows.Err()
 }
 
-func (r *APILogRepository) CreateLog(ctx context.Context, log *domain.APILog) error {
-	paramsJSON, _ := json.Marshal(log.Params)
-	queryParamsJSON, _ := json.Marshal(log.QueryParams)
+// FindOlderThan implements output.APILogRepository.
+func (r *APILogRepository) FindOlderThan(ctx context.Context, projectID string, before time.Time, limit int) ([]*domain.APILog, error) {
+	where, args := olderThanWhere("timestamp", projectID, before)
+	args = append(args, limit)
 
-	_, err := r.pool.Exec(ctx, `
-        INSERT INTO api_logs (
-            id, project_id, environment, method, path, params, query_params, status_code,
-            response_time, content_length, ip_address, user_agent, error_message, user_id, timestamp
-        ) VALUES (
-            $1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13, $14, $15
-        )
-    `,
-		log.ID, log.ProjectID, string(log.Environment), string(log.Method), log.Path, paramsJSON, queryParamsJSON, log.StatusCode,
-		log.ResponseTime, log.ContentLength, log.IPAddress, log.UserAgent, log.ErrorMessage, log.UserID, log.Timestamp,
+	rows, err := r.pool.Query(ctx, `
+		SELECT `+logColumns+` FROM api_logs`+where+` ORDER BY timestamp LIMIT $`+strconv.Itoa(len(args)),
+		args...,
 	)
-	return err
-}
-
-func (r *APILogRepository) FindByFilter(ctx context.Context, filter domain.LogFilter) ([]*domain.APILog, error) {
-	baseQuery := `
-		SELECT id, project_id, environment, method, path, params, query_params, status_code,
-			   response_time, content_length, ip_address, user_agent, error_message, user_id, timestamp
-		FROM api_logs
-		WHERE project_id = $1 AND environment = $2`
-
-	args := []interface{}{filter.ProjectID, string(filter.Environment)}
-	conditions := []string{"project_id = $1", "environment = $2"}
-	argIndex := 2
-
-	if filter.Method != "" {
-		argIndex++
-		conditions = append(conditions, "method = $"+strconv.Itoa(argIndex))
-		args = append(args, string(filter.Method))
-	}
-	if filter.StatusCode != nil {
-		argIndex++
-		conditions = append(conditions, "status_code = $"+strconv.Itoa(argIndex))
-		args = append(args, *filter.StatusCode)
-	}
-	if filter.StatusCodeMin != nil {
-		argIndex++
-		conditions = append(conditions, "status_code >= $"+strconv.Itoa(argIndex))
-		args = append(args, *filter.StatusCodeMin)
-	}
-	if filter.StatusCodeMax != nil {
-		argIndex++
-		conditions = append(conditions, "status_code <= $"+strconv.Itoa(argIndex))
-		args = append(args, *filter.StatusCodeMax)
-	}
-	if filter.Path != "" {
-		argIndex++
-		conditions = append(conditions, "path LIKE $"+strconv.Itoa(argIndex))
-		args = append(args, "%"+filter.Path+"%")
-	}
-	if filter.Search != "" {
-		argIndex++
-		conditions = append(conditions, "(path ILIKE $"+strconv.Itoa(argIndex)+" OR error_message ILIKE $"+strconv.Itoa(argIndex)+")")
-		args = append(args, "%"+filter.Search+"%")
-	}
-	if filter.FromDate != nil {
-		argIndex++
-		conditions = append(conditions, "timestamp >= $"+strconv.Itoa(argIndex))
-		args = append(args, *filter.FromDate)
-	}
-	if filter.ToDate != nil {
-		argIndex++
-		conditions = append(conditions, "timestamp <= $"+strconv.Itoa(argIndex))
-		args = append(args, *filter.ToDate)
-	}
-
-	query := baseQuery
-	if len(conditions) > 2 {
-		query += " AND " + strings.Join(conditions[2:], " AND ")
-	}
-	query += " ORDER BY timestamp DESC LIMIT $" + strconv.Itoa(argIndex+1) + " OFFSET $" + strconv.Itoa(argIndex+2)
-	args = append(args, filter.Limit, filter.Offset)
-
-	rows, err := r.pool.Query(ctx, query, args...)
 	if err != nil {
 		return nil, err
 	}
@@ -289,79 +380,21 @@ func (r *APILogRepository) FindByFilter(ctx context.Context, filter domain.LogFi
 
 	var logs []*domain.APILog
 	for rows.Next() {
-		var log domain.APILog
-		var paramsJSON, queryParamsJSON []byte
-		var envStr string
-		var methodStr string
-		err := rows.Scan(
-			&log.ID, &log.ProjectID, &envStr, &methodStr, &log.Path, &paramsJSON, &queryParamsJSON, &log.StatusCode,
-			&log.ResponseTime, &log.ContentLength, &log.IPAddress, &log.UserAgent, &log.ErrorMessage, &log.UserID, &log.Timestamp,
-		)
+		log, err := scanAPILog(rows)
 		if err != nil {
 			return nil, err
 		}
-		log.Environment = domain.Environment(envStr)
-		log.Method = domain.HTTPMethod(methodStr)
-		json.Unmarshal(paramsJSON, &log.Params)
-		json.Unmarshal(queryParamsJSON, &log.QueryParams)
-		logs = append(logs, &log)
+		logs = append(logs, log)
 	}
-	return logs, nil
+	return logs, rows.Err()
 }
 
-func (r *APILogRepository) CountByFilter(ctx context.Context, filter domain.LogFilter) (int64, error) {
-	baseQuery := `SELECT COUNT(*) FROM api_logs WHERE project_id = $1 AND environment = $2`
-	args := []interface{}{filter.ProjectID, string(filter.Environment)}
-	conditions := []string{"project_id = $1", "environment = $2"}
-	argIndex := 2
-
-	if filter.Method != "" {
-		argIndex++
-		conditions = append(conditions, "method = $"+strconv.Itoa(argIndex))
-		args = append(args, string(filter.Method))
-	}
-	if filter.StatusCode != nil {
-		argIndex++
-		conditions = append(conditions, "status_code = $"+strconv.Itoa(argIndex))
-		args = append(args, *filter.StatusCode)
-	}
-	if filter.StatusCodeMin != nil {
-		argIndex++
-		conditions = append(conditions, "status_code >= $"+strconv.Itoa(argIndex))
-		args = append(args, *filter.StatusCodeMin)
-	}
-	if filter.StatusCodeMax != nil {
-		argIndex++
-		conditions = append(conditions, "status_code <= $"+strconv.Itoa(argIndex))
-		args = append(args, *filter.StatusCodeMax)
-	}
-	if filter.Path != "" {
-		argIndex++
-		conditions = append(conditions, "path LIKE $"+strconv.Itoa(argIndex))
-		args = append(args, "%"+filter.Path+"%")
-	}
-	if filter.Search != "" {
-		argIndex++
-		conditions = append(conditions, "(path ILIKE $"+strconv.Itoa(argIndex)+" OR error_message ILIKE $"+strconv.Itoa(argIndex)+")")
-		args = append(args, "%"+filter.Search+"%")
-	}
-	if filter.FromDate != nil {
-		argIndex++
-		conditions = append(conditions, "timestamp >= $"+strconv.Itoa(argIndex))
-		args = append(args, *filter.FromDate)
-	}
-	if filter.ToDate != nil {
-		argIndex++
-		conditions = append(conditions, "timestamp <= $"+strconv.Itoa(argIndex))
-		args = append(args, *filter.ToDate)
-	}
-
-	query := baseQuery
-	if len(conditions) > 2 {
-		query += " AND " + strings.Join(conditions[2:], " AND ")
-	}
+// DeleteOlderThan implements output.APILogRepository.
+func (r *APILogRepository) DeleteOlderThan(ctx context.Context, projectID string, before time.Time, limit int) (int64, error) {
+	return deleteOlderThan(ctx, r.pool, "api_logs", "timestamp", projectID, before, limit)
+}
 
-	var count int64
-	err := r.pool.QueryRow(ctx, query, args...).Scan(&count)
-	return count, err
+// VolumeOlderThan implements output.APILogRepository.
+func (r *APILogRepository) VolumeOlderThan(ctx context.Context, projectID string, before time.Time) (domain.DataVolume, error) {
+	return volumeOlderThan(ctx, r.pool, "api_logs", "timestamp", projectID, before)
 }
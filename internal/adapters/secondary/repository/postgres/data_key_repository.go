@@ -0,0 +1,104 @@
+package postgres
+
+import (
+	"context"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+	"github.com/spidey52/api-logs/internal/domain"
+	"github.com/spidey52/api-logs/internal/ports/output"
+)
+
+type DataKeyRepository struct {
+	pool *pgxpool.Pool
+}
+
+func NewDataKeyRepository(pool *pgxpool.Pool) *DataKeyRepository {
+	return &DataKeyRepository{pool: pool}
+}
+
+var _ output.DataKeyRepository = (*DataKeyRepository)(nil)
+
+const dataKeyColumns = `project_id, master_key_id, wrapped_key, created_at, updated_at`
+
+func scanDataKey(row pgx.Row) (*domain.DataKey, error) {
+	var key domain.DataKey
+	if err := row.Scan(&key.ProjectID, &key.MasterKeyID, &key.WrappedKey, &key.CreatedAt, &key.UpdatedAt); err != nil {
+		return nil, err
+	}
+	return &key, nil
+}
+
+// Find implements output.DataKeyRepository.
+func (r *DataKeyRepository) Find(ctx context.Context, projectID string) (*domain.DataKey, error) {
+	key, err := scanDataKey(r.pool.QueryRow(ctx, `SELECT `+dataKeyColumns+` FROM data_keys WHERE project_id = $1`, projectID))
+	if err != nil {
+		if err == pgx.ErrNoRows {
+			return nil, domain.ErrDataKeyNotFound
+		}
+		return nil, err
+	}
+	return key, nil
+}
+
+// Create implements output.DataKeyRepository.
+func (r *DataKeyRepository) Create(ctx context.Context, key *domain.DataKey) error {
+	_, err := r.pool.Exec(ctx, `
+		INSERT INTO data_keys (`+dataKeyColumns+`)
+		VALUES ($1, $2, $3, $4, $5)`,
+		key.ProjectID, key.MasterKeyID, key.WrappedKey, key.CreatedAt, key.UpdatedAt,
+	)
+	if isUniqueViolation(err) {
+		return domain.ErrDuplicateDataKey
+	}
+	return err
+}
+
+// Rewrap implements output.DataKeyRepository.
+func (r *DataKeyRepository) Rewrap(ctx context.Context, key *domain.DataKey) error {
+	tag, err := r.pool.Exec(ctx, `
+		UPDATE data_keys SET master_key_id = $1, wrapped_key = $2, updated_at = $3
+		WHERE project_id = $4`,
+		key.MasterKeyID, key.WrappedKey, key.UpdatedAt, key.ProjectID,
+	)
+	if err != nil {
+		return err
+	}
+
+	if tag.RowsAffected() == 0 {
+		return domain.ErrDataKeyNotFound
+	}
+	return nil
+}
+
+// Delete implements output.DataKeyRepository.
+func (r *DataKeyRepository) Delete(ctx context.Context, projectID string) error {
+	tag, err := r.pool.Exec(ctx, `DELETE FROM data_keys WHERE project_id = $1`, projectID)
+	if err != nil {
+		return err
+	}
+
+	if tag.RowsAffected() == 0 {
+		return domain.ErrDataKeyNotFound
+	}
+	return nil
+}
+
+// List implements output.DataKeyRepository.
+func (r *DataKeyRepository) List(ctx context.Context) ([]*domain.DataKey, error) {
+	rows, err := r.pool.Query(ctx, `SELECT `+dataKeyColumns+` FROM data_keys ORDER BY project_id`)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	keys := []*domain.DataKey{}
+	for rows.Next() {
+		key, err := scanDataKey(rows)
+		if err != nil {
+			return nil, err
+		}
+		keys = append(keys, key)
+	}
+	return keys, rows.Err()
+}
@@ -0,0 +1,132 @@
+package postgres
+
+import (
+	"context"
+	"errors"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgconn"
+	"github.com/jackc/pgx/v5/pgxpool"
+	"github.com/spidey52/api-logs/internal/domain"
+)
+
+// uniqueViolationCode is the SQLSTATE for unique_violation
+const uniqueViolationCode = "23505"
+
+// isUniqueViolation reports whether err is a unique constraint violation
+func isUniqueViolation(err error) bool {
+	var pgErr *pgconn.PgError
+	return errors.As(err, &pgErr) && pgErr.Code == uniqueViolationCode
+}
+
+// copyIgnoringConflicts bulk loads rows with COPY. COPY cannot skip rows that
+// conflict with existing ones, so when one does the rows are inserted again
+// with ON CONFLICT DO NOTHING instead.
+func copyIgnoringConflicts(ctx context.Context, pool *pgxpool.Pool, table string, columns []string, rows [][]interface{}) error {
+	_, err := pool.CopyFrom(ctx, pgx.Identifier{table}, columns, pgx.CopyFromRows(rows))
+	if !isUniqueViolation(err) {
+		return err
+	}
+
+	placeholders := make([]string, len(columns))
+	for i := range placeholders {
+		placeholders[i] = "$" + strconv.Itoa(i+1)
+	}
+	query := `INSERT INTO ` + table + ` (` + strings.Join(columns, ", ") + `)
+		VALUES (` + strings.Join(placeholders, ", ") + `) ON CONFLICT DO NOTHING`
+
+	batch := &pgx.Batch{}
+	for _, row := range rows {
+		batch.Queue(query, row...)
+	}
+	return pool.SendBatch(ctx, batch).Close()
+}
+
+// likeEscaper escapes LIKE wildcards so filters match literal substrings
+var likeEscaper = strings.NewReplacer(`\`, `\\`, `%`, `\%`, `_`, `\_`)
+
+func escapeLike(s string) string {
+	return likeEscaper.Replace(s)
+}
+
+// startOfDay returns local midnight for t, matching the MongoDB stats window
+func startOfDay(t time.Time) time.Time {
+	year, month, day := t.Date()
+	return time.Date(year, month, day, 0, 0, 0, 0, t.Location())
+}
+
+// hashPlaintextKeys replaces the plaintext keys left in table by the 0006
+// migration (rows whose prefix column is still empty) with their hash
+func hashPlaintextKeys(ctx context.Context, pool *pgxpool.Pool, table, hashColumn, prefixColumn string, hash func(string) string) (int, error) {
+	rows, err := pool.Query(ctx, `SELECT id, `+hashColumn+` FROM `+table+` WHERE `+prefixColumn+` = ''`)
+	if err != nil {
+		return 0, err
+	}
+
+	plaintext := map[string]string{}
+	for rows.Next() {
+		var id, key string
+		if err := rows.Scan(&id, &key); err != nil {
+			rows.Close()
+			return 0, err
+		}
+		plaintext[id] = key
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		return 0, err
+	}
+
+	converted := 0
+	for id, key := range plaintext {
+		tag, err := pool.Exec(ctx, `
+			UPDATE `+table+` SET `+hashColumn+` = $1, `+prefixColumn+` = $2
+			WHERE id = $3 AND `+prefixColumn+` = ''`,
+			hash(key), domain.KeyPrefix(key), id,
+		)
+		if err != nil {
+			return converted, err
+		}
+		converted += int(tag.RowsAffected())
+	}
+	return converted, nil
+}
+
+// olderThanWhere selects the rows of a project stored before a time. Headers
+// and bodies written before their project was recorded have a NULL project,
+// and are selected by an empty project ID.
+func olderThanWhere(timeColumn, projectID string, before time.Time) (string, []any) {
+	if projectID == "" {
+		return ` WHERE (project_id IS NULL OR project_id = '') AND ` + timeColumn + ` < $1`, []any{before}
+	}
+	return ` WHERE project_id = $1 AND ` + timeColumn + ` < $2`, []any{projectID, before}
+}
+
+// deleteOlderThan removes at most limit of the rows olderThanWhere selects
+func deleteOlderThan(ctx context.Context, pool *pgxpool.Pool, table, timeColumn, projectID string, before time.Time, limit int) (int64, error) {
+	where, args := olderThanWhere(timeColumn, projectID, before)
+	args = append(args, limit)
+
+	tag, err := pool.Exec(ctx, `
+		DELETE FROM `+table+` WHERE id IN (SELECT id FROM `+table+where+` LIMIT $`+strconv.Itoa(len(args))+`)`,
+		args...,
+	)
+	if err != nil {
+		return 0, err
+	}
+	return tag.RowsAffected(), nil
+}
+
+// volumeOlderThan measures the rows olderThanWhere selects by their stored size
+func volumeOlderThan(ctx context.Context, pool *pgxpool.Pool, table, timeColumn, projectID string, before time.Time) (domain.DataVolume, error) {
+	where, args := olderThanWhere(timeColumn, projectID, before)
+
+	var volume domain.DataVolume
+	err := pool.QueryRow(ctx, `
+		SELECT COUNT(*), COALESCE(SUM(pg_column_size(t.*)), 0) FROM `+table+` t`+where, args...,
+	).Scan(&volume.Documents, &volume.Bytes)
+	return volume, err
+}
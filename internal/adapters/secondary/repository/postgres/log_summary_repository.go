@@ -0,0 +1,115 @@
+package postgres
+
+import (
+	"context"
+	"encoding/json"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+	"github.com/spidey52/api-logs/internal/domain"
+	"github.com/spidey52/api-logs/internal/ports/output"
+)
+
+type LogSummaryRepository struct {
+	pool *pgxpool.Pool
+}
+
+func NewLogSummaryRepository(pool *pgxpool.Pool) *LogSummaryRepository {
+	return &LogSummaryRepository{pool: pool}
+}
+
+var _ output.LogSummaryRepository = (*LogSummaryRepository)(nil)
+
+const summaryColumns = `project_id, granularity, bucket, environment, method, route, count, error_count,
+	status_1xx, status_2xx, status_3xx, status_4xx, status_5xx, status_codes,
+	latency_sum, latency_min, latency_max, latency_histogram`
+
+// Replace implements output.LogSummaryRepository in a single transaction.
+// Summaries are upserted, as another instance may replace the same range at
+// the same time.
+func (r *LogSummaryRepository) Replace(ctx context.Context, projectID string, granularity domain.SummaryGranularity, from, to time.Time, summaries []*domain.LogSummary) error {
+	return pgx.BeginFunc(ctx, r.pool, func(tx pgx.Tx) error {
+		if _, err := tx.Exec(ctx, `
+			DELETE FROM api_log_summaries
+			WHERE project_id = $1 AND granularity = $2 AND bucket >= $3 AND bucket < $4`,
+			projectID, string(granularity), from, to,
+		); err != nil {
+			return err
+		}
+
+		batch := &pgx.Batch{}
+		for _, s := range summaries {
+			statusCodesJSON, _ := json.Marshal(s.StatusCodes)
+			batch.Queue(`
+				INSERT INTO api_log_summaries (`+summaryColumns+`)
+				VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13, $14, $15, $16, $17, $18)
+				ON CONFLICT (project_id, granularity, bucket, environment, method, route) DO UPDATE SET
+					count = EXCLUDED.count,
+					error_count = EXCLUDED.error_count,
+					status_1xx = EXCLUDED.status_1xx,
+					status_2xx = EXCLUDED.status_2xx,
+					status_3xx = EXCLUDED.status_3xx,
+					status_4xx = EXCLUDED.status_4xx,
+					status_5xx = EXCLUDED.status_5xx,
+					status_codes = EXCLUDED.status_codes,
+					latency_sum = EXCLUDED.latency_sum,
+					latency_min = EXCLUDED.latency_min,
+					latency_max = EXCLUDED.latency_max,
+					latency_histogram = EXCLUDED.latency_histogram`,
+				s.ProjectID, string(s.Granularity), s.Bucket, string(s.Environment), string(s.Method), s.Route, s.Count, s.ErrorCount,
+				s.Status1xx, s.Status2xx, s.Status3xx, s.Status4xx, s.Status5xx, statusCodesJSON,
+				s.LatencySum, s.LatencyMin, s.LatencyMax, s.Histogram,
+			)
+		}
+		return tx.SendBatch(ctx, batch).Close()
+	})
+}
+
+// Find implements output.LogSummaryRepository.
+func (r *LogSummaryRepository) Find(ctx context.Context, filter domain.SummaryFilter) ([]*domain.LogSummary, error) {
+	query := `SELECT ` + summaryColumns + ` FROM api_log_summaries
+		WHERE project_id = $1 AND granularity = $2 AND bucket >= $3 AND bucket < $4`
+	args := []interface{}{filter.ProjectID, string(filter.Granularity), filter.From, filter.To}
+	if filter.Environment != "" {
+		query += ` AND environment = $5`
+		args = append(args, string(filter.Environment))
+	}
+	query += ` ORDER BY bucket, environment, method, route`
+
+	rows, err := r.pool.Query(ctx, query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var summaries []*domain.LogSummary
+	for rows.Next() {
+		var s domain.LogSummary
+		var granularity, environment, method string
+		var statusCodesJSON []byte
+		if err := rows.Scan(
+			&s.ProjectID, &granularity, &s.Bucket, &environment, &method, &s.Route, &s.Count, &s.ErrorCount,
+			&s.Status1xx, &s.Status2xx, &s.Status3xx, &s.Status4xx, &s.Status5xx, &statusCodesJSON,
+			&s.LatencySum, &s.LatencyMin, &s.LatencyMax, &s.Histogram,
+		); err != nil {
+			return nil, err
+		}
+		s.Granularity = domain.SummaryGranularity(granularity)
+		s.Environment = domain.Environment(environment)
+		s.Method = domain.HTTPMethod(method)
+		s.Bucket = s.Bucket.UTC()
+		json.Unmarshal(statusCodesJSON, &s.StatusCodes)
+		summaries = append(summaries, &s)
+	}
+	return summaries, rows.Err()
+}
+
+// DeleteOlderThan implements output.LogSummaryRepository.
+func (r *LogSummaryRepository) DeleteOlderThan(ctx context.Context, granularity domain.SummaryGranularity, before time.Time) (int64, error) {
+	tag, err := r.pool.Exec(ctx, `DELETE FROM api_log_summaries WHERE granularity = $1 AND bucket < $2`, string(granularity), before)
+	if err != nil {
+		return 0, err
+	}
+	return tag.RowsAffected(), nil
+}
@@ -0,0 +1,87 @@
+-- Initial schema for the PostgreSQL storage backend
+
+CREATE TABLE IF NOT EXISTS projects (
+	id          TEXT PRIMARY KEY,
+	name        TEXT NOT NULL,
+	description TEXT NOT NULL DEFAULT '',
+	api_key     TEXT NOT NULL UNIQUE,
+	environment TEXT NOT NULL,
+	is_active   BOOLEAN NOT NULL DEFAULT TRUE,
+	created_at  TIMESTAMPTZ NOT NULL,
+	updated_at  TIMESTAMPTZ NOT NULL
+);
+
+CREATE INDEX IF NOT EXISTS idx_projects_environment ON projects (environment);
+CREATE INDEX IF NOT EXISTS idx_projects_created_at ON projects (created_at DESC);
+
+CREATE TABLE IF NOT EXISTS users (
+	id         TEXT PRIMARY KEY,
+	name       TEXT NOT NULL,
+	identifier TEXT NOT NULL,
+	metadata   JSONB,
+	created_at TIMESTAMPTZ NOT NULL,
+	project_id TEXT NOT NULL DEFAULT '',
+	UNIQUE (project_id, identifier)
+);
+
+CREATE INDEX IF NOT EXISTS idx_users_created_at ON users (created_at DESC);
+
+CREATE TABLE IF NOT EXISTS api_logs (
+	id             TEXT PRIMARY KEY,
+	project_id     TEXT NOT NULL,
+	environment    TEXT NOT NULL,
+	method         TEXT NOT NULL,
+	path           TEXT NOT NULL,
+	params         JSONB,
+	query_params   JSONB,
+	status_code    INTEGER NOT NULL,
+	response_time  BIGINT NOT NULL DEFAULT 0,
+	content_length BIGINT NOT NULL DEFAULT 0,
+	ip_address     TEXT NOT NULL DEFAULT '',
+	user_agent     TEXT NOT NULL DEFAULT '',
+	error_message  TEXT NOT NULL DEFAULT '',
+	user_id        TEXT,
+	timestamp      TIMESTAMPTZ NOT NULL
+);
+
+CREATE INDEX IF NOT EXISTS idx_api_logs_project_env_ts ON api_logs (project_id, environment, timestamp DESC);
+CREATE INDEX IF NOT EXISTS idx_api_logs_status_code ON api_logs (status_code);
+CREATE INDEX IF NOT EXISTS idx_api_logs_method ON api_logs (method);
+CREATE INDEX IF NOT EXISTS idx_api_logs_user_id ON api_logs (user_id);
+CREATE INDEX IF NOT EXISTS idx_api_logs_timestamp ON api_logs (timestamp);
+
+CREATE TABLE IF NOT EXISTS apilog_headers (
+	id               TEXT PRIMARY KEY,
+	log_id           TEXT NOT NULL UNIQUE,
+	request_headers  JSONB,
+	response_headers JSONB,
+	created_at       TIMESTAMPTZ NOT NULL
+);
+
+CREATE INDEX IF NOT EXISTS idx_apilog_headers_created_at ON apilog_headers (created_at);
+
+CREATE TABLE IF NOT EXISTS apilog_bodies (
+	id            TEXT PRIMARY KEY,
+	log_id        TEXT NOT NULL UNIQUE,
+	request_body  JSONB,
+	response_body JSONB,
+	created_at    TIMESTAMPTZ NOT NULL
+);
+
+CREATE INDEX IF NOT EXISTS idx_apilog_bodies_created_at ON apilog_bodies (created_at);
+
+CREATE TABLE IF NOT EXISTS access_logs (
+	id            TEXT PRIMARY KEY,
+	project_id    TEXT NOT NULL,
+	timestamp     TIMESTAMPTZ NOT NULL,
+	actor_id      TEXT NOT NULL,
+	actor_type    TEXT NOT NULL,
+	action        TEXT NOT NULL,
+	resource_type TEXT NOT NULL DEFAULT '',
+	resource_id   TEXT NOT NULL DEFAULT '',
+	outcome       TEXT NOT NULL,
+	message       TEXT NOT NULL DEFAULT '',
+	metadata      JSONB NOT NULL DEFAULT '{}'::jsonb
+);
+
+CREATE INDEX IF NOT EXISTS idx_access_logs_project_actor_ts ON access_logs (project_id, actor_id, timestamp DESC);
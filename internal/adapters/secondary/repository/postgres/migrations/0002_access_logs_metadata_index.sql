@@ -0,0 +1,4 @@
+-- Make access log metadata queryable with JSONB containment (metadata @> '{...}')
+
+CREATE INDEX IF NOT EXISTS idx_access_logs_metadata ON access_logs USING GIN (metadata jsonb_path_ops);
+CREATE INDEX IF NOT EXISTS idx_access_logs_timestamp ON access_logs (timestamp DESC);
@@ -0,0 +1,15 @@
+-- Project roles for dashboard accounts
+
+ALTER TABLE accounts ADD COLUMN IF NOT EXISTS is_admin BOOLEAN NOT NULL DEFAULT FALSE;
+
+CREATE TABLE IF NOT EXISTS project_members (
+	id         TEXT PRIMARY KEY,
+	project_id TEXT NOT NULL,
+	account_id TEXT NOT NULL,
+	role       TEXT NOT NULL,
+	created_at TIMESTAMPTZ NOT NULL,
+	updated_at TIMESTAMPTZ NOT NULL,
+	UNIQUE (project_id, account_id)
+);
+
+CREATE INDEX IF NOT EXISTS idx_project_members_account_id ON project_members (account_id);
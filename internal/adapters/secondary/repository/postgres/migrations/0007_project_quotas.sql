@@ -0,0 +1,10 @@
+-- Per-project ingestion limits; projects without a row use the configured defaults
+
+CREATE TABLE IF NOT EXISTS project_quotas (
+	project_id              TEXT PRIMARY KEY,
+	requests_per_second     BIGINT NOT NULL DEFAULT 0,
+	logs_per_day            BIGINT NOT NULL DEFAULT 0,
+	key_requests_per_second BIGINT NOT NULL DEFAULT 0,
+	key_logs_per_day        BIGINT NOT NULL DEFAULT 0,
+	updated_at              TIMESTAMPTZ NOT NULL
+);
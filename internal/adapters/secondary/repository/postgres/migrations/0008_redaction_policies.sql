@@ -0,0 +1,11 @@
+-- Per-project redaction policies; projects without a row use the configured default
+
+CREATE TABLE IF NOT EXISTS redaction_policies (
+	project_id   TEXT PRIMARY KEY,
+	headers      TEXT[],
+	query_params TEXT[],
+	json_paths   TEXT[],
+	patterns     TEXT[],
+	regexes      TEXT[],
+	updated_at   TIMESTAMPTZ NOT NULL
+);
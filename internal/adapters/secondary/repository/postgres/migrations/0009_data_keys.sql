@@ -0,0 +1,10 @@
+-- Per-project data keys encrypting captured headers and bodies, each wrapped
+-- by the master key named in master_key_id
+
+CREATE TABLE IF NOT EXISTS data_keys (
+	project_id    TEXT PRIMARY KEY,
+	master_key_id TEXT NOT NULL,
+	wrapped_key   BYTEA NOT NULL,
+	created_at    TIMESTAMPTZ NOT NULL,
+	updated_at    TIMESTAMPTZ NOT NULL
+);
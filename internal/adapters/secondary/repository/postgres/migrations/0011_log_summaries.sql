@@ -0,0 +1,27 @@
+-- Per-minute and per-hour rollups of each project's logs by environment,
+-- method and route, recomputed from api_logs by the rollup worker. The stats
+-- endpoint reads them instead of aggregating the raw logs.
+
+CREATE TABLE IF NOT EXISTS api_log_summaries (
+	project_id        TEXT NOT NULL,
+	granularity       TEXT NOT NULL,
+	bucket            TIMESTAMPTZ NOT NULL,
+	environment       TEXT NOT NULL,
+	method            TEXT NOT NULL,
+	route             TEXT NOT NULL,
+	count             BIGINT NOT NULL,
+	error_count       BIGINT NOT NULL,
+	status_1xx        BIGINT NOT NULL,
+	status_2xx        BIGINT NOT NULL,
+	status_3xx        BIGINT NOT NULL,
+	status_4xx        BIGINT NOT NULL,
+	status_5xx        BIGINT NOT NULL,
+	status_codes      JSONB,
+	latency_sum       BIGINT NOT NULL,
+	latency_min       BIGINT NOT NULL,
+	latency_max       BIGINT NOT NULL,
+	latency_histogram BIGINT[],
+	PRIMARY KEY (project_id, granularity, bucket, environment, method, route)
+);
+
+CREATE INDEX IF NOT EXISTS idx_api_log_summaries_granularity_bucket ON api_log_summaries (granularity, bucket);
@@ -0,0 +1,123 @@
+package postgres
+
+import (
+	"context"
+	"os"
+	"testing"
+
+	"github.com/jackc/pgx/v5/pgxpool"
+	"github.com/spidey52/api-logs/internal/adapters/secondary/repository/contract"
+	"github.com/spidey52/api-logs/internal/ports/output"
+)
+
+// openTestPool connects to TEST_POSTGRES_URI, migrates and empties every table.
+// Tests are skipped when the variable is unset.
+func openTestPool(t *testing.T) *pgxpool.Pool {
+	t.Helper()
+
+	uri := os.Getenv("TEST_POSTGRES_URI")
+	if uri == "" {
+		t.Skip("TEST_POSTGRES_URI not set")
+	}
+
+	ctx := context.Background()
+	pool, err := NewPool(ctx, uri)
+	if err != nil {
+		t.Fatalf("connect postgres: %v", err)
+	}
+	t.Cleanup(pool.Close)
+
+	if err := Migrate(ctx, pool); err != nil {
+		t.Fatalf("migrate postgres: %v", err)
+	}
+
+	_, err = pool.Exec(ctx, `TRUNCATE projects, users, api_logs, apilog_headers, apilog_bodies, access_logs, accounts, sessions, project_members, api_keys, project_quotas, redaction_policies, data_keys, api_log_summaries`)
+	if err != nil {
+		t.Fatalf("truncate postgres: %v", err)
+	}
+	return pool
+}
+
+func TestAPILogRepository(t *testing.T) {
+	contract.RunAPILogRepository(t, func(t *testing.T) output.APILogRepository {
+		return NewAPILogRepository(openTestPool(t))
+	})
+}
+
+func TestHeadersRepository(t *testing.T) {
+	contract.RunHeadersRepository(t, func(t *testing.T) output.APILogHeadersRepository {
+		return NewAPILogHeadersRepository(openTestPool(t))
+	})
+}
+
+func TestBodyRepository(t *testing.T) {
+	contract.RunBodyRepository(t, func(t *testing.T) output.APILogBodyRepository {
+		return NewAPILogBodyRepository(openTestPool(t))
+	})
+}
+
+func TestProjectRepository(t *testing.T) {
+	contract.RunProjectRepository(t, func(t *testing.T) output.ProjectRepository {
+		return NewProjectRepository(openTestPool(t))
+	})
+}
+
+func TestUserRepository(t *testing.T) {
+	contract.RunUserRepository(t, func(t *testing.T) output.UserRepository {
+		return NewUserRepository(openTestPool(t))
+	})
+}
+
+func TestAccessLogRepository(t *testing.T) {
+	contract.RunAccessLogRepository(t, func(t *testing.T) output.AccessLogRepository {
+		return NewAccessLogRepository(openTestPool(t))
+	})
+}
+
+func TestAccountRepository(t *testing.T) {
+	contract.RunAccountRepository(t, func(t *testing.T) output.AccountRepository {
+		return NewAccountRepository(openTestPool(t))
+	})
+}
+
+func TestSessionRepository(t *testing.T) {
+	contract.RunSessionRepository(t, func(t *testing.T) output.SessionRepository {
+		return NewSessionRepository(openTestPool(t))
+	})
+}
+
+func TestProjectMemberRepository(t *testing.T) {
+	contract.RunProjectMemberRepository(t, func(t *testing.T) output.ProjectMemberRepository {
+		return NewProjectMemberRepository(openTestPool(t))
+	})
+}
+
+func TestAPIKeyRepository(t *testing.T) {
+	contract.RunAPIKeyRepository(t, func(t *testing.T) output.APIKeyRepository {
+		return NewAPIKeyRepository(openTestPool(t))
+	})
+}
+
+func TestQuotaRepository(t *testing.T) {
+	contract.RunQuotaRepository(t, func(t *testing.T) output.QuotaRepository {
+		return NewQuotaRepository(openTestPool(t))
+	})
+}
+
+func TestRedactionPolicyRepository(t *testing.T) {
+	contract.RunRedactionPolicyRepository(t, func(t *testing.T) output.RedactionPolicyRepository {
+		return NewRedactionPolicyRepository(openTestPool(t))
+	})
+}
+
+func TestDataKeyRepository(t *testing.T) {
+	contract.RunDataKeyRepository(t, func(t *testing.T) output.DataKeyRepository {
+		return NewDataKeyRepository(openTestPool(t))
+	})
+}
+
+func TestLogSummaryRepository(t *testing.T) {
+	contract.RunLogSummaryRepository(t, func(t *testing.T) output.LogSummaryRepository {
+		return NewLogSummaryRepository(openTestPool(t))
+	})
+}
@@ -0,0 +1,124 @@
+package postgres
+
+import (
+	"context"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+	"github.com/spidey52/api-logs/internal/domain"
+	"github.com/spidey52/api-logs/internal/ports/output"
+)
+
+type ProjectMemberRepository struct {
+	pool *pgxpool.Pool
+}
+
+func NewProjectMemberRepository(pool *pgxpool.Pool) *ProjectMemberRepository {
+	return &ProjectMemberRepository{pool: pool}
+}
+
+var _ output.ProjectMemberRepository = (*ProjectMemberRepository)(nil)
+
+const memberColumns = `id, project_id, account_id, role, created_at, updated_at`
+
+func scanMember(row pgx.Row) (*domain.ProjectMember, error) {
+	var member domain.ProjectMember
+	var role string
+	err := row.Scan(&member.ID, &member.ProjectID, &member.AccountID, &role, &member.CreatedAt, &member.UpdatedAt)
+	if err != nil {
+		return nil, err
+	}
+	member.Role = domain.ProjectRole(role)
+	return &member, nil
+}
+
+// Create implements output.ProjectMemberRepository.
+func (r *ProjectMemberRepository) Create(ctx context.Context, member *domain.ProjectMember) error {
+	_, err := r.pool.Exec(ctx, `
+		INSERT INTO project_members (`+memberColumns+`)
+		VALUES ($1, $2, $3, $4, $5, $6)`,
+		member.ID, member.ProjectID, member.AccountID, string(member.Role), member.CreatedAt, member.UpdatedAt,
+	)
+	if isUniqueViolation(err) {
+		return domain.ErrDuplicateMember
+	}
+	return err
+}
+
+// Find implements output.ProjectMemberRepository.
+func (r *ProjectMemberRepository) Find(ctx context.Context, projectID, accountID string) (*domain.ProjectMember, error) {
+	member, err := scanMember(r.pool.QueryRow(ctx, `
+		SELECT `+memberColumns+` FROM project_members
+		WHERE project_id = $1 AND account_id = $2`, projectID, accountID))
+	if err != nil {
+		if err == pgx.ErrNoRows {
+			return nil, domain.ErrMemberNotFound
+		}
+		return nil, err
+	}
+	return member, nil
+}
+
+// Update implements output.ProjectMemberRepository.
+func (r *ProjectMemberRepository) Update(ctx context.Context, member *domain.ProjectMember) error {
+	tag, err := r.pool.Exec(ctx, `
+		UPDATE project_members SET role = $1, updated_at = $2
+		WHERE project_id = $3 AND account_id = $4`,
+		string(member.Role), member.UpdatedAt, member.ProjectID, member.AccountID,
+	)
+	if err != nil {
+		return err
+	}
+
+	if tag.RowsAffected() == 0 {
+		return domain.ErrMemberNotFound
+	}
+	return nil
+}
+
+// Delete implements output.ProjectMemberRepository.
+func (r *ProjectMemberRepository) Delete(ctx context.Context, projectID, accountID string) error {
+	tag, err := r.pool.Exec(ctx, `DELETE FROM project_members WHERE project_id = $1 AND account_id = $2`, projectID, accountID)
+	if err != nil {
+		return err
+	}
+
+	if tag.RowsAffected() == 0 {
+		return domain.ErrMemberNotFound
+	}
+	return nil
+}
+
+// DeleteByProject implements output.ProjectMemberRepository.
+func (r *ProjectMemberRepository) DeleteByProject(ctx context.Context, projectID string) error {
+	_, err := r.pool.Exec(ctx, `DELETE FROM project_members WHERE project_id = $1`, projectID)
+	return err
+}
+
+// ListByProject implements output.ProjectMemberRepository.
+func (r *ProjectMemberRepository) ListByProject(ctx context.Context, projectID string) ([]*domain.ProjectMember, error) {
+	return r.list(ctx, `SELECT `+memberColumns+` FROM project_members WHERE project_id = $1 ORDER BY created_at, id`, projectID)
+}
+
+// ListByAccount implements output.ProjectMemberRepository.
+func (r *ProjectMemberRepository) ListByAccount(ctx context.Context, accountID string) ([]*domain.ProjectMember, error) {
+	return r.list(ctx, `SELECT `+memberColumns+` FROM project_members WHERE account_id = $1 ORDER BY created_at, id`, accountID)
+}
+
+func (r *ProjectMemberRepository) list(ctx context.Context, query string, args ...interface{}) ([]*domain.ProjectMember, error) {
+	rows, err := r.pool.Query(ctx, query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	members := []*domain.ProjectMember{}
+	for rows.Next() {
+		member, err := scanMember(rows)
+		if err != nil {
+			return nil, err
+		}
+		members = append(members, member)
+	}
+	return members, rows.Err()
+}
@@ -21,10 +21,15 @@ func NewProjectRepository(pool *pgxpool.Pool) *ProjectRepository {
 // Create implements output.ProjectRepository.
 func (r *ProjectRepository) Create(ctx context.Context, project *domain.Project) error {
 	_, err := r.pool.Exec(ctx, `
-		INSERT INTO projects (id, name, description, api_key, environment, is_active, created_at, updated_at)
-		VALUES ($1, $2, $3, $4, $5, $6, $7, $8)`,
-		project.ID, project.Name, project.Description, project.APIKey, string(project.Environment), project.IsActive, project.CreatedAt, project.UpdatedAt,
+		INSERT INTO projects (id, name, description, api_key_hash, api_key_prefix, environment, is_active, created_at, updated_at,
+			retention_logs_days, retention_headers_days, retention_bodies_days, retention_access_logs_days)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13)`,
+		project.ID, project.Name, project.Description, project.APIKeyHash, project.APIKeyPrefix, string(project.Environment), project.IsActive, project.CreatedAt, project.UpdatedAt,
+		project.Retention.LogsDays, project.Retention.HeadersDays, project.Retention.BodiesDays, project.Retention.AccessLogsDays,
 	)
+	if isUniqueViolation(err) {
+		return domain.ErrDuplicateAPIKey
+	}
 	return err
 }
 
@@ -34,9 +39,10 @@ func (r *ProjectRepository) FindByID(ctx context.Context, id string) (*domain.Pr
 	var envStr string
 
 	err := r.pool.QueryRow(ctx, `
-		SELECT id, name, description, api_key, environment, is_active, created_at, updated_at
+		SELECT id, name, description, api_key_hash, api_key_prefix, environment, is_active, created_at, updated_at,
+			retention_logs_days, retention_headers_days, retention_bodies_days, retention_access_logs_days
 		FROM projects WHERE id = $1`, id).Scan(
-		&project.ID, &project.Name, &project.Description, &project.APIKey, &envStr, &project.IsActive, &project.CreatedAt, &project.UpdatedAt,
+		&project.ID, &project.Name, &project.Description, &project.APIKeyHash, &project.APIKeyPrefix, &envStr, &project.IsActive, &project.CreatedAt, &project.UpdatedAt,
 	)
 	if err != nil {
 		if err == pgx.ErrNoRows {
@@ -50,14 +56,15 @@ func (r *ProjectRepository) FindByID(ctx context.Context, id string) (*domain.Pr
 }
 
 // FindByAPIKey implements output.ProjectRepository.
-func (r *ProjectRepository) FindByAPIKey(ctx context.Context, apiKey string) (*domain.Project, error) {
+func (r *ProjectRepository) FindByAPIKey(ctx context.Context, apiKeyHash string) (*domain.Project, error) {
 	var project domain.Project
 	var envStr string
 
 	err := r.pool.QueryRow(ctx, `
-		SELECT id, name, description, api_key, environment, is_active, created_at, updated_at
-		FROM projects WHERE api_key = $1`, apiKey).Scan(
-		&project.ID, &project.Name, &project.Description, &project.APIKey, &envStr, &project.IsActive, &project.CreatedAt, &project.UpdatedAt,
+		SELECT id, name, description, api_key_hash, api_key_prefix, environment, is_active, created_at, updated_at,
+			retention_logs_days, retention_headers_days, retention_bodies_days, retention_access_logs_days
+		FROM projects WHERE api_key_hash = $1`, apiKeyHash).Scan(
+		&project.ID, &project.Name, &project.Description, &project.APIKeyHash, &project.APIKeyPrefix, &envStr, &project.IsActive, &project.CreatedAt, &project.UpdatedAt,
 	)
 	if err != nil {
 		if err == pgx.ErrNoRows {
@@ -73,7 +80,8 @@ func (r *ProjectRepository) FindByAPIKey(ctx context.Context, apiKey string) (*d
 // FindAll implements output.ProjectRepository.
 func (r *ProjectRepository) FindAll(ctx context.Context, filter domain.ProjectFilter) ([]*domain.Project, error) {
 	query := `
-		SELECT id, name, description, api_key, environment, is_active, created_at, updated_at
+		SELECT id, name, description, api_key_hash, api_key_prefix, environment, is_active, created_at, updated_at,
+			retention_logs_days, retention_headers_days, retention_bodies_days, retention_access_logs_days
 		FROM projects WHERE 1=1`
 
 	args := []interface{}{}
@@ -91,8 +99,17 @@ func (r *ProjectRepository) FindAll(ctx context.Context, filter domain.ProjectFi
 		args = append(args, *filter.IsActive)
 	}
 
-	query += ` ORDER BY created_at DESC LIMIT $` + strconv.Itoa(argIndex+1) + ` OFFSET $` + strconv.Itoa(argIndex+2)
-	args = append(args, filter.Limit, filter.Offset)
+	if len(filter.IDs) > 0 {
+		argIndex++
+		query += ` AND id = ANY($` + strconv.Itoa(argIndex) + `)`
+		args = append(args, filter.IDs)
+	}
+
+	query += ` ORDER BY created_at DESC`
+	if filter.Limit > 0 {
+		query += ` LIMIT $` + strconv.Itoa(argIndex+1) + ` OFFSET $` + strconv.Itoa(argIndex+2)
+		args = append(args, filter.Limit, filter.Offset)
+	}
 
 	rows, err := r.pool.Query(ctx, query, args...)
 	if err != nil {
@@ -100,12 +117,12 @@ func (r *ProjectRepository) FindAll(ctx context.Context, filter domain.ProjectFi
 	}
 	defer rows.Close()
 
-	var projects []*domain.Project
+	projects := []*domain.Project{}
 	for rows.Next() {
 		var project domain.Project
 		var envStr string
 		err := rows.Scan(
-			&project.ID, &project.Name, &project.Description, &project.APIKey, &envStr, &project.IsActive, &project.CreatedAt, &project.UpdatedAt,
+			&project.ID, &project.Name, &project.Description, &project.APIKeyHash, &project.APIKeyPrefix, &envStr, &project.IsActive, &project.CreatedAt, &project.UpdatedAt,
 		)
 		if err != nil {
 			return nil, err
@@ -113,30 +130,54 @@ func (r *ProjectRepository) FindAll(ctx context.Context, filter domain.ProjectFi
 		project.Environment = domain.Environment(envStr)
 		projects = append(projects, &project)
 	}
-	return projects, nil
+	return projects, rows.Err()
 }
 
 // Update implements output.ProjectRepository.
 func (r *ProjectRepository) Update(ctx context.Context, project *domain.Project) error {
-	_, err := r.pool.Exec(ctx, `
-		UPDATE projects SET name = $1, description = $2, api_key = $3, environment = $4, is_active = $5, updated_at = $6
-		WHERE id = $7`,
-		project.Name, project.Description, project.APIKey, string(project.Environment), project.IsActive, project.UpdatedAt, project.ID,
+	tag, err := r.pool.Exec(ctx, `
+		UPDATE projects SET name = $1, description = $2, api_key_hash = $3, api_key_prefix = $4, environment = $5, is_active = $6, updated_at = $7,
+			retention_logs_days = $8, retention_headers_days = $9, retention_bodies_days = $10, retention_access_logs_days = $11
+		WHERE id = $12`,
+		project.Name, project.Description, project.APIKeyHash, project.APIKeyPrefix, string(project.Environment), project.IsActive, project.UpdatedAt,
+		project.Retention.LogsDays, project.Retention.HeadersDays, project.Retention.BodiesDays, project.Retention.AccessLogsDays, project.ID,
 	)
-	return err
+	if err != nil {
+		if isUniqueViolation(err) {
+			return domain.ErrDuplicateAPIKey
+		}
+		return err
+	}
+
+	if tag.RowsAffected() == 0 {
+		return domain.ErrProjectNotFound
+	}
+	return nil
 }
 
 // Delete implements output.ProjectRepository.
 func (r *ProjectRepository) Delete(ctx context.Context, id string) error {
-	_, err := r.pool.Exec(ctx, `DELETE FROM projects WHERE id = $1`, id)
-	return err
+	tag, err := r.pool.Exec(ctx, `DELETE FROM projects WHERE id = $1`, id)
+	if err != nil {
+		return err
+	}
+
+	if tag.RowsAffected() == 0 {
+		return domain.ErrProjectNotFound
+	}
+	return nil
 }
 
 // ExistsByAPIKey implements output.ProjectRepository.
-func (r *ProjectRepository) ExistsByAPIKey(ctx context.Context, apiKey string) (bool, error) {
+func (r *ProjectRepository) ExistsByAPIKey(ctx context.Context, apiKeyHash string) (bool, error) {
 	var exists bool
-	err := r.pool.QueryRow(ctx, `SELECT EXISTS(SELECT 1 FROM projects WHERE api_key = $1)`, apiKey).Scan(&exists)
+	err := r.pool.QueryRow(ctx, `SELECT EXISTS(SELECT 1 FROM projects WHERE api_key_hash = $1)`, apiKeyHash).Scan(&exists)
 	return exists, err
 }
 
+// HashPlaintextAPIKeys implements output.ProjectRepository.
+func (r *ProjectRepository) HashPlaintextAPIKeys(ctx context.Context, hash func(key string) string) (int, error) {
+	return hashPlaintextKeys(ctx, r.pool, "projects", "api_key_hash", "api_key_prefix", hash)
+}
+
 var _ output.ProjectRepository = (*ProjectRepository)(nil)
@@ -0,0 +1,66 @@
+package postgres
+
+import (
+	"context"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+	"github.com/spidey52/api-logs/internal/domain"
+	"github.com/spidey52/api-logs/internal/ports/output"
+)
+
+type QuotaRepository struct {
+	pool *pgxpool.Pool
+}
+
+func NewQuotaRepository(pool *pgxpool.Pool) *QuotaRepository {
+	return &QuotaRepository{pool: pool}
+}
+
+var _ output.QuotaRepository = (*QuotaRepository)(nil)
+
+// Find implements output.QuotaRepository.
+func (r *QuotaRepository) Find(ctx context.Context, projectID string) (*domain.ProjectQuota, error) {
+	var quota domain.ProjectQuota
+	err := r.pool.QueryRow(ctx, `
+		SELECT project_id, requests_per_second, logs_per_day, key_requests_per_second, key_logs_per_day, updated_at
+		FROM project_quotas WHERE project_id = $1`, projectID).Scan(
+		&quota.ProjectID, &quota.RequestsPerSecond, &quota.LogsPerDay, &quota.KeyRequestsPerSecond, &quota.KeyLogsPerDay, &quota.UpdatedAt,
+	)
+	if err != nil {
+		if err == pgx.ErrNoRows {
+			return nil, domain.ErrQuotaNotFound
+		}
+		return nil, err
+	}
+	return &quota, nil
+}
+
+// Upsert implements output.QuotaRepository.
+func (r *QuotaRepository) Upsert(ctx context.Context, quota *domain.ProjectQuota) error {
+	_, err := r.pool.Exec(ctx, `
+		INSERT INTO project_quotas (project_id, requests_per_second, logs_per_day, key_requests_per_second, key_logs_per_day, updated_at)
+		VALUES ($1, $2, $3, $4, $5, $6)
+		ON CONFLICT (project_id) DO UPDATE SET
+			requests_per_second = EXCLUDED.requests_per_second,
+			logs_per_day = EXCLUDED.logs_per_day,
+			key_requests_per_second = EXCLUDED.key_requests_per_second,
+			key_logs_per_day = EXCLUDED.key_logs_per_day,
+			updated_at = EXCLUDED.updated_at`,
+		quota.ProjectID, quota.RequestsPerSecond, quota.LogsPerDay, quota.KeyRequestsPerSecond, quota.KeyLogsPerDay, quota.UpdatedAt,
+	)
+	return err
+}
+
+// Delete implements output.QuotaRepository.
+func (r *QuotaRepository) Delete(ctx context.Context, projectID string) error {
+	tag, err := r.pool.Exec(ctx, `DELETE FROM project_quotas WHERE project_id = $1`, projectID)
+	if err != nil {
+		return err
+	}
+
+	if tag.RowsAffected() == 0 {
+		return domain.ErrQuotaNotFound
+	}
+	return nil
+}
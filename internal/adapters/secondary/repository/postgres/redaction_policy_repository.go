@@ -0,0 +1,67 @@
+package postgres
+
+import (
+	"context"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+	"github.com/spidey52/api-logs/internal/domain"
+	"github.com/spidey52/api-logs/internal/ports/output"
+)
+
+type RedactionPolicyRepository struct {
+	pool *pgxpool.Pool
+}
+
+func NewRedactionPolicyRepository(pool *pgxpool.Pool) *RedactionPolicyRepository {
+	return &RedactionPolicyRepository{pool: pool}
+}
+
+var _ output.RedactionPolicyRepository = (*RedactionPolicyRepository)(nil)
+
+// Find implements output.RedactionPolicyRepository.
+func (r *RedactionPolicyRepository) Find(ctx context.Context, projectID string) (*domain.RedactionPolicy, error) {
+	var policy domain.RedactionPolicy
+	err := r.pool.QueryRow(ctx, `
+		SELECT project_id, headers, query_params, json_paths, patterns, regexes, updated_at
+		FROM redaction_policies WHERE project_id = $1`, projectID).Scan(
+		&policy.ProjectID, &policy.Headers, &policy.QueryParams, &policy.JSONPaths, &policy.Patterns, &policy.Regexes, &policy.UpdatedAt,
+	)
+	if err != nil {
+		if err == pgx.ErrNoRows {
+			return nil, domain.ErrRedactionPolicyNotFound
+		}
+		return nil, err
+	}
+	return &policy, nil
+}
+
+// Upsert implements output.RedactionPolicyRepository.
+func (r *RedactionPolicyRepository) Upsert(ctx context.Context, policy *domain.RedactionPolicy) error {
+	_, err := r.pool.Exec(ctx, `
+		INSERT INTO redaction_policies (project_id, headers, query_params, json_paths, patterns, regexes, updated_at)
+		VALUES ($1, $2, $3, $4, $5, $6, $7)
+		ON CONFLICT (project_id) DO UPDATE SET
+			headers = EXCLUDED.headers,
+			query_params = EXCLUDED.query_params,
+			json_paths = EXCLUDED.json_paths,
+			patterns = EXCLUDED.patterns,
+			regexes = EXCLUDED.regexes,
+			updated_at = EXCLUDED.updated_at`,
+		policy.ProjectID, policy.Headers, policy.QueryParams, policy.JSONPaths, policy.Patterns, policy.Regexes, policy.UpdatedAt,
+	)
+	return err
+}
+
+// Delete implements output.RedactionPolicyRepository.
+func (r *RedactionPolicyRepository) Delete(ctx context.Context, projectID string) error {
+	tag, err := r.pool.Exec(ctx, `DELETE FROM redaction_policies WHERE project_id = $1`, projectID)
+	if err != nil {
+		return err
+	}
+
+	if tag.RowsAffected() == 0 {
+		return domain.ErrRedactionPolicyNotFound
+	}
+	return nil
+}
@@ -16,7 +16,31 @@ type UserRepository struct {
 
 // GetUserMap implements output.UserRepository.
 func (r *UserRepository) GetUserMap(ctx context.Context, ids []string) (map[string]*domain.User, error) {
-	panic("unimplemented")
+	userMap := make(map[string]*domain.User)
+	if len(ids) == 0 {
+		return userMap, nil
+	}
+
+	rows, err := r.pool.Query(ctx, `
+		SELECT id, name, identifier, metadata, created_at, project_id
+		FROM users WHERE id = ANY($1)`, ids)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var user domain.User
+		var metadataJSON []byte
+		if err := rows.Scan(
+			&user.ID, &user.Name, &user.Identifier, &metadataJSON, &user.CreatedAt, &user.ProjectID,
+		); err != nil {
+			return nil, err
+		}
+		json.Unmarshal(metadataJSON, &user.Metadata)
+		userMap[user.ID] = &user
+	}
+	return userMap, rows.Err()
 }
 
 func NewUserRepository(pool *pgxpool.Pool) *UserRepository {
@@ -32,6 +56,9 @@ func (r *UserRepository) Create(ctx context.Context, user *domain.User) error {
 		VALUES ($1, $2, $3, $4, $5, $6)`,
 		user.ID, user.Name, user.Identifier, metadataJSON, user.CreatedAt, user.ProjectID,
 	)
+	if isUniqueViolation(err) {
+		return domain.ErrDuplicateUserIdentifier
+	}
 	return err
 }
 
@@ -81,27 +108,50 @@ func (r *UserRepository) FindByIdentifier(ctx context.Context, identifier string
 func (r *UserRepository) Update(ctx context.Context, user *domain.User) error {
 	metadataJSON, _ := json.Marshal(user.Metadata)
 
-	_, err := r.pool.Exec(ctx, `
+	tag, err := r.pool.Exec(ctx, `
 		UPDATE users SET name = $1, identifier = $2, metadata = $3
 		WHERE id = $4`,
 		user.Name, user.Identifier, metadataJSON, user.ID,
 	)
-	return err
+	if err != nil {
+		if isUniqueViolation(err) {
+			return domain.ErrDuplicateUserIdentifier
+		}
+		return err
+	}
+
+	if tag.RowsAffected() == 0 {
+		return domain.ErrUserNotFound
+	}
+	return nil
 }
 
 // Delete implements output.UserRepository.
 func (r *UserRepository) Delete(ctx context.Context, id string) error {
-	_, err := r.pool.Exec(ctx, `DELETE FROM users WHERE id = $1`, id)
+	tag, err := r.pool.Exec(ctx, `DELETE FROM users WHERE id = $1`, id)
+	if err != nil {
+		return err
+	}
+
+	if tag.RowsAffected() == 0 {
+		return domain.ErrUserNotFound
+	}
+	return nil
+}
+
+// DeleteByProject implements output.UserRepository.
+func (r *UserRepository) DeleteByProject(ctx context.Context, projectID string) error {
+	_, err := r.pool.Exec(ctx, `DELETE FROM users WHERE project_id = $1`, projectID)
 	return err
 }
 
 // List implements output.UserRepository.
-func (r *UserRepository) List(ctx context.Context, page, pageSize int) ([]*domain.User, int, error) {
+func (r *UserRepository) List(ctx context.Context, projectID string, page, pageSize int) ([]*domain.User, int, error) {
 	offset := (page - 1) * pageSize
 
 	// Get total count
 	var total int
-	err := r.pool.QueryRow(ctx, `SELECT COUNT(*) FROM users`).Scan(&total)
+	err := r.pool.QueryRow(ctx, `SELECT COUNT(*) FROM users WHERE project_id = $1`, projectID).Scan(&total)
 	if err != nil {
 		return nil, 0, err
 	}
@@ -109,13 +159,13 @@ func (r *UserRepository) List(ctx context.Context, page, pageSize int) ([]*domai
 	// Get users
 	rows, err := r.pool.Query(ctx, `
 		SELECT id, name, identifier, metadata, created_at, project_id
-		FROM users ORDER BY created_at DESC LIMIT $1 OFFSET $2`, pageSize, offset)
+		FROM users WHERE project_id = $1 ORDER BY created_at DESC LIMIT $2 OFFSET $3`, projectID, pageSize, offset)
 	if err != nil {
 		return nil, 0, err
 	}
 	defer rows.Close()
 
-	var users []*domain.User
+	users := []*domain.User{}
 	for rows.Next() {
 		var user domain.User
 		var metadataJSON []byte
@@ -128,7 +178,7 @@ func (r *UserRepository) List(ctx context.Context, page, pageSize int) ([]*domai
 		json.Unmarshal(metadataJSON, &user.Metadata)
 		users = append(users, &user)
 	}
-	return users, total, nil
+	return users, total, rows.Err()
 }
 
 var _ output.UserRepository = (*UserRepository)(nil)
@@ -0,0 +1,223 @@
+package sqlite
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"strings"
+	"time"
+
+	"github.com/spidey52/api-logs/internal/domain"
+	"github.com/spidey52/api-logs/internal/ports/output"
+)
+
+type AccessLogRepository struct {
+	db *sql.DB
+}
+
+func NewAccessLogRepository(db *sql.DB) *AccessLogRepository {
+	return &AccessLogRepository{db: db}
+}
+
+var _ output.AccessLogRepository = (*AccessLogRepository)(nil)
+
+const accessLogColumns = `id, project_id, timestamp, actor_id, actor_type, action,
+	resource_type, resource_id, outcome, message, metadata`
+
+// buildAccessLogWhere translates the filter into a WHERE clause and its arguments
+func buildAccessLogWhere(filter domain.AccessLogFilter) (string, []any) {
+	conditions := []string{}
+	args := []any{}
+
+	add := func(condition string, value any) {
+		conditions = append(conditions, condition)
+		args = append(args, value)
+	}
+
+	if filter.ProjectID != "" {
+		add("project_id = ?", filter.ProjectID)
+	}
+	if filter.ActorID != "" {
+		add("actor_id = ?", filter.ActorID)
+	}
+	if filter.ActorType != nil {
+		add("actor_type = ?", string(*filter.ActorType))
+	}
+	if filter.Action != "" {
+		add("action = ?", filter.Action)
+	}
+	if filter.ResourceType != "" {
+		add("resource_type = ?", filter.ResourceType)
+	}
+	if filter.ResourceID != "" {
+		add("resource_id = ?", filter.ResourceID)
+	}
+	if filter.Outcome != nil {
+		add("outcome = ?", string(*filter.Outcome))
+	}
+	if filter.Search != "" {
+		add("message LIKE ?", "%"+filter.Search+"%")
+	}
+	for key, value := range filter.Metadata {
+		// json_extract on a quoted path handles keys with dots or spaces
+		add(`json_extract(metadata, '$."`+strings.ReplaceAll(key, `"`, ``)+`"') = ?`, value)
+	}
+	if filter.FromDate != nil {
+		add("timestamp >= ?", toMillis(*filter.FromDate))
+	}
+	if filter.ToDate != nil {
+		add("timestamp <= ?", toMillis(*filter.ToDate))
+	}
+
+	if len(conditions) == 0 {
+		return "", args
+	}
+	return " WHERE " + strings.Join(conditions, " AND "), args
+}
+
+func scanAccessLog(row rowScanner) (*domain.AccessLog, error) {
+	var log domain.AccessLog
+	var actorType, outcome string
+	var metadataJSON *string
+	var timestamp int64
+
+	err := row.Scan(
+		&log.ID, &log.ProjectID, &timestamp, &log.ActorID, &actorType, &log.Action,
+		&log.ResourceType, &log.ResourceID, &outcome, &log.Message, &metadataJSON,
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	log.Timestamp = fromMillis(timestamp)
+	log.ActorType = domain.ActorType(actorType)
+	log.Outcome = domain.AccessOutcome(outcome)
+	unmarshalJSON(metadataJSON, &log.Metadata)
+	return &log, nil
+}
+
+func accessLogArgs(log *domain.AccessLog) []any {
+	metadataJSON := "{}"
+	if len(log.Metadata) > 0 {
+		if data, err := json.Marshal(log.Metadata); err == nil {
+			metadataJSON = string(data)
+		}
+	}
+
+	return []any{
+		log.ID, log.ProjectID, toMillis(log.Timestamp), log.ActorID, string(log.ActorType), log.Action,
+		log.ResourceType, log.ResourceID, string(log.Outcome), log.Message, metadataJSON,
+	}
+}
+
+// Create implements output.AccessLogRepository.
+func (r *AccessLogRepository) Create(ctx context.Context, log *domain.AccessLog) error {
+	_, err := r.db.ExecContext(ctx, `
+		INSERT INTO access_logs (`+accessLogColumns+`)
+		VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)`, accessLogArgs(log)...)
+	return err
+}
+
+// CreateMany implements output.AccessLogRepository in a single transaction.
+func (r *AccessLogRepository) CreateMany(ctx context.Context, logs []*domain.AccessLog) error {
+	if len(logs) == 0 {
+		return nil
+	}
+
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	stmt, err := tx.PrepareContext(ctx, `
+		INSERT INTO access_logs (`+accessLogColumns+`)
+		VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)`)
+	if err != nil {
+		return err
+	}
+	defer stmt.Close()
+
+	for _, log := range logs {
+		if _, err := stmt.ExecContext(ctx, accessLogArgs(log)...); err != nil {
+			return err
+		}
+	}
+
+	return tx.Commit()
+}
+
+// FindByID implements output.AccessLogRepository.
+func (r *AccessLogRepository) FindByID(ctx context.Context, id string) (*domain.AccessLog, error) {
+	log, err := scanAccessLog(r.db.QueryRowContext(ctx, `SELECT `+accessLogColumns+` FROM access_logs WHERE id = ?`, id))
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, domain.ErrAccessLogNotFound
+		}
+		return nil, err
+	}
+	return log, nil
+}
+
+// FindByFilter implements output.AccessLogRepository.
+func (r *AccessLogRepository) FindByFilter(ctx context.Context, filter domain.AccessLogFilter) ([]*domain.AccessLog, error) {
+	where, args := buildAccessLogWhere(filter)
+
+	query := `SELECT ` + accessLogColumns + ` FROM access_logs` + where + ` ORDER BY timestamp DESC`
+	if filter.Limit > 0 {
+		query += ` LIMIT ? OFFSET ?`
+		args = append(args, filter.Limit, filter.Offset)
+	}
+
+	rows, err := r.db.QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	logs := []*domain.AccessLog{}
+	for rows.Next() {
+		log, err := scanAccessLog(rows)
+		if err != nil {
+			return nil, err
+		}
+		logs = append(logs, log)
+	}
+	return logs, rows.Err()
+}
+
+// CountByFilter implements output.AccessLogRepository.
+func (r *AccessLogRepository) CountByFilter(ctx context.Context, filter domain.AccessLogFilter) (int64, error) {
+	where, args := buildAccessLogWhere(filter)
+
+	var count int64
+	err := r.db.QueryRowContext(ctx, `SELECT COUNT(*) FROM access_logs`+where, args...).Scan(&count)
+	return count, err
+}
+
+// DeleteByID implements output.AccessLogRepository.
+func (r *AccessLogRepository) DeleteByID(ctx context.Context, id string) error {
+	_, err := r.db.ExecContext(ctx, `DELETE FROM access_logs WHERE id = ?`, id)
+	return err
+}
+
+// DeleteByFilter implements output.AccessLogRepository.
+func (r *AccessLogRepository) DeleteByFilter(ctx context.Context, filter domain.AccessLogFilter) (int64, error) {
+	where, args := buildAccessLogWhere(filter)
+
+	result, err := r.db.ExecContext(ctx, `DELETE FROM access_logs`+where, args...)
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected()
+}
+
+// DeleteOlderThan implements output.AccessLogRepository.
+func (r *AccessLogRepository) DeleteOlderThan(ctx context.Context, projectID string, before time.Time, limit int) (int64, error) {
+	return deleteOlderThan(ctx, r.db, "access_logs", "timestamp", projectID, before, limit)
+}
+
+// VolumeOlderThan implements output.AccessLogRepository.
+func (r *AccessLogRepository) VolumeOlderThan(ctx context.Context, projectID string, before time.Time) (domain.DataVolume, error) {
+	return volumeOlderThan(ctx, r.db, "access_logs", accessLogColumns, "timestamp", projectID, before)
+}
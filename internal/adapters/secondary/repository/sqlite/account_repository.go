@@ -0,0 +1,190 @@
+package sqlite
+
+import (
+	"context"
+	"database/sql"
+	"time"
+
+	"github.com/spidey52/api-logs/internal/domain"
+	"github.com/spidey52/api-logs/internal/ports/output"
+)
+
+type AccountRepository struct {
+	db *sql.DB
+}
+
+func NewAccountRepository(db *sql.DB) *AccountRepository {
+	return &AccountRepository{db: db}
+}
+
+var _ output.AccountRepository = (*AccountRepository)(nil)
+
+const accountColumns = `id, username, password_hash, is_active, is_admin, created_at, updated_at, last_login_at`
+
+func scanAccount(row rowScanner) (*domain.Account, error) {
+	var account domain.Account
+	var createdAt, updatedAt int64
+	var lastLoginAt sql.NullInt64
+
+	err := row.Scan(
+		&account.ID, &account.Username, &account.PasswordHash, &account.IsActive, &account.IsAdmin,
+		&createdAt, &updatedAt, &lastLoginAt,
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	account.CreatedAt = fromMillis(createdAt)
+	account.UpdatedAt = fromMillis(updatedAt)
+	if lastLoginAt.Valid {
+		t := fromMillis(lastLoginAt.Int64)
+		account.LastLoginAt = &t
+	}
+	return &account, nil
+}
+
+// nullableMillis converts an optional time to a nullable INTEGER column value
+func nullableMillis(t *time.Time) any {
+	if t == nil {
+		return nil
+	}
+	return toMillis(*t)
+}
+
+// Create implements output.AccountRepository.
+func (r *AccountRepository) Create(ctx context.Context, account *domain.Account) error {
+	_, err := r.db.ExecContext(ctx, `
+		INSERT INTO accounts (`+accountColumns+`)
+		VALUES (?, ?, ?, ?, ?, ?, ?, ?)`,
+		account.ID, account.Username, account.PasswordHash, account.IsActive, account.IsAdmin,
+		toMillis(account.CreatedAt), toMillis(account.UpdatedAt), nullableMillis(account.LastLoginAt),
+	)
+	if isUniqueViolation(err) {
+		return domain.ErrDuplicateUsername
+	}
+	return err
+}
+
+// FindByID implements output.AccountRepository.
+func (r *AccountRepository) FindByID(ctx context.Context, id string) (*domain.Account, error) {
+	return r.findOne(ctx, `SELECT `+accountColumns+` FROM accounts WHERE id = ?`, id)
+}
+
+// FindByUsername implements output.AccountRepository.
+func (r *AccountRepository) FindByUsername(ctx context.Context, username string) (*domain.Account, error) {
+	return r.findOne(ctx, `SELECT `+accountColumns+` FROM accounts WHERE username = ?`, username)
+}
+
+func (r *AccountRepository) findOne(ctx context.Context, query string, args ...any) (*domain.Account, error) {
+	account, err := scanAccount(r.db.QueryRowContext(ctx, query, args...))
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, domain.ErrAccountNotFound
+		}
+		return nil, err
+	}
+	return account, nil
+}
+
+// Update implements output.AccountRepository.
+func (r *AccountRepository) Update(ctx context.Context, account *domain.Account) error {
+	result, err := r.db.ExecContext(ctx, `
+		UPDATE accounts SET username = ?, password_hash = ?, is_active = ?, is_admin = ?, updated_at = ?, last_login_at = ?
+		WHERE id = ?`,
+		account.Username, account.PasswordHash, account.IsActive, account.IsAdmin, toMillis(account.UpdatedAt),
+		nullableMillis(account.LastLoginAt), account.ID,
+	)
+	if err != nil {
+		if isUniqueViolation(err) {
+			return domain.ErrDuplicateUsername
+		}
+		return err
+	}
+
+	if affected, _ := result.RowsAffected(); affected == 0 {
+		return domain.ErrAccountNotFound
+	}
+	return nil
+}
+
+// List implements output.AccountRepository.
+func (r *AccountRepository) List(ctx context.Context) ([]*domain.Account, error) {
+	rows, err := r.db.QueryContext(ctx, `SELECT `+accountColumns+` FROM accounts ORDER BY username`)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	accounts := []*domain.Account{}
+	for rows.Next() {
+		account, err := scanAccount(rows)
+		if err != nil {
+			return nil, err
+		}
+		accounts = append(accounts, account)
+	}
+	return accounts, rows.Err()
+}
+
+type SessionRepository struct {
+	db *sql.DB
+}
+
+func NewSessionRepository(db *sql.DB) *SessionRepository {
+	return &SessionRepository{db: db}
+}
+
+var _ output.SessionRepository = (*SessionRepository)(nil)
+
+// Create implements output.SessionRepository.
+func (r *SessionRepository) Create(ctx context.Context, session *domain.Session) error {
+	_, err := r.db.ExecContext(ctx, `
+		INSERT INTO sessions (id, account_id, token_hash, created_at, expires_at)
+		VALUES (?, ?, ?, ?, ?)`,
+		session.ID, session.AccountID, session.TokenHash, toMillis(session.CreatedAt), toMillis(session.ExpiresAt),
+	)
+	return err
+}
+
+// FindByTokenHash implements output.SessionRepository.
+func (r *SessionRepository) FindByTokenHash(ctx context.Context, tokenHash string) (*domain.Session, error) {
+	var session domain.Session
+	var createdAt, expiresAt int64
+
+	err := r.db.QueryRowContext(ctx, `
+		SELECT id, account_id, token_hash, created_at, expires_at
+		FROM sessions WHERE token_hash = ?`, tokenHash).Scan(
+		&session.ID, &session.AccountID, &session.TokenHash, &createdAt, &expiresAt,
+	)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, domain.ErrSessionNotFound
+		}
+		return nil, err
+	}
+
+	session.CreatedAt = fromMillis(createdAt)
+	session.ExpiresAt = fromMillis(expiresAt)
+	return &session, nil
+}
+
+// Delete implements output.SessionRepository.
+func (r *SessionRepository) Delete(ctx context.Context, id string) error {
+	_, err := r.db.ExecContext(ctx, `DELETE FROM sessions WHERE id = ?`, id)
+	return err
+}
+
+// DeleteByAccountID implements output.SessionRepository.
+func (r *SessionRepository) DeleteByAccountID(ctx context.Context, accountID string) error {
+	_, err := r.db.ExecContext(ctx, `DELETE FROM sessions WHERE account_id = ?`, accountID)
+	return err
+}
+
+// DeleteExpired implements output.SessionRepository.
+func (r *SessionRepository) DeleteExpired(ctx context.Context, before time.Time) (int64, error) {
+	result, err := r.db.ExecContext(ctx, `DELETE FROM sessions WHERE expires_at < ?`, toMillis(before))
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected()
+}
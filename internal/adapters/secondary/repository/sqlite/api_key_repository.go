@@ -0,0 +1,151 @@
+package sqlite
+
+import (
+	"context"
+	"database/sql"
+	"time"
+
+	"github.com/spidey52/api-logs/internal/domain"
+	"github.com/spidey52/api-logs/internal/ports/output"
+)
+
+type APIKeyRepository struct {
+	db *sql.DB
+}
+
+func NewAPIKeyRepository(db *sql.DB) *APIKeyRepository {
+	return &APIKeyRepository{db: db}
+}
+
+var _ output.APIKeyRepository = (*APIKeyRepository)(nil)
+
+const apiKeyColumns = `id, project_id, name, key_hash, prefix, scopes, expires_at, last_used_at, revoked_at, created_at`
+
+// nullableTime converts a nullable INTEGER column value to an optional time
+func nullableTime(v sql.NullInt64) *time.Time {
+	if !v.Valid {
+		return nil
+	}
+	t := fromMillis(v.Int64)
+	return &t
+}
+
+func scanAPIKey(row rowScanner) (*domain.APIKey, error) {
+	var key domain.APIKey
+	var scopes string
+	var expiresAt, lastUsedAt, revokedAt sql.NullInt64
+	var createdAt int64
+
+	err := row.Scan(
+		&key.ID, &key.ProjectID, &key.Name, &key.KeyHash, &key.Prefix, &scopes,
+		&expiresAt, &lastUsedAt, &revokedAt, &createdAt,
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	unmarshalJSON(&scopes, &key.Scopes)
+	key.ExpiresAt = nullableTime(expiresAt)
+	key.LastUsedAt = nullableTime(lastUsedAt)
+	key.RevokedAt = nullableTime(revokedAt)
+	key.CreatedAt = fromMillis(createdAt)
+	return &key, nil
+}
+
+// Create implements output.APIKeyRepository.
+func (r *APIKeyRepository) Create(ctx context.Context, key *domain.APIKey) error {
+	_, err := r.db.ExecContext(ctx, `
+		INSERT INTO api_keys (`+apiKeyColumns+`)
+		VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?)`,
+		key.ID, key.ProjectID, key.Name, key.KeyHash, key.Prefix, marshalJSON(key.Scopes),
+		nullableMillis(key.ExpiresAt), nullableMillis(key.LastUsedAt), nullableMillis(key.RevokedAt), toMillis(key.CreatedAt),
+	)
+	if isUniqueViolation(err) {
+		return domain.ErrDuplicateAPIKey
+	}
+	return err
+}
+
+// FindByID implements output.APIKeyRepository.
+func (r *APIKeyRepository) FindByID(ctx context.Context, id string) (*domain.APIKey, error) {
+	return r.findOne(ctx, `SELECT `+apiKeyColumns+` FROM api_keys WHERE id = ?`, id)
+}
+
+// FindByKey implements output.APIKeyRepository.
+func (r *APIKeyRepository) FindByKey(ctx context.Context, keyHash string) (*domain.APIKey, error) {
+	return r.findOne(ctx, `SELECT `+apiKeyColumns+` FROM api_keys WHERE key_hash = ?`, keyHash)
+}
+
+func (r *APIKeyRepository) findOne(ctx context.Context, query string, args ...any) (*domain.APIKey, error) {
+	key, err := scanAPIKey(r.db.QueryRowContext(ctx, query, args...))
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, domain.ErrAPIKeyNotFound
+		}
+		return nil, err
+	}
+	return key, nil
+}
+
+// ListByProject implements output.APIKeyRepository.
+func (r *APIKeyRepository) ListByProject(ctx context.Context, projectID string) ([]*domain.APIKey, error) {
+	rows, err := r.db.QueryContext(ctx, `
+		SELECT `+apiKeyColumns+` FROM api_keys
+		WHERE project_id = ? ORDER BY created_at DESC, id`, projectID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	keys := []*domain.APIKey{}
+	for rows.Next() {
+		key, err := scanAPIKey(rows)
+		if err != nil {
+			return nil, err
+		}
+		keys = append(keys, key)
+	}
+	return keys, rows.Err()
+}
+
+// Revoke implements output.APIKeyRepository.
+func (r *APIKeyRepository) Revoke(ctx context.Context, id string, revokedAt time.Time) error {
+	result, err := r.db.ExecContext(ctx, `UPDATE api_keys SET revoked_at = COALESCE(revoked_at, ?) WHERE id = ?`, toMillis(revokedAt), id)
+	if err != nil {
+		return err
+	}
+
+	if affected, _ := result.RowsAffected(); affected == 0 {
+		return domain.ErrAPIKeyNotFound
+	}
+	return nil
+}
+
+// RevokeExpired implements output.APIKeyRepository.
+func (r *APIKeyRepository) RevokeExpired(ctx context.Context, now time.Time) (int, error) {
+	result, err := r.db.ExecContext(ctx, `
+		UPDATE api_keys SET revoked_at = expires_at
+		WHERE revoked_at IS NULL AND expires_at <= ?`, toMillis(now))
+	if err != nil {
+		return 0, err
+	}
+	affected, err := result.RowsAffected()
+	return int(affected), err
+}
+
+// TouchLastUsed implements output.APIKeyRepository.
+func (r *APIKeyRepository) TouchLastUsed(ctx context.Context, id string, usedAt time.Time) error {
+	_, err := r.db.ExecContext(ctx, `UPDATE api_keys SET last_used_at = ? WHERE id = ?`, toMillis(usedAt), id)
+	return err
+}
+
+// DeleteByProject implements output.APIKeyRepository.
+func (r *APIKeyRepository) DeleteByProject(ctx context.Context, projectID string) error {
+	_, err := r.db.ExecContext(ctx, `DELETE FROM api_keys WHERE project_id = ?`, projectID)
+	return err
+}
+
+// HashPlaintextKeys implements output.APIKeyRepository.
+func (r *APIKeyRepository) HashPlaintextKeys(ctx context.Context, hash func(key string) string) (int, error) {
+	return hashPlaintextKeys(ctx, r.db, "api_keys", "key_hash", "prefix", hash)
+}
@@ -0,0 +1,421 @@
+package sqlite
+
+import (
+	"context"
+	"database/sql"
+	"strings"
+	"time"
+
+	"github.com/spidey52/api-logs/internal/domain"
+	"github.com/spidey52/api-logs/internal/ports/output"
+)
+
+type APILogRepository struct {
+	db *sql.DB
+}
+
+func NewAPILogRepository(db *sql.DB) *APILogRepository {
+	return &APILogRepository{db: db}
+}
+
+var _ output.APILogRepository = (*APILogRepository)(nil)
+
+const apiLogColumns = `id, project_id, environment, method, path, params, query_params, status_code,
+	response_time, content_length, ip_address, user_agent, error_message, user_id, timestamp`
+
+// buildLogWhere translates the filter into a WHERE clause and its arguments
+func buildLogWhere(filter domain.LogFilter) (string, []any) {
+	conditions := []string{}
+	args := []any{}
+
+	if filter.ProjectID != "" {
+		conditions = append(conditions, "project_id = ?")
+		args = append(args, filter.ProjectID)
+	}
+	if filter.Environment != "" {
+		conditions = append(conditions, "environment = ?")
+		args = append(args, string(filter.Environment))
+	}
+	if filter.Method != "" {
+		conditions = append(conditions, "method = ?")
+		args = append(args, string(filter.Method))
+	}
+
+	// Handle status code filtering (exact, min/max range)
+	if filter.StatusCode != nil {
+		conditions = append(conditions, "status_code = ?")
+		args = append(args, *filter.StatusCode)
+	} else {
+		if filter.StatusCodeMin != nil {
+			conditions = append(conditions, "status_code >= ?")
+			args = append(args, *filter.StatusCodeMin)
+		}
+		if filter.StatusCodeMax != nil {
+			conditions = append(conditions, "status_code <= ?")
+			args = append(args, *filter.StatusCodeMax)
+		}
+	}
+
+	// LIKE is case-insensitive for ASCII in SQLite
+	if filter.Path != "" {
+		conditions = append(conditions, "path LIKE ?")
+		args = append(args, "%"+filter.Path+"%")
+	}
+	if filter.Search != "" {
+		conditions = append(conditions, "(path LIKE ? OR user_agent LIKE ? OR ip_address LIKE ?)")
+		pattern := "%" + filter.Search + "%"
+		args = append(args, pattern, pattern, pattern)
+	}
+	if filter.FromDate != nil {
+		conditions = append(conditions, "timestamp >= ?")
+		args = append(args, toMillis(*filter.FromDate))
+	}
+	if filter.ToDate != nil {
+		conditions = append(conditions, "timestamp <= ?")
+		args = append(args, toMillis(*filter.ToDate))
+	}
+	if filter.UserID != "" {
+		conditions = append(conditions, "user_id = ?")
+		args = append(args, filter.UserID)
+	}
+
+	if len(conditions) == 0 {
+		return "", args
+	}
+	return " WHERE " + strings.Join(conditions, " AND "), args
+}
+
+// statsWhere scopes stats queries to a project/environment since the start of today
+func statsWhere(projectID string, environment domain.Environment) (string, []any) {
+	return " WHERE project_id = ? AND environment = ? AND timestamp >= ?",
+		[]any{projectID, string(environment), toMillis(startOfDay(time.Now()))}
+}
+
+type rowScanner interface {
+	Scan(dest ...any) error
+}
+
+func scanAPILog(row rowScanner) (*domain.APILog, error) {
+	var log domain.APILog
+	var envStr, methodStr string
+	var paramsJSON, queryParamsJSON *string
+	var timestamp int64
+
+	err := row.Scan(
+		&log.ID, &log.ProjectID, &envStr, &methodStr, &log.Path, &paramsJSON, &queryParamsJSON, &log.StatusCode,
+		&log.ResponseTime, &log.ContentLength, &log.IPAddress, &log.UserAgent, &log.ErrorMessage, &log.UserID, &timestamp,
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	log.Environment = domain.Environment(envStr)
+	log.Method = domain.HTTPMethod(methodStr)
+	log.Timestamp = fromMillis(timestamp)
+	unmarshalJSON(paramsJSON, &log.Params)
+	unmarshalJSON(queryParamsJSON, &log.QueryParams)
+	return &log, nil
+}
+
+// apiLogArgs returns the values of apiLogColumns for a log
+func apiLogArgs(log *domain.APILog) []any {
+	return []any{
+		log.ID, log.ProjectID, string(log.Environment), string(log.Method), log.Path,
+		marshalJSON(log.Params), marshalJSON(log.QueryParams), log.StatusCode,
+		log.ResponseTime, log.ContentLength, log.IPAddress, log.UserAgent, log.ErrorMessage, log.UserID, toMillis(log.Timestamp),
+	}
+}
+
+// Create implements output.APILogRepository.
+func (r *APILogRepository) Create(ctx context.Context, log *domain.APILog) error {
+	_, err := r.db.ExecContext(ctx, `
+		INSERT INTO api_logs (`+apiLogColumns+`)
+		VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)`, apiLogArgs(log)...)
+	return err
+}
+
+// CreateMany implements output.APILogRepository in a single transaction.
+func (r *APILogRepository) CreateMany(ctx context.Context, logs []*domain.APILog) error {
+	if len(logs) == 0 {
+		return nil
+	}
+
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	stmt, err := tx.PrepareContext(ctx, `
+		INSERT INTO api_logs (`+apiLogColumns+`)
+		VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)
+		ON CONFLICT (id) DO NOTHING`)
+	if err != nil {
+		return err
+	}
+	defer stmt.Close()
+
+	for _, log := range logs {
+		if _, err := stmt.ExecContext(ctx, apiLogArgs(log)...); err != nil {
+			return err
+		}
+	}
+
+	return tx.Commit()
+}
+
+// FindByID implements output.APILogRepository.
+func (r *APILogRepository) FindByID(ctx context.Context, id string) (*domain.APILog, error) {
+	row := r.db.QueryRowContext(ctx, `SELECT `+apiLogColumns+` FROM api_logs WHERE id = ?`, id)
+
+	log, err := scanAPILog(row)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, domain.ErrLogNotFound
+		}
+		return nil, err
+	}
+	return log, nil
+}
+
+// FindByFilter implements output.APILogRepository.
+func (r *APILogRepository) FindByFilter(ctx context.Context, filter domain.LogFilter) ([]*domain.APILog, error) {
+	where, args := buildLogWhere(filter)
+
+	query := `SELECT ` + apiLogColumns + ` FROM api_logs` + where + ` ORDER BY timestamp DESC`
+	if filter.Limit > 0 {
+		query += ` LIMIT ? OFFSET ?`
+		args = append(args, filter.Limit, filter.Offset)
+	}
+
+	rows, err := r.db.QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	logs := []*domain.APILog{}
+	for rows.Next() {
+		log, err := scanAPILog(rows)
+		if err != nil {
+			return nil, err
+		}
+		logs = append(logs, log)
+	}
+	return logs, rows.Err()
+}
+
+// Delete implements output.APILogRepository.
+func (r *APILogRepository) Delete(ctx context.Context, id string) error {
+	result, err := r.db.ExecContext(ctx, `DELETE FROM api_logs WHERE id = ?`, id)
+	if err != nil {
+		return err
+	}
+
+	if affected, _ := result.RowsAffected(); affected == 0 {
+		return domain.ErrLogNotFound
+	}
+	return nil
+}
+
+// DeleteBatch implements output.APILogRepository.
+func (r *APILogRepository) DeleteBatch(ctx context.Context, ids []string) error {
+	if len(ids) == 0 {
+		return nil
+	}
+
+	args := make([]any, len(ids))
+	for i, id := range ids {
+		args[i] = id
+	}
+
+	_, err := r.db.ExecContext(ctx, `DELETE FROM api_logs WHERE id IN (`+placeholders(len(args))+`)`, args...)
+	return err
+}
+
+// CountByProject implements output.APILogRepository.
+func (r *APILogRepository) CountByProject(ctx context.Context, projectID string, environment domain.Environment) (int64, error) {
+	where, args := statsWhere(projectID, environment)
+
+	var count int64
+	err := r.db.QueryRowContext(ctx, `SELECT COUNT(*) FROM api_logs`+where, args...).Scan(&count)
+	return count, err
+}
+
+// CountByFilter implements output.APILogRepository.
+func (r *APILogRepository) CountByFilter(ctx context.Context, filter domain.LogFilter) (int64, error) {
+	where, args := buildLogWhere(filter)
+
+	var count int64
+	err := r.db.QueryRowContext(ctx, `SELECT COUNT(*) FROM api_logs`+where, args...).Scan(&count)
+	return count, err
+}
+
+// GetStatusCodeDistribution implements output.APILogRepository.
+func (r *APILogRepository) GetStatusCodeDistribution(ctx context.Context, projectID string, environment domain.Environment) (map[int]int64, error) {
+	where, args := statsWhere(projectID, environment)
+
+	rows, err := r.db.QueryContext(ctx, `SELECT status_code, COUNT(*) FROM api_logs`+where+` GROUP BY status_code`, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	distribution := make(map[int]int64)
+	for rows.Next() {
+		var status int
+		var count int64
+		if err := rows.Scan(&status, &count); err != nil {
+			return nil, err
+		}
+		distribution[status] = count
+	}
+	return distribution, rows.Err()
+}
+
+// GetAverageResponseTime implements output.APILogRepository.
+func (r *APILogRepository) GetAverageResponseTime(ctx context.Context, projectID string, environment domain.Environment) (float64, error) {
+	where, args := statsWhere(projectID, environment)
+
+	var avg float64
+	err := r.db.QueryRowContext(ctx, `SELECT COALESCE(AVG(response_time), 0) FROM api_logs`+where, args...).Scan(&avg)
+	return avg, err
+}
+
+// GetTimeSeriesStats implements output.APILogRepository.
+func (r *APILogRepository) GetTimeSeriesStats(ctx context.Context, projectID string, environment domain.Environment) ([]map[string]interface{}, error) {
+	where, args := statsWhere(projectID, environment)
+
+	// bucket timestamps to the start of their UTC hour
+	rows, err := r.db.QueryContext(ctx, `
+		SELECT (timestamp / 3600000) * 3600000 AS bucket, COUNT(*) AS count
+		FROM api_logs`+where+`
+		GROUP BY bucket ORDER BY bucket ASC LIMIT 24`, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var stats []map[string]interface{}
+	for rows.Next() {
+		var bucket, count int64
+		if err := rows.Scan(&bucket, &count); err != nil {
+			return nil, err
+		}
+		stats = append(stats, map[string]interface{}{
+			"timestamp": fromMillis(bucket),
+			"count":     count,
+		})
+	}
+	return stats, rows.Err()
+}
+
+// GetTopEndpoints implements output.APILogRepository.
+func (r *APILogRepository) GetTopEndpoints(ctx context.Context, projectID string, environment domain.Environment, limit int) ([]map[string]interface{}, error) {
+	where, args := statsWhere(projectID, environment)
+	args = append(args, limit)
+
+	rows, err := r.db.QueryContext(ctx, `
+		SELECT path, COUNT(*) AS count, MIN(method) AS method, COALESCE(AVG(response_time), 0) AS avg_response_time
+		FROM api_logs`+where+`
+		GROUP BY path ORDER BY count DESC LIMIT ?`, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var endpoints []map[string]interface{}
+	for rows.Next() {
+		var path, method string
+		var count int64
+		var avgResponseTime float64
+		if err := rows.Scan(&path, &count, &method, &avgResponseTime); err != nil {
+			return nil, err
+		}
+		// Keys mirror the MongoDB aggregation output consumed by the dashboard
+		endpoints = append(endpoints, map[string]interface{}{
+			"_id":               path,
+			"count":             count,
+			"method":            method,
+			"avg_response_time": avgResponseTime,
+		})
+	}
+	return endpoints, rows.Err()
+}
+
+// GetMethodDistribution implements output.APILogRepository.
+func (r *APILogRepository) GetMethodDistribution(ctx context.Context, projectID string, environment domain.Environment) (map[string]int64, error) {
+	where, args := statsWhere(projectID, environment)
+
+	rows, err := r.db.QueryContext(ctx, `SELECT method, COUNT(*) FROM api_logs`+where+` GROUP BY method`, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	distribution := make(map[string]int64)
+	for rows.Next() {
+		var method string
+		var count int64
+		if err := rows.Scan(&method, &count); err != nil {
+			return nil, err
+		}
+		distribution[method] = count
+	}
+	return distribution, rows.Err()
+}
+
+// GetUniquePaths implements output.APILogRepository.
+func (r *APILogRepository) GetUniquePaths(ctx context.Context, projectID string, environment domain.Environment) ([]string, error) {
+	where, args := statsWhere(projectID, environment)
+
+	rows, err := r.db.QueryContext(ctx, `SELECT DISTINCT path FROM api_logs`+where+` ORDER BY path`, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	paths := []string{}
+	for rows.Next() {
+		var path string
+		if err := rows.Scan(&path); err != nil {
+			return nil, err
+		}
+		paths = append(paths, path)
+	}
+	return paths, rows.Err()
+}
+
+// FindOlderThan implements output.APILogRepository.
+func (r *APILogRepository) FindOlderThan(ctx context.Context, projectID string, before time.Time, limit int) ([]*domain.APILog, error) {
+	where, args := olderThanWhere("timestamp", projectID, before)
+
+	rows, err := r.db.QueryContext(ctx, `
+		SELECT `+apiLogColumns+` FROM api_logs`+where+` ORDER BY timestamp LIMIT ?`,
+		append(args, limit)...,
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var logs []*domain.APILog
+	for rows.Next() {
+		log, err := scanAPILog(rows)
+		if err != nil {
+			return nil, err
+		}
+		logs = append(logs, log)
+	}
+	return logs, rows.Err()
+}
+
+// DeleteOlderThan implements output.APILogRepository.
+func (r *APILogRepository) DeleteOlderThan(ctx context.Context, projectID string, before time.Time, limit int) (int64, error) {
+	return deleteOlderThan(ctx, r.db, "api_logs", "timestamp", projectID, before, limit)
+}
+
+// VolumeOlderThan implements output.APILogRepository.
+func (r *APILogRepository) VolumeOlderThan(ctx context.Context, projectID string, before time.Time) (domain.DataVolume, error) {
+	return volumeOlderThan(ctx, r.db, "api_logs", apiLogColumns, "timestamp", projectID, before)
+}
@@ -0,0 +1,180 @@
+package sqlite
+
+import (
+	"context"
+	"database/sql"
+	"time"
+
+	"github.com/spidey52/api-logs/internal/domain"
+	"github.com/spidey52/api-logs/internal/ports/output"
+)
+
+type APILogBodyRepository struct {
+	db *sql.DB
+}
+
+func NewAPILogBodyRepository(db *sql.DB) *APILogBodyRepository {
+	return &APILogBodyRepository{db: db}
+}
+
+var _ output.APILogBodyRepository = (*APILogBodyRepository)(nil)
+
+// Create implements output.APILogBodyRepository.
+func (r *APILogBodyRepository) Create(ctx context.Context, body *domain.APILogBody) error {
+	_, err := r.db.ExecContext(ctx, `
+		INSERT INTO apilog_bodies (id, log_id, project_id, request_body, response_body, created_at)
+		VALUES (?, ?, ?, ?, ?, ?)`,
+		body.ID, body.LogID, body.ProjectID, marshalJSON(body.RequestBody), marshalJSON(body.ResponseBody), toMillis(body.CreatedAt),
+	)
+	return err
+}
+
+// CreateMany implements output.APILogBodyRepository in a single transaction.
+func (r *APILogBodyRepository) CreateMany(ctx context.Context, bodies []*domain.APILogBody) error {
+	if len(bodies) == 0 {
+		return nil
+	}
+
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	stmt, err := tx.PrepareContext(ctx, `
+		INSERT INTO apilog_bodies (id, log_id, project_id, request_body, response_body, created_at)
+		VALUES (?, ?, ?, ?, ?, ?)
+		ON CONFLICT (log_id) DO NOTHING`)
+	if err != nil {
+		return err
+	}
+	defer stmt.Close()
+
+	for _, body := range bodies {
+		if _, err := stmt.ExecContext(ctx, body.ID, body.LogID, body.ProjectID, marshalJSON(body.RequestBody), marshalJSON(body.ResponseBody), toMillis(body.CreatedAt)); err != nil {
+			return err
+		}
+	}
+
+	return tx.Commit()
+}
+
+// FindByLogID implements output.APILogBodyRepository.
+func (r *APILogBodyRepository) FindByLogID(ctx context.Context, logID string) (*domain.APILogBody, error) {
+	var body domain.APILogBody
+	var requestBodyJSON, responseBodyJSON *string
+	var createdAt int64
+
+	err := r.db.QueryRowContext(ctx, `
+		SELECT id, log_id, request_body, response_body, created_at
+		FROM apilog_bodies WHERE log_id = ?`, logID).Scan(
+		&body.ID, &body.LogID, &requestBodyJSON, &responseBodyJSON, &createdAt,
+	)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, domain.ErrBodyNotFound
+		}
+		return nil, err
+	}
+
+	body.CreatedAt = fromMillis(createdAt)
+	unmarshalJSON(requestBodyJSON, &body.RequestBody)
+	unmarshalJSON(responseBodyJSON, &body.ResponseBody)
+	return &body, nil
+}
+
+// FindByLogIDs implements output.APILogBodyRepository.
+func (r *APILogBodyRepository) FindByLogIDs(ctx context.Context, logIDs []string) ([]*domain.APILogBody, error) {
+	if len(logIDs) == 0 {
+		return nil, nil
+	}
+
+	args := make([]any, len(logIDs))
+	for i, id := range logIDs {
+		args[i] = id
+	}
+
+	rows, err := r.db.QueryContext(ctx, `
+		SELECT id, log_id, request_body, response_body, created_at
+		FROM apilog_bodies WHERE log_id IN (`+placeholders(len(args))+`)`, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var found []*domain.APILogBody
+	for rows.Next() {
+		var body domain.APILogBody
+		var requestBodyJSON, responseBodyJSON *string
+		var createdAt int64
+		if err := rows.Scan(&body.ID, &body.LogID, &requestBodyJSON, &responseBodyJSON, &createdAt); err != nil {
+			return nil, err
+		}
+		body.CreatedAt = fromMillis(createdAt)
+		unmarshalJSON(requestBodyJSON, &body.RequestBody)
+		unmarshalJSON(responseBodyJSON, &body.ResponseBody)
+		found = append(found, &body)
+	}
+	return found, rows.Err()
+}
+
+// Delete implements output.APILogBodyRepository.
+func (r *APILogBodyRepository) Delete(ctx context.Context, logID string) error {
+	_, err := r.db.ExecContext(ctx, `DELETE FROM apilog_bodies WHERE log_id = ?`, logID)
+	return err
+}
+
+// DeleteBatch implements output.APILogBodyRepository.
+func (r *APILogBodyRepository) DeleteBatch(ctx context.Context, logIDs []string) error {
+	if len(logIDs) == 0 {
+		return nil
+	}
+
+	args := make([]any, len(logIDs))
+	for i, id := range logIDs {
+		args[i] = id
+	}
+
+	_, err := r.db.ExecContext(ctx, `DELETE FROM apilog_bodies WHERE log_id IN (`+placeholders(len(args))+`)`, args...)
+	return err
+}
+
+// FindOlderThan implements output.APILogBodyRepository.
+func (r *APILogBodyRepository) FindOlderThan(ctx context.Context, projectID string, before time.Time, limit int) ([]*domain.APILogBody, error) {
+	where, args := olderThanWhere("created_at", projectID, before)
+
+	rows, err := r.db.QueryContext(ctx, `
+		SELECT id, log_id, COALESCE(project_id, ''), request_body, response_body, created_at
+		FROM apilog_bodies`+where+` ORDER BY created_at LIMIT ?`,
+		append(args, limit)...,
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var found []*domain.APILogBody
+	for rows.Next() {
+		var body domain.APILogBody
+		var requestBodyJSON, responseBodyJSON *string
+		var createdAt int64
+		if err := rows.Scan(&body.ID, &body.LogID, &body.ProjectID, &requestBodyJSON, &responseBodyJSON, &createdAt); err != nil {
+			return nil, err
+		}
+		body.CreatedAt = fromMillis(createdAt)
+		unmarshalJSON(requestBodyJSON, &body.RequestBody)
+		unmarshalJSON(responseBodyJSON, &body.ResponseBody)
+		found = append(found, &body)
+	}
+	return found, rows.Err()
+}
+
+// DeleteOlderThan implements output.APILogBodyRepository.
+func (r *APILogBodyRepository) DeleteOlderThan(ctx context.Context, projectID string, before time.Time, limit int) (int64, error) {
+	return deleteOlderThan(ctx, r.db, "apilog_bodies", "created_at", projectID, before, limit)
+}
+
+// VolumeOlderThan implements output.APILogBodyRepository.
+func (r *APILogBodyRepository) VolumeOlderThan(ctx context.Context, projectID string, before time.Time) (domain.DataVolume, error) {
+	return volumeOlderThan(ctx, r.db, "apilog_bodies", "id, log_id, project_id, request_body, response_body, created_at", "created_at", projectID, before)
+}
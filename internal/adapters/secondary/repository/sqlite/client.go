@@ -0,0 +1,41 @@
+package sqlite
+
+import (
+	"context"
+	"database/sql"
+	"strings"
+	"time"
+
+	// pure-Go SQLite driver, registers itself as "sqlite"
+	_ "modernc.org/sqlite"
+)
+
+// Open opens (or creates) the SQLite database at path and verifies connectivity.
+// Use ":memory:" for a throwaway in-process database.
+func Open(ctx context.Context, path string) (*sql.DB, error) {
+	dsn := "file:" + path + "?_pragma=busy_timeout(5000)&_pragma=journal_mode(WAL)&_pragma=synchronous(NORMAL)"
+	if path == ":memory:" || strings.Contains(path, "mode=memory") {
+		dsn = "file:" + path + "?_pragma=busy_timeout(5000)"
+	}
+
+	db, err := sql.Open("sqlite", dsn)
+	if err != nil {
+		return nil, err
+	}
+
+	if path == ":memory:" {
+		// every connection would otherwise get its own empty database
+		db.SetMaxOpenConns(1)
+	}
+
+	// Ping the database
+	ctx, cancel := context.WithTimeout(ctx, 10*time.Second)
+	defer cancel()
+
+	if err := db.PingContext(ctx); err != nil {
+		db.Close()
+		return nil, err
+	}
+
+	return db, nil
+}
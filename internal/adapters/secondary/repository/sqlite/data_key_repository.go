@@ -0,0 +1,106 @@
+package sqlite
+
+import (
+	"context"
+	"database/sql"
+
+	"github.com/spidey52/api-logs/internal/domain"
+	"github.com/spidey52/api-logs/internal/ports/output"
+)
+
+type DataKeyRepository struct {
+	db *sql.DB
+}
+
+func NewDataKeyRepository(db *sql.DB) *DataKeyRepository {
+	return &DataKeyRepository{db: db}
+}
+
+var _ output.DataKeyRepository = (*DataKeyRepository)(nil)
+
+const dataKeyColumns = `project_id, master_key_id, wrapped_key, created_at, updated_at`
+
+func scanDataKey(row rowScanner) (*domain.DataKey, error) {
+	var key domain.DataKey
+	var createdAt, updatedAt int64
+	if err := row.Scan(&key.ProjectID, &key.MasterKeyID, &key.WrappedKey, &createdAt, &updatedAt); err != nil {
+		return nil, err
+	}
+	key.CreatedAt = fromMillis(createdAt)
+	key.UpdatedAt = fromMillis(updatedAt)
+	return &key, nil
+}
+
+// Find implements output.DataKeyRepository.
+func (r *DataKeyRepository) Find(ctx context.Context, projectID string) (*domain.DataKey, error) {
+	key, err := scanDataKey(r.db.QueryRowContext(ctx, `SELECT `+dataKeyColumns+` FROM data_keys WHERE project_id = ?`, projectID))
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, domain.ErrDataKeyNotFound
+		}
+		return nil, err
+	}
+	return key, nil
+}
+
+// Create implements output.DataKeyRepository.
+func (r *DataKeyRepository) Create(ctx context.Context, key *domain.DataKey) error {
+	_, err := r.db.ExecContext(ctx, `
+		INSERT INTO data_keys (`+dataKeyColumns+`)
+		VALUES (?, ?, ?, ?, ?)`,
+		key.ProjectID, key.MasterKeyID, key.WrappedKey, toMillis(key.CreatedAt), toMillis(key.UpdatedAt),
+	)
+	if isUniqueViolation(err) {
+		return domain.ErrDuplicateDataKey
+	}
+	return err
+}
+
+// Rewrap implements output.DataKeyRepository.
+func (r *DataKeyRepository) Rewrap(ctx context.Context, key *domain.DataKey) error {
+	result, err := r.db.ExecContext(ctx, `
+		UPDATE data_keys SET master_key_id = ?, wrapped_key = ?, updated_at = ?
+		WHERE project_id = ?`,
+		key.MasterKeyID, key.WrappedKey, toMillis(key.UpdatedAt), key.ProjectID,
+	)
+	if err != nil {
+		return err
+	}
+
+	if affected, _ := result.RowsAffected(); affected == 0 {
+		return domain.ErrDataKeyNotFound
+	}
+	return nil
+}
+
+// Delete implements output.DataKeyRepository.
+func (r *DataKeyRepository) Delete(ctx context.Context, projectID string) error {
+	result, err := r.db.ExecContext(ctx, `DELETE FROM data_keys WHERE project_id = ?`, projectID)
+	if err != nil {
+		return err
+	}
+
+	if affected, _ := result.RowsAffected(); affected == 0 {
+		return domain.ErrDataKeyNotFound
+	}
+	return nil
+}
+
+// List implements output.DataKeyRepository.
+func (r *DataKeyRepository) List(ctx context.Context) ([]*domain.DataKey, error) {
+	rows, err := r.db.QueryContext(ctx, `SELECT `+dataKeyColumns+` FROM data_keys ORDER BY project_id`)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	keys := []*domain.DataKey{}
+	for rows.Next() {
+		key, err := scanDataKey(rows)
+		if err != nil {
+			return nil, err
+		}
+		keys = append(keys, key)
+	}
+	return keys, rows.Err()
+}
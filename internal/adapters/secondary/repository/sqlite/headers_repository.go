@@ -0,0 +1,180 @@
+package sqlite
+
+import (
+	"context"
+	"database/sql"
+	"time"
+
+	"github.com/spidey52/api-logs/internal/domain"
+	"github.com/spidey52/api-logs/internal/ports/output"
+)
+
+type APILogHeadersRepository struct {
+	db *sql.DB
+}
+
+func NewAPILogHeadersRepository(db *sql.DB) *APILogHeadersRepository {
+	return &APILogHeadersRepository{db: db}
+}
+
+var _ output.APILogHeadersRepository = (*APILogHeadersRepository)(nil)
+
+// Create implements output.APILogHeadersRepository.
+func (r *APILogHeadersRepository) Create(ctx context.Context, headers *domain.APILogHeaders) error {
+	_, err := r.db.ExecContext(ctx, `
+		INSERT INTO apilog_headers (id, log_id, project_id, request_headers, response_headers, created_at)
+		VALUES (?, ?, ?, ?, ?, ?)`,
+		headers.ID, headers.LogID, headers.ProjectID, marshalJSON(headers.RequestHeaders), marshalJSON(headers.ResponseHeaders), toMillis(headers.CreatedAt),
+	)
+	return err
+}
+
+// CreateMany implements output.APILogHeadersRepository in a single transaction.
+func (r *APILogHeadersRepository) CreateMany(ctx context.Context, headers []*domain.APILogHeaders) error {
+	if len(headers) == 0 {
+		return nil
+	}
+
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	stmt, err := tx.PrepareContext(ctx, `
+		INSERT INTO apilog_headers (id, log_id, project_id, request_headers, response_headers, created_at)
+		VALUES (?, ?, ?, ?, ?, ?)
+		ON CONFLICT (log_id) DO NOTHING`)
+	if err != nil {
+		return err
+	}
+	defer stmt.Close()
+
+	for _, h := range headers {
+		if _, err := stmt.ExecContext(ctx, h.ID, h.LogID, h.ProjectID, marshalJSON(h.RequestHeaders), marshalJSON(h.ResponseHeaders), toMillis(h.CreatedAt)); err != nil {
+			return err
+		}
+	}
+
+	return tx.Commit()
+}
+
+// FindByLogID implements output.APILogHeadersRepository.
+func (r *APILogHeadersRepository) FindByLogID(ctx context.Context, logID string) (*domain.APILogHeaders, error) {
+	var headers domain.APILogHeaders
+	var requestHeadersJSON, responseHeadersJSON *string
+	var createdAt int64
+
+	err := r.db.QueryRowContext(ctx, `
+		SELECT id, log_id, request_headers, response_headers, created_at
+		FROM apilog_headers WHERE log_id = ?`, logID).Scan(
+		&headers.ID, &headers.LogID, &requestHeadersJSON, &responseHeadersJSON, &createdAt,
+	)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, domain.ErrHeadersNotFound
+		}
+		return nil, err
+	}
+
+	headers.CreatedAt = fromMillis(createdAt)
+	unmarshalJSON(requestHeadersJSON, &headers.RequestHeaders)
+	unmarshalJSON(responseHeadersJSON, &headers.ResponseHeaders)
+	return &headers, nil
+}
+
+// FindByLogIDs implements output.APILogHeadersRepository.
+func (r *APILogHeadersRepository) FindByLogIDs(ctx context.Context, logIDs []string) ([]*domain.APILogHeaders, error) {
+	if len(logIDs) == 0 {
+		return nil, nil
+	}
+
+	args := make([]any, len(logIDs))
+	for i, id := range logIDs {
+		args[i] = id
+	}
+
+	rows, err := r.db.QueryContext(ctx, `
+		SELECT id, log_id, request_headers, response_headers, created_at
+		FROM apilog_headers WHERE log_id IN (`+placeholders(len(args))+`)`, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var found []*domain.APILogHeaders
+	for rows.Next() {
+		var headers domain.APILogHeaders
+		var requestHeadersJSON, responseHeadersJSON *string
+		var createdAt int64
+		if err := rows.Scan(&headers.ID, &headers.LogID, &requestHeadersJSON, &responseHeadersJSON, &createdAt); err != nil {
+			return nil, err
+		}
+		headers.CreatedAt = fromMillis(createdAt)
+		unmarshalJSON(requestHeadersJSON, &headers.RequestHeaders)
+		unmarshalJSON(responseHeadersJSON, &headers.ResponseHeaders)
+		found = append(found, &headers)
+	}
+	return found, rows.Err()
+}
+
+// Delete implements output.APILogHeadersRepository.
+func (r *APILogHeadersRepository) Delete(ctx context.Context, logID string) error {
+	_, err := r.db.ExecContext(ctx, `DELETE FROM apilog_headers WHERE log_id = ?`, logID)
+	return err
+}
+
+// DeleteBatch implements output.APILogHeadersRepository.
+func (r *APILogHeadersRepository) DeleteBatch(ctx context.Context, logIDs []string) error {
+	if len(logIDs) == 0 {
+		return nil
+	}
+
+	args := make([]any, len(logIDs))
+	for i, id := range logIDs {
+		args[i] = id
+	}
+
+	_, err := r.db.ExecContext(ctx, `DELETE FROM apilog_headers WHERE log_id IN (`+placeholders(len(args))+`)`, args...)
+	return err
+}
+
+// FindOlderThan implements output.APILogHeadersRepository.
+func (r *APILogHeadersRepository) FindOlderThan(ctx context.Context, projectID string, before time.Time, limit int) ([]*domain.APILogHeaders, error) {
+	where, args := olderThanWhere("created_at", projectID, before)
+
+	rows, err := r.db.QueryContext(ctx, `
+		SELECT id, log_id, COALESCE(project_id, ''), request_headers, response_headers, created_at
+		FROM apilog_headers`+where+` ORDER BY created_at LIMIT ?`,
+		append(args, limit)...,
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var found []*domain.APILogHeaders
+	for rows.Next() {
+		var headers domain.APILogHeaders
+		var requestHeadersJSON, responseHeadersJSON *string
+		var createdAt int64
+		if err := rows.Scan(&headers.ID, &headers.LogID, &headers.ProjectID, &requestHeadersJSON, &responseHeadersJSON, &createdAt); err != nil {
+			return nil, err
+		}
+		headers.CreatedAt = fromMillis(createdAt)
+		unmarshalJSON(requestHeadersJSON, &headers.RequestHeaders)
+		unmarshalJSON(responseHeadersJSON, &headers.ResponseHeaders)
+		found = append(found, &headers)
+	}
+	return found, rows.Err()
+}
+
+// DeleteOlderThan implements output.APILogHeadersRepository.
+func (r *APILogHeadersRepository) DeleteOlderThan(ctx context.Context, projectID string, before time.Time, limit int) (int64, error) {
+	return deleteOlderThan(ctx, r.db, "apilog_headers", "created_at", projectID, before, limit)
+}
+
+// VolumeOlderThan implements output.APILogHeadersRepository.
+func (r *APILogHeadersRepository) VolumeOlderThan(ctx context.Context, projectID string, before time.Time) (domain.DataVolume, error) {
+	return volumeOlderThan(ctx, r.db, "apilog_headers", "id, log_id, project_id, request_headers, response_headers, created_at", "created_at", projectID, before)
+}
@@ -0,0 +1,144 @@
+package sqlite
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"strings"
+	"time"
+
+	"github.com/spidey52/api-logs/internal/domain"
+)
+
+// Timestamps are stored as INTEGER unix milliseconds (UTC), the same precision
+// MongoDB uses for dates, so range filters and ordering stay index friendly.
+
+func toMillis(t time.Time) int64 {
+	return t.UnixMilli()
+}
+
+func fromMillis(ms int64) time.Time {
+	return time.UnixMilli(ms).UTC()
+}
+
+func nowUTC() time.Time {
+	return time.Now().UTC()
+}
+
+// startOfDay returns local midnight for t, matching the MongoDB stats window
+func startOfDay(t time.Time) time.Time {
+	year, month, day := t.Date()
+	return time.Date(year, month, day, 0, 0, 0, 0, t.Location())
+}
+
+// marshalJSON encodes v as a TEXT column value; nil maps are stored as NULL
+func marshalJSON(v any) any {
+	if v == nil {
+		return nil
+	}
+	data, err := json.Marshal(v)
+	if err != nil || string(data) == "null" {
+		return nil
+	}
+	return string(data)
+}
+
+// unmarshalJSON decodes a nullable TEXT column into dest
+func unmarshalJSON(data *string, dest any) {
+	if data == nil || *data == "" {
+		return
+	}
+	json.Unmarshal([]byte(*data), dest)
+}
+
+// placeholders returns "?, ?, ?" with n placeholders
+func placeholders(n int) string {
+	if n <= 0 {
+		return ""
+	}
+	return strings.TrimSuffix(strings.Repeat("?, ", n), ", ")
+}
+
+// isUniqueViolation reports whether err is a UNIQUE constraint failure
+func isUniqueViolation(err error) bool {
+	return err != nil && strings.Contains(err.Error(), "UNIQUE constraint failed")
+}
+
+// hashPlaintextKeys replaces the plaintext keys left in table by the 0005
+// migration (rows whose prefix column is still empty) with their hash
+func hashPlaintextKeys(ctx context.Context, db *sql.DB, table, hashColumn, prefixColumn string, hash func(string) string) (int, error) {
+	rows, err := db.QueryContext(ctx, `SELECT id, `+hashColumn+` FROM `+table+` WHERE `+prefixColumn+` = ''`)
+	if err != nil {
+		return 0, err
+	}
+
+	plaintext := map[string]string{}
+	for rows.Next() {
+		var id, key string
+		if err := rows.Scan(&id, &key); err != nil {
+			rows.Close()
+			return 0, err
+		}
+		plaintext[id] = key
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		return 0, err
+	}
+
+	converted := 0
+	for id, key := range plaintext {
+		result, err := db.ExecContext(ctx, `
+			UPDATE `+table+` SET `+hashColumn+` = ?, `+prefixColumn+` = ?
+			WHERE id = ? AND `+prefixColumn+` = ''`,
+			hash(key), domain.KeyPrefix(key), id,
+		)
+		if err != nil {
+			return converted, err
+		}
+		affected, _ := result.RowsAffected()
+		converted += int(affected)
+	}
+	return converted, nil
+}
+
+// olderThanWhere selects the rows of a project stored before a time. Headers
+// and bodies written before their project was recorded have a NULL project,
+// and are selected by an empty project ID.
+func olderThanWhere(timeColumn, projectID string, before time.Time) (string, []any) {
+	if projectID == "" {
+		return ` WHERE (project_id IS NULL OR project_id = '') AND ` + timeColumn + ` < ?`, []any{toMillis(before)}
+	}
+	return ` WHERE project_id = ? AND ` + timeColumn + ` < ?`, []any{projectID, toMillis(before)}
+}
+
+// deleteOlderThan removes at most limit of the rows olderThanWhere selects
+func deleteOlderThan(ctx context.Context, db *sql.DB, table, timeColumn, projectID string, before time.Time, limit int) (int64, error) {
+	where, args := olderThanWhere(timeColumn, projectID, before)
+
+	result, err := db.ExecContext(ctx, `
+		DELETE FROM `+table+` WHERE id IN (SELECT id FROM `+table+where+` LIMIT ?)`,
+		append(args, limit)...,
+	)
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected()
+}
+
+// volumeOlderThan measures the rows olderThanWhere selects, counting the
+// stored bytes of the given comma separated columns
+func volumeOlderThan(ctx context.Context, db *sql.DB, table, columns, timeColumn, projectID string, before time.Time) (domain.DataVolume, error) {
+	where, args := olderThanWhere(timeColumn, projectID, before)
+
+	var sizes []string
+	for _, column := range strings.Split(columns, ",") {
+		sizes = append(sizes, `COALESCE(length(CAST(`+strings.TrimSpace(column)+` AS BLOB)), 0)`)
+	}
+
+	var volume domain.DataVolume
+	err := db.QueryRowContext(ctx, `
+		SELECT COUNT(*), COALESCE(SUM(`+strings.Join(sizes, " + ")+`), 0) FROM `+table+where, args...,
+	).Scan(&volume.Documents, &volume.Bytes)
+	return volume, err
+}
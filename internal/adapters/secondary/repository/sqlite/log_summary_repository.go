@@ -0,0 +1,111 @@
+package sqlite
+
+import (
+	"context"
+	"database/sql"
+	"time"
+
+	"github.com/spidey52/api-logs/internal/domain"
+	"github.com/spidey52/api-logs/internal/ports/output"
+)
+
+type LogSummaryRepository struct {
+	db *sql.DB
+}
+
+func NewLogSummaryRepository(db *sql.DB) *LogSummaryRepository {
+	return &LogSummaryRepository{db: db}
+}
+
+var _ output.LogSummaryRepository = (*LogSummaryRepository)(nil)
+
+const summaryColumns = `project_id, granularity, bucket, environment, method, route, count, error_count,
+	status_1xx, status_2xx, status_3xx, status_4xx, status_5xx, status_codes,
+	latency_sum, latency_min, latency_max, latency_histogram`
+
+// Replace implements output.LogSummaryRepository in a single transaction.
+func (r *LogSummaryRepository) Replace(ctx context.Context, projectID string, granularity domain.SummaryGranularity, from, to time.Time, summaries []*domain.LogSummary) error {
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	if _, err := tx.ExecContext(ctx, `
+		DELETE FROM api_log_summaries
+		WHERE project_id = ? AND granularity = ? AND bucket >= ? AND bucket < ?`,
+		projectID, string(granularity), toMillis(from), toMillis(to),
+	); err != nil {
+		return err
+	}
+
+	stmt, err := tx.PrepareContext(ctx, `
+		INSERT OR REPLACE INTO api_log_summaries (`+summaryColumns+`)
+		VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)`)
+	if err != nil {
+		return err
+	}
+	defer stmt.Close()
+
+	for _, s := range summaries {
+		if _, err := stmt.ExecContext(ctx,
+			s.ProjectID, string(s.Granularity), toMillis(s.Bucket), string(s.Environment), string(s.Method), s.Route, s.Count, s.ErrorCount,
+			s.Status1xx, s.Status2xx, s.Status3xx, s.Status4xx, s.Status5xx, marshalJSON(s.StatusCodes),
+			s.LatencySum, s.LatencyMin, s.LatencyMax, marshalJSON(s.Histogram),
+		); err != nil {
+			return err
+		}
+	}
+
+	return tx.Commit()
+}
+
+// Find implements output.LogSummaryRepository.
+func (r *LogSummaryRepository) Find(ctx context.Context, filter domain.SummaryFilter) ([]*domain.LogSummary, error) {
+	query := `SELECT ` + summaryColumns + ` FROM api_log_summaries
+		WHERE project_id = ? AND granularity = ? AND bucket >= ? AND bucket < ?`
+	args := []any{filter.ProjectID, string(filter.Granularity), toMillis(filter.From), toMillis(filter.To)}
+	if filter.Environment != "" {
+		query += ` AND environment = ?`
+		args = append(args, string(filter.Environment))
+	}
+	query += ` ORDER BY bucket, environment, method, route`
+
+	rows, err := r.db.QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var summaries []*domain.LogSummary
+	for rows.Next() {
+		var s domain.LogSummary
+		var granularity, environment, method string
+		var bucket int64
+		var statusCodes, histogram *string
+		if err := rows.Scan(
+			&s.ProjectID, &granularity, &bucket, &environment, &method, &s.Route, &s.Count, &s.ErrorCount,
+			&s.Status1xx, &s.Status2xx, &s.Status3xx, &s.Status4xx, &s.Status5xx, &statusCodes,
+			&s.LatencySum, &s.LatencyMin, &s.LatencyMax, &histogram,
+		); err != nil {
+			return nil, err
+		}
+		s.Granularity = domain.SummaryGranularity(granularity)
+		s.Bucket = fromMillis(bucket)
+		s.Environment = domain.Environment(environment)
+		s.Method = domain.HTTPMethod(method)
+		unmarshalJSON(statusCodes, &s.StatusCodes)
+		unmarshalJSON(histogram, &s.Histogram)
+		summaries = append(summaries, &s)
+	}
+	return summaries, rows.Err()
+}
+
+// DeleteOlderThan implements output.LogSummaryRepository.
+func (r *LogSummaryRepository) DeleteOlderThan(ctx context.Context, granularity domain.SummaryGranularity, before time.Time) (int64, error) {
+	result, err := r.db.ExecContext(ctx, `DELETE FROM api_log_summaries WHERE granularity = ? AND bucket < ?`, string(granularity), toMillis(before))
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected()
+}
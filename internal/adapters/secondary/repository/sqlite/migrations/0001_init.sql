@@ -0,0 +1,81 @@
+-- Initial schema for the SQLite storage backend.
+-- Timestamps are INTEGER unix milliseconds, JSON columns are TEXT.
+
+CREATE TABLE IF NOT EXISTS projects (
+	id          TEXT PRIMARY KEY,
+	name        TEXT NOT NULL,
+	description TEXT NOT NULL DEFAULT '',
+	api_key     TEXT NOT NULL UNIQUE,
+	environment TEXT NOT NULL,
+	is_active   INTEGER NOT NULL DEFAULT 1,
+	created_at  INTEGER NOT NULL,
+	updated_at  INTEGER NOT NULL
+);
+
+CREATE INDEX IF NOT EXISTS idx_projects_created_at ON projects (created_at DESC);
+
+CREATE TABLE IF NOT EXISTS users (
+	id         TEXT PRIMARY KEY,
+	name       TEXT NOT NULL,
+	identifier TEXT NOT NULL,
+	metadata   TEXT,
+	created_at INTEGER NOT NULL,
+	project_id TEXT NOT NULL DEFAULT '',
+	UNIQUE (project_id, identifier)
+);
+
+CREATE INDEX IF NOT EXISTS idx_users_created_at ON users (created_at DESC);
+
+CREATE TABLE IF NOT EXISTS api_logs (
+	id             TEXT PRIMARY KEY,
+	project_id     TEXT NOT NULL,
+	environment    TEXT NOT NULL,
+	method         TEXT NOT NULL,
+	path           TEXT NOT NULL,
+	params         TEXT,
+	query_params   TEXT,
+	status_code    INTEGER NOT NULL,
+	response_time  INTEGER NOT NULL DEFAULT 0,
+	content_length INTEGER NOT NULL DEFAULT 0,
+	ip_address     TEXT NOT NULL DEFAULT '',
+	user_agent     TEXT NOT NULL DEFAULT '',
+	error_message  TEXT NOT NULL DEFAULT '',
+	user_id        TEXT,
+	timestamp      INTEGER NOT NULL
+);
+
+CREATE INDEX IF NOT EXISTS idx_api_logs_project_env_ts ON api_logs (project_id, environment, timestamp DESC);
+CREATE INDEX IF NOT EXISTS idx_api_logs_user_id ON api_logs (user_id);
+CREATE INDEX IF NOT EXISTS idx_api_logs_timestamp ON api_logs (timestamp);
+
+CREATE TABLE IF NOT EXISTS apilog_headers (
+	id               TEXT PRIMARY KEY,
+	log_id           TEXT NOT NULL UNIQUE,
+	request_headers  TEXT,
+	response_headers TEXT,
+	created_at       INTEGER NOT NULL
+);
+
+CREATE TABLE IF NOT EXISTS apilog_bodies (
+	id            TEXT PRIMARY KEY,
+	log_id        TEXT NOT NULL UNIQUE,
+	request_body  TEXT,
+	response_body TEXT,
+	created_at    INTEGER NOT NULL
+);
+
+CREATE TABLE IF NOT EXISTS access_logs (
+	id            TEXT PRIMARY KEY,
+	project_id    TEXT NOT NULL,
+	timestamp     INTEGER NOT NULL,
+	actor_id      TEXT NOT NULL,
+	actor_type    TEXT NOT NULL,
+	action        TEXT NOT NULL,
+	resource_type TEXT NOT NULL DEFAULT '',
+	resource_id   TEXT NOT NULL DEFAULT '',
+	outcome       TEXT NOT NULL,
+	message       TEXT NOT NULL DEFAULT '',
+	metadata      TEXT NOT NULL DEFAULT '{}'
+);
+
+CREATE INDEX IF NOT EXISTS idx_access_logs_project_actor_ts ON access_logs (project_id, actor_id, timestamp DESC);
@@ -0,0 +1,15 @@
+-- Named, scoped API keys per project
+
+CREATE TABLE IF NOT EXISTS api_keys (
+	id           TEXT PRIMARY KEY,
+	project_id   TEXT NOT NULL,
+	name         TEXT NOT NULL,
+	key          TEXT NOT NULL UNIQUE,
+	scopes       TEXT NOT NULL,
+	expires_at   INTEGER,
+	last_used_at INTEGER,
+	revoked_at   INTEGER,
+	created_at   INTEGER NOT NULL
+);
+
+CREATE INDEX IF NOT EXISTS idx_api_keys_project_id ON api_keys (project_id, created_at DESC);
@@ -0,0 +1,9 @@
+-- API keys are stored as keyed hashes with a visible prefix. Rows with an
+-- empty prefix still hold the plaintext key until the server hashes them on
+-- startup (HashPlaintextAPIKeys / HashPlaintextKeys).
+
+ALTER TABLE projects RENAME COLUMN api_key TO api_key_hash;
+ALTER TABLE projects ADD COLUMN api_key_prefix TEXT NOT NULL DEFAULT '';
+
+ALTER TABLE api_keys RENAME COLUMN key TO key_hash;
+ALTER TABLE api_keys ADD COLUMN prefix TEXT NOT NULL DEFAULT '';
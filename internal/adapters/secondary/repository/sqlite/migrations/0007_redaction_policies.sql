@@ -0,0 +1,12 @@
+-- Per-project redaction policies; projects without a row use the configured default.
+-- Each list is a JSON array of strings.
+
+CREATE TABLE IF NOT EXISTS redaction_policies (
+	project_id   TEXT PRIMARY KEY,
+	headers      TEXT,
+	query_params TEXT,
+	json_paths   TEXT,
+	patterns     TEXT,
+	regexes      TEXT,
+	updated_at   INTEGER NOT NULL
+);
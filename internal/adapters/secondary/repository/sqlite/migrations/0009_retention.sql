@@ -0,0 +1,19 @@
+-- Per-project retention, enforced by the retention worker. Zero days take the
+-- configured default. Headers and bodies record their log's project, so they
+-- can be purged without a join.
+
+ALTER TABLE projects ADD COLUMN retention_logs_days INTEGER NOT NULL DEFAULT 0;
+ALTER TABLE projects ADD COLUMN retention_headers_days INTEGER NOT NULL DEFAULT 0;
+ALTER TABLE projects ADD COLUMN retention_bodies_days INTEGER NOT NULL DEFAULT 0;
+ALTER TABLE projects ADD COLUMN retention_access_logs_days INTEGER NOT NULL DEFAULT 0;
+
+ALTER TABLE apilog_headers ADD COLUMN project_id TEXT;
+ALTER TABLE apilog_bodies ADD COLUMN project_id TEXT;
+
+UPDATE apilog_headers SET project_id = (SELECT project_id FROM api_logs WHERE api_logs.id = apilog_headers.log_id);
+UPDATE apilog_bodies SET project_id = (SELECT project_id FROM api_logs WHERE api_logs.id = apilog_bodies.log_id);
+
+CREATE INDEX IF NOT EXISTS idx_api_logs_project_ts ON api_logs (project_id, timestamp);
+CREATE INDEX IF NOT EXISTS idx_apilog_headers_project_created ON apilog_headers (project_id, created_at);
+CREATE INDEX IF NOT EXISTS idx_apilog_bodies_project_created ON apilog_bodies (project_id, created_at);
+CREATE INDEX IF NOT EXISTS idx_access_logs_project_ts ON access_logs (project_id, timestamp);
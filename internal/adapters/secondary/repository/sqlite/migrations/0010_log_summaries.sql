@@ -0,0 +1,28 @@
+-- Per-minute and per-hour rollups of each project's logs by environment,
+-- method and route, recomputed from api_logs by the rollup worker. The stats
+-- endpoint reads them instead of aggregating the raw logs. Status codes and
+-- the latency histogram are stored as JSON.
+
+CREATE TABLE IF NOT EXISTS api_log_summaries (
+	project_id        TEXT NOT NULL,
+	granularity       TEXT NOT NULL,
+	bucket            INTEGER NOT NULL,
+	environment       TEXT NOT NULL,
+	method            TEXT NOT NULL,
+	route             TEXT NOT NULL,
+	count             INTEGER NOT NULL,
+	error_count       INTEGER NOT NULL,
+	status_1xx        INTEGER NOT NULL,
+	status_2xx        INTEGER NOT NULL,
+	status_3xx        INTEGER NOT NULL,
+	status_4xx        INTEGER NOT NULL,
+	status_5xx        INTEGER NOT NULL,
+	status_codes      TEXT,
+	latency_sum       INTEGER NOT NULL,
+	latency_min       INTEGER NOT NULL,
+	latency_max       INTEGER NOT NULL,
+	latency_histogram TEXT,
+	PRIMARY KEY (project_id, granularity, bucket, environment, method, route)
+);
+
+CREATE INDEX IF NOT EXISTS idx_api_log_summaries_granularity_bucket ON api_log_summaries (granularity, bucket);
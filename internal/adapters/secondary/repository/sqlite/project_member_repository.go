@@ -0,0 +1,126 @@
+package sqlite
+
+import (
+	"context"
+	"database/sql"
+
+	"github.com/spidey52/api-logs/internal/domain"
+	"github.com/spidey52/api-logs/internal/ports/output"
+)
+
+type ProjectMemberRepository struct {
+	db *sql.DB
+}
+
+func NewProjectMemberRepository(db *sql.DB) *ProjectMemberRepository {
+	return &ProjectMemberRepository{db: db}
+}
+
+var _ output.ProjectMemberRepository = (*ProjectMemberRepository)(nil)
+
+const memberColumns = `id, project_id, account_id, role, created_at, updated_at`
+
+func scanMember(row rowScanner) (*domain.ProjectMember, error) {
+	var member domain.ProjectMember
+	var role string
+	var createdAt, updatedAt int64
+	err := row.Scan(&member.ID, &member.ProjectID, &member.AccountID, &role, &createdAt, &updatedAt)
+	if err != nil {
+		return nil, err
+	}
+	member.Role = domain.ProjectRole(role)
+	member.CreatedAt = fromMillis(createdAt)
+	member.UpdatedAt = fromMillis(updatedAt)
+	return &member, nil
+}
+
+// Create implements output.ProjectMemberRepository.
+func (r *ProjectMemberRepository) Create(ctx context.Context, member *domain.ProjectMember) error {
+	_, err := r.db.ExecContext(ctx, `
+		INSERT INTO project_members (`+memberColumns+`)
+		VALUES (?, ?, ?, ?, ?, ?)`,
+		member.ID, member.ProjectID, member.AccountID, string(member.Role), toMillis(member.CreatedAt), toMillis(member.UpdatedAt),
+	)
+	if isUniqueViolation(err) {
+		return domain.ErrDuplicateMember
+	}
+	return err
+}
+
+// Find implements output.ProjectMemberRepository.
+func (r *ProjectMemberRepository) Find(ctx context.Context, projectID, accountID string) (*domain.ProjectMember, error) {
+	member, err := scanMember(r.db.QueryRowContext(ctx, `
+		SELECT `+memberColumns+` FROM project_members
+		WHERE project_id = ? AND account_id = ?`, projectID, accountID))
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, domain.ErrMemberNotFound
+		}
+		return nil, err
+	}
+	return member, nil
+}
+
+// Update implements output.ProjectMemberRepository.
+func (r *ProjectMemberRepository) Update(ctx context.Context, member *domain.ProjectMember) error {
+	result, err := r.db.ExecContext(ctx, `
+		UPDATE project_members SET role = ?, updated_at = ?
+		WHERE project_id = ? AND account_id = ?`,
+		string(member.Role), toMillis(member.UpdatedAt), member.ProjectID, member.AccountID,
+	)
+	if err != nil {
+		return err
+	}
+
+	if affected, _ := result.RowsAffected(); affected == 0 {
+		return domain.ErrMemberNotFound
+	}
+	return nil
+}
+
+// Delete implements output.ProjectMemberRepository.
+func (r *ProjectMemberRepository) Delete(ctx context.Context, projectID, accountID string) error {
+	result, err := r.db.ExecContext(ctx, `DELETE FROM project_members WHERE project_id = ? AND account_id = ?`, projectID, accountID)
+	if err != nil {
+		return err
+	}
+
+	if affected, _ := result.RowsAffected(); affected == 0 {
+		return domain.ErrMemberNotFound
+	}
+	return nil
+}
+
+// DeleteByProject implements output.ProjectMemberRepository.
+func (r *ProjectMemberRepository) DeleteByProject(ctx context.Context, projectID string) error {
+	_, err := r.db.ExecContext(ctx, `DELETE FROM project_members WHERE project_id = ?`, projectID)
+	return err
+}
+
+// ListByProject implements output.ProjectMemberRepository.
+func (r *ProjectMemberRepository) ListByProject(ctx context.Context, projectID string) ([]*domain.ProjectMember, error) {
+	return r.list(ctx, `SELECT `+memberColumns+` FROM project_members WHERE project_id = ? ORDER BY created_at, id`, projectID)
+}
+
+// ListByAccount implements output.ProjectMemberRepository.
+func (r *ProjectMemberRepository) ListByAccount(ctx context.Context, accountID string) ([]*domain.ProjectMember, error) {
+	return r.list(ctx, `SELECT `+memberColumns+` FROM project_members WHERE account_id = ? ORDER BY created_at, id`, accountID)
+}
+
+func (r *ProjectMemberRepository) list(ctx context.Context, query string, args ...any) ([]*domain.ProjectMember, error) {
+	rows, err := r.db.QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	members := []*domain.ProjectMember{}
+	for rows.Next() {
+		member, err := scanMember(rows)
+		if err != nil {
+			return nil, err
+		}
+		members = append(members, member)
+	}
+	return members, rows.Err()
+}
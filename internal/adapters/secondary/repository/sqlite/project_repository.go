@@ -0,0 +1,170 @@
+package sqlite
+
+import (
+	"context"
+	"database/sql"
+
+	"github.com/spidey52/api-logs/internal/domain"
+	"github.com/spidey52/api-logs/internal/ports/output"
+)
+
+type ProjectRepository struct {
+	db *sql.DB
+}
+
+func NewProjectRepository(db *sql.DB) *ProjectRepository {
+	return &ProjectRepository{db: db}
+}
+
+var _ output.ProjectRepository = (*ProjectRepository)(nil)
+
+const projectColumns = `id, name, description, api_key_hash, api_key_prefix, environment, is_active, created_at, updated_at,
+	retention_logs_days, retention_headers_days, retention_bodies_days, retention_access_logs_days`
+
+func scanProject(row rowScanner) (*domain.Project, error) {
+	var project domain.Project
+	var envStr string
+	var createdAt, updatedAt int64
+
+	err := row.Scan(
+		&project.ID, &project.Name, &project.Description, &project.APIKeyHash, &project.APIKeyPrefix, &envStr, &project.IsActive, &createdAt, &updatedAt,
+		&project.Retention.LogsDays, &project.Retention.HeadersDays, &project.Retention.BodiesDays, &project.Retention.AccessLogsDays,
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	project.Environment = domain.Environment(envStr)
+	project.CreatedAt = fromMillis(createdAt)
+	project.UpdatedAt = fromMillis(updatedAt)
+	return &project, nil
+}
+
+// Create implements output.ProjectRepository.
+func (r *ProjectRepository) Create(ctx context.Context, project *domain.Project) error {
+	_, err := r.db.ExecContext(ctx, `
+		INSERT INTO projects (`+projectColumns+`)
+		VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)`,
+		project.ID, project.Name, project.Description, project.APIKeyHash, project.APIKeyPrefix, string(project.Environment), project.IsActive,
+		toMillis(project.CreatedAt), toMillis(project.UpdatedAt),
+		project.Retention.LogsDays, project.Retention.HeadersDays, project.Retention.BodiesDays, project.Retention.AccessLogsDays,
+	)
+	if isUniqueViolation(err) {
+		return domain.ErrDuplicateAPIKey
+	}
+	return err
+}
+
+// FindByID implements output.ProjectRepository.
+func (r *ProjectRepository) FindByID(ctx context.Context, id string) (*domain.Project, error) {
+	return r.findOne(ctx, `SELECT `+projectColumns+` FROM projects WHERE id = ?`, id)
+}
+
+// FindByAPIKey implements output.ProjectRepository.
+func (r *ProjectRepository) FindByAPIKey(ctx context.Context, apiKeyHash string) (*domain.Project, error) {
+	return r.findOne(ctx, `SELECT `+projectColumns+` FROM projects WHERE api_key_hash = ?`, apiKeyHash)
+}
+
+func (r *ProjectRepository) findOne(ctx context.Context, query string, args ...any) (*domain.Project, error) {
+	project, err := scanProject(r.db.QueryRowContext(ctx, query, args...))
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, domain.ErrProjectNotFound
+		}
+		return nil, err
+	}
+	return project, nil
+}
+
+// FindAll implements output.ProjectRepository.
+func (r *ProjectRepository) FindAll(ctx context.Context, filter domain.ProjectFilter) ([]*domain.Project, error) {
+	query := `SELECT ` + projectColumns + ` FROM projects WHERE 1=1`
+	args := []any{}
+
+	if filter.Environment != "" {
+		query += ` AND environment = ?`
+		args = append(args, string(filter.Environment))
+	}
+
+	if filter.IsActive != nil {
+		query += ` AND is_active = ?`
+		args = append(args, *filter.IsActive)
+	}
+
+	if len(filter.IDs) > 0 {
+		query += ` AND id IN (` + placeholders(len(filter.IDs)) + `)`
+		for _, id := range filter.IDs {
+			args = append(args, id)
+		}
+	}
+
+	query += ` ORDER BY created_at DESC`
+	if filter.Limit > 0 {
+		query += ` LIMIT ? OFFSET ?`
+		args = append(args, filter.Limit, filter.Offset)
+	}
+
+	rows, err := r.db.QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	projects := []*domain.Project{}
+	for rows.Next() {
+		project, err := scanProject(rows)
+		if err != nil {
+			return nil, err
+		}
+		projects = append(projects, project)
+	}
+	return projects, rows.Err()
+}
+
+// Update implements output.ProjectRepository.
+func (r *ProjectRepository) Update(ctx context.Context, project *domain.Project) error {
+	result, err := r.db.ExecContext(ctx, `
+		UPDATE projects SET name = ?, description = ?, api_key_hash = ?, api_key_prefix = ?, environment = ?, is_active = ?, updated_at = ?,
+			retention_logs_days = ?, retention_headers_days = ?, retention_bodies_days = ?, retention_access_logs_days = ?
+		WHERE id = ?`,
+		project.Name, project.Description, project.APIKeyHash, project.APIKeyPrefix, string(project.Environment), project.IsActive,
+		toMillis(project.UpdatedAt),
+		project.Retention.LogsDays, project.Retention.HeadersDays, project.Retention.BodiesDays, project.Retention.AccessLogsDays, project.ID,
+	)
+	if err != nil {
+		if isUniqueViolation(err) {
+			return domain.ErrDuplicateAPIKey
+		}
+		return err
+	}
+
+	if affected, _ := result.RowsAffected(); affected == 0 {
+		return domain.ErrProjectNotFound
+	}
+	return nil
+}
+
+// Delete implements output.ProjectRepository.
+func (r *ProjectRepository) Delete(ctx context.Context, id string) error {
+	result, err := r.db.ExecContext(ctx, `DELETE FROM projects WHERE id = ?`, id)
+	if err != nil {
+		return err
+	}
+
+	if affected, _ := result.RowsAffected(); affected == 0 {
+		return domain.ErrProjectNotFound
+	}
+	return nil
+}
+
+// ExistsByAPIKey implements output.ProjectRepository.
+func (r *ProjectRepository) ExistsByAPIKey(ctx context.Context, apiKeyHash string) (bool, error) {
+	var exists bool
+	err := r.db.QueryRowContext(ctx, `SELECT EXISTS(SELECT 1 FROM projects WHERE api_key_hash = ?)`, apiKeyHash).Scan(&exists)
+	return exists, err
+}
+
+// HashPlaintextAPIKeys implements output.ProjectRepository.
+func (r *ProjectRepository) HashPlaintextAPIKeys(ctx context.Context, hash func(key string) string) (int, error) {
+	return hashPlaintextKeys(ctx, r.db, "projects", "api_key_hash", "api_key_prefix", hash)
+}
@@ -0,0 +1,67 @@
+package sqlite
+
+import (
+	"context"
+	"database/sql"
+
+	"github.com/spidey52/api-logs/internal/domain"
+	"github.com/spidey52/api-logs/internal/ports/output"
+)
+
+type QuotaRepository struct {
+	db *sql.DB
+}
+
+func NewQuotaRepository(db *sql.DB) *QuotaRepository {
+	return &QuotaRepository{db: db}
+}
+
+var _ output.QuotaRepository = (*QuotaRepository)(nil)
+
+// Find implements output.QuotaRepository.
+func (r *QuotaRepository) Find(ctx context.Context, projectID string) (*domain.ProjectQuota, error) {
+	var quota domain.ProjectQuota
+	var updatedAt int64
+	err := r.db.QueryRowContext(ctx, `
+		SELECT project_id, requests_per_second, logs_per_day, key_requests_per_second, key_logs_per_day, updated_at
+		FROM project_quotas WHERE project_id = ?`, projectID).Scan(
+		&quota.ProjectID, &quota.RequestsPerSecond, &quota.LogsPerDay, &quota.KeyRequestsPerSecond, &quota.KeyLogsPerDay, &updatedAt,
+	)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, domain.ErrQuotaNotFound
+		}
+		return nil, err
+	}
+	quota.UpdatedAt = fromMillis(updatedAt)
+	return &quota, nil
+}
+
+// Upsert implements output.QuotaRepository.
+func (r *QuotaRepository) Upsert(ctx context.Context, quota *domain.ProjectQuota) error {
+	_, err := r.db.ExecContext(ctx, `
+		INSERT INTO project_quotas (project_id, requests_per_second, logs_per_day, key_requests_per_second, key_logs_per_day, updated_at)
+		VALUES (?, ?, ?, ?, ?, ?)
+		ON CONFLICT (project_id) DO UPDATE SET
+			requests_per_second = excluded.requests_per_second,
+			logs_per_day = excluded.logs_per_day,
+			key_requests_per_second = excluded.key_requests_per_second,
+			key_logs_per_day = excluded.key_logs_per_day,
+			updated_at = excluded.updated_at`,
+		quota.ProjectID, quota.RequestsPerSecond, quota.LogsPerDay, quota.KeyRequestsPerSecond, quota.KeyLogsPerDay, toMillis(quota.UpdatedAt),
+	)
+	return err
+}
+
+// Delete implements output.QuotaRepository.
+func (r *QuotaRepository) Delete(ctx context.Context, projectID string) error {
+	result, err := r.db.ExecContext(ctx, `DELETE FROM project_quotas WHERE project_id = ?`, projectID)
+	if err != nil {
+		return err
+	}
+
+	if affected, _ := result.RowsAffected(); affected == 0 {
+		return domain.ErrQuotaNotFound
+	}
+	return nil
+}
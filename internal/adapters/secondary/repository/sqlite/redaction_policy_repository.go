@@ -0,0 +1,76 @@
+package sqlite
+
+import (
+	"context"
+	"database/sql"
+
+	"github.com/spidey52/api-logs/internal/domain"
+	"github.com/spidey52/api-logs/internal/ports/output"
+)
+
+type RedactionPolicyRepository struct {
+	db *sql.DB
+}
+
+func NewRedactionPolicyRepository(db *sql.DB) *RedactionPolicyRepository {
+	return &RedactionPolicyRepository{db: db}
+}
+
+var _ output.RedactionPolicyRepository = (*RedactionPolicyRepository)(nil)
+
+// Find implements output.RedactionPolicyRepository.
+func (r *RedactionPolicyRepository) Find(ctx context.Context, projectID string) (*domain.RedactionPolicy, error) {
+	var policy domain.RedactionPolicy
+	var headers, queryParams, jsonPaths, patterns, regexes *string
+	var updatedAt int64
+	err := r.db.QueryRowContext(ctx, `
+		SELECT project_id, headers, query_params, json_paths, patterns, regexes, updated_at
+		FROM redaction_policies WHERE project_id = ?`, projectID).Scan(
+		&policy.ProjectID, &headers, &queryParams, &jsonPaths, &patterns, &regexes, &updatedAt,
+	)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, domain.ErrRedactionPolicyNotFound
+		}
+		return nil, err
+	}
+
+	unmarshalJSON(headers, &policy.Headers)
+	unmarshalJSON(queryParams, &policy.QueryParams)
+	unmarshalJSON(jsonPaths, &policy.JSONPaths)
+	unmarshalJSON(patterns, &policy.Patterns)
+	unmarshalJSON(regexes, &policy.Regexes)
+	policy.UpdatedAt = fromMillis(updatedAt)
+	return &policy, nil
+}
+
+// Upsert implements output.RedactionPolicyRepository.
+func (r *RedactionPolicyRepository) Upsert(ctx context.Context, policy *domain.RedactionPolicy) error {
+	_, err := r.db.ExecContext(ctx, `
+		INSERT INTO redaction_policies (project_id, headers, query_params, json_paths, patterns, regexes, updated_at)
+		VALUES (?, ?, ?, ?, ?, ?, ?)
+		ON CONFLICT (project_id) DO UPDATE SET
+			headers = excluded.headers,
+			query_params = excluded.query_params,
+			json_paths = excluded.json_paths,
+			patterns = excluded.patterns,
+			regexes = excluded.regexes,
+			updated_at = excluded.updated_at`,
+		policy.ProjectID, marshalJSON(policy.Headers), marshalJSON(policy.QueryParams), marshalJSON(policy.JSONPaths),
+		marshalJSON(policy.Patterns), marshalJSON(policy.Regexes), toMillis(policy.UpdatedAt),
+	)
+	return err
+}
+
+// Delete implements output.RedactionPolicyRepository.
+func (r *RedactionPolicyRepository) Delete(ctx context.Context, projectID string) error {
+	result, err := r.db.ExecContext(ctx, `DELETE FROM redaction_policies WHERE project_id = ?`, projectID)
+	if err != nil {
+		return err
+	}
+
+	if affected, _ := result.RowsAffected(); affected == 0 {
+		return domain.ErrRedactionPolicyNotFound
+	}
+	return nil
+}
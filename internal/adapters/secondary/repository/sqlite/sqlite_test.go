@@ -0,0 +1,111 @@
+package sqlite
+
+import (
+	"context"
+	"database/sql"
+	"path/filepath"
+	"testing"
+
+	"github.com/spidey52/api-logs/internal/adapters/secondary/repository/contract"
+	"github.com/spidey52/api-logs/internal/ports/output"
+)
+
+// openTestDB returns a migrated database in a fresh temp file
+func openTestDB(t *testing.T) *sql.DB {
+	t.Helper()
+
+	db, err := Open(context.Background(), filepath.Join(t.TempDir(), "test.db"))
+	if err != nil {
+		t.Fatalf("open sqlite: %v", err)
+	}
+	t.Cleanup(func() { db.Close() })
+
+	if err := Migrate(context.Background(), db); err != nil {
+		t.Fatalf("migrate sqlite: %v", err)
+	}
+	return db
+}
+
+func TestAPILogRepository(t *testing.T) {
+	contract.RunAPILogRepository(t, func(t *testing.T) output.APILogRepository {
+		return NewAPILogRepository(openTestDB(t))
+	})
+}
+
+func TestHeadersRepository(t *testing.T) {
+	contract.RunHeadersRepository(t, func(t *testing.T) output.APILogHeadersRepository {
+		return NewAPILogHeadersRepository(openTestDB(t))
+	})
+}
+
+func TestBodyRepository(t *testing.T) {
+	contract.RunBodyRepository(t, func(t *testing.T) output.APILogBodyRepository {
+		return NewAPILogBodyRepository(openTestDB(t))
+	})
+}
+
+func TestProjectRepository(t *testing.T) {
+	contract.RunProjectRepository(t, func(t *testing.T) output.ProjectRepository {
+		return NewProjectRepository(openTestDB(t))
+	})
+}
+
+func TestUserRepository(t *testing.T) {
+	contract.RunUserRepository(t, func(t *testing.T) output.UserRepository {
+		return NewUserRepository(openTestDB(t))
+	})
+}
+
+func TestAccessLogRepository(t *testing.T) {
+	contract.RunAccessLogRepository(t, func(t *testing.T) output.AccessLogRepository {
+		return NewAccessLogRepository(openTestDB(t))
+	})
+}
+
+func TestAccountRepository(t *testing.T) {
+	contract.RunAccountRepository(t, func(t *testing.T) output.AccountRepository {
+		return NewAccountRepository(openTestDB(t))
+	})
+}
+
+func TestSessionRepository(t *testing.T) {
+	contract.RunSessionRepository(t, func(t *testing.T) output.SessionRepository {
+		return NewSessionRepository(openTestDB(t))
+	})
+}
+
+func TestProjectMemberRepository(t *testing.T) {
+	contract.RunProjectMemberRepository(t, func(t *testing.T) output.ProjectMemberRepository {
+		return NewProjectMemberRepository(openTestDB(t))
+	})
+}
+
+func TestAPIKeyRepository(t *testing.T) {
+	contract.RunAPIKeyRepository(t, func(t *testing.T) output.APIKeyRepository {
+		return NewAPIKeyRepository(openTestDB(t))
+	})
+}
+
+func TestQuotaRepository(t *testing.T) {
+	contract.RunQuotaRepository(t, func(t *testing.T) output.QuotaRepository {
+		return NewQuotaRepository(openTestDB(t))
+	})
+}
+
+func TestRedactionPolicyRepository(t *testing.T) {
+	contract.RunRedactionPolicyRepository(t, func(t *testing.T) output.RedactionPolicyRepository {
+		return NewRedactionPolicyRepository(openTestDB(t))
+	})
+}
+
+func TestDataKeyRepository(t *testing.T) {
+	contract.RunDataKeyRepository(t, func(t *testing.T) output.DataKeyRepository {
+		return NewDataKeyRepository(openTestDB(t))
+	})
+}
+
+func TestLogSummaryRepository(t *testing.T) {
+	contract.RunLogSummaryRepository(t, func(t *testing.T) output.LogSummaryRepository {
+		return NewLogSummaryRepository(openTestDB(t))
+	})
+}
@@ -0,0 +1,161 @@
+package sqlite
+
+import (
+	"context"
+	"database/sql"
+
+	"github.com/spidey52/api-logs/internal/domain"
+	"github.com/spidey52/api-logs/internal/ports/output"
+)
+
+type UserRepository struct {
+	db *sql.DB
+}
+
+func NewUserRepository(db *sql.DB) *UserRepository {
+	return &UserRepository{db: db}
+}
+
+var _ output.UserRepository = (*UserRepository)(nil)
+
+const userColumns = `id, name, identifier, metadata, created_at, project_id`
+
+func scanUser(row rowScanner) (*domain.User, error) {
+	var user domain.User
+	var metadataJSON *string
+	var createdAt int64
+
+	if err := row.Scan(&user.ID, &user.Name, &user.Identifier, &metadataJSON, &createdAt, &user.ProjectID); err != nil {
+		return nil, err
+	}
+
+	user.CreatedAt = fromMillis(createdAt)
+	unmarshalJSON(metadataJSON, &user.Metadata)
+	return &user, nil
+}
+
+// Create implements output.UserRepository.
+func (r *UserRepository) Create(ctx context.Context, user *domain.User) error {
+	_, err := r.db.ExecContext(ctx, `
+		INSERT INTO users (`+userColumns+`)
+		VALUES (?, ?, ?, ?, ?, ?)`,
+		user.ID, user.Name, user.Identifier, marshalJSON(user.Metadata), toMillis(user.CreatedAt), user.ProjectID,
+	)
+	if isUniqueViolation(err) {
+		return domain.ErrDuplicateUserIdentifier
+	}
+	return err
+}
+
+// FindByID implements output.UserRepository.
+func (r *UserRepository) FindByID(ctx context.Context, id string) (*domain.User, error) {
+	return r.findOne(ctx, `SELECT `+userColumns+` FROM users WHERE id = ?`, id)
+}
+
+// FindByIdentifier implements output.UserRepository.
+func (r *UserRepository) FindByIdentifier(ctx context.Context, identifier string, projectID string) (*domain.User, error) {
+	return r.findOne(ctx, `SELECT `+userColumns+` FROM users WHERE identifier = ? AND project_id = ?`, identifier, projectID)
+}
+
+func (r *UserRepository) findOne(ctx context.Context, query string, args ...any) (*domain.User, error) {
+	user, err := scanUser(r.db.QueryRowContext(ctx, query, args...))
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, domain.ErrUserNotFound
+		}
+		return nil, err
+	}
+	return user, nil
+}
+
+// Update implements output.UserRepository.
+func (r *UserRepository) Update(ctx context.Context, user *domain.User) error {
+	result, err := r.db.ExecContext(ctx, `
+		UPDATE users SET name = ?, identifier = ?, metadata = ?
+		WHERE id = ?`,
+		user.Name, user.Identifier, marshalJSON(user.Metadata), user.ID,
+	)
+	if err != nil {
+		return err
+	}
+
+	if affected, _ := result.RowsAffected(); affected == 0 {
+		return domain.ErrUserNotFound
+	}
+	return nil
+}
+
+// Delete implements output.UserRepository.
+func (r *UserRepository) Delete(ctx context.Context, id string) error {
+	result, err := r.db.ExecContext(ctx, `DELETE FROM users WHERE id = ?`, id)
+	if err != nil {
+		return err
+	}
+
+	if affected, _ := result.RowsAffected(); affected == 0 {
+		return domain.ErrUserNotFound
+	}
+	return nil
+}
+
+// DeleteByProject implements output.UserRepository.
+func (r *UserRepository) DeleteByProject(ctx context.Context, projectID string) error {
+	_, err := r.db.ExecContext(ctx, `DELETE FROM users WHERE project_id = ?`, projectID)
+	return err
+}
+
+// List implements output.UserRepository.
+func (r *UserRepository) List(ctx context.Context, projectID string, page, pageSize int) ([]*domain.User, int, error) {
+	offset := (page - 1) * pageSize
+
+	var total int
+	if err := r.db.QueryRowContext(ctx, `SELECT COUNT(*) FROM users WHERE project_id = ?`, projectID).Scan(&total); err != nil {
+		return nil, 0, err
+	}
+
+	rows, err := r.db.QueryContext(ctx, `
+		SELECT `+userColumns+`
+		FROM users WHERE project_id = ? ORDER BY created_at DESC LIMIT ? OFFSET ?`, projectID, pageSize, offset)
+	if err != nil {
+		return nil, 0, err
+	}
+	defer rows.Close()
+
+	users := []*domain.User{}
+	for rows.Next() {
+		user, err := scanUser(rows)
+		if err != nil {
+			return nil, 0, err
+		}
+		users = append(users, user)
+	}
+	return users, total, rows.Err()
+}
+
+// GetUserMap implements output.UserRepository.
+func (r *UserRepository) GetUserMap(ctx context.Context, ids []string) (map[string]*domain.User, error) {
+	userMap := make(map[string]*domain.User)
+	if len(ids) == 0 {
+		return userMap, nil
+	}
+
+	args := make([]any, len(ids))
+	for i, id := range ids {
+		args[i] = id
+	}
+
+	rows, err := r.db.QueryContext(ctx, `SELECT `+userColumns+` FROM users WHERE id IN (`+placeholders(len(args))+`)`, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		user, err := scanUser(rows)
+		if err != nil {
+			return nil, err
+		}
+		userMap[user.ID] = user
+	}
+	return userMap, rows.Err()
+}
@@ -0,0 +1,89 @@
+package tiered
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/spidey52/api-logs/internal/domain"
+	"github.com/spidey52/api-logs/internal/ports/output"
+)
+
+// Mover moves the logs of every project past an age from the hot tier to the
+// cold one
+type Mover struct {
+	hot       output.APILogRepository
+	cold      *Store
+	projects  output.ProjectRepository
+	age       time.Duration
+	batchSize int
+}
+
+// NewMover creates a mover of the logs older than age, moving at most
+// batchSize logs per file
+func NewMover(hot output.APILogRepository, cold *Store, projects output.ProjectRepository, age time.Duration, batchSize int) *Mover {
+	if batchSize <= 0 {
+		batchSize = 10000
+	}
+	return &Mover{hot: hot, cold: cold, projects: projects, age: age, batchSize: batchSize}
+}
+
+// Move moves each project's logs past the age, returning how many were moved
+func (m *Mover) Move(ctx context.Context) (int64, error) {
+	projects, err := m.projects.FindAll(ctx, domain.ProjectFilter{})
+	if err != nil {
+		return 0, err
+	}
+
+	var total int64
+	for _, project := range projects {
+		moved, err := m.MoveProject(ctx, project.ID)
+		total += moved
+		if err != nil {
+			return total, fmt.Errorf("move logs of project %s: %w", project.ID, err)
+		}
+	}
+	return total, nil
+}
+
+// MoveProject moves a project's logs past the age, one batch at a time. Each
+// day of a batch is written to the cold tier before it is removed from the
+// hot one; a batch moved again after a failed removal replaces its file.
+func (m *Mover) MoveProject(ctx context.Context, projectID string) (int64, error) {
+	before := time.Now().Add(-m.age)
+
+	var moved int64
+	for {
+		logs, err := m.hot.FindOlderThan(ctx, projectID, before, m.batchSize)
+		if err != nil {
+			return moved, err
+		}
+
+		for batch := logs; len(batch) > 0; {
+			// Logs are found oldest first, so a day's logs are adjacent
+			day := truncateDay(batch[0].Timestamp)
+			n := 1
+			for n < len(batch) && truncateDay(batch[n].Timestamp).Equal(day) {
+				n++
+			}
+
+			if err := m.cold.write(projectID, day, batch[:n]); err != nil {
+				return moved, err
+			}
+			ids := make([]string, n)
+			for i, log := range batch[:n] {
+				ids[i] = log.ID
+			}
+			if err := m.hot.DeleteBatch(ctx, ids); err != nil {
+				return moved, err
+			}
+
+			moved += int64(n)
+			batch = batch[n:]
+		}
+
+		if len(logs) < m.batchSize || ctx.Err() != nil {
+			return moved, ctx.Err()
+		}
+	}
+}
@@ -0,0 +1,192 @@
+package tiered
+
+import (
+	"context"
+	"sort"
+	"time"
+
+	"github.com/spidey52/api-logs/internal/domain"
+	"github.com/spidey52/api-logs/internal/ports/output"
+)
+
+// apiLogRepository queries an APILogRepository, the hot tier, together with
+// the cold tier its old logs were moved to. Writes go to the hot tier.
+type apiLogRepository struct {
+	output.APILogRepository
+	cold *Store
+}
+
+// NewAPILogRepository wraps a log repository so its queries also cover the
+// logs moved to the cold tier
+func NewAPILogRepository(hot output.APILogRepository, cold *Store) output.APILogRepository {
+	return &apiLogRepository{APILogRepository: hot, cold: cold}
+}
+
+// CreateMany stores multiple API log entries, skipping the ones already in
+// either tier
+func (r *apiLogRepository) CreateMany(ctx context.Context, logs []*domain.APILog) error {
+	existing, err := r.cold.existing(ctx, logs)
+	if err != nil {
+		return err
+	}
+	if len(existing) > 0 {
+		fresh := make([]*domain.APILog, 0, len(logs))
+		for _, log := range logs {
+			if !existing[log.ID] {
+				fresh = append(fresh, log)
+			}
+		}
+		logs = fresh
+	}
+	return r.APILogRepository.CreateMany(ctx, logs)
+}
+
+// FindByID retrieves a log by ID from the hot tier, then the cold one
+func (r *apiLogRepository) FindByID(ctx context.Context, id string) (*domain.APILog, error) {
+	log, err := r.APILogRepository.FindByID(ctx, id)
+	if err != domain.ErrLogNotFound {
+		return log, err
+	}
+	return r.cold.findByID(ctx, id)
+}
+
+// FindByFilter retrieves logs based on filter criteria from both tiers. The
+// cold tier is not read when the hot tier fills the page with logs newer than
+// any cold log the filter reaches.
+func (r *apiLogRepository) FindByFilter(ctx context.Context, filter domain.LogFilter) ([]*domain.APILog, error) {
+	partitions, err := r.cold.reaches(filter)
+	if err != nil {
+		return nil, err
+	}
+	if len(partitions) == 0 {
+		return r.APILogRepository.FindByFilter(ctx, filter)
+	}
+
+	// Both tiers are read from the first log, as the page can start in either
+	need := 0
+	if filter.Limit > 0 {
+		need = filter.Offset + filter.Limit
+	}
+	hotFilter := filter
+	hotFilter.SharedFilter = domain.SharedFilter{Limit: need}
+	logs, err := r.APILogRepository.FindByFilter(ctx, hotFilter)
+	if err != nil {
+		return nil, err
+	}
+
+	newestCold := partitions[len(partitions)-1].end()
+	if need == 0 || len(logs) < need || logs[need-1].Timestamp.Before(newestCold) {
+		cold, err := r.cold.find(ctx, filter, need)
+		if err != nil {
+			return nil, err
+		}
+		logs = merge(logs, cold)
+	}
+	return paginate(logs, filter.SharedFilter), nil
+}
+
+// merge merges the hot and cold logs, newest first. A log in both tiers, left
+// by an interrupted move, is listed once.
+func merge(hot, cold []*domain.APILog) []*domain.APILog {
+	seen := make(map[string]bool, len(hot))
+	for _, log := range hot {
+		seen[log.ID] = true
+	}
+
+	logs := hot
+	for _, log := range cold {
+		if !seen[log.ID] {
+			logs = append(logs, log)
+		}
+	}
+	sort.SliceStable(logs, func(i, j int) bool {
+		return logs[i].Timestamp.After(logs[j].Timestamp)
+	})
+	return logs
+}
+
+// paginate applies offset/limit to logs
+func paginate(logs []*domain.APILog, shared domain.SharedFilter) []*domain.APILog {
+	start := min(shared.Offset, len(logs))
+	end := len(logs)
+	if shared.Limit > 0 {
+		end = min(start+shared.Limit, end)
+	}
+	return logs[start:end]
+}
+
+// Delete removes a log by ID from the tier holding it
+func (r *apiLogRepository) Delete(ctx context.Context, id string) error {
+	err := r.APILogRepository.Delete(ctx, id)
+	if err != domain.ErrLogNotFound {
+		return err
+	}
+	return r.cold.delete(ctx, id)
+}
+
+// DeleteBatch removes the logs with the given IDs. Cold logs are removed only
+// when they are the oldest of their project, as FindOlderThan returns them.
+func (r *apiLogRepository) DeleteBatch(ctx context.Context, ids []string) error {
+	if err := r.APILogRepository.DeleteBatch(ctx, ids); err != nil {
+		return err
+	}
+	return r.cold.deleteOldest(ctx, ids)
+}
+
+// CountByFilter counts logs matching the filter criteria in both tiers
+func (r *apiLogRepository) CountByFilter(ctx context.Context, filter domain.LogFilter) (int64, error) {
+	count, err := r.APILogRepository.CountByFilter(ctx, filter)
+	if err != nil {
+		return 0, err
+	}
+	cold, err := r.cold.count(ctx, filter)
+	return count + cold, err
+}
+
+// FindOlderThan retrieves a batch of a project's expired logs from both
+// tiers, oldest first
+func (r *apiLogRepository) FindOlderThan(ctx context.Context, projectID string, before time.Time, limit int) ([]*domain.APILog, error) {
+	cold, err := r.cold.findOlderThan(ctx, projectID, before, limit)
+	if err != nil {
+		return nil, err
+	}
+	logs, err := r.APILogRepository.FindOlderThan(ctx, projectID, before, limit)
+	if err != nil {
+		return nil, err
+	}
+	if len(cold) == 0 {
+		return logs, nil
+	}
+
+	logs = append(cold, logs...)
+	sort.SliceStable(logs, func(i, j int) bool {
+		return logs[i].Timestamp.Before(logs[j].Timestamp)
+	})
+	if len(logs) > limit {
+		logs = logs[:limit]
+	}
+	return logs, nil
+}
+
+// DeleteOlderThan removes a batch of a project's expired logs, from the hot
+// tier first
+func (r *apiLogRepository) DeleteOlderThan(ctx context.Context, projectID string, before time.Time, limit int) (int64, error) {
+	deleted, err := r.APILogRepository.DeleteOlderThan(ctx, projectID, before, limit)
+	if err != nil || deleted >= int64(limit) {
+		return deleted, err
+	}
+	cold, err := r.cold.deleteOlderThan(ctx, projectID, before, limit-int(deleted))
+	return deleted + cold, err
+}
+
+// VolumeOlderThan measures a project's expired logs in both tiers
+func (r *apiLogRepository) VolumeOlderThan(ctx context.Context, projectID string, before time.Time) (domain.DataVolume, error) {
+	volume, err := r.APILogRepository.VolumeOlderThan(ctx, projectID, before)
+	if err != nil {
+		return volume, err
+	}
+	cold, err := r.cold.volumeOlderThan(ctx, projectID, before)
+	volume.Documents += cold.Documents
+	volume.Bytes += cold.Bytes
+	return volume, err
+}
@@ -0,0 +1,301 @@
+package tiered
+
+import (
+	"context"
+	"sort"
+	"time"
+
+	"github.com/spidey52/api-logs/internal/domain"
+)
+
+// coldStats aggregates the cold logs in the stats window
+type coldStats struct {
+	count        int64
+	responseTime int64
+	statusCodes  map[int]int64
+	methods      map[string]int64
+	hours        map[time.Time]int64
+	endpoints    map[string]*endpointStats
+}
+
+// endpointStats aggregates the logs of one path
+type endpointStats struct {
+	method       string
+	count        int64
+	responseTime int64
+}
+
+// startOfDay returns local midnight for t, matching the backends' stats window
+func startOfDay(t time.Time) time.Time {
+	year, month, day := t.Date()
+	return time.Date(year, month, day, 0, 0, 0, 0, t.Location())
+}
+
+// stats aggregates a project's cold logs in the stats window, today's. It
+// returns nil when no cold log is in the window, as is the case unless logs
+// are moved on the day they are stored.
+func (r *apiLogRepository) stats(ctx context.Context, projectID string, environment domain.Environment) (*coldStats, error) {
+	from := startOfDay(time.Now())
+	logs, err := r.cold.find(ctx, domain.LogFilter{ProjectID: projectID, Environment: environment, FromDate: &from}, 0)
+	if err != nil || len(logs) == 0 {
+		return nil, err
+	}
+
+	s := &coldStats{
+		statusCodes: make(map[int]int64),
+		methods:     make(map[string]int64),
+		hours:       make(map[time.Time]int64),
+		endpoints:   make(map[string]*endpointStats),
+	}
+	for _, log := range logs {
+		s.count++
+		s.responseTime += log.ResponseTime
+		s.statusCodes[log.StatusCode]++
+		s.methods[string(log.Method)]++
+		s.hours[log.Timestamp.UTC().Truncate(time.Hour)]++
+
+		e, ok := s.endpoints[log.Path]
+		if !ok {
+			e = &endpointStats{method: string(log.Method)}
+			s.endpoints[log.Path] = e
+		}
+		e.count++
+		e.responseTime += log.ResponseTime
+	}
+	return s, nil
+}
+
+// CountByProject counts today's logs of a project in both tiers
+func (r *apiLogRepository) CountByProject(ctx context.Context, projectID string, environment domain.Environment) (int64, error) {
+	count, err := r.APILogRepository.CountByProject(ctx, projectID, environment)
+	if err != nil {
+		return 0, err
+	}
+	cold, err := r.stats(ctx, projectID, environment)
+	if err != nil || cold == nil {
+		return count, err
+	}
+	return count + cold.count, nil
+}
+
+// GetStatusCodeDistribution returns distribution of status codes in both tiers
+func (r *apiLogRepository) GetStatusCodeDistribution(ctx context.Context, projectID string, environment domain.Environment) (map[int]int64, error) {
+	distribution, err := r.APILogRepository.GetStatusCodeDistribution(ctx, projectID, environment)
+	if err != nil {
+		return nil, err
+	}
+	cold, err := r.stats(ctx, projectID, environment)
+	if err != nil || cold == nil {
+		return distribution, err
+	}
+
+	if distribution == nil {
+		distribution = make(map[int]int64)
+	}
+	for code, count := range cold.statusCodes {
+		distribution[code] += count
+	}
+	return distribution, nil
+}
+
+// GetAverageResponseTime returns average response time over both tiers
+func (r *apiLogRepository) GetAverageResponseTime(ctx context.Context, projectID string, environment domain.Environment) (float64, error) {
+	avg, err := r.APILogRepository.GetAverageResponseTime(ctx, projectID, environment)
+	if err != nil {
+		return 0, err
+	}
+	cold, err := r.stats(ctx, projectID, environment)
+	if err != nil || cold == nil {
+		return avg, err
+	}
+
+	count, err := r.APILogRepository.CountByProject(ctx, projectID, environment)
+	if err != nil {
+		return 0, err
+	}
+	return (avg*float64(count) + float64(cold.responseTime)) / float64(count+cold.count), nil
+}
+
+// GetTimeSeriesStats returns request count grouped by UTC hour over both
+// tiers
+func (r *apiLogRepository) GetTimeSeriesStats(ctx context.Context, projectID string, environment domain.Environment) ([]map[string]interface{}, error) {
+	series, err := r.APILogRepository.GetTimeSeriesStats(ctx, projectID, environment)
+	if err != nil {
+		return nil, err
+	}
+	cold, err := r.stats(ctx, projectID, environment)
+	if err != nil || cold == nil {
+		return series, err
+	}
+
+	counts := cold.hours
+	for _, point := range series {
+		if hour, ok := toTime(point["timestamp"]); ok {
+			counts[hour.UTC().Truncate(time.Hour)] += toInt64(point["count"])
+		}
+	}
+
+	hours := make([]time.Time, 0, len(counts))
+	for hour := range counts {
+		hours = append(hours, hour)
+	}
+	sort.Slice(hours, func(i, j int) bool { return hours[i].Before(hours[j]) })
+	if len(hours) > 24 {
+		hours = hours[:24]
+	}
+
+	results := make([]map[string]interface{}, 0, len(hours))
+	for _, hour := range hours {
+		results = append(results, map[string]interface{}{
+			"timestamp": hour,
+			"count":     counts[hour],
+		})
+	}
+	return results, nil
+}
+
+// GetTopEndpoints returns top N most requested endpoints over both tiers. The
+// hot tier's ranking is read deep enough that a path missing from it could
+// not rank in the top N.
+func (r *apiLogRepository) GetTopEndpoints(ctx context.Context, projectID string, environment domain.Environment, limit int) ([]map[string]interface{}, error) {
+	cold, err := r.stats(ctx, projectID, environment)
+	if err != nil {
+		return nil, err
+	}
+	if cold == nil {
+		return r.APILogRepository.GetTopEndpoints(ctx, projectID, environment, limit)
+	}
+
+	top, err := r.APILogRepository.GetTopEndpoints(ctx, projectID, environment, limit+len(cold.endpoints))
+	if err != nil {
+		return nil, err
+	}
+
+	endpoints := cold.endpoints
+	for _, entry := range top {
+		path, _ := entry["_id"].(string)
+		count := toInt64(entry["count"])
+		responseTime := int64(toFloat64(entry["avg_response_time"]) * float64(count))
+
+		e, ok := endpoints[path]
+		if !ok {
+			method, _ := entry["method"].(string)
+			e = &endpointStats{method: method}
+			endpoints[path] = e
+		}
+		e.count += count
+		e.responseTime += responseTime
+	}
+
+	paths := make([]string, 0, len(endpoints))
+	for path := range endpoints {
+		paths = append(paths, path)
+	}
+	sort.Slice(paths, func(i, j int) bool {
+		if endpoints[paths[i]].count != endpoints[paths[j]].count {
+			return endpoints[paths[i]].count > endpoints[paths[j]].count
+		}
+		return paths[i] < paths[j]
+	})
+	if limit > 0 && len(paths) > limit {
+		paths = paths[:limit]
+	}
+
+	results := make([]map[string]interface{}, 0, len(paths))
+	for _, path := range paths {
+		e := endpoints[path]
+		results = append(results, map[string]interface{}{
+			"_id":               path,
+			"count":             e.count,
+			"method":            e.method,
+			"avg_response_time": float64(e.responseTime) / float64(e.count),
+		})
+	}
+	return results, nil
+}
+
+// GetMethodDistribution returns distribution of HTTP methods over both tiers
+func (r *apiLogRepository) GetMethodDistribution(ctx context.Context, projectID string, environment domain.Environment) (map[string]int64, error) {
+	distribution, err := r.APILogRepository.GetMethodDistribution(ctx, projectID, environment)
+	if err != nil {
+		return nil, err
+	}
+	cold, err := r.stats(ctx, projectID, environment)
+	if err != nil || cold == nil {
+		return distribution, err
+	}
+
+	if distribution == nil {
+		distribution = make(map[string]int64)
+	}
+	for method, count := range cold.methods {
+		distribution[method] += count
+	}
+	return distribution, nil
+}
+
+// GetUniquePaths returns the unique paths of both tiers
+func (r *apiLogRepository) GetUniquePaths(ctx context.Context, projectID string, environment domain.Environment) ([]string, error) {
+	paths, err := r.APILogRepository.GetUniquePaths(ctx, projectID, environment)
+	if err != nil {
+		return nil, err
+	}
+	cold, err := r.stats(ctx, projectID, environment)
+	if err != nil || cold == nil {
+		return paths, err
+	}
+
+	seen := make(map[string]bool, len(paths))
+	for _, path := range paths {
+		seen[path] = true
+	}
+	for path := range cold.endpoints {
+		if !seen[path] {
+			paths = append(paths, path)
+		}
+	}
+	sort.Strings(paths)
+	return paths, nil
+}
+
+// toTime reads a time series timestamp, which drivers return as time.Time or
+// as their own date type
+func toTime(v interface{}) (time.Time, bool) {
+	switch t := v.(type) {
+	case time.Time:
+		return t, true
+	case interface{ Time() time.Time }:
+		return t.Time(), true
+	default:
+		return time.Time{}, false
+	}
+}
+
+// toInt64 normalises numeric aggregation results, which differ in width
+// between drivers
+func toInt64(v interface{}) int64 {
+	switch n := v.(type) {
+	case int:
+		return int64(n)
+	case int32:
+		return int64(n)
+	case int64:
+		return n
+	case float64:
+		return int64(n)
+	default:
+		return 0
+	}
+}
+
+// toFloat64 normalises a numeric aggregation result to float64
+func toFloat64(v interface{}) float64 {
+	switch n := v.(type) {
+	case float64:
+		return n
+	case float32:
+		return float64(n)
+	default:
+		return float64(toInt64(v))
+	}
+}
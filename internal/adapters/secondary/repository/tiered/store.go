@@ -0,0 +1,820 @@
+// Package tiered keeps old API logs in a cold tier of Parquet files, next to
+// the hot storage backend, and queries both as one repository.
+//
+// Cold logs are partitioned by project and UTC day of their timestamp:
+//
+//	<dir>/project=<id>/date=2006-01-02/logs-<hash>.parquet
+//
+// so a query reads only the partitions its project and date range reach.
+// Each file carries a bloom filter of its log IDs, so a log is found by ID
+// without reading the files that cannot hold it.
+package tiered
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io/fs"
+	"net/url"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/parquet-go/parquet-go"
+	"github.com/spidey52/api-logs/internal/domain"
+)
+
+const (
+	dateLayout = "2006-01-02"
+	fileExt    = ".parquet"
+)
+
+// coldLog is the Parquet row of a cold log
+type coldLog struct {
+	ID            string            `parquet:"id"`
+	ProjectID     string            `parquet:"project_id,dict"`
+	Environment   string            `parquet:"environment,dict"`
+	Method        string            `parquet:"method,dict"`
+	Path          string            `parquet:"path"`
+	Params        map[string]string `parquet:"params"`
+	QueryParams   map[string]string `parquet:"query_params"`
+	StatusCode    int               `parquet:"status_code"`
+	ResponseTime  int64             `parquet:"response_time_ms"`
+	ContentLength int64             `parquet:"content_length"`
+	IPAddress     string            `parquet:"ip_address"`
+	UserAgent     string            `parquet:"user_agent"`
+	ErrorMessage  string            `parquet:"error_message"`
+	UserID        *string           `parquet:"user_id,optional"`
+	Timestamp     time.Time         `parquet:"timestamp,timestamp(millisecond)"`
+}
+
+func toColdLog(log *domain.APILog) coldLog {
+	return coldLog{
+		ID:            log.ID,
+		ProjectID:     log.ProjectID,
+		Environment:   string(log.Environment),
+		Method:        string(log.Method),
+		Path:          log.Path,
+		Params:        log.Params,
+		QueryParams:   log.QueryParams,
+		StatusCode:    log.StatusCode,
+		ResponseTime:  log.ResponseTime,
+		ContentLength: log.ContentLength,
+		IPAddress:     log.IPAddress,
+		UserAgent:     log.UserAgent,
+		ErrorMessage:  log.ErrorMessage,
+		UserID:        log.UserID,
+		Timestamp:     log.Timestamp,
+	}
+}
+
+func (c *coldLog) toDomain() *domain.APILog {
+	return &domain.APILog{
+		ID:            c.ID,
+		ProjectID:     c.ProjectID,
+		Environment:   domain.Environment(c.Environment),
+		Method:        domain.HTTPMethod(c.Method),
+		Path:          c.Path,
+		Params:        c.Params,
+		QueryParams:   c.QueryParams,
+		StatusCode:    c.StatusCode,
+		ResponseTime:  c.ResponseTime,
+		ContentLength: c.ContentLength,
+		IPAddress:     c.IPAddress,
+		UserAgent:     c.UserAgent,
+		ErrorMessage:  c.ErrorMessage,
+		UserID:        c.UserID,
+		Timestamp:     c.Timestamp,
+	}
+}
+
+// Store keeps the cold tier under a directory. Reads run concurrently with
+// each other and with changes, which replace files atomically; changes are
+// serialized.
+type Store struct {
+	dir string
+	mu  sync.Mutex
+}
+
+// NewStore creates a cold tier kept under dir, creating it if needed
+func NewStore(dir string) (*Store, error) {
+	if err := os.MkdirAll(dir, 0o750); err != nil {
+		return nil, fmt.Errorf("cold storage: %w", err)
+	}
+	return &Store{dir: dir}, nil
+}
+
+// partition is the directory of a project's cold logs for one UTC day
+type partition struct {
+	projectID string
+	day       time.Time
+	dir       string
+}
+
+// end returns the start of the day after the partition's
+func (p partition) end() time.Time {
+	return p.day.AddDate(0, 0, 1)
+}
+
+// within reports whether the whole day of the partition is between from and
+// to, nil bounds being open
+func (p partition) within(from, to *time.Time) bool {
+	return (from == nil || !from.After(p.day)) && (to == nil || !to.Before(p.end().Add(-time.Nanosecond)))
+}
+
+// files lists the Parquet files of the partition
+func (p partition) files() ([]string, error) {
+	entries, err := os.ReadDir(p.dir)
+	if errors.Is(err, fs.ErrNotExist) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	var names []string
+	for _, entry := range entries {
+		if !entry.IsDir() && strings.HasSuffix(entry.Name(), fileExt) {
+			names = append(names, filepath.Join(p.dir, entry.Name()))
+		}
+	}
+	return names, nil
+}
+
+// partitions lists the partitions of a project, or of every project when
+// projectID is empty, whose day is within from and to, oldest day first.
+// Nil bounds are open.
+func (s *Store) partitions(projectID string, from, to *time.Time) ([]partition, error) {
+	projectDirs := []string{"project=" + url.PathEscape(projectID)}
+	if projectID == "" {
+		entries, err := os.ReadDir(s.dir)
+		if err != nil {
+			return nil, err
+		}
+		projectDirs = projectDirs[:0]
+		for _, entry := range entries {
+			if entry.IsDir() && strings.HasPrefix(entry.Name(), "project=") {
+				projectDirs = append(projectDirs, entry.Name())
+			}
+		}
+	}
+
+	var first, last time.Time
+	if from != nil {
+		first = truncateDay(*from)
+	}
+	if to != nil {
+		last = truncateDay(*to)
+	}
+
+	var partitions []partition
+	for _, projectDir := range projectDirs {
+		id, err := url.PathUnescape(strings.TrimPrefix(projectDir, "project="))
+		if err != nil {
+			continue
+		}
+		entries, err := os.ReadDir(filepath.Join(s.dir, projectDir))
+		if errors.Is(err, fs.ErrNotExist) {
+			continue
+		}
+		if err != nil {
+			return nil, err
+		}
+
+		for _, entry := range entries {
+			date, ok := strings.CutPrefix(entry.Name(), "date=")
+			if !entry.IsDir() || !ok {
+				continue
+			}
+			day, err := time.Parse(dateLayout, date)
+			if err != nil || (from != nil && day.Before(first)) || (to != nil && day.After(last)) {
+				continue
+			}
+			partitions = append(partitions, partition{
+				projectID: id,
+				day:       day,
+				dir:       filepath.Join(s.dir, projectDir, entry.Name()),
+			})
+		}
+	}
+
+	sort.SliceStable(partitions, func(i, j int) bool {
+		return partitions[i].day.Before(partitions[j].day)
+	})
+	return partitions, nil
+}
+
+// partitionOf returns the partition holding a project's logs of a time
+func (s *Store) partitionOf(projectID string, t time.Time) partition {
+	day := truncateDay(t)
+	return partition{
+		projectID: projectID,
+		day:       day,
+		dir:       filepath.Join(s.dir, "project="+url.PathEscape(projectID), "date="+day.Format(dateLayout)),
+	}
+}
+
+// readFile reads the rows of a cold file. A file removed since it was listed
+// reads as empty.
+func readFile(name string) ([]coldLog, error) {
+	rows, err := parquet.ReadFile[coldLog](name)
+	if errors.Is(err, fs.ErrNotExist) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("cold storage: read %s: %w", name, err)
+	}
+	return rows, nil
+}
+
+// fileInfo returns the number of rows and the size of a cold file
+func fileInfo(name string) (int64, int64, error) {
+	file, err := os.Open(name)
+	if err != nil {
+		return 0, 0, err
+	}
+	defer file.Close()
+
+	stat, err := file.Stat()
+	if err != nil {
+		return 0, 0, err
+	}
+	pf, err := parquet.OpenFile(file, stat.Size(), parquet.SkipPageIndex(true), parquet.SkipBloomFilters(true))
+	if err != nil {
+		return 0, 0, fmt.Errorf("cold storage: open %s: %w", name, err)
+	}
+	return pf.NumRows(), stat.Size(), nil
+}
+
+// mayContain reports whether a cold file may hold any of the IDs, by its
+// bloom filters. A false positive only costs reading the file.
+func mayContain(name string, ids []string) (bool, error) {
+	file, err := os.Open(name)
+	if errors.Is(err, fs.ErrNotExist) {
+		return false, nil
+	}
+	if err != nil {
+		return false, err
+	}
+	defer file.Close()
+
+	stat, err := file.Stat()
+	if err != nil {
+		return false, err
+	}
+	pf, err := parquet.OpenFile(file, stat.Size(), parquet.SkipPageIndex(true))
+	if err != nil {
+		return false, fmt.Errorf("cold storage: open %s: %w", name, err)
+	}
+	column, ok := pf.Schema().Lookup("id")
+	if !ok {
+		return true, nil
+	}
+
+	for _, rowGroup := range pf.RowGroups() {
+		filter := rowGroup.ColumnChunks()[column.ColumnIndex].BloomFilter()
+		if filter == nil {
+			return true, nil
+		}
+		for _, id := range ids {
+			found, err := filter.Check(parquet.ValueOf(id))
+			if err != nil {
+				return false, err
+			}
+			if found {
+				return true, nil
+			}
+		}
+	}
+	return false, nil
+}
+
+// writeFile replaces a cold file with rows, through a temporary file renamed
+// into place, removing it when rows is empty
+func writeFile(name string, rows []coldLog) error {
+	dir := filepath.Dir(name)
+	if len(rows) == 0 {
+		if err := os.Remove(name); err != nil && !errors.Is(err, fs.ErrNotExist) {
+			return err
+		}
+		// Drop the partition once its last file is gone; a partition still
+		// holding files is not removed
+		_ = os.Remove(dir)
+		return nil
+	}
+
+	if err := os.MkdirAll(dir, 0o750); err != nil {
+		return err
+	}
+	tmp, err := os.CreateTemp(dir, ".tmp-*")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(tmp.Name())
+
+	err = parquet.Write(tmp, rows,
+		parquet.Compression(&parquet.Zstd),
+		parquet.BloomFilters(parquet.SplitBlockFilter(10, "id")),
+	)
+	if err == nil {
+		err = tmp.Sync()
+	}
+	if closeErr := tmp.Close(); err == nil {
+		err = closeErr
+	}
+	if err != nil {
+		return fmt.Errorf("cold storage: write %s: %w", name, err)
+	}
+	return os.Rename(tmp.Name(), name)
+}
+
+// write stores logs of a project's day as one file. The file is named after
+// the logs' IDs, so writing the same logs again replaces it.
+func (s *Store) write(projectID string, day time.Time, logs []*domain.APILog) error {
+	if len(logs) == 0 {
+		return nil
+	}
+
+	hash := sha256.New()
+	rows := make([]coldLog, len(logs))
+	for i, log := range logs {
+		rows[i] = toColdLog(log)
+		hash.Write([]byte(log.ID))
+		hash.Write([]byte{0})
+	}
+	name := filepath.Join(s.partitionOf(projectID, day).dir, "logs-"+hex.EncodeToString(hash.Sum(nil)[:8])+fileExt)
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return writeFile(name, rows)
+}
+
+// scan calls fn with the rows of each file of the partitions, in order
+func scan(ctx context.Context, partitions []partition, fn func(p partition, name string, rows []coldLog) error) error {
+	for _, p := range partitions {
+		names, err := p.files()
+		if err != nil {
+			return err
+		}
+		for _, name := range names {
+			if err := ctx.Err(); err != nil {
+				return err
+			}
+			rows, err := readFile(name)
+			if err != nil {
+				return err
+			}
+			if err := fn(p, name, rows); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+// reaches returns the partitions a filter reaches
+func (s *Store) reaches(filter domain.LogFilter) ([]partition, error) {
+	return s.partitions(filter.ProjectID, filter.FromDate, filter.ToDate)
+}
+
+// find returns the cold logs matching a filter, newest first, ignoring its
+// pagination. Days are read newest first until at least need logs are found;
+// a non-positive need reads them all.
+func (s *Store) find(ctx context.Context, filter domain.LogFilter, need int) ([]*domain.APILog, error) {
+	partitions, err := s.reaches(filter)
+	if err != nil {
+		return nil, err
+	}
+
+	var logs []*domain.APILog
+	for end := len(partitions); end > 0; {
+		// Read every project's partition of the day before checking need
+		start := end - 1
+		for start > 0 && partitions[start-1].day.Equal(partitions[end-1].day) {
+			start--
+		}
+
+		var day []*domain.APILog
+		err := scan(ctx, partitions[start:end], func(_ partition, _ string, rows []coldLog) error {
+			for i := range rows {
+				if log := rows[i].toDomain(); filter.Matches(log) {
+					day = append(day, log)
+				}
+			}
+			return nil
+		})
+		if err != nil {
+			return nil, err
+		}
+		sort.SliceStable(day, func(i, j int) bool {
+			return day[i].Timestamp.After(day[j].Timestamp)
+		})
+		logs = append(logs, day...)
+
+		if need > 0 && len(logs) >= need {
+			break
+		}
+		end = start
+	}
+	return logs, nil
+}
+
+// count counts the cold logs matching a filter. Partitions the filter selects
+// whole are counted from the files' metadata.
+func (s *Store) count(ctx context.Context, filter domain.LogFilter) (int64, error) {
+	partitions, err := s.reaches(filter)
+	if err != nil {
+		return 0, err
+	}
+
+	// Without criteria on the logs themselves, a partition within the date
+	// range matches whole
+	criteria := filter
+	criteria.SharedFilter, criteria.ProjectID, criteria.FromDate, criteria.ToDate = domain.SharedFilter{}, "", nil, nil
+	selectsWhole := criteria == domain.LogFilter{}
+
+	var total int64
+	for _, p := range partitions {
+		if selectsWhole && p.within(filter.FromDate, filter.ToDate) {
+			names, err := p.files()
+			if err != nil {
+				return 0, err
+			}
+			for _, name := range names {
+				rows, _, err := fileInfo(name)
+				if err != nil && !errors.Is(err, fs.ErrNotExist) {
+					return 0, err
+				}
+				total += rows
+			}
+			continue
+		}
+
+		err := scan(ctx, []partition{p}, func(_ partition, _ string, rows []coldLog) error {
+			for i := range rows {
+				if filter.Matches(rows[i].toDomain()) {
+					total++
+				}
+			}
+			return nil
+		})
+		if err != nil {
+			return 0, err
+		}
+	}
+	return total, nil
+}
+
+// findByID returns a cold log by ID, newest partitions first
+func (s *Store) findByID(ctx context.Context, id string) (*domain.APILog, error) {
+	partitions, err := s.partitions("", nil, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	for i := len(partitions) - 1; i >= 0; i-- {
+		names, err := partitions[i].files()
+		if err != nil {
+			return nil, err
+		}
+		for _, name := range names {
+			if err := ctx.Err(); err != nil {
+				return nil, err
+			}
+			if ok, err := mayContain(name, []string{id}); err != nil || !ok {
+				if err != nil {
+					return nil, err
+				}
+				continue
+			}
+
+			rows, err := readFile(name)
+			if err != nil {
+				return nil, err
+			}
+			for j := range rows {
+				if rows[j].ID == id {
+					return rows[j].toDomain(), nil
+				}
+			}
+		}
+	}
+	return nil, domain.ErrLogNotFound
+}
+
+// existing returns which of the logs are already in the cold tier. Only the
+// partitions of the logs' own days are searched, which exist only for logs
+// older than the tiering age.
+func (s *Store) existing(ctx context.Context, logs []*domain.APILog) (map[string]bool, error) {
+	byPartition := make(map[string][]string)
+	for _, log := range logs {
+		dir := s.partitionOf(log.ProjectID, log.Timestamp).dir
+		byPartition[dir] = append(byPartition[dir], log.ID)
+	}
+
+	found := make(map[string]bool)
+	for dir, ids := range byPartition {
+		names, err := partition{dir: dir}.files()
+		if err != nil {
+			return nil, err
+		}
+		for _, name := range names {
+			if err := ctx.Err(); err != nil {
+				return nil, err
+			}
+			if ok, err := mayContain(name, ids); err != nil || !ok {
+				if err != nil {
+					return nil, err
+				}
+				continue
+			}
+
+			rows, err := readFile(name)
+			if err != nil {
+				return nil, err
+			}
+			wanted := make(map[string]bool, len(ids))
+			for _, id := range ids {
+				wanted[id] = true
+			}
+			for i := range rows {
+				if wanted[rows[i].ID] {
+					found[rows[i].ID] = true
+				}
+			}
+		}
+	}
+	return found, nil
+}
+
+// removeRows removes the rows with the given IDs from a file, returning how many
+// were removed
+func removeRows(name string, ids map[string]bool) (int, error) {
+	rows, err := readFile(name)
+	if err != nil {
+		return 0, err
+	}
+
+	kept := rows[:0]
+	for _, row := range rows {
+		if !ids[row.ID] {
+			kept = append(kept, row)
+		}
+	}
+	removed := len(rows) - len(kept)
+	if removed == 0 {
+		return 0, nil
+	}
+	return removed, writeFile(name, kept)
+}
+
+// delete removes a cold log by ID
+func (s *Store) delete(ctx context.Context, id string) error {
+	partitions, err := s.partitions("", nil, nil)
+	if err != nil {
+		return err
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	for i := len(partitions) - 1; i >= 0; i-- {
+		names, err := partitions[i].files()
+		if err != nil {
+			return err
+		}
+		for _, name := range names {
+			if err := ctx.Err(); err != nil {
+				return err
+			}
+			if ok, err := mayContain(name, []string{id}); err != nil || !ok {
+				if err != nil {
+					return err
+				}
+				continue
+			}
+
+			removed, err := removeRows(name, map[string]bool{id: true})
+			if err != nil {
+				return err
+			}
+			if removed > 0 {
+				return nil
+			}
+		}
+	}
+	return domain.ErrLogNotFound
+}
+
+// deleteOldest removes the cold logs with the given IDs, expecting them to be
+// the oldest of their project, as FindOlderThan returns them. Each project's
+// days are searched oldest first, up to the first day holding none of them.
+func (s *Store) deleteOldest(ctx context.Context, ids []string) error {
+	if len(ids) == 0 {
+		return nil
+	}
+	partitions, err := s.partitions("", nil, nil)
+	if err != nil {
+		return err
+	}
+
+	remaining := make(map[string]bool, len(ids))
+	for _, id := range ids {
+		remaining[id] = true
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	done := make(map[string]bool)
+	for _, p := range partitions {
+		if done[p.projectID] {
+			continue
+		}
+		names, err := p.files()
+		if err != nil {
+			return err
+		}
+
+		hit := false
+		for _, name := range names {
+			if err := ctx.Err(); err != nil {
+				return err
+			}
+			pending := make([]string, 0, len(remaining))
+			for id := range remaining {
+				pending = append(pending, id)
+			}
+			if ok, err := mayContain(name, pending); err != nil || !ok {
+				if err != nil {
+					return err
+				}
+				continue
+			}
+
+			rows, err := readFile(name)
+			if err != nil {
+				return err
+			}
+			matched := make(map[string]bool)
+			for i := range rows {
+				if remaining[rows[i].ID] {
+					matched[rows[i].ID] = true
+				}
+			}
+			if len(matched) == 0 {
+				continue
+			}
+			if _, err := removeRows(name, matched); err != nil {
+				return err
+			}
+			for id := range matched {
+				delete(remaining, id)
+			}
+			hit = true
+		}
+
+		if len(remaining) == 0 {
+			return nil
+		}
+		if !hit {
+			done[p.projectID] = true
+		}
+	}
+	return nil
+}
+
+// olderThan returns the partitions holding a project's logs before a time
+func (s *Store) olderThan(projectID string, before time.Time) ([]partition, error) {
+	if projectID == "" {
+		// Logs always belong to a project
+		return nil, nil
+	}
+	return s.partitions(projectID, nil, &before)
+}
+
+// findOlderThan returns at most limit of a project's cold logs before a time,
+// oldest first
+func (s *Store) findOlderThan(ctx context.Context, projectID string, before time.Time, limit int) ([]*domain.APILog, error) {
+	partitions, err := s.olderThan(projectID, before)
+	if err != nil {
+		return nil, err
+	}
+
+	var logs []*domain.APILog
+	for _, p := range partitions {
+		var day []*domain.APILog
+		err := scan(ctx, []partition{p}, func(_ partition, _ string, rows []coldLog) error {
+			for i := range rows {
+				if rows[i].Timestamp.Before(before) {
+					day = append(day, rows[i].toDomain())
+				}
+			}
+			return nil
+		})
+		if err != nil {
+			return nil, err
+		}
+		sort.SliceStable(day, func(i, j int) bool {
+			return day[i].Timestamp.Before(day[j].Timestamp)
+		})
+		logs = append(logs, day...)
+
+		if len(logs) >= limit {
+			return logs[:limit], nil
+		}
+	}
+	return logs, nil
+}
+
+// deleteOlderThan removes at most limit of a project's cold logs before a
+// time. Files holding only such logs are removed whole.
+func (s *Store) deleteOlderThan(ctx context.Context, projectID string, before time.Time, limit int) (int64, error) {
+	partitions, err := s.olderThan(projectID, before)
+	if err != nil {
+		return 0, err
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	var removed int64
+	err = scan(ctx, partitions, func(_ partition, name string, rows []coldLog) error {
+		budget := int64(limit) - removed
+		if budget <= 0 {
+			return nil
+		}
+
+		kept := rows[:0]
+		for _, row := range rows {
+			if budget > 0 && row.Timestamp.Before(before) {
+				budget--
+				continue
+			}
+			kept = append(kept, row)
+		}
+		if len(kept) == len(rows) {
+			return nil
+		}
+
+		removed += int64(len(rows) - len(kept))
+		return writeFile(name, kept)
+	})
+	return removed, err
+}
+
+// volumeOlderThan measures a project's cold logs before a time. The bytes of a
+// file partly before it are its share of the file's size.
+func (s *Store) volumeOlderThan(ctx context.Context, projectID string, before time.Time) (domain.DataVolume, error) {
+	var volume domain.DataVolume
+	partitions, err := s.olderThan(projectID, before)
+	if err != nil {
+		return volume, err
+	}
+
+	for _, p := range partitions {
+		names, err := p.files()
+		if err != nil {
+			return volume, err
+		}
+		for _, name := range names {
+			if err := ctx.Err(); err != nil {
+				return volume, err
+			}
+			total, size, err := fileInfo(name)
+			if errors.Is(err, fs.ErrNotExist) || total == 0 {
+				continue
+			}
+			if err != nil {
+				return volume, err
+			}
+
+			expired := total
+			if p.end().After(before) {
+				rows, err := readFile(name)
+				if err != nil {
+					return volume, err
+				}
+				expired = 0
+				for i := range rows {
+					if rows[i].Timestamp.Before(before) {
+						expired++
+					}
+				}
+			}
+			volume.Documents += expired
+			volume.Bytes += size * expired / total
+		}
+	}
+	return volume, nil
+}
+
+// truncateDay returns the start of the UTC day of t
+func truncateDay(t time.Time) time.Time {
+	return t.UTC().Truncate(24 * time.Hour)
+}
@@ -0,0 +1,74 @@
+package tiered
+
+import (
+	"context"
+	"testing"
+
+	"github.com/spidey52/api-logs/internal/adapters/secondary/repository/contract"
+	"github.com/spidey52/api-logs/internal/adapters/secondary/repository/inmemory"
+	"github.com/spidey52/api-logs/internal/domain"
+	"github.com/spidey52/api-logs/internal/ports/output"
+)
+
+// movingRepository moves every log stored so far to the cold tier after every
+// nth write, so the contract runs against logs spread over both tiers
+type movingRepository struct {
+	output.APILogRepository
+	mover  *Mover
+	every  int
+	writes int
+}
+
+func (r *movingRepository) Create(ctx context.Context, log *domain.APILog) error {
+	if err := r.APILogRepository.Create(ctx, log); err != nil {
+		return err
+	}
+	return r.moved(ctx, log)
+}
+
+func (r *movingRepository) CreateMany(ctx context.Context, logs []*domain.APILog) error {
+	if err := r.APILogRepository.CreateMany(ctx, logs); err != nil {
+		return err
+	}
+	return r.moved(ctx, logs...)
+}
+
+func (r *movingRepository) moved(ctx context.Context, logs ...*domain.APILog) error {
+	if r.writes++; r.writes%r.every != 0 {
+		return nil
+	}
+	for _, log := range logs {
+		if _, err := r.mover.MoveProject(ctx, log.ProjectID); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func newMovingRepository(t *testing.T, every int) output.APILogRepository {
+	cold, err := NewStore(t.TempDir())
+	if err != nil {
+		t.Fatal(err)
+	}
+	hot := inmemory.NewAPILogRepository()
+
+	return &movingRepository{
+		APILogRepository: NewAPILogRepository(hot, cold),
+		mover:            NewMover(hot, cold, nil, 0, 2),
+		every:            every,
+	}
+}
+
+func TestAPILogRepository(t *testing.T) {
+	t.Run("Cold", func(t *testing.T) {
+		contract.RunAPILogRepository(t, func(t *testing.T) output.APILogRepository {
+			return newMovingRepository(t, 1)
+		})
+	})
+
+	t.Run("Mixed", func(t *testing.T) {
+		contract.RunAPILogRepository(t, func(t *testing.T) output.APILogRepository {
+			return newMovingRepository(t, 2)
+		})
+	})
+}
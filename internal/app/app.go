@@ -5,10 +5,19 @@ import (
 	"net/http"
 
 	"github.com/spidey52/api-logs/pkg/config"
+	"github.com/spidey52/api-logs/pkg/logger"
 )
 
 type App struct {
 	Server *http.Server
+
+	services *Services
+}
+
+// Drain stores the logs still queued for ingestion. Call it once the server
+// has stopped accepting requests and before cleanup closes the storage.
+func (a *App) Drain(ctx context.Context) error {
+	return a.services.Ingest.Drain(ctx)
 }
 
 func New(cfg *config.Config) (*App, func(context.Context) error, error) {
@@ -18,12 +27,23 @@ func New(cfg *config.Config) (*App, func(context.Context) error, error) {
 		return nil, nil, err
 	}
 
-	// init http server
-	server := newHTTPServer(cfg, infra)
+	// init services
+	services, err := newServices(cfg, infra)
+	if err != nil {
+		_ = infraCleanup(context.Background())
+		return nil, nil, err
+	}
+
+	// init http server and background jobs
+	server := newHTTPServer(cfg, services)
+	workers := startWorkers(cfg, infra, services)
 
 	cleanup := func(ctx context.Context) error {
+		if err := workers.stop(ctx); err != nil {
+			logger.Error("background jobs did not stop in time", "error", err)
+		}
 		return infraCleanup(ctx)
 	}
 
-	return &App{Server: server}, cleanup, nil
+	return &App{Server: server, services: services}, cleanup, nil
 }
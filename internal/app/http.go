@@ -6,31 +6,21 @@ import (
 
 	"github.com/gin-gonic/gin"
 	httpHandler "github.com/spidey52/api-logs/internal/adapters/primary/http"
-	"github.com/spidey52/api-logs/internal/adapters/primary/service"
-	"github.com/spidey52/api-logs/internal/adapters/secondary/repository/mongodb"
 	"github.com/spidey52/api-logs/pkg/config"
 )
 
-func newHTTPServer(cfg *config.Config, infra *Infrastructure) *http.Server {
-	// repositories
-	projectRepo := mongodb.NewProjectRepository(infra.Mongo)
-	logRepo := mongodb.NewAPILogRepository(infra.Mongo, infra.Cache)
-	headersRepo := mongodb.NewHeadersRepository(infra.Mongo)
-	bodyRepo := mongodb.NewBodyRepository(infra.Mongo)
-	userRepo := mongodb.NewUserRepository(infra.Mongo)
-	accessLogRepo := mongodb.NewMongoAccessLogRepository(infra.Mongo)
-
-	// services
-	projectService := service.NewProjectService(projectRepo)
-	logService := service.NewAPILogService(logRepo, headersRepo, bodyRepo, userRepo)
-	userService := service.NewUserService(userRepo)
-	accessLogService := service.NewAccessLogService(accessLogRepo)
-
+func newHTTPServer(cfg *config.Config, services *Services) *http.Server {
 	// handlers
-	projectHandler := httpHandler.NewProjectHandler(projectService)
-	apiLogHandler := httpHandler.NewAPILogHandler(logService, projectService, userService)
-	userHandler := httpHandler.NewUserHandler(userService)
-	accessLogHandler := httpHandler.NewAccessLogHandler(accessLogService)
+	projectHandler := httpHandler.NewProjectHandler(services.Projects)
+	apiLogHandler := httpHandler.NewAPILogHandler(services.Logs, services.Projects, services.Auth, services.Quotas, services.Ingest)
+	userHandler := httpHandler.NewUserHandler(services.Users)
+	accessLogHandler := httpHandler.NewAccessLogHandler(services.AccessLogs)
+	authHandler := httpHandler.NewAuthHandler(services.Auth)
+	quotaHandler := httpHandler.NewQuotaHandler(services.Quotas)
+	redactionHandler := httpHandler.NewRedactionHandler(services.Redaction)
+	retentionHandler := httpHandler.NewRetentionHandler(services.Retention)
+	archiveHandler := httpHandler.NewArchiveHandler(services.Archive)
+	healthHandler := httpHandler.NewHealthHandler(services.Ingest)
 
 	if cfg.App.IsProductionMode() {
 		gin.SetMode(gin.ReleaseMode)
@@ -45,6 +35,14 @@ func newHTTPServer(cfg *config.Config, infra *Infrastructure) *http.Server {
 		APILogHandler:    apiLogHandler,
 		UserHandler:      userHandler,
 		AccessLogHandler: accessLogHandler,
+		AuthHandler:      authHandler,
+		QuotaHandler:     quotaHandler,
+		RedactionHandler: redactionHandler,
+		RetentionHandler: retentionHandler,
+		ArchiveHandler:   archiveHandler,
+		HealthHandler:    healthHandler,
+
+		MaxIngestBodySize: int64(cfg.Ingest.MaxBodySizeMB) << 20,
 	})
 
 	return &http.Server{
@@ -61,7 +59,7 @@ func corsMiddleware() gin.HandlerFunc {
 	return func(c *gin.Context) {
 		c.Writer.Header().Set("Access-Control-Allow-Origin", "*")
 		c.Writer.Header().Set("Access-Control-Allow-Credentials", "true")
-		c.Writer.Header().Set("Access-Control-Allow-Headers", "Content-Type, Content-Length, Accept-Encoding, X-CSRF-Token, Authorization, accept, origin, Cache-Control, X-Requested-With, X-API-Key, X-Environment")
+		c.Writer.Header().Set("Access-Control-Allow-Headers", "Content-Type, Content-Length, Accept-Encoding, X-CSRF-Token, Authorization, accept, origin, Cache-Control, X-Requested-With, X-API-Key, X-Environment, X-Project-ID, Idempotency-Key")
 		c.Writer.Header().Set("Access-Control-Allow-Methods", "POST, OPTIONS, GET, PUT, DELETE, PATCH")
 
 		if c.Request.Method == "OPTIONS" {
@@ -2,49 +2,242 @@ package app
 
 import (
 	"context"
+	"database/sql"
+	"fmt"
 	"time"
 
+	"github.com/jackc/pgx/v5/pgxpool"
 	"github.com/redis/go-redis/v9"
+	"github.com/spidey52/api-logs/internal/adapters/secondary/repository/encrypted"
 	"github.com/spidey52/api-logs/internal/adapters/secondary/repository/mongodb"
+	"github.com/spidey52/api-logs/internal/adapters/secondary/repository/postgres"
+	"github.com/spidey52/api-logs/internal/adapters/secondary/repository/sqlite"
+	"github.com/spidey52/api-logs/internal/adapters/secondary/repository/tiered"
+	"github.com/spidey52/api-logs/internal/ports/output"
+	"github.com/spidey52/api-logs/pkg/archive"
 	"github.com/spidey52/api-logs/pkg/cache"
 	"github.com/spidey52/api-logs/pkg/config"
+	"github.com/spidey52/api-logs/pkg/envelope"
 	"github.com/spidey52/api-logs/pkg/logger"
+	"github.com/spidey52/api-logs/pkg/wal"
 )
 
 type Infrastructure struct {
-	Mongo *mongodb.Client
-	Cache cache.Cache
+	Mongo        *mongodb.Client
+	Postgres     *pgxpool.Pool
+	SQLite       *sql.DB
+	Cache        cache.Cache
+	Repositories *Repositories
+
+	// Spool holds accepted logs until they are stored; nil when disabled
+	Spool *wal.Log
+
+	// Archive receives expired data before retention removes it; nil when
+	// archiving is disabled
+	Archive *archive.Archive
+
+	// Tiering moves old logs to the cold tier; nil when tiering is disabled
+	Tiering *tiered.Mover
+
+	// Decrypter opens the headers and bodies stored encrypted; nil when
+	// encryption is disabled
+	Decrypter output.LogDecrypter
 }
 
-func newInfrastructure(cfg *config.Config) (*Infrastructure, func(context.Context) error, error) {
+func newInfrastructure(cfg *config.Config) (_ *Infrastructure, _ func(context.Context) error, err error) {
 	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
 	defer cancel()
 
-	mongo, err := mongodb.NewClient(ctx, cfg.MongoDB.URI, cfg.MongoDB.Database)
+	cacheClient, closeCache, err := newCache(cfg.Cache)
 	if err != nil {
 		return nil, nil, err
 	}
 
-	if err := mongo.CreateIndexes(ctx); err != nil {
-		return nil, nil, err
+	infra := &Infrastructure{
+		Cache: cacheClient,
 	}
 
-	cacheClient := cache.NewRedisCache(&redis.Options{
-		Addr: "localhost:6379",
-	})
-
 	cleanup := func(ctx context.Context) error {
-		if err := mongo.Close(ctx); err != nil {
-			logger.Error("mongo close failed", "error", err)
+		if infra.Spool != nil {
+			if err := infra.Spool.Close(); err != nil {
+				logger.Error("spool close failed", "error", err)
+			}
+		}
+		if infra.Mongo != nil {
+			if err := infra.Mongo.Close(ctx); err != nil {
+				logger.Error("mongo close failed", "error", err)
+			}
+		}
+		if infra.Postgres != nil {
+			infra.Postgres.Close()
 		}
-		if err := cacheClient.Close(); err != nil {
-			logger.Error("redis close failed", "error", err)
+		if infra.SQLite != nil {
+			if err := infra.SQLite.Close(); err != nil {
+				logger.Error("sqlite close failed", "error", err)
+			}
+		}
+		if err := closeCache(); err != nil {
+			logger.Error("cache close failed", "error", err)
 		}
 		return nil
 	}
 
-	return &Infrastructure{
-		Mongo: mongo,
-		Cache: cacheClient,
-	}, cleanup, nil
+	// Release the connections opened so far when a later step fails
+	defer func() {
+		if err != nil {
+			_ = cleanup(context.Background())
+		}
+	}()
+
+	switch cfg.Storage.Backend {
+	case config.StorageBackendMongo:
+		mongo, err := mongodb.NewClient(ctx, cfg.MongoDB.URI, cfg.MongoDB.Database)
+		if err != nil {
+			return nil, nil, err
+		}
+		infra.Mongo = mongo
+
+		if err := mongo.CreateIndexes(ctx); err != nil {
+			return nil, nil, err
+		}
+
+		infra.Repositories = newMongoRepositories(mongo, cacheClient)
+
+	case config.StorageBackendPostgres:
+		pool, err := postgres.NewPool(ctx, cfg.Postgres.URI)
+		if err != nil {
+			return nil, nil, err
+		}
+		infra.Postgres = pool
+
+		if err := postgres.Migrate(ctx, pool); err != nil {
+			return nil, nil, err
+		}
+
+		infra.Repositories = newPostgresRepositories(pool)
+
+	case config.StorageBackendSQLite:
+		db, err := sqlite.Open(ctx, cfg.SQLite.Path)
+		if err != nil {
+			return nil, nil, err
+		}
+		infra.SQLite = db
+
+		if err := sqlite.Migrate(ctx, db); err != nil {
+			return nil, nil, err
+		}
+
+		infra.Repositories = newSQLiteRepositories(db)
+
+	case config.StorageBackendMemory:
+		infra.Repositories = newInMemoryRepositories()
+
+	default:
+		return nil, nil, fmt.Errorf("unsupported storage backend %q", cfg.Storage.Backend)
+	}
+
+	logger.Info("storage backend initialized", "backend", cfg.Storage.Backend)
+
+	keys, err := enableEncryption(ctx, cfg.Encrypt, infra.Repositories)
+	if err != nil {
+		return nil, nil, err
+	}
+	if keys != nil {
+		infra.Decrypter = keys
+	}
+
+	if cfg.Tiering.AfterDays > 0 {
+		cold, err := tiered.NewStore(cfg.Tiering.Dir)
+		if err != nil {
+			return nil, nil, fmt.Errorf("open cold storage: %w", err)
+		}
+		age := time.Duration(cfg.Tiering.AfterDays) * 24 * time.Hour
+		infra.Tiering = tiered.NewMover(infra.Repositories.Logs, cold, infra.Repositories.Projects, age, cfg.Tiering.BatchSize)
+		infra.Repositories.Logs = tiered.NewAPILogRepository(infra.Repositories.Logs, cold)
+		logger.Info("cold storage of old logs enabled", "dir", cfg.Tiering.Dir, "after_days", cfg.Tiering.AfterDays)
+	}
+
+	if cfg.Spool.Enabled {
+		spool, err := wal.Open(cfg.Spool.Dir, wal.Options{
+			SegmentSize: int64(cfg.Spool.SegmentSizeMB) << 20,
+			MaxSize:     int64(cfg.Spool.MaxSizeMB) << 20,
+			// A spooled batch is about the size of the request it came from
+			MaxRecordSize: max(wal.DefaultMaxRecordSize, 2*int64(cfg.Ingest.MaxBodySizeMB)<<20),
+		})
+		if err != nil {
+			return nil, nil, fmt.Errorf("open ingest spool: %w", err)
+		}
+		infra.Spool = spool
+	}
+
+	if cfg.Archive.Enabled {
+		sink, err := archive.NewFileSink(cfg.Archive.Dir)
+		if err != nil {
+			return nil, nil, fmt.Errorf("open archive: %w", err)
+		}
+		infra.Archive = archive.New(sink)
+		logger.Info("archiving of expired data enabled", "dir", cfg.Archive.Dir)
+	}
+
+	return infra, cleanup, nil
+}
+
+// enableEncryption wraps the headers and body repositories so they store
+// encrypted values, when master keys are configured, and re-wraps the data
+// keys of a retired master key with the active one
+func enableEncryption(ctx context.Context, cfg config.EncryptConfig, repos *Repositories) (*encrypted.Keys, error) {
+	masterKeys, err := envelope.ParseMasterKeys(cfg.MasterKeys)
+	if err != nil {
+		return nil, err
+	}
+	if cfg.Keyfile != "" {
+		fileKeys, err := envelope.ReadKeyfile(cfg.Keyfile)
+		if err != nil {
+			return nil, fmt.Errorf("read encryption keyfile: %w", err)
+		}
+		masterKeys = append(masterKeys, fileKeys...)
+	}
+	if len(masterKeys) == 0 {
+		return nil, nil
+	}
+
+	keyring, err := envelope.NewKeyring(masterKeys...)
+	if err != nil {
+		return nil, err
+	}
+	keys := encrypted.NewKeys(repos.DataKeys, keyring)
+
+	rewrapped, err := keys.Rewrap(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("rewrap data keys: %w", err)
+	}
+	if rewrapped > 0 {
+		logger.Info("Re-wrapped data keys with the active master key", "keys", rewrapped, "master_key", keyring.ActiveKeyID())
+	}
+
+	repos.Headers = encrypted.NewHeadersRepository(repos.Headers, keys)
+	repos.Bodies = encrypted.NewBodyRepository(repos.Bodies, keys)
+	logger.Info("encryption of captured headers and bodies enabled", "master_key", keyring.ActiveKeyID())
+	return keys, nil
+}
+
+// newCache builds the configured cache and a function that releases it
+func newCache(cfg config.CacheConfig) (cache.Cache, func() error, error) {
+	switch cfg.Backend {
+	case config.CacheBackendRedis:
+		redisCache := cache.NewRedisCache(&redis.Options{
+			Addr: cfg.RedisAddr,
+		})
+		return redisCache, redisCache.Close, nil
+
+	case config.CacheBackendMemory:
+		memoryCache := cache.NewMemoryCache()
+		return memoryCache, func() error {
+			memoryCache.Close()
+			return nil
+		}, nil
+
+	default:
+		return nil, nil, fmt.Errorf("unsupported cache backend %q", cfg.Backend)
+	}
 }
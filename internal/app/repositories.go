@@ -0,0 +1,112 @@
+package app
+
+import (
+	"database/sql"
+
+	"github.com/jackc/pgx/v5/pgxpool"
+	"github.com/spidey52/api-logs/internal/adapters/secondary/repository/inmemory"
+	"github.com/spidey52/api-logs/internal/adapters/secondary/repository/mongodb"
+	"github.com/spidey52/api-logs/internal/adapters/secondary/repository/postgres"
+	"github.com/spidey52/api-logs/internal/adapters/secondary/repository/sqlite"
+	"github.com/spidey52/api-logs/internal/ports/output"
+	"github.com/spidey52/api-logs/pkg/cache"
+)
+
+// Repositories groups the secondary adapters for the selected storage backend
+type Repositories struct {
+	Projects   output.ProjectRepository
+	Logs       output.APILogRepository
+	Headers    output.APILogHeadersRepository
+	Bodies     output.APILogBodyRepository
+	Users      output.UserRepository
+	AccessLogs output.AccessLogRepository
+	Accounts   output.AccountRepository
+	Sessions   output.SessionRepository
+	Members    output.ProjectMemberRepository
+	APIKeys    output.APIKeyRepository
+	Quotas     output.QuotaRepository
+
+	RedactionPolicies output.RedactionPolicyRepository
+	DataKeys          output.DataKeyRepository
+	Summaries         output.LogSummaryRepository
+}
+
+func newMongoRepositories(client *mongodb.Client, cacheClient cache.Cache) *Repositories {
+	return &Repositories{
+		Projects:   mongodb.NewProjectRepository(client),
+		Logs:       mongodb.NewAPILogRepository(client, cacheClient),
+		Headers:    mongodb.NewHeadersRepository(client),
+		Bodies:     mongodb.NewBodyRepository(client),
+		Users:      mongodb.NewUserRepository(client),
+		AccessLogs: mongodb.NewMongoAccessLogRepository(client),
+		Accounts:   mongodb.NewAccountRepository(client),
+		Sessions:   mongodb.NewSessionRepository(client),
+		Members:    mongodb.NewProjectMemberRepository(client),
+		APIKeys:    mongodb.NewAPIKeyRepository(client),
+		Quotas:     mongodb.NewQuotaRepository(client),
+
+		RedactionPolicies: mongodb.NewRedactionPolicyRepository(client),
+		DataKeys:          mongodb.NewDataKeyRepository(client),
+		Summaries:         mongodb.NewLogSummaryRepository(client),
+	}
+}
+
+func newPostgresRepositories(pool *pgxpool.Pool) *Repositories {
+	return &Repositories{
+		Projects:   postgres.NewProjectRepository(pool),
+		Logs:       postgres.NewAPILogRepository(pool),
+		Headers:    postgres.NewAPILogHeadersRepository(pool),
+		Bodies:     postgres.NewAPILogBodyRepository(pool),
+		Users:      postgres.NewUserRepository(pool),
+		AccessLogs: postgres.NewAccessLogRepository(pool),
+		Accounts:   postgres.NewAccountRepository(pool),
+		Sessions:   postgres.NewSessionRepository(pool),
+		Members:    postgres.NewProjectMemberRepository(pool),
+		APIKeys:    postgres.NewAPIKeyRepository(pool),
+		Quotas:     postgres.NewQuotaRepository(pool),
+
+		RedactionPolicies: postgres.NewRedactionPolicyRepository(pool),
+		DataKeys:          postgres.NewDataKeyRepository(pool),
+		Summaries:         postgres.NewLogSummaryRepository(pool),
+	}
+}
+
+func newSQLiteRepositories(db *sql.DB) *Repositories {
+	return &Repositories{
+		Projects:   sqlite.NewProjectRepository(db),
+		Logs:       sqlite.NewAPILogRepository(db),
+		Headers:    sqlite.NewAPILogHeadersRepository(db),
+		Bodies:     sqlite.NewAPILogBodyRepository(db),
+		Users:      sqlite.NewUserRepository(db),
+		AccessLogs: sqlite.NewAccessLogRepository(db),
+		Accounts:   sqlite.NewAccountRepository(db),
+		Sessions:   sqlite.NewSessionRepository(db),
+		Members:    sqlite.NewProjectMemberRepository(db),
+		APIKeys:    sqlite.NewAPIKeyRepository(db),
+		Quotas:     sqlite.NewQuotaRepository(db),
+
+		RedactionPolicies: sqlite.NewRedactionPolicyRepository(db),
+		DataKeys:          sqlite.NewDataKeyRepository(db),
+		Summaries:         sqlite.NewLogSummaryRepository(db),
+	}
+}
+
+func newInMemoryRepositories() *Repositories {
+	return &Repositories{
+		Projects:   inmemory.NewProjectRepository(),
+		Logs:       inmemory.NewAPILogRepository(),
+		Headers:    inmemory.NewHeadersRepository(),
+		Bodies:     inmemory.NewBodyRepository(),
+		Users:      inmemory.NewUserRepository(),
+		AccessLogs: inmemory.NewAccessLogRepository(),
+		Accounts:   inmemory.NewAccountRepository(),
+		Sessions:   inmemory.NewSessionRepository(),
+		Members:    inmemory.NewProjectMemberRepository(),
+		APIKeys:    inmemory.NewAPIKeyRepository(),
+		Quotas:     inmemory.NewQuotaRepository(),
+
+		RedactionPolicies: inmemory.NewRedactionPolicyRepository(),
+		DataKeys:          inmemory.NewDataKeyRepository(),
+		Summaries:         inmemory.NewLogSummaryRepository(),
+	}
+}
@@ -0,0 +1,221 @@
+package app
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/spidey52/api-logs/internal/adapters/primary/service"
+	"github.com/spidey52/api-logs/internal/domain"
+	"github.com/spidey52/api-logs/internal/ports/input"
+	"github.com/spidey52/api-logs/pkg/config"
+	"github.com/spidey52/api-logs/pkg/logger"
+)
+
+// Services groups the primary ports, shared by the HTTP handlers and the
+// background workers
+type Services struct {
+	Projects   input.ProjectService
+	Logs       input.APILogService
+	Users      input.UserService
+	AccessLogs input.AccessLogService
+	Auth       input.AuthService
+	Quotas     input.QuotaService
+	Redaction  input.RedactionService
+	Retention  input.RetentionService
+	Archive    input.ArchiveService
+	Ingest     input.IngestService
+	Rollups    input.RollupService
+}
+
+func newServices(cfg *config.Config, infra *Infrastructure) (*Services, error) {
+	repos := infra.Repositories
+
+	hasher, err := newKeyHasher(cfg)
+	if err != nil {
+		return nil, err
+	}
+	if err := hashPlaintextAPIKeys(repos, hasher); err != nil {
+		return nil, err
+	}
+
+	authorizer := service.NewAuthorizer(repos.Members, repos.AccessLogs)
+	rollups := service.NewRollupService(repos.Logs, repos.Summaries, repos.Projects, service.RollupOptions{
+		MinuteRetention: cfg.Rollup.MinuteRetention,
+		HourRetention:   cfg.Rollup.HourRetention,
+	})
+	projects := service.NewProjectService(repos.Projects, repos.Members, repos.Accounts, repos.APIKeys, authorizer, hasher, service.ProjectData{
+		Logs:              repos.Logs,
+		Headers:           repos.Headers,
+		Bodies:            repos.Bodies,
+		AccessLogs:        repos.AccessLogs,
+		Summaries:         repos.Summaries,
+		Users:             repos.Users,
+		Quotas:            repos.Quotas,
+		RedactionPolicies: repos.RedactionPolicies,
+		DataKeys:          repos.DataKeys,
+		BatchSize:         cfg.Retention.BatchSize,
+	}, cfg.Auth.APIKeyRotationGrace)
+	services := &Services{
+		Projects:   projects,
+		Logs:       service.NewAPILogService(repos.Logs, repos.Headers, repos.Bodies, repos.Users, authorizer, infra.Decrypter, rollups),
+		Users:      service.NewUserService(repos.Users, authorizer),
+		AccessLogs: service.NewAccessLogService(repos.AccessLogs, authorizer),
+		Auth:       service.NewAuthService(repos.Accounts, repos.Sessions, cfg.Auth.SessionTTL),
+		Quotas:     service.NewQuotaService(repos.Quotas, repos.Projects, repos.APIKeys, infra.Cache, defaultQuota(cfg.Quota)),
+		Rollups:    rollups,
+	}
+
+	services.Redaction, err = service.NewRedactionService(repos.RedactionPolicies, repos.Projects, authorizer, defaultRedactionPolicy(cfg.Redact))
+	if err != nil {
+		return nil, err
+	}
+
+	services.Retention, err = service.NewRetentionService(repos.Projects, repos.Logs, repos.Headers, repos.Bodies, repos.AccessLogs, authorizer, service.RetentionOptions{
+		Defaults: domain.RetentionPolicy{
+			LogsDays:       cfg.Retention.LogsDays,
+			HeadersDays:    cfg.Retention.HeadersDays,
+			BodiesDays:     cfg.Retention.BodiesDays,
+			AccessLogsDays: cfg.Retention.AccessLogsDays,
+		},
+		Interval:  cfg.Retention.Interval,
+		BatchSize: cfg.Retention.BatchSize,
+		Archive:   infra.Archive,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	services.Archive = service.NewArchiveService(infra.Archive, repos.Projects, services.Logs, authorizer, infra.Decrypter)
+
+	services.Ingest = service.NewIngestService(services.Logs, services.Redaction, services.Quotas, authorizer, infra.Spool, infra.Cache, service.IngestOptions{
+		QueueSize:     cfg.Ingest.QueueSize,
+		Workers:       cfg.Ingest.Workers,
+		BatchSize:     cfg.Ingest.BatchSize,
+		FlushInterval: cfg.Ingest.FlushInterval,
+		DedupWindow:   cfg.Ingest.DedupWindow,
+		Normalize: service.NormalizeOptions{
+			Methods:               cfg.Ingest.Methods,
+			MaxPathLength:         cfg.Ingest.MaxPathLength,
+			MaxUserAgentLength:    cfg.Ingest.MaxUserAgentLength,
+			MaxErrorMessageLength: cfg.Ingest.MaxErrorMessageLength,
+			MaxParamLength:        cfg.Ingest.MaxParamLength,
+			MaxHeaderLength:       cfg.Ingest.MaxHeaderLength,
+			MaxBodySize:           cfg.Ingest.MaxStoredBodySizeKB * 1024,
+			LowercaseHeaders:      cfg.Ingest.LowercaseHeaders,
+			TemplatePaths:         cfg.Ingest.TemplatePaths,
+		},
+	})
+
+	if err := bootstrapAdmin(cfg, services.Auth, repos); err != nil {
+		return nil, err
+	}
+	if err := backfillRollups(cfg, services.Rollups); err != nil {
+		return nil, err
+	}
+
+	return services, nil
+}
+
+// defaultQuota converts the configured limits for projects without a quota of
+// their own
+func defaultQuota(cfg config.QuotaConfig) domain.ProjectQuota {
+	return domain.ProjectQuota{
+		RequestsPerSecond:    int64(cfg.RequestsPerSecond),
+		LogsPerDay:           int64(cfg.LogsPerDay),
+		KeyRequestsPerSecond: int64(cfg.KeyRequestsPerSecond),
+		KeyLogsPerDay:        int64(cfg.KeyLogsPerDay),
+	}
+}
+
+// defaultRedactionPolicy converts the configured policy for projects without
+// a policy of their own
+func defaultRedactionPolicy(cfg config.RedactConfig) domain.RedactionPolicy {
+	return domain.RedactionPolicy{
+		Headers:     cfg.Headers,
+		QueryParams: cfg.QueryParams,
+		JSONPaths:   cfg.JSONPaths,
+		Patterns:    cfg.Patterns,
+		Regexes:     cfg.Regexes,
+	}
+}
+
+// bootstrapAdmin creates the configured admin account (or grants an existing
+// account of that name admin), so the management API is reachable once every
+// route requires a login
+func bootstrapAdmin(cfg *config.Config, authService input.AuthService, repos *Repositories) error {
+	ctx, cancel := context.WithTimeout(domain.SystemContext(context.Background()), 10*time.Second)
+	defer cancel()
+
+	if cfg.Auth.AdminUsername != "" && cfg.Auth.AdminPassword != "" {
+		if err := authService.EnsureAdminAccount(ctx, cfg.Auth.AdminUsername, cfg.Auth.AdminPassword); err != nil {
+			return fmt.Errorf("bootstrap admin account: %w", err)
+		}
+		return nil
+	}
+
+	accounts, err := repos.Accounts.List(ctx)
+	if err != nil {
+		return err
+	}
+	if len(accounts) == 0 {
+		logger.Warn("no admin accounts exist; set ADMIN_USERNAME and ADMIN_PASSWORD to create one")
+	}
+	return nil
+}
+
+// backfillRollups marks the configured span of every project's logs for the
+// rollup worker, so summaries missed while the server was down, or written
+// before summaries were kept, are recomputed
+func backfillRollups(cfg *config.Config, rollups input.RollupService) error {
+	if cfg.Rollup.Backfill <= 0 {
+		return nil
+	}
+
+	ctx, cancel := context.WithTimeout(domain.SystemContext(context.Background()), 10*time.Second)
+	defer cancel()
+
+	if err := rollups.Backfill(ctx, time.Now().Add(-cfg.Rollup.Backfill)); err != nil {
+		return fmt.Errorf("backfill log summaries: %w", err)
+	}
+	return nil
+}
+
+// developmentAPIKeySecret keys API key hashes when API_KEY_SECRET is unset
+// outside production, so local setups work without extra configuration
+const developmentAPIKeySecret = "api-logs-development-secret"
+
+// newKeyHasher returns the hasher API keys are stored under; production
+// deployments must configure their own secret
+func newKeyHasher(cfg *config.Config) (*service.KeyHasher, error) {
+	if cfg.Auth.APIKeySecret != "" {
+		return service.NewKeyHasher(cfg.Auth.APIKeySecret), nil
+	}
+	if cfg.App.IsProductionMode() {
+		return nil, fmt.Errorf("API_KEY_SECRET is required in production")
+	}
+
+	logger.Warn("API_KEY_SECRET is not set; using the development secret for API key hashes")
+	return service.NewKeyHasher(developmentAPIKeySecret), nil
+}
+
+// hashPlaintextAPIKeys converts API keys stored before keys were hashed. It is
+// a no-op once every key has been converted.
+func hashPlaintextAPIKeys(repos *Repositories, hasher *service.KeyHasher) error {
+	ctx, cancel := context.WithTimeout(domain.SystemContext(context.Background()), time.Minute)
+	defer cancel()
+
+	projects, err := repos.Projects.HashPlaintextAPIKeys(ctx, hasher.Hash)
+	if err != nil {
+		return fmt.Errorf("hash project API keys: %w", err)
+	}
+	keys, err := repos.APIKeys.HashPlaintextKeys(ctx, hasher.Hash)
+	if err != nil {
+		return fmt.Errorf("hash named API keys: %w", err)
+	}
+
+	if projects > 0 || keys > 0 {
+		logger.Info("Hashed plaintext API keys", "projects", projects, "keys", keys)
+	}
+	return nil
+}
@@ -0,0 +1,117 @@
+package app
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"github.com/spidey52/api-logs/internal/domain"
+	"github.com/spidey52/api-logs/pkg/config"
+	"github.com/spidey52/api-logs/pkg/logger"
+)
+
+// workers runs periodic background jobs until stopped
+type workers struct {
+	ctx    context.Context
+	cancel context.CancelFunc
+	wg     sync.WaitGroup
+}
+
+func startWorkers(cfg *config.Config, infra *Infrastructure, services *Services) *workers {
+	// Jobs act on every project, as the system
+	ctx, cancel := context.WithCancel(domain.SystemContext(context.Background()))
+	w := &workers{ctx: ctx, cancel: cancel}
+
+	w.every("revoke expired API keys", cfg.Auth.APIKeySweepInterval, func(ctx context.Context) error {
+		revoked, err := services.Projects.RevokeExpiredAPIKeys(ctx)
+		if revoked > 0 {
+			logger.Info("Revoked expired API keys", "count", revoked)
+		}
+		return err
+	})
+
+	w.every("replay ingest spool", cfg.Spool.ReplayInterval, func(ctx context.Context) error {
+		stored, err := services.Ingest.Replay(ctx)
+		if stored > 0 {
+			logger.Info("Replayed spooled logs", "count", stored)
+		}
+		return err
+	})
+
+	w.every("purge expired data", cfg.Retention.Interval, func(ctx context.Context) error {
+		purged, err := services.Retention.Purge(ctx)
+		if purged > 0 {
+			logger.Info("Purged expired data", "count", purged)
+		}
+		return err
+	})
+
+	w.every("refresh log summaries", cfg.Rollup.Interval, func(ctx context.Context) error {
+		_, err := services.Rollups.Refresh(ctx)
+		return err
+	})
+
+	w.every("purge expired log summaries", cfg.Retention.Interval, func(ctx context.Context) error {
+		purged, err := services.Rollups.Purge(ctx)
+		if purged > 0 {
+			logger.Info("Purged expired log summaries", "count", purged)
+		}
+		return err
+	})
+
+	if infra.Tiering != nil {
+		w.every("move logs to cold storage", cfg.Tiering.Interval, func(ctx context.Context) error {
+			moved, err := infra.Tiering.Move(ctx)
+			if moved > 0 {
+				logger.Info("Moved logs to cold storage", "count", moved)
+			}
+			return err
+		})
+	}
+
+	return w
+}
+
+// every runs job on a fixed interval; a non-positive interval disables it
+func (w *workers) every(name string, interval time.Duration, job func(ctx context.Context) error) {
+	if interval <= 0 {
+		return
+	}
+
+	w.wg.Add(1)
+	go func() {
+		defer w.wg.Done()
+
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+
+		for {
+			select {
+			case <-w.ctx.Done():
+				return
+			case <-ticker.C:
+				if err := job(w.ctx); err != nil && w.ctx.Err() == nil {
+					logger.Error("background job failed", "job", name, "error", err)
+				}
+			}
+		}
+	}()
+}
+
+// stop cancels the jobs and waits for running ones to return, or for ctx to end
+func (w *workers) stop(ctx context.Context) error {
+	w.cancel()
+
+	done := make(chan struct{})
+	go func() {
+		w.wg.Wait()
+		close(done)
+	}()
+
+	select {
+	case <-done:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
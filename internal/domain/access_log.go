@@ -108,6 +108,9 @@ type AccessLogFilter struct {
 	Outcome *AccessOutcome `json:"outcome"`
 	Search  string         `json:"search"`
 
+	// Metadata matches logs whose metadata contains all of these key/value pairs
+	Metadata map[string]any `json:"metadata" form:"-"`
+
 	FromDate *time.Time `json:"from_date"`
 	ToDate   *time.Time `json:"to_date"`
 }
@@ -0,0 +1,62 @@
+package domain
+
+import (
+	"strings"
+	"time"
+)
+
+// MinPasswordLength is the shortest password accepted for an account
+const MinPasswordLength = 8
+
+// Account is a dashboard login for the management API. It is unrelated to
+// User, which describes the end users recorded on API logs. Admin accounts
+// can reach every project; other accounts need a ProjectMember role.
+type Account struct {
+	ID           string     `json:"id" bson:"_id"`
+	Username     string     `json:"username" bson:"username"`
+	PasswordHash string     `json:"-" bson:"password_hash"`
+	IsActive     bool       `json:"is_active" bson:"is_active"`
+	IsAdmin      bool       `json:"is_admin" bson:"is_admin"`
+	CreatedAt    time.Time  `json:"created_at" bson:"created_at"`
+	UpdatedAt    time.Time  `json:"updated_at" bson:"updated_at"`
+	LastLoginAt  *time.Time `json:"last_login_at,omitempty" bson:"last_login_at,omitempty"`
+}
+
+// NormalizeUsername trims and lower-cases a username so lookups are case-insensitive
+func NormalizeUsername(username string) string {
+	return strings.ToLower(strings.TrimSpace(username))
+}
+
+// Validate validates the account
+func (a *Account) Validate() error {
+	if len(a.Username) < 3 {
+		return ErrInvalidUsername
+	}
+	if a.PasswordHash == "" {
+		return ErrWeakPassword
+	}
+	return nil
+}
+
+// ValidatePassword checks a plaintext password before it is hashed
+func ValidatePassword(password string) error {
+	if len(password) < MinPasswordLength {
+		return ErrWeakPassword
+	}
+	return nil
+}
+
+// Session is a bearer token issued to an account at login. Only the SHA-256
+// of the token is stored, so a leaked database does not leak live tokens.
+type Session struct {
+	ID        string    `json:"id" bson:"_id"`
+	AccountID string    `json:"account_id" bson:"account_id"`
+	TokenHash string    `json:"-" bson:"token_hash"`
+	CreatedAt time.Time `json:"created_at" bson:"created_at"`
+	ExpiresAt time.Time `json:"expires_at" bson:"expires_at"`
+}
+
+// IsExpired reports whether the session is no longer valid at t
+func (s *Session) IsExpired(t time.Time) bool {
+	return !t.Before(s.ExpiresAt)
+}
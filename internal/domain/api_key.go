@@ -0,0 +1,127 @@
+package domain
+
+import (
+	"errors"
+	"strings"
+	"time"
+)
+
+// APIKeyScope limits which routes an API key can call
+type APIKeyScope string
+
+const (
+	// ScopeIngest allows writing logs
+	ScopeIngest APIKeyScope = "ingest"
+	// ScopeRead allows reading logs, headers and bodies
+	ScopeRead APIKeyScope = "read"
+	// ScopeStats allows reading aggregated stats
+	ScopeStats APIKeyScope = "stats"
+)
+
+// AllAPIKeyScopes lists every scope; the project's legacy key holds all of them
+var AllAPIKeyScopes = []APIKeyScope{ScopeIngest, ScopeRead, ScopeStats}
+
+// Validate validates the scope value
+func (s APIKeyScope) Validate() error {
+	switch s {
+	case ScopeIngest, ScopeRead, ScopeStats:
+		return nil
+	default:
+		return ErrInvalidScope
+	}
+}
+
+// MaxRotationGracePeriod bounds how long a project's previous key keeps working after rotation
+const MaxRotationGracePeriod = 30 * 24 * time.Hour
+
+// RotatedKeyName names the key that keeps a project's previous key valid
+// during a rotation's grace period
+const RotatedKeyName = "previous project key"
+
+// APIKeyRotation is the result of rotating a project's key. The previous key
+// stays valid, as a named key with every scope, until PreviousKeyExpiresAt.
+type APIKeyRotation struct {
+	APIKey               string     `json:"api_key"`
+	APIKeyPrefix         string     `json:"api_key_prefix"`
+	PreviousKeyID        string     `json:"previous_key_id,omitempty"`
+	PreviousKeyPrefix    string     `json:"previous_key_prefix,omitempty"`
+	PreviousKeyExpiresAt *time.Time `json:"previous_key_expires_at,omitempty"`
+}
+
+// keyPrefixChars is how much of a key's random part stays visible in its prefix
+const keyPrefixChars = 4
+
+// KeyPrefix returns the visible start of a plaintext key: its environment
+// prefix and the first few random characters, e.g. "prod_ab12"
+func KeyPrefix(key string) string {
+	n := strings.IndexByte(key, '_') + 1 + keyPrefixChars
+	if n > len(key) {
+		n = len(key)
+	}
+	return key[:n]
+}
+
+// APIKey is a named, scoped credential for a project. A project can hold any
+// number of keys, e.g. an ingest-only key embedded in services and a read key
+// for tooling. Like the project key, only a keyed hash and a visible prefix are
+// stored; Key holds the plaintext only in the response that creates it.
+type APIKey struct {
+	ID         string        `json:"id" bson:"_id"`
+	ProjectID  string        `json:"project_id" bson:"project_id"`
+	Name       string        `json:"name" bson:"name"`
+	Key        string        `json:"key,omitempty" bson:"-"`
+	KeyHash    string        `json:"-" bson:"key_hash"`
+	Prefix     string        `json:"prefix" bson:"prefix"`
+	Scopes     []APIKeyScope `json:"scopes" bson:"scopes"`
+	ExpiresAt  *time.Time    `json:"expires_at,omitempty" bson:"expires_at,omitempty"`
+	LastUsedAt *time.Time    `json:"last_used_at,omitempty" bson:"last_used_at,omitempty"`
+	RevokedAt  *time.Time    `json:"revoked_at,omitempty" bson:"revoked_at,omitempty"`
+	CreatedAt  time.Time     `json:"created_at" bson:"created_at"`
+}
+
+// Validate validates the API key
+func (k *APIKey) Validate() error {
+	if k.ProjectID == "" {
+		return errors.New("project_id is required")
+	}
+	if k.Name == "" {
+		return errors.New("key name is required")
+	}
+	if k.KeyHash == "" {
+		return errors.New("key hash is required")
+	}
+	if len(k.Scopes) == 0 {
+		return ErrInvalidScope
+	}
+	for _, scope := range k.Scopes {
+		if err := scope.Validate(); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// HasScope reports whether the key grants the scope
+func (k *APIKey) HasScope(scope APIKeyScope) bool {
+	for _, s := range k.Scopes {
+		if s == scope {
+			return true
+		}
+	}
+	return false
+}
+
+// IsExpired reports whether the key has expired at t
+func (k *APIKey) IsExpired(t time.Time) bool {
+	return k.ExpiresAt != nil && !t.Before(*k.ExpiresAt)
+}
+
+// IsRevoked reports whether the key has been revoked
+func (k *APIKey) IsRevoked() bool {
+	return k.RevokedAt != nil
+}
+
+// IsUsable reports whether the key can authenticate requests at t
+func (k *APIKey) IsUsable(t time.Time) bool {
+	return !k.IsRevoked() && !k.IsExpired(t)
+}
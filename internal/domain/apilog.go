@@ -16,8 +16,17 @@ const (
 	MethodDELETE  HTTPMethod = "DELETE"
 	MethodOPTIONS HTTPMethod = "OPTIONS"
 	MethodHEAD    HTTPMethod = "HEAD"
+	MethodCONNECT HTTPMethod = "CONNECT"
+	MethodTRACE   HTTPMethod = "TRACE"
 )
 
+// StandardMethods are the methods defined by HTTP, which ingestion accepts
+// unless configured otherwise
+var StandardMethods = []HTTPMethod{
+	MethodGET, MethodPOST, MethodPUT, MethodPATCH, MethodDELETE,
+	MethodOPTIONS, MethodHEAD, MethodCONNECT, MethodTRACE,
+}
+
 // String returns the string representation of the HTTP method
 func (m HTTPMethod) String() string {
 	return string(m)
@@ -71,6 +80,10 @@ type APILogHeaders struct {
 	RequestHeaders  map[string]any `json:"request_headers"`
 	ResponseHeaders map[string]any `json:"response_headers"`
 	CreatedAt       time.Time      `json:"created_at"`
+
+	// ProjectID is the project of the log, stored with the headers so they can
+	// be encrypted with the project's data key and purged by its retention
+	ProjectID string `json:"-"`
 }
 
 // Validate validates the headers
@@ -88,6 +101,10 @@ type APILogBody struct {
 	RequestBody  any       `json:"request_body,omitempty"`
 	ResponseBody any       `json:"response_body,omitempty"`
 	CreatedAt    time.Time `json:"created_at"`
+
+	// ProjectID is the project of the log, stored with the body so it can be
+	// encrypted with the project's data key and purged by its retention
+	ProjectID string `json:"-"`
 }
 
 // Validate validates the body
@@ -97,3 +114,24 @@ func (b *APILogBody) Validate() error {
 	}
 	return nil
 }
+
+// LogEntry is a log with its optional headers and body, ingested together as
+// one item of a batch
+type LogEntry struct {
+	Log     *APILog        `json:"log"`
+	Headers *APILogHeaders `json:"headers,omitempty"`
+	Body    *APILogBody    `json:"body,omitempty"`
+
+	// UserIdentifier, when set, resolves the log's user by identifier within
+	// the project, creating the user (named UserName) if it does not exist
+	UserIdentifier string `json:"user_identifier,omitempty"`
+	UserName       string `json:"user_name,omitempty"`
+
+	// IdempotencyKey, when set and Log has no ID, derives the log's ID, so
+	// retries of the same log are stored once
+	IdempotencyKey string `json:"idempotency_key,omitempty"`
+
+	// RejectedFields lists the fields ingestion dropped or truncated while
+	// accepting the entry
+	RejectedFields []FieldError `json:"-"`
+}
@@ -0,0 +1,52 @@
+package domain
+
+import (
+	"fmt"
+	"time"
+)
+
+// MaxRestoreDays bounds the days of archive a single restore reads
+const MaxRestoreDays = 31
+
+// ArchivedObject is an object of a project's archive, holding one class of
+// its data for one UTC day
+type ArchivedObject struct {
+	Key   string    `json:"key"`
+	Class DataClass `json:"class"`
+	Day   time.Time `json:"day"`
+}
+
+// ArchiveRestore selects the archived logs restored into a project
+type ArchiveRestore struct {
+	// SourceProjectID is the project whose archive is read
+	SourceProjectID string
+	// ProjectID is the project the logs are restored into
+	ProjectID string
+	// From and To are the first and last UTC days restored
+	From time.Time
+	To   time.Time
+}
+
+// Validate validates the restore range
+func (r ArchiveRestore) Validate() error {
+	if r.SourceProjectID == "" || r.ProjectID == "" {
+		return fmt.Errorf("%w: source and target projects are required", ErrInvalidArchiveRange)
+	}
+	if r.From.IsZero() || r.To.IsZero() || r.To.Before(r.From) {
+		return fmt.Errorf("%w: from must be a day on or before to", ErrInvalidArchiveRange)
+	}
+	if r.To.Sub(r.From) >= MaxRestoreDays*24*time.Hour {
+		return fmt.Errorf("%w: at most %d days can be restored at once", ErrInvalidArchiveRange, MaxRestoreDays)
+	}
+	return nil
+}
+
+// RestoreResult reports what a restore stored. Logs restored before are
+// counted again, but stored once.
+type RestoreResult struct {
+	Objects int `json:"objects"`
+	Logs    int `json:"logs"`
+	Headers int `json:"headers"`
+	Bodies  int `json:"bodies"`
+	Failed  int `json:"failed"`
+}
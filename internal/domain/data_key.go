@@ -0,0 +1,29 @@
+package domain
+
+import "time"
+
+// DataKey is the key a project's captured headers and bodies are encrypted
+// with. It is stored wrapped by a master key, so rotating the master key only
+// re-wraps the data key.
+type DataKey struct {
+	ProjectID string `json:"project_id" bson:"_id"`
+	// MasterKeyID names the master key WrappedKey is encrypted with
+	MasterKeyID string    `json:"master_key_id" bson:"master_key_id"`
+	WrappedKey  []byte    `json:"-" bson:"wrapped_key"`
+	CreatedAt   time.Time `json:"created_at" bson:"created_at"`
+	UpdatedAt   time.Time `json:"updated_at" bson:"updated_at"`
+}
+
+// EncryptedField is the only field of the object an encrypted header map or
+// body is stored as: {"_encrypted": "<version>:<ciphertext>"}
+const EncryptedField = "_encrypted"
+
+// IsEncrypted reports whether a stored header map or body is encrypted
+func IsEncrypted(value any) bool {
+	m, ok := value.(map[string]any)
+	if !ok || len(m) != 1 {
+		return false
+	}
+	_, ok = m[EncryptedField].(string)
+	return ok
+}
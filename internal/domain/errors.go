@@ -21,15 +21,71 @@ var (
 	// ErrUnauthorized is returned when a request is unauthorized
 	ErrUnauthorized = errors.New("unauthorized")
 
+	// ErrForbidden is returned when the caller lacks the role required for an action
+	ErrForbidden = errors.New("forbidden")
+
 	// ErrInvalidInput is returned when input validation fails
 	ErrInvalidInput = errors.New("invalid input")
 
 	// ErrDuplicateAPIKey is returned when an API key already exists
 	ErrDuplicateAPIKey = errors.New("api key already exists")
 
+	// ErrAPIKeyNotFound is returned when a named API key is not found
+	ErrAPIKeyNotFound = errors.New("api key not found")
+
+	// ErrInvalidScope is returned when an API key scope is missing or unknown
+	ErrInvalidScope = errors.New("scopes must be one or more of ingest, read, stats")
+
+	// ErrInsufficientScope is returned when an API key lacks the scope a route requires
+	ErrInsufficientScope = errors.New("api key does not have the required scope")
+
 	// ErrInvalidEnvironment is returned when environment is invalid
 	ErrInvalidEnvironment = errors.New("invalid environment")
 
+	// ErrAccessLogNotFound is returned when an access log is not found
+	ErrAccessLogNotFound = errors.New("access log not found")
+
+	// Account and session related errors
+	ErrAccountNotFound    = errors.New("account not found")
+	ErrDuplicateUsername  = errors.New("username already exists")
+	ErrInvalidUsername    = errors.New("username must be at least 3 characters")
+	ErrWeakPassword       = errors.New("password must be at least 8 characters")
+	ErrInvalidCredentials = errors.New("invalid username or password")
+	ErrSessionNotFound    = errors.New("session not found")
+
+	// Project membership related errors
+	ErrMemberNotFound   = errors.New("project member not found")
+	ErrDuplicateMember  = errors.New("account is already a member of the project")
+	ErrInvalidRole      = errors.New("role must be owner, editor or viewer")
+	ErrLastProjectOwner = errors.New("project must keep at least one owner")
+
+	// Ingestion related errors
+	ErrIngestQueueFull = errors.New("ingest queue is full")
+	ErrIngestStopped   = errors.New("ingest pipeline is shutting down")
+	ErrIngestSpoolFull = errors.New("ingest spool is full")
+	ErrIngestTooLarge  = errors.New("ingest batch is too large to spool")
+	ErrDuplicateLog    = errors.New("log was already ingested")
+
+	// Quota related errors
+	ErrQuotaNotFound = errors.New("quota not found")
+	ErrInvalidQuota  = errors.New("quota limits must not be negative")
+	ErrQuotaExceeded = errors.New("quota exceeded")
+
+	// Redaction policy related errors
+	ErrRedactionPolicyNotFound = errors.New("redaction policy not found")
+	ErrInvalidRedactionPolicy  = errors.New("invalid redaction policy")
+
+	// Retention related errors
+	ErrInvalidRetentionPolicy = errors.New("invalid retention policy")
+
+	// Archive related errors
+	ErrArchiveDisabled     = errors.New("archiving is not enabled")
+	ErrInvalidArchiveRange = errors.New("invalid archive range")
+
+	// Data key related errors
+	ErrDataKeyNotFound  = errors.New("data key not found")
+	ErrDuplicateDataKey = errors.New("data key already exists")
+
 	// User related errors
 	ErrUserNotFound            = errors.New("user not found")
 	ErrInvalidUserName         = errors.New("user name is required")
@@ -1,6 +1,9 @@
 package domain
 
-import "time"
+import (
+	"strings"
+	"time"
+)
 
 type SharedFilter struct {
 	Limit  int
@@ -33,11 +36,67 @@ func (f *SharedFilter) ApplyDefaults() {
 	}
 }
 
+// Matches reports whether a log meets the filter criteria, for storage that
+// filters logs in memory. Path and Search match case-insensitive substrings,
+// and an exact status code takes precedence over the status range.
+func (f LogFilter) Matches(log *APILog) bool {
+	if f.ProjectID != "" && log.ProjectID != f.ProjectID {
+		return false
+	}
+	if f.Environment != "" && log.Environment != f.Environment {
+		return false
+	}
+	if f.Method != "" && log.Method != f.Method {
+		return false
+	}
+
+	// Handle status code filtering (exact, min/max range)
+	if f.StatusCode != nil {
+		if log.StatusCode != *f.StatusCode {
+			return false
+		}
+	} else {
+		if f.StatusCodeMin != nil && log.StatusCode < *f.StatusCodeMin {
+			return false
+		}
+		if f.StatusCodeMax != nil && log.StatusCode > *f.StatusCodeMax {
+			return false
+		}
+	}
+
+	if f.Path != "" && !containsFold(log.Path, f.Path) {
+		return false
+	}
+	if f.Search != "" &&
+		!containsFold(log.Path, f.Search) &&
+		!containsFold(log.UserAgent, f.Search) &&
+		!containsFold(log.IPAddress, f.Search) {
+		return false
+	}
+	if f.FromDate != nil && log.Timestamp.Before(*f.FromDate) {
+		return false
+	}
+	if f.ToDate != nil && log.Timestamp.After(*f.ToDate) {
+		return false
+	}
+	if f.UserID != "" && (log.UserID == nil || *log.UserID != f.UserID) {
+		return false
+	}
+	return true
+}
+
+// containsFold reports whether substr is within s, ignoring case
+func containsFold(s, substr string) bool {
+	return strings.Contains(strings.ToLower(s), strings.ToLower(substr))
+}
+
 // ProjectFilter represents filtering criteria for querying projects
 type ProjectFilter struct {
 	SharedFilter
 	Environment Environment
 	IsActive    *bool
+	// IDs restricts the result to these projects when non-empty
+	IDs []string
 }
 
 // ApplyDefaults sets default values for pagination
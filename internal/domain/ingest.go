@@ -0,0 +1,19 @@
+package domain
+
+// IngestStats describes the logs accepted for ingestion but not yet stored
+type IngestStats struct {
+	// QueuedLogs counts logs waiting in memory for a writer
+	QueuedLogs int64 `json:"queued_logs"`
+	// Spool is nil when the on-disk spool is disabled
+	Spool *SpoolStats `json:"spool,omitempty"`
+}
+
+// SpoolStats describes the on-disk spool of accepted batches
+type SpoolStats struct {
+	// Batches counts spooled batches not yet stored
+	Batches int `json:"batches"`
+	// Bytes is the size of the spool on disk
+	Bytes int64 `json:"bytes"`
+	// OldestAgeSeconds is the age of the oldest unstored batch, 0 when empty
+	OldestAgeSeconds float64 `json:"oldest_age_seconds"`
+}
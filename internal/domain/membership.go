@@ -0,0 +1,66 @@
+package domain
+
+import (
+	"errors"
+	"time"
+)
+
+// ProjectRole is an account's role within a single project
+type ProjectRole string
+
+const (
+	// RoleViewer can read a project's logs, stats and access logs
+	RoleViewer ProjectRole = "viewer"
+	// RoleEditor can additionally write logs and update the project
+	RoleEditor ProjectRole = "editor"
+	// RoleOwner can additionally manage members, API keys and delete the project
+	RoleOwner ProjectRole = "owner"
+)
+
+// roleRank orders roles so that a higher role includes every lower one
+var roleRank = map[ProjectRole]int{
+	RoleViewer: 1,
+	RoleEditor: 2,
+	RoleOwner:  3,
+}
+
+// Validate validates the role
+func (r ProjectRole) Validate() error {
+	if _, ok := roleRank[r]; !ok {
+		return ErrInvalidRole
+	}
+	return nil
+}
+
+// Allows reports whether the role grants at least the required role
+func (r ProjectRole) Allows(required ProjectRole) bool {
+	rank, ok := roleRank[r]
+	return ok && rank >= roleRank[required]
+}
+
+// String returns the string representation of the role
+func (r ProjectRole) String() string {
+	return string(r)
+}
+
+// ProjectMember grants an account a role on a project
+type ProjectMember struct {
+	ID        string      `json:"id" bson:"_id"`
+	ProjectID string      `json:"project_id" bson:"project_id"`
+	AccountID string      `json:"account_id" bson:"account_id"`
+	Username  string      `json:"username,omitempty" bson:"-"`
+	Role      ProjectRole `json:"role" bson:"role"`
+	CreatedAt time.Time   `json:"created_at" bson:"created_at"`
+	UpdatedAt time.Time   `json:"updated_at" bson:"updated_at"`
+}
+
+// Validate validates the membership
+func (m *ProjectMember) Validate() error {
+	if m.ProjectID == "" {
+		return errors.New("project_id is required")
+	}
+	if m.AccountID == "" {
+		return errors.New("account_id is required")
+	}
+	return m.Role.Validate()
+}
@@ -0,0 +1,72 @@
+package domain
+
+import "context"
+
+// Principal identifies the caller of a service method. Services read it from
+// the context to authorize project access; a context without a principal is
+// denied, so background jobs act as the SystemPrincipal.
+type Principal struct {
+	ActorID   string
+	ActorType ActorType
+
+	// Account is set when a dashboard account is calling
+	Account *Account
+
+	// ProjectID is set when an API key is calling; a key only reaches its own project
+	ProjectID string
+}
+
+// AccountPrincipal returns the principal for a signed-in dashboard account
+func AccountPrincipal(account *Account) *Principal {
+	return &Principal{
+		ActorID:   account.ID,
+		ActorType: ActorUser,
+		Account:   account,
+	}
+}
+
+// APIKeyPrincipal returns the principal for a request authenticated with one of a project's API keys
+func APIKeyPrincipal(project *Project, key *APIKey) *Principal {
+	return &Principal{
+		ActorID:   key.ID,
+		ActorType: ActorAPIKey,
+		ProjectID: project.ID,
+	}
+}
+
+// SystemPrincipal returns the principal of the server's own background jobs,
+// such as workers storing accepted logs or purging expired data
+func SystemPrincipal() *Principal {
+	return &Principal{
+		ActorID:   "system",
+		ActorType: ActorSystem,
+	}
+}
+
+// IsAdmin reports whether the principal is an admin account, which bypasses project roles
+func (p *Principal) IsAdmin() bool {
+	return p.Account != nil && p.Account.IsAdmin
+}
+
+// IsSystem reports whether the principal is the server itself, which bypasses project roles
+func (p *Principal) IsSystem() bool {
+	return p.ActorType == ActorSystem
+}
+
+type principalKey struct{}
+
+// ContextWithPrincipal returns a copy of ctx carrying the principal
+func ContextWithPrincipal(ctx context.Context, principal *Principal) context.Context {
+	return context.WithValue(ctx, principalKey{}, principal)
+}
+
+// SystemContext returns a copy of ctx carrying the SystemPrincipal
+func SystemContext(ctx context.Context) context.Context {
+	return ContextWithPrincipal(ctx, SystemPrincipal())
+}
+
+// PrincipalFromContext returns the principal stored in ctx, if any
+func PrincipalFromContext(ctx context.Context) (*Principal, bool) {
+	principal, ok := ctx.Value(principalKey{}).(*Principal)
+	return principal, ok && principal != nil
+}
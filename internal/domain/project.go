@@ -5,16 +5,26 @@ import (
 	"time"
 )
 
-// Project represents a project that generates API logs
+// Project represents a project that generates API logs.
+//
+// Only a keyed hash of the project's API key is stored, together with its
+// visible prefix (e.g. "prod_ab12"). APIKey holds the plaintext key only in
+// the response that creates or regenerates it.
 type Project struct {
-	ID          string      `json:"id" bson:"_id,omitempty"`
-	Name        string      `json:"name" bson:"name"`
-	Description string      `json:"description" bson:"description,omitempty"`
-	APIKey      string      `json:"api_key" bson:"api_key"`
-	Environment Environment `json:"environment" bson:"environment"`
-	IsActive    bool        `json:"is_active" bson:"is_active"`
-	CreatedAt   time.Time   `json:"created_at" bson:"created_at"`
-	UpdatedAt   time.Time   `json:"updated_at" bson:"updated_at"`
+	ID           string      `json:"id" bson:"_id,omitempty"`
+	Name         string      `json:"name" bson:"name"`
+	Description  string      `json:"description" bson:"description,omitempty"`
+	APIKey       string      `json:"api_key,omitempty" bson:"-"`
+	APIKeyHash   string      `json:"-" bson:"api_key_hash"`
+	APIKeyPrefix string      `json:"api_key_prefix" bson:"api_key_prefix"`
+	Environment  Environment `json:"environment" bson:"environment"`
+	IsActive     bool        `json:"is_active" bson:"is_active"`
+	CreatedAt    time.Time   `json:"created_at" bson:"created_at"`
+	UpdatedAt    time.Time   `json:"updated_at" bson:"updated_at"`
+
+	// Retention overrides the configured retention of the project's data;
+	// zero days take the default
+	Retention RetentionPolicy `json:"retention" bson:"retention"`
 }
 
 // Validate validates the project
@@ -25,7 +35,7 @@ func (p *Project) Validate() error {
 	if len(p.Name) < 3 {
 		return errors.New("project name must be at least 3 characters")
 	}
-	if p.APIKey == "" {
+	if p.APIKeyHash == "" {
 		return errors.New("api key is required")
 	}
 
@@ -0,0 +1,66 @@
+package domain
+
+import (
+	"errors"
+	"fmt"
+	"time"
+)
+
+// ProjectQuota limits how fast and how much a project can ingest. Project
+// limits apply to all of a project's traffic, key limits to each API key on
+// its own. A zero limit means unlimited.
+type ProjectQuota struct {
+	ProjectID            string    `json:"project_id" bson:"_id"`
+	RequestsPerSecond    int64     `json:"requests_per_second" bson:"requests_per_second"`
+	LogsPerDay           int64     `json:"logs_per_day" bson:"logs_per_day"`
+	KeyRequestsPerSecond int64     `json:"key_requests_per_second" bson:"key_requests_per_second"`
+	KeyLogsPerDay        int64     `json:"key_logs_per_day" bson:"key_logs_per_day"`
+	UpdatedAt            time.Time `json:"updated_at" bson:"updated_at"`
+}
+
+// Validate validates the quota
+func (q *ProjectQuota) Validate() error {
+	if q.ProjectID == "" {
+		return errors.New("project_id is required")
+	}
+	if q.RequestsPerSecond < 0 || q.LogsPerDay < 0 || q.KeyRequestsPerSecond < 0 || q.KeyLogsPerDay < 0 {
+		return ErrInvalidQuota
+	}
+	return nil
+}
+
+// QuotaUsage is a project's consumption in the current second and UTC day
+type QuotaUsage struct {
+	ProjectID          string     `json:"project_id"`
+	Day                string     `json:"day"`
+	RequestsThisSecond int64      `json:"requests_this_second"`
+	LogsToday          int64      `json:"logs_today"`
+	Keys               []KeyUsage `json:"keys"`
+}
+
+// KeyUsage is one API key's consumption in the current second and UTC day
+type KeyUsage struct {
+	KeyID              string `json:"key_id"`
+	Name               string `json:"name"`
+	Prefix             string `json:"prefix"`
+	RequestsThisSecond int64  `json:"requests_this_second"`
+	LogsToday          int64  `json:"logs_today"`
+}
+
+// QuotaExceededError is returned when a request would exceed a limit. It
+// matches ErrQuotaExceeded with errors.Is.
+type QuotaExceededError struct {
+	// Limit names the exceeded limit, e.g. "logs_per_day"
+	Limit string
+	// RetryAfter is how long until the limit's window resets
+	RetryAfter time.Duration
+}
+
+func (e *QuotaExceededError) Error() string {
+	return fmt.Sprintf("%s: %s exceeded", ErrQuotaExceeded, e.Limit)
+}
+
+// Is reports whether target is ErrQuotaExceeded
+func (e *QuotaExceededError) Is(target error) bool {
+	return target == ErrQuotaExceeded
+}
@@ -0,0 +1,36 @@
+package domain
+
+import (
+	"errors"
+	"time"
+)
+
+// RedactionPolicy selects the values masked in a project's logs before they
+// are stored. Projects without a policy of their own use the configured
+// default policy.
+type RedactionPolicy struct {
+	ProjectID string `json:"project_id" bson:"_id"`
+	// Headers and QueryParams name the headers and URL parameters whose
+	// values are masked, case-insensitively; QueryParams also covers path
+	// parameters
+	Headers     []string `json:"headers" bson:"headers"`
+	QueryParams []string `json:"query_params" bson:"query_params"`
+	// JSONPaths select request and response body fields, e.g. $.password or
+	// $..card_number
+	JSONPaths []string `json:"json_paths" bson:"json_paths"`
+	// Patterns name built-in detectors (email, token, card_number) and
+	// Regexes add custom ones; matches are masked in headers, parameters and
+	// bodies
+	Patterns  []string  `json:"patterns" bson:"patterns"`
+	Regexes   []string  `json:"regexes" bson:"regexes"`
+	UpdatedAt time.Time `json:"updated_at" bson:"updated_at"`
+}
+
+// Validate validates the policy. Paths, patterns and regexes are checked
+// when the policy is compiled.
+func (p *RedactionPolicy) Validate() error {
+	if p.ProjectID == "" {
+		return errors.New("project_id is required")
+	}
+	return nil
+}
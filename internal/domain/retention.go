@@ -0,0 +1,111 @@
+package domain
+
+import (
+	"fmt"
+	"time"
+)
+
+// MaxRetentionDays bounds how long a retention policy can keep data
+const MaxRetentionDays = 3650
+
+// DataClass names a kind of project data with its own retention
+type DataClass string
+
+const (
+	DataLogs       DataClass = "logs"
+	DataHeaders    DataClass = "headers"
+	DataBodies     DataClass = "bodies"
+	DataAccessLogs DataClass = "access_logs"
+)
+
+// DataClasses lists the classes in the order they are purged: details before
+// the logs they belong to
+var DataClasses = []DataClass{DataBodies, DataHeaders, DataLogs, DataAccessLogs}
+
+// RetentionPolicy sets how many days each class of a project's data is kept.
+// On a project, zero takes the configured default; in an effective policy,
+// zero keeps the data forever.
+type RetentionPolicy struct {
+	LogsDays       int `json:"logs_days" bson:"logs_days"`
+	HeadersDays    int `json:"headers_days" bson:"headers_days"`
+	BodiesDays     int `json:"bodies_days" bson:"bodies_days"`
+	AccessLogsDays int `json:"access_logs_days" bson:"access_logs_days"`
+}
+
+// WithDefaults returns the effective policy: the days the policy leaves unset
+// are taken from defaults
+func (p RetentionPolicy) WithDefaults(defaults RetentionPolicy) RetentionPolicy {
+	pick := func(days, fallback int) int {
+		if days == 0 {
+			return fallback
+		}
+		return days
+	}
+	return RetentionPolicy{
+		LogsDays:       pick(p.LogsDays, defaults.LogsDays),
+		HeadersDays:    pick(p.HeadersDays, defaults.HeadersDays),
+		BodiesDays:     pick(p.BodiesDays, defaults.BodiesDays),
+		AccessLogsDays: pick(p.AccessLogsDays, defaults.AccessLogsDays),
+	}
+}
+
+// Days returns the days the policy keeps a class of data
+func (p RetentionPolicy) Days(class DataClass) int {
+	switch class {
+	case DataLogs:
+		return p.LogsDays
+	case DataHeaders:
+		return p.HeadersDays
+	case DataBodies:
+		return p.BodiesDays
+	case DataAccessLogs:
+		return p.AccessLogsDays
+	}
+	return 0
+}
+
+// Validate validates an effective policy. Headers and bodies cannot outlive
+// the logs they belong to.
+func (p RetentionPolicy) Validate() error {
+	for _, class := range DataClasses {
+		if days := p.Days(class); days < 0 || days > MaxRetentionDays {
+			return fmt.Errorf("%w: %s_days must be between 0 and %d", ErrInvalidRetentionPolicy, class, MaxRetentionDays)
+		}
+	}
+	if p.LogsDays == 0 {
+		return nil
+	}
+	for _, class := range []DataClass{DataHeaders, DataBodies} {
+		if days := p.Days(class); days == 0 || days > p.LogsDays {
+			return fmt.Errorf("%w: %s are kept longer than the %d days of their logs", ErrInvalidRetentionPolicy, class, p.LogsDays)
+		}
+	}
+	return nil
+}
+
+// DataVolume measures stored data
+type DataVolume struct {
+	Documents int64 `json:"documents"`
+	Bytes     int64 `json:"bytes"`
+}
+
+// PurgeEstimate is what the next purge of one class of a project's data
+// will remove: everything stored before Before
+type PurgeEstimate struct {
+	Class DataClass `json:"class"`
+	Days  int       `json:"retention_days"`
+	// Before is nil when the class is kept forever
+	Before *time.Time `json:"before"`
+	// Archived is set when the purge archives the data before removing it
+	Archived bool `json:"archived"`
+	DataVolume
+}
+
+// RetentionPreview lists what the next purge will remove from a project
+type RetentionPreview struct {
+	ProjectID string          `json:"project_id"`
+	Policy    RetentionPolicy `json:"policy"`
+	NextRunAt time.Time       `json:"next_run_at"`
+	Classes   []PurgeEstimate `json:"classes"`
+	Total     DataVolume      `json:"total"`
+}
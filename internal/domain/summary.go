@@ -0,0 +1,153 @@
+package domain
+
+import "time"
+
+// SummaryGranularity is the width of the buckets logs are rolled up into
+type SummaryGranularity string
+
+const (
+	GranularityMinute SummaryGranularity = "minute"
+	GranularityHour   SummaryGranularity = "hour"
+)
+
+// Duration returns the width of the granularity's buckets
+func (g SummaryGranularity) Duration() time.Duration {
+	if g == GranularityHour {
+		return time.Hour
+	}
+	return time.Minute
+}
+
+// Bucket returns the start of the bucket t falls in, in UTC
+func (g SummaryGranularity) Bucket(t time.Time) time.Time {
+	return t.UTC().Truncate(g.Duration())
+}
+
+// LatencyBounds are the upper bounds, in milliseconds, of the latency
+// histogram's buckets. A last bucket counts the slower responses.
+var LatencyBounds = []int64{5, 10, 25, 50, 100, 250, 500, 1000, 2500, 5000, 10000}
+
+// LogSummary rolls up a project's logs of one environment, method and route
+// over one minute or hour. Logs with a status of 400 or above count as errors.
+type LogSummary struct {
+	ProjectID   string             `json:"project_id"`
+	Environment Environment        `json:"environment"`
+	Granularity SummaryGranularity `json:"granularity"`
+	Bucket      time.Time          `json:"bucket"`
+	Method      HTTPMethod         `json:"method"`
+	Route       string             `json:"route"`
+
+	Count      int64 `json:"count"`
+	ErrorCount int64 `json:"error_count"`
+	Status1xx  int64 `json:"status_1xx"`
+	Status2xx  int64 `json:"status_2xx"`
+	Status3xx  int64 `json:"status_3xx"`
+	Status4xx  int64 `json:"status_4xx"`
+	Status5xx  int64 `json:"status_5xx"`
+
+	// StatusCodes counts each status code, for the stats' distribution
+	StatusCodes map[int]int64 `json:"status_codes"`
+
+	LatencySum int64 `json:"latency_sum_ms"`
+	LatencyMin int64 `json:"latency_min_ms"`
+	LatencyMax int64 `json:"latency_max_ms"`
+	// Histogram counts the responses per bucket of LatencyBounds
+	Histogram []int64 `json:"latency_histogram"`
+}
+
+// NewLogSummary returns an empty summary of the bucket of log
+func NewLogSummary(log *APILog, granularity SummaryGranularity) *LogSummary {
+	return &LogSummary{
+		ProjectID:   log.ProjectID,
+		Environment: log.Environment,
+		Granularity: granularity,
+		Bucket:      granularity.Bucket(log.Timestamp),
+		Method:      log.Method,
+		Route:       log.Path,
+		StatusCodes: make(map[int]int64),
+		Histogram:   make([]int64, len(LatencyBounds)+1),
+	}
+}
+
+// Add counts log in the summary
+func (s *LogSummary) Add(log *APILog) {
+	if s.Count == 0 || log.ResponseTime < s.LatencyMin {
+		s.LatencyMin = log.ResponseTime
+	}
+	if s.Count == 0 || log.ResponseTime > s.LatencyMax {
+		s.LatencyMax = log.ResponseTime
+	}
+	s.Count++
+	s.LatencySum += log.ResponseTime
+
+	if log.StatusCode >= 400 {
+		s.ErrorCount++
+	}
+	switch log.StatusCode / 100 {
+	case 1:
+		s.Status1xx++
+	case 2:
+		s.Status2xx++
+	case 3:
+		s.Status3xx++
+	case 4:
+		s.Status4xx++
+	case 5:
+		s.Status5xx++
+	}
+	s.StatusCodes[log.StatusCode]++
+
+	bucket := len(LatencyBounds)
+	for i, bound := range LatencyBounds {
+		if log.ResponseTime <= bound {
+			bucket = i
+			break
+		}
+	}
+	s.Histogram[bucket]++
+}
+
+// Merge adds the counts of other, a summary of the same logs' dimensions, to
+// the summary
+func (s *LogSummary) Merge(other *LogSummary) {
+	if other.Count == 0 {
+		return
+	}
+	if s.Count == 0 || other.LatencyMin < s.LatencyMin {
+		s.LatencyMin = other.LatencyMin
+	}
+	if s.Count == 0 || other.LatencyMax > s.LatencyMax {
+		s.LatencyMax = other.LatencyMax
+	}
+	s.Count += other.Count
+	s.ErrorCount += other.ErrorCount
+	s.Status1xx += other.Status1xx
+	s.Status2xx += other.Status2xx
+	s.Status3xx += other.Status3xx
+	s.Status4xx += other.Status4xx
+	s.Status5xx += other.Status5xx
+	s.LatencySum += other.LatencySum
+
+	if s.StatusCodes == nil {
+		s.StatusCodes = make(map[int]int64)
+	}
+	for code, count := range other.StatusCodes {
+		s.StatusCodes[code] += count
+	}
+	for len(s.Histogram) < len(other.Histogram) {
+		s.Histogram = append(s.Histogram, 0)
+	}
+	for i, count := range other.Histogram {
+		s.Histogram[i] += count
+	}
+}
+
+// SummaryFilter selects a project's summaries of one granularity with buckets
+// in [From, To). An empty environment selects every environment.
+type SummaryFilter struct {
+	ProjectID   string
+	Environment Environment
+	Granularity SummaryGranularity
+	From        time.Time
+	To          time.Time
+}
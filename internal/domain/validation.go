@@ -0,0 +1,33 @@
+package domain
+
+import "strings"
+
+// FieldError reports a field of an ingested log that was rejected, or
+// dropped or truncated on the way in
+type FieldError struct {
+	Field  string `json:"field"`
+	Reason string `json:"reason"`
+}
+
+func (e FieldError) Error() string {
+	return e.Field + ": " + e.Reason
+}
+
+// ValidationError is returned for a log with fields that cannot be stored. It
+// matches ErrInvalidInput with errors.Is.
+type ValidationError struct {
+	Fields []FieldError
+}
+
+func (e *ValidationError) Error() string {
+	reasons := make([]string, len(e.Fields))
+	for i, field := range e.Fields {
+		reasons[i] = field.Error()
+	}
+	return ErrInvalidInput.Error() + ": " + strings.Join(reasons, "; ")
+}
+
+// Is reports whether target is ErrInvalidInput
+func (e *ValidationError) Is(target error) bool {
+	return target == ErrInvalidInput
+}
@@ -16,6 +16,11 @@ type APILogService interface {
 		body *domain.APILogBody,
 	) error
 
+	// CreateLogs creates a batch of log entries with bulk writes. It returns one
+	// error per entry, nil for the entries that were stored, or an error for the
+	// whole batch when storage fails.
+	CreateLogs(ctx context.Context, entries []*domain.LogEntry) ([]error, error)
+
 	// GetLog retrieves a log by ID (core log only)
 	GetLog(ctx context.Context, id string) (*domain.APILog, error)
 
@@ -31,6 +36,10 @@ type APILogService interface {
 	// ListLogs retrieves logs based on filter criteria (core logs only)
 	ListLogs(ctx context.Context, filter domain.LogFilter) ([]*domain.APILog, error)
 
+	// ListLogDetails retrieves the logs ListLogs would return, each with its
+	// headers and body when they were stored
+	ListLogDetails(ctx context.Context, filter domain.LogFilter) ([]*domain.LogEntry, error)
+
 	// CountLogs counts logs matching the filter criteria
 	CountLogs(ctx context.Context, filter domain.LogFilter) (int64, error)
 
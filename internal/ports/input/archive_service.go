@@ -0,0 +1,21 @@
+package input
+
+import (
+	"context"
+	"time"
+
+	"github.com/spidey52/api-logs/internal/domain"
+)
+
+// ArchiveService defines the interface for reading back the data retention
+// archived before purging it (Primary Port)
+type ArchiveService interface {
+	// List returns the archived objects of a project from the UTC day of from
+	// through the UTC day of to, oldest day first
+	List(ctx context.Context, projectID string, from, to time.Time) ([]domain.ArchivedObject, error)
+
+	// Restore stores the logs archived for the source project over a range of
+	// days in the target project, with their headers and bodies. It requires
+	// read access to the source and write access to the target.
+	Restore(ctx context.Context, restore domain.ArchiveRestore) (*domain.RestoreResult, error)
+}
@@ -0,0 +1,32 @@
+package input
+
+import (
+	"context"
+
+	"github.com/spidey52/api-logs/internal/domain"
+)
+
+// AuthService defines the interface for dashboard authentication (Primary Port)
+type AuthService interface {
+	// Login verifies credentials and returns a new session with its bearer token
+	Login(ctx context.Context, username, password string) (*domain.Session, string, *domain.Account, error)
+
+	// Logout revokes the session identified by the bearer token
+	Logout(ctx context.Context, token string) error
+
+	// Authenticate resolves a bearer token to its active account
+	Authenticate(ctx context.Context, token string) (*domain.Account, error)
+
+	// CreateAccount creates a new account with a hashed password; only admins may create accounts
+	CreateAccount(ctx context.Context, username, password string, isAdmin bool) (*domain.Account, error)
+
+	// ListAccounts retrieves all accounts; only admins may list accounts
+	ListAccounts(ctx context.Context) ([]*domain.Account, error)
+
+	// ChangePassword replaces an account's password and revokes its sessions
+	ChangePassword(ctx context.Context, accountID, currentPassword, newPassword string) error
+
+	// EnsureAdminAccount creates the admin account if no account with that username
+	// exists, and grants admin to an existing account of that name
+	EnsureAdminAccount(ctx context.Context, username, password string) error
+}
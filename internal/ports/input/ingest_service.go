@@ -0,0 +1,32 @@
+package input
+
+import (
+	"context"
+
+	"github.com/spidey52/api-logs/internal/domain"
+)
+
+// IngestService accepts logs for asynchronous storage (Primary Port)
+type IngestService interface {
+	// Enqueue validates the entries, assigns their IDs and timestamps, and queues
+	// the valid ones for storage. It returns one error per entry: nil for the
+	// accepted ones, and domain.ErrDuplicateLog for those whose client-supplied
+	// ID or idempotency key was accepted before. The batch is rejected as a
+	// whole with domain.ErrIngestQueueFull when the queue has no room for it,
+	// with domain.ErrIngestSpoolFull when the spool has no room for it, with
+	// domain.ErrIngestTooLarge when it is over the spool's record size, and
+	// with a *domain.QuotaExceededError when its accepted entries would exceed
+	// a daily quota.
+	Enqueue(ctx context.Context, entries []*domain.LogEntry) ([]error, error)
+
+	// Replay stores the spooled batches whose write failed or was interrupted
+	// by a restart, and returns how many logs it stored. It stops at the first
+	// storage error, leaving the rest for the next call.
+	Replay(ctx context.Context) (int, error)
+
+	// Stats reports the logs accepted but not yet stored
+	Stats() domain.IngestStats
+
+	// Drain stops accepting entries and waits until the queued ones are stored
+	Drain(ctx context.Context) error
+}
@@ -2,6 +2,7 @@ package input
 
 import (
 	"context"
+	"time"
 
 	"github.com/spidey52/api-logs/internal/domain"
 )
@@ -26,9 +27,34 @@ type ProjectService interface {
 	// DeleteProject deletes a project by ID
 	DeleteProject(ctx context.Context, id string) error
 
-	// ValidateAPIKey validates an API key and returns the associated project
-	ValidateAPIKey(ctx context.Context, apiKey string, environment domain.Environment) (*domain.Project, error)
+	// ValidateAPIKey validates an API key for a scope and returns the associated project and key
+	ValidateAPIKey(ctx context.Context, apiKey string, environment domain.Environment, scope domain.APIKeyScope) (*domain.Project, *domain.APIKey, error)
 
-	// RegenerateAPIKey generates a new API key for a project
-	RegenerateAPIKey(ctx context.Context, projectID string) (string, error)
+	// RegenerateAPIKey generates a new API key for a project, keeping the previous
+	// key valid for a grace period (the configured default when nil)
+	RegenerateAPIKey(ctx context.Context, projectID string, gracePeriod *time.Duration) (*domain.APIKeyRotation, error)
+
+	// RevokeExpiredAPIKeys revokes keys whose expiry or rotation grace period has passed
+	RevokeExpiredAPIKeys(ctx context.Context) (int, error)
+
+	// CreateAPIKey issues a new named, scoped key for a project
+	CreateAPIKey(ctx context.Context, projectID, name string, scopes []domain.APIKeyScope, expiresAt *time.Time) (*domain.APIKey, error)
+
+	// ListAPIKeys retrieves the named keys of a project
+	ListAPIKeys(ctx context.Context, projectID string) ([]*domain.APIKey, error)
+
+	// RevokeAPIKey revokes one of a project's named keys
+	RevokeAPIKey(ctx context.Context, projectID, keyID string) error
+
+	// ListMembers retrieves the members of a project
+	ListMembers(ctx context.Context, projectID string) ([]*domain.ProjectMember, error)
+
+	// AddMember grants an account a role on a project
+	AddMember(ctx context.Context, projectID, username string, role domain.ProjectRole) (*domain.ProjectMember, error)
+
+	// UpdateMemberRole changes the role of an account on a project
+	UpdateMemberRole(ctx context.Context, projectID, accountID string, role domain.ProjectRole) (*domain.ProjectMember, error)
+
+	// RemoveMember revokes an account's role on a project
+	RemoveMember(ctx context.Context, projectID, accountID string) error
 }
@@ -0,0 +1,36 @@
+package input
+
+import (
+	"context"
+
+	"github.com/spidey52/api-logs/internal/domain"
+)
+
+// QuotaService defines the interface for ingestion rate limits and quotas (Primary Port)
+type QuotaService interface {
+	// CheckRequest counts one ingest request against the project's and the
+	// key's requests per second. It returns a *domain.QuotaExceededError when
+	// a limit is exceeded. keyID is empty for callers without an API key.
+	CheckRequest(ctx context.Context, projectID, keyID string) error
+
+	// CountLogs counts accepted logs against the project's and the key's logs
+	// per day. It returns a *domain.QuotaExceededError when a limit would be
+	// exceeded; denied logs are not counted.
+	CountLogs(ctx context.Context, projectID, keyID string, logs int) error
+
+	// ReleaseLogs takes logs counted by CountLogs back out, for logs that were
+	// not accepted after all
+	ReleaseLogs(ctx context.Context, projectID, keyID string, logs int) error
+
+	// GetQuota retrieves a project's effective quota: its own, or the defaults
+	GetQuota(ctx context.Context, projectID string) (*domain.ProjectQuota, error)
+
+	// GetUsage retrieves a project's and its keys' consumption in the current windows
+	GetUsage(ctx context.Context, projectID string) (*domain.QuotaUsage, error)
+
+	// SetQuota replaces a project's quota; only admins may set quotas
+	SetQuota(ctx context.Context, quota *domain.ProjectQuota) error
+
+	// ResetQuota reverts a project to the default quota; only admins may reset quotas
+	ResetQuota(ctx context.Context, projectID string) error
+}
@@ -0,0 +1,23 @@
+package input
+
+import (
+	"context"
+
+	"github.com/spidey52/api-logs/internal/domain"
+)
+
+// RedactionService defines the interface for masking sensitive values in ingested logs (Primary Port)
+type RedactionService interface {
+	// Redact masks, in place, the header, parameter and body values that the
+	// policy of each entry's project selects
+	Redact(ctx context.Context, entries []*domain.LogEntry) error
+
+	// GetPolicy retrieves a project's effective policy: its own, or the default
+	GetPolicy(ctx context.Context, projectID string) (*domain.RedactionPolicy, error)
+
+	// SetPolicy replaces a project's policy; only project owners may set policies
+	SetPolicy(ctx context.Context, policy *domain.RedactionPolicy) error
+
+	// ResetPolicy reverts a project to the default policy; only project owners may reset policies
+	ResetPolicy(ctx context.Context, projectID string) error
+}
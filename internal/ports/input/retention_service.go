@@ -0,0 +1,24 @@
+package input
+
+import (
+	"context"
+
+	"github.com/spidey52/api-logs/internal/domain"
+)
+
+// RetentionService defines the interface for purging project data past its retention (Primary Port)
+type RetentionService interface {
+	// GetPolicy retrieves a project's effective policy: its own days, or the defaults
+	GetPolicy(ctx context.Context, projectID string) (*domain.RetentionPolicy, error)
+
+	// SetPolicy replaces a project's retention days, zero taking the default,
+	// and returns the effective policy; only project owners may set policies
+	SetPolicy(ctx context.Context, projectID string, policy domain.RetentionPolicy) (*domain.RetentionPolicy, error)
+
+	// Preview estimates what the next purge will remove from a project
+	Preview(ctx context.Context, projectID string) (*domain.RetentionPreview, error)
+
+	// Purge removes every project's data past its retention, returning how
+	// many records were removed
+	Purge(ctx context.Context) (int64, error)
+}
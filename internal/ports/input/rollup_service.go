@@ -0,0 +1,33 @@
+package input
+
+import (
+	"context"
+	"time"
+
+	"github.com/spidey52/api-logs/internal/domain"
+)
+
+// RollupService defines the interface for maintaining the per-minute and
+// per-hour log summaries the stats are read from (Primary Port)
+type RollupService interface {
+	// Track marks the minutes of stored logs for the next refresh
+	Track(logs []*domain.APILog)
+
+	// Backfill marks every minute since a time, of every project, for the
+	// next refresh
+	Backfill(ctx context.Context, since time.Time) error
+
+	// Refresh recomputes the summaries of the marked minutes, and of the hours
+	// holding them, from the stored logs, returning how many minutes were
+	// refreshed
+	Refresh(ctx context.Context) (int, error)
+
+	// Purge removes the summaries past their retention, returning how many
+	// were removed
+	Purge(ctx context.Context) (int64, error)
+
+	// Summaries retrieves the summaries covering a project's logs in [from,
+	// to): hour summaries for the whole hours of the range, minute summaries
+	// for the rest
+	Summaries(ctx context.Context, projectID string, environment domain.Environment, from, to time.Time) ([]*domain.LogSummary, error)
+}
@@ -23,6 +23,6 @@ type UserService interface {
 	// DeleteUser deletes a user by ID
 	DeleteUser(ctx context.Context, id string) error
 
-	// ListUsers retrieves all users with pagination
-	ListUsers(ctx context.Context, page, pageSize int) ([]*domain.User, int, error)
+	// ListUsers retrieves a project's users with pagination
+	ListUsers(ctx context.Context, projectID string, page, pageSize int) ([]*domain.User, int, error)
 }
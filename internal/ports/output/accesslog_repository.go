@@ -2,6 +2,7 @@ package output
 
 import (
 	"context"
+	"time"
 
 	"github.com/spidey52/api-logs/internal/domain"
 )
@@ -20,4 +21,11 @@ type AccessLogRepository interface {
 	DeleteByID(ctx context.Context, id string) error
 
 	DeleteByFilter(ctx context.Context, filter domain.AccessLogFilter) (int64, error)
+
+	// DeleteOlderThan removes at most limit access logs of a project with a
+	// timestamp before the given time, returning how many were removed
+	DeleteOlderThan(ctx context.Context, projectID string, before time.Time, limit int) (int64, error)
+
+	// VolumeOlderThan measures the access logs DeleteOlderThan would remove without a limit
+	VolumeOlderThan(ctx context.Context, projectID string, before time.Time) (domain.DataVolume, error)
 }
@@ -0,0 +1,36 @@
+package output
+
+import (
+	"context"
+	"time"
+
+	"github.com/spidey52/api-logs/internal/domain"
+)
+
+// AccountRepository defines the interface for dashboard account persistence (Secondary Port)
+type AccountRepository interface {
+	// Create stores a new account
+	Create(ctx context.Context, account *domain.Account) error
+	// FindByID retrieves an account by ID
+	FindByID(ctx context.Context, id string) (*domain.Account, error)
+	// FindByUsername retrieves an account by its normalized username
+	FindByUsername(ctx context.Context, username string) (*domain.Account, error)
+	// Update updates an existing account
+	Update(ctx context.Context, account *domain.Account) error
+	// List retrieves all accounts ordered by username
+	List(ctx context.Context) ([]*domain.Account, error)
+}
+
+// SessionRepository defines the interface for login session persistence (Secondary Port)
+type SessionRepository interface {
+	// Create stores a new session
+	Create(ctx context.Context, session *domain.Session) error
+	// FindByTokenHash retrieves a session by the hash of its bearer token
+	FindByTokenHash(ctx context.Context, tokenHash string) (*domain.Session, error)
+	// Delete removes a session by ID
+	Delete(ctx context.Context, id string) error
+	// DeleteByAccountID removes every session of an account
+	DeleteByAccountID(ctx context.Context, accountID string) error
+	// DeleteExpired removes sessions that expired before the given time
+	DeleteExpired(ctx context.Context, before time.Time) (int64, error)
+}
@@ -0,0 +1,32 @@
+package output
+
+import (
+	"context"
+	"time"
+
+	"github.com/spidey52/api-logs/internal/domain"
+)
+
+// APIKeyRepository defines the interface for named project API key persistence (Secondary Port)
+type APIKeyRepository interface {
+	// Create stores a new API key
+	Create(ctx context.Context, key *domain.APIKey) error
+	// FindByID retrieves an API key by ID
+	FindByID(ctx context.Context, id string) (*domain.APIKey, error)
+	// FindByKey retrieves an API key by the hash of its secret
+	FindByKey(ctx context.Context, keyHash string) (*domain.APIKey, error)
+	// ListByProject retrieves a project's API keys, newest first
+	ListByProject(ctx context.Context, projectID string) ([]*domain.APIKey, error)
+	// Revoke marks an API key as revoked at the given time
+	Revoke(ctx context.Context, id string, revokedAt time.Time) error
+	// RevokeExpired revokes every key that expired at or before now, recording
+	// its expiry as the revocation time, and returns how many were revoked
+	RevokeExpired(ctx context.Context, now time.Time) (int, error)
+	// TouchLastUsed records when an API key was last used
+	TouchLastUsed(ctx context.Context, id string, usedAt time.Time) error
+	// DeleteByProject removes every API key of a project
+	DeleteByProject(ctx context.Context, projectID string) error
+	// HashPlaintextKeys replaces keys stored before hashing was introduced with
+	// hash(key) and their prefix, and returns how many keys were converted
+	HashPlaintextKeys(ctx context.Context, hash func(key string) string) (int, error)
+}
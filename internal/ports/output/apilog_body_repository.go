@@ -2,6 +2,7 @@ package output
 
 import (
 	"context"
+	"time"
 
 	"github.com/spidey52/api-logs/internal/domain"
 )
@@ -11,12 +12,33 @@ type APILogBodyRepository interface {
 	// Create stores request/response bodies for a log
 	Create(ctx context.Context, body *domain.APILogBody) error
 
+	// CreateMany stores bodies for multiple logs in one round trip. Logs that
+	// already have a body are skipped, so a batch can safely be written again.
+	CreateMany(ctx context.Context, bodies []*domain.APILogBody) error
+
 	// FindByLogID retrieves body by log ID
 	FindByLogID(ctx context.Context, logID string) (*domain.APILogBody, error)
 
+	// FindByLogIDs retrieves the bodies of the given logs that have them, in no
+	// particular order
+	FindByLogIDs(ctx context.Context, logIDs []string) ([]*domain.APILogBody, error)
+
 	// Delete removes body by log ID
 	Delete(ctx context.Context, logID string) error
 
 	// DeleteBatch removes bodies for multiple log IDs
 	DeleteBatch(ctx context.Context, logIDs []string) error
+
+	// FindOlderThan retrieves at most limit bodies of a project created before
+	// the given time, oldest first, with their project set. An empty project ID
+	// selects bodies stored without their project.
+	FindOlderThan(ctx context.Context, projectID string, before time.Time, limit int) ([]*domain.APILogBody, error)
+
+	// DeleteOlderThan removes at most limit bodies of a project created before
+	// the given time, returning how many were removed. An empty project ID
+	// selects bodies stored without their project.
+	DeleteOlderThan(ctx context.Context, projectID string, before time.Time, limit int) (int64, error)
+
+	// VolumeOlderThan measures the bodies DeleteOlderThan would remove without a limit
+	VolumeOlderThan(ctx context.Context, projectID string, before time.Time) (domain.DataVolume, error)
 }
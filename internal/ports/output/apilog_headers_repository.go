@@ -2,6 +2,7 @@ package output
 
 import (
 	"context"
+	"time"
 
 	"github.com/spidey52/api-logs/internal/domain"
 )
@@ -11,12 +12,33 @@ type APILogHeadersRepository interface {
 	// Create stores headers for a log
 	Create(ctx context.Context, headers *domain.APILogHeaders) error
 
+	// CreateMany stores headers for multiple logs in one round trip. Logs that
+	// already have headers are skipped, so a batch can safely be written again.
+	CreateMany(ctx context.Context, headers []*domain.APILogHeaders) error
+
 	// FindByLogID retrieves headers by log ID
 	FindByLogID(ctx context.Context, logID string) (*domain.APILogHeaders, error)
 
+	// FindByLogIDs retrieves the headers of the given logs that have them, in
+	// no particular order
+	FindByLogIDs(ctx context.Context, logIDs []string) ([]*domain.APILogHeaders, error)
+
 	// Delete removes headers by log ID
 	Delete(ctx context.Context, logID string) error
 
 	// DeleteBatch removes headers for multiple log IDs
 	DeleteBatch(ctx context.Context, logIDs []string) error
+
+	// FindOlderThan retrieves at most limit headers of a project created before
+	// the given time, oldest first, with their project set. An empty project ID
+	// selects headers stored without their project.
+	FindOlderThan(ctx context.Context, projectID string, before time.Time, limit int) ([]*domain.APILogHeaders, error)
+
+	// DeleteOlderThan removes at most limit headers of a project created before
+	// the given time, returning how many were removed. An empty project ID
+	// selects headers stored without their project.
+	DeleteOlderThan(ctx context.Context, projectID string, before time.Time, limit int) (int64, error)
+
+	// VolumeOlderThan measures the headers DeleteOlderThan would remove without a limit
+	VolumeOlderThan(ctx context.Context, projectID string, before time.Time) (domain.DataVolume, error)
 }
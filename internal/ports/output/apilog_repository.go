@@ -2,6 +2,7 @@ package output
 
 import (
 	"context"
+	"time"
 
 	"github.com/spidey52/api-logs/internal/domain"
 )
@@ -11,6 +12,10 @@ type APILogRepository interface {
 	// Create stores a new API log entry (core log only)
 	Create(ctx context.Context, log *domain.APILog) error
 
+	// CreateMany stores multiple API log entries in one round trip. Logs whose
+	// ID already exists are skipped, so a batch can safely be written again.
+	CreateMany(ctx context.Context, logs []*domain.APILog) error
+
 	// FindByID retrieves a log by ID
 	FindByID(ctx context.Context, id string) (*domain.APILog, error)
 
@@ -20,6 +25,9 @@ type APILogRepository interface {
 	// Delete removes a log by ID
 	Delete(ctx context.Context, id string) error
 
+	// DeleteBatch removes the logs with the given IDs
+	DeleteBatch(ctx context.Context, ids []string) error
+
 	// CountByProject counts logs for a specific project
 	CountByProject(ctx context.Context, projectID string, environment domain.Environment) (int64, error)
 
@@ -43,4 +51,15 @@ type APILogRepository interface {
 
 	// GetUniquePaths returns list of unique paths for autocomplete
 	GetUniquePaths(ctx context.Context, projectID string, environment domain.Environment) ([]string, error)
+
+	// FindOlderThan retrieves at most limit logs of a project with a timestamp
+	// before the given time, oldest first
+	FindOlderThan(ctx context.Context, projectID string, before time.Time, limit int) ([]*domain.APILog, error)
+
+	// DeleteOlderThan removes at most limit logs of a project with a timestamp
+	// before the given time, returning how many were removed
+	DeleteOlderThan(ctx context.Context, projectID string, before time.Time, limit int) (int64, error)
+
+	// VolumeOlderThan measures the logs DeleteOlderThan would remove without a limit
+	VolumeOlderThan(ctx context.Context, projectID string, before time.Time) (domain.DataVolume, error)
 }
@@ -0,0 +1,23 @@
+package output
+
+import (
+	"context"
+
+	"github.com/spidey52/api-logs/internal/domain"
+)
+
+// DataKeyRepository defines the interface for project data key persistence (Secondary Port)
+type DataKeyRepository interface {
+	// Find retrieves a project's data key
+	Find(ctx context.Context, projectID string) (*domain.DataKey, error)
+	// Create stores a project's data key, failing with ErrDuplicateDataKey
+	// when the project already has one
+	Create(ctx context.Context, key *domain.DataKey) error
+	// Rewrap replaces the wrapping of a project's data key
+	Rewrap(ctx context.Context, key *domain.DataKey) error
+	// List retrieves the data keys of all projects
+	List(ctx context.Context) ([]*domain.DataKey, error)
+	// Delete removes a project's data key, leaving its encrypted data
+	// unreadable
+	Delete(ctx context.Context, projectID string) error
+}
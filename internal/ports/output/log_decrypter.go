@@ -0,0 +1,18 @@
+package output
+
+import (
+	"context"
+
+	"github.com/spidey52/api-logs/internal/domain"
+)
+
+// LogDecrypter decrypts the headers and bodies the storage encrypted at rest (Secondary Port)
+type LogDecrypter interface {
+	// DecryptHeaders replaces the encrypted header maps of a project's log
+	// with their plaintext; maps stored unencrypted are left as they are
+	DecryptHeaders(ctx context.Context, projectID string, headers *domain.APILogHeaders) error
+
+	// DecryptBody replaces the encrypted bodies of a project's log with their
+	// plaintext; bodies stored unencrypted are left as they are
+	DecryptBody(ctx context.Context, projectID string, body *domain.APILogBody) error
+}
@@ -0,0 +1,20 @@
+package output
+
+import (
+	"context"
+	"time"
+
+	"github.com/spidey52/api-logs/internal/domain"
+)
+
+// LogSummaryRepository defines the interface for log rollup persistence (Secondary Port)
+type LogSummaryRepository interface {
+	// Replace replaces a project's summaries of one granularity with buckets
+	// in [from, to) by the given ones
+	Replace(ctx context.Context, projectID string, granularity domain.SummaryGranularity, from, to time.Time, summaries []*domain.LogSummary) error
+	// Find retrieves the summaries matching the filter, oldest bucket first
+	Find(ctx context.Context, filter domain.SummaryFilter) ([]*domain.LogSummary, error)
+	// DeleteOlderThan removes the summaries of one granularity with buckets
+	// before the cutoff, returning how many were removed
+	DeleteOlderThan(ctx context.Context, granularity domain.SummaryGranularity, before time.Time) (int64, error)
+}
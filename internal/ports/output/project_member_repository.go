@@ -0,0 +1,25 @@
+package output
+
+import (
+	"context"
+
+	"github.com/spidey52/api-logs/internal/domain"
+)
+
+// ProjectMemberRepository defines the interface for project membership persistence (Secondary Port)
+type ProjectMemberRepository interface {
+	// Create stores a new membership
+	Create(ctx context.Context, member *domain.ProjectMember) error
+	// Find retrieves the membership of an account in a project
+	Find(ctx context.Context, projectID, accountID string) (*domain.ProjectMember, error)
+	// Update updates the role of an existing membership
+	Update(ctx context.Context, member *domain.ProjectMember) error
+	// Delete removes the membership of an account in a project
+	Delete(ctx context.Context, projectID, accountID string) error
+	// DeleteByProject removes every membership of a project
+	DeleteByProject(ctx context.Context, projectID string) error
+	// ListByProject retrieves the members of a project, oldest first
+	ListByProject(ctx context.Context, projectID string) ([]*domain.ProjectMember, error)
+	// ListByAccount retrieves the memberships of an account, oldest first
+	ListByAccount(ctx context.Context, accountID string) ([]*domain.ProjectMember, error)
+}
@@ -14,8 +14,8 @@ type ProjectRepository interface {
 	// FindByID retrieves a project by ID
 	FindByID(ctx context.Context, id string) (*domain.Project, error)
 
-	// FindByAPIKey retrieves a project by API key
-	FindByAPIKey(ctx context.Context, apiKey string) (*domain.Project, error)
+	// FindByAPIKey retrieves a project by the hash of its API key
+	FindByAPIKey(ctx context.Context, apiKeyHash string) (*domain.Project, error)
 
 	// FindAll retrieves projects based on filter criteria
 	FindAll(ctx context.Context, filter domain.ProjectFilter) ([]*domain.Project, error)
@@ -26,6 +26,11 @@ type ProjectRepository interface {
 	// Delete removes a project by ID
 	Delete(ctx context.Context, id string) error
 
-	// ExistsByAPIKey checks if an API key already exists
-	ExistsByAPIKey(ctx context.Context, apiKey string) (bool, error)
+	// ExistsByAPIKey checks if an API key hash already exists
+	ExistsByAPIKey(ctx context.Context, apiKeyHash string) (bool, error)
+
+	// HashPlaintextAPIKeys replaces keys stored before hashing was introduced
+	// (those without a prefix) with hash(key) and their prefix, and returns how
+	// many projects were converted
+	HashPlaintextAPIKeys(ctx context.Context, hash func(key string) string) (int, error)
 }
@@ -0,0 +1,17 @@
+package output
+
+import (
+	"context"
+
+	"github.com/spidey52/api-logs/internal/domain"
+)
+
+// QuotaRepository defines the interface for project quota persistence (Secondary Port)
+type QuotaRepository interface {
+	// Find retrieves a project's quota
+	Find(ctx context.Context, projectID string) (*domain.ProjectQuota, error)
+	// Upsert creates or replaces a project's quota
+	Upsert(ctx context.Context, quota *domain.ProjectQuota) error
+	// Delete removes a project's quota, reverting it to the defaults
+	Delete(ctx context.Context, projectID string) error
+}
@@ -0,0 +1,17 @@
+package output
+
+import (
+	"context"
+
+	"github.com/spidey52/api-logs/internal/domain"
+)
+
+// RedactionPolicyRepository defines the interface for redaction policy persistence (Secondary Port)
+type RedactionPolicyRepository interface {
+	// Find retrieves a project's redaction policy
+	Find(ctx context.Context, projectID string) (*domain.RedactionPolicy, error)
+	// Upsert creates or replaces a project's redaction policy
+	Upsert(ctx context.Context, policy *domain.RedactionPolicy) error
+	// Delete removes a project's redaction policy, reverting it to the default
+	Delete(ctx context.Context, projectID string) error
+}
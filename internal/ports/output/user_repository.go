@@ -23,8 +23,11 @@ type UserRepository interface {
 	// Delete deletes a user by ID
 	Delete(ctx context.Context, id string) error
 
-	// List retrieves all users with pagination
-	List(ctx context.Context, page, pageSize int) ([]*domain.User, int, error)
+	// DeleteByProject removes every user of a project
+	DeleteByProject(ctx context.Context, projectID string) error
+
+	// List retrieves a project's users with pagination
+	List(ctx context.Context, projectID string, page, pageSize int) ([]*domain.User, int, error)
 
 	GetUserMap(ctx context.Context, ids []string) (map[string]*domain.User, error)
 }
@@ -0,0 +1,240 @@
+// Package accesslog parses web server access logs: the common and combined
+// formats nginx and Apache share, and formats written in the syntax of nginx's
+// log_format directive. A format is compiled once and then matches one line at
+// a time, so files of any size can be read as a stream.
+package accesslog
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+)
+
+const (
+	// Common is the Common Log Format
+	Common = `$remote_addr - $remote_user [$time_local] "$request" $status $body_bytes_sent`
+	// Combined is the Common Log Format with the referer and user agent, the
+	// default of both nginx and Apache
+	Combined = Common + ` "$http_referer" "$http_user_agent"`
+)
+
+// Formats maps the names of the predefined formats to their log_format strings
+var Formats = map[string]string{
+	"common":   Common,
+	"combined": Combined,
+}
+
+var (
+	// ErrMismatch is returned by Parse for a line that does not match the format
+	ErrMismatch = errors.New("accesslog: line does not match the log format")
+	// ErrInvalidRequest is returned by Parse for a malformed request line
+	ErrInvalidRequest = errors.New("accesslog: invalid request line")
+)
+
+// timeLocalLayout is the layout of $time_local
+const timeLocalLayout = "02/Jan/2006:15:04:05 -0700"
+
+// variablePattern matches the variables of a log_format string: $name or ${name}
+var variablePattern = regexp.MustCompile(`\$(?:\{(\w+)\}|(\w+))`)
+
+// Entry is one parsed request. Fields the format does not log are left empty.
+type Entry struct {
+	RemoteAddr string
+	RemoteUser string
+	Time       time.Time
+	Method     string
+	Path       string
+	Query      string // without the leading '?'
+	Protocol   string
+	Status     int
+	BodyBytes  int64
+	// RequestTime comes from $request_time
+	RequestTime time.Duration
+	Referer     string
+	UserAgent   string
+}
+
+// Format is a compiled log format
+type Format struct {
+	pattern   *regexp.Regexp
+	variables []string // the variable of each capture group
+}
+
+// Compile compiles a log_format string. Each variable matches up to the
+// character that follows it in the format, so variables must be separated by
+// literal text; those the parser does not know are matched and ignored.
+func Compile(logFormat string) (*Format, error) {
+	matches := variablePattern.FindAllStringSubmatchIndex(logFormat, -1)
+	if len(matches) == 0 {
+		return nil, fmt.Errorf("accesslog: log format %q has no variables", logFormat)
+	}
+
+	var pattern strings.Builder
+	pattern.WriteString("^")
+	format := &Format{}
+	last := 0
+	for i, match := range matches {
+		pattern.WriteString(regexp.QuoteMeta(logFormat[last:match[0]]))
+		last = match[1]
+
+		var name string
+		if match[2] >= 0 {
+			name = logFormat[match[2]:match[3]] // ${name}
+		} else {
+			name = logFormat[match[4]:match[5]]
+		}
+		format.variables = append(format.variables, name)
+
+		switch {
+		case last == len(logFormat):
+			pattern.WriteString(`(.*)`)
+		case i+1 < len(matches) && matches[i+1][0] == last:
+			pattern.WriteString(`(.*?)`)
+		case logFormat[last] == '"':
+			// Quoted values escape their quotes
+			pattern.WriteString(`((?:[^"\\]|\\.)*)`)
+		default:
+			pattern.WriteString(`([^` + regexp.QuoteMeta(logFormat[last:last+1]) + `]*)`)
+		}
+	}
+	pattern.WriteString(regexp.QuoteMeta(logFormat[last:]))
+	pattern.WriteString("$")
+
+	compiled, err := regexp.Compile(pattern.String())
+	if err != nil {
+		return nil, fmt.Errorf("accesslog: compile log format %q: %w", logFormat, err)
+	}
+	format.pattern = compiled
+	return format, nil
+}
+
+// Parse parses one line, without its line ending
+func (f *Format) Parse(line string) (*Entry, error) {
+	match := f.pattern.FindStringSubmatch(line)
+	if match == nil {
+		return nil, ErrMismatch
+	}
+
+	// "-" stands for a value that is not set
+	values := make(map[string]string, len(f.variables))
+	for i, name := range f.variables {
+		if value := match[i+1]; value != "" && value != "-" {
+			values[name] = unescape(value)
+		}
+	}
+
+	entry := &Entry{
+		RemoteAddr: values["remote_addr"],
+		RemoteUser: values["remote_user"],
+		Referer:    values["http_referer"],
+		UserAgent:  values["http_user_agent"],
+	}
+
+	var uri string
+	if request, ok := values["request"]; ok {
+		parts := strings.Fields(request)
+		if len(parts) < 2 || len(parts) > 3 {
+			return nil, ErrInvalidRequest
+		}
+		entry.Method, uri = parts[0], parts[1]
+		if len(parts) == 3 {
+			entry.Protocol = parts[2]
+		}
+	}
+	if method, ok := values["request_method"]; ok {
+		entry.Method = method
+	}
+	if requestURI, ok := values["request_uri"]; ok {
+		uri = requestURI
+	}
+	entry.Path, entry.Query, _ = strings.Cut(uri, "?")
+	if path, ok := values["uri"]; ok && entry.Path == "" {
+		entry.Path = path
+	}
+	if query, ok := firstOf(values, "args", "query_string"); ok {
+		entry.Query = query
+	}
+	if protocol, ok := values["server_protocol"]; ok {
+		entry.Protocol = protocol
+	}
+
+	var err error
+	if status, ok := values["status"]; ok {
+		if entry.Status, err = strconv.Atoi(status); err != nil {
+			return nil, fmt.Errorf("accesslog: invalid status %q", status)
+		}
+	}
+	if size, ok := firstOf(values, "body_bytes_sent", "bytes_sent"); ok {
+		if entry.BodyBytes, err = strconv.ParseInt(size, 10, 64); err != nil {
+			return nil, fmt.Errorf("accesslog: invalid size %q", size)
+		}
+	}
+	if requestTime, ok := values["request_time"]; ok {
+		seconds, err := strconv.ParseFloat(requestTime, 64)
+		if err != nil || seconds < 0 {
+			return nil, fmt.Errorf("accesslog: invalid request time %q", requestTime)
+		}
+		entry.RequestTime = time.Duration(math.Round(seconds * float64(time.Second)))
+	}
+
+	switch {
+	case values["time_local"] != "":
+		entry.Time, err = time.Parse(timeLocalLayout, values["time_local"])
+	case values["time_iso8601"] != "":
+		entry.Time, err = time.Parse(time.RFC3339, values["time_iso8601"])
+	case values["msec"] != "":
+		var seconds float64
+		seconds, err = strconv.ParseFloat(values["msec"], 64)
+		entry.Time = time.UnixMilli(int64(math.Round(seconds * 1000)))
+	}
+	if err != nil {
+		return nil, fmt.Errorf("accesslog: invalid time: %w", err)
+	}
+
+	return entry, nil
+}
+
+// firstOf returns the first of the named values that is set
+func firstOf(values map[string]string, names ...string) (string, bool) {
+	for _, name := range names {
+		if value, ok := values[name]; ok {
+			return value, true
+		}
+	}
+	return "", false
+}
+
+// unescape reverts the escaping nginx (\xHH) and Apache (\" and \\) apply to
+// logged values
+func unescape(value string) string {
+	if !strings.Contains(value, `\`) {
+		return value
+	}
+
+	var b strings.Builder
+	for i := 0; i < len(value); i++ {
+		if value[i] != '\\' || i+1 == len(value) {
+			b.WriteByte(value[i])
+			continue
+		}
+		switch next := value[i+1]; {
+		case next == 'x' && i+3 < len(value):
+			if n, err := strconv.ParseUint(value[i+2:i+4], 16, 8); err == nil {
+				b.WriteByte(byte(n))
+				i += 3
+				continue
+			}
+			b.WriteByte('\\')
+		case next == '"' || next == '\\':
+			b.WriteByte(next)
+			i++
+		default:
+			b.WriteByte('\\')
+		}
+	}
+	return b.String()
+}
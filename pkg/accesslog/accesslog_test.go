@@ -0,0 +1,117 @@
+package accesslog
+
+import (
+	"errors"
+	"reflect"
+	"testing"
+	"time"
+)
+
+func TestParse(t *testing.T) {
+	at := time.Date(2026, 1, 7, 10, 0, 0, 0, time.FixedZone("", 2*60*60))
+
+	tests := []struct {
+		name   string
+		format string
+		line   string
+		want   *Entry
+	}{
+		{
+			name:   "common",
+			format: Common,
+			line:   `10.0.0.1 - alice [07/Jan/2026:10:00:00 +0200] "GET /items?page=2 HTTP/1.1" 200 512`,
+			want: &Entry{RemoteAddr: "10.0.0.1", RemoteUser: "alice", Time: at, Method: "GET", Path: "/items",
+				Query: "page=2", Protocol: "HTTP/1.1", Status: 200, BodyBytes: 512},
+		},
+		{
+			name:   "combined",
+			format: Combined,
+			line:   `10.0.0.1 - - [07/Jan/2026:10:00:00 +0200] "POST /items HTTP/2.0" 201 - "https://example.com/" "curl/8 \"quoted\""`,
+			want: &Entry{RemoteAddr: "10.0.0.1", Time: at, Method: "POST", Path: "/items", Protocol: "HTTP/2.0",
+				Status: 201, Referer: "https://example.com/", UserAgent: `curl/8 "quoted"`},
+		},
+		{
+			name:   "nginx escapes",
+			format: Combined,
+			line:   `10.0.0.1 - - [07/Jan/2026:10:00:00 +0200] "GET /caf\xC3\xA9 HTTP/1.1" 404 0 "-" "-"`,
+			want:   &Entry{RemoteAddr: "10.0.0.1", Time: at, Method: "GET", Path: "/café", Protocol: "HTTP/1.1", Status: 404},
+		},
+		{
+			name:   "custom",
+			format: `$remote_addr [$time_iso8601] $request_method ${uri}?$args $status ${request_time}s $bytes_sent $unknown`,
+			line:   `10.0.0.1 [2026-01-07T10:00:00+02:00] DELETE /items/42?force=1 204 0.125s 97 anything`,
+			want: &Entry{RemoteAddr: "10.0.0.1", Time: at, Method: "DELETE", Path: "/items/42", Query: "force=1",
+				Status: 204, BodyBytes: 97, RequestTime: 125 * time.Millisecond},
+		},
+		{
+			name:   "msec",
+			format: `$msec $request_method $request_uri $status`,
+			line:   `1767772800.250 GET /health 200`,
+			want:   &Entry{Time: time.UnixMilli(1767772800250), Method: "GET", Path: "/health", Status: 200},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			format, err := Compile(tt.format)
+			if err != nil {
+				t.Fatalf("compile: %v", err)
+			}
+			got, err := format.Parse(tt.line)
+			if err != nil {
+				t.Fatalf("parse: %v", err)
+			}
+			if !got.Time.Equal(tt.want.Time) {
+				t.Fatalf("want time %v, got %v", tt.want.Time, got.Time)
+			}
+			got.Time = tt.want.Time
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Fatalf("entry mismatch:\ngot  %+v\nwant %+v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestParseErrors(t *testing.T) {
+	combined, err := Compile(Combined)
+	if err != nil {
+		t.Fatal(err)
+	}
+	timed, err := Compile(`$request_uri $request_time`)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		name    string
+		format  *Format
+		line    string
+		wantErr error // nil when any error will do
+	}{
+		{"mismatch", combined, `not an access log line`, ErrMismatch},
+		{"truncated", combined, `10.0.0.1 - - [07/Jan/2026:10:00:00 +0200] "GET / HTTP/1.1" 200 0`, ErrMismatch},
+		{"invalid request", combined, `10.0.0.1 - - [07/Jan/2026:10:00:00 +0200] "GARBAGE" 400 0 "-" "-"`, ErrInvalidRequest},
+		{"invalid status", combined, `10.0.0.1 - - [07/Jan/2026:10:00:00 +0200] "GET / HTTP/1.1" OK 0 "-" "-"`, nil},
+		{"invalid size", combined, `10.0.0.1 - - [07/Jan/2026:10:00:00 +0200] "GET / HTTP/1.1" 200 lots "-" "-"`, nil},
+		{"invalid time", combined, `10.0.0.1 - - [yesterday] "GET / HTTP/1.1" 200 0 "-" "-"`, nil},
+		{"negative request time", timed, `/ -1`, nil},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			entry, err := tt.format.Parse(tt.line)
+			if err == nil {
+				t.Fatalf("want an error, got %+v", entry)
+			}
+			if tt.wantErr != nil && !errors.Is(err, tt.wantErr) {
+				t.Fatalf("want %v, got %v", tt.wantErr, err)
+			}
+		})
+	}
+}
+
+func TestCompileWithoutVariables(t *testing.T) {
+	if _, err := Compile("static text"); err == nil {
+		t.Fatal("want an error for a format without variables")
+	}
+}
@@ -0,0 +1,214 @@
+// Package archive stores records in compressed, partitioned objects. Each
+// object is zstd-compressed NDJSON holding records of one kind, for one
+// project and UTC day, under a Hive-style key:
+//
+//	project=<id>/date=2006-01-02/<kind>-<time>-<random>.ndjson.zst
+//
+// so the objects of a range of days are found by listing key prefixes. A Sink
+// keeps the objects: the local filesystem, or any object store implementing
+// it.
+package archive
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/url"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/klauspost/compress/zstd"
+)
+
+// ErrNotFound is returned by Sink.Get when no object is stored under a key
+var ErrNotFound = errors.New("archive: object not found")
+
+const (
+	objectExt  = ".ndjson.zst"
+	dateLayout = "2006-01-02"
+
+	// maxRecordSize bounds a record read back, so a damaged object cannot
+	// exhaust memory
+	maxRecordSize = 64 << 20
+)
+
+// Sink stores archive objects. Implementations must be safe for concurrent
+// use.
+type Sink interface {
+	// Put stores data under key, replacing any object stored there. The
+	// object must be complete once Put returns, or not stored at all.
+	Put(ctx context.Context, key string, data []byte) error
+	// Get opens the object stored under key
+	Get(ctx context.Context, key string) (io.ReadCloser, error)
+	// List returns the keys starting with prefix, sorted
+	List(ctx context.Context, prefix string) ([]string, error)
+}
+
+// Object describes an archived object
+type Object struct {
+	Key       string    `json:"key"`
+	ProjectID string    `json:"project_id"`
+	Day       time.Time `json:"day"`
+	Kind      string    `json:"kind"`
+}
+
+// Archive writes and reads the objects kept by a Sink
+type Archive struct {
+	sink Sink
+}
+
+// New creates an archive keeping its objects in sink
+func New(sink Sink) *Archive {
+	return &Archive{sink: sink}
+}
+
+// Write stores records as one object of a project's kind for the UTC day of
+// day, returning its key. Records are encoded as JSON, one per line.
+func (a *Archive) Write(ctx context.Context, projectID, kind string, day time.Time, records []any) (string, error) {
+	if projectID == "" || kind == "" || strings.ContainsAny(kind, "-/") {
+		return "", fmt.Errorf("archive: invalid project %q or kind %q", projectID, kind)
+	}
+
+	var buf bytes.Buffer
+	encoder, err := zstd.NewWriter(&buf)
+	if err != nil {
+		return "", err
+	}
+	lines := json.NewEncoder(encoder)
+	for _, record := range records {
+		if err := lines.Encode(record); err != nil {
+			encoder.Close()
+			return "", fmt.Errorf("archive: encode record: %w", err)
+		}
+	}
+	if err := encoder.Close(); err != nil {
+		return "", err
+	}
+
+	suffix := make([]byte, 4)
+	if _, err := rand.Read(suffix); err != nil {
+		return "", err
+	}
+	key := fmt.Sprintf("%s%s-%s-%s%s",
+		dayPrefix(projectID, day), kind, time.Now().UTC().Format("20060102T150405.000Z"), hex.EncodeToString(suffix), objectExt)
+
+	if err := a.sink.Put(ctx, key, buf.Bytes()); err != nil {
+		return "", fmt.Errorf("archive: put %s: %w", key, err)
+	}
+	return key, nil
+}
+
+// Objects lists the objects of a project from the UTC day of from through the
+// UTC day of to, oldest day first. An empty kind lists every kind.
+func (a *Archive) Objects(ctx context.Context, projectID, kind string, from, to time.Time) ([]Object, error) {
+	keys, err := a.sink.List(ctx, projectPrefix(projectID))
+	if err != nil {
+		return nil, err
+	}
+
+	first, last := truncateDay(from), truncateDay(to)
+	var objects []Object
+	for _, key := range keys {
+		object, ok := parseKey(key)
+		if !ok || object.ProjectID != projectID || object.Day.Before(first) || object.Day.After(last) {
+			continue
+		}
+		if kind != "" && object.Kind != kind {
+			continue
+		}
+		objects = append(objects, object)
+	}
+
+	sort.SliceStable(objects, func(i, j int) bool {
+		return objects[i].Day.Before(objects[j].Day)
+	})
+	return objects, nil
+}
+
+// Read decodes the records of an object, calling fn with each one in order.
+// It stops at the first error fn returns.
+func (a *Archive) Read(ctx context.Context, key string, fn func(record json.RawMessage) error) error {
+	object, err := a.sink.Get(ctx, key)
+	if err != nil {
+		return err
+	}
+	defer object.Close()
+
+	decoder, err := zstd.NewReader(object)
+	if err != nil {
+		return err
+	}
+	defer decoder.Close()
+
+	scanner := bufio.NewScanner(decoder)
+	scanner.Buffer(make([]byte, 0, 64*1024), maxRecordSize)
+	for scanner.Scan() {
+		line := bytes.TrimSpace(scanner.Bytes())
+		if len(line) == 0 {
+			continue
+		}
+		if err := fn(json.RawMessage(line)); err != nil {
+			return err
+		}
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return fmt.Errorf("archive: read %s: %w", key, err)
+	}
+	return nil
+}
+
+// projectPrefix returns the key prefix of a project's objects
+func projectPrefix(projectID string) string {
+	return "project=" + url.PathEscape(projectID) + "/"
+}
+
+// dayPrefix returns the key prefix of a project's objects for a UTC day
+func dayPrefix(projectID string, day time.Time) string {
+	return projectPrefix(projectID) + "date=" + day.UTC().Format(dateLayout) + "/"
+}
+
+// parseKey reads the partition of an object key
+func parseKey(key string) (Object, bool) {
+	parts := strings.Split(key, "/")
+	if len(parts) != 3 || !strings.HasSuffix(parts[2], objectExt) {
+		return Object{}, false
+	}
+
+	project, ok := strings.CutPrefix(parts[0], "project=")
+	if !ok {
+		return Object{}, false
+	}
+	projectID, err := url.PathUnescape(project)
+	if err != nil {
+		return Object{}, false
+	}
+	date, ok := strings.CutPrefix(parts[1], "date=")
+	if !ok {
+		return Object{}, false
+	}
+	day, err := time.Parse(dateLayout, date)
+	if err != nil {
+		return Object{}, false
+	}
+	kind, _, ok := strings.Cut(parts[2], "-")
+	if !ok {
+		return Object{}, false
+	}
+
+	return Object{Key: key, ProjectID: projectID, Day: day, Kind: kind}, true
+}
+
+// truncateDay returns the start of the UTC day of t
+func truncateDay(t time.Time) time.Time {
+	return t.UTC().Truncate(24 * time.Hour)
+}
@@ -0,0 +1,156 @@
+package archive
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+)
+
+func newTestArchive(t *testing.T) *Archive {
+	t.Helper()
+	sink, err := NewFileSink(t.TempDir())
+	if err != nil {
+		t.Fatal(err)
+	}
+	return New(sink)
+}
+
+type record struct {
+	ID   string `json:"id"`
+	Body string `json:"body"`
+}
+
+func TestWriteRead(t *testing.T) {
+	ctx := context.Background()
+	a := newTestArchive(t)
+	day := time.Date(2026, 1, 7, 23, 30, 0, 0, time.FixedZone("", -2*60*60)) // Jan 8 in UTC
+
+	records := []any{record{ID: "1", Body: "line\nbreak"}, record{ID: "2", Body: strings.Repeat("a", 100*1024)}}
+	key, err := a.Write(ctx, "p/1", "logs", day, records)
+	if err != nil {
+		t.Fatalf("write: %v", err)
+	}
+	if !strings.HasPrefix(key, "project=p%2F1/date=2026-01-08/logs-") || !strings.HasSuffix(key, objectExt) {
+		t.Fatalf("unexpected key %q", key)
+	}
+
+	var read []any
+	err = a.Read(ctx, key, func(raw json.RawMessage) error {
+		var r record
+		if err := json.Unmarshal(raw, &r); err != nil {
+			return err
+		}
+		read = append(read, r)
+		return nil
+	})
+	if err != nil {
+		t.Fatalf("read: %v", err)
+	}
+	if !reflect.DeepEqual(read, records) {
+		t.Fatalf("want the records written, got %d records", len(read))
+	}
+
+	// Read stops at the first error of fn
+	stop := errors.New("stop")
+	calls := 0
+	err = a.Read(ctx, key, func(json.RawMessage) error {
+		calls++
+		return stop
+	})
+	if err != stop || calls != 1 {
+		t.Fatalf("want Read to stop after the first record, got %v after %d calls", err, calls)
+	}
+}
+
+func TestWriteRejectsInvalidKind(t *testing.T) {
+	a := newTestArchive(t)
+	for _, kind := range []string{"", "access-logs", "logs/x"} {
+		if _, err := a.Write(context.Background(), "p1", kind, time.Now(), nil); err == nil {
+			t.Fatalf("want kind %q rejected", kind)
+		}
+	}
+	if _, err := a.Write(context.Background(), "", "logs", time.Now(), nil); err == nil {
+		t.Fatal("want an empty project rejected")
+	}
+}
+
+func TestObjects(t *testing.T) {
+	ctx := context.Background()
+	a := newTestArchive(t)
+	day1 := time.Date(2026, 1, 7, 0, 0, 0, 0, time.UTC)
+	day2, day3 := day1.AddDate(0, 0, 1), day1.AddDate(0, 0, 2)
+
+	write := func(projectID, kind string, day time.Time) string {
+		key, err := a.Write(ctx, projectID, kind, day, []any{record{ID: kind}})
+		if err != nil {
+			t.Fatal(err)
+		}
+		return key
+	}
+	logs3 := write("p1", "logs", day3)
+	logs1 := write("p1", "logs", day1)
+	headers1 := write("p1", "headers", day1)
+	logs2 := write("p1", "logs", day2)
+	write("p10", "logs", day2) // a project whose ID starts with p1
+
+	tests := []struct {
+		name     string
+		kind     string
+		from, to time.Time
+		want     []Object
+	}{
+		{"every day and kind", "", day1, day3.Add(time.Hour), []Object{
+			{Key: headers1, ProjectID: "p1", Day: day1, Kind: "headers"},
+			{Key: logs1, ProjectID: "p1", Day: day1, Kind: "logs"},
+			{Key: logs2, ProjectID: "p1", Day: day2, Kind: "logs"},
+			{Key: logs3, ProjectID: "p1", Day: day3, Kind: "logs"},
+		}},
+		{"one kind", "logs", day1, day3, []Object{
+			{Key: logs1, ProjectID: "p1", Day: day1, Kind: "logs"},
+			{Key: logs2, ProjectID: "p1", Day: day2, Kind: "logs"},
+			{Key: logs3, ProjectID: "p1", Day: day3, Kind: "logs"},
+		}},
+		{"times within the days", "logs", day2.Add(23 * time.Hour), day3.Add(time.Minute), []Object{
+			{Key: logs2, ProjectID: "p1", Day: day2, Kind: "logs"},
+			{Key: logs3, ProjectID: "p1", Day: day3, Kind: "logs"},
+		}},
+		{"no objects in range", "", day3.AddDate(0, 0, 1), day3.AddDate(0, 0, 5), nil},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			objects, err := a.Objects(ctx, "p1", tt.kind, tt.from, tt.to)
+			if err != nil {
+				t.Fatalf("objects: %v", err)
+			}
+			if !reflect.DeepEqual(objects, tt.want) {
+				t.Fatalf("objects mismatch:\ngot  %+v\nwant %+v", objects, tt.want)
+			}
+		})
+	}
+}
+
+func TestFileSinkKeys(t *testing.T) {
+	ctx := context.Background()
+	sink, err := NewFileSink(t.TempDir())
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if _, err := sink.Get(ctx, "project=p1/date=2026-01-07/logs-x"+objectExt); !errors.Is(err, ErrNotFound) {
+		t.Fatalf("want ErrNotFound, got %v", err)
+	}
+	for _, key := range []string{"", "../outside", "/absolute"} {
+		if err := sink.Put(ctx, key, []byte("x")); err == nil {
+			t.Fatalf("want key %q rejected", key)
+		}
+	}
+	keys, err := sink.List(ctx, "project=missing/")
+	if err != nil || len(keys) != 0 {
+		t.Fatalf("want no keys for a missing prefix, got %v, %v", keys, err)
+	}
+}
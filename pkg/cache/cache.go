@@ -8,9 +8,15 @@ import (
 type Cache interface {
 	Get(ctx context.Context, key string, dest any) (bool, error)
 	Set(ctx context.Context, key string, value any, duration time.Duration) error // expirySeconds: 0 means no expiry
+	// SetNX sets a key that does not exist yet, reporting whether it did
+	SetNX(ctx context.Context, key string, value any, duration time.Duration) (bool, error)
 	Delete(ctx context.Context, key string) error
 
 	// extra methods can be added here
 	Incr(ctx context.Context, key string) (int64, error)
-	IncrBy(ctx context.Context, key string, delta int64) (int64, error)
+	IncrBy(ctx context.Context, key string, delta int64) (int64, error) // a missing key counts from 0
+	Expire(ctx context.Context, key string, duration time.Duration) error
+	// IncrByWithTTL is IncrBy that also sets the key's expiry, in the same
+	// operation, when the key has none
+	IncrByWithTTL(ctx context.Context, key string, delta int64, duration time.Duration) (int64, error)
 }
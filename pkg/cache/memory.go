@@ -78,6 +78,26 @@ func (m *MemoryCache) Set(ctx context.Context, key string, value any, duration t
 	return nil
 }
 
+// SetNX implements Cache.
+func (m *MemoryCache) SetNX(ctx context.Context, key string, value any, duration time.Duration) (bool, error) {
+	var buf bytes.Buffer
+	if err := gob.NewEncoder(&buf).Encode(value); err != nil {
+		return false, fmt.Errorf("failed to encode value: %w", err)
+	}
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if item, ok := m.items[key]; ok && (item.expiry.IsZero() || time.Now().Before(item.expiry)) {
+		return false, nil
+	}
+	expiry := time.Time{}
+	if duration > 0 {
+		expiry = time.Now().Add(duration)
+	}
+	m.items[key] = memoryItem{value: buf.Bytes(), expiry: expiry}
+	return true, nil
+}
+
 func (m *MemoryCache) Get(ctx context.Context, key string, dest any) (bool, error) {
 	m.mu.RLock()
 	item, ok := m.items[key]
@@ -110,10 +130,64 @@ func (m *MemoryCache) Delete(ctx context.Context, key string) error {
 
 // Incr implements Cache.
 func (m *MemoryCache) Incr(ctx context.Context, key string) (int64, error) {
-	return 0, fmt.Errorf("Incr not implemented")
+	return m.IncrBy(ctx, key, 1)
 }
 
-// IncrBy implements Cache.
+// IncrBy implements Cache. Counters are stored gob-encoded like any other
+// value, so Get can read them back into an int64; the key's expiry is kept.
 func (m *MemoryCache) IncrBy(ctx context.Context, key string, delta int64) (int64, error) {
-	return 0, fmt.Errorf("IncrBy not implemented")
+	return m.incrBy(key, delta, 0)
+}
+
+// IncrByWithTTL implements Cache.
+func (m *MemoryCache) IncrByWithTTL(ctx context.Context, key string, delta int64, duration time.Duration) (int64, error) {
+	return m.incrBy(key, delta, duration)
+}
+
+// incrBy adds delta to a counter, giving it an expiry of duration if it has
+// none and duration is positive
+func (m *MemoryCache) incrBy(key string, delta int64, duration time.Duration) (int64, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	var current int64
+	item, ok := m.items[key]
+	if ok && !item.expiry.IsZero() && time.Now().After(item.expiry) {
+		ok = false
+		item = memoryItem{}
+	}
+	if ok {
+		if err := gob.NewDecoder(bytes.NewReader(item.value)).Decode(&current); err != nil {
+			return 0, fmt.Errorf("value is not an integer: %w", err)
+		}
+	}
+
+	current += delta
+	var buf bytes.Buffer
+	if err := gob.NewEncoder(&buf).Encode(current); err != nil {
+		return 0, fmt.Errorf("failed to encode value: %w", err)
+	}
+	if item.expiry.IsZero() && duration > 0 {
+		item.expiry = time.Now().Add(duration)
+	}
+	m.items[key] = memoryItem{value: buf.Bytes(), expiry: item.expiry}
+	return current, nil
+}
+
+// Expire implements Cache.
+func (m *MemoryCache) Expire(ctx context.Context, key string, duration time.Duration) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	item, ok := m.items[key]
+	if !ok {
+		return nil
+	}
+	if duration <= 0 {
+		delete(m.items, key)
+		return nil
+	}
+	item.expiry = time.Now().Add(duration)
+	m.items[key] = item
+	return nil
 }
@@ -24,9 +24,17 @@ func (r *RedisCache) Delete(ctx context.Context, key string) error {
 	return r.client.Del(ctx, key).Err()
 }
 
-// Get implements Cache.
+// Get implements Cache. dest is scanned like go-redis scans replies, so it
+// reads back strings, byte slices, numbers and encoding.BinaryUnmarshalers.
 func (r *RedisCache) Get(ctx context.Context, key string, dest any) (bool, error) {
-	return false, nil
+	err := r.client.Get(ctx, key).Scan(dest)
+	if err == redis.Nil {
+		return false, nil
+	}
+	if err != nil {
+		return false, err
+	}
+	return true, nil
 }
 
 // Incr implements Cache.
@@ -47,11 +55,36 @@ func (r *RedisCache) IncrBy(ctx context.Context, key string, delta int64) (int64
 	return val, nil
 }
 
+// incrByWithTTL increments a counter and sets its expiry, in milliseconds,
+// when it has none, so a counter can never be left without one
+var incrByWithTTL = redis.NewScript(`
+local count = redis.call("INCRBY", KEYS[1], ARGV[1])
+if tonumber(ARGV[2]) > 0 and redis.call("PTTL", KEYS[1]) == -1 then
+	redis.call("PEXPIRE", KEYS[1], ARGV[2])
+end
+return count
+`)
+
+// IncrByWithTTL implements Cache.
+func (r *RedisCache) IncrByWithTTL(ctx context.Context, key string, delta int64, duration time.Duration) (int64, error) {
+	return incrByWithTTL.Run(ctx, r.client, []string{key}, delta, duration.Milliseconds()).Int64()
+}
+
+// Expire implements Cache.
+func (r *RedisCache) Expire(ctx context.Context, key string, duration time.Duration) error {
+	return r.client.Expire(ctx, key, duration).Err()
+}
+
 // Set implements Cache.
 func (r *RedisCache) Set(ctx context.Context, key string, value any, duration time.Duration) error {
 	return r.client.Set(ctx, key, value, duration).Err()
 }
 
+// SetNX implements Cache.
+func (r *RedisCache) SetNX(ctx context.Context, key string, value any, duration time.Duration) (bool, error) {
+	return r.client.SetNX(ctx, key, value, duration).Result()
+}
+
 func (r *RedisCache) Close() error {
 	return r.client.Close()
 }
@@ -0,0 +1,175 @@
+// Package envelope implements envelope encryption with AES-256-GCM. Values
+// are sealed with a data key, and data keys are stored wrapped (encrypted) by
+// a master key from a Keyring. Rotating the master key only re-wraps the data
+// keys; values sealed with them stay as they are.
+package envelope
+
+import (
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/rand"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"os"
+	"strings"
+)
+
+// KeySize is the size in bytes of master and data keys
+const KeySize = 32
+
+var (
+	// ErrUnknownMasterKey is returned when a data key is wrapped by a master
+	// key the keyring does not hold
+	ErrUnknownMasterKey = errors.New("envelope: unknown master key")
+	// ErrDecrypt is returned when a value or data key fails authentication:
+	// it was sealed with another key, with other additional data, or altered
+	ErrDecrypt = errors.New("envelope: decryption failed")
+)
+
+// MasterKey is a named key encrypting data keys
+type MasterKey struct {
+	ID  string
+	Key []byte
+}
+
+// Keyring holds the master keys. The first key wraps new data keys; the
+// others only unwrap data keys that were wrapped before a rotation.
+type Keyring struct {
+	keys []MasterKey
+}
+
+// NewKeyring creates a keyring whose first key is the active one
+func NewKeyring(keys ...MasterKey) (*Keyring, error) {
+	if len(keys) == 0 {
+		return nil, errors.New("envelope: no master keys")
+	}
+
+	seen := make(map[string]bool, len(keys))
+	for _, key := range keys {
+		switch {
+		case key.ID == "" || strings.ContainsAny(key.ID, ": \t\n"):
+			return nil, fmt.Errorf("envelope: invalid master key ID %q", key.ID)
+		case len(key.Key) != KeySize:
+			return nil, fmt.Errorf("envelope: master key %q must be %d bytes, got %d", key.ID, KeySize, len(key.Key))
+		case seen[key.ID]:
+			return nil, fmt.Errorf("envelope: duplicate master key %q", key.ID)
+		}
+		seen[key.ID] = true
+	}
+	return &Keyring{keys: keys}, nil
+}
+
+// ActiveKeyID returns the ID of the master key new data keys are wrapped by
+func (k *Keyring) ActiveKeyID() string {
+	return k.keys[0].ID
+}
+
+// Wrap encrypts a data key with the active master key, returning the ID of
+// that key with the wrapped data key
+func (k *Keyring) Wrap(dataKey []byte) (string, []byte, error) {
+	active := k.keys[0]
+	wrapped, err := Seal(active.Key, dataKey, []byte(active.ID))
+	if err != nil {
+		return "", nil, err
+	}
+	return active.ID, wrapped, nil
+}
+
+// Unwrap decrypts a data key wrapped by the named master key
+func (k *Keyring) Unwrap(masterKeyID string, wrapped []byte) ([]byte, error) {
+	for _, key := range k.keys {
+		if key.ID == masterKeyID {
+			return Open(key.Key, wrapped, []byte(key.ID))
+		}
+	}
+	return nil, fmt.Errorf("%w %q", ErrUnknownMasterKey, masterKeyID)
+}
+
+// NewDataKey returns a random data key
+func NewDataKey() ([]byte, error) {
+	key := make([]byte, KeySize)
+	if _, err := rand.Read(key); err != nil {
+		return nil, err
+	}
+	return key, nil
+}
+
+// Seal encrypts and authenticates plaintext, binding it to additionalData:
+// Open fails unless it is given the same additional data. The result holds
+// the random nonce followed by the ciphertext.
+func Seal(key, plaintext, additionalData []byte) ([]byte, error) {
+	aead, err := newAEAD(key)
+	if err != nil {
+		return nil, err
+	}
+
+	nonce := make([]byte, aead.NonceSize(), aead.NonceSize()+len(plaintext)+aead.Overhead())
+	if _, err := rand.Read(nonce); err != nil {
+		return nil, err
+	}
+	return aead.Seal(nonce, nonce, plaintext, additionalData), nil
+}
+
+// Open decrypts a value sealed by Seal
+func Open(key, sealed, additionalData []byte) ([]byte, error) {
+	aead, err := newAEAD(key)
+	if err != nil {
+		return nil, err
+	}
+
+	if len(sealed) < aead.NonceSize()+aead.Overhead() {
+		return nil, ErrDecrypt
+	}
+	nonce, ciphertext := sealed[:aead.NonceSize()], sealed[aead.NonceSize():]
+	plaintext, err := aead.Open(nil, nonce, ciphertext, additionalData)
+	if err != nil {
+		return nil, ErrDecrypt
+	}
+	return plaintext, nil
+}
+
+func newAEAD(key []byte) (cipher.AEAD, error) {
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		return nil, fmt.Errorf("envelope: %w", err)
+	}
+	return cipher.NewGCM(block)
+}
+
+// ParseMasterKeys parses master keys written as id:base64key, separated by
+// commas or newlines. Blank lines and lines starting with # are skipped.
+func ParseMasterKeys(spec string) ([]MasterKey, error) {
+	var keys []MasterKey
+	for _, line := range strings.FieldsFunc(spec, func(r rune) bool { return r == ',' || r == '\n' }) {
+		line = strings.TrimSpace(line)
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+
+		id, encoded, ok := strings.Cut(line, ":")
+		if !ok {
+			return nil, fmt.Errorf("envelope: master key %q must be written as id:base64key", line)
+		}
+		key, err := base64.StdEncoding.DecodeString(strings.TrimSpace(encoded))
+		if err != nil {
+			return nil, fmt.Errorf("envelope: master key %q is not valid base64: %w", id, err)
+		}
+		keys = append(keys, MasterKey{ID: strings.TrimSpace(id), Key: key})
+	}
+	return keys, nil
+}
+
+// ReadKeyfile reads master keys from a file with one id:base64key per line
+func ReadKeyfile(path string) ([]MasterKey, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+
+	keys, err := ParseMasterKeys(string(data))
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", path, err)
+	}
+	return keys, nil
+}
@@ -0,0 +1,164 @@
+package envelope
+
+import (
+	"bytes"
+	"encoding/base64"
+	"errors"
+	"strings"
+	"testing"
+)
+
+// testKey returns a key of KeySize bytes filled with b
+func testKey(b byte) []byte {
+	return bytes.Repeat([]byte{b}, KeySize)
+}
+
+func TestSealOpen(t *testing.T) {
+	key := testKey(1)
+	sealed, err := Seal(key, []byte("secret"), []byte("log-1"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if bytes.Contains(sealed, []byte("secret")) {
+		t.Fatal("want the plaintext encrypted")
+	}
+
+	plaintext, err := Open(key, sealed, []byte("log-1"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(plaintext) != "secret" {
+		t.Fatalf("want secret, got %q", plaintext)
+	}
+
+	again, err := Seal(key, []byte("secret"), []byte("log-1"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if bytes.Equal(sealed, again) {
+		t.Fatal("want a fresh nonce for every seal")
+	}
+}
+
+func TestOpenFails(t *testing.T) {
+	key := testKey(1)
+	sealed, err := Seal(key, []byte("secret"), []byte("log-1"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	tampered := bytes.Clone(sealed)
+	tampered[len(tampered)-1] ^= 1
+
+	tests := []struct {
+		name   string
+		key    []byte
+		sealed []byte
+		ad     string
+	}{
+		{"wrong key", testKey(2), sealed, "log-1"},
+		{"wrong additional data", key, sealed, "log-2"},
+		{"tampered", key, tampered, "log-1"},
+		{"short", key, sealed[:10], "log-1"},
+		{"empty", key, nil, "log-1"},
+	}
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			if _, err := Open(tc.key, tc.sealed, []byte(tc.ad)); !errors.Is(err, ErrDecrypt) {
+				t.Fatalf("want ErrDecrypt, got %v", err)
+			}
+		})
+	}
+}
+
+func TestKeyringWrapUnwrap(t *testing.T) {
+	keyring, err := NewKeyring(MasterKey{ID: "k2", Key: testKey(2)}, MasterKey{ID: "k1", Key: testKey(1)})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if keyring.ActiveKeyID() != "k2" {
+		t.Fatalf("want the first key active, got %s", keyring.ActiveKeyID())
+	}
+
+	dataKey, err := NewDataKey()
+	if err != nil {
+		t.Fatal(err)
+	}
+	id, wrapped, err := keyring.Wrap(dataKey)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if id != "k2" {
+		t.Fatalf("want the data key wrapped by k2, got %s", id)
+	}
+	unwrapped, err := keyring.Unwrap(id, wrapped)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !bytes.Equal(unwrapped, dataKey) {
+		t.Fatal("want the data key unwrapped")
+	}
+
+	// A data key is bound to the master key that wrapped it
+	if _, err := keyring.Unwrap("k1", wrapped); !errors.Is(err, ErrDecrypt) {
+		t.Fatalf("want ErrDecrypt under another master key, got %v", err)
+	}
+	if _, err := keyring.Unwrap("k3", wrapped); !errors.Is(err, ErrUnknownMasterKey) {
+		t.Fatalf("want ErrUnknownMasterKey, got %v", err)
+	}
+}
+
+func TestNewKeyringRejectsBadKeys(t *testing.T) {
+	tests := []struct {
+		name string
+		keys []MasterKey
+	}{
+		{"none", nil},
+		{"empty ID", []MasterKey{{ID: "", Key: testKey(1)}}},
+		{"ID with a colon", []MasterKey{{ID: "k:1", Key: testKey(1)}}},
+		{"ID with a space", []MasterKey{{ID: "k 1", Key: testKey(1)}}},
+		{"short key", []MasterKey{{ID: "k1", Key: testKey(1)[:16]}}},
+		{"duplicate ID", []MasterKey{{ID: "k1", Key: testKey(1)}, {ID: "k1", Key: testKey(2)}}},
+	}
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			if _, err := NewKeyring(tc.keys...); err == nil {
+				t.Fatal("want an error")
+			}
+		})
+	}
+}
+
+func TestParseMasterKeys(t *testing.T) {
+	k1 := base64.StdEncoding.EncodeToString(testKey(1))
+	k2 := base64.StdEncoding.EncodeToString(testKey(2))
+	spec := strings.Join([]string{
+		"# active key first",
+		"k2: " + k2,
+		"",
+		"   ",
+		"# retired",
+		" k1 :" + k1 + " ",
+	}, "\n")
+
+	keys, err := ParseMasterKeys(spec)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(keys) != 2 || keys[0].ID != "k2" || keys[1].ID != "k1" {
+		t.Fatalf("want keys k2 and k1, got %+v", keys)
+	}
+	if !bytes.Equal(keys[0].Key, testKey(2)) || !bytes.Equal(keys[1].Key, testKey(1)) {
+		t.Fatal("want the keys decoded")
+	}
+
+	keys, err = ParseMasterKeys("k2:" + k2 + ",k1:" + k1)
+	if err != nil || len(keys) != 2 {
+		t.Fatalf("want comma-separated keys parsed, got %v, %+v", err, keys)
+	}
+
+	for _, spec := range []string{"k1", "k1:not base64!"} {
+		if _, err := ParseMasterKeys(spec); err == nil {
+			t.Fatalf("%q: want an error", spec)
+		}
+	}
+}
@@ -0,0 +1,115 @@
+// Package har defines the HTTP Archive (HAR) 1.2 format browsers export from
+// their developer tools. Only the fields needed to describe a request and its
+// response are modelled; fields this package does not model are dropped.
+// See http://www.softwareishard.com/blog/har-12-spec/.
+package har
+
+// Version is the HAR version this package writes
+const Version = "1.2"
+
+// HAR is a HAR document
+type HAR struct {
+	Log Log `json:"log"`
+}
+
+// Log is the root of a HAR document
+type Log struct {
+	Version string  `json:"version"`
+	Creator Creator `json:"creator"`
+	Entries []Entry `json:"entries"`
+	Comment string  `json:"comment,omitempty"`
+}
+
+// Creator names the application that wrote the document
+type Creator struct {
+	Name    string `json:"name"`
+	Version string `json:"version"`
+}
+
+// Entry is one request and its response
+type Entry struct {
+	StartedDateTime string   `json:"startedDateTime"` // ISO 8601
+	Time            float64  `json:"time"`            // total time in milliseconds
+	Request         Request  `json:"request"`
+	Response        Response `json:"response"`
+	Cache           Cache    `json:"cache"`
+	Timings         Timings  `json:"timings"`
+	ServerIPAddress string   `json:"serverIPAddress,omitempty"`
+	Comment         string   `json:"comment,omitempty"`
+
+	// ID is the API log the entry was exported from
+	ID string `json:"_id,omitempty"`
+}
+
+// Request describes a request
+type Request struct {
+	Method      string      `json:"method"`
+	URL         string      `json:"url"` // absolute, with the query string
+	HTTPVersion string      `json:"httpVersion"`
+	Cookies     []Cookie    `json:"cookies"`
+	Headers     []NameValue `json:"headers"`
+	QueryString []NameValue `json:"queryString"`
+	PostData    *PostData   `json:"postData,omitempty"`
+	HeadersSize int64       `json:"headersSize"` // -1 when unknown
+	BodySize    int64       `json:"bodySize"`    // -1 when unknown
+}
+
+// Response describes a response
+type Response struct {
+	Status      int         `json:"status"`
+	StatusText  string      `json:"statusText"`
+	HTTPVersion string      `json:"httpVersion"`
+	Cookies     []Cookie    `json:"cookies"`
+	Headers     []NameValue `json:"headers"`
+	Content     Content     `json:"content"`
+	RedirectURL string      `json:"redirectURL"`
+	HeadersSize int64       `json:"headersSize"` // -1 when unknown
+	BodySize    int64       `json:"bodySize"`    // -1 when unknown
+
+	// Error is the reason a request failed; browsers set it for requests
+	// without a response
+	Error string `json:"_error,omitempty"`
+}
+
+// NameValue is a header or query string parameter
+type NameValue struct {
+	Name  string `json:"name"`
+	Value string `json:"value"`
+}
+
+// Cookie is a request or response cookie
+type Cookie struct {
+	Name  string `json:"name"`
+	Value string `json:"value"`
+}
+
+// PostData is the body of a request
+type PostData struct {
+	MimeType string      `json:"mimeType"`
+	Text     string      `json:"text"`
+	Params   []NameValue `json:"params,omitempty"`
+}
+
+// Content is the body of a response
+type Content struct {
+	Size     int64  `json:"size"`
+	MimeType string `json:"mimeType"`
+	Text     string `json:"text,omitempty"`
+	Encoding string `json:"encoding,omitempty"` // "base64" for binary text
+}
+
+// Cache describes the browser cache; entries written by this package leave it
+// empty
+type Cache struct{}
+
+// Timings splits the time of an entry into phases, in milliseconds; -1 marks a
+// phase that does not apply
+type Timings struct {
+	Blocked float64 `json:"blocked,omitempty"`
+	DNS     float64 `json:"dns,omitempty"`
+	Connect float64 `json:"connect,omitempty"`
+	Send    float64 `json:"send"`
+	Wait    float64 `json:"wait"`
+	Receive float64 `json:"receive"`
+	SSL     float64 `json:"ssl,omitempty"`
+}
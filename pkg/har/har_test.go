@@ -0,0 +1,80 @@
+package har
+
+import (
+	"encoding/json"
+	"reflect"
+	"testing"
+)
+
+func TestJSONRoundTrip(t *testing.T) {
+	document := HAR{Log: Log{
+		Version: Version,
+		Creator: Creator{Name: "api-logs", Version: Version},
+		Entries: []Entry{{
+			StartedDateTime: "2026-01-07T10:00:00.123Z",
+			Time:            42,
+			Request: Request{
+				Method:      "POST",
+				URL:         "https://api.example.com/items?page=2",
+				HTTPVersion: "HTTP/1.1",
+				Cookies:     []Cookie{},
+				Headers:     []NameValue{{Name: "Content-Type", Value: "application/json"}},
+				QueryString: []NameValue{{Name: "page", Value: "2"}},
+				PostData:    &PostData{MimeType: "application/json", Text: `{"name":"a"}`},
+				HeadersSize: -1,
+				BodySize:    12,
+			},
+			Response: Response{
+				Status:      502,
+				StatusText:  "Bad Gateway",
+				HTTPVersion: "HTTP/1.1",
+				Cookies:     []Cookie{},
+				Headers:     []NameValue{},
+				Content:     Content{Size: 4, MimeType: "image/png", Text: "iVBORw==", Encoding: "base64"},
+				HeadersSize: -1,
+				BodySize:    -1,
+				Error:       "upstream timed out",
+			},
+			Timings: Timings{Wait: 42},
+			ID:      "log-1",
+		}},
+	}}
+
+	encoded, err := json.Marshal(document)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var decoded HAR
+	if err := json.Unmarshal(encoded, &decoded); err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(decoded, document) {
+		t.Fatalf("round trip mismatch:\ngot  %+v\nwant %+v", decoded, document)
+	}
+
+	// Fields the spec requires are written even when empty, and the fields
+	// this service adds use the underscore prefix of custom fields
+	var raw struct {
+		Log struct {
+			Entries []map[string]json.RawMessage `json:"entries"`
+		} `json:"log"`
+	}
+	if err := json.Unmarshal(encoded, &raw); err != nil {
+		t.Fatal(err)
+	}
+	entry := raw.Log.Entries[0]
+	for _, field := range []string{"cache", "timings", "_id"} {
+		if _, ok := entry[field]; !ok {
+			t.Fatalf("entry is missing %q: %s", field, encoded)
+		}
+	}
+	var response map[string]json.RawMessage
+	if err := json.Unmarshal(entry["response"], &response); err != nil {
+		t.Fatal(err)
+	}
+	for _, field := range []string{"cookies", "headers", "redirectURL", "_error"} {
+		if _, ok := response[field]; !ok {
+			t.Fatalf("response is missing %q: %s", field, entry["response"])
+		}
+	}
+}
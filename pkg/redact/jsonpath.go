@@ -0,0 +1,125 @@
+package redact
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// step is one selector of a JSON path
+type step struct {
+	// descendant selects at any depth (..), not only among the children
+	descendant bool
+	wildcard   bool
+	name       string
+	index      int // -1 unless the step is an array index
+}
+
+// jsonPath is a parsed JSON path
+type jsonPath struct {
+	steps []step
+}
+
+// parseJSONPath parses the subset of JSONPath that selects fields: $ followed
+// by .name, ['name'], .*, [*], [n] and their recursive forms ..name, ..['name']
+// and ..*
+func parseJSONPath(expr string) (jsonPath, error) {
+	invalid := func(reason string) (jsonPath, error) {
+		return jsonPath{}, fmt.Errorf("invalid JSON path %q: %s", expr, reason)
+	}
+
+	if !strings.HasPrefix(expr, "$") {
+		return invalid("must start with $")
+	}
+
+	var path jsonPath
+	rest := expr[1:]
+	for rest != "" {
+		s := step{index: -1}
+		switch {
+		case strings.HasPrefix(rest, ".."):
+			s.descendant = true
+			rest = rest[2:]
+		case rest[0] == '.':
+			rest = rest[1:]
+		case rest[0] != '[':
+			return invalid("expected . or [")
+		}
+
+		switch {
+		case rest == "":
+			return invalid("ends without a field")
+		case rest[0] == '[':
+			end := strings.IndexByte(rest, ']')
+			if end < 0 {
+				return invalid("unterminated [")
+			}
+			selector := rest[1:end]
+			rest = rest[end+1:]
+
+			switch {
+			case selector == "*":
+				s.wildcard = true
+			case len(selector) >= 2 && (selector[0] == '\'' || selector[0] == '"') && selector[len(selector)-1] == selector[0]:
+				s.name = selector[1 : len(selector)-1]
+			default:
+				index, err := strconv.Atoi(selector)
+				if err != nil || index < 0 {
+					return invalid("bad selector [" + selector + "]")
+				}
+				s.index = index
+			}
+		default:
+			end := strings.IndexAny(rest, ".[")
+			if end < 0 {
+				end = len(rest)
+			}
+			s.name = rest[:end]
+			rest = rest[end:]
+			if s.name == "" {
+				return invalid("empty field name")
+			}
+			if s.name == "*" {
+				s.wildcard, s.name = true, ""
+			}
+		}
+
+		path.steps = append(path.steps, s)
+	}
+	return path, nil
+}
+
+// apply masks the values the path selects, returning the result. Objects and
+// arrays are changed in place.
+func (p jsonPath) apply(value any) any {
+	return applySteps(value, p.steps)
+}
+
+func applySteps(value any, steps []step) any {
+	if len(steps) == 0 {
+		return Mask
+	}
+	s := steps[0]
+
+	switch v := value.(type) {
+	case map[string]any:
+		for key := range v {
+			if s.index < 0 && (s.wildcard || s.name == key) {
+				v[key] = applySteps(v[key], steps[1:])
+			}
+			if s.descendant {
+				v[key] = applySteps(v[key], steps)
+			}
+		}
+	case []any:
+		for i := range v {
+			if s.wildcard || s.index == i {
+				v[i] = applySteps(v[i], steps[1:])
+			}
+			if s.descendant {
+				v[i] = applySteps(v[i], steps)
+			}
+		}
+	}
+	return value
+}
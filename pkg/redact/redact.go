@@ -0,0 +1,203 @@
+// Package redact masks sensitive values in logged requests: headers and
+// parameters by name, JSON body fields by path, and anything matching a
+// pattern (emails, tokens, card numbers or custom regexes) wherever it
+// appears. Rules are compiled once into a Redactor, which is safe for
+// concurrent use.
+package redact
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+)
+
+// Mask replaces redacted values
+const Mask = "[REDACTED]"
+
+// pattern is a compiled detector. valid, when set, confirms a match, so
+// look-alikes are left alone.
+type pattern struct {
+	re    *regexp.Regexp
+	valid func(match string) bool
+}
+
+// builtins are the detectors Rules.Patterns can name
+var builtins = map[string]pattern{
+	"email": {re: regexp.MustCompile(`[A-Za-z0-9._%+\-]+@[A-Za-z0-9\-]+(?:\.[A-Za-z0-9\-]+)*\.[A-Za-z]{2,}`)},
+	"token": {re: regexp.MustCompile(`(?i:\b(?:bearer|basic)\s+[A-Za-z0-9\-._~+/]+=*)` +
+		`|\beyJ[A-Za-z0-9_\-]+\.[A-Za-z0-9_\-]+\.[A-Za-z0-9_\-]*` + // JWT
+		`|\b(?:sk|pk|rk)_(?:live|test)_[A-Za-z0-9]{10,}` + // Stripe
+		`|\bgh[pousr]_[A-Za-z0-9]{36,}` + // GitHub
+		`|\bxox[abpr]-[A-Za-z0-9\-]{10,}` + // Slack
+		`|\bAKIA[0-9A-Z]{16}\b` + // AWS access key ID
+		`|\b(?:dev|prod)_[0-9a-f]{32}\b`)}, // API logs project keys
+	"card_number": {re: regexp.MustCompile(`\b\d(?:[ \-]?\d){12,18}\b`), valid: luhn},
+}
+
+// Patterns returns the names of the built-in patterns
+func Patterns() []string {
+	return []string{"card_number", "email", "token"}
+}
+
+// Rules selects what a Redactor masks
+type Rules struct {
+	// Headers and Params name headers and URL parameters whose values are
+	// masked, case-insensitively
+	Headers []string
+	Params  []string
+	// JSONPaths select body fields whose values are masked, e.g. $.password,
+	// $..card_number or $.items[*].token
+	JSONPaths []string
+	// Patterns name built-in detectors and Regexes add custom ones; their
+	// matches are masked in every header, parameter and body string
+	Patterns []string
+	Regexes  []string
+}
+
+// Redactor applies compiled Rules
+type Redactor struct {
+	headers  map[string]bool
+	params   map[string]bool
+	paths    []jsonPath
+	patterns []pattern
+}
+
+// Compile compiles rules, reporting the first invalid JSON path, pattern name
+// or regex
+func Compile(rules Rules) (*Redactor, error) {
+	r := &Redactor{
+		headers: lowerSet(rules.Headers),
+		params:  lowerSet(rules.Params),
+	}
+
+	for _, expr := range rules.JSONPaths {
+		path, err := parseJSONPath(expr)
+		if err != nil {
+			return nil, err
+		}
+		r.paths = append(r.paths, path)
+	}
+
+	for _, name := range rules.Patterns {
+		p, ok := builtins[name]
+		if !ok {
+			return nil, fmt.Errorf("unknown pattern %q, must be one of %s", name, strings.Join(Patterns(), ", "))
+		}
+		r.patterns = append(r.patterns, p)
+	}
+	for _, expr := range rules.Regexes {
+		re, err := regexp.Compile(expr)
+		if err != nil {
+			return nil, fmt.Errorf("invalid regex %q: %w", expr, err)
+		}
+		r.patterns = append(r.patterns, pattern{re: re})
+	}
+
+	return r, nil
+}
+
+// Headers masks headers in place. Values are strings, or lists of them for
+// repeated headers.
+func (r *Redactor) Headers(headers map[string]any) {
+	for name, value := range headers {
+		if r.headers[strings.ToLower(name)] {
+			headers[name] = Mask
+			continue
+		}
+		headers[name] = r.walk(value)
+	}
+}
+
+// Params masks path or query parameters in place
+func (r *Redactor) Params(params map[string]string) {
+	for name, value := range params {
+		if r.params[strings.ToLower(name)] {
+			params[name] = Mask
+			continue
+		}
+		params[name] = r.Text(value)
+	}
+}
+
+// Body masks a decoded JSON body, or a body kept as text, returning the
+// result. Objects and arrays are changed in place.
+func (r *Redactor) Body(body any) any {
+	for _, path := range r.paths {
+		body = path.apply(body)
+	}
+	return r.walk(body)
+}
+
+// Text masks the pattern matches of a string
+func (r *Redactor) Text(s string) string {
+	if s == "" || s == Mask {
+		return s
+	}
+	for _, p := range r.patterns {
+		if p.valid == nil {
+			s = p.re.ReplaceAllLiteralString(s, Mask)
+			continue
+		}
+		s = p.re.ReplaceAllStringFunc(s, func(match string) string {
+			if p.valid(match) {
+				return Mask
+			}
+			return match
+		})
+	}
+	return s
+}
+
+// walk masks the pattern matches of every string in a value
+func (r *Redactor) walk(value any) any {
+	if len(r.patterns) == 0 {
+		return value
+	}
+
+	switch v := value.(type) {
+	case string:
+		return r.Text(v)
+	case map[string]any:
+		for key, child := range v {
+			v[key] = r.walk(child)
+		}
+	case []any:
+		for i, child := range v {
+			v[i] = r.walk(child)
+		}
+	case []string:
+		for i, child := range v {
+			v[i] = r.Text(child)
+		}
+	}
+	return value
+}
+
+func lowerSet(names []string) map[string]bool {
+	set := make(map[string]bool, len(names))
+	for _, name := range names {
+		set[strings.ToLower(name)] = true
+	}
+	return set
+}
+
+// luhn reports whether the digits of s pass the Luhn checksum card numbers
+// carry
+func luhn(s string) bool {
+	sum, double := 0, false
+	for i := len(s) - 1; i >= 0; i-- {
+		c := s[i]
+		if c < '0' || c > '9' {
+			continue
+		}
+		d := int(c - '0')
+		if double {
+			if d *= 2; d > 9 {
+				d -= 9
+			}
+		}
+		sum += d
+		double = !double
+	}
+	return sum%10 == 0
+}
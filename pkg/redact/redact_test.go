@@ -0,0 +1,147 @@
+package redact
+
+import (
+	"reflect"
+	"testing"
+)
+
+func mustCompile(t *testing.T, rules Rules) *Redactor {
+	t.Helper()
+	r, err := Compile(rules)
+	if err != nil {
+		t.Fatalf("compile: %v", err)
+	}
+	return r
+}
+
+func TestCompileErrors(t *testing.T) {
+	tests := []struct {
+		name  string
+		rules Rules
+	}{
+		{"path without root", Rules{JSONPaths: []string{"password"}}},
+		{"path ending in a dot", Rules{JSONPaths: []string{"$.user."}}},
+		{"unterminated bracket", Rules{JSONPaths: []string{"$.items[0"}}},
+		{"negative index", Rules{JSONPaths: []string{"$.items[-1]"}}},
+		{"unknown pattern", Rules{Patterns: []string{"ssn"}}},
+		{"invalid regex", Rules{Regexes: []string{"("}}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if _, err := Compile(tt.rules); err == nil {
+				t.Fatal("want an error")
+			}
+		})
+	}
+}
+
+func TestHeadersAndParams(t *testing.T) {
+	r := mustCompile(t, Rules{Headers: []string{"Authorization"}, Params: []string{"API_KEY"}, Patterns: []string{"email"}})
+
+	headers := map[string]any{
+		"authorization": "Bearer abc",
+		"X-User":        []any{"alice@example.com", "bob"},
+		"Accept":        "application/json",
+	}
+	r.Headers(headers)
+	wantHeaders := map[string]any{
+		"authorization": Mask,
+		"X-User":        []any{Mask, "bob"},
+		"Accept":        "application/json",
+	}
+	if !reflect.DeepEqual(headers, wantHeaders) {
+		t.Fatalf("headers mismatch: %v", headers)
+	}
+
+	params := map[string]string{"api_key": "secret", "to": "mail alice@example.com now", "page": "2"}
+	r.Params(params)
+	wantParams := map[string]string{"api_key": Mask, "to": "mail " + Mask + " now", "page": "2"}
+	if !reflect.DeepEqual(params, wantParams) {
+		t.Fatalf("params mismatch: %v", params)
+	}
+}
+
+func TestBodyJSONPaths(t *testing.T) {
+	tests := []struct {
+		path string
+		want any
+	}{
+		{"$.password", map[string]any{"password": Mask, "user": map[string]any{"token": "t1", "name": "a"}, "items": []any{map[string]any{"token": "t2"}, map[string]any{"token": "t3"}}, "api-key": "k"}},
+		{"$..token", map[string]any{"password": "p", "user": map[string]any{"token": Mask, "name": "a"}, "items": []any{map[string]any{"token": Mask}, map[string]any{"token": Mask}}, "api-key": "k"}},
+		{"$.items[*].token", map[string]any{"password": "p", "user": map[string]any{"token": "t1", "name": "a"}, "items": []any{map[string]any{"token": Mask}, map[string]any{"token": Mask}}, "api-key": "k"}},
+		{"$.items[1]", map[string]any{"password": "p", "user": map[string]any{"token": "t1", "name": "a"}, "items": []any{map[string]any{"token": "t2"}, Mask}, "api-key": "k"}},
+		{"$['api-key']", map[string]any{"password": "p", "user": map[string]any{"token": "t1", "name": "a"}, "items": []any{map[string]any{"token": "t2"}, map[string]any{"token": "t3"}}, "api-key": Mask}},
+		{"$.user.*", map[string]any{"password": "p", "user": map[string]any{"token": Mask, "name": Mask}, "items": []any{map[string]any{"token": "t2"}, map[string]any{"token": "t3"}}, "api-key": "k"}},
+		{"$.missing.token", map[string]any{"password": "p", "user": map[string]any{"token": "t1", "name": "a"}, "items": []any{map[string]any{"token": "t2"}, map[string]any{"token": "t3"}}, "api-key": "k"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.path, func(t *testing.T) {
+			body := map[string]any{
+				"password": "p",
+				"user":     map[string]any{"token": "t1", "name": "a"},
+				"items":    []any{map[string]any{"token": "t2"}, map[string]any{"token": "t3"}},
+				"api-key":  "k",
+			}
+			got := mustCompile(t, Rules{JSONPaths: []string{tt.path}}).Body(body)
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Fatalf("body mismatch:\ngot  %v\nwant %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestText(t *testing.T) {
+	tests := []struct {
+		name    string
+		pattern string
+		regex   string
+		text    string
+		want    string
+	}{
+		{"email", "email", "", "contact alice.smith+logs@mail.example.co.uk today", "contact " + Mask + " today"},
+		{"bearer token", "token", "", "Authorization: bearer abc.DEF-123", "Authorization: " + Mask},
+		{"jwt", "token", "", "jwt=eyJhbGciOiJIUzI1NiJ9.eyJzdWIiOiIxIn0.sig", "jwt=" + Mask},
+		{"project key", "token", "", "key prod_0123456789abcdef0123456789abcdef", "key " + Mask},
+		{"card number", "card_number", "", "card 4111 1111 1111 1111 ok", "card " + Mask + " ok"},
+		{"number failing the checksum", "card_number", "", "order 4111 1111 1111 1112", "order 4111 1111 1111 1112"},
+		{"custom regex", "", `ssn-\d{3}`, "id ssn-123 and ssn-12", "id " + Mask + " and ssn-12"},
+		{"mask left alone", "token", "", Mask, Mask},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			rules := Rules{}
+			if tt.pattern != "" {
+				rules.Patterns = []string{tt.pattern}
+			}
+			if tt.regex != "" {
+				rules.Regexes = []string{tt.regex}
+			}
+			if got := mustCompile(t, rules).Text(tt.text); got != tt.want {
+				t.Fatalf("want %q, got %q", tt.want, got)
+			}
+		})
+	}
+}
+
+func TestBodyPatterns(t *testing.T) {
+	r := mustCompile(t, Rules{JSONPaths: []string{"$.password"}, Patterns: []string{"email"}})
+
+	body := map[string]any{
+		"password": "hunter2",
+		"contacts": []any{"alice@example.com", map[string]any{"email": "bob@example.com", "age": float64(30)}},
+	}
+	want := map[string]any{
+		"password": Mask,
+		"contacts": []any{Mask, map[string]any{"email": Mask, "age": float64(30)}},
+	}
+	if got := r.Body(body); !reflect.DeepEqual(got, want) {
+		t.Fatalf("body mismatch: %v", got)
+	}
+
+	// Bodies kept as text are matched too
+	if got := r.Body("from alice@example.com"); got != "from "+Mask {
+		t.Fatalf("want the text body masked, got %v", got)
+	}
+}
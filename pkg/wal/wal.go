@@ -0,0 +1,559 @@
+// Package wal implements a segmented write-ahead log. Records are appended to
+// segment files with a CRC-32C checksum and synced before Append returns.
+// Consumers acknowledge records once they are processed; a segment is removed
+// when every record in it has been acknowledged. Records that were not
+// acknowledged before a restart are reported as pending by Open.
+package wal
+
+import (
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"hash/crc32"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/spidey52/api-logs/pkg/logger"
+)
+
+var (
+	// ErrFull is returned by Append when the log would exceed its maximum size
+	ErrFull = errors.New("wal: log is full")
+	// ErrCorrupt is returned by Read when a record fails its checksum, and by
+	// Open when a segment is damaged before its end
+	ErrCorrupt = errors.New("wal: corrupt record")
+	// ErrTooLarge is returned by Append for a record over MaxRecordSize
+	ErrTooLarge = errors.New("wal: record is too large")
+	// ErrClosed is returned after Close
+	ErrClosed = errors.New("wal: log is closed")
+)
+
+const (
+	segmentExt = ".wal"
+	ackExt     = ".ack"
+
+	// headerSize is the record header: data length, checksum and append time
+	headerSize = 4 + 4 + 8
+
+	// DefaultMaxRecordSize is the MaxRecordSize used when none is set
+	DefaultMaxRecordSize = 64 << 20
+)
+
+var crcTable = crc32.MakeTable(crc32.Castagnoli)
+
+// Options configures a Log
+type Options struct {
+	// SegmentSize is the size after which a new segment is started
+	SegmentSize int64
+	// MaxSize bounds the bytes on disk; 0 means unlimited
+	MaxSize int64
+	// MaxRecordSize bounds the data of a record. Recovery treats a header
+	// claiming more as corrupt rather than allocating for it.
+	MaxRecordSize int64
+}
+
+// Position identifies a record
+type Position struct {
+	Segment uint64
+	Offset  int64
+}
+
+// Stats describes the records not yet acknowledged
+type Stats struct {
+	Records int
+	Bytes   int64     // size of the segment files on disk
+	Oldest  time.Time // append time of the oldest pending record; zero when there are none
+}
+
+type segment struct {
+	id      uint64
+	file    *os.File
+	acks    *os.File
+	size    int64
+	records int
+	acked   int
+}
+
+// Log is a segmented write-ahead log. It is safe for concurrent use.
+type Log struct {
+	dir  string
+	opts Options
+
+	mu       sync.Mutex
+	segments map[uint64]*segment
+	active   *segment
+	pending  map[Position]time.Time
+	size     int64
+	closed   bool
+}
+
+// Open opens the log in dir, creating the directory if needed, and recovers
+// the records of earlier runs that were not acknowledged. A torn record at the
+// end of a segment, left by a crash during Append, is truncated. A record
+// failing its checksum before the end is kept pending, for Read to report as
+// ErrCorrupt, and the records after it are recovered; a header too damaged to
+// find the next record fails Open with ErrCorrupt.
+func Open(dir string, opts Options) (*Log, error) {
+	if opts.SegmentSize <= 0 {
+		opts.SegmentSize = 64 << 20
+	}
+	if opts.MaxRecordSize <= 0 {
+		opts.MaxRecordSize = DefaultMaxRecordSize
+	}
+	// Records may hold sensitive data, so the log is readable by its owner
+	// only
+	if err := os.MkdirAll(dir, 0o700); err != nil {
+		return nil, fmt.Errorf("wal: create directory: %w", err)
+	}
+	if info, err := os.Stat(dir); err == nil && info.Mode().Perm()&0o077 != 0 {
+		logger.Warn("Write-ahead log directory is accessible to other users", "dir", dir, "mode", info.Mode().Perm())
+	}
+
+	l := &Log{
+		dir:      dir,
+		opts:     opts,
+		segments: make(map[uint64]*segment),
+		pending:  make(map[Position]time.Time),
+	}
+
+	ids, err := l.segmentIDs()
+	if err != nil {
+		return nil, err
+	}
+
+	var next uint64 = 1
+	for _, id := range ids {
+		if err := l.recover(id); err != nil {
+			l.Close()
+			return nil, err
+		}
+		next = id + 1
+	}
+
+	if err := l.startSegment(next); err != nil {
+		l.Close()
+		return nil, err
+	}
+	return l, nil
+}
+
+func (l *Log) segmentIDs() ([]uint64, error) {
+	entries, err := os.ReadDir(l.dir)
+	if err != nil {
+		return nil, fmt.Errorf("wal: read directory: %w", err)
+	}
+
+	var ids []uint64
+	for _, entry := range entries {
+		name := entry.Name()
+		if entry.IsDir() || !strings.HasSuffix(name, segmentExt) {
+			continue
+		}
+		id, err := strconv.ParseUint(strings.TrimSuffix(name, segmentExt), 10, 64)
+		if err != nil {
+			continue
+		}
+		ids = append(ids, id)
+	}
+	sort.Slice(ids, func(i, j int) bool { return ids[i] < ids[j] })
+	return ids, nil
+}
+
+func (l *Log) segmentPath(id uint64, ext string) string {
+	return filepath.Join(l.dir, fmt.Sprintf("%016d%s", id, ext))
+}
+
+// recover scans a segment of an earlier run and registers its pending records
+func (l *Log) recover(id uint64) error {
+	file, err := os.OpenFile(l.segmentPath(id, segmentExt), os.O_RDWR, 0o600)
+	if err != nil {
+		return fmt.Errorf("wal: open segment: %w", err)
+	}
+	// Segments of earlier versions were created readable by everyone
+	if err := file.Chmod(0o600); err != nil {
+		file.Close()
+		return fmt.Errorf("wal: chmod segment: %w", err)
+	}
+	seg := &segment{id: id, file: file}
+
+	info, err := file.Stat()
+	if err != nil {
+		file.Close()
+		return fmt.Errorf("wal: stat segment: %w", err)
+	}
+
+	acked, err := l.readAcks(id)
+	if err != nil {
+		file.Close()
+		return err
+	}
+
+	var offset int64
+	for offset < info.Size() {
+		appended, length, err := l.scanRecord(file, offset, info.Size())
+		if errors.Is(err, errTorn) {
+			logger.Warn("Truncating torn write-ahead log segment", "segment", id, "offset", offset, "error", err)
+			if err := file.Truncate(offset); err != nil {
+				file.Close()
+				return fmt.Errorf("wal: truncate segment: %w", err)
+			}
+			break
+		}
+		if errors.Is(err, ErrCorrupt) && !errors.Is(err, errLength) {
+			// The frame is intact but its data is not; Read reports it
+			logger.Error("Corrupt record in write-ahead log segment", "segment", id, "offset", offset, "error", err)
+			appended = info.ModTime()
+		} else if err != nil {
+			file.Close()
+			return fmt.Errorf("wal: segment %d at offset %d: %w", id, offset, err)
+		}
+
+		seg.records++
+		if acked[offset] {
+			seg.acked++
+		} else {
+			l.pending[Position{Segment: id, Offset: offset}] = appended
+		}
+		offset += headerSize + length
+	}
+	seg.size = offset
+
+	if seg.acked == seg.records {
+		file.Close()
+		return l.removeSegmentFiles(id)
+	}
+
+	acks, err := os.OpenFile(l.segmentPath(id, ackExt), os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0o600)
+	if err != nil {
+		file.Close()
+		return fmt.Errorf("wal: open acks: %w", err)
+	}
+	seg.acks = acks
+
+	l.segments[id] = seg
+	l.size += seg.size
+	return nil
+}
+
+// readAcks returns the acknowledged record offsets of a segment
+func (l *Log) readAcks(id uint64) (map[int64]bool, error) {
+	data, err := os.ReadFile(l.segmentPath(id, ackExt))
+	if err != nil && !os.IsNotExist(err) {
+		return nil, fmt.Errorf("wal: read acks: %w", err)
+	}
+
+	acked := make(map[int64]bool, len(data)/8)
+	for i := 0; i+8 <= len(data); i += 8 {
+		acked[int64(binary.LittleEndian.Uint64(data[i:]))] = true
+	}
+	return acked, nil
+}
+
+var (
+	// errTorn reports a record cut short by the end of its segment
+	errTorn = errors.New("wal: torn record")
+	// errLength reports a record header whose length cannot be trusted, so
+	// the records after it cannot be found
+	errLength = fmt.Errorf("%w: length over the maximum", ErrCorrupt)
+)
+
+// scanRecord verifies the record at offset of a segment of the given size,
+// returning its append time and data length. A record that does not fit in
+// the segment, fails its checksum as the last one or is followed only by
+// zeros is torn: Append was writing it when the process stopped. A record
+// failing its checksum before the end is ErrCorrupt with its length, so the
+// next one can be read; a header whose length cannot be trusted is errLength.
+func (l *Log) scanRecord(file *os.File, offset, size int64) (time.Time, int64, error) {
+	if size-offset < headerSize {
+		return time.Time{}, 0, fmt.Errorf("%w: short header", errTorn)
+	}
+
+	appended, data, err := readRecord(file, offset, l.opts.MaxRecordSize)
+	if err == nil {
+		return appended, int64(len(data)), nil
+	}
+	if errors.Is(err, ErrCorrupt) {
+		if zeros, zerr := zeroFrom(file, offset, size); zerr != nil {
+			return time.Time{}, 0, zerr
+		} else if zeros {
+			return time.Time{}, 0, fmt.Errorf("%w: %v", errTorn, err)
+		}
+	}
+	if errors.Is(err, errLength) {
+		return time.Time{}, 0, err
+	}
+
+	header := make([]byte, headerSize)
+	if _, err := file.ReadAt(header, offset); err != nil {
+		return time.Time{}, 0, fmt.Errorf("wal: read record: %w", err)
+	}
+	length := int64(binary.LittleEndian.Uint32(header[0:]))
+	if end := offset + headerSize + length; end >= size {
+		return time.Time{}, 0, fmt.Errorf("%w: %v", errTorn, err)
+	}
+	return time.Time{}, length, err
+}
+
+// zeroFrom reports whether a segment holds only zeros from offset to size,
+// as when the file grew but the record written there never reached the disk
+func zeroFrom(file *os.File, offset, size int64) (bool, error) {
+	buf := make([]byte, 32<<10)
+	for offset < size {
+		n := min(int64(len(buf)), size-offset)
+		if _, err := file.ReadAt(buf[:n], offset); err != nil {
+			return false, fmt.Errorf("wal: read segment: %w", err)
+		}
+		for _, b := range buf[:n] {
+			if b != 0 {
+				return false, nil
+			}
+		}
+		offset += n
+	}
+	return true, nil
+}
+
+// readRecord reads and verifies the record at offset, returning its append time
+// and data. Lengths over maxSize are not read.
+func readRecord(file *os.File, offset, maxSize int64) (time.Time, []byte, error) {
+	header := make([]byte, headerSize)
+	if n, _ := file.ReadAt(header, offset); n < headerSize {
+		return time.Time{}, nil, fmt.Errorf("%w: short header", ErrCorrupt)
+	}
+
+	length := int64(binary.LittleEndian.Uint32(header[0:]))
+	if length > maxSize {
+		return time.Time{}, nil, fmt.Errorf("%w: %d bytes", errLength, length)
+	}
+	data := make([]byte, length)
+	if _, err := file.ReadAt(data, offset+headerSize); err != nil {
+		return time.Time{}, nil, fmt.Errorf("%w: short record", ErrCorrupt)
+	}
+	if checksum(header[8:], data) != binary.LittleEndian.Uint32(header[4:]) {
+		return time.Time{}, nil, fmt.Errorf("%w: checksum mismatch", ErrCorrupt)
+	}
+
+	appended := time.Unix(0, int64(binary.LittleEndian.Uint64(header[8:])))
+	return appended, data, nil
+}
+
+func checksum(timestamp, data []byte) uint32 {
+	crc := crc32.Update(0, crcTable, timestamp)
+	return crc32.Update(crc, crcTable, data)
+}
+
+func (l *Log) startSegment(id uint64) error {
+	file, err := os.OpenFile(l.segmentPath(id, segmentExt), os.O_CREATE|os.O_RDWR|os.O_TRUNC, 0o600)
+	if err != nil {
+		return fmt.Errorf("wal: create segment: %w", err)
+	}
+	acks, err := os.OpenFile(l.segmentPath(id, ackExt), os.O_CREATE|os.O_WRONLY|os.O_TRUNC, 0o600)
+	if err != nil {
+		file.Close()
+		return fmt.Errorf("wal: create acks: %w", err)
+	}
+
+	// The new files' directory entries must survive a crash along with
+	// the records synced into them
+	if err := syncDir(l.dir); err != nil {
+		file.Close()
+		acks.Close()
+		return err
+	}
+
+	seg := &segment{id: id, file: file, acks: acks}
+	l.segments[id] = seg
+	l.active = seg
+	return nil
+}
+
+// syncDir syncs a directory, persisting the files created in it
+func syncDir(dir string) error {
+	d, err := os.Open(dir)
+	if err != nil {
+		return fmt.Errorf("wal: open directory: %w", err)
+	}
+	defer d.Close()
+
+	if err := d.Sync(); err != nil {
+		return fmt.Errorf("wal: sync directory: %w", err)
+	}
+	return nil
+}
+
+// Append writes a record and syncs it to disk
+func (l *Log) Append(data []byte) (Position, error) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	if l.closed {
+		return Position{}, ErrClosed
+	}
+	if int64(len(data)) > l.opts.MaxRecordSize {
+		return Position{}, ErrTooLarge
+	}
+
+	size := headerSize + int64(len(data))
+	if l.opts.MaxSize > 0 && l.size+size > l.opts.MaxSize {
+		return Position{}, ErrFull
+	}
+
+	if l.active.size > 0 && l.active.size+size > l.opts.SegmentSize {
+		previous := l.active
+		if err := l.startSegment(previous.id + 1); err != nil {
+			return Position{}, err
+		}
+		if err := l.removeIfAcked(previous); err != nil {
+			return Position{}, err
+		}
+	}
+
+	now := time.Now()
+	record := make([]byte, size)
+	binary.LittleEndian.PutUint32(record[0:], uint32(len(data)))
+	binary.LittleEndian.PutUint64(record[8:], uint64(now.UnixNano()))
+	copy(record[headerSize:], data)
+	binary.LittleEndian.PutUint32(record[4:], checksum(record[8:headerSize], data))
+
+	seg := l.active
+	pos := Position{Segment: seg.id, Offset: seg.size}
+	if _, err := seg.file.WriteAt(record, seg.size); err != nil {
+		return Position{}, fmt.Errorf("wal: write record: %w", err)
+	}
+	if err := seg.file.Sync(); err != nil {
+		return Position{}, fmt.Errorf("wal: sync segment: %w", err)
+	}
+
+	seg.size += size
+	seg.records++
+	l.size += size
+	l.pending[pos] = now
+	return pos, nil
+}
+
+// Read returns the data of a pending record
+func (l *Log) Read(pos Position) ([]byte, error) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	if l.closed {
+		return nil, ErrClosed
+	}
+	seg, ok := l.segments[pos.Segment]
+	if !ok {
+		return nil, fmt.Errorf("wal: segment %d not found", pos.Segment)
+	}
+
+	_, data, err := readRecord(seg.file, pos.Offset, l.opts.MaxRecordSize)
+	return data, err
+}
+
+// Ack marks a record as processed. Acknowledging a record twice is a no-op.
+func (l *Log) Ack(pos Position) error {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	if l.closed {
+		return ErrClosed
+	}
+	if _, ok := l.pending[pos]; !ok {
+		return nil
+	}
+	seg := l.segments[pos.Segment]
+
+	var offset [8]byte
+	binary.LittleEndian.PutUint64(offset[:], uint64(pos.Offset))
+	if _, err := seg.acks.Write(offset[:]); err != nil {
+		return fmt.Errorf("wal: write ack: %w", err)
+	}
+	if err := seg.acks.Sync(); err != nil {
+		return fmt.Errorf("wal: sync acks: %w", err)
+	}
+
+	delete(l.pending, pos)
+	seg.acked++
+	return l.removeIfAcked(seg)
+}
+
+// removeIfAcked removes a full segment once all of its records are acknowledged
+func (l *Log) removeIfAcked(seg *segment) error {
+	if seg == l.active || seg.acked < seg.records {
+		return nil
+	}
+
+	seg.file.Close()
+	seg.acks.Close()
+	delete(l.segments, seg.id)
+	l.size -= seg.size
+	return l.removeSegmentFiles(seg.id)
+}
+
+func (l *Log) removeSegmentFiles(id uint64) error {
+	for _, ext := range []string{segmentExt, ackExt} {
+		if err := os.Remove(l.segmentPath(id, ext)); err != nil && !os.IsNotExist(err) {
+			return fmt.Errorf("wal: remove segment: %w", err)
+		}
+	}
+	return nil
+}
+
+// Pending returns the records not yet acknowledged, oldest first
+func (l *Log) Pending() []Position {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	positions := make([]Position, 0, len(l.pending))
+	for pos := range l.pending {
+		positions = append(positions, pos)
+	}
+	sort.Slice(positions, func(i, j int) bool {
+		if positions[i].Segment != positions[j].Segment {
+			return positions[i].Segment < positions[j].Segment
+		}
+		return positions[i].Offset < positions[j].Offset
+	})
+	return positions
+}
+
+// Stats describes the records not yet acknowledged
+func (l *Log) Stats() Stats {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	stats := Stats{Records: len(l.pending), Bytes: l.size}
+	for _, appended := range l.pending {
+		if stats.Oldest.IsZero() || appended.Before(stats.Oldest) {
+			stats.Oldest = appended
+		}
+	}
+	return stats
+}
+
+// Close closes the segment files. Pending records are recovered by the next Open.
+func (l *Log) Close() error {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	if l.closed {
+		return nil
+	}
+	l.closed = true
+
+	var firstErr error
+	for _, seg := range l.segments {
+		if err := seg.file.Close(); err != nil && firstErr == nil {
+			firstErr = err
+		}
+		if seg.acks != nil {
+			if err := seg.acks.Close(); err != nil && firstErr == nil {
+				firstErr = err
+			}
+		}
+	}
+	return firstErr
+}
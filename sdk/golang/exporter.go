@@ -2,12 +2,16 @@ package apilog
 
 import (
 	"bytes"
+	"compress/gzip"
+	"crypto/rand"
 	"encoding/json"
 	"fmt"
 	"io"
 	"net/http"
 	"sync"
 	"time"
+
+	"github.com/klauspost/compress/zstd"
 )
 
 type Environment string
@@ -31,6 +35,9 @@ const (
 )
 
 type APILogEntry struct {
+	// ID identifies the log so the server stores it once even when a batch is
+	// retried. Log assigns one when empty.
+	ID              string                 `json:"id,omitempty"`
 	Method          HTTPMethod             `json:"method"`
 	Path            string                 `json:"path"`
 	QueryParams     map[string]string      `json:"query_params"`
@@ -49,6 +56,15 @@ type APILogEntry struct {
 	ErrorMessage    string                 `json:"error_message,omitempty"`
 }
 
+// Compression is the Content-Encoding batches are sent with
+type Compression string
+
+const (
+	CompressionNone Compression = ""
+	CompressionGzip Compression = "gzip"
+	CompressionZstd Compression = "zstd"
+)
+
 type ExporterConfig struct {
 	APIKey        string
 	Environment   Environment
@@ -59,6 +75,7 @@ type ExporterConfig struct {
 	MaxRetries    int
 	RetryDelay    time.Duration
 	CreateUsers   bool
+	Compression   Compression
 }
 
 type BatchRequest struct {
@@ -67,10 +84,11 @@ type BatchRequest struct {
 }
 
 type BatchResponse struct {
-	SuccessCount int      `json:"success_count"`
-	FailedCount  int      `json:"failed_count"`
-	Total        int      `json:"total"`
-	Errors       []string `json:"errors,omitempty"`
+	SuccessCount   int      `json:"success_count"`
+	DuplicateCount int      `json:"duplicate_count"` // logs the server had already accepted
+	FailedCount    int      `json:"failed_count"`
+	Total          int      `json:"total"`
+	Errors         []string `json:"errors,omitempty"`
 }
 
 type Exporter struct {
@@ -126,6 +144,10 @@ func (e *Exporter) Log(entry APILogEntry) error {
 		return nil
 	}
 
+	if entry.ID == "" {
+		entry.ID = newLogID()
+	}
+
 	e.mu.Lock()
 	defer e.mu.Unlock()
 
@@ -139,6 +161,15 @@ func (e *Exporter) Log(entry APILogEntry) error {
 	return nil
 }
 
+// newLogID returns a random (version 4) UUID
+func newLogID() string {
+	var b [16]byte
+	_, _ = rand.Read(b[:])
+	b[6] = (b[6] & 0x0f) | 0x40
+	b[8] = (b[8] & 0x3f) | 0x80
+	return fmt.Sprintf("%x-%x-%x-%x-%x", b[0:4], b[4:6], b[6:8], b[8:10], b[10:])
+}
+
 func (e *Exporter) Flush() (*BatchResponse, error) {
 	if !e.config.Enabled {
 		return nil, nil
@@ -169,6 +200,10 @@ func (e *Exporter) sendBatch(logs []APILogEntry) (*BatchResponse, error) {
 	if err != nil {
 		return nil, fmt.Errorf("failed to marshal batch: %w", err)
 	}
+	body, err = compress(body, e.config.Compression)
+	if err != nil {
+		return nil, fmt.Errorf("failed to compress batch: %w", err)
+	}
 
 	url := fmt.Sprintf("%s/api/v1/logs/batch", e.config.BaseURL)
 	req, err := http.NewRequest("POST", url, bytes.NewBuffer(body))
@@ -177,6 +212,9 @@ func (e *Exporter) sendBatch(logs []APILogEntry) (*BatchResponse, error) {
 	}
 
 	req.Header.Set("Content-Type", "application/json")
+	if e.config.Compression != CompressionNone {
+		req.Header.Set("Content-Encoding", string(e.config.Compression))
+	}
 	req.Header.Set("X-API-Key", e.config.APIKey)
 	req.Header.Set("X-Environment", string(e.config.Environment))
 
@@ -213,6 +251,36 @@ func (e *Exporter) sendBatch(logs []APILogEntry) (*BatchResponse, error) {
 	return &batchResp, nil
 }
 
+// compress encodes a request body with the given compression
+func compress(body []byte, compression Compression) ([]byte, error) {
+	switch compression {
+	case CompressionNone:
+		return body, nil
+
+	case CompressionGzip:
+		var buf bytes.Buffer
+		writer := gzip.NewWriter(&buf)
+		if _, err := writer.Write(body); err != nil {
+			return nil, err
+		}
+		if err := writer.Close(); err != nil {
+			return nil, err
+		}
+		return buf.Bytes(), nil
+
+	case CompressionZstd:
+		encoder, err := zstd.NewWriter(nil)
+		if err != nil {
+			return nil, err
+		}
+		defer encoder.Close()
+		return encoder.EncodeAll(body, nil), nil
+
+	default:
+		return nil, fmt.Errorf("unsupported compression %q", compression)
+	}
+}
+
 func (e *Exporter) autoFlush() {
 	for {
 		select {
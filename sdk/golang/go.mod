@@ -4,7 +4,10 @@ go 1.23.0
 
 toolchain go1.24.5
 
-require github.com/gin-gonic/gin v1.11.0
+require (
+	github.com/gin-gonic/gin v1.11.0
+	github.com/klauspost/compress v1.16.7
+)
 
 require (
 	github.com/bytedance/sonic v1.14.0 // indirect